@@ -132,3 +132,20 @@ func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
 
 	return fmt.Errorf("invalid byte size value: %s", node.Value)
 }
+
+// Scan implements Scanner for the default, env, and ref tags, parsing a size
+// string such as "512MiB" or a number of bytes.
+func (b *ByteSize) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("fuda.ByteSize: cannot scan %T", src)
+	}
+
+	parsed, err := types.ParseBytes(s)
+	if err != nil {
+		return fmt.Errorf("invalid byte size string %q: %w", s, err)
+	}
+	*b = ByteSize(parsed)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+// runDeprecations implements "fuda-doc deprecations", reporting the code
+// under a repository root that still uses deprecated fields of a struct.
+func runDeprecations(args []string) error {
+	fs := flag.NewFlagSet("deprecations", flag.ContinueOnError)
+	structName := fs.String("struct", "", "Struct name to check (required)")
+	path := fs.String("path", "", "Directory or file path containing the struct (required)")
+	repo := fs.String("repo", ".", "Repository root to scan for references")
+	output := fs.String("output", "stdout", "Output target: file path or \"stdout\"")
+	fs.StringVar(structName, "s", "", "Short for -struct")
+	fs.StringVar(path, "p", "", "Short for -path")
+	fs.StringVar(repo, "r", ".", "Short for -repo")
+	fs.StringVar(output, "o", "stdout", "Short for -output")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-doc deprecations -s <struct> -p <path> [-r <repo>] [-o <file>]\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Reports references to fields marked with a `deprecated` tag or a\n")
+		_, _ = fmt.Fprint(os.Stderr, "\"Deprecated:\" doc comment, so old keys can be removed safely.\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Flags:\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -s, --struct string    Struct name to check (required)\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -p, --path string      Directory or file path containing the struct (required)\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -r, --repo string      Repository root to scan for references (default \".\")\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -o, --output string    Output target: file path or \"stdout\" (default \"stdout\")\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *structName == "" || *path == "" {
+		fs.Usage()
+
+		return errors.New("-struct and -path flags are required")
+	}
+
+	docs, err := docgen.ParseAll(*structName, *path)
+	if err != nil {
+		return err
+	}
+
+	deps, err := docgen.FindDeprecations(docs[0], *repo)
+	if err != nil {
+		return fmt.Errorf("scanning %s: %w", *repo, err)
+	}
+
+	report := docgen.FormatDeprecations(docs[0].Name, deps)
+	if *output == "stdout" {
+		fmt.Print(report)
+
+		return nil
+	}
+
+	if err := os.WriteFile(*output, []byte(report), 0o644); err != nil { //nolint:gosec // reports are meant to be readable
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	return nil
+}
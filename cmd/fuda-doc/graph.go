@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+// runGraph implements "fuda-doc graph", emitting the dependencies between
+// the fields of a struct as a Graphviz or Mermaid graph.
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	structName := fs.String("struct", "", "Struct name to graph (required)")
+	path := fs.String("path", ".", "Directory or file path containing the struct")
+	output := fs.String("output", "stdout", "Output target: file path or \"stdout\"")
+	dot := fs.Bool("dot", false, "Output a Graphviz digraph (default)")
+	mermaid := fs.Bool("mermaid", false, "Output a Mermaid flowchart")
+	fs.StringVar(structName, "s", "", "Short for -struct")
+	fs.StringVar(path, "p", ".", "Short for -path")
+	fs.StringVar(output, "o", "stdout", "Short for -output")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-doc graph -s <struct> [-p <path>] [--dot | --mermaid] [-o <file>]\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Graphs which fields feed dsn, ref, and expr templates and refFrom\n")
+		_, _ = fmt.Fprint(os.Stderr, "sources. Red dashed edges read a field declared later, which is still\n")
+		_, _ = fmt.Fprint(os.Stderr, "unset when the template runs.\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Flags:\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -s, --struct string    Struct name to graph (required)\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -p, --path string      Directory or file path containing the struct (default \".\")\n")
+		_, _ = fmt.Fprint(os.Stderr, "      --dot              Output a Graphviz digraph (default)\n")
+		_, _ = fmt.Fprint(os.Stderr, "      --mermaid          Output a Mermaid flowchart\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -o, --output string    Output target: file path or \"stdout\" (default \"stdout\")\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *structName == "" {
+		fs.Usage()
+
+		return errors.New("-struct flag is required")
+	}
+	if *dot && *mermaid {
+		return errors.New("--dot and --mermaid are mutually exclusive")
+	}
+
+	docs, err := docgen.ParseAll(*structName, *path)
+	if err != nil {
+		return err
+	}
+
+	deps := docgen.FindDependencies(docs[0])
+	graph := docgen.FormatDOT(docs[0].Name, deps)
+	if *mermaid {
+		graph = docgen.FormatMermaid(deps)
+	}
+
+	if *output == "stdout" {
+		fmt.Print(graph)
+
+		return nil
+	}
+
+	if err := os.WriteFile(*output, []byte(graph), 0o644); err != nil { //nolint:gosec // graphs are meant to be readable
+		return fmt.Errorf("writing graph: %w", err)
+	}
+
+	return nil
+}
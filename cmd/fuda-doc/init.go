@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/wizard"
+)
+
+// runInit implements "fuda-doc init", an interactive wizard that asks for
+// the value of each field of a struct and writes a config.yaml and .env pair.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	structName := fs.String("struct", "", "Struct name to create a config for (required)")
+	path := fs.String("path", "", "Directory or file path containing the struct (required)")
+	output := fs.String("output", ".", "Directory to write config.yaml and .env to")
+	force := fs.Bool("force", false, "Overwrite existing config.yaml and .env files")
+	fs.StringVar(structName, "s", "", "Short for -struct")
+	fs.StringVar(path, "p", "", "Short for -path")
+	fs.StringVar(output, "o", ".", "Short for -output")
+	fs.BoolVar(force, "f", false, "Short for -force")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-doc init -s <struct> -p <path> [-o <dir>] [-f]\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Walks through the fields of a struct, showing descriptions, defaults, and\n")
+		_, _ = fmt.Fprint(os.Stderr, "validate rules, and writes the checked values to config.yaml (fields\n")
+		_, _ = fmt.Fprint(os.Stderr, "without an env tag) and .env (fields with one).\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Flags:\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -s, --struct string    Struct name to create a config for (required)\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -p, --path string      Directory or file path containing the struct (required)\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -o, --output string    Directory to write config.yaml and .env to (default \".\")\n")
+		_, _ = fmt.Fprint(os.Stderr, "  -f, --force            Overwrite existing config.yaml and .env files\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *structName == "" || *path == "" {
+		fs.Usage()
+
+		return errors.New("-struct and -path flags are required")
+	}
+
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return errors.New("init needs an interactive terminal")
+	}
+
+	configPath := filepath.Join(*output, "config.yaml")
+	envPath := filepath.Join(*output, ".env")
+	if !*force {
+		for _, p := range []string{configPath, envPath} {
+			if _, err := os.Stat(p); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", p)
+			}
+		}
+	}
+
+	docs, err := docgen.ParseAll(*structName, *path)
+	if err != nil {
+		return err
+	}
+
+	fields := docgen.InitFields(docs[0])
+	if len(fields) == 0 {
+		return fmt.Errorf("struct %s has no fields to configure", *structName)
+	}
+
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	values, err := wizard.Run(docs[0].Name, fields)
+	if err != nil {
+		return err
+	}
+
+	configYAML, dotenv := docgen.RenderInitFiles(docs[0].Name, fields, values)
+
+	if err := os.MkdirAll(*output, 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	if err := os.WriteFile(configPath, configYAML, 0o644); err != nil { //nolint:gosec // the config is meant to be readable
+		return fmt.Errorf("writing config: %w", err)
+	}
+	// The .env file may hold secrets
+	if err := os.WriteFile(envPath, dotenv, 0o600); err != nil {
+		return fmt.Errorf("writing env file: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Wrote %s and %s\n", configPath, envPath)
+
+	return nil
+}
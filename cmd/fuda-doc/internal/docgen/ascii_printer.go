@@ -5,8 +5,11 @@ import (
 	"io"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+
 	"github.com/arloliu/fuda/cmd/fuda-doc/internal/colors"
 	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+	"github.com/arloliu/fuda/docfmt"
 )
 
 // ASCIIPrinter generates terminal-friendly documentation with adaptive colors.
@@ -169,12 +172,16 @@ func (a *ASCIIPrinter) printFieldReference(fields []FieldInfo, depth int) {
 // ---------------------------------------------------------------------------
 
 func (a *ASCIIPrinter) printFieldTableASCII(fields []FieldInfo, indent string) {
-	type row struct {
-		field, typ, def, source string
+	table := docfmt.Table{
+		Headers:   []string{"Field", "Type", "Default", "Env / Source"},
+		MaxWidths: []int{32, 18, 24, 34},
+		Style: docfmt.TableStyle{
+			Divider: styler(colors.MutedStyle),
+			Header:  styler(colors.Bold(colors.Text)),
+			Columns: []docfmt.Styler{styler(colors.FieldStyle), styler(colors.TypeStyle)},
+		},
 	}
 
-	rows := make([]row, 0, len(fields))
-
 	for _, f := range fields {
 		if !docutil.IsExported(f.Name) {
 			continue
@@ -187,63 +194,14 @@ func (a *ASCIIPrinter) printFieldTableASCII(fields []FieldInfo, indent string) {
 			fieldStr = f.Name + " (" + yamlKey + ")"
 		}
 
-		rows = append(rows, row{
-			field:  fieldStr,
-			typ:    f.Type,
-			def:    plainDefault(f),
-			source: plainSource(f),
-		})
+		table.Rows = append(table.Rows, []string{fieldStr, f.Type, plainDefault(f), plainSource(f)})
 	}
 
-	if len(rows) == 0 {
+	if len(table.Rows) == 0 {
 		return
 	}
 
-	// Measure widths
-	wField, wType, wDef, wSrc := 5, 4, 7, 12
-
-	for _, r := range rows {
-		wField = maxInt(wField, len(r.field))
-		wType = maxInt(wType, len(r.typ))
-		wDef = maxInt(wDef, len(r.def))
-		wSrc = maxInt(wSrc, len(r.source))
-	}
-
-	// Cap widths
-	wField = minInt(wField, 32)
-	wType = minInt(wType, 18)
-	wDef = minInt(wDef, 24)
-	wSrc = minInt(wSrc, 34)
-
-	totalW := wField + wType + wDef + wSrc + 13 // separators + padding
-
-	// Header
-	divider := indent + "  " + colors.MutedStyle.Render(strings.Repeat("─", totalW))
-
-	hdr := colors.Bold(colors.Text)
-	a.printf("%s\n", divider)
-	a.printf("%s  %s │ %s │ %s │ %s\n",
-		indent,
-		hdr.Render(padOrTrunc("Field", wField)),
-		hdr.Render(padOrTrunc("Type", wType)),
-		hdr.Render(padOrTrunc("Default", wDef)),
-		hdr.Render(padOrTrunc("Env / Source", wSrc)))
-	a.printf("%s\n", divider)
-
-	// Rows
-	fld := colors.FieldStyle
-	typ := colors.TypeStyle
-
-	for _, r := range rows {
-		a.printf("%s  %s │ %s │ %-*s │ %-*s\n",
-			indent,
-			fld.Render(padOrTrunc(r.field, wField)),
-			typ.Render(padOrTrunc(r.typ, wType)),
-			wDef, padOrTrunc(r.def, wDef),
-			wSrc, padOrTrunc(r.source, wSrc))
-	}
-
-	a.printf("%s\n", divider)
+	_ = table.WriteASCII(a.w, indent)
 }
 
 func (a *ASCIIPrinter) printFieldDetailsASCII(fields []FieldInfo, indent string) {
@@ -281,6 +239,10 @@ func (a *ASCIIPrinter) printFieldDetailsASCII(fields []FieldInfo, indent string)
 			a.printPropRow(indent, "DSN tmpl", v)
 		}
 
+		if v := f.Tags["expr"]; v != "" {
+			a.printPropRow(indent, "Expr", v)
+		}
+
 		if v := f.Tags["validate"]; v != "" {
 			a.printPropRow(indent, "Validate", v)
 		}
@@ -351,17 +313,16 @@ func (a *ASCIIPrinter) printDescriptionASCII(desc, indent string) {
 // ---------------------------------------------------------------------------
 
 func (a *ASCIIPrinter) printSectionTitle(title string) {
-	bar := strings.Repeat("─", len(title)+2)
-	s := colors.SectionStyle
-	a.printf("  %s\n", s.Render("┌"+bar+"┐"))
-	a.printf("  %s\n", s.Render("│ "+title+" │"))
-	a.printf("  %s\n\n", s.Render("└"+bar+"┘"))
+	a.printf("%s\n", docfmt.SectionTitle(title, styler(colors.SectionStyle)))
 }
 
 func (a *ASCIIPrinter) printSubsectionTitle(title string, depth int) {
-	indent := strings.Repeat("  ", depth)
-	bar := strings.Repeat("─", len(title)+4)
-	a.printf("%s  %s\n\n", indent, colors.SubsectionStyle.Render("── "+title+" "+bar))
+	a.printf("%s\n", docfmt.SubsectionTitle(title, depth, styler(colors.SubsectionStyle)))
+}
+
+// styler adapts a lipgloss style to a docfmt.Styler.
+func styler(style lipgloss.Style) docfmt.Styler {
+	return func(s string) string { return style.Render(s) }
 }
 
 // ---------------------------------------------------------------------------
@@ -396,6 +357,10 @@ func plainSource(f FieldInfo) string {
 		parts = append(parts, "dsn ✓")
 	}
 
+	if _, ok := f.Tags["expr"]; ok {
+		parts = append(parts, "expr ✓")
+	}
+
 	if len(parts) == 0 {
 		return "-"
 	}
@@ -410,28 +375,3 @@ func plainSource(f FieldInfo) string {
 func (a *ASCIIPrinter) printf(format string, args ...any) {
 	_, _ = fmt.Fprintf(a.w, format, args...)
 }
-
-func maxInt(a, b int) int {
-	if a > b {
-		return a
-	}
-
-	return b
-}
-
-func minInt(a, b int) int {
-	if a < b {
-		return a
-	}
-
-	return b
-}
-
-func padOrTrunc(s string, width int) string {
-	r := []rune(s)
-	if len(r) > width {
-		return string(r[:width-1]) + "…"
-	}
-
-	return s + strings.Repeat(" ", width-len(r))
-}
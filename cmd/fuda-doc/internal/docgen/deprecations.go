@@ -0,0 +1,258 @@
+package docgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+)
+
+// Deprecation is a deprecated config field and the code that still uses it.
+type Deprecation struct {
+	Path       string      // Go field path, e.g. "Database.OldHost"
+	Key        string      // dotted YAML key path, e.g. "database.old_host"
+	Env        string      // env var name, if the field has an env tag
+	Message    string      // deprecated tag or "Deprecated:" doc paragraph
+	References []Reference // uses of the field, ordered by position
+}
+
+// Reference is one use of a deprecated field in Go source.
+type Reference struct {
+	File   string // path relative to the scanned root
+	Line   int
+	Column int
+	Write  bool // assignment or composite literal key, rather than a read
+}
+
+// FindDeprecations returns the deprecated fields of doc, marked with a
+// `deprecated:"message"` tag or a "Deprecated:" doc paragraph, with their
+// references in the Go files under root.
+//
+// References are matched by identifier: a selector or composite literal key
+// naming the field. A selector qualified by another field of the config,
+// such as cfg.Cache.Host for a deprecated Database.Host, is not counted.
+// Vendor, testdata, and hidden directories are skipped.
+func FindDeprecations(doc StructDoc, root string) ([]Deprecation, error) {
+	var deps []Deprecation
+	byName := make(map[string][]int) // field name → indexes into deps
+	parents := make(map[int]string)  // deps index → parent field name
+	fieldNames := make(map[string]bool)
+
+	var walk func(fields []FieldInfo, prefix, keyPrefix, parent string)
+	walk = func(fields []FieldInfo, prefix, keyPrefix, parent string) {
+		for i := range fields {
+			f := &fields[i]
+			fieldNames[f.Name] = true
+
+			path := joinPath(prefix, f.Name)
+			key := joinPath(keyPrefix, docutil.YAMLKey(f))
+			if msg, ok := deprecationMessage(f); ok {
+				env, _, _ := strings.Cut(f.Tags["env"], "=")
+				env, _, _ = strings.Cut(env, ",")
+				byName[f.Name] = append(byName[f.Name], len(deps))
+				parents[len(deps)] = parent
+				deps = append(deps, Deprecation{Path: path, Key: key, Env: env, Message: msg})
+			}
+			walk(f.Nested, path, key, f.Name)
+		}
+	}
+	walk(doc.Fields, "", "", "")
+
+	if len(deps) == 0 {
+		return nil, nil
+	}
+
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+
+		for _, u := range fieldUses(file) {
+			for _, idx := range byName[u.name] {
+				if u.qualifier != "" && fieldNames[u.qualifier] && u.qualifier != parents[idx] {
+					continue
+				}
+
+				pos := fset.Position(u.pos)
+				deps[idx].References = append(deps[idx].References, Reference{
+					File:   filepath.ToSlash(rel),
+					Line:   pos.Line,
+					Column: pos.Column,
+					Write:  u.write,
+				})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deps {
+		refs := deps[i].References
+		sort.SliceStable(refs, func(a, b int) bool {
+			if refs[a].File != refs[b].File {
+				return refs[a].File < refs[b].File
+			}
+			if refs[a].Line != refs[b].Line {
+				return refs[a].Line < refs[b].Line
+			}
+
+			return refs[a].Column < refs[b].Column
+		})
+	}
+
+	return deps, nil
+}
+
+// FormatDeprecations renders a cleanup report for the deprecated fields of
+// structName.
+func FormatDeprecations(structName string, deps []Deprecation) string {
+	var sb strings.Builder
+	if len(deps) == 0 {
+		fmt.Fprintf(&sb, "No deprecated fields in %s.\n", structName)
+
+		return sb.String()
+	}
+
+	unused := 0
+	fmt.Fprintf(&sb, "Deprecated fields in %s: %d\n", structName, len(deps))
+	for _, d := range deps {
+		sb.WriteString("\n")
+		sb.WriteString(d.Path)
+		if d.Env != "" {
+			fmt.Fprintf(&sb, " (key: %s, env: %s)\n", d.Key, d.Env)
+		} else {
+			fmt.Fprintf(&sb, " (key: %s)\n", d.Key)
+		}
+		fmt.Fprintf(&sb, "  Deprecated: %s\n", d.Message)
+
+		if len(d.References) == 0 {
+			unused++
+			sb.WriteString("  References: none, safe to remove\n")
+
+			continue
+		}
+
+		fmt.Fprintf(&sb, "  References: %d\n", len(d.References))
+		for _, r := range d.References {
+			kind := "read"
+			if r.Write {
+				kind = "write"
+			}
+			fmt.Fprintf(&sb, "    %s:%d:%d  %s\n", r.File, r.Line, r.Column, kind)
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n%d of %d deprecated fields have no references.\n", unused, len(deps))
+
+	return sb.String()
+}
+
+// deprecationMessage returns the deprecation notice of f, if any.
+func deprecationMessage(f *FieldInfo) (string, bool) {
+	if msg, ok := f.Tags["deprecated"]; ok {
+		if msg == "" {
+			msg = "(no message)"
+		}
+
+		return msg, true
+	}
+
+	// Go convention: a paragraph starting with "Deprecated: ".
+	for para := range strings.SplitSeq(f.Description, "\n\n") {
+		if msg, ok := strings.CutPrefix(strings.TrimSpace(para), "Deprecated:"); ok {
+			return strings.Join(strings.Fields(msg), " "), true
+		}
+	}
+
+	return "", false
+}
+
+// fieldUse is a selector or composite literal key that may name a field.
+type fieldUse struct {
+	name      string
+	qualifier string // field name the selector is applied to, if any
+	pos       token.Pos
+	write     bool
+}
+
+// fieldUses returns the selectors and composite literal keys in file.
+func fieldUses(file *ast.File) []fieldUse {
+	written := make(map[*ast.SelectorExpr]bool)
+	var uses []fieldUse
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if sel, ok := lhs.(*ast.SelectorExpr); ok {
+					written[sel] = true
+				}
+			}
+		case *ast.IncDecStmt:
+			if sel, ok := n.X.(*ast.SelectorExpr); ok {
+				written[sel] = true
+			}
+		case *ast.CompositeLit:
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if id, ok := kv.Key.(*ast.Ident); ok {
+						uses = append(uses, fieldUse{name: id.Name, pos: id.Pos(), write: true})
+					}
+				}
+			}
+		case *ast.SelectorExpr:
+			u := fieldUse{name: n.Sel.Name, pos: n.Sel.Pos(), write: written[n]}
+			if x, ok := n.X.(*ast.SelectorExpr); ok {
+				u.qualifier = x.Sel.Name
+			}
+			uses = append(uses, u)
+		}
+
+		return true
+	})
+
+	return uses
+}
+
+// skipDir reports whether a directory is excluded from the scan.
+func skipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || name == "node_modules" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
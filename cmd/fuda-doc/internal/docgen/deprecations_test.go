@@ -0,0 +1,157 @@
+package docgen_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const deprecationConfig = `package config
+
+type Config struct {
+	Name     string   ` + "`" + `yaml:"name"` + "`" + `
+	LegacyID string   ` + "`" + `yaml:"legacy_id" env:"APP_LEGACY_ID" deprecated:"use Name"` + "`" + `
+	Database Database ` + "`" + `yaml:"database"` + "`" + `
+	Cache    Cache    ` + "`" + `yaml:"cache"` + "`" + `
+}
+
+type Database struct {
+	// Host is the primary host.
+	//
+	// Deprecated: use
+	// Primary instead.
+	Host    string ` + "`" + `yaml:"host"` + "`" + `
+	Primary string ` + "`" + `yaml:"primary"` + "`" + `
+	Unused  int    ` + "`" + `yaml:"unused" deprecated:""` + "`" + `
+}
+
+type Cache struct {
+	Host string ` + "`" + `yaml:"host"` + "`" + `
+}
+`
+
+const deprecationApp = `package app
+
+import "example.com/config"
+
+func run(cfg *config.Config, db config.Database) string {
+	cfg.Database.Host = "x"
+	_ = cfg.Cache.Host
+	_ = config.Config{LegacyID: "id"}
+	return cfg.LegacyID + db.Host
+}
+`
+
+// writeFiles writes files, keyed by relative path, under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindDeprecations(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"config/config.go":      deprecationConfig,
+		"app/app.go":            deprecationApp,
+		"vendor/x/x.go":         "package x\n\nvar _ = v.LegacyID\n",
+		"testdata/old.go":       "package old\n\nvar _ = v.LegacyID\n",
+		".hidden/hidden.go":     "package hidden\n\nvar _ = v.LegacyID\n",
+		"app/notes.txt":         "cfg.LegacyID",
+		"config/config_test.go": "package config\n\nfunc f(c Config) { c.Database.Unused++ }\n",
+	})
+
+	docs, err := docgen.ParseAll("Config", filepath.Join(root, "config"))
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+
+	deps, err := docgen.FindDeprecations(docs[0], root)
+	if err != nil {
+		t.Fatalf("FindDeprecations: %v", err)
+	}
+
+	if len(deps) != 3 {
+		t.Fatalf("got %d deprecations, want 3: %+v", len(deps), deps)
+	}
+
+	legacy := deps[0]
+	if legacy.Path != "LegacyID" || legacy.Key != "legacy_id" || legacy.Env != "APP_LEGACY_ID" || legacy.Message != "use Name" {
+		t.Errorf("unexpected LegacyID entry: %+v", legacy)
+	}
+	if got := refsString(legacy.References); got != "app/app.go:8:20 write, app/app.go:9:13 read" {
+		t.Errorf("LegacyID references = %s", got)
+	}
+
+	host := deps[1]
+	if host.Path != "Database.Host" || host.Key != "database.host" || host.Message != "use Primary instead." {
+		t.Errorf("unexpected Database.Host entry: %+v", host)
+	}
+	// cfg.Cache.Host is excluded; db.Host has no qualifier and is counted.
+	if got := refsString(host.References); got != "app/app.go:6:15 write, app/app.go:9:27 read" {
+		t.Errorf("Database.Host references = %s", got)
+	}
+
+	unused := deps[2]
+	if unused.Message != "(no message)" {
+		t.Errorf("Unused message = %q", unused.Message)
+	}
+	if got := refsString(unused.References); got != "config/config_test.go:3:31 write" {
+		t.Errorf("Unused references = %s", got)
+	}
+}
+
+func TestFormatDeprecations(t *testing.T) {
+	t.Parallel()
+
+	deps := []docgen.Deprecation{
+		{
+			Path: "LegacyID", Key: "legacy_id", Env: "APP_LEGACY_ID", Message: "use Name",
+			References: []docgen.Reference{{File: "app/app.go", Line: 9, Column: 13}},
+		},
+		{Path: "Database.Old", Key: "database.old", Message: "remove"},
+	}
+
+	got := docgen.FormatDeprecations("Config", deps)
+	for _, want := range []string{
+		"Deprecated fields in Config: 2",
+		"LegacyID (key: legacy_id, env: APP_LEGACY_ID)\n  Deprecated: use Name\n  References: 1\n    app/app.go:9:13  read\n",
+		"Database.Old (key: database.old)\n  Deprecated: remove\n  References: none, safe to remove\n",
+		"1 of 2 deprecated fields have no references.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := docgen.FormatDeprecations("Config", nil); got != "No deprecated fields in Config.\n" {
+		t.Errorf("empty report = %q", got)
+	}
+}
+
+func refsString(refs []docgen.Reference) string {
+	parts := make([]string, 0, len(refs))
+	for _, r := range refs {
+		kind := "read"
+		if r.Write {
+			kind = "write"
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d %s", r.File, r.Line, r.Column, kind))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -38,15 +38,27 @@ func collectEnvEntries(fields []FieldInfo, pathPrefix string) []envEntry {
 			path = pathPrefix + "." + key
 		}
 
-		if envVar := f.Tags["env"]; envVar != "" {
-			entries = append(entries, envEntry{
-				EnvVar:      envVar,
+		if tag := f.Tags["env"]; tag != "" {
+			entry := envEntry{
 				Type:        f.Type,
 				Default:     f.Tags["default"],
 				YAMLPath:    path,
 				Description: f.Description,
 				Required:    f.Tags["required"],
-			})
+			}
+
+			// Split NAME[=fallback][,required] into its parts.
+			name, required := strings.CutSuffix(tag, ",required")
+			name, fallback, hasFallback := strings.Cut(name, "=")
+			entry.EnvVar = name
+			if hasFallback && entry.Default == "" {
+				entry.Default = fallback
+			}
+			if required && entry.Required == "" {
+				entry.Required = "true"
+			}
+
+			entries = append(entries, entry)
 		}
 
 		if len(f.Nested) > 0 {
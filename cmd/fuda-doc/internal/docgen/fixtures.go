@@ -0,0 +1,984 @@
+package docgen
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+)
+
+// Fixture is one generated YAML config file.
+type Fixture struct {
+	Name        string // file name, e.g. "minimal-valid.yaml"
+	Description string // what the fixture exercises
+	Content     []byte
+}
+
+// GenerateFixtures derives test fixtures from a struct: a minimal valid
+// config setting only the fields that need a value, a fully populated one
+// setting every field, and one invalid config per validate rule that can be
+// violated, each breaking only that rule.
+//
+// Values are derived from the struct tags alone. Rules that depend on other
+// fields (such as required_if) and types without a YAML scalar form are not
+// evaluated, so such fields may need editing by hand.
+func GenerateFixtures(doc StructDoc) []Fixture {
+	fixtures := []Fixture{
+		renderFixture(doc.Name, "minimal-valid.yaml", "Minimal valid config: only fields without a usable zero value or default.",
+			doc.Fields, modeMinimal, nil),
+		renderFixture(doc.Name, "fully-populated.yaml", "Fully populated config: every field set to a valid value.",
+			doc.Fields, modeFull, nil),
+	}
+
+	for _, v := range collectViolations(doc.Fields, "") {
+		name := "invalid-" + v.path + "-" + v.rule + ".yaml"
+		if v.dive {
+			name = "invalid-" + v.path + "-dive-" + v.rule + ".yaml"
+		}
+		desc := fmt.Sprintf("Invalid config: %s violates %s.", v.path, v.ruleText)
+
+		fixtures = append(fixtures, renderFixture(doc.Name, name, desc, doc.Fields, modeFull, &v))
+	}
+
+	return fixtures
+}
+
+// fixtureMode selects which fields a fixture sets.
+type fixtureMode int
+
+const (
+	modeMinimal fixtureMode = iota
+	modeFull
+)
+
+// violation replaces the value at path to break one rule.
+type violation struct {
+	path     string
+	rule     string // rule name, used in the file name
+	ruleText string // rule with parameter, used in the description
+	dive     bool   // the rule applies to elements
+	omit     bool   // leave the field out instead of setting value
+	value    string // rendered YAML value
+}
+
+// renderFixture renders fields as a YAML document.
+func renderFixture(structName, name, desc string, fields []FieldInfo, mode fixtureMode, v *violation) Fixture {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by fuda-doc fixtures from %s.\n", structName)
+	fmt.Fprintf(&sb, "# %s\n", desc)
+
+	body := renderFields(fields, "", 0, mode, v)
+	if body == "" {
+		body = "{}\n"
+	}
+	sb.WriteString(body)
+
+	return Fixture{Name: name, Description: desc, Content: []byte(sb.String())}
+}
+
+// renderFields renders the YAML lines of fields at the given indent level.
+func renderFields(fields []FieldInfo, prefix string, indent int, mode fixtureMode, v *violation) string {
+	var sb strings.Builder
+	pad := strings.Repeat("  ", indent)
+
+	for i := range fields {
+		f := &fields[i]
+
+		key, inline, ok := fixtureKey(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			sb.WriteString(renderFields(f.Nested, prefix, indent, mode, v))
+
+			continue
+		}
+
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if v != nil && v.path == path {
+			if !v.omit {
+				fmt.Fprintf(&sb, "%s%s: %s\n", pad, key, v.value)
+			}
+
+			continue
+		}
+
+		rules := parseRules(f.Tags["validate"])
+		typ := docutil.MapForm(f.Type)
+
+		if len(f.Nested) > 0 && !isCollection(typ) {
+			isPtr := strings.HasPrefix(typ, "*")
+			if mode == modeMinimal && isPtr && !rules.has("required") {
+				continue
+			}
+
+			children := renderFields(f.Nested, path, indent+1, mode, v)
+			switch {
+			case children != "":
+				fmt.Fprintf(&sb, "%s%s:\n%s", pad, key, children)
+			case isPtr || mode == modeFull:
+				fmt.Fprintf(&sb, "%s%s: {}\n", pad, key)
+			}
+
+			continue
+		}
+
+		def := f.Tags["default"]
+		if mode == modeMinimal && (def != "" || !rules.zeroFails(typ)) {
+			continue
+		}
+
+		value, ok := validValue(typ, def, rules)
+		if !ok {
+			if mode == modeMinimal {
+				fmt.Fprintf(&sb, "%s# %s: (set a valid %s value)\n", pad, key, f.Type)
+			}
+
+			continue
+		}
+		fmt.Fprintf(&sb, "%s%s: %s\n", pad, key, value)
+	}
+
+	return sb.String()
+}
+
+// collectViolations returns one violation per breakable rule in fields.
+func collectViolations(fields []FieldInfo, prefix string) []violation {
+	var out []violation
+
+	for i := range fields {
+		f := &fields[i]
+
+		key, inline, ok := fixtureKey(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			out = append(out, collectViolations(f.Nested, prefix)...)
+
+			continue
+		}
+
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		rules := parseRules(f.Tags["validate"])
+		if rules.alternatives {
+			continue
+		}
+		typ := docutil.MapForm(f.Type)
+
+		if len(f.Nested) > 0 && !isCollection(typ) {
+			if strings.HasPrefix(typ, "*") && rules.has("required") {
+				out = append(out, violation{path: path, rule: "required", ruleText: "required", omit: true})
+			}
+			out = append(out, collectViolations(f.Nested, path)...)
+
+			continue
+		}
+
+		def := f.Tags["default"]
+		for _, r := range rules.field {
+			if r.name == "required" {
+				if def == "" && !rules.omitempty {
+					out = append(out, violation{path: path, rule: "required", ruleText: "required", omit: true})
+				}
+
+				continue
+			}
+
+			value, zero, ok := invalidValue(typ, r, rules)
+			if !ok || (zero && (def != "" || rules.omitempty)) {
+				continue // a zero value would be skipped or replaced by the default
+			}
+			out = append(out, violation{path: path, rule: r.name, ruleText: r.String(), value: value})
+		}
+
+		for _, r := range rules.elem {
+			value, ok := invalidElemValue(typ, r, rules)
+			if !ok {
+				continue
+			}
+			out = append(out, violation{path: path, rule: r.name, ruleText: "dive," + r.String(), dive: true, value: value})
+		}
+	}
+
+	return out
+}
+
+// fixtureKey returns the YAML key that yaml.v3 decodes f from, whether f is
+// inlined, and false if f is not loaded from YAML.
+func fixtureKey(f *FieldInfo) (key string, inline bool, ok bool) {
+	if f.Name == "" || f.Name[0] < 'A' || f.Name[0] > 'Z' {
+		return "", false, false
+	}
+
+	name, opts, _ := strings.Cut(f.Tags["yaml"], ",")
+	switch {
+	case name == "-":
+		return "", false, false
+	case slices.Contains(strings.Split(opts, ","), "inline"):
+		return "", true, len(f.Nested) > 0
+	case name == "":
+		return strings.ToLower(f.Name), false, true
+	default:
+		return name, false, true
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Validate rules
+// ---------------------------------------------------------------------------
+
+// rule is one validate rule, such as min=1.
+type rule struct {
+	name  string
+	param string
+}
+
+func (r rule) String() string {
+	if r.param == "" {
+		return r.name
+	}
+
+	return r.name + "=" + r.param
+}
+
+// fieldRules holds the parsed validate tag of a field.
+type fieldRules struct {
+	field        []rule // rules on the field itself
+	elem         []rule // rules after dive, on elements
+	omitempty    bool
+	elemOmit     bool
+	alternatives bool // uses "|", which fixtures do not evaluate
+}
+
+// parseRules parses a validate tag.
+func parseRules(tag string) fieldRules {
+	var fr fieldRules
+	if strings.Contains(tag, "|") {
+		fr.alternatives = true
+	}
+
+	dived := false
+	for part := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch {
+		case name == "":
+			continue
+		case name == "dive":
+			if dived {
+				return fr // nested dives are not evaluated
+			}
+			dived = true
+		case name == "omitempty" && !dived:
+			fr.omitempty = true
+		case name == "omitempty":
+			fr.elemOmit = true
+		case dived:
+			fr.elem = append(fr.elem, rule{name: name, param: param})
+		default:
+			fr.field = append(fr.field, rule{name: name, param: param})
+		}
+	}
+
+	return fr
+}
+
+// has reports whether the field rules include name.
+func (fr fieldRules) has(name string) bool {
+	return slices.ContainsFunc(fr.field, func(r rule) bool { return r.name == name })
+}
+
+// zeroBoundFails maps the bound rules to whether the zero value fails
+// them, given their parameter.
+var zeroBoundFails = map[string]func(p float64) bool{
+	"min": func(p float64) bool { return p > 0 },
+	"gte": func(p float64) bool { return p > 0 },
+	"len": func(p float64) bool { return p != 0 },
+	"eq":  func(p float64) bool { return p != 0 },
+	"gt":  func(p float64) bool { return p >= 0 },
+	"lt":  func(p float64) bool { return p <= 0 },
+	"lte": func(p float64) bool { return p < 0 },
+	"max": func(p float64) bool { return p < 0 },
+}
+
+// zeroFails reports whether the zero value of typ fails the field rules.
+func (fr fieldRules) zeroFails(typ string) bool {
+	if fr.omitempty || fr.alternatives {
+		return false
+	}
+
+	kind := scalarKind(typ)
+	isLen := kind == kindString || isCollection(typ)
+
+	for _, r := range fr.field {
+		if fails, ok := zeroBoundFails[r.name]; ok {
+			if p, ok := numParam(r.param, kind, isLen); ok && fails(p) {
+				return true
+			}
+
+			continue
+		}
+
+		switch r.name {
+		case "required":
+			return true
+		case "oneof":
+			if isLen || !slices.Contains(oneofValues(r.param), zeroScalar(kind)) {
+				return true
+			}
+		default:
+			if _, ok := formatSamples[r.name]; ok && kind == kindString && !isCollection(typ) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// zeroScalar returns the zero value of a non-string scalar kind, as written
+// in a oneof rule.
+func zeroScalar(kind valueKind) string {
+	switch kind { //nolint:exhaustive // other kinds are numbers
+	case kindBool:
+		return "false"
+	case kindDuration:
+		return "0s"
+	default:
+		return "0"
+	}
+}
+
+// numParam parses a numeric rule parameter, as a duration for durations.
+func numParam(param string, kind valueKind, isLen bool) (float64, bool) {
+	if kind == kindDuration && !isLen {
+		d, err := time.ParseDuration(param)
+
+		return float64(d), err == nil
+	}
+
+	f, err := strconv.ParseFloat(param, 64)
+
+	return f, err == nil
+}
+
+// oneofValues splits a oneof parameter; single quotes group a value with spaces.
+func oneofValues(param string) []string {
+	var values []string
+	for len(param) > 0 {
+		param = strings.TrimLeft(param, " ")
+		if param == "" {
+			break
+		}
+		if param[0] == '\'' {
+			if end := strings.IndexByte(param[1:], '\''); end >= 0 {
+				values = append(values, param[1:end+1])
+				param = param[end+2:]
+
+				continue
+			}
+		}
+
+		value, rest, _ := strings.Cut(param, " ")
+		values = append(values, value)
+		param = rest
+	}
+
+	return values
+}
+
+// formatSamples maps string format rules to a valid and an invalid value.
+var formatSamples = map[string][2]string{
+	"email":            {"user@example.com", "not-an-email"},
+	"url":              {"https://example.com", "not a url"},
+	"http_url":         {"https://example.com", "ftp://example.com"},
+	"uri":              {"https://example.com", "not a uri"},
+	"hostname":         {"example.com", "-invalid-"},
+	"hostname_rfc1123": {"example.com", "-invalid-"},
+	"fqdn":             {"example.com", "not a fqdn"},
+	"hostname_port":    {"localhost:8080", "localhost"},
+	"ip":               {"192.0.2.1", "999.0.0.1"},
+	"ipv4":             {"192.0.2.1", "2001:db8::1"},
+	"ipv6":             {"2001:db8::1", "192.0.2.1"},
+	"ip_addr":          {"192.0.2.1", "not-an-ip"},
+	"cidr":             {"10.0.0.0/8", "10.0.0.0"},
+	"uuid":             {"123e4567-e89b-42d3-a456-426614174000", "not-a-uuid"},
+	"uuid4":            {"123e4567-e89b-42d3-a456-426614174000", "not-a-uuid"},
+	"alpha":            {"example", "example1"},
+	"alphanum":         {"example1", "example-1"},
+	"numeric":          {"12345", "abc"},
+	"number":           {"12345", "abc"},
+	"lowercase":        {"example", "EXAMPLE"},
+	"uppercase":        {"EXAMPLE", "example"},
+	"base64":           {"ZXhhbXBsZQ==", "not base64!"},
+	"json":             {`{"key":"value"}`, "{not json"},
+}
+
+// ---------------------------------------------------------------------------
+// Values
+// ---------------------------------------------------------------------------
+
+// valueKind classifies scalar types.
+type valueKind int
+
+const (
+	kindUnknown valueKind = iota
+	kindString
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindDuration
+)
+
+// scalarKind returns the kind of a scalar type name, ignoring a pointer.
+func scalarKind(typ string) valueKind {
+	switch strings.TrimPrefix(typ, "*") {
+	case "string":
+		return kindString
+	case "bool":
+		return kindBool
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return kindInt
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		return kindUint
+	case "float32", "float64":
+		return kindFloat
+	case "time.Duration", "fuda.Duration":
+		return kindDuration
+	default:
+		return kindUnknown
+	}
+}
+
+// isCollection reports whether typ is a slice or map type.
+func isCollection(typ string) bool {
+	typ = strings.TrimPrefix(typ, "*")
+
+	return strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[")
+}
+
+// elemType returns the element type of a slice or map of scalars.
+func elemType(typ string) (string, bool) {
+	typ = strings.TrimPrefix(typ, "*")
+	switch {
+	case typ == "[]byte":
+		return "", false
+	case strings.HasPrefix(typ, "[]"):
+		elem := typ[2:]
+
+		return elem, scalarKind(elem) != kindUnknown
+	case strings.HasPrefix(typ, "map[string]"):
+		elem := strings.TrimPrefix(typ, "map[string]")
+
+		return elem, scalarKind(elem) != kindUnknown
+	default:
+		return "", false
+	}
+}
+
+// validValue returns a rendered valid value for a field, preferring its
+// default tag.
+func validValue(typ, def string, fr fieldRules) (string, bool) {
+	if isCollection(typ) {
+		if docutil.IsJSONDefault(def) {
+			return strings.TrimSpace(def), true
+		}
+		elem, ok := elemType(typ)
+		if !ok {
+			return "", false
+		}
+		if def != "" {
+			return renderDefaultCollection(typ, elem, def), true
+		}
+
+		count := collectionCount(fr.field)
+		items := make([]string, count)
+		for i := range items {
+			raw, ok := sampleScalar(scalarKind(elem), fr.elem)
+			if !ok {
+				return "", false
+			}
+			items[i] = renderScalar(scalarKind(elem), raw)
+		}
+
+		return renderCollection(typ, items), true
+	}
+
+	kind := scalarKind(typ)
+	if kind == kindUnknown {
+		if def == "" {
+			return "", false
+		}
+
+		return def, true
+	}
+	if def != "" {
+		return renderScalar(kind, def), true
+	}
+
+	raw, ok := sampleScalar(kind, fr.field)
+	if !ok {
+		return "", false
+	}
+
+	return renderScalar(kind, raw), true
+}
+
+// collectionCount returns the number of elements a valid collection needs.
+func collectionCount(rules []rule) int {
+	count := 1
+	for _, r := range rules {
+		n, err := strconv.Atoi(r.param)
+		if err != nil {
+			continue
+		}
+		switch r.name {
+		case "min", "gte", "len", "eq":
+			count = max(count, n)
+			if r.name == "len" || r.name == "eq" {
+				return n
+			}
+		case "gt":
+			count = max(count, n+1)
+		case "max", "lte":
+			count = min(count, n)
+		case "lt":
+			count = min(count, n-1)
+		}
+	}
+
+	return max(count, 0)
+}
+
+// sampleScalar returns a raw value of kind satisfying rules.
+func sampleScalar(kind valueKind, rules []rule) (string, bool) {
+	for _, r := range rules {
+		if r.name == "oneof" {
+			if values := oneofValues(r.param); len(values) > 0 {
+				return values[0], true
+			}
+		}
+	}
+
+	switch kind {
+	case kindString:
+		return sampleString(rules), true
+	case kindBool:
+		return "true", true
+	case kindInt, kindUint, kindFloat:
+		return sampleNumber(kind, rules), true
+	case kindDuration:
+		return sampleDuration(rules), true
+	default:
+		return "", false
+	}
+}
+
+// sampleString returns a string satisfying the format and length rules.
+func sampleString(rules []rule) string {
+	s := "example"
+	for _, r := range rules {
+		if sample, ok := formatSamples[r.name]; ok {
+			return sample[0]
+		}
+		if r.name == "precision" {
+			return "1"
+		}
+	}
+
+	for _, r := range rules {
+		n, err := strconv.Atoi(r.param)
+		if err != nil {
+			continue
+		}
+		switch r.name {
+		case "len", "eq":
+			return strings.Repeat("a", n)
+		case "min", "gte":
+			if len(s) < n {
+				s += strings.Repeat("x", n-len(s))
+			}
+		case "gt":
+			if len(s) <= n {
+				s += strings.Repeat("x", n+1-len(s))
+			}
+		case "max", "lte":
+			if len(s) > n {
+				s = s[:n]
+			}
+		case "lt":
+			if len(s) >= n {
+				s = s[:max(n-1, 0)]
+			}
+		}
+	}
+
+	return s
+}
+
+// numberRange is the range of numbers allowed by bound rules. Open ends
+// exclude their bound.
+type numberRange struct {
+	lo, hi         float64
+	loOpen, hiOpen bool
+}
+
+// numberBounds maps the bound rules to how they narrow a numberRange, given
+// their parameter and the step of exclusive bounds.
+var numberBounds = map[string]func(nr *numberRange, p, step float64){
+	"min": func(nr *numberRange, p, _ float64) { nr.lo = math.Max(nr.lo, p) },
+	"gte": func(nr *numberRange, p, _ float64) { nr.lo = math.Max(nr.lo, p) },
+	"gt":  func(nr *numberRange, p, step float64) { nr.lo, nr.loOpen = math.Max(nr.lo, p+step), step == 0 },
+	"max": func(nr *numberRange, p, _ float64) { nr.hi = math.Min(nr.hi, p) },
+	"lte": func(nr *numberRange, p, _ float64) { nr.hi = math.Min(nr.hi, p) },
+	"lt":  func(nr *numberRange, p, step float64) { nr.hi, nr.hiOpen = math.Min(nr.hi, p-step), step == 0 },
+}
+
+// sampleNumber returns a number within the bounds of rules, 1 if allowed.
+func sampleNumber(kind valueKind, rules []rule) string {
+	// Exclusive bounds step by one for integers; floats pick a value
+	// strictly inside them.
+	step := 1.0
+	if kind == kindFloat {
+		step = 0
+	}
+
+	nr := numberRange{lo: math.Inf(-1), hi: math.Inf(1)}
+	if kind == kindUint {
+		nr.lo = 0
+	}
+
+	for _, r := range rules {
+		p, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			continue
+		}
+		if r.name == "eq" || r.name == "len" {
+			return formatNumber(kind, p)
+		}
+		if narrow, ok := numberBounds[r.name]; ok {
+			narrow(&nr, p, step)
+		}
+	}
+
+	return formatNumber(kind, nr.clamp(1))
+}
+
+// clamp returns v if the range allows it, or the allowed value nearest to it.
+func (nr numberRange) clamp(v float64) float64 {
+	tooLow := v < nr.lo || (v == nr.lo && nr.loOpen)
+	tooHigh := v > nr.hi || (v == nr.hi && nr.hiOpen)
+	switch {
+	case (tooLow || tooHigh) && nr.loOpen && nr.hiOpen:
+		return (nr.lo + nr.hi) / 2
+	case tooLow && nr.loOpen:
+		return nr.lo + 1
+	case tooLow:
+		return nr.lo
+	case tooHigh && nr.hiOpen:
+		return nr.hi - 1
+	case tooHigh:
+		return nr.hi
+	default:
+		return v
+	}
+}
+
+// formatNumber renders v for kind.
+func formatNumber(kind valueKind, v float64) string {
+	if kind == kindFloat {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// sampleDuration returns a duration within the bounds of rules, 30s if allowed.
+func sampleDuration(rules []rule) string {
+	d := 30 * time.Second
+	for _, r := range rules {
+		p, err := time.ParseDuration(r.param)
+		if err != nil {
+			continue
+		}
+		switch r.name {
+		case "eq":
+			return p.String()
+		case "min", "gte":
+			d = max(d, p)
+		case "gt":
+			if d <= p {
+				d = p + time.Second
+			}
+		case "max", "lte":
+			d = min(d, p)
+		case "lt":
+			if d >= p {
+				d = p / 2
+			}
+		}
+	}
+
+	return d.String()
+}
+
+// invalidValue returns a rendered value of typ breaking r, and whether the
+// value is the zero value.
+func invalidValue(typ string, r rule, fr fieldRules) (value string, zero bool, ok bool) {
+	if isCollection(typ) {
+		elem, ok := elemType(typ)
+		if !ok {
+			return "", false, false
+		}
+		count, ok := invalidCount(r)
+		if !ok {
+			return "", false, false
+		}
+
+		items := make([]string, count)
+		for i := range items {
+			raw, ok := sampleScalar(scalarKind(elem), fr.elem)
+			if !ok {
+				return "", false, false
+			}
+			items[i] = renderScalar(scalarKind(elem), raw)
+		}
+
+		return renderCollection(typ, items), count == 0, true
+	}
+
+	kind := scalarKind(typ)
+	raw, ok := invalidScalar(kind, r)
+	if !ok {
+		return "", false, false
+	}
+
+	return renderScalar(kind, raw), isZeroScalar(kind, raw), true
+}
+
+// invalidElemValue returns a rendered collection whose single element
+// breaks the element rule r.
+func invalidElemValue(typ string, r rule, fr fieldRules) (string, bool) {
+	elem, ok := elemType(typ)
+	if !ok || r.name == "required" {
+		return "", false
+	}
+
+	kind := scalarKind(elem)
+	raw, ok := invalidScalar(kind, r)
+	if !ok || (fr.elemOmit && isZeroScalar(kind, raw)) {
+		return "", false
+	}
+
+	return renderCollection(typ, []string{renderScalar(kind, raw)}), true
+}
+
+// invalidCount returns a collection size breaking the length rule r.
+func invalidCount(r rule) (int, bool) {
+	n, err := strconv.Atoi(r.param)
+	if err != nil {
+		return 0, false
+	}
+
+	switch r.name {
+	case "min", "gte":
+		return n - 1, n > 0
+	case "gt":
+		return n, n >= 0
+	case "max", "lte", "len", "eq":
+		return n + 1, true
+	case "lt":
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// invalidScalar returns a raw value of kind breaking r.
+func invalidScalar(kind valueKind, r rule) (string, bool) {
+	switch kind {
+	case kindString:
+		return invalidString(r)
+	case kindInt, kindUint, kindFloat:
+		return invalidNumber(kind, r)
+	case kindDuration:
+		return invalidDuration(r)
+	default:
+		return "", false
+	}
+}
+
+// invalidString returns a string breaking r.
+func invalidString(r rule) (string, bool) {
+	if sample, ok := formatSamples[r.name]; ok {
+		return sample[1], true
+	}
+
+	switch r.name {
+	case "oneof":
+		values := oneofValues(r.param)
+		s := "invalid"
+		for slices.Contains(values, s) {
+			s += "-value"
+		}
+
+		return s, true
+	case "precision":
+		n, err := strconv.Atoi(r.param)
+
+		return "0." + strings.Repeat("1", n+1), err == nil
+	case "ne":
+		return r.param, true
+	case "eq":
+		return r.param + "x", true
+	}
+
+	n, ok := invalidCount(r)
+	if !ok {
+		return "", false
+	}
+
+	return strings.Repeat("a", n), true
+}
+
+// invalidNumber returns a number breaking r.
+func invalidNumber(kind valueKind, r rule) (string, bool) {
+	if r.name == "oneof" {
+		if kind == kindFloat {
+			return "", false
+		}
+		high := 0.0
+		for _, v := range oneofValues(r.param) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				high = math.Max(high, f)
+			}
+		}
+
+		return formatNumber(kind, high+1), true
+	}
+	if r.name == "precision" {
+		n, err := strconv.Atoi(r.param)
+
+		return "0." + strings.Repeat("1", n+1), kind == kindFloat && err == nil
+	}
+
+	p, err := strconv.ParseFloat(r.param, 64)
+	if err != nil {
+		return "", false
+	}
+
+	var v float64
+	switch r.name {
+	case "min", "gte":
+		v = p - 1
+	case "gt", "lt", "ne":
+		v = p
+	case "max", "lte", "eq", "len":
+		v = p + 1
+	default:
+		return "", false
+	}
+	if kind == kindUint && v < 0 {
+		return "", false
+	}
+
+	return formatNumber(kind, v), true
+}
+
+// invalidDuration returns a duration breaking r.
+func invalidDuration(r rule) (string, bool) {
+	p, err := time.ParseDuration(r.param)
+	if err != nil {
+		return "", false
+	}
+
+	switch r.name {
+	case "min", "gte":
+		return (p / 2).String(), p > 0
+	case "gt", "lt", "ne":
+		return p.String(), true
+	case "max", "lte", "eq":
+		return (p + time.Second).String(), true
+	default:
+		return "", false
+	}
+}
+
+// isZeroScalar reports whether raw is the zero value of kind.
+func isZeroScalar(kind valueKind, raw string) bool {
+	switch kind {
+	case kindString:
+		return raw == ""
+	case kindDuration:
+		d, err := time.ParseDuration(raw)
+
+		return err == nil && d == 0
+	default:
+		f, err := strconv.ParseFloat(raw, 64)
+
+		return err == nil && f == 0
+	}
+}
+
+// renderScalar renders a raw value as YAML, quoting strings and durations.
+func renderScalar(kind valueKind, raw string) string {
+	if kind == kindString || kind == kindDuration {
+		return strconv.Quote(raw)
+	}
+
+	return raw
+}
+
+// renderCollection renders items as a flow sequence, or as a flow mapping
+// with keys key1, key2, ... for map types.
+func renderCollection(typ string, items []string) string {
+	if !strings.HasPrefix(strings.TrimPrefix(typ, "*"), "map[") {
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	pairs := make([]string, len(items))
+	for i, item := range items {
+		pairs[i] = fmt.Sprintf("key%d: %s", i+1, item)
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// renderDefaultCollection renders a default tag ("a,b" or "k:v,k:v") of a
+// collection type.
+func renderDefaultCollection(typ, elem, def string) string {
+	kind := scalarKind(elem)
+	isMap := strings.HasPrefix(strings.TrimPrefix(typ, "*"), "map[")
+
+	var items []string
+	for part := range strings.SplitSeq(def, ",") {
+		part = strings.TrimSpace(part)
+		if !isMap {
+			items = append(items, renderScalar(kind, part))
+
+			continue
+		}
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			items = append(items, strconv.Quote(strings.TrimSpace(k))+": "+renderScalar(kind, strings.TrimSpace(v)))
+		}
+	}
+
+	if isMap {
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+
+	return "[" + strings.Join(items, ", ") + "]"
+}
@@ -0,0 +1,227 @@
+package docgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const fixtureSource = `package cfg
+
+import "time"
+
+type Config struct {
+	Name     string            ` + "`" + `yaml:"name" validate:"required,min=3,max=20"` + "`" + `
+	Level    string            ` + "`" + `yaml:"level" validate:"oneof=debug info"` + "`" + `
+	Ratio    float64           ` + "`" + `yaml:"ratio" validate:"gt=0,lt=1"` + "`" + `
+	Port     int               ` + "`" + `yaml:"port" default:"8080" validate:"min=1024"` + "`" + `
+	Timeout  time.Duration     ` + "`" + `yaml:"timeout" validate:"min=1s,max=1m"` + "`" + `
+	Hosts    []string          ` + "`" + `yaml:"hosts" validate:"min=1,dive,hostname"` + "`" + `
+	Site     string            ` + "`" + `yaml:"site" validate:"omitempty,url"` + "`" + `
+	TLS      *TLS              ` + "`" + `yaml:"tls" validate:"required"` + "`" + `
+	Optional *TLS              ` + "`" + `yaml:"optional"` + "`" + `
+	Skip     string            ` + "`" + `yaml:"-" validate:"required"` + "`" + `
+	Plain    string
+}
+
+type TLS struct {
+	Cert string ` + "`" + `yaml:"cert" validate:"required"` + "`" + `
+}
+`
+
+// generateFixtures parses fixtureSource and returns its fixtures by name.
+func generateFixtures(t *testing.T) map[string]string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte(fixtureSource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+
+	out := make(map[string]string)
+	for _, f := range docgen.GenerateFixtures(docs[0]) {
+		out[f.Name] = string(f.Content)
+	}
+
+	return out
+}
+
+func TestGenerateFixtures_Minimal(t *testing.T) {
+	t.Parallel()
+
+	got := generateFixtures(t)["minimal-valid.yaml"]
+	want := `# Generated by fuda-doc fixtures from Config.
+# Minimal valid config: only fields without a usable zero value or default.
+name: "example"
+level: "debug"
+ratio: 0.5
+timeout: "30s"
+hosts: ["example.com"]
+tls:
+  cert: "example"
+`
+	if got != want {
+		t.Errorf("minimal-valid.yaml =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateFixtures_FullyPopulated(t *testing.T) {
+	t.Parallel()
+
+	got := generateFixtures(t)["fully-populated.yaml"]
+	for _, line := range []string{
+		"port: 8080\n",
+		`site: "https://example.com"` + "\n",
+		"optional:\n  cert: \"example\"\n",
+		`plain: "example"` + "\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("fully-populated.yaml missing %q:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "skip") {
+		t.Errorf("fully-populated.yaml sets a yaml:\"-\" field:\n%s", got)
+	}
+}
+
+func TestGenerateFixtures_Invalid(t *testing.T) {
+	t.Parallel()
+
+	fixtures := generateFixtures(t)
+
+	tests := map[string]string{
+		"invalid-name-required.yaml":          "",
+		"invalid-name-min.yaml":               `name: "aa"`,
+		"invalid-name-max.yaml":               `name: "aaaaaaaaaaaaaaaaaaaaa"`,
+		"invalid-level-oneof.yaml":            `level: "invalid"`,
+		"invalid-ratio-gt.yaml":               "ratio: 0\n",
+		"invalid-ratio-lt.yaml":               "ratio: 1\n",
+		"invalid-port-min.yaml":               "port: 1023\n",
+		"invalid-timeout-min.yaml":            `timeout: "500ms"`,
+		"invalid-timeout-max.yaml":            `timeout: "1m1s"`,
+		"invalid-hosts-min.yaml":              "hosts: []\n",
+		"invalid-hosts-dive-hostname.yaml":    `hosts: ["-invalid-"]`,
+		"invalid-site-url.yaml":               `site: "not a url"`,
+		"invalid-tls-required.yaml":           "",
+		"invalid-tls.cert-required.yaml":      "",
+		"invalid-optional.cert-required.yaml": "",
+	}
+
+	for name, line := range tests {
+		content, ok := fixtures[name]
+		if !ok {
+			t.Errorf("missing fixture %s", name)
+
+			continue
+		}
+		if line != "" && !strings.Contains(content, line) {
+			t.Errorf("%s missing %q:\n%s", name, line, content)
+		}
+	}
+
+	if got := fixtures["invalid-name-required.yaml"]; strings.Contains(got, "name:") {
+		t.Errorf("invalid-name-required.yaml still sets name:\n%s", got)
+	}
+	if got := fixtures["invalid-tls-required.yaml"]; strings.Contains(got, "tls:") {
+		t.Errorf("invalid-tls-required.yaml still sets tls:\n%s", got)
+	}
+
+	// A zero value would be replaced by the default or skipped by omitempty.
+	for _, name := range []string{"invalid-port-required.yaml", "invalid-site-omitempty.yaml"} {
+		if _, ok := fixtures[name]; ok {
+			t.Errorf("unexpected fixture %s", name)
+		}
+	}
+
+	if want := 2 + len(tests); len(fixtures) != want {
+		t.Errorf("got %d fixtures, want %d", len(fixtures), want)
+	}
+}
+
+func TestGenerateFixtures_OrderedMap(t *testing.T) {
+	t.Parallel()
+
+	const source = `package cfg
+
+import "github.com/arloliu/fuda"
+
+type Config struct {
+	Weights fuda.OrderedMap[string, int] ` + "`" + `yaml:"weights" default:"zeta:3,alpha:1"` + "`" + `
+	Stages  *fuda.OrderedMap[string, []string] ` + "`" + `yaml:"stages"` + "`" + `
+}
+`
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+
+	fields := docs[0].Fields
+	if fields[0].Type != "fuda.OrderedMap[string, int]" || fields[1].Type != "*fuda.OrderedMap[string, []string]" {
+		t.Fatalf("types = %q, %q", fields[0].Type, fields[1].Type)
+	}
+
+	var full string
+	for _, f := range docgen.GenerateFixtures(docs[0]) {
+		if f.Name == "fully-populated.yaml" {
+			full = string(f.Content)
+		}
+	}
+	if !strings.Contains(full, `weights: {"zeta": 3, "alpha": 1}`) {
+		t.Errorf("fully-populated.yaml does not keep the default order:\n%s", full)
+	}
+}
+
+func TestGenerateFixtures_JSONDefault(t *testing.T) {
+	t.Parallel()
+
+	const source = `package cfg
+
+type Config struct {
+	Servers   []Server          ` + "`" + `yaml:"servers" default:"[{\"host\":\"a\"},{\"host\":\"b\"}]"` + "`" + `
+	Endpoints map[string]Server ` + "`" + `yaml:"endpoints" default:"{\"api\":{\"host\":\"x\"}}"` + "`" + `
+}
+
+type Server struct {
+	Host string ` + "`" + `yaml:"host"` + "`" + `
+}
+`
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+
+	var full string
+	for _, f := range docgen.GenerateFixtures(docs[0]) {
+		if f.Name == "fully-populated.yaml" {
+			full = string(f.Content)
+		}
+	}
+	for _, line := range []string{
+		`servers: [{"host":"a"},{"host":"b"}]` + "\n",
+		`endpoints: {"api":{"host":"x"}}` + "\n",
+	} {
+		if !strings.Contains(full, line) {
+			t.Errorf("fully-populated.yaml missing %q:\n%s", line, full)
+		}
+	}
+}
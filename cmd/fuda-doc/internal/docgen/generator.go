@@ -15,6 +15,10 @@ const (
 	FormatMarkdown OutputFormat = iota
 	// FormatASCII outputs terminal-friendly documentation with ANSI colors.
 	FormatASCII
+	// FormatHTML outputs a standalone HTML page.
+	FormatHTML
+	// FormatSchema outputs a JSON Schema of the config file.
+	FormatSchema
 )
 
 // StructDoc holds parsed documentation data for a single struct.
@@ -110,6 +114,14 @@ func Generate(structName, path string, w io.Writer, format OutputFormat) error {
 	case FormatASCII:
 		printer := NewASCIIPrinter(w)
 		printer.Print(structName, doc, fields)
+	case FormatHTML:
+		printer := NewHTMLPrinter(w)
+
+		return printer.Print(structName, doc, fields)
+	case FormatSchema:
+		printer := NewSchemaPrinter(w)
+
+		return printer.Print(structName, doc, fields)
 	default:
 		return fmt.Errorf("unsupported output format: %d", format)
 	}
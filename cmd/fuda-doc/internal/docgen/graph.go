@@ -0,0 +1,262 @@
+package docgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dependency is an edge of the field dependency graph: the value of To is
+// computed from From by a dsn, ref, refFrom, or expr tag.
+type Dependency struct {
+	From string // Go field path, or "env:KEY" or "ref:URI" for inline inputs
+	To   string // Go field path of the dependent field
+	Tag  string // tag of To that reads From
+	// Late is set if From is processed after To, so To sees its zero
+	// value. Template and refFrom sources must be declared earlier.
+	Late bool
+	// Missing is set if From names no field of the struct.
+	Missing bool
+}
+
+// External reports whether From is an env var or URI rather than a field.
+func (d Dependency) External() bool {
+	return strings.HasPrefix(d.From, "env:") || strings.HasPrefix(d.From, "ref:")
+}
+
+// fieldRefPattern matches field references such as .Database.Host in a
+// template pipeline, after string literals are removed.
+var fieldRefPattern = regexp.MustCompile(`(?:^|[^\w.)\]])\.([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*)`)
+
+// stringLiteralPattern matches the string literals of a template pipeline.
+var stringLiteralPattern = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`")
+
+// FindDependencies returns the dependencies between the fields of doc, in
+// field order. Template references in dsn, ref, and expr tags and refFrom
+// paths are resolved against the struct holding the tag, as fuda does.
+func FindDependencies(doc StructDoc) []Dependency {
+	order := make(map[string]int) // field path → processing order
+	var number func(fields []FieldInfo, prefix string)
+	number = func(fields []FieldInfo, prefix string) {
+		for i := range fields {
+			path := joinPath(prefix, fields[i].Name)
+			order[path] = len(order)
+			number(fields[i].Nested, path)
+		}
+	}
+	number(doc.Fields, "")
+
+	var deps []Dependency
+	add := func(from, to, tag string) {
+		d := Dependency{From: from, To: to, Tag: tag}
+		if !d.External() {
+			seq, ok := order[from]
+			d.Missing = !ok
+			d.Late = ok && seq > order[to]
+		}
+		deps = append(deps, d)
+	}
+
+	var walk func(fields []FieldInfo, prefix string)
+	walk = func(fields []FieldInfo, prefix string) {
+		for i := range fields {
+			f := &fields[i]
+			path := joinPath(prefix, f.Name)
+
+			if from, ok := f.Tags["refFrom"]; ok && from != "" {
+				from, _, _ = strings.Cut(from, ",")
+				add(joinPath(prefix, strings.TrimSpace(from)), path, "refFrom")
+			}
+			for _, tag := range []string{"ref", "dsn", "expr"} {
+				for _, ref := range templateRefs(f.Tags[tag]) {
+					if !strings.HasPrefix(ref, "env:") && !strings.HasPrefix(ref, "ref:") {
+						ref = joinPath(prefix, ref)
+					}
+					add(ref, path, tag)
+				}
+			}
+
+			walk(f.Nested, path)
+		}
+	}
+	walk(doc.Fields, "")
+
+	return deps
+}
+
+// templateRefs returns the inputs of the ${...} pipelines in tag, in order
+// and without duplicates: field paths relative to the struct, and inline
+// ${env:KEY} and ${ref:uri} inputs with their prefix.
+func templateRefs(tag string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for rest := tag; ; {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			break
+		}
+		rest = rest[start+2:]
+		end := closingBrace(rest)
+		if end < 0 {
+			break
+		}
+		pipeline := strings.TrimSpace(rest[:end])
+		rest = rest[end+1:]
+
+		if strings.HasPrefix(pipeline, "env:") || strings.HasPrefix(pipeline, "ref:") {
+			add(pipeline)
+
+			continue
+		}
+		pipeline = stringLiteralPattern.ReplaceAllString(pipeline, `""`)
+		for _, m := range fieldRefPattern.FindAllStringSubmatch(pipeline, -1) {
+			add(m[1])
+		}
+	}
+
+	return refs
+}
+
+// closingBrace returns the index of the brace closing a ${ opened before
+// s, skipping nested braces and quoted strings, or -1 if there is none.
+func closingBrace(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// FormatDOT renders deps as a Graphviz digraph. Inline env and ref inputs
+// are ellipses, late edges are red and dashed, and missing fields are red.
+func FormatDOT(structName string, deps []Dependency) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotID(structName))
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for _, n := range graphNodes(deps) {
+		switch {
+		case n.external:
+			fmt.Fprintf(&sb, "  %s [shape=ellipse];\n", dotID(n.name))
+		case n.missing:
+			fmt.Fprintf(&sb, "  %s [color=red, fontcolor=red];\n", dotID(n.name))
+		}
+	}
+
+	for _, d := range deps {
+		attrs := "label=" + dotID(d.Tag)
+		if d.Late {
+			attrs = "label=" + dotID(d.Tag+" (declared later)") + ", color=red, style=dashed"
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [%s];\n", dotID(d.From), dotID(d.To), attrs)
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// FormatMermaid renders deps as a Mermaid flowchart, styled like FormatDOT.
+func FormatMermaid(deps []Dependency) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	ids := make(map[string]string)
+	var missing []string
+	for i, n := range graphNodes(deps) {
+		id := "n" + strconv.Itoa(i)
+		ids[n.name] = id
+		if n.external {
+			fmt.Fprintf(&sb, "  %s([%s])\n", id, mermaidLabel(n.name))
+		} else {
+			fmt.Fprintf(&sb, "  %s[%s]\n", id, mermaidLabel(n.name))
+		}
+		if n.missing {
+			missing = append(missing, id)
+		}
+	}
+
+	var late []string
+	for i, d := range deps {
+		if d.Late {
+			fmt.Fprintf(&sb, "  %s -.->|%s| %s\n", ids[d.From], mermaidLabel(d.Tag+" (declared later)"), ids[d.To])
+			late = append(late, strconv.Itoa(i))
+		} else {
+			fmt.Fprintf(&sb, "  %s -->|%s| %s\n", ids[d.From], mermaidLabel(d.Tag), ids[d.To])
+		}
+	}
+
+	if len(missing) > 0 {
+		sb.WriteString("  classDef missing stroke:red,color:red\n")
+		fmt.Fprintf(&sb, "  class %s missing\n", strings.Join(missing, ","))
+	}
+	if len(late) > 0 {
+		fmt.Fprintf(&sb, "  linkStyle %s stroke:red\n", strings.Join(late, ","))
+	}
+
+	return sb.String()
+}
+
+// graphNode is a node of the rendered graph.
+type graphNode struct {
+	name     string
+	external bool
+	missing  bool
+}
+
+// graphNodes returns the nodes of deps, sorted by name.
+func graphNodes(deps []Dependency) []graphNode {
+	nodes := make(map[string]graphNode)
+	for _, d := range deps {
+		nodes[d.From] = graphNode{name: d.From, external: d.External(), missing: d.Missing}
+		if _, ok := nodes[d.To]; !ok {
+			nodes[d.To] = graphNode{name: d.To}
+		}
+	}
+
+	sorted := make([]graphNode, 0, len(nodes))
+	for _, n := range nodes {
+		sorted = append(sorted, n)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+
+	return sorted
+}
+
+// dotID quotes s as a Graphviz ID.
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// mermaidLabel quotes s as a Mermaid label.
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,98 @@
+package docgen_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const graphConfig = `package config
+
+type Config struct {
+	Database Database ` + "`" + `yaml:"database"` + "`" + `
+	Port     int      ` + "`" + `yaml:"port"` + "`" + `
+	DSN      string   ` + "`" + `dsn:"postgres://${.Database.User}:${env:DB_PASS}@${.Database.Host}:${.Port | default 5432}/${.Name}"` + "`" + `
+	Name     string   ` + "`" + `yaml:"name"` + "`" + `
+	Workers  int      ` + "`" + `fuda:"expr=${.Port} * 2"` + "`" + `
+	Label    string   ` + "`" + `dsn:"${join \".x\" .Tags}-${ref:file:///run/app.name}"` + "`" + `
+}
+
+type Database struct {
+	TokenPath string ` + "`" + `yaml:"token_path"` + "`" + `
+	Token     string ` + "`" + `refFrom:"TokenPath,jsonpath=$.token"` + "`" + `
+	Host      string ` + "`" + `yaml:"host"` + "`" + `
+	User      string ` + "`" + `yaml:"user"` + "`" + `
+	Password  string ` + "`" + `ref:"file://${.Dir}/${.User}.pw"` + "`" + `
+}
+`
+
+func TestFindDependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.go": graphConfig})
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := docgen.FindDependencies(docs[0])
+	want := []docgen.Dependency{
+		{From: "Database.TokenPath", To: "Database.Token", Tag: "refFrom"},
+		{From: "Database.Dir", To: "Database.Password", Tag: "ref", Missing: true},
+		{From: "Database.User", To: "Database.Password", Tag: "ref"},
+		{From: "Database.User", To: "DSN", Tag: "dsn"},
+		{From: "env:DB_PASS", To: "DSN", Tag: "dsn"},
+		{From: "Database.Host", To: "DSN", Tag: "dsn"},
+		{From: "Port", To: "DSN", Tag: "dsn"},
+		{From: "Name", To: "DSN", Tag: "dsn", Late: true},
+		{From: "Port", To: "Workers", Tag: "expr"},
+		{From: "Tags", To: "Label", Tag: "dsn", Missing: true},
+		{From: "ref:file:///run/app.name", To: "Label", Tag: "dsn"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDependencies() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestFormatGraph(t *testing.T) {
+	deps := []docgen.Dependency{
+		{From: "Host", To: "DSN", Tag: "dsn"},
+		{From: "env:DB_PASS", To: "DSN", Tag: "dsn"},
+		{From: "Name", To: "DSN", Tag: "dsn", Late: true},
+		{From: "Dir", To: "Password", Tag: "ref", Missing: true},
+	}
+
+	dot := docgen.FormatDOT("Config", deps)
+	for _, line := range []string{
+		`digraph "Config" {`,
+		`  "env:DB_PASS" [shape=ellipse];`,
+		`  "Dir" [color=red, fontcolor=red];`,
+		`  "Host" -> "DSN" [label="dsn"];`,
+		`  "Name" -> "DSN" [label="dsn (declared later)", color=red, style=dashed];`,
+	} {
+		if !strings.Contains(dot, line+"\n") {
+			t.Errorf("DOT output missing %q:\n%s", line, dot)
+		}
+	}
+
+	want := `flowchart LR
+  n0["DSN"]
+  n1["Dir"]
+  n2["Host"]
+  n3["Name"]
+  n4["Password"]
+  n5(["env:DB_PASS"])
+  n2 -->|"dsn"| n0
+  n5 -->|"dsn"| n0
+  n3 -.->|"dsn (declared later)"| n0
+  n1 -->|"ref"| n4
+  classDef missing stroke:red,color:red
+  class n1 missing
+  linkStyle 2 stroke:red
+`
+	if got := docgen.FormatMermaid(deps); got != want {
+		t.Errorf("FormatMermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,324 @@
+package docgen
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+)
+
+// HTMLPrinter generates a standalone HTML page: styles and scripts are
+// inlined, so the page can be published as a single file, e.g. on an internal
+// wiki. The page has a search box filtering fields, collapsible sections for
+// nested structs, and a YAML example with a copy button.
+type HTMLPrinter struct {
+	w io.Writer
+}
+
+// NewHTMLPrinter creates a new HTMLPrinter that writes to the given writer.
+func NewHTMLPrinter(w io.Writer) *HTMLPrinter {
+	return &HTMLPrinter{w: w}
+}
+
+// htmlPage is the data of htmlTemplate.
+type htmlPage struct {
+	Name   string
+	Doc    template.HTML
+	YAML   string
+	Fields []htmlField
+}
+
+// htmlField is one field of the field reference. Nested structs have Nested
+// set instead of a table row.
+type htmlField struct {
+	Name        string
+	Path        string // dotted YAML path, e.g. "database.pool.size"
+	Type        string
+	Default     string
+	Env         string
+	Sources     []string // ref, refFrom, dsn, and expr tags
+	Validate    string
+	Description template.HTML
+	Search      string // lowercase text matched by the search box
+	Nested      []htmlField
+	NestedType  string
+}
+
+// Print generates the HTML page for the given fields.
+func (p *HTMLPrinter) Print(structName string, doc string, fields []FieldInfo) error {
+	var yaml bytes.Buffer
+	writeYAMLFields(&yaml, fields, 0, true)
+
+	page := htmlPage{
+		Name:   structName,
+		Doc:    descriptionHTML(doc),
+		YAML:   yaml.String(),
+		Fields: htmlFields(fields, ""),
+	}
+
+	return htmlTemplate.Execute(p.w, page)
+}
+
+// htmlFields converts the exported fields under the YAML path prefix.
+func htmlFields(fields []FieldInfo, prefix string) []htmlField {
+	var out []htmlField
+
+	for _, f := range fields {
+		if !docutil.IsExported(f.Name) {
+			continue
+		}
+
+		key := docutil.YAMLKey(&f)
+		if key == "-" {
+			continue
+		}
+
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		hf := htmlField{
+			Name:        f.Name,
+			Path:        path,
+			Type:        f.Type,
+			Default:     f.Tags["default"],
+			Env:         f.Tags["env"],
+			Validate:    f.Tags["validate"],
+			Description: descriptionHTML(f.Description),
+			NestedType:  f.NestedType,
+		}
+
+		if v := f.Tags["ref"]; v != "" {
+			hf.Sources = append(hf.Sources, "ref: "+v)
+		}
+
+		if v := f.Tags["refFrom"]; v != "" {
+			hf.Sources = append(hf.Sources, "refFrom: "+v)
+		}
+
+		if v := f.Tags["dsn"]; v != "" {
+			hf.Sources = append(hf.Sources, "dsn: "+v)
+		}
+
+		if v := f.Tags["expr"]; v != "" {
+			hf.Sources = append(hf.Sources, "expr: "+v)
+		}
+
+		if len(f.Nested) > 0 {
+			hf.Nested = htmlFields(f.Nested, path)
+		}
+
+		hf.Search = strings.ToLower(strings.Join(strings.Fields(strings.Join([]string{
+			f.Name, path, f.Type, hf.Env, strings.Join(hf.Sources, " "), f.Description,
+		}, " ")), " "))
+
+		out = append(out, hf)
+	}
+
+	return out
+}
+
+// descriptionHTML formats a godoc comment as HTML: consecutive lines are
+// joined into paragraphs, and indented lines become preformatted blocks.
+func descriptionHTML(desc string) template.HTML {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	var paragraph, code []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + template.HTMLEscapeString(strings.Join(paragraph, " ")) + "</p>")
+			paragraph = nil
+		}
+
+		if len(code) > 0 {
+			b.WriteString("<pre>" + template.HTMLEscapeString(strings.Join(code, "\n")) + "</pre>")
+			code = nil
+		}
+	}
+
+	for line := range strings.SplitSeq(desc, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t"):
+			if len(paragraph) > 0 {
+				flush()
+			}
+
+			code = append(code, strings.TrimPrefix(strings.TrimPrefix(line, "  "), "\t"))
+		default:
+			if len(code) > 0 {
+				flush()
+			}
+
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flush()
+
+	return template.HTML(b.String()) //nolint:gosec // parts are escaped above
+}
+
+var htmlTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="fuda-doc">
+<title>{{.Name}} Configuration</title>
+<style>
+:root { --fg: #1f2328; --muted: #59636e; --bg: #ffffff; --panel: #f6f8fa; --border: #d1d9e0; --accent: #0969da; }
+@media (prefers-color-scheme: dark) {
+  :root { --fg: #e6edf3; --muted: #9198a1; --bg: #0d1117; --panel: #151b23; --border: #3d444d; --accent: #4493f8; }
+}
+* { box-sizing: border-box; }
+body { margin: 0 auto; max-width: 72rem; padding: 2rem 1.5rem; color: var(--fg); background: var(--bg);
+  font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; }
+h1 { margin-top: 0; }
+h2 { border-bottom: 1px solid var(--border); padding-bottom: .3rem; }
+code, pre { font: 13px/1.45 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+pre { background: var(--panel); border: 1px solid var(--border); border-radius: 6px; padding: .75rem 1rem; overflow-x: auto; }
+.doc p, td p { margin: 0 0 .5rem; }
+.toolbar { display: flex; gap: .5rem; align-items: center; margin-bottom: 1rem; }
+#search { flex: 1; padding: .5rem .75rem; font-size: 15px; color: var(--fg); background: var(--bg);
+  border: 1px solid var(--border); border-radius: 6px; }
+button { padding: .4rem .8rem; color: var(--fg); background: var(--panel); border: 1px solid var(--border);
+  border-radius: 6px; cursor: pointer; font-size: 13px; }
+button:hover { border-color: var(--accent); }
+.example { position: relative; }
+.example button { position: absolute; top: .5rem; right: .5rem; }
+table { width: 100%; border-collapse: collapse; margin: .5rem 0 1rem; }
+th, td { text-align: left; vertical-align: top; padding: .4rem .6rem; border-bottom: 1px solid var(--border); }
+th { color: var(--muted); font-weight: 600; font-size: 13px; }
+td.name code { font-weight: 600; }
+td .path { display: block; color: var(--muted); font-size: 12px; }
+td .source { display: block; }
+details { border-left: 2px solid var(--border); margin: .5rem 0; padding-left: 1rem; }
+summary { cursor: pointer; font-weight: 600; padding: .25rem 0; }
+summary .path { color: var(--muted); font-weight: normal; margin-left: .5rem; }
+.muted { color: var(--muted); }
+.hidden { display: none; }
+#no-match { color: var(--muted); }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{with .Doc}}<div class="doc">{{.}}</div>{{end}}
+
+<h2>Configuration Example</h2>
+<div class="example">
+<button type="button" id="copy-yaml">Copy</button>
+<pre id="yaml">{{.YAML}}</pre>
+</div>
+
+<h2>Field Reference</h2>
+<div class="toolbar">
+<input type="search" id="search" placeholder="Search fields, keys, env vars..." autocomplete="off">
+<button type="button" id="expand-all">Expand all</button>
+<button type="button" id="collapse-all">Collapse all</button>
+</div>
+<p id="no-match" class="hidden">No fields match.</p>
+<div id="fields">
+{{template "fields" .Fields}}
+</div>
+
+<script>
+(function () {
+  var search = document.getElementById("search");
+  var sections = document.querySelectorAll("#fields details");
+  var rows = document.querySelectorAll("#fields tr.field");
+
+  function filter() {
+    var q = search.value.trim().toLowerCase();
+    var matches = function (el) { return q === "" || el.dataset.search.indexOf(q) !== -1; };
+    rows.forEach(function (row) { row.classList.toggle("hidden", !matches(row)); });
+    // A matching section shows all of its fields
+    sections.forEach(function (sec) {
+      if (q !== "" && matches(sec)) {
+        sec.querySelectorAll("tr.field").forEach(function (row) { row.classList.remove("hidden"); });
+      }
+    });
+    // Innermost sections first, so parents see their children's state
+    Array.prototype.slice.call(sections).reverse().forEach(function (sec) {
+      var match = matches(sec) || sec.querySelector("tr.field:not(.hidden), details:not(.hidden)") !== null;
+      sec.classList.toggle("hidden", !match);
+      if (q !== "" && match) { sec.open = true; }
+    });
+    document.querySelectorAll("#fields table").forEach(function (table) {
+      table.classList.toggle("hidden", table.querySelector("tr.field:not(.hidden)") === null);
+    });
+    var any = document.querySelector("#fields tr.field:not(.hidden), #fields details:not(.hidden)") !== null;
+    document.getElementById("no-match").classList.toggle("hidden", any);
+  }
+
+  search.addEventListener("input", filter);
+  document.getElementById("expand-all").addEventListener("click", function () {
+    sections.forEach(function (sec) { sec.open = true; });
+  });
+  document.getElementById("collapse-all").addEventListener("click", function () {
+    sections.forEach(function (sec) { sec.open = false; });
+  });
+
+  var copy = document.getElementById("copy-yaml");
+  copy.addEventListener("click", function () {
+    var text = document.getElementById("yaml").textContent;
+    var done = function () {
+      copy.textContent = "Copied";
+      setTimeout(function () { copy.textContent = "Copy"; }, 1500);
+    };
+    if (navigator.clipboard && window.isSecureContext) {
+      navigator.clipboard.writeText(text).then(done);
+      return;
+    }
+    // Pages opened from file:// have no clipboard API
+    var area = document.createElement("textarea");
+    area.value = text;
+    document.body.appendChild(area);
+    area.select();
+    document.execCommand("copy");
+    document.body.removeChild(area);
+    done();
+  });
+})();
+</script>
+</body>
+</html>
+{{define "fields"}}
+{{- $rows := false}}{{range .}}{{if not .Nested}}{{$rows = true}}{{end}}{{end}}
+{{- if $rows}}
+<table>
+<thead><tr><th>Field</th><th>Type</th><th>Default</th><th>Env / Source</th><th>Validation</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .}}{{if not .Nested}}
+<tr class="field" id="{{.Path}}" data-search="{{.Search}}">
+<td class="name"><code>{{.Name}}</code><span class="path">{{.Path}}</span></td>
+<td><code>{{.Type}}</code></td>
+<td>{{with .Default}}<code>{{.}}</code>{{else}}<span class="muted">-</span>{{end}}</td>
+<td>{{with .Env}}<code class="source">{{.}}</code>{{end}}{{range .Sources}}<code class="source">{{.}}</code>{{end}}{{if and (not .Env) (not .Sources)}}<span class="muted">-</span>{{end}}</td>
+<td>{{with .Validate}}<code>{{.}}</code>{{else}}<span class="muted">-</span>{{end}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{- end}}{{end}}
+</tbody>
+</table>
+{{- end}}
+{{- range .}}{{if .Nested}}
+<details open id="{{.Path}}" data-search="{{.Search}}">
+<summary>{{.Name}}<code class="path">{{.Path}}</code>{{with .NestedType}} <span class="muted">{{.}}</span>{{end}}</summary>
+{{.Description}}
+{{template "fields" .Nested}}
+</details>
+{{- end}}{{end}}
+{{- end}}
+`))
@@ -0,0 +1,62 @@
+package docgen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const htmlConfig = `package config
+
+// Config is the <app> config.
+type Config struct {
+	// Port is the port to listen on.
+	Port int ` + "`" + `yaml:"port" default:"8080" env:"PORT" validate:"min=1"` + "`" + `
+	// Database configures the database.
+	Database Database ` + "`" + `yaml:"database"` + "`" + `
+}
+
+type Database struct {
+	// Password is read from a file.
+	//
+	//	echo secret > /run/db.pw
+	Password string ` + "`" + `yaml:"password" ref:"file:///run/db.pw"` + "`" + `
+}
+`
+
+func TestGenerate_HTML(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.go": htmlConfig})
+
+	var buf bytes.Buffer
+	if err := docgen.Generate("Config", dir, &buf, docgen.FormatHTML); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Config Configuration</title>",
+		"<p>Config is the &lt;app&gt; config.</p>",
+		`<input type="search" id="search"`,
+		`<pre id="yaml">`,
+		"port: 8080",
+		`<tr class="field" id="port" data-search="port port int port port is the port to listen on.">`,
+		"<code>min=1</code>",
+		`<details open id="database"`,
+		`<tr class="field" id="database.password"`,
+		`<code class="source">ref: file:///run/db.pw</code>`,
+		"<p>Password is read from a file.</p><pre>echo secret &gt; /run/db.pw</pre>",
+		`<button type="button" id="copy-yaml">Copy</button>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML output missing %q", want)
+		}
+	}
+
+	if strings.Contains(out, "<link") || strings.Contains(out, "<script src") {
+		t.Error("HTML output is not self-contained")
+	}
+}
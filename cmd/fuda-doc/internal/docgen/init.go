@@ -0,0 +1,483 @@
+package docgen
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+)
+
+// InitField is one field the init wizard asks a value for.
+type InitField struct {
+	Path        string // dotted YAML path, e.g. "database.host"; "" if not read from YAML
+	Env         string // env var named by the env tag, if any
+	Type        string
+	Default     string
+	Description string
+	Validate    string // validate tag
+	Required    bool   // the zero value fails validation and there is no default
+
+	rules fieldRules
+}
+
+// Name returns the name shown for the field: its YAML path, or its env var.
+func (f *InitField) Name() string {
+	if f.Path != "" {
+		return f.Path
+	}
+
+	return f.Env
+}
+
+// InitFields lists the fields of doc that take a value from a config file or
+// env var, in struct order. Nested structs are walked into; fields computed
+// by a dsn or expr tag and collections of structs are left out.
+func InitFields(doc StructDoc) []InitField {
+	return collectInitFields(doc.Fields, "")
+}
+
+func collectInitFields(fields []FieldInfo, prefix string) []InitField {
+	var out []InitField
+
+	for i := range fields {
+		f := &fields[i]
+		if !docutil.IsExported(f.Name) || f.Tags["dsn"] != "" || f.Tags["expr"] != "" {
+			continue
+		}
+
+		key, inline, ok := fixtureKey(f)
+		env := envTagName(f.Tags["env"])
+		if !ok && env == "" {
+			continue
+		}
+		if inline {
+			out = append(out, collectInitFields(f.Nested, prefix)...)
+
+			continue
+		}
+
+		path := ""
+		if ok {
+			path = key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+		}
+
+		typ := docutil.MapForm(f.Type)
+		if len(f.Nested) > 0 && !isCollection(typ) {
+			if path != "" {
+				out = append(out, collectInitFields(f.Nested, path)...)
+			}
+
+			continue
+		}
+		if isCollection(typ) && typ != "[]byte" {
+			if _, ok := elemType(typ); !ok {
+				continue
+			}
+		}
+
+		rules := parseRules(f.Tags["validate"])
+		def := f.Tags["default"]
+		if _, fallback, ok := strings.Cut(strings.TrimSuffix(f.Tags["env"], ",required"), "="); ok && def == "" {
+			def = fallback
+		}
+
+		out = append(out, InitField{
+			Path:        path,
+			Env:         env,
+			Type:        f.Type,
+			Default:     def,
+			Description: f.Description,
+			Validate:    f.Tags["validate"],
+			Required:    def == "" && (rules.zeroFails(typ) || strings.HasSuffix(f.Tags["env"], ",required")),
+			rules:       rules,
+		})
+	}
+
+	return out
+}
+
+// envTagName returns the variable name of an env tag NAME[=fallback][,required].
+// Wildcards like FEATURE_* name no single variable and return "".
+func envTagName(tag string) string {
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(strings.TrimSuffix(tag, ",required"), "=")
+	if strings.HasSuffix(name, "*") {
+		return ""
+	}
+
+	return name
+}
+
+// Check reports whether value is a valid input for the field: it must parse
+// as the field type and satisfy the validate rules that can be checked
+// without the rest of the config. An empty value keeps the default, so it
+// is only rejected for required fields. Collections are entered in the tag
+// form, "a,b" or "key:value,key:value".
+func (f *InitField) Check(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if f.Required {
+			return errors.New("a value is required")
+		}
+
+		return nil
+	}
+
+	typ := docutil.MapForm(f.Type)
+	if isCollection(typ) && strings.TrimPrefix(typ, "*") != "[]byte" {
+		return f.checkCollection(typ, value)
+	}
+
+	kind := scalarKind(typ)
+	n, measured, err := parseInitScalar(kind, typ, value)
+	if err != nil {
+		return err
+	}
+	if f.rules.alternatives {
+		return nil
+	}
+
+	if kind == kindString {
+		n, measured = float64(utf8.RuneCountInString(value)), true
+	}
+
+	return checkInitRules(f.rules.field, kind, value, n, measured)
+}
+
+// checkCollection checks the items of a slice or map value.
+func (f *InitField) checkCollection(typ, value string) error {
+	elem, _ := elemType(typ)
+	kind := scalarKind(elem)
+	isMap := strings.HasPrefix(strings.TrimPrefix(typ, "*"), "map[")
+
+	items := strings.Split(value, ",")
+	if !f.rules.alternatives {
+		if err := checkInitRules(f.rules.field, kindUnknown, value, float64(len(items)), true); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if isMap {
+			k, v, ok := strings.Cut(item, ":")
+			if !ok || strings.TrimSpace(k) == "" {
+				return fmt.Errorf("%q is not a key:value pair", item)
+			}
+			item = strings.TrimSpace(v)
+		}
+
+		n, measured, err := parseInitScalar(kind, elem, item)
+		if err != nil {
+			return fmt.Errorf("item %q: %w", item, err)
+		}
+		if f.rules.alternatives || (f.rules.elemOmit && item == "") {
+			continue
+		}
+		if kind == kindString {
+			n, measured = float64(utf8.RuneCountInString(item)), true
+		}
+		if err := checkInitRules(f.rules.elem, kind, item, n, measured); err != nil {
+			return fmt.Errorf("item %q: %w", item, err)
+		}
+	}
+
+	return nil
+}
+
+// byteSizePattern matches the byte size strings fuda accepts for integers.
+var byteSizePattern = regexp.MustCompile(`^\d+(\.\d+)?\s*([KMGTPE]i?B|B)$`)
+
+// parseInitScalar parses raw as kind, returning its numeric value for range
+// rules and whether it has one. Types without a known form are accepted
+// as is.
+func parseInitScalar(kind valueKind, typ, raw string) (float64, bool, error) {
+	switch kind {
+	case kindBool:
+		if _, err := strconv.ParseBool(raw); err != nil {
+			return 0, false, errors.New("must be true or false")
+		}
+
+		return 0, false, nil
+	case kindInt, kindUint:
+		n, err := strconv.ParseFloat(raw, 64)
+		switch {
+		case err == nil && n == float64(int64(n)) && (kind == kindInt || n >= 0):
+			return n, true, nil
+		case byteSizePattern.MatchString(raw):
+			return 0, false, nil // a byte size, converted when loading
+		case kind == kindUint:
+			return 0, false, errors.New("must be a non-negative integer")
+		default:
+			return 0, false, errors.New("must be an integer")
+		}
+	case kindFloat:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, false, errors.New("must be a number")
+		}
+
+		return n, true, nil
+	case kindDuration:
+		d, err := parseInitDuration(strings.TrimPrefix(typ, "*"), raw)
+		if err != nil {
+			return 0, false, err
+		}
+
+		return float64(d), true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// parseInitDuration parses a duration; fuda.Duration also takes a leading
+// day count such as "7d" or "1d12h".
+func parseInitDuration(typ, raw string) (time.Duration, error) {
+	var days time.Duration
+	if typ == "fuda.Duration" {
+		if i := strings.IndexByte(raw, 'd'); i > 0 {
+			n, err := strconv.Atoi(raw[:i])
+			if err == nil {
+				days, raw = time.Duration(n)*24*time.Hour, raw[i+1:]
+			}
+		}
+		if raw == "" {
+			return days, nil
+		}
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, errors.New("must be a duration such as 30s or 5m")
+	}
+
+	return days + d, nil
+}
+
+// checkInitRules checks rules against a value. n is the number compared by
+// range rules (the length of strings and collections), valid if measured.
+func checkInitRules(rules []rule, kind valueKind, raw string, n float64, measured bool) error {
+	isLen := kind == kindString || kind == kindUnknown
+	for _, r := range rules {
+		switch r.name {
+		case "oneof":
+			if values := oneofValues(r.param); !slices.Contains(values, raw) {
+				return fmt.Errorf("must be one of: %s", strings.Join(values, ", "))
+			}
+		case "eq", "ne":
+			if kind == kindString {
+				if (raw == r.param) != (r.name == "eq") {
+					return fmt.Errorf("must satisfy %s", r)
+				}
+
+				continue
+			}
+			if err := checkInitRange(r, kind, isLen, n, measured); err != nil {
+				return err
+			}
+		case "min", "max", "gte", "lte", "gt", "lt", "len":
+			if err := checkInitRange(r, kind, isLen, n, measured); err != nil {
+				return err
+			}
+		case "precision":
+			places, err := strconv.Atoi(r.param)
+			_, frac, _ := strings.Cut(raw, ".")
+			if err == nil && len(strings.TrimRight(frac, "0")) > places {
+				return fmt.Errorf("must have at most %d decimal places", places)
+			}
+		default:
+			if check, ok := formatChecks[r.name]; ok && kind == kindString && !check(raw) {
+				return fmt.Errorf("must be a valid %s", r.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkInitRange checks a comparison rule against n.
+func checkInitRange(r rule, kind valueKind, isLen bool, n float64, measured bool) error {
+	p, ok := numParam(r.param, kind, isLen)
+	if !ok || !measured {
+		return nil
+	}
+
+	var fails bool
+	switch r.name {
+	case "min", "gte":
+		fails = n < p
+	case "max", "lte":
+		fails = n > p
+	case "gt":
+		fails = n <= p
+	case "lt":
+		fails = n >= p
+	case "len", "eq":
+		fails = n != p
+	case "ne":
+		fails = n == p
+	}
+	if !fails {
+		return nil
+	}
+
+	if isLen {
+		return fmt.Errorf("length must satisfy %s", r)
+	}
+
+	return fmt.Errorf("must satisfy %s", r)
+}
+
+// formatChecks checks the string format rules that can be evaluated here.
+// Other formats are left to validation when the config is loaded.
+var formatChecks = map[string]func(string) bool{
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+
+		return err == nil && addr.Address == s
+	},
+	"url": isURL,
+	"uri": isURL,
+	"http_url": func(s string) bool {
+		return isURL(s) && (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://"))
+	},
+	"hostname_port": func(s string) bool {
+		host, port, err := net.SplitHostPort(s)
+		_, perr := strconv.ParseUint(port, 10, 16)
+
+		return err == nil && host != "" && perr == nil
+	},
+	"ip": func(s string) bool { return net.ParseIP(s) != nil },
+	"ipv4": func(s string) bool {
+		ip := net.ParseIP(s)
+
+		return ip != nil && ip.To4() != nil
+	},
+	"ipv6": func(s string) bool {
+		ip := net.ParseIP(s)
+
+		return ip != nil && ip.To4() == nil
+	},
+	"cidr": func(s string) bool {
+		_, _, err := net.ParseCIDR(s)
+
+		return err == nil
+	},
+	"numeric": func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+
+		return err == nil
+	},
+	"alpha": func(s string) bool {
+		return strings.IndexFunc(s, func(r rune) bool { return r > unicode.MaxASCII || !unicode.IsLetter(r) }) < 0
+	},
+	"alphanum": func(s string) bool {
+		return strings.IndexFunc(s, func(r rune) bool {
+			return r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) < 0
+	},
+	"lowercase": func(s string) bool { return s == strings.ToLower(s) },
+	"uppercase": func(s string) bool { return s == strings.ToUpper(s) },
+	"base64": func(s string) bool {
+		_, err := base64.StdEncoding.DecodeString(s)
+
+		return err == nil
+	},
+	"json": func(s string) bool { return json.Valid([]byte(s)) },
+}
+
+// isURL reports whether s is an absolute URL.
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+
+	return err == nil && u.Scheme != ""
+}
+
+// RenderInitFiles renders the values entered for fields, keyed by position,
+// as a config.yaml and a .env file. Fields with an env tag go to the .env
+// file, the others to the YAML file. Values left empty or equal to the
+// default are omitted, so the default keeps applying.
+func RenderInitFiles(structName string, fields []InitField, values []string) (configYAML, dotenv []byte) {
+	var y, e strings.Builder
+	fmt.Fprintf(&y, "# Generated by fuda-doc init from %s.\n", structName)
+	fmt.Fprintf(&e, "# Generated by fuda-doc init from %s.\n", structName)
+
+	var open []string // YAML mapping keys of the current nesting
+	wroteYAML := false
+	for i := range fields {
+		f := &fields[i]
+		value := strings.TrimSpace(values[i])
+		if value == "" || value == f.Default {
+			continue
+		}
+
+		if f.Env != "" {
+			fmt.Fprintf(&e, "%s=%s\n", f.Env, dotenvValue(value))
+
+			continue
+		}
+
+		keys := strings.Split(f.Path, ".")
+		depth := 0
+		for depth < len(open) && depth < len(keys)-1 && open[depth] == keys[depth] {
+			depth++
+		}
+		open = open[:depth]
+		for ; depth < len(keys)-1; depth++ {
+			fmt.Fprintf(&y, "%s%s:\n", strings.Repeat("  ", depth), keys[depth])
+			open = append(open, keys[depth])
+		}
+		fmt.Fprintf(&y, "%s%s: %s\n", strings.Repeat("  ", depth), keys[depth], initYAMLValue(f.Type, value))
+		wroteYAML = true
+	}
+	if !wroteYAML {
+		y.WriteString("{}\n")
+	}
+
+	return []byte(y.String()), []byte(e.String())
+}
+
+// initYAMLValue renders an entered value of typ as YAML.
+func initYAMLValue(typ, value string) string {
+	typ = docutil.MapForm(typ)
+	if elem, ok := elemType(typ); ok {
+		return renderDefaultCollection(typ, elem, value)
+	}
+
+	kind := scalarKind(typ)
+	if kind == kindUnknown {
+		kind = kindString
+	}
+
+	return renderScalar(kind, value)
+}
+
+// dotenvValue quotes value for a .env file if it contains spaces, quotes,
+// or comment characters.
+func dotenvValue(value string) string {
+	if !strings.ContainsAny(value, " \t#'\"\\=$") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+
+	return strconv.Quote(value)
+}
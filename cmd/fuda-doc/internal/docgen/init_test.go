@@ -0,0 +1,170 @@
+package docgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const initSource = `package cfg
+
+import "time"
+
+type Config struct {
+	// Name of the service.
+	Name     string            ` + "`" + `yaml:"name" validate:"required,min=3,max=20"` + "`" + `
+	Level    string            ` + "`" + `yaml:"level" default:"info" validate:"oneof=debug info"` + "`" + `
+	Port     int               ` + "`" + `yaml:"port" default:"8080" validate:"min=1024"` + "`" + `
+	Timeout  time.Duration     ` + "`" + `yaml:"timeout" validate:"omitempty,min=1s"` + "`" + `
+	Hosts    []string          ` + "`" + `yaml:"hosts" validate:"dive,hostname_port"` + "`" + `
+	Labels   map[string]int    ` + "`" + `yaml:"labels"` + "`" + `
+	Database Database          ` + "`" + `yaml:"database"` + "`" + `
+	Token    string            ` + "`" + `yaml:"-" env:"APP_TOKEN,required"` + "`" + `
+	Servers  []Server          ` + "`" + `yaml:"servers"` + "`" + `
+	DSN      string            ` + "`" + `yaml:"dsn" dsn:"postgres://{{.Database.Host}}"` + "`" + `
+	Conns    int               ` + "`" + `yaml:"conns" expr:"${.Port} * 2"` + "`" + `
+	Skip     string            ` + "`" + `yaml:"-"` + "`" + `
+}
+
+type Database struct {
+	Host     string ` + "`" + `yaml:"host" validate:"required,hostname"` + "`" + `
+	Password string ` + "`" + `yaml:"password" env:"DB_PASSWORD"` + "`" + `
+}
+
+type Server struct {
+	Addr string ` + "`" + `yaml:"addr"` + "`" + `
+}
+`
+
+// initFields parses initSource and returns its init fields by name.
+func initFields(t *testing.T) ([]docgen.InitField, map[string]*docgen.InitField) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte(initSource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+
+	fields := docgen.InitFields(docs[0])
+	byName := make(map[string]*docgen.InitField, len(fields))
+	for i := range fields {
+		byName[fields[i].Name()] = &fields[i]
+	}
+
+	return fields, byName
+}
+
+func TestInitFields(t *testing.T) {
+	t.Parallel()
+
+	fields, byName := initFields(t)
+
+	var names []string
+	for i := range fields {
+		names = append(names, fields[i].Name())
+	}
+	want := "name level port timeout hosts labels database.host database.password APP_TOKEN"
+	if got := strings.Join(names, " "); got != want {
+		t.Fatalf("fields = %q, want %q", got, want)
+	}
+
+	required := map[string]bool{"name": true, "database.host": true, "APP_TOKEN": true}
+	for name, f := range byName {
+		if f.Required != required[name] {
+			t.Errorf("%s: Required = %v", name, f.Required)
+		}
+	}
+
+	if f := byName["name"]; f.Description != "Name of the service." {
+		t.Errorf("description = %q", f.Description)
+	}
+	if f := byName["database.password"]; f.Env != "DB_PASSWORD" {
+		t.Errorf("env = %q", f.Env)
+	}
+}
+
+func TestInitField_Check(t *testing.T) {
+	t.Parallel()
+
+	_, byName := initFields(t)
+
+	tests := []struct {
+		field, value, wantErr string
+	}{
+		{"name", "", "a value is required"},
+		{"name", "ab", "length must satisfy min=3"},
+		{"name", "api", ""},
+		{"level", "", ""},
+		{"level", "warn", "must be one of: debug, info"},
+		{"port", "80", "must satisfy min=1024"},
+		{"port", "http", "must be an integer"},
+		{"port", "9090", ""},
+		{"timeout", "500ms", "must satisfy min=1s"},
+		{"timeout", "soon", "must be a duration"},
+		{"timeout", "2m", ""},
+		{"hosts", "a:80,b", `item "b": must be a valid hostname_port`},
+		{"hosts", "a:80, b:81", ""},
+		{"labels", "a:1,b", `"b" is not a key:value pair`},
+		{"labels", "a:1,b:x", `item "x": must be an integer`},
+		{"labels", "a:1,b:2", ""},
+		{"database.host", "db.local", ""},
+		{"APP_TOKEN", "", "a value is required"},
+	}
+	for _, tt := range tests {
+		err := byName[tt.field].Check(tt.value)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s=%q: unexpected error %v", tt.field, tt.value, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s=%q: error = %v, want %q", tt.field, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenderInitFiles(t *testing.T) {
+	t.Parallel()
+
+	fields, _ := initFields(t)
+	values := map[string]string{
+		"name":              "api",
+		"level":             "info", // the default, so left out
+		"hosts":             "a:80,b:81",
+		"labels":            "x:1",
+		"database.host":     "db.local",
+		"database.password": "p@ss word",
+		"APP_TOKEN":         "s3cret",
+	}
+	entered := make([]string, len(fields))
+	for i := range fields {
+		entered[i] = values[fields[i].Name()]
+	}
+
+	configYAML, dotenv := docgen.RenderInitFiles("Config", fields, entered)
+
+	wantYAML := `# Generated by fuda-doc init from Config.
+name: "api"
+hosts: ["a:80", "b:81"]
+labels: {"x": 1}
+database:
+  host: "db.local"
+`
+	if string(configYAML) != wantYAML {
+		t.Errorf("config.yaml =\n%s\nwant\n%s", configYAML, wantYAML)
+	}
+
+	wantEnv := `# Generated by fuda-doc init from Config.
+DB_PASSWORD='p@ss word'
+APP_TOKEN=s3cret
+`
+	if string(dotenv) != wantEnv {
+		t.Errorf(".env =\n%s\nwant\n%s", dotenv, wantEnv)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+	"github.com/arloliu/fuda/docfmt"
 )
 
 // MarkdownPrinter handles markdown output generation.
@@ -148,8 +149,7 @@ func (p *MarkdownPrinter) printSectionFields(fields []FieldInfo, headingLevel in
 }
 
 func (p *MarkdownPrinter) printFieldTable(fields []FieldInfo) {
-	p.printf("| Field | Type | Default | Env / Source |\n")
-	p.printf("|:------|:-----|:--------|:------------|\n")
+	table := docfmt.Table{Headers: []string{"Field", "Type", "Default", "Env / Source"}}
 
 	for _, f := range fields {
 		if !docutil.IsExported(f.Name) {
@@ -163,8 +163,10 @@ func (p *MarkdownPrinter) printFieldTable(fields []FieldInfo) {
 			fieldCol = fmt.Sprintf("`%s`<br><sub>`%s`</sub>", f.Name, yamlKey)
 		}
 
-		p.printf("| %s | `%s` | %s | %s |\n", fieldCol, f.Type, defaultDisplay(f), sourceDisplay(f))
+		table.Rows = append(table.Rows, []string{fieldCol, "`" + f.Type + "`", defaultDisplay(f), sourceDisplay(f)})
 	}
+
+	_ = table.WriteMarkdown(p.w)
 }
 
 func (p *MarkdownPrinter) printFieldDetails(fields []FieldInfo) {
@@ -204,6 +206,10 @@ func (p *MarkdownPrinter) printFieldDetails(fields []FieldInfo) {
 			p.printf("| **DSN template** | `%s` |\n", v)
 		}
 
+		if v := f.Tags["expr"]; v != "" {
+			p.printf("| **Expression** | `%s` |\n", v)
+		}
+
 		if v := f.Tags["validate"]; v != "" {
 			p.printf("| **Validation** | `%s` |\n", v)
 		}
@@ -251,6 +257,10 @@ func sourceDisplay(f FieldInfo) string {
 		parts = append(parts, "dsn ✓")
 	}
 
+	if _, ok := f.Tags["expr"]; ok {
+		parts = append(parts, "expr ✓")
+	}
+
 	if len(parts) == 0 {
 		return "-"
 	}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 
@@ -141,7 +142,7 @@ func (p *Parser) parseDirWithPreferred(dir string, preferredPkg string) (*ast.Pa
 //
 //nolint:staticcheck // ast.Package used for simplicity
 func (p *Parser) FindStruct(pkg *ast.Package, structName string) *ast.TypeSpec {
-	for _, file := range pkg.Files {
+	for _, file := range sortedFiles(pkg) {
 		for _, decl := range file.Decls {
 			gd, ok := decl.(*ast.GenDecl)
 			if !ok || gd.Tok != token.TYPE {
@@ -169,14 +170,15 @@ func (p *Parser) FindStruct(pkg *ast.Package, structName string) *ast.TypeSpec {
 	return nil
 }
 
-// FindAllStructs returns all exported struct type declarations in the package.
-// Doc comments are propagated from GenDecl to TypeSpec where needed.
+// FindAllStructs returns all exported struct type declarations in the package,
+// ordered by file name and then by position in the file. Doc comments are
+// propagated from GenDecl to TypeSpec where needed.
 //
 //nolint:staticcheck // ast.Package used for simplicity
 func (p *Parser) FindAllStructs(pkg *ast.Package) []*ast.TypeSpec {
 	var results []*ast.TypeSpec
 
-	for _, file := range pkg.Files {
+	for _, file := range sortedFiles(pkg) {
 		for _, decl := range file.Decls {
 			gd, ok := decl.(*ast.GenDecl)
 			if !ok || gd.Tok != token.TYPE {
@@ -206,6 +208,25 @@ func (p *Parser) FindAllStructs(pkg *ast.Package) []*ast.TypeSpec {
 	return results
 }
 
+// sortedFiles returns the files of pkg ordered by file name, so lookups and
+// generated output do not depend on map iteration order.
+//
+//nolint:staticcheck // ast.Package used for simplicity
+func sortedFiles(pkg *ast.Package) []*ast.File {
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]*ast.File, len(names))
+	for i, name := range names {
+		files[i] = pkg.Files[name]
+	}
+
+	return files
+}
+
 // propagateDoc copies the GenDecl doc comment to the TypeSpec when the
 // TypeSpec's own Doc is nil. This handles the common case where a standalone
 // `type Foo struct { ... }` has its comment attached to the GenDecl.
@@ -260,6 +281,9 @@ func (p *Parser) processStructVisited(ts *ast.TypeSpec, pkg *ast.Package, stack
 				Description: getDoc(field.Doc, field.Comment),
 				Tags:        parseTags(field.Tag),
 			}
+			if info.Description == "" {
+				info.Description = tagDoc(field.Tag)
+			}
 
 			// Check for nested struct (same package or cross-package).
 			nestedType, nestedPkg := p.resolveNestedType(field.Type, pkg)
@@ -364,7 +388,7 @@ func findStructInPkg(name string, pkg *ast.Package) *ast.TypeSpec {
 		return nil
 	}
 
-	for _, file := range pkg.Files {
+	for _, file := range sortedFiles(pkg) {
 		for _, decl := range file.Decls {
 			gd, ok := decl.(*ast.GenDecl)
 			if !ok || gd.Tok != token.TYPE {
@@ -396,7 +420,7 @@ func findStructInPkg(name string, pkg *ast.Package) *ast.TypeSpec {
 //
 //nolint:staticcheck // ast.Package used for simplicity
 func findImportPath(pkg *ast.Package, alias string) string {
-	for _, file := range pkg.Files {
+	for _, file := range sortedFiles(pkg) {
 		for _, imp := range file.Imports {
 			path := strings.Trim(imp.Path.Value, `"`)
 
@@ -519,6 +543,15 @@ func getTypeName(expr ast.Expr) string {
 		return "[]" + getTypeName(t.Elt)
 	case *ast.MapType:
 		return "map[" + getTypeName(t.Key) + "]" + getTypeName(t.Value)
+	case *ast.IndexExpr:
+		return getTypeName(t.X) + "[" + getTypeName(t.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			args[i] = getTypeName(index)
+		}
+
+		return getTypeName(t.X) + "[" + strings.Join(args, ", ") + "]"
 	default:
 		return fmt.Sprintf("%T", expr)
 	}
@@ -539,8 +572,19 @@ func getDoc(doc *ast.CommentGroup, comment *ast.CommentGroup) string {
 	return strings.TrimSpace(sb.String())
 }
 
+// tagDoc returns the `doc` struct tag, used as the description for fields
+// without doc comments.
+func tagDoc(tag *ast.BasicLit) string {
+	if tag == nil {
+		return ""
+	}
+
+	return reflect.StructTag(strings.Trim(tag.Value, "`")).Get("doc")
+}
+
 var supportedTags = []string{
-	"default", "env", "validate", "yaml", "json", "ref", "refFrom", "dsn", "required",
+	"default", "env", "validate", "yaml", "json", "ref", "refFrom", "dsn", "expr", "required", "deprecated",
+	"secret", "sensitive", "kms", "secretName",
 }
 
 func parseTags(tag *ast.BasicLit) map[string]string {
@@ -558,5 +602,65 @@ func parseTags(tag *ast.BasicLit) map[string]string {
 		}
 	}
 
+	// Separate tags take precedence over the combined fuda tag
+	if v, ok := st.Lookup("fuda"); ok {
+		for key, val := range parseFudaTag(v) {
+			if _, ok := tags[key]; !ok && slices.Contains(supportedTags, key) {
+				tags[key] = val
+			}
+		}
+	}
+
+	return tags
+}
+
+// parseFudaTag splits a combined tag such as
+// `fuda:"default=8080,env=APP_PORT,validate='min=1,max=65535',required"`
+// into the separate tags it stands for. Bare "required" is prepended to the
+// validate rules; other bare words are boolean tags set to "true".
+func parseFudaTag(tag string) map[string]string {
+	var items []string
+	quoted, start := false, 0
+	for i := range len(tag) {
+		switch tag[i] {
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				items = append(items, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	items = append(items, tag[start:])
+
+	tags := make(map[string]string)
+	required := false
+	for _, item := range items {
+		key, val, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		switch {
+		case key == "":
+		case !ok && key == "required":
+			required = true
+		case !ok:
+			tags[key] = "true"
+		default:
+			val = strings.TrimSpace(val)
+			if len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'' {
+				val = val[1 : len(val)-1]
+			}
+			tags[key] = val
+		}
+	}
+
+	if required {
+		if rules := tags["validate"]; rules != "" {
+			tags["validate"] = "required," + rules
+		} else {
+			tags["validate"] = "required"
+		}
+	}
+
 	return tags
 }
@@ -1,8 +1,10 @@
 package docgen_test
 
 import (
+	"bytes"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
@@ -103,6 +105,55 @@ func TestFindAllStructs_OnlyExportedStructs(t *testing.T) {
 	}
 }
 
+func TestFindAllStructs_Order(t *testing.T) {
+	t.Parallel()
+
+	p := docgen.NewParser()
+	pkg, err := p.ParsePackage(testdataDir(t))
+	if err != nil {
+		t.Fatalf("ParsePackage: %v", err)
+	}
+
+	var names []string
+	for _, ts := range p.FindAllStructs(pkg) {
+		names = append(names, ts.Name.Name)
+	}
+
+	// config.go before edge_cases.go, each in declaration order
+	want := []string{"Config", "StorageConfig", "ServerConfig", "TLSConfig", "DatabaseConfig", "SQLConfig", "RedisConfig", "SecretsConfig", "Flat", "WithPointer"}
+	if len(names) < len(want) || strings.Join(names[:len(want)], ",") != strings.Join(want, ",") {
+		t.Errorf("FindAllStructs order = %v, want prefix %v", names, want)
+	}
+}
+
+func TestGeneratedFiles_Stable(t *testing.T) {
+	t.Parallel()
+
+	render := func() string {
+		docs, err := docgen.ParseAll("", testdataDir(t))
+		if err != nil {
+			t.Fatalf("ParseAll: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := docgen.PrintDefaultYAML(docs, &buf, true); err != nil {
+			t.Fatalf("PrintDefaultYAML: %v", err)
+		}
+		if err := docgen.PrintEnvFile(docs, &buf); err != nil {
+			t.Fatalf("PrintEnvFile: %v", err)
+		}
+
+		return buf.String()
+	}
+
+	first := render()
+	for range 10 {
+		if got := render(); got != first {
+			t.Fatal("default YAML and .env output differ between runs")
+		}
+	}
+}
+
 // ---------- Doc propagation -------------------------------------------
 
 func TestProcessStruct_DocComment(t *testing.T) {
@@ -456,6 +507,35 @@ func TestProcessStruct_NoComments(t *testing.T) {
 	}
 }
 
+func TestProcessStruct_TagDocs(t *testing.T) {
+	t.Parallel()
+
+	p := docgen.NewParser()
+	pkg, err := p.ParsePackage(testdataDir(t))
+	if err != nil {
+		t.Fatalf("ParsePackage: %v", err)
+	}
+
+	ts := p.FindStruct(pkg, "TagDocs")
+	if ts == nil {
+		t.Fatal("TagDocs not found")
+	}
+
+	fields, err := p.ProcessStruct(ts, pkg)
+	if err != nil {
+		t.Fatalf("ProcessStruct(TagDocs): %v", err)
+	}
+
+	assertFieldCount(t, "TagDocs", fields, 2)
+
+	if got := fields[0].Description; got != "Deployment region" {
+		t.Errorf("Region description = %q, want doc tag", got)
+	}
+	if got := fields[1].Description; got != "Zone comes from the comment, which wins over the doc tag." {
+		t.Errorf("Zone description = %q, want comment", got)
+	}
+}
+
 // ---------- Cross-package struct resolution ----------------------------
 
 func TestProcessStruct_CrossPackageDirect(t *testing.T) {
@@ -665,6 +745,34 @@ func TestParseAll_AllStructs(t *testing.T) {
 	}
 }
 
+func TestParseAll_FudaTag(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.go": "package config\n\ntype Config struct {\n" +
+		"\tPort int `yaml:\"port\" fuda:\"default=8080,env=APP_PORT,validate='min=1,max=65535',required\"`\n" +
+		"\tHost string `yaml:\"host\" env:\"APP_HOST\" fuda:\"env=IGNORED,default=localhost\"`\n" +
+		"}\n"})
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatalf("ParseAll(Config): %v", err)
+	}
+
+	fields := docs[0].Fields
+	assertFieldCount(t, "Config", fields, 2)
+	assertField(t, fields[0], "Port", "int", map[string]string{
+		"yaml":     "port",
+		"default":  "8080",
+		"env":      "APP_PORT",
+		"validate": "required,min=1,max=65535",
+	})
+	assertField(t, fields[1], "Host", "string", map[string]string{
+		"env":     "APP_HOST",
+		"default": "localhost",
+	})
+}
+
 func TestParseAll_NonExistent(t *testing.T) {
 	t.Parallel()
 
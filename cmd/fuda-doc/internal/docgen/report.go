@@ -0,0 +1,186 @@
+package docgen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// inlineRefPattern matches the URIs of ${ref:uri} and ${ref "uri"} template
+// calls in dsn, ref, and expr tags.
+var inlineRefPattern = regexp.MustCompile(`\$\{\s*ref(?::|\s+")([^"}]+)`)
+
+// Report summarizes the size and complexity of a config struct, with the
+// metrics of fuda.Report, read from source instead of at runtime. As in the
+// generated docs, slices and maps of structs count as single fields.
+type Report struct {
+	Type        string         `json:"type"`
+	Fields      int            `json:"fields"`       // leaf fields, which hold values
+	Sections    int            `json:"sections"`     // nested structs holding fields
+	MaxDepth    int            `json:"max_depth"`    // depth of the deepest field, top level is 1
+	Secrets     int            `json:"secrets"`      // sensitive fields
+	Refs        map[string]int `json:"refs"`         // ref tags and inline refs by scheme
+	DynamicRefs int            `json:"dynamic_refs"` // refFrom tags and refs with a templated scheme
+	EnvVars     []string       `json:"env_vars"`     // env tag names, sorted
+	Validated   int            `json:"validated"`    // fields with validate rules
+}
+
+// BuildReport returns the Report of doc.
+func BuildReport(doc StructDoc) Report {
+	r := Report{Type: doc.Name, Refs: map[string]int{}, EnvVars: []string{}}
+	r.addFields(doc.Fields, 1)
+	sort.Strings(r.EnvVars)
+	r.EnvVars = slices.Compact(r.EnvVars)
+
+	return r
+}
+
+// ValidationCoverage returns the share of fields with validate rules, from
+// 0 to 1, or 0 if there are no fields.
+func (r Report) ValidationCoverage() float64 {
+	if r.Fields == 0 {
+		return 0
+	}
+
+	return float64(r.Validated) / float64(r.Fields)
+}
+
+// RefCount returns the total number of references, including dynamic ones.
+func (r Report) RefCount() int {
+	n := r.DynamicRefs
+	for _, count := range r.Refs {
+		n += count
+	}
+
+	return n
+}
+
+// FormatReport renders r as aligned text, one metric per line, as
+// fuda.ConfigReport does.
+func FormatReport(r Report) string {
+	schemes := make([]string, 0, len(r.Refs))
+	for scheme := range r.Refs {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	parts := make([]string, 0, len(schemes)+1)
+	for _, scheme := range schemes {
+		parts = append(parts, fmt.Sprintf("%s: %d", scheme, r.Refs[scheme]))
+	}
+	if r.DynamicRefs > 0 {
+		parts = append(parts, fmt.Sprintf("dynamic: %d", r.DynamicRefs))
+	}
+	breakdown := ""
+	if len(parts) > 0 {
+		breakdown = " (" + strings.Join(parts, ", ") + ")"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Config report: %s\n", r.Type)
+	fmt.Fprintf(&sb, "  Fields:              %d in %d sections\n", r.Fields, r.Sections)
+	fmt.Fprintf(&sb, "  Deepest nesting:     %d\n", r.MaxDepth)
+	fmt.Fprintf(&sb, "  Secrets:             %d\n", r.Secrets)
+	fmt.Fprintf(&sb, "  External refs:       %d%s\n", r.RefCount(), breakdown)
+	fmt.Fprintf(&sb, "  Env variables:       %d\n", len(r.EnvVars))
+	fmt.Fprintf(&sb, "  Validation coverage: %d/%d fields (%.0f%%)\n", r.Validated, r.Fields, r.ValidationCoverage()*100) //nolint:mnd // percent
+
+	return sb.String()
+}
+
+// addFields adds fields at nesting depth depth. Inline structs are counted
+// at the depth of their parent.
+func (r *Report) addFields(fields []FieldInfo, depth int) {
+	for i := range fields {
+		f := &fields[i]
+		name, opts, _ := strings.Cut(f.Tags["yaml"], ",")
+		if name == "-" {
+			continue
+		}
+
+		if len(f.Nested) > 0 {
+			if slices.Contains(strings.Split(opts, ","), "inline") {
+				r.addFields(f.Nested, depth)
+			} else {
+				r.Sections++
+				r.addFields(f.Nested, depth+1)
+			}
+
+			continue
+		}
+		if f.NestedType != "" {
+			continue // recursive struct, counted where first declared
+		}
+
+		r.addField(f, depth)
+	}
+}
+
+// addField adds a leaf field at nesting depth depth.
+func (r *Report) addField(f *FieldInfo, depth int) {
+	r.Fields++
+	r.MaxDepth = max(r.MaxDepth, depth)
+
+	if isSecret(f) {
+		r.Secrets++
+	}
+	if rules := f.Tags["validate"]; rules != "" && rules != "-" {
+		r.Validated++
+	}
+	if name, _, _ := strings.Cut(f.Tags["env"], ","); name != "" && name != "-" {
+		r.EnvVars = append(r.EnvVars, strings.TrimSpace(name))
+	}
+
+	if uri := f.Tags["ref"]; uri != "" {
+		r.addRef(uri)
+	}
+	if f.Tags["refFrom"] != "" {
+		r.DynamicRefs++
+	}
+	if f.Tags["secretName"] != "" {
+		r.Refs["file"]++
+	}
+	for _, key := range []string{"ref", "dsn", "expr"} {
+		for _, m := range inlineRefPattern.FindAllStringSubmatch(f.Tags[key], -1) {
+			r.addRef(strings.TrimSpace(m[1]))
+		}
+	}
+}
+
+// addRef counts a reference by the scheme of uri; fuda reads URIs without
+// a scheme as files.
+func (r *Report) addRef(uri string) {
+	scheme, _, ok := strings.Cut(uri, "://")
+	switch {
+	case ok && !strings.Contains(scheme, "${"):
+		r.Refs[scheme]++
+	case !ok && !strings.HasPrefix(strings.TrimSpace(uri), "${"):
+		r.Refs["file"]++
+	default:
+		r.DynamicRefs++
+	}
+}
+
+// isSecret reports whether fuda treats the field as sensitive: an explicit
+// secret or sensitive tag decides, otherwise fields set by ref, refFrom,
+// secretName, dsn, or kms tags are.
+func isSecret(f *FieldInfo) bool {
+	for _, key := range []string{"secret", "sensitive"} {
+		if tag, ok := f.Tags[key]; ok {
+			secret, err := strconv.ParseBool(tag)
+
+			return err != nil || secret
+		}
+	}
+
+	for _, key := range []string{"ref", "refFrom", "secretName", "dsn", "kms"} {
+		if f.Tags[key] != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package docgen_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const reportConfig = `package config
+
+type Common struct {
+	Version string ` + "`" + `yaml:"version" validate:"required"` + "`" + `
+}
+
+type Config struct {
+	Common   ` + "`" + `yaml:",inline"` + "`" + `
+	Name     string   ` + "`" + `yaml:"name" env:"APP_NAME" validate:"required"` + "`" + `
+	APIKey   string   ` + "`" + `yaml:"api_key" fuda:"env=API_KEY,secret"` + "`" + `
+	Banner   string   ` + "`" + `yaml:"banner" ref:"/etc/app/banner" secret:"false"` + "`" + `
+	Token    string   ` + "`" + `yaml:"token" ref:"${.TokenURI}"` + "`" + `
+	Database Database ` + "`" + `yaml:"database"` + "`" + `
+	Tree     *Node    ` + "`" + `yaml:"tree"` + "`" + `
+	Ignored  string   ` + "`" + `yaml:"-" env:"IGNORED"` + "`" + `
+}
+
+type Database struct {
+	Host     string ` + "`" + `yaml:"host" env:"DB_HOST,required" validate:"hostname"` + "`" + `
+	Password string ` + "`" + `yaml:"password" ref:"vault:///secret/db#password"` + "`" + `
+	CertPath string ` + "`" + `yaml:"cert_path"` + "`" + `
+	Cert     string ` + "`" + `yaml:"cert" refFrom:"CertPath"` + "`" + `
+	DSN      string ` + "`" + `yaml:"dsn" dsn:"postgres://${.Host}:${ref:file:///run/pw}/app"` + "`" + `
+}
+
+type Node struct {
+	Name     string  ` + "`" + `yaml:"name"` + "`" + `
+	Children []*Node ` + "`" + `yaml:"children"` + "`" + `
+}
+`
+
+func TestBuildReport(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.go": reportConfig})
+
+	docs, err := docgen.ParseAll("Config", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := docgen.BuildReport(docs[0])
+	want := docgen.Report{
+		Type:        "Config",
+		Fields:      12, // 5 top-level with version, 5 database, 2 tree
+		Sections:    2,
+		MaxDepth:    2,
+		Secrets:     5, // api_key, token, password, cert, dsn
+		Refs:        map[string]int{"file": 2, "vault": 1},
+		DynamicRefs: 2,
+		EnvVars:     []string{"API_KEY", "APP_NAME", "DB_HOST"},
+		Validated:   3,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildReport() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	wantText := `Config report: Config
+  Fields:              12 in 2 sections
+  Deepest nesting:     2
+  Secrets:             5
+  External refs:       5 (file: 2, vault: 1, dynamic: 2)
+  Env variables:       3
+  Validation coverage: 3/12 fields (25%)
+`
+	if text := docgen.FormatReport(got); text != wantText {
+		t.Errorf("FormatReport() =\n%s\nwant\n%s", text, wantText)
+	}
+}
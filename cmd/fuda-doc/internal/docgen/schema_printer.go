@@ -0,0 +1,309 @@
+package docgen
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+)
+
+// schemaDraft is the JSON Schema dialect emitted by SchemaPrinter.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaPrinter generates a JSON Schema (draft 2020-12) of the config file,
+// as fuda.Schema does at runtime, from the source alone. It feeds fuda-watch
+// and editor autocomplete without building the program.
+//
+// Types are derived from the type names in the source: slices and maps of
+// structs, and types that are not declared in the parsed packages, accept
+// any value.
+type SchemaPrinter struct {
+	w io.Writer
+}
+
+// NewSchemaPrinter creates a new SchemaPrinter that writes to the given writer.
+func NewSchemaPrinter(w io.Writer) *SchemaPrinter {
+	return &SchemaPrinter{w: w}
+}
+
+// Print generates the JSON Schema for the given fields.
+func (p *SchemaPrinter) Print(structName string, doc string, fields []FieldInfo) error {
+	root := objectSchema(fields)
+	root["$schema"] = schemaDraft
+	root["title"] = structName
+	if doc != "" {
+		root["description"] = doc
+	}
+
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(root)
+}
+
+// objectSchema returns the schema of a struct with the given fields.
+func objectSchema(fields []FieldInfo) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	addSchemaProperties(fields, properties, &required)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// addSchemaProperties adds the properties of fields, flattening inline
+// fields into the same property set.
+func addSchemaProperties(fields []FieldInfo, properties map[string]any, required *[]string) {
+	for i := range fields {
+		f := &fields[i]
+
+		key, inline, ok := fixtureKey(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			addSchemaProperties(f.Nested, properties, required)
+
+			continue
+		}
+
+		prop := fieldSchema(f)
+		if applySchemaRules(prop, f) && !hasAlternateSource(f) {
+			*required = append(*required, key)
+		}
+		properties[key] = prop
+	}
+}
+
+// fieldSchema returns the schema of a field, without its validate rules.
+func fieldSchema(f *FieldInfo) map[string]any {
+	typ := docutil.MapForm(f.Type)
+
+	var prop map[string]any
+	if len(f.Nested) > 0 && !isCollection(typ) {
+		prop = objectSchema(f.Nested)
+	} else {
+		prop = typeSchema(typ)
+	}
+
+	if desc := strings.TrimSpace(f.Description); desc != "" {
+		prop["description"] = desc
+	}
+	if def := f.Tags["default"]; def != "" && def != "-" {
+		prop["default"] = schemaValue(def, typ)
+	}
+
+	return prop
+}
+
+// typeSchema returns the schema for values of the named type.
+func typeSchema(typ string) map[string]any {
+	typ = strings.TrimLeft(typ, "*")
+
+	switch typ {
+	case "time.Duration", "fuda.Duration", "fuda.ByteSize":
+		return map[string]any{"type": []string{"string", "integer"}}
+	case "time.Time":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "[]byte":
+		return map[string]any{"type": "string"}
+	}
+
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		return map[string]any{"type": "array", "items": typeSchema(elem)}
+	}
+	if value, ok := mapValueType(typ); ok {
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(value)}
+	}
+
+	switch scalarKind(typ) {
+	case kindString:
+		return map[string]any{"type": "string"}
+	case kindBool:
+		return map[string]any{"type": "boolean"}
+	case kindInt:
+		return map[string]any{"type": "integer"}
+	case kindUint:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case kindFloat:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{} // struct or custom type; accept anything
+	}
+}
+
+// mapValueType returns the value type of a map type, such as "[]int" for
+// "map[string][]int".
+func mapValueType(typ string) (string, bool) {
+	rest, ok := strings.CutPrefix(typ, "map[")
+	if !ok {
+		return "", false
+	}
+
+	depth := 1
+	for i, r := range rest {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return rest[i+1:], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// schemaBoundKeys returns the keywords the min/max and gt/lt rules map to
+// for the named type: lengths for strings and collections, bounds for
+// numbers, and none for durations and sizes, which are strings in the file.
+func schemaBoundKeys(typ string) (minKey, maxKey, exMinKey, exMaxKey string) {
+	typ = strings.TrimLeft(typ, "*")
+
+	switch {
+	case typ == "[]byte":
+		return "minLength", "maxLength", "", ""
+	case strings.HasPrefix(typ, "[]"):
+		return "minItems", "maxItems", "", ""
+	case strings.HasPrefix(typ, "map["):
+		return "minProperties", "maxProperties", "", ""
+	}
+
+	switch scalarKind(typ) {
+	case kindString:
+		return "minLength", "maxLength", "", ""
+	case kindInt, kindUint, kindFloat:
+		return "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"
+	default:
+		return "", "", "", ""
+	}
+}
+
+// applySchemaRules translates the field's validate tag into schema keywords
+// and reports whether the field is required. Rules after "dive" apply to
+// elements and are ignored, as are rules with no schema equivalent.
+func applySchemaRules(prop map[string]any, f *FieldInfo) bool {
+	typ := docutil.MapForm(f.Type)
+	minKey, maxKey, exMinKey, exMaxKey := schemaBoundKeys(typ)
+	bound := func(key, param string) {
+		if key == "" {
+			return
+		}
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			prop[key] = jsonNumber(n)
+		}
+	}
+
+	rules := parseRules(f.Tags["validate"])
+	for _, r := range rules.field {
+		switch r.name {
+		case "min", "gte":
+			bound(minKey, r.param)
+		case "max", "lte":
+			bound(maxKey, r.param)
+		case "gt":
+			bound(exMinKey, r.param)
+		case "lt":
+			bound(exMaxKey, r.param)
+		case "len":
+			bound(minKey, r.param)
+			bound(maxKey, r.param)
+		case "oneof":
+			var enum []any
+			for _, v := range oneofValues(r.param) {
+				enum = append(enum, schemaValue(v, typ))
+			}
+			prop["enum"] = enum
+		case "email", "hostname", "ipv4", "ipv6", "uuid":
+			prop["format"] = r.name
+		case "url", "uri":
+			prop["format"] = "uri"
+		}
+	}
+
+	return rules.has("required")
+}
+
+// hasAlternateSource reports whether a field can be populated from something
+// other than the config file.
+func hasAlternateSource(f *FieldInfo) bool {
+	for _, key := range []string{"default", "env", "ref", "refFrom", "secretName", "dsn", "expr"} {
+		if v := f.Tags[key]; v != "" && v != "-" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schemaValue converts a tag value to the JSON value matching the named
+// type, falling back to the raw string.
+func schemaValue(raw, typ string) any {
+	typ = strings.TrimLeft(typ, "*")
+	if isCollection(typ) {
+		return schemaCollectionValue(raw, typ)
+	}
+
+	switch scalarKind(typ) {
+	case kindBool:
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	case kindInt, kindUint, kindFloat:
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return jsonNumber(n)
+		}
+	default:
+	}
+
+	return raw
+}
+
+// schemaCollectionValue converts the default tag of a collection, a JSON
+// literal or "a,b" ("k:v,k:v" for maps) with scalar elements.
+func schemaCollectionValue(raw, typ string) any {
+	var doc any
+	if docutil.IsJSONDefault(raw) && json.Unmarshal([]byte(raw), &doc) == nil {
+		return doc
+	}
+	elem, ok := elemType(typ)
+	if !ok {
+		return raw
+	}
+
+	if !strings.HasPrefix(typ, "map[") {
+		items := []any{}
+		for part := range strings.SplitSeq(raw, ",") {
+			items = append(items, schemaValue(strings.TrimSpace(part), elem))
+		}
+
+		return items
+	}
+
+	entries := map[string]any{}
+	for part := range strings.SplitSeq(raw, ",") {
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			entries[strings.TrimSpace(k)] = schemaValue(strings.TrimSpace(v), elem)
+		}
+	}
+
+	return entries
+}
+
+// jsonNumber returns n as an int when it has no fractional part, so bounds
+// render as 1 rather than 1.0 in tools that distinguish them.
+func jsonNumber(n float64) any {
+	if n == float64(int64(n)) {
+		return int64(n)
+	}
+
+	return n
+}
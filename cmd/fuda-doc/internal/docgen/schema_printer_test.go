@@ -0,0 +1,86 @@
+package docgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+)
+
+const schemaConfig = `package config
+
+import "time"
+
+// Config is the app config.
+type Config struct {
+	// Host is the host to listen on.
+	Host    string        ` + "`" + `yaml:"host" validate:"required,hostname"` + "`" + `
+	Port    int           ` + "`" + `yaml:"port" default:"8080" validate:"min=1,max=65535"` + "`" + `
+	Mode    string        ` + "`" + `yaml:"mode" validate:"oneof=dev prod"` + "`" + `
+	Timeout time.Duration ` + "`" + `yaml:"timeout" default:"5s"` + "`" + `
+	Tags    []string      ` + "`" + `yaml:"tags" default:"a,b" validate:"max=3,dive,min=1"` + "`" + `
+	Limits  map[string]uint ` + "`" + `yaml:"limits"` + "`" + `
+	Token   string        ` + "`" + `yaml:"token" env:"TOKEN" validate:"required"` + "`" + `
+	Common  Common        ` + "`" + `yaml:",inline"` + "`" + `
+	DB      *Database     ` + "`" + `yaml:"db"` + "`" + `
+	Skipped string        ` + "`" + `yaml:"-"` + "`" + `
+}
+
+type Common struct {
+	Debug bool ` + "`" + `yaml:"debug" default:"true"` + "`" + `
+}
+
+type Database struct {
+	URL string ` + "`" + `yaml:"url" validate:"required,url"` + "`" + `
+}
+`
+
+func TestGenerate_Schema(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.go": schemaConfig})
+
+	var buf bytes.Buffer
+	if err := docgen.Generate("Config", dir, &buf, docgen.FormatSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "Config",
+		"description": "Config is the app config.",
+		"type":        "object",
+		"required":    []any{"host"},
+		"properties": map[string]any{
+			"host":    map[string]any{"type": "string", "format": "hostname", "description": "Host is the host to listen on."},
+			"port":    map[string]any{"type": "integer", "default": 8080.0, "minimum": 1.0, "maximum": 65535.0},
+			"mode":    map[string]any{"type": "string", "enum": []any{"dev", "prod"}},
+			"timeout": map[string]any{"type": []any{"string", "integer"}, "default": "5s"},
+			"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "default": []any{"a", "b"}, "maxItems": 3.0},
+			"limits":  map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer", "minimum": 0.0}},
+			"token":   map[string]any{"type": "string"},
+			"debug":   map[string]any{"type": "boolean", "default": true},
+			"db": map[string]any{
+				"type":       "object",
+				"required":   []any{"url"},
+				"properties": map[string]any{"url": map[string]any{"type": "string", "format": "uri"}},
+			},
+		},
+	}
+
+	for key, w := range want["properties"].(map[string]any) {
+		g := got["properties"].(map[string]any)[key]
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("property %s = %v, want %v", key, g, w)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Schema:\n%s", buf.String())
+	}
+}
@@ -119,3 +119,11 @@ type NoComments struct {
 	Alpha string `yaml:"alpha" default:"a"`
 	Beta  int    `yaml:"beta" default:"1"`
 }
+
+// TagDocs documents fields with doc tags instead of comments.
+type TagDocs struct {
+	Region string `yaml:"region" doc:"Deployment region"`
+
+	// Zone comes from the comment, which wins over the doc tag.
+	Zone string `yaml:"zone" doc:"ignored"`
+}
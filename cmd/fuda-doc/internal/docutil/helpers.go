@@ -3,6 +3,7 @@
 package docutil
 
 import (
+	"encoding/json"
 	"strings"
 )
 
@@ -39,41 +40,76 @@ func YAMLKey(f *FieldInfo) string {
 	return key
 }
 
+// MapForm returns the Go map type written for an ordered map type, such as
+// "map[string]Stage" for "fuda.OrderedMap[string, Stage]", so ordered maps
+// are rendered like maps (keeping the order of their default tag). Other
+// types are returned unchanged.
+func MapForm(typ string) string {
+	ptr := ""
+	for strings.HasPrefix(typ, "*") {
+		ptr += "*"
+		typ = typ[1:]
+	}
+
+	args, ok := strings.CutPrefix(typ, "fuda.OrderedMap[")
+	if !ok || !strings.HasSuffix(args, "]") {
+		return ptr + typ
+	}
+	args = args[:len(args)-1]
+
+	// Split "K, V" at the top-level comma
+	depth := 0
+	for i, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				return ptr + "map[" + strings.TrimSpace(args[:i]) + "]" + strings.TrimSpace(args[i+1:])
+			}
+		}
+	}
+
+	return ptr + typ
+}
+
 // YAMLDefault returns a YAML-friendly default value string for a field,
 // choosing appropriate formatting based on the field's type.
 func YAMLDefault(f *FieldInfo) string {
 	d := f.Tags["default"]
 
-	switch {
-	case strings.HasPrefix(f.Type, "map"):
+	switch typ := MapForm(f.Type); {
+	case strings.HasPrefix(typ, "map"):
 		return FormatMapDefault(d)
-	case strings.HasPrefix(f.Type, "[]byte"):
+	case strings.HasPrefix(typ, "[]byte"):
 		if d == "" {
 			return "null"
 		}
 
 		return d
-	case strings.HasPrefix(f.Type, "[]"):
+	case strings.HasPrefix(typ, "[]"):
 		return FormatSliceDefault(d)
-	case f.Type == "string":
+	case typ == "string":
 		if d == "" {
 			return `""`
 		}
 
 		return `"` + d + `"`
-	case f.Type == "bool":
+	case typ == "bool":
 		if d == "" {
 			return "false"
 		}
 
 		return d
-	case f.Type == "time.Duration":
+	case typ == "time.Duration":
 		if d == "" {
 			return "0s"
 		}
 
 		return d
-	case strings.Contains(f.Type, "int") || strings.Contains(f.Type, "float"):
+	case strings.Contains(typ, "int") || strings.Contains(typ, "float"):
 		if d == "" {
 			return "0"
 		}
@@ -94,6 +130,9 @@ func FormatMapDefault(d string) string {
 	if d == "" {
 		return "{}"
 	}
+	if IsJSONDefault(d) {
+		return strings.TrimSpace(d)
+	}
 
 	var entries []string
 
@@ -117,6 +156,9 @@ func FormatSliceDefault(d string) string {
 	if d == "" {
 		return "[]"
 	}
+	if IsJSONDefault(d) {
+		return strings.TrimSpace(d)
+	}
 
 	items := strings.Split(d, ",")
 	trimmed := make([]string, len(items))
@@ -128,6 +170,18 @@ func FormatSliceDefault(d string) string {
 	return "[" + strings.Join(trimmed, ", ") + "]"
 }
 
+// IsJSONDefault reports whether a default tag is a JSON array or object,
+// such as `[{"host":"a"}]` for a slice of structs. JSON is valid YAML, so it
+// is written as is.
+func IsJSONDefault(d string) bool {
+	d = strings.TrimSpace(d)
+	if !strings.HasPrefix(d, "[") && !strings.HasPrefix(d, "{") {
+		return false
+	}
+
+	return json.Valid([]byte(d))
+}
+
 // IsExported returns true if a Go identifier starts with an uppercase letter.
 func IsExported(name string) bool {
 	if len(name) == 0 {
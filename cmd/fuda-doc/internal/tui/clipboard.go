@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+const (
+	// osc52Limit is the largest text sent with OSC 52. Terminals such as
+	// xterm ignore longer sequences, so the copy would fail silently.
+	osc52Limit = 100_000
+	// clipboardTimeout bounds native clipboard tools, so a stuck tool
+	// cannot freeze the TUI.
+	clipboardTimeout = 2 * time.Second
+)
+
+// clipboardOut receives OSC 52 sequences. Stderr is the terminal too, and
+// writing there does not interleave with the renderer on stdout.
+var clipboardOut io.Writer = os.Stderr
+
+// clipboardTool is a native command that copies its stdin to the clipboard.
+type clipboardTool struct {
+	name string
+	args []string
+}
+
+// copyToClipboard copies text to the system clipboard and returns how it
+// was copied: with a native tool such as pbcopy, wl-copy, xclip, xsel, or
+// clip.exe, or else with an OSC 52 escape sequence, which the terminal
+// handles. Over SSH, OSC 52 is used first, as it reaches the clipboard of
+// the local machine rather than the remote one.
+func copyToClipboard(text string) (string, error) {
+	ssh := os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+	if !ssh {
+		if tool, ok := nativeClipboard(); ok {
+			if err := runClipboardTool(tool, text); err == nil {
+				return tool.name, nil
+			}
+		}
+	}
+
+	if len(text) > osc52Limit {
+		return "", fmt.Errorf("%d bytes is too large for the terminal clipboard, save to a file instead", len(text))
+	}
+
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		// Screen limits the length of a sequence, so it is sent in chunks
+		seq = seq.Screen()
+	}
+	if _, err := seq.WriteTo(clipboardOut); err != nil {
+		return "", fmt.Errorf("writing OSC 52 sequence: %w", err)
+	}
+
+	return "OSC 52", nil
+}
+
+// nativeClipboard returns the first clipboard tool found for this platform
+// and display server.
+func nativeClipboard() (clipboardTool, bool) {
+	var tools []clipboardTool
+	switch runtime.GOOS {
+	case "darwin":
+		tools = []clipboardTool{{name: "pbcopy"}}
+	case "windows":
+		tools = []clipboardTool{{name: "clip.exe"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			tools = append(tools, clipboardTool{name: "wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			tools = append(tools,
+				clipboardTool{name: "xclip", args: []string{"-selection", "clipboard"}},
+				clipboardTool{name: "xsel", args: []string{"--clipboard", "--input"}},
+			)
+		}
+	}
+
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool.name); err == nil {
+			return tool, true
+		}
+	}
+
+	return clipboardTool{}, false
+}
+
+// runClipboardTool pipes text into tool. Its output is discarded rather
+// than captured: xclip forks a child that owns the selection and would keep
+// the output pipe open.
+func runClipboardTool(tool clipboardTool, text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, tool.name, tool.args...) //nolint:gosec // fixed tool names
+	cmd.Stdin = strings.NewReader(text)
+
+	return cmd.Run()
+}
@@ -104,6 +104,10 @@ func (d *detailModel) buildFieldDetail(n *Node) {
 		d.addProp("DSN tmpl", v)
 	}
 
+	if v := f.Tags["expr"]; v != "" {
+		d.addProp("Expr", v)
+	}
+
 	if v := f.Tags["validate"]; v != "" {
 		d.addProp("Validate", v)
 	}
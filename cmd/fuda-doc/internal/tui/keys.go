@@ -20,6 +20,7 @@ type KeyMap struct {
 	Help        key.Binding
 	Filter      key.Binding
 	Save        key.Binding
+	ExportCopy  key.Binding
 	Quit        key.Binding
 }
 
@@ -88,6 +89,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "save/export"),
 		),
+		ExportCopy: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "copy to clipboard"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
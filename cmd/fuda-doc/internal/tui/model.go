@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"strings"
@@ -421,7 +422,7 @@ func (m *Model) refreshPanels() {
 // ---------------------------------------------------------------------------
 
 // copyYAMLPath builds the dotted YAML path of the selected node and copies
-// it to the clipboard using OSC 52 escape sequence.
+// it to the clipboard (see copyToClipboard).
 func (m *Model) copyYAMLPath() {
 	n := m.tree.selected()
 	if n == nil || n.IsRoot {
@@ -452,6 +453,11 @@ func (m *Model) copyYAMLPath() {
 	}
 
 	path := strings.Join(parts, ".")
+	if _, err := copyToClipboard(path); err != nil {
+		m.setFlash("Error: "+err.Error(), flashDurationError)
+
+		return
+	}
 	m.setFlash("Copied: "+path, flashDurationInfo)
 }
 
@@ -473,7 +479,7 @@ func (m Model) helpOverlay() string {
 		{"Esc", "Clear search or filter"},
 		{"y", "Copy YAML path of selected field"},
 		{"f", "Filter by tag"},
-		{"s", "Export (Markdown / YAML / .env)"},
+		{"s", "Export (Markdown / YAML / .env) to file or clipboard"},
 		{"?", "Show/hide this help"},
 		{"q / Ctrl+C", "Quit"},
 		{"", ""},
@@ -517,7 +523,7 @@ const clearFilterLabel = "(clear filter)"
 // allFilterTags is the pre-built filter list (clear sentinel + known tags).
 // Built once to avoid allocation on every openFilter call.
 var allFilterTags = append([]string{clearFilterLabel},
-	"env", "default", "validate", "ref", "refFrom", "dsn", "required",
+	"env", "default", "validate", "ref", "refFrom", "dsn", "expr", "required",
 )
 
 func (m *Model) openFilter() {
@@ -630,7 +636,7 @@ func (m Model) filterOverlay() string {
 
 	sb.WriteString("\n")
 	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8b9dab")).
-		Render("space select • esc cancel"))
+		Render("space save • c copy to clipboard • esc cancel"))
 
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -686,7 +692,13 @@ func (m Model) handleExportKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Toggle):
 		m.exportActive = false
-		m.doExport(m.exportItems[m.exportCursor])
+		m.doExport(m.exportItems[m.exportCursor], false)
+
+		return m, nil
+
+	case key.Matches(msg, m.keys.ExportCopy):
+		m.exportActive = false
+		m.doExport(m.exportItems[m.exportCursor], true)
 
 		return m, nil
 	}
@@ -694,28 +706,49 @@ func (m Model) handleExportKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m *Model) doExport(item exportItem) {
+// doExport renders the selected struct in the format of item and writes it
+// to a file in the working directory, or copies it to the clipboard.
+func (m *Model) doExport(item exportItem, toClipboard bool) {
 	root := m.findSelectedRoot()
 	if root == nil || root.StructDoc == nil {
 		return
 	}
 
 	doc := root.StructDoc
-	baseName := strings.ToLower(doc.Name)
+	docs := []docgen.StructDoc{*doc}
 
-	var filename string
+	var buf bytes.Buffer
+	var err error
 
 	switch item.ext {
 	case ".md":
-		filename = baseName + ".md"
-		m.exportMarkdown(filename, doc)
+		docgen.NewMarkdownPrinter(&buf).Print(doc.Name, doc.Doc, doc.Fields)
 	case ".yaml":
-		filename = baseName + ".yaml"
-		m.exportYAML(filename, doc)
+		err = docgen.PrintDefaultYAML(docs, &buf, true)
 	case ".env.example":
-		filename = baseName + ".env.example"
-		m.exportEnvFile(filename, doc)
+		err = docgen.PrintEnvFile(docs, &buf)
 	}
+
+	if err == nil && toClipboard {
+		var method string
+		if method, err = copyToClipboard(buf.String()); err == nil {
+			m.setFlash("Copied "+item.label+" to clipboard ("+method+")", flashDurationInfo)
+
+			return
+		}
+	}
+
+	filename := strings.ToLower(doc.Name) + item.ext
+	if err == nil {
+		err = os.WriteFile(filename, buf.Bytes(), 0o644) //nolint:gosec // exports are meant to be readable
+	}
+	if err != nil {
+		m.setFlash("Error: "+err.Error(), flashDurationError)
+
+		return
+	}
+
+	m.setFlash("Saved: "+filename, flashDurationInfo)
 }
 
 func (m *Model) findSelectedRoot() *Node {
@@ -743,51 +776,6 @@ func (m *Model) setFlash(msg string, d time.Duration) {
 	m.flashEnd = time.Now().Add(d)
 }
 
-func (m *Model) exportMarkdown(filename string, doc *docgen.StructDoc) {
-	f, err := os.Create(filename)
-	if err != nil {
-		m.setFlash("Error: "+err.Error(), flashDurationError)
-
-		return
-	}
-
-	printer := docgen.NewMarkdownPrinter(f)
-	printer.Print(doc.Name, doc.Doc, doc.Fields)
-	_ = f.Close()
-
-	m.setFlash("Saved: "+filename, flashDurationInfo)
-}
-
-func (m *Model) exportYAML(filename string, doc *docgen.StructDoc) {
-	f, err := os.Create(filename)
-	if err != nil {
-		m.setFlash("Error: "+err.Error(), flashDurationError)
-
-		return
-	}
-
-	docs := []docgen.StructDoc{*doc}
-	_ = docgen.PrintDefaultYAML(docs, f, true)
-	_ = f.Close()
-
-	m.setFlash("Saved: "+filename, flashDurationInfo)
-}
-
-func (m *Model) exportEnvFile(filename string, doc *docgen.StructDoc) {
-	f, err := os.Create(filename)
-	if err != nil {
-		m.setFlash("Error: "+err.Error(), flashDurationError)
-
-		return
-	}
-
-	docs := []docgen.StructDoc{*doc}
-	_ = docgen.PrintEnvFile(docs, f)
-	_ = f.Close()
-
-	m.setFlash("Saved: "+filename, flashDurationInfo)
-}
-
 func (m Model) exportOverlay() string {
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5eead4")).
 		Render("Export / Save")
@@ -812,7 +800,7 @@ func (m Model) exportOverlay() string {
 
 	sb.WriteString("\n")
 	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8b9dab")).
-		Render("space select • esc cancel"))
+		Render("space save • c copy to clipboard • esc cancel"))
 
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
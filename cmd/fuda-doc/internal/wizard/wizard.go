@@ -0,0 +1,312 @@
+// Package wizard implements the interactive "fuda-doc init" wizard, which
+// walks through the fields of a config struct one by one and collects a
+// value for each.
+package wizard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/colors"
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docutil"
+)
+
+// ErrCanceled is returned by Run when the user quits without confirming.
+var ErrCanceled = errors.New("init canceled")
+
+// styles
+var (
+	titleStyle  = colors.TUITitleStyle
+	nameStyle   = colors.FieldStyle
+	labelStyle  = colors.LabelStyle
+	valueStyle  = colors.ValueStyle
+	typeStyle   = colors.TypeStyle
+	mutedStyle  = colors.MutedStyle
+	helpStyle   = colors.TUIHelpStyle
+	promptStyle = colors.SearchPromptStyle
+	inputStyle  = colors.SearchInputStyle
+	errorStyle  = lipgloss.NewStyle().Bold(true).Foreground(colors.Sand)
+)
+
+// keyMap defines the wizard keybindings.
+type keyMap struct {
+	Next      key.Binding
+	Back      key.Binding
+	Backspace key.Binding
+	Clear     key.Binding
+	Write     key.Binding
+	Quit      key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Next: key.NewBinding(
+			key.WithKeys("enter", "tab"),
+			key.WithHelp("enter", "next"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("shift+tab", "up"),
+			key.WithHelp("shift+tab", "back"),
+		),
+		Backspace: key.NewBinding(
+			key.WithKeys("backspace"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("ctrl+u"),
+			key.WithHelp("ctrl+u", "clear"),
+		),
+		Write: key.NewBinding(
+			key.WithKeys("w", "y"),
+			key.WithHelp("w", "write files"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("esc", "ctrl+c"),
+			key.WithHelp("esc", "quit"),
+		),
+	}
+}
+
+// Model is the bubbletea model of the wizard.
+type Model struct {
+	structName string
+	fields     []docgen.InitField
+	values     []string
+	keys       keyMap
+
+	index    int    // current field; len(fields) shows the summary
+	buf      string // text being typed for the current field
+	err      string // why the current input was rejected
+	width    int
+	done     bool // the user confirmed the summary
+	canceled bool
+}
+
+// New creates a wizard asking for the given fields of structName.
+func New(structName string, fields []docgen.InitField) Model {
+	return Model{
+		structName: structName,
+		fields:     fields,
+		values:     make([]string, len(fields)),
+		keys:       defaultKeyMap(),
+	}
+}
+
+// Values returns the entered values, by field position. Empty values keep
+// the field default.
+func (m Model) Values() []string {
+	return m.values
+}
+
+// Done reports whether the user confirmed the entered values.
+func (m Model) Done() bool {
+	return m.done
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+		return m, nil
+	case tea.KeyMsg:
+		if key.Matches(msg, m.keys.Quit) {
+			m.canceled = true
+
+			return m, tea.Quit
+		}
+		if m.index == len(m.fields) {
+			return m.handleSummaryKey(msg)
+		}
+
+		return m.handleFieldKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleFieldKey processes keys while a field is being edited.
+func (m Model) handleFieldKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Next):
+		if err := m.fields[m.index].Check(m.buf); err != nil {
+			m.err = err.Error()
+
+			return m, nil
+		}
+		m.values[m.index] = strings.TrimSpace(m.buf)
+		m.move(m.index + 1)
+	case key.Matches(msg, m.keys.Back):
+		if m.index > 0 {
+			m.values[m.index] = strings.TrimSpace(m.buf)
+			m.move(m.index - 1)
+		}
+	case key.Matches(msg, m.keys.Backspace):
+		if r := []rune(m.buf); len(r) > 0 {
+			m.buf = string(r[:len(r)-1])
+		}
+		m.err = ""
+	case key.Matches(msg, m.keys.Clear):
+		m.buf, m.err = "", ""
+	case msg.Type == tea.KeyRunes:
+		m.buf += string(msg.Runes)
+		m.err = ""
+	case msg.Type == tea.KeySpace:
+		m.buf += " "
+		m.err = ""
+	}
+
+	return m, nil
+}
+
+// handleSummaryKey processes keys on the summary screen.
+func (m Model) handleSummaryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Write), msg.Type == tea.KeyEnter:
+		// Values left with shift+tab were not checked yet
+		for i := range m.fields {
+			if err := m.fields[i].Check(m.values[i]); err != nil {
+				m.move(i)
+				m.err = err.Error()
+
+				return m, nil
+			}
+		}
+		m.done = true
+
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		if len(m.fields) > 0 {
+			m.move(len(m.fields) - 1)
+		}
+	}
+
+	return m, nil
+}
+
+// move switches to field i, loading its entered value for editing.
+func (m *Model) move(i int) {
+	m.index = i
+	m.err = ""
+	m.buf = ""
+	if i < len(m.fields) {
+		m.buf = m.values[i]
+	}
+}
+
+// View implements tea.Model.
+func (m Model) View() string {
+	if m.done || m.canceled {
+		return ""
+	}
+
+	var sb strings.Builder
+	if m.index == len(m.fields) {
+		m.summaryView(&sb)
+	} else {
+		m.fieldView(&sb)
+	}
+
+	return sb.String()
+}
+
+// fieldView renders the prompt for the current field.
+func (m Model) fieldView(sb *strings.Builder) {
+	f := &m.fields[m.index]
+	fmt.Fprintf(sb, "%s %s\n\n", titleStyle.Render("fuda-doc init · "+m.structName),
+		mutedStyle.Render(fmt.Sprintf("field %d of %d", m.index+1, len(m.fields))))
+
+	name := nameStyle.Render(f.Name())
+	if f.Required {
+		name += " " + errorStyle.Render("(required)")
+	}
+	sb.WriteString("  " + name + "\n")
+
+	if f.Description != "" {
+		width := max(m.width-4, 40) //nolint:mnd // keep descriptions readable in narrow terminals
+		for _, line := range docutil.WordWrap(f.Description, width) {
+			sb.WriteString("  " + valueStyle.Render(line) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+
+	m.detailLine(sb, "Type", typeStyle.Render(f.Type))
+	if f.Path != "" && f.Env != "" {
+		m.detailLine(sb, "Env", valueStyle.Render(f.Env)+mutedStyle.Render(" (written to .env)"))
+	}
+	if f.Default != "" {
+		m.detailLine(sb, "Default", valueStyle.Render(f.Default)+mutedStyle.Render(" (leave empty to keep)"))
+	}
+	if f.Validate != "" {
+		m.detailLine(sb, "Validate", valueStyle.Render(f.Validate))
+	}
+
+	fmt.Fprintf(sb, "\n  %s%s\n", promptStyle.Render("> "), inputStyle.Render(m.buf+"█"))
+	if m.err != "" {
+		sb.WriteString("  " + errorStyle.Render("✗ "+m.err) + "\n")
+	}
+
+	sb.WriteString("\n" + helpStyle.Render("  enter next · shift+tab back · ctrl+u clear · esc quit") + "\n")
+}
+
+// detailLine renders one labeled line of field details.
+func (m Model) detailLine(sb *strings.Builder, label, value string) {
+	sb.WriteString("  " + labelStyle.Render(docutil.PadRight(label+":", 10)) + value + "\n") //nolint:mnd // label column width
+}
+
+// summaryView renders the entered values for confirmation.
+func (m Model) summaryView(sb *strings.Builder) {
+	fmt.Fprintf(sb, "%s %s\n\n", titleStyle.Render("fuda-doc init · "+m.structName), mutedStyle.Render("summary"))
+
+	width := 0
+	for i := range m.fields {
+		width = max(width, len(m.fields[i].Name()))
+	}
+
+	for i := range m.fields {
+		f := &m.fields[i]
+		value := valueStyle.Render(m.values[i])
+		switch {
+		case m.values[i] == "" && f.Default != "":
+			value = mutedStyle.Render("default (" + f.Default + ")")
+		case m.values[i] == "":
+			value = mutedStyle.Render("unset")
+		}
+		target := "config.yaml"
+		if f.Env != "" {
+			target = ".env"
+		}
+		fmt.Fprintf(sb, "  %s  %s %s\n", nameStyle.Render(docutil.PadRight(f.Name(), width)), value,
+			mutedStyle.Render("→ "+target))
+	}
+
+	sb.WriteString("\n" + helpStyle.Render("  w write files · shift+tab back · esc quit") + "\n")
+}
+
+// Run runs the wizard for fields of structName and returns the confirmed
+// values, or ErrCanceled if the user quits.
+func Run(structName string, fields []docgen.InitField) ([]string, error) {
+	p := tea.NewProgram(New(structName, fields), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("tui error: %w", err)
+	}
+
+	m, ok := final.(Model)
+	if !ok || !m.Done() {
+		return nil, ErrCanceled
+	}
+
+	return m.Values(), nil
+}
@@ -0,0 +1,71 @@
+package wizard_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/docgen"
+	"github.com/arloliu/fuda/cmd/fuda-doc/internal/wizard"
+)
+
+// send feeds keys to m: runes are typed, names such as "enter" are pressed.
+func send(m tea.Model, keys ...string) tea.Model {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "shift+tab":
+			msg = tea.KeyMsg{Type: tea.KeyShiftTab}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m, _ = m.Update(msg)
+	}
+
+	return m
+}
+
+func TestWizard(t *testing.T) {
+	t.Parallel()
+
+	fields := []docgen.InitField{
+		{Path: "port", Type: "int", Default: "8080"},
+		{Path: "name", Type: "string", Required: true},
+	}
+
+	var m tea.Model = wizard.New("Config", fields)
+	m = send(m, "80x", "enter")
+	if !strings.Contains(m.View(), "must be an integer") {
+		t.Fatalf("invalid input not rejected:\n%s", m.View())
+	}
+
+	m = send(m, "backspace", "enter")
+	if !strings.Contains(m.View(), "field 2 of 2") {
+		t.Fatalf("did not advance:\n%s", m.View())
+	}
+
+	// Going back keeps the entered value for editing
+	m = send(m, "shift+tab", "9", "enter", "enter")
+	if !strings.Contains(m.View(), "a value is required") {
+		t.Fatalf("required field not enforced:\n%s", m.View())
+	}
+
+	m = send(m, "api", "enter")
+	if !strings.Contains(m.View(), "summary") {
+		t.Fatalf("summary not shown:\n%s", m.View())
+	}
+
+	m = send(m, "w")
+	wm := m.(wizard.Model)
+	if !wm.Done() {
+		t.Fatal("not done after write")
+	}
+	if got := strings.Join(wm.Values(), ","); got != "809,api" {
+		t.Errorf("values = %q", got)
+	}
+}
@@ -24,6 +24,8 @@ var (
 	targetPath   = flag.String("path", "", "Directory or file path containing the struct (required)")
 	outputTarget = flag.String("output", "stdout", "Output target: file path or \"stdout\"")
 	markdown     = flag.Bool("markdown", false, "Output in Markdown format")
+	htmlOutput   = flag.Bool("html", false, "Output a standalone HTML page")
+	schemaOutput = flag.Bool("schema", false, "Output a JSON Schema of the config file")
 	ascii        = flag.Bool("ascii", false, "Output in terminal-friendly format with ANSI colors")
 	noPager      = flag.Bool("no-pager", false, "Disable built-in pager for ASCII output")
 	forceColor   = flag.Bool("color", false, "Force ANSI color output even when stdout is not a TTY (useful with: | less -R)")
@@ -46,12 +48,19 @@ func init() {
 	flag.BoolVar(showVersion, "v", false, "Short for -version")
 
 	flag.Usage = func() {
-		_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-doc [flags]\n\n")
+		_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-doc [flags]\n")
+		_, _ = fmt.Fprint(os.Stderr, "       fuda-doc init -s <struct> -p <path> [-o <dir>] [-f]\n")
+		_, _ = fmt.Fprint(os.Stderr, "       fuda-doc fixtures -s <struct> -p <path> [-o <dir>]\n")
+		_, _ = fmt.Fprint(os.Stderr, "       fuda-doc deprecations -s <struct> -p <path> [-r <repo>]\n")
+		_, _ = fmt.Fprint(os.Stderr, "       fuda-doc graph -s <struct> [-p <path>] [--dot | --mermaid]\n")
+		_, _ = fmt.Fprint(os.Stderr, "       fuda-doc report -s <struct> [-p <path>] [--json]\n\n")
 		_, _ = fmt.Fprint(os.Stderr, "Flags:\n")
 		_, _ = fmt.Fprint(os.Stderr, "  -s, --struct string    Struct name to generate docs for (required unless -tui)\n")
 		_, _ = fmt.Fprint(os.Stderr, "  -p, --path string      Directory or file path containing the struct (required)\n")
 		_, _ = fmt.Fprint(os.Stderr, "  -o, --output string    Output target: file path or \"stdout\" (default \"stdout\")\n")
 		_, _ = fmt.Fprint(os.Stderr, "  -m, --markdown         Output in Markdown format\n")
+		_, _ = fmt.Fprint(os.Stderr, "      --html             Output a standalone HTML page (search, collapsible sections)\n")
+		_, _ = fmt.Fprint(os.Stderr, "      --schema           Output a JSON Schema of the config file (for fuda-watch, editors)\n")
 		_, _ = fmt.Fprint(os.Stderr, "  -a, --ascii            Output in terminal-friendly format with ANSI colors\n")
 		_, _ = fmt.Fprint(os.Stderr, "      --no-pager         Disable built-in pager for ASCII output\n")
 		_, _ = fmt.Fprint(os.Stderr, "  -c, --color            Force ANSI color output (useful with: | less -R)\n")
@@ -70,7 +79,23 @@ func main() {
 	}
 }
 
+// subcommands maps the subcommand names to their handlers, which receive
+// the arguments after the name.
+var subcommands = map[string]func(args []string) error{
+	"init":         runInit,
+	"fixtures":     runFixtures,
+	"deprecations": runDeprecations,
+	"graph":        runGraph,
+	"report":       runReport,
+}
+
 func run() error {
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			return cmd(os.Args[2:])
+		}
+	}
+
 	flag.Parse()
 
 	if *showVersion {
@@ -97,28 +122,11 @@ func run() error {
 		return runTUI()
 	}
 
-	if *targetStruct == "" || *targetPath == "" {
-		if *targetStruct == "" {
-			_, _ = fmt.Fprintln(os.Stderr, "Error: -struct flag is required")
-		}
-
-		if *targetPath == "" {
-			_, _ = fmt.Fprintln(os.Stderr, "Error: -path flag is required")
-		}
-
-		_, _ = fmt.Fprintln(os.Stderr)
-		flag.Usage()
-
-		return errors.New("required flags missing")
+	if err := checkRequiredFlags(); err != nil {
+		return err
 	}
 
-	// Determine format — default to ascii if neither specified
-	format := docgen.FormatASCII
-	if *markdown {
-		format = docgen.FormatMarkdown
-	} else if *ascii {
-		format = docgen.FormatASCII
-	}
+	format := outputFormat()
 
 	// Determine if we should use the built-in pager:
 	// pager is enabled when ASCII format + stdout + TTY + not disabled
@@ -133,6 +141,40 @@ func run() error {
 	return runDirect(format, toStdout)
 }
 
+// checkRequiredFlags reports the missing -struct and -path flags.
+func checkRequiredFlags() error {
+	if *targetStruct != "" && *targetPath != "" {
+		return nil
+	}
+
+	if *targetStruct == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "Error: -struct flag is required")
+	}
+
+	if *targetPath == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "Error: -path flag is required")
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr)
+	flag.Usage()
+
+	return errors.New("required flags missing")
+}
+
+// outputFormat returns the format selected by the flags, ASCII by default.
+func outputFormat() docgen.OutputFormat {
+	switch {
+	case *markdown:
+		return docgen.FormatMarkdown
+	case *htmlOutput:
+		return docgen.FormatHTML
+	case *schemaOutput:
+		return docgen.FormatSchema
+	default:
+		return docgen.FormatASCII
+	}
+}
+
 func runWithPager(format docgen.OutputFormat) error {
 	// Force color output for the pager (lipgloss may disable colors for non-TTY writers)
 	lipgloss.SetColorProfile(termenv.TrueColor)
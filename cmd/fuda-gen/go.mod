@@ -0,0 +1,3 @@
+module github.com/arloliu/fuda/cmd/fuda-gen
+
+go 1.25
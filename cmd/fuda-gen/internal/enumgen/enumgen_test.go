@@ -0,0 +1,233 @@
+package enumgen_test
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda/cmd/fuda-gen/internal/enumgen"
+)
+
+// testdataDir returns the absolute path to the testdata directory.
+func testdataDir(t *testing.T) string {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("unable to determine test file path")
+	}
+
+	return filepath.Join(filepath.Dir(file), "testdata")
+}
+
+func TestParse_Struct(t *testing.T) {
+	t.Parallel()
+
+	pkg, err := enumgen.Parse(testdataDir(t), "Config")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if pkg.Name != "testdata" {
+		t.Errorf("package name = %q, want %q", pkg.Name, "testdata")
+	}
+
+	want := []enumgen.Enum{
+		{Name: "LogLevel", Fields: []string{"Config.LogLevel", "Server.LogLevel"}, Values: []string{"debug", "info", "warn", "error"}},
+		{Name: "Region", Fields: []string{"Config.Region"}, Values: []string{"us-east-1", "eu-west-1"}},
+		{Name: "Formats", Fields: []string{"Config.Formats"}, Values: []string{"json", "text"}},
+		{Name: "Color", Fields: []string{"Config.Color"}, Values: []string{"light blue", "red"}},
+		{Name: "Protocol", Fields: []string{"Worker.Protocol"}, Values: []string{"tcp", "udp"}},
+		{Name: "Mode", Fields: []string{"Config.Inline.Mode"}, Values: []string{"fast", "safe"}},
+	}
+	if !reflect.DeepEqual(pkg.Enums, want) {
+		t.Errorf("Enums =\n%+v\nwant\n%+v", pkg.Enums, want)
+	}
+}
+
+func TestParse_AllStructs(t *testing.T) {
+	t.Parallel()
+
+	pkg, err := enumgen.Parse(testdataDir(t), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var names []string
+	for _, e := range pkg.Enums {
+		names = append(names, e.Name)
+	}
+	if got := strings.Join(names, ","); !strings.Contains(got, "Kind") {
+		t.Errorf("enums = %s, want Unreachable.Kind included", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "conflicting values",
+			src: `package p
+type A struct { Level string ` + "`validate:\"oneof=a b\"`" + ` }
+type B struct { Level string ` + "`validate:\"oneof=a c\"`" + ` }
+`,
+			wantErr: "different values",
+		},
+		{
+			name: "declared type",
+			src: `package p
+type Level int
+type A struct { Level string ` + "`validate:\"oneof=a b\"`" + ` }
+`,
+			wantErr: "conflicts with a type declared",
+		},
+		{
+			name: "duplicate constant",
+			src: `package p
+type A struct { Sep string ` + "`validate:\"oneof=a-b a_b\"`" + ` }
+`,
+			wantErr: "both map to constant SepAB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(tt.src), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := enumgen.Parse(dir, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := enumgen.Parse(testdataDir(t), "Missing"); err == nil {
+		t.Error("Parse with unknown struct: expected error")
+	}
+}
+
+func TestParse_KeepsGeneratedTypeName(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := `package p
+type A struct { Level ConsoleLevel ` + "`validate:\"oneof=a b\"`" + ` }
+`
+	generated := `// Code generated by fuda-gen enum; DO NOT EDIT.
+
+package p
+
+type ConsoleLevel string
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fuda_enums.go"), []byte(generated), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := enumgen.Parse(dir, "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(pkg.Enums) != 1 || pkg.Enums[0].Name != "ConsoleLevel" {
+		t.Errorf("Enums = %+v, want one ConsoleLevel", pkg.Enums)
+	}
+}
+
+func TestConstName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"debug", "LevelDebug"},
+		{"us-east-1", "LevelUsEast1"},
+		{"light blue", "LevelLightBlue"},
+		{"snake_case", "LevelSnakeCase"},
+		{"HTTP", "LevelHTTP"},
+		{"-", "LevelEmpty"},
+	}
+
+	for _, tt := range tests {
+		if got := enumgen.ConstName("Level", tt.value); got != tt.want {
+			t.Errorf("ConstName(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	pkg, err := enumgen.Parse(testdataDir(t), "Config")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := enumgen.Generate(&buf, pkg); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	src := buf.String()
+
+	for _, want := range []string{
+		"// Code generated by fuda-gen enum; DO NOT EDIT.",
+		"package testdata",
+		"// LogLevel is the set of values allowed for Config.LogLevel, Server.LogLevel.",
+		`LogLevelDebug LogLevel = "debug"`,
+		`RegionUsEast1 Region = "us-east-1"`,
+		`ColorLightBlue Color = "light blue"`,
+		"func LogLevelValues() []LogLevel {",
+		"func (e *LogLevel) Scan(src any) error {",
+		"func (e LogLevel) MarshalYAML() (any, error) {",
+		`must be one of %s", s, "debug, info, warn, error")`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code is missing %q", want)
+		}
+	}
+
+	// The generated file must type-check on its own.
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "fuda_enums.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parsing generated code: %v\n%s", err, src)
+	}
+	if !ast.IsGenerated(file) {
+		t.Error("generated file is not marked as generated")
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("testdata", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("type-checking generated code: %v\n%s", err, src)
+	}
+}
+
+func TestGenerate_NoEnums(t *testing.T) {
+	t.Parallel()
+
+	err := enumgen.Generate(&bytes.Buffer{}, &enumgen.Package{Name: "p"})
+	if !errors.Is(err, enumgen.ErrNoEnums) {
+		t.Errorf("Generate error = %v, want ErrNoEnums", err)
+	}
+}
@@ -0,0 +1,118 @@
+package enumgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ErrNoEnums is returned by Generate when there is nothing to generate.
+var ErrNoEnums = errors.New("no string fields with oneof rules found")
+
+// enumTemplate renders one enum type. Scan implements fuda.Scanner, so env
+// vars and default tags are checked against the allowed values, and
+// MarshalYAML keeps the type readable in Dump and Marshal output.
+var enumTemplate = template.Must(template.New("enum").Parse(`
+// {{.Name}} is the set of values allowed for {{.FieldList}}.
+type {{.Name}} string
+
+// {{.Name}} values.
+const (
+{{- range .Consts}}
+	{{.Name}} {{$.Name}} = {{.Value}}
+{{- end}}
+)
+
+// {{.Name}}Values returns all allowed {{.Name}} values.
+func {{.Name}}Values() []{{.Name}} {
+	return []{{.Name}}{ {{- range $i, $c := .Consts}}{{if $i}}, {{end}}{{$c.Name}}{{end -}} }
+}
+
+// IsValid reports whether e is an allowed {{.Name}} value.
+func (e {{.Name}}) IsValid() bool {
+	switch e {
+	case {{range $i, $c := .Consts}}{{if $i}}, {{end}}{{$c.Name}}{{end}}:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns e as a string.
+func (e {{.Name}}) String() string {
+	return string(e)
+}
+
+// Scan implements fuda.Scanner, rejecting values that are not allowed.
+func (e *{{.Name}}) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("{{.Name}}: expected string, got %T", src)
+	}
+	if !{{.Name}}(s).IsValid() {
+		return fmt.Errorf("invalid {{.Name}} %q: must be one of %s", s, {{.Allowed}})
+	}
+	*e = {{.Name}}(s)
+
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (e {{.Name}}) MarshalYAML() (any, error) {
+	return string(e), nil
+}
+`))
+
+// constant is one rendered enum constant.
+type constant struct {
+	Name  string
+	Value string // quoted Go string literal
+}
+
+// enumData is the template input for one enum.
+type enumData struct {
+	Name      string
+	FieldList string
+	Consts    []constant
+	Allowed   string // quoted Go string literal
+}
+
+// Generate writes a gofmt-formatted Go file declaring the enums of pkg to w.
+func Generate(w io.Writer, pkg *Package) error {
+	if len(pkg.Enums) == 0 {
+		return ErrNoEnums
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by fuda-gen enum; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport \"fmt\"\n", pkg.Name)
+
+	for _, e := range pkg.Enums {
+		data := enumData{
+			Name:      e.Name,
+			FieldList: strings.Join(e.Fields, ", "),
+			Allowed:   strconv.Quote(strings.Join(e.Values, ", ")),
+		}
+		for _, v := range e.Values {
+			data.Consts = append(data.Consts, constant{Name: ConstName(e.Name, v), Value: strconv.Quote(v)})
+		}
+
+		if err := enumTemplate.Execute(&buf, data); err != nil {
+			return err
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	_, err = w.Write(src)
+
+	return err
+}
@@ -0,0 +1,346 @@
+// Package enumgen generates typed string enums from the oneof validation
+// rules of fuda configuration structs.
+package enumgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Enum describes one generated enum type.
+type Enum struct {
+	// Name is the Go type name.
+	Name string
+	// Fields lists the struct fields using the enum, such as "Config.LogLevel".
+	Fields []string
+	// Values holds the allowed values, in rule order.
+	Values []string
+}
+
+// Package holds the enums found in a package.
+type Package struct {
+	// Name is the Go package name.
+	Name string
+	// Enums holds the enums in the order their fields were found.
+	Enums []Enum
+}
+
+// oneofValueRe splits a oneof parameter like go-playground/validator does:
+// values are separated by spaces, and single quotes group a value with spaces.
+var oneofValueRe = regexp.MustCompile(`'[^']*'|\S+`)
+
+// collector walks the struct types of one package.
+type collector struct {
+	structs  map[string]*ast.StructType
+	declared map[string]bool
+	visited  map[string]bool
+	enums    []Enum
+}
+
+// Parse reads the Go files in dir and collects an enum for every string field
+// with a oneof rule. With structName set, only that struct and the structs it
+// contains are considered; otherwise every struct in the package is.
+//
+// Generated files and tests are skipped, so a previous output of Generate is
+// ignored and a field already using a generated type keeps that type's name.
+func Parse(dir, structName string) (*Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	c := &collector{
+		structs:  make(map[string]*ast.StructType),
+		declared: make(map[string]bool),
+		visited:  make(map[string]bool),
+	}
+	var pkgName string
+	var order []string
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		if pkgName == "" {
+			pkgName = file.Name.Name
+		} else if file.Name.Name != pkgName {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				c.declared[ts.Name.Name] = true
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					c.structs[ts.Name.Name] = st
+					order = append(order, ts.Name.Name)
+				}
+			}
+		}
+	}
+
+	if pkgName == "" {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+
+	if structName != "" {
+		if _, ok := c.structs[structName]; !ok {
+			return nil, fmt.Errorf("struct %q not found in %s", structName, dir)
+		}
+		order = []string{structName}
+	}
+
+	for _, name := range order {
+		if err := c.walkNamed(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Package{Name: pkgName, Enums: c.enums}, nil
+}
+
+// walkNamed collects the enums of the named struct once.
+func (c *collector) walkNamed(name string) error {
+	if c.visited[name] {
+		return nil
+	}
+	c.visited[name] = true
+
+	return c.walkStruct(c.structs[name], name)
+}
+
+// walkStruct collects the enums of st, whose fields are reported as
+// path.Field.
+func (c *collector) walkStruct(st *ast.StructType, path string) error {
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(raw)
+			}
+		}
+
+		names := make([]string, 0, len(field.Names))
+		for _, n := range field.Names {
+			if n.IsExported() {
+				names = append(names, n.Name)
+			}
+		}
+		if len(field.Names) == 0 {
+			names = append(names, typeName(field.Type)) // embedded
+		}
+
+		for _, name := range names {
+			if err := c.addField(field.Type, tag.Get("validate"), path+"."+name, name); err != nil {
+				return err
+			}
+		}
+
+		if len(names) == 0 {
+			continue // unexported fields are not loaded
+		}
+		if err := c.walkType(field.Type, path+"."+names[0]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkType descends into the structs contained in t.
+func (c *collector) walkType(t ast.Expr, path string) error {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if _, ok := c.structs[t.Name]; ok {
+			return c.walkNamed(t.Name)
+		}
+	case *ast.StarExpr:
+		return c.walkType(t.X, path)
+	case *ast.ArrayType:
+		return c.walkType(t.Elt, path)
+	case *ast.MapType:
+		return c.walkType(t.Value, path)
+	case *ast.StructType:
+		return c.walkStruct(t, path)
+	}
+
+	return nil
+}
+
+// addField records the enum of a field whose validate tag has a oneof rule.
+// A rule after "dive" applies to the elements of a slice, array, or map.
+func (c *collector) addField(t ast.Expr, rules, path, fieldName string) error {
+	param, dived, ok := oneofParam(rules)
+	if !ok {
+		return nil
+	}
+
+	if dived {
+		switch tt := t.(type) {
+		case *ast.ArrayType:
+			t = tt.Elt
+		case *ast.MapType:
+			t = tt.Value
+		default:
+			return nil
+		}
+	}
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+
+	ident, ok := t.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	// A string field is named after the field; any other name not declared
+	// by hand is a type generated earlier, whose name is kept.
+	name := ident.Name
+	switch {
+	case name == "string":
+		name = fieldName
+	case c.declared[name] || isPredeclared(name):
+		return nil
+	}
+
+	values := make([]string, 0)
+	for _, v := range oneofValueRe.FindAllString(param, -1) {
+		values = append(values, strings.Trim(v, "'"))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	return c.add(Enum{Name: name, Fields: []string{path}, Values: values})
+}
+
+// add records e, merging it with an enum of the same name and values.
+func (c *collector) add(e Enum) error {
+	if c.declared[e.Name] {
+		return fmt.Errorf("%s: enum type %s conflicts with a type declared in the package", e.Fields[0], e.Name)
+	}
+
+	for i := range c.enums {
+		existing := &c.enums[i]
+		if existing.Name != e.Name {
+			continue
+		}
+		if !slices.Equal(existing.Values, e.Values) {
+			return fmt.Errorf("%s and %s both map to enum %s with different values; give one field its own type name",
+				existing.Fields[0], e.Fields[0], e.Name)
+		}
+		existing.Fields = append(existing.Fields, e.Fields...)
+
+		return nil
+	}
+
+	seen := make(map[string]string, len(e.Values))
+	for _, v := range e.Values {
+		constName := ConstName(e.Name, v)
+		if prev, ok := seen[constName]; ok {
+			return fmt.Errorf("%s: values %q and %q both map to constant %s", e.Fields[0], prev, v, constName)
+		}
+		seen[constName] = v
+	}
+
+	c.enums = append(c.enums, e)
+
+	return nil
+}
+
+// oneofParam returns the parameter of the oneof rule in a validate tag and
+// whether the rule follows "dive".
+func oneofParam(rules string) (param string, dived, ok bool) {
+	for rule := range strings.SplitSeq(rules, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			dived = true
+		case "oneof":
+			return value, dived, true
+		}
+	}
+
+	return "", false, false
+}
+
+// ConstName returns the constant name of value in enum typeName, such as
+// "LogLevelDebug" for "debug" or "RegionUsEast1" for "us-east-1".
+func ConstName(typeName, value string) string {
+	var b strings.Builder
+	b.WriteString(typeName)
+
+	upper := true
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	if b.Len() == len(typeName) {
+		b.WriteString("Empty")
+	}
+
+	return b.String()
+}
+
+// typeName returns the type name of an embedded field.
+func typeName(t ast.Expr) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	default:
+		return ""
+	}
+}
+
+// isPredeclared reports whether name is a predeclared Go type.
+func isPredeclared(name string) bool {
+	switch name {
+	case "bool", "byte", "complex64", "complex128", "error", "float32", "float64",
+		"int", "int8", "int16", "int32", "int64", "rune",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "any":
+		return true
+	}
+
+	return false
+}
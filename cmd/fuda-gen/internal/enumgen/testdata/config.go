@@ -0,0 +1,31 @@
+package testdata
+
+// Config is the root configuration.
+type Config struct {
+	LogLevel string   `yaml:"log_level" default:"info" validate:"oneof=debug info warn error"`
+	Region   Region   `yaml:"region" validate:"required,oneof=us-east-1 eu-west-1"`
+	Formats  []string `yaml:"formats" validate:"dive,oneof=json text"`
+	Color    *string  `yaml:"color" validate:"omitempty,oneof='light blue' red"`
+	Port     int      `yaml:"port" validate:"oneof=80 443"`
+	Name     string   `yaml:"name" validate:"required"`
+	Server   Server   `yaml:"server"`
+	Workers  []Worker `yaml:"workers"`
+	Inline   struct {
+		Mode string `yaml:"mode" validate:"oneof=fast safe"`
+	} `yaml:"inline"`
+}
+
+// Server is nested in Config.
+type Server struct {
+	LogLevel string `yaml:"log_level" validate:"oneof=debug info warn error"`
+}
+
+// Worker is a slice element of Config.
+type Worker struct {
+	Protocol string `yaml:"protocol" validate:"oneof=tcp udp"`
+}
+
+// Unreachable is not reachable from Config.
+type Unreachable struct {
+	Kind string `yaml:"kind" validate:"oneof=a b"`
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/arloliu/fuda/cmd/fuda-gen/internal/enumgen"
+)
+
+// version is set at build time via -ldflags "-X main.version=..."
+var version = "dev"
+
+func usage() {
+	_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-gen <mode> [flags]\n\n")
+	_, _ = fmt.Fprint(os.Stderr, "Modes:\n")
+	_, _ = fmt.Fprint(os.Stderr, "  enum       Generate typed enums from `validate:\"oneof=...\"` rules\n")
+	_, _ = fmt.Fprint(os.Stderr, "  version    Print version and exit\n\n")
+	_, _ = fmt.Fprint(os.Stderr, "Run 'fuda-gen <mode> -h' for the flags of a mode.\n")
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		usage()
+
+		return errors.New("mode is required")
+	}
+
+	switch args[0] {
+	case "enum":
+		return runEnum(args[1:])
+	case "version", "-version", "--version", "-v":
+		fmt.Println("fuda-gen " + version)
+
+		return nil
+	case "help", "-h", "-help", "--help":
+		usage()
+
+		return nil
+	default:
+		usage()
+
+		return fmt.Errorf("unknown mode %q", args[0])
+	}
+}
+
+func runEnum(args []string) error {
+	flags := flag.NewFlagSet("fuda-gen enum", flag.ContinueOnError)
+	targetPath := flags.String("path", ".", "Package directory containing the config structs")
+	targetStruct := flags.String("struct", "", "Root struct to scan (default: every struct in the package)")
+	outputTarget := flags.String("output", "", "Output file, or \"stdout\" (default \"<path>/fuda_enums.go\")")
+	flags.StringVar(targetPath, "p", ".", "Short for -path")
+	flags.StringVar(targetStruct, "s", "", "Short for -struct")
+	flags.StringVar(outputTarget, "o", "", "Short for -output")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	pkg, err := enumgen.Parse(*targetPath, *targetStruct)
+	if err != nil {
+		return err
+	}
+
+	if *outputTarget == "stdout" {
+		return enumgen.Generate(os.Stdout, pkg)
+	}
+
+	output := *outputTarget
+	if output == "" {
+		output = filepath.Join(*targetPath, "fuda_enums.go")
+	}
+
+	return writeFile(output, func(w io.Writer) error {
+		return enumgen.Generate(w, pkg)
+	})
+}
+
+// writeFile writes the output of gen to path, leaving any existing file
+// untouched if gen fails.
+func writeFile(path string, gen func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fuda-gen-*")
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gen(tmp); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { //nolint:gosec // generated source is world-readable
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,10 @@
+module github.com/arloliu/fuda/cmd/fuda-watch
+
+go 1.25
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.13.0 // indirect
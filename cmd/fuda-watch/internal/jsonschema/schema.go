@@ -0,0 +1,497 @@
+// Package jsonschema validates decoded YAML or JSON documents against the
+// subset of JSON Schema that fuda.Schema generates: types, properties,
+// required, additionalProperties, items, enum, numeric, length, and size
+// bounds, pattern, and the common formats. Other keywords are ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a compiled schema.
+type Schema struct {
+	// Types lists the allowed JSON types; empty allows any type.
+	Types      []string
+	Properties map[string]*Schema
+	Required   []string
+	// AdditionalProperties validates keys not in Properties; nil allows
+	// any value.
+	AdditionalProperties *Schema
+	// NoAdditionalProperties rejects keys not in Properties.
+	NoAdditionalProperties bool
+	Items                  *Schema
+	Enum                   []any
+	Format                 string
+	Pattern                *regexp.Regexp
+
+	Minimum, Maximum                   *float64
+	ExclusiveMinimum, ExclusiveMaximum *float64
+	MinLength, MaxLength               *int
+	MinItems, MaxItems                 *int
+	MinProperties, MaxProperties       *int
+}
+
+// Error is a violation of the schema.
+type Error struct {
+	// Path is the dotted path of the value, such as "servers[0].port";
+	// empty for the document itself.
+	Path    string
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return "(root): " + e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+// Parse compiles the JSON schema in data.
+func Parse(data []byte) (*Schema, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	return compile(raw, "")
+}
+
+// compile compiles the schema raw, found at the JSON pointer ptr.
+func compile(raw any, ptr string) (*Schema, error) {
+	switch raw := raw.(type) {
+	case bool:
+		if raw {
+			return &Schema{}, nil
+		}
+
+		return nil, fmt.Errorf("schema %s: false schemas are only supported as additionalProperties", pointer(ptr))
+	case map[string]any:
+		return compileObject(raw, ptr)
+	default:
+		return nil, fmt.Errorf("schema %s: expected an object, got %T", pointer(ptr), raw)
+	}
+}
+
+func compileObject(raw map[string]any, ptr string) (*Schema, error) {
+	s := &Schema{}
+	var err error
+
+	switch t := raw["type"].(type) {
+	case nil:
+	case string:
+		s.Types = []string{t}
+	case []any:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("schema %s/type: expected strings", pointer(ptr))
+			}
+			s.Types = append(s.Types, name)
+		}
+	default:
+		return nil, fmt.Errorf("schema %s/type: expected a string or array", pointer(ptr))
+	}
+
+	if props, ok := raw["properties"].(map[string]any); ok {
+		s.Properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.Properties[name], err = compile(prop, ptr+"/properties/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if required, ok := raw["required"].([]any); ok {
+		for _, v := range required {
+			if name, ok := v.(string); ok {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+
+	switch additional := raw["additionalProperties"].(type) {
+	case nil:
+	case bool:
+		s.NoAdditionalProperties = !additional
+	default:
+		if s.AdditionalProperties, err = compile(additional, ptr+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if items, ok := raw["items"]; ok {
+		if s.Items, err = compile(items, ptr+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	if enum, ok := raw["enum"].([]any); ok {
+		s.Enum = enum
+	}
+	s.Format, _ = raw["format"].(string)
+	if pattern, ok := raw["pattern"].(string); ok {
+		if s.Pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("schema %s/pattern: %w", pointer(ptr), err)
+		}
+	}
+
+	s.Minimum, s.Maximum = number(raw, "minimum"), number(raw, "maximum")
+	s.ExclusiveMinimum, s.ExclusiveMaximum = number(raw, "exclusiveMinimum"), number(raw, "exclusiveMaximum")
+	s.MinLength, s.MaxLength = count(raw, "minLength"), count(raw, "maxLength")
+	s.MinItems, s.MaxItems = count(raw, "minItems"), count(raw, "maxItems")
+	s.MinProperties, s.MaxProperties = count(raw, "minProperties"), count(raw, "maxProperties")
+
+	return s, nil
+}
+
+func pointer(ptr string) string {
+	if ptr == "" {
+		return "#"
+	}
+
+	return "#" + ptr
+}
+
+func number(raw map[string]any, key string) *float64 {
+	if n, ok := raw[key].(float64); ok {
+		return &n
+	}
+
+	return nil
+}
+
+func count(raw map[string]any, key string) *int {
+	if n, ok := raw[key].(float64); ok {
+		c := int(n)
+
+		return &c
+	}
+
+	return nil
+}
+
+// Validate returns the violations of the schema by doc, a document decoded
+// from YAML or JSON (see Normalize), ordered by path.
+func (s *Schema) Validate(doc any) []Error {
+	var errs []Error
+	s.validate("", Normalize(doc), &errs)
+
+	return errs
+}
+
+func (s *Schema) validate(path string, v any, errs *[]Error) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Types) > 0 && !slices.ContainsFunc(s.Types, func(t string) bool { return hasType(v, t) }) {
+		fail("expected %s, got %s", strings.Join(s.Types, " or "), typeName(v))
+
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		fail("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case string:
+		s.validateString(v, fail)
+	case int64, float64:
+		s.validateNumber(toFloat(v), fail)
+	case []any:
+		s.validateArray(path, v, errs, fail)
+	case map[string]any:
+		s.validateObject(path, v, errs, fail)
+	}
+}
+
+func (s *Schema) validateString(v string, fail func(string, ...any)) {
+	n := len([]rune(v))
+	if s.MinLength != nil && n < *s.MinLength {
+		fail("must be at least %d characters long", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		fail("must be at most %d characters long", *s.MaxLength)
+	}
+	if s.Pattern != nil && !s.Pattern.MatchString(v) {
+		fail("must match pattern %q", s.Pattern.String())
+	}
+	if s.Format != "" && !validFormat(s.Format, v) {
+		fail("must be a valid %s", s.Format)
+	}
+}
+
+func (s *Schema) validateNumber(v float64, fail func(string, ...any)) {
+	if s.Minimum != nil && v < *s.Minimum {
+		fail("must be >= %s", formatNumber(*s.Minimum))
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		fail("must be <= %s", formatNumber(*s.Maximum))
+	}
+	if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+		fail("must be > %s", formatNumber(*s.ExclusiveMinimum))
+	}
+	if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+		fail("must be < %s", formatNumber(*s.ExclusiveMaximum))
+	}
+}
+
+func (s *Schema) validateArray(path string, v []any, errs *[]Error, fail func(string, ...any)) {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		fail("must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		fail("must have at most %d items", *s.MaxItems)
+	}
+	if s.Items == nil {
+		return
+	}
+	for i, item := range v {
+		s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+	}
+}
+
+func (s *Schema) validateObject(path string, v map[string]any, errs *[]Error, fail func(string, ...any)) {
+	if s.MinProperties != nil && len(v) < *s.MinProperties {
+		fail("must have at least %d keys", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(v) > *s.MaxProperties {
+		fail("must have at most %d keys", *s.MaxProperties)
+	}
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			*errs = append(*errs, Error{Path: JoinPath(path, name), Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := JoinPath(path, key)
+		if prop, ok := s.Properties[key]; ok {
+			prop.validate(keyPath, v[key], errs)
+
+			continue
+		}
+		switch {
+		case s.NoAdditionalProperties:
+			*errs = append(*errs, Error{Path: keyPath, Message: "unknown key"})
+		case s.AdditionalProperties != nil:
+			s.AdditionalProperties.validate(keyPath, v[key], errs)
+		}
+	}
+}
+
+// JoinPath appends key to the dotted path.
+func JoinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// Normalize converts a document decoded by yaml.v3 or encoding/json to
+// plain JSON values: map[string]any, []any, string, bool, int64, float64,
+// and nil. Timestamps become RFC 3339 strings and other map keys are
+// formatted as strings.
+func Normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, val := range v {
+			out[key] = Normalize(val)
+		}
+
+		return out
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for key, val := range v {
+			out[fmt.Sprint(key)] = Normalize(val)
+		}
+
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = Normalize(val)
+		}
+
+		return out
+	case int:
+		return int64(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return float64(v)
+		}
+
+		return int64(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+
+		return f
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+
+		return ok
+	case "array":
+		_, ok := v.([]any)
+
+		return ok
+	case "string":
+		_, ok := v.(string)
+
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		switch v.(type) {
+		case int64, float64:
+			return true
+		}
+
+		return false
+	case "integer":
+		switch v := v.(type) {
+		case int64:
+			return true
+		case float64:
+			return v == math.Trunc(v) && !math.IsInf(v, 0)
+		}
+
+		return false
+	default:
+		return false
+	}
+}
+
+func typeName(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		if hasType(v, "integer") {
+			return "integer"
+		}
+
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func toFloat(v any) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return math.NaN()
+	}
+}
+
+// equal compares a schema value, as decoded by encoding/json, to a
+// normalized document value.
+func equal(schemaValue, v any) bool {
+	if f, ok := schemaValue.(float64); ok {
+		return hasType(v, "number") && toFloat(v) == f
+	}
+
+	return reflect.DeepEqual(Normalize(schemaValue), v)
+}
+
+func formatEnum(enum []any) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		if s, ok := e.(string); ok {
+			values[i] = strconv.Quote(s)
+		} else {
+			b, _ := json.Marshal(e)
+			values[i] = string(b)
+		}
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+var (
+	hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+	uuidRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// validFormat reports whether v is valid for format; unknown formats
+// accept any value.
+func validFormat(format, v string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(v)
+
+		return err == nil && addr.Address == v
+	case "hostname":
+		return len(v) <= 253 && hostnameRegex.MatchString(v)
+	case "ipv4":
+		ip := net.ParseIP(v)
+
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	case "ipv6":
+		return net.ParseIP(v) != nil && strings.Contains(v, ":")
+	case "uuid":
+		return uuidRegex.MatchString(v)
+	case "uri":
+		u, err := url.Parse(v)
+
+		return err == nil && u.Scheme != ""
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, v)
+
+		return err == nil
+	default:
+		return true
+	}
+}
@@ -0,0 +1,159 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/jsonschema"
+)
+
+// configSchema is shaped like the output of fuda.Schema.
+const configSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Config",
+  "type": "object",
+  "properties": {
+    "host": {"type": "string", "format": "hostname"},
+    "port": {"type": "integer", "minimum": 1, "maximum": 65535},
+    "timeout": {"type": ["string", "integer"]},
+    "log_level": {"type": "string", "enum": ["debug", "info", "warn", "error"]},
+    "ratio": {"type": "number", "exclusiveMaximum": 1},
+    "admin": {"type": "string", "format": "email"},
+    "servers": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "properties": {"url": {"type": "string", "format": "uri"}},
+        "required": ["url"],
+        "additionalProperties": false
+      }
+    },
+    "labels": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 5}}
+  },
+  "required": ["host"]
+}`
+
+func mustParse(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	schema, err := jsonschema.Parse([]byte(configSchema))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	return schema
+}
+
+func decode(t *testing.T, doc string) any {
+	t.Helper()
+
+	var v any
+	if err := yaml.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+
+	return v
+}
+
+func TestValidate_Valid(t *testing.T) {
+	t.Parallel()
+
+	doc := decode(t, `
+host: db.internal
+port: 5432
+timeout: 5s
+log_level: info
+ratio: 0.5
+admin: ops@example.com
+servers:
+  - url: https://a.example.com
+labels: {team: core}
+`)
+	if errs := mustParse(t).Validate(doc); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestValidate_Violations(t *testing.T) {
+	t.Parallel()
+
+	doc := decode(t, `
+port: 70000
+timeout: true
+log_level: verbose
+ratio: 1
+admin: not-an-email
+servers:
+  - url: /relative
+    weight: 2
+  - {}
+labels: {team: platform}
+`)
+
+	var got []string
+	for _, err := range mustParse(t).Validate(doc) {
+		got = append(got, err.Error())
+	}
+
+	want := []string{
+		"host: is required",
+		"admin: must be a valid email",
+		"labels.team: must be at most 5 characters long",
+		`log_level: must be one of "debug", "info", "warn", "error"`,
+		"port: must be <= 65535",
+		"ratio: must be < 1",
+		"servers[0].url: must be a valid uri",
+		"servers[0].weight: unknown key",
+		"servers[1].url: is required",
+		"timeout: expected string or integer, got boolean",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidate_Types(t *testing.T) {
+	t.Parallel()
+
+	schema, err := jsonschema.Parse([]byte(`{"type": "object", "properties": {
+		"n": {"type": "integer"},
+		"list": {"type": "array", "maxItems": 1},
+		"root": {"type": "string"}
+	}}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	errs := schema.Validate(decode(t, "n: 1.0\nlist: [1, 2]\nroot: [x]"))
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	want := "list: must have at most 1 items\nroot: expected string, got array"
+	if strings.Join(got, "\n") != want {
+		t.Errorf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), want)
+	}
+
+	if errs := schema.Validate("scalar"); len(errs) != 1 || errs[0].Error() != "(root): expected object, got string" {
+		t.Errorf("root errors = %v", errs)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"not json":    `{`,
+		"bad type":    `{"type": 1}`,
+		"bad pattern": `{"pattern": "("}`,
+		"bad items":   `{"items": "x"}`,
+	}
+	for name, schema := range tests {
+		if _, err := jsonschema.Parse([]byte(schema)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
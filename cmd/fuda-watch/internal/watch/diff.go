@@ -0,0 +1,124 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/jsonschema"
+)
+
+// ChangeKind classifies a Change.
+type ChangeKind int
+
+const (
+	// Modified means the value at the path changed.
+	Modified ChangeKind = iota
+	// Added means the path is new.
+	Added
+	// Removed means the path is gone.
+	Removed
+)
+
+// Change is a difference between two versions of a document.
+type Change struct {
+	Kind ChangeKind
+	// Path is the dotted path of the value, such as "servers[0].port".
+	Path          string
+	Before, After any
+}
+
+// String formats the change as a diff line, such as "~ port: 8080 -> 9090".
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.After))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Before))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatValue(c.Before), formatValue(c.After))
+	}
+}
+
+// Diff returns the changes from before to after, two normalized documents
+// (see jsonschema.Normalize), ordered by path. Maps and lists are compared
+// entry by entry; any other value is compared as a whole.
+func Diff(before, after any) []Change {
+	var changes []Change
+	diff("", before, after, &changes)
+
+	return changes
+}
+
+func diff(path string, before, after any, changes *[]Change) {
+	switch b := before.(type) {
+	case map[string]any:
+		if a, ok := after.(map[string]any); ok {
+			diffMaps(path, b, a, changes)
+
+			return
+		}
+	case []any:
+		if a, ok := after.([]any); ok {
+			diffLists(path, b, a, changes)
+
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Kind: Modified, Path: path, Before: before, After: after})
+	}
+}
+
+func diffMaps(path string, before, after map[string]any, changes *[]Change) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+		keyPath := jsonschema.JoinPath(path, key)
+		switch {
+		case !inBefore:
+			*changes = append(*changes, Change{Kind: Added, Path: keyPath, After: a})
+		case !inAfter:
+			*changes = append(*changes, Change{Kind: Removed, Path: keyPath, Before: b})
+		default:
+			diff(keyPath, b, a, changes)
+		}
+	}
+}
+
+func diffLists(path string, before, after []any, changes *[]Change) {
+	for i := range max(len(before), len(after)) {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(before):
+			*changes = append(*changes, Change{Kind: Added, Path: itemPath, After: after[i]})
+		case i >= len(after):
+			*changes = append(*changes, Change{Kind: Removed, Path: itemPath, Before: before[i]})
+		default:
+			diff(itemPath, before[i], after[i], changes)
+		}
+	}
+}
+
+// formatValue formats v as compact JSON.
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,208 @@
+// Package watch re-validates a config file against a JSON schema whenever it
+// changes and reports what changed between valid versions.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/jsonschema"
+)
+
+// ErrInvalid is returned by Run when the config file is not valid.
+var ErrInvalid = errors.New("invalid config")
+
+// defaultDebounce lets an editor finish saving before the file is read.
+const defaultDebounce = 100 * time.Millisecond
+
+// Options configures Run.
+type Options struct {
+	// Path is the YAML or JSON config file to watch.
+	Path   string
+	Schema *jsonschema.Schema
+	// Out receives the reports.
+	Out io.Writer
+	// KeepGoing reports invalid edits and keeps watching, instead of
+	// returning ErrInvalid.
+	KeepGoing bool
+	// Once checks the file and returns without watching it, with
+	// ErrInvalid if the file is invalid.
+	Once bool
+	// Debounce is how long to wait after the last file event before
+	// reading the file (default 100ms).
+	Debounce time.Duration
+}
+
+// Check parses the config document in data and validates it against
+// schema. It returns the normalized document and the violations, or an
+// error if data is not valid YAML. An empty document is an empty object.
+func Check(data []byte, schema *jsonschema.Schema) (any, []jsonschema.Error, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	doc = jsonschema.Normalize(doc)
+
+	return doc, schema.Validate(doc), nil
+}
+
+// Run validates the config file, then watches it until ctx is done,
+// reporting each edit to opts.Out: the changes from the last valid version
+// if the edit is valid, or the violations if not. Unless opts.KeepGoing is
+// set, an invalid file ends the watch with ErrInvalid.
+func Run(ctx context.Context, opts Options) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = defaultDebounce
+	}
+	path := filepath.Clean(opts.Path)
+	r := reporter{opts: opts, name: filepath.Base(path)}
+
+	if opts.Once {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return r.check(data)
+	}
+
+	// Watch the directory before the first read so no edit is missed; it
+	// also sees files that editors replace instead of writing in place.
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsw.Close()
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := r.check(data); err != nil {
+		return err
+	}
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-fsw.Errors:
+			return err
+		case event := <-fsw.Events:
+			if !isConfigEvent(event, path) {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(opts.Debounce)
+			fire = timer.C
+		case <-fire:
+			fire = nil
+			data, err := os.ReadFile(path)
+			if err != nil {
+				// Removed or being replaced; the next event re-reads it
+				continue
+			}
+			if err := r.check(data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// isConfigEvent reports whether event may have changed the file at path,
+// including a swap of Kubernetes' hidden "..data" symlink in its directory.
+func isConfigEvent(event fsnotify.Event, path string) bool {
+	name := filepath.Clean(event.Name)
+	if name == path {
+		return event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0
+	}
+
+	return filepath.Dir(name) == filepath.Dir(path) && strings.HasPrefix(filepath.Base(name), "..")
+}
+
+// reporter checks versions of the config file and reports the results.
+type reporter struct {
+	opts     Options
+	name     string
+	lastData []byte
+	lastDoc  any // last valid document, nil before the first
+	checked  bool
+}
+
+// check checks data and reports the result, returning ErrInvalid if data
+// is invalid and the watch should stop.
+func (r *reporter) check(data []byte) error {
+	if r.checked && bytes.Equal(data, r.lastData) {
+		return nil
+	}
+	r.checked, r.lastData = true, data
+
+	stamp := time.Now().Format(time.TimeOnly)
+	doc, violations, err := Check(data, r.opts.Schema)
+	if err != nil || len(violations) > 0 {
+		r.printf("[%s] %s: invalid\n", stamp, r.name)
+		if err != nil {
+			r.printf("  - %v\n", err)
+		}
+		for _, v := range violations {
+			r.printf("  - %v\n", v)
+		}
+		if r.opts.KeepGoing && !r.opts.Once {
+			return nil
+		}
+
+		return fmt.Errorf("%s: %w", r.name, ErrInvalid)
+	}
+
+	if r.lastDoc == nil {
+		if r.opts.Once {
+			r.printf("[%s] %s: valid\n", stamp, r.name)
+		} else {
+			r.printf("[%s] %s: valid, watching for changes\n", stamp, r.name)
+		}
+		r.lastDoc = doc
+
+		return nil
+	}
+
+	changes := Diff(r.lastDoc, doc)
+	r.lastDoc = doc
+	switch len(changes) {
+	case 0:
+		r.printf("[%s] %s: valid, no changes\n", stamp, r.name)
+	case 1:
+		r.printf("[%s] %s: valid, 1 change\n", stamp, r.name)
+	default:
+		r.printf("[%s] %s: valid, %d changes\n", stamp, r.name, len(changes))
+	}
+	for _, c := range changes {
+		r.printf("  %s\n", c)
+	}
+
+	return nil
+}
+
+func (r *reporter) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.opts.Out, format, args...)
+}
@@ -0,0 +1,223 @@
+package watch_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/jsonschema"
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/watch"
+)
+
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "host": {"type": "string"},
+    "port": {"type": "integer", "maximum": 65535},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["host"]
+}`
+
+func mustSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	schema, err := jsonschema.Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	return schema
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use. Each write wakes
+// up the waiters of waitFor.
+type syncBuffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	written chan struct{} // closed and replaced on each write
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.written != nil {
+		close(b.written)
+		b.written = nil
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// next returns the output so far, and a channel closed on the next write.
+func (b *syncBuffer) next() (string, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.written == nil {
+		b.written = make(chan struct{})
+	}
+
+	return b.buf.String(), b.written
+}
+
+// waitFor waits until out contains s.
+func waitFor(t *testing.T, out *syncBuffer, s string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		got, written := out.next()
+		if strings.Contains(got, s) {
+			return
+		}
+
+		select {
+		case <-written:
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q; output:\n%s", s, out.String())
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]any{"host": "a", "port": int64(80), "tags": []any{"x", "y"}, "tls": map[string]any{"on": false}}
+	after := map[string]any{"host": "a", "port": int64(8080), "tags": []any{"x"}, "tls": map[string]any{"on": true}, "debug": true}
+
+	var got []string
+	for _, c := range watch.Diff(before, after) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"+ debug: true",
+		"~ port: 80 -> 8080",
+		`- tags[1]: "y"`,
+		"~ tls.on: false -> true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	schema := mustSchema(t)
+
+	doc, violations, err := watch.Check(nil, schema)
+	if err != nil || len(violations) != 1 || violations[0].Error() != "host: is required" {
+		t.Errorf("empty document: doc=%v violations=%v err=%v", doc, violations, err)
+	}
+
+	if _, _, err := watch.Check([]byte("host: [unclosed"), schema); err == nil {
+		t.Error("expected a YAML error")
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "host: db\nport: 5432\n")
+
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- watch.Run(context.Background(), watch.Options{Path: path, Schema: mustSchema(t), Out: out, Debounce: 20 * time.Millisecond})
+	}()
+	waitFor(t, out, "config.yaml: valid, watching for changes")
+
+	writeFile(t, path, "host: db\nport: 6432\ntags: [primary]\n")
+	waitFor(t, out, "valid, 2 changes\n  ~ port: 5432 -> 6432\n  + tags: [\"primary\"]\n")
+
+	// Replace the file as editors do on save
+	tmp := path + ".tmp"
+	writeFile(t, tmp, "host: db\nport: 99999\n")
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, watch.ErrInvalid) {
+			t.Fatalf("Run = %v, want ErrInvalid", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop on an invalid edit")
+	}
+	if !strings.Contains(out.String(), "config.yaml: invalid\n  - port: must be <= 65535\n") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
+
+func TestRun_KeepGoing(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "host: db\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- watch.Run(ctx, watch.Options{Path: path, Schema: mustSchema(t), Out: out, KeepGoing: true, Debounce: 20 * time.Millisecond})
+	}()
+	waitFor(t, out, "valid, watching for changes")
+
+	writeFile(t, path, "port: 1\n")
+	waitFor(t, out, "  - host: is required\n")
+
+	// Changes are reported against the last valid version
+	writeFile(t, path, "host: db\nport: 2\n")
+	waitFor(t, out, "valid, 1 change\n  + port: 2\n")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run = %v", err)
+	}
+}
+
+func TestRun_Once(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "port: 1\n")
+
+	out := &syncBuffer{}
+	err := watch.Run(context.Background(), watch.Options{Path: path, Schema: mustSchema(t), Out: out, Once: true, KeepGoing: true})
+	if !errors.Is(err, watch.ErrInvalid) {
+		t.Fatalf("Run = %v, want ErrInvalid", err)
+	}
+
+	writeFile(t, path, "host: db\n")
+	if err := watch.Run(context.Background(), watch.Options{Path: path, Schema: mustSchema(t), Out: out, Once: true}); err != nil {
+		t.Fatalf("Run = %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "config.yaml: valid\n") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
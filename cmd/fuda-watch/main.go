@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/jsonschema"
+	"github.com/arloliu/fuda/cmd/fuda-watch/internal/watch"
+)
+
+// version is set at build time via -ldflags "-X main.version=..."
+var version = "dev"
+
+func usage() {
+	_, _ = fmt.Fprint(os.Stderr, "Usage: fuda-watch -schema <schema.json> [flags] <config-file>\n\n")
+	_, _ = fmt.Fprint(os.Stderr, "Validates a YAML or JSON config file against a JSON schema, then prints the\n")
+	_, _ = fmt.Fprint(os.Stderr, "changes of each valid edit. Exits with status 1 on the first invalid edit.\n\n")
+	_, _ = fmt.Fprint(os.Stderr, "Flags:\n")
+	_, _ = fmt.Fprint(os.Stderr, "  -s, --schema string    JSON schema of the config, e.g. from fuda.Schema (required)\n")
+	_, _ = fmt.Fprint(os.Stderr, "  -k, --keep-going       Report invalid edits and keep watching\n")
+	_, _ = fmt.Fprint(os.Stderr, "      --once             Validate the file once and exit\n")
+	_, _ = fmt.Fprint(os.Stderr, "  -v, --version          Print version and exit\n")
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if errors.Is(err, watch.ErrInvalid) {
+		os.Exit(1) // the violations are already reported
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("fuda-watch", flag.ContinueOnError)
+	flags.Usage = usage
+	schemaPath := flags.String("schema", "", "JSON schema of the config (required)")
+	keepGoing := flags.Bool("keep-going", false, "Report invalid edits and keep watching")
+	once := flags.Bool("once", false, "Validate the file once and exit")
+	showVersion := flags.Bool("version", false, "Print version and exit")
+	flags.StringVar(schemaPath, "s", "", "Short for -schema")
+	flags.BoolVar(keepGoing, "k", false, "Short for -keep-going")
+	flags.BoolVar(showVersion, "v", false, "Short for -version")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if *showVersion {
+		fmt.Println("fuda-watch " + version)
+
+		return nil
+	}
+	if *schemaPath == "" || flags.NArg() != 1 {
+		usage()
+
+		return errors.New("a schema and one config file are required")
+	}
+
+	data, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		return err
+	}
+	schema, err := jsonschema.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return watch.Run(ctx, watch.Options{
+		Path:      flags.Arg(0),
+		Schema:    schema,
+		Out:       os.Stdout,
+		KeepGoing: *keepGoing,
+		Once:      *once,
+	})
+}
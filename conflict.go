@@ -0,0 +1,14 @@
+package fuda
+
+import "github.com/arloliu/fuda/internal/loader"
+
+// Conflict is a key set to different values by more than one source
+// document, as reported by Builder.WithConflictReport. Its String method
+// formats it as a single line, e.g.
+//
+//	database.host: "db.prod" from prod.yaml shadows "localhost" from base.yaml
+type Conflict = loader.Conflict
+
+// ConflictValue is the value one source document, such as a file or
+// "overrides", gave a conflicting key.
+type ConflictValue = loader.ConflictValue
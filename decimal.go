@@ -0,0 +1,32 @@
+package fuda
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+var decimalType = reflect.TypeFor[Decimal]()
+
+// Decimal is implemented by arbitrary-precision decimal types, such as
+// github.com/shopspring/decimal.Decimal. Use one for money-like fields:
+// float64 cannot represent most decimal fractions exactly, so 0.1 + 0.2
+// already drifts from 0.3.
+//
+// fuda needs no dependency on the decimal package. Values are parsed from the
+// exact text written in the config file, env var, or default tag (with
+// UnmarshalText, or Scan if the type implements Scanner), so no precision is
+// lost on the way in. String is used by the precision validation rule.
+//
+// Example:
+//
+//	import "github.com/shopspring/decimal"
+//
+//	type Billing struct {
+//	    Price   decimal.Decimal `yaml:"price" validate:"precision=2"`
+//	    TaxRate decimal.Decimal `yaml:"tax_rate" default:"0.0825" validate:"precision=4"`
+//	}
+type Decimal interface {
+	encoding.TextUnmarshaler
+	fmt.Stringer
+}
@@ -0,0 +1,76 @@
+package fuda
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// DecodeHook converts the string form of a value to type to, for types that
+// fuda does not convert or converts differently, such as third-party types
+// that cannot implement Scanner. It returns nil and no error if it does not
+// apply to the type, leaving the value to the next hook or the built-in
+// conversion. The result must be assignable to to, or be a pointer to a
+// value that is.
+//
+// Hooks see scalars from the config file and the values of env, flag, ref,
+// dsn, and default tags, including the elements of slices and maps
+// converted from them. Use DecodeHookFor to build one from a parse function.
+type DecodeHook = types.DecodeHook
+
+// DecodeHookFor returns a DecodeHook that converts values to T with parse,
+// such as uuid.Parse or netip.ParsePrefix. It applies to fields of type T,
+// *T, or, if T is a pointer like *url.URL, the type T points to.
+func DecodeHookFor[T any](parse func(string) (T, error)) DecodeHook {
+	target := reflect.TypeFor[T]()
+
+	return func(value string, to reflect.Type) (any, error) {
+		if to != target && to != reflect.PointerTo(target) &&
+			(target.Kind() != reflect.Pointer || to != target.Elem()) {
+			return nil, nil
+		}
+
+		v, err := parse(value)
+		if err != nil {
+			return nil, err
+		}
+		if to == reflect.PointerTo(target) {
+			return &v, nil
+		}
+
+		return v, nil
+	}
+}
+
+// WithDecodeHook registers hook to convert string values to field types on
+// every load, after the hooks registered before it and ahead of the built-in
+// conversions, Scanner, and encoding.TextUnmarshaler:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithDecodeHook(fuda.DecodeHookFor(uuid.Parse)).
+//	    WithDecodeHook(fuda.DecodeHookFor(url.Parse)).
+//	    Build()
+//
+// Build fails if hook is nil.
+func (b *Builder) WithDecodeHook(hook DecodeHook) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if hook == nil {
+		b.err = errors.New("nil decode hook")
+
+		return b
+	}
+
+	b.config.decodeHooks = append(b.config.decodeHooks, hook)
+
+	return b
+}
+
+// WithDecodeHook returns an option that registers a decode hook. See
+// Builder.WithDecodeHook.
+func WithDecodeHook(hook DecodeHook) LoaderOption {
+	return func(b *Builder) { b.WithDecodeHook(hook) }
+}
@@ -0,0 +1,85 @@
+package fuda
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// Decoder decodes the raw content of a field with a decoder tag into
+// target, the settable field value. It is registered with RegisterDecoder.
+//
+// Decoders receive the content of every source: the YAML value, the env
+// var, the resolved ref, or the default. A YAML scalar is passed as its
+// string value; a YAML mapping or sequence is passed as JSON. Decoders are
+// called during Load and must be safe for concurrent use if loaders are
+// used from several goroutines.
+type Decoder = types.FieldDecoder
+
+// decoders holds decoders registered via RegisterDecoder, keyed by name.
+var decoders = struct {
+	mu       sync.RWMutex
+	decoders map[string]Decoder
+}{decoders: make(map[string]Decoder)}
+
+// RegisterDecoder registers fn as the decoder named name in every Loader
+// built afterwards. Fields tagged `decoder:"name"` are set by fn from their
+// raw content instead of the built-in conversions, so formats such as PEM
+// blocks, JWKS sets, or protobuf text can be decoded into foreign types that
+// do not implement Scanner. Registering a name again replaces its decoder;
+// loading a field whose decoder is not registered fails.
+//
+// RegisterDecoder is typically called from init or main before any loader
+// is built. It panics if name is empty or fn is nil.
+//
+// Example:
+//
+//	fuda.RegisterDecoder("pem", func(data []byte, target reflect.Value) error {
+//	    block, _ := pem.Decode(data)
+//	    if block == nil {
+//	        return errors.New("no PEM block found")
+//	    }
+//	    target.Set(reflect.ValueOf(block))
+//
+//	    return nil
+//	})
+//
+//	type Config struct {
+//	    CA *pem.Block `yaml:"ca" decoder:"pem" ref:"file:///etc/app/ca.pem"`
+//	}
+func RegisterDecoder(name string, fn func(data []byte, target reflect.Value) error) {
+	if name == "" {
+		panic("fuda: RegisterDecoder called with empty name")
+	}
+	if fn == nil {
+		panic("fuda: RegisterDecoder called with nil decoder for " + name)
+	}
+
+	decoders.mu.Lock()
+	defer decoders.mu.Unlock()
+
+	decoders.decoders[name] = fn
+}
+
+// UnregisterDecoder removes the decoder registered as name. Loaders built
+// before keep using it.
+func UnregisterDecoder(name string) {
+	decoders.mu.Lock()
+	defer decoders.mu.Unlock()
+
+	delete(decoders.decoders, name)
+}
+
+// registeredDecoders returns a snapshot of the registered decoders.
+func registeredDecoders() map[string]Decoder {
+	decoders.mu.RLock()
+	defer decoders.mu.RUnlock()
+
+	if len(decoders.decoders) == 0 {
+		return nil
+	}
+
+	return maps.Clone(decoders.decoders)
+}
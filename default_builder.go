@@ -0,0 +1,48 @@
+package fuda
+
+import "sync/atomic"
+
+// defaultBuilder holds the function registered by SetDefaultBuilder.
+var defaultBuilder atomic.Pointer[func(*Builder)]
+
+// SetDefaultBuilder registers fn to configure the builder used by the
+// convenience functions LoadFile, LoadBytes, and LoadReader (and their Must
+// variants), so organization-wide defaults such as resolvers, timeouts, or an
+// env prefix can be set once while keeping the one-line loading style.
+// fn runs on a fresh builder before the source is set. Passing nil removes
+// the defaults. Builders created with New are not affected.
+//
+// SetDefaultBuilder is typically called from init or main before any config
+// is loaded; it is safe for concurrent use.
+//
+// Example:
+//
+//	func init() {
+//	    fuda.SetDefaultBuilder(func(b *fuda.Builder) {
+//	        b.WithResolver("vault", vaultResolver).
+//	            WithTimeout(10 * time.Second).
+//	            WithEnvPrefix("ACME_")
+//	    })
+//	}
+//
+//	// Elsewhere, unchanged call sites pick up the defaults:
+//	fuda.MustLoadFile("config.yaml", &cfg)
+func SetDefaultBuilder(fn func(*Builder)) {
+	if fn == nil {
+		defaultBuilder.Store(nil)
+
+		return
+	}
+
+	defaultBuilder.Store(&fn)
+}
+
+// newDefault returns a Builder configured by the SetDefaultBuilder function.
+func newDefault() *Builder {
+	b := New()
+	if fn := defaultBuilder.Load(); fn != nil {
+		(*fn)(b)
+	}
+
+	return b
+}
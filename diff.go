@@ -0,0 +1,293 @@
+package fuda
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// FieldChange describes one value that differs between two configs.
+type FieldChange struct {
+	// Path is the dotted path of the value using yaml field names, with
+	// indexes for slice elements (e.g., "database.port", "servers[1].host",
+	// "labels.team").
+	Path string
+	// Old is the previous value, or nil if it did not exist.
+	Old any
+	// New is the current value, or nil if it no longer exists.
+	New any
+	// Redacted reports whether the field is sensitive. Its non-zero Old and
+	// New values are then replaced by "[REDACTED]", so changes can be logged
+	// safely. See Redact for which fields are sensitive.
+	Redacted bool
+}
+
+// String returns a log-friendly description of the change.
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// Diff compares two configs of the same type and returns the values that
+// differ, in field order. before and after are structs or pointers to structs,
+// typically the previous and reloaded config from a watcher. It returns nil
+// if the configs are equal.
+//
+// Changes are reported per leaf value: a changed nested struct field yields
+// one change per differing field, and slice elements and map entries are
+// compared individually. Values that marshal themselves, such as time.Time
+// and Duration, are compared as a whole. OrderedMap entries are compared by
+// key like map entries; if the order of the keys they share changed, one
+// more change at the map's path holds the old and new key order.
+//
+// Example:
+//
+//	for _, c := range fuda.Diff(oldCfg, newCfg) {
+//	    slog.Info("config changed", "path", c.Path, "old", c.Old, "new", c.New)
+//	}
+func Diff(before, after any) []FieldChange {
+	var changes []FieldChange
+
+	oldVal, newVal := reflect.ValueOf(before), reflect.ValueOf(after)
+	if oldVal.IsValid() && newVal.IsValid() && oldVal.Type() != newVal.Type() {
+		return []FieldChange{{Old: before, New: after}}
+	}
+
+	diffValues("", oldVal, newVal, &changes)
+
+	return changes
+}
+
+// diffValues appends the changes between before and after at path.
+func diffValues(path string, before, after reflect.Value, changes *[]FieldChange) {
+	before, oldNil := derefValue(before)
+	after, newNil := derefValue(after)
+	if oldNil || newNil {
+		if oldNil != newNil {
+			*changes = append(*changes, FieldChange{Path: path, Old: valueOrNil(before, oldNil), New: valueOrNil(after, newNil)})
+		}
+
+		return
+	}
+
+	t := before.Type()
+	if t.Kind() == reflect.Struct && t == after.Type() && !encodesItself(t) {
+		// Structs are walked even when only reachable through an unexported
+		// inline field; their exported fields remain accessible.
+		diffStructFields(path, before, after, changes)
+
+		return
+	}
+
+	if !before.CanInterface() || !after.CanInterface() {
+		return
+	}
+
+	if t != after.Type() {
+		// Interface fields holding different dynamic types
+		*changes = append(*changes, FieldChange{Path: path, Old: before.Interface(), New: after.Interface()})
+
+		return
+	}
+
+	switch {
+	case isOrderedMapType(t):
+		diffOrderedMaps(path, before, after, changes)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8,
+		t.Kind() == reflect.Array && !encodesItself(t):
+		diffSequences(path, before, after, changes)
+	case t.Kind() == reflect.Map && !encodesItself(t):
+		diffMaps(path, before, after, changes)
+	default:
+		if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+			*changes = append(*changes, FieldChange{Path: path, Old: before.Interface(), New: after.Interface()})
+		}
+	}
+}
+
+// diffSequences compares two slices or arrays element by element, recording
+// elements present on only one side as additions or removals.
+func diffSequences(path string, before, after reflect.Value, changes *[]FieldChange) {
+	for i := range max(before.Len(), after.Len()) {
+		elemPath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= before.Len():
+			*changes = append(*changes, FieldChange{Path: elemPath, New: after.Index(i).Interface()})
+		case i >= after.Len():
+			*changes = append(*changes, FieldChange{Path: elemPath, Old: before.Index(i).Interface()})
+		default:
+			diffValues(elemPath, before.Index(i), after.Index(i), changes)
+		}
+	}
+}
+
+// diffMaps compares two maps key by key, recording keys present on only one
+// side as additions or removals.
+func diffMaps(path string, before, after reflect.Value, changes *[]FieldChange) {
+	for _, key := range mapKeysUnion(before, after) {
+		keyPath := joinDiffPath(path, fmt.Sprint(key.Interface()))
+		oldElem, newElem := before.MapIndex(key), after.MapIndex(key)
+		switch {
+		case !oldElem.IsValid():
+			*changes = append(*changes, FieldChange{Path: keyPath, New: newElem.Interface()})
+		case !newElem.IsValid():
+			*changes = append(*changes, FieldChange{Path: keyPath, Old: oldElem.Interface()})
+		default:
+			diffValues(keyPath, oldElem, newElem, changes)
+		}
+	}
+}
+
+// diffStructFields compares the fields of two structs of the same type,
+// flattening inline fields and masking sensitive ones.
+func diffStructFields(path string, before, after reflect.Value, changes *[]FieldChange) {
+	t := before.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, inline, skip := yamlFieldName(field)
+		if skip || !dumpable(field.Type) {
+			continue
+		}
+		if !field.IsExported() && !(field.Anonymous && inline) {
+			continue
+		}
+
+		oldField, newField := before.Field(i), after.Field(i)
+		if inline {
+			diffValues(path, oldField, newField, changes)
+
+			continue
+		}
+
+		fieldPath := joinDiffPath(path, name)
+		if !tags.IsSensitive(field) {
+			diffValues(fieldPath, oldField, newField, changes)
+
+			continue
+		}
+
+		if !oldField.CanInterface() || reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+		*changes = append(*changes, FieldChange{
+			Path:     fieldPath,
+			Old:      redactedValue(oldField),
+			New:      redactedValue(newField),
+			Redacted: true,
+		})
+	}
+}
+
+// derefValue follows pointers and interfaces, reporting whether a nil was
+// reached. An invalid value counts as nil.
+func derefValue(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return v, true
+		}
+		v = v.Elem()
+	}
+
+	return v, !v.IsValid()
+}
+
+// valueOrNil returns v as an interface, or nil for nil values.
+func valueOrNil(v reflect.Value, isNil bool) any {
+	if isNil || !v.CanInterface() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+// redactedValue masks a sensitive value unless it is zero, mirroring Redact.
+func redactedValue(v reflect.Value) any {
+	if v.IsZero() {
+		return v.Interface()
+	}
+
+	return tags.RedactedValue
+}
+
+// mapKeysUnion returns the keys present in either map, sorted for
+// deterministic output (see compareMapKeys).
+func mapKeysUnion(a, b reflect.Value) []reflect.Value {
+	seen := make(map[any]bool)
+	var keys []reflect.Value
+	for _, m := range []reflect.Value{a, b} {
+		for _, key := range m.MapKeys() {
+			if !seen[key.Interface()] {
+				seen[key.Interface()] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	slices.SortFunc(keys, compareMapKeys)
+
+	return keys
+}
+
+// diffOrderedMaps compares two OrderedMaps by key, in the order of before
+// followed by the keys only in after, and reports a change of the order of
+// their shared keys.
+func diffOrderedMaps(path string, before, after reflect.Value, changes *[]FieldChange) {
+	oldIdx, newIdx := orderedMapIndex(before), orderedMapIndex(after)
+
+	for i := range before.Len() {
+		key, oldElem := before.Index(i).Field(0), before.Index(i).Field(1)
+		keyPath := joinDiffPath(path, fmt.Sprint(key.Interface()))
+		if j, ok := newIdx[key.Interface()]; ok {
+			diffValues(keyPath, oldElem, after.Index(j).Field(1), changes)
+		} else {
+			*changes = append(*changes, FieldChange{Path: keyPath, Old: oldElem.Interface()})
+		}
+	}
+	for i := range after.Len() {
+		key := after.Index(i).Field(0)
+		if _, ok := oldIdx[key.Interface()]; !ok {
+			keyPath := joinDiffPath(path, fmt.Sprint(key.Interface()))
+			*changes = append(*changes, FieldChange{Path: keyPath, New: after.Index(i).Field(1).Interface()})
+		}
+	}
+
+	oldOrder, newOrder := sharedKeys(before, newIdx), sharedKeys(after, oldIdx)
+	if !reflect.DeepEqual(oldOrder, newOrder) {
+		*changes = append(*changes, FieldChange{Path: path, Old: oldOrder, New: newOrder})
+	}
+}
+
+// orderedMapIndex maps the keys of an OrderedMap to their entry index.
+func orderedMapIndex(m reflect.Value) map[any]int {
+	index := make(map[any]int, m.Len())
+	for i := range m.Len() {
+		index[m.Index(i).Field(0).Interface()] = i
+	}
+
+	return index
+}
+
+// sharedKeys returns the keys of OrderedMap m that are also in other, in
+// the order of m.
+func sharedKeys(m reflect.Value, other map[any]int) []any {
+	var keys []any
+	for i := range m.Len() {
+		key := m.Index(i).Field(0).Interface()
+		if _, ok := other[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// joinDiffPath appends name to a dotted path.
+func joinDiffPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
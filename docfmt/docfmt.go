@@ -0,0 +1,199 @@
+// Package docfmt renders the plain-text tables and section titles shared by
+// fuda-doc's ASCII and Markdown printers and Trace.Explain: box-drawing
+// section titles, "│"-separated columns between "─" dividers, and "…"
+// truncation. Reports from a running service therefore read like the
+// generated documentation.
+//
+// Output is plain unless a Styler is given; fuda-doc uses them to color the
+// terminal output.
+//
+// The package depends only on the standard library.
+package docfmt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Styler decorates rendered text, such as with terminal colors. It is
+// applied after padding, so it never affects the layout.
+type Styler func(string) string
+
+// render applies s to text, or returns text unchanged if s is nil.
+func (s Styler) render(text string) string {
+	if s == nil {
+		return text
+	}
+
+	return s(text)
+}
+
+// TableStyle decorates the parts of an ASCII table. Nil entries leave the
+// part plain.
+type TableStyle struct {
+	// Divider styles the horizontal rules.
+	Divider Styler
+	// Header styles each header cell.
+	Header Styler
+	// Columns styles the body cells of each column.
+	Columns []Styler
+}
+
+// column returns the styler of body column i.
+func (s TableStyle) column(i int) Styler {
+	if i < len(s.Columns) {
+		return s.Columns[i]
+	}
+
+	return nil
+}
+
+// Table is a text table rendered as ASCII or Markdown.
+type Table struct {
+	// Headers names the columns.
+	Headers []string
+	// Rows holds the cells; short rows are padded with empty cells.
+	Rows [][]string
+	// MaxWidths caps each column's width in ASCII output; longer cells are
+	// truncated with "…". A missing or zero entry leaves the column uncapped.
+	MaxWidths []int
+	// Style decorates the ASCII output.
+	Style TableStyle
+}
+
+// WriteASCII writes t as an aligned table, each line prefixed with indent.
+func (t Table) WriteASCII(w io.Writer, indent string) error {
+	widths := t.widths()
+
+	total := 3 * (len(widths) - 1) // " │ " separators
+	for _, width := range widths {
+		total += width
+	}
+	divider := indent + "  " + t.Style.Divider.render(strings.Repeat("─", total)) + "\n"
+
+	line := func(cells func(i int) string, style func(i int) Styler) string {
+		parts := make([]string, len(widths))
+		for i, width := range widths {
+			text := PadOrTrunc(cells(i), width)
+			if i == len(widths)-1 {
+				text = strings.TrimRight(text, " ")
+			}
+			parts[i] = style(i).render(text)
+		}
+
+		return indent + "  " + strings.TrimRight(strings.Join(parts, " │ "), " ") + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(divider)
+	b.WriteString(line(func(i int) string { return t.Headers[i] }, func(int) Styler { return t.Style.Header }))
+	b.WriteString(divider)
+	for _, row := range t.Rows {
+		b.WriteString(line(func(i int) string { return cell(row, i) }, t.Style.column))
+	}
+	b.WriteString(divider)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// widths returns the width of each column: its widest cell, capped by
+// MaxWidths.
+func (t Table) widths() []int {
+	widths := make([]int, len(t.Headers))
+	for i, h := range t.Headers {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.Rows {
+		for i := range widths {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell(row, i)))
+		}
+	}
+	for i := range widths {
+		if i < len(t.MaxWidths) && t.MaxWidths[i] > 0 {
+			widths[i] = min(widths[i], t.MaxWidths[i])
+		}
+	}
+
+	return widths
+}
+
+// WriteMarkdown writes t as a GitHub-flavored Markdown table with
+// left-aligned columns. Pipes and newlines in cells are escaped.
+func (t Table) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("|")
+	for _, h := range t.Headers {
+		fmt.Fprintf(&b, " %s |", escapeMarkdown(h))
+	}
+	b.WriteString("\n|")
+	for _, h := range t.Headers {
+		fmt.Fprintf(&b, ":%s|", strings.Repeat("-", max(utf8.RuneCountInString(h), 3)))
+	}
+	b.WriteString("\n")
+
+	for _, row := range t.Rows {
+		b.WriteString("|")
+		for i := range t.Headers {
+			fmt.Fprintf(&b, " %s |", escapeMarkdown(cell(row, i)))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// SectionTitle returns title in a box, as fuda-doc prints top-level
+// sections. style, if not nil, decorates each line of the box.
+func SectionTitle(title string, style Styler) string {
+	bar := strings.Repeat("─", utf8.RuneCountInString(title)+2)
+
+	return "  " + style.render("┌"+bar+"┐") + "\n" +
+		"  " + style.render("│ "+title+" │") + "\n" +
+		"  " + style.render("└"+bar+"┘") + "\n"
+}
+
+// SubsectionTitle returns an underlined title, indented two spaces per depth,
+// as fuda-doc prints nested struct sections. style, if not nil, decorates
+// the title line.
+func SubsectionTitle(title string, depth int, style Styler) string {
+	bar := strings.Repeat("─", utf8.RuneCountInString(title)+4)
+
+	return strings.Repeat("  ", depth) + "  " + style.render("── "+title+" "+bar) + "\n"
+}
+
+// PadOrTrunc pads s with spaces to width runes, or truncates it with "…".
+func PadOrTrunc(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		if width <= 0 {
+			return ""
+		}
+
+		return string([]rune(s)[:width-1]) + "…"
+	}
+
+	return s + strings.Repeat(" ", width-n)
+}
+
+// cell returns row[i], or "" for a short row.
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+
+	return ""
+}
+
+// escapeMarkdown makes s safe inside a Markdown table cell.
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,73 @@
+package docfmt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_WriteASCII(t *testing.T) {
+	table := Table{
+		Headers:   []string{"Field", "Source"},
+		Rows:      [][]string{{"Host", "env APP_HOST=example.com"}, {"Port"}},
+		MaxWidths: []int{0, 12},
+	}
+
+	var b strings.Builder
+	require.NoError(t, table.WriteASCII(&b, "  "))
+	assert.Equal(t, ""+
+		"    ────────────────────\n"+
+		"    Field │ Source\n"+
+		"    ────────────────────\n"+
+		"    Host  │ env APP_HOS…\n"+
+		"    Port  │\n"+
+		"    ────────────────────\n", b.String())
+}
+
+func TestTable_WriteASCII_Style(t *testing.T) {
+	bracket := func(s string) string { return "[" + s + "]" }
+	table := Table{
+		Headers: []string{"Field", "Source"},
+		Rows:    [][]string{{"Host", "env"}},
+		Style:   TableStyle{Divider: bracket, Header: bracket, Columns: []Styler{bracket}},
+	}
+
+	var b strings.Builder
+	require.NoError(t, table.WriteASCII(&b, ""))
+	assert.Equal(t, ""+
+		"  [──────────────]\n"+
+		"  [Field] │ [Source]\n"+
+		"  [──────────────]\n"+
+		"  [Host ] │ env\n"+
+		"  [──────────────]\n", b.String())
+}
+
+func TestTable_WriteMarkdown(t *testing.T) {
+	table := Table{
+		Headers: []string{"Field", "Description"},
+		Rows:    [][]string{{"Mode", "a|b\nc"}, {"Port"}},
+	}
+
+	var b strings.Builder
+	require.NoError(t, table.WriteMarkdown(&b))
+	assert.Equal(t, ""+
+		"| Field | Description |\n"+
+		"|:-----|:-----------|\n"+
+		"| Mode | a\\|b<br>c |\n"+
+		"| Port |  |\n", b.String())
+}
+
+func TestTitles(t *testing.T) {
+	assert.Equal(t, "  ┌──────┐\n  │ Élan │\n  └──────┘\n", SectionTitle("Élan", nil))
+	assert.Equal(t, "    ── DB ──────\n", SubsectionTitle("DB", 1, nil))
+	assert.Equal(t, "  <── DB ──────>\n", SubsectionTitle("DB", 0, func(s string) string { return "<" + s + ">" }))
+}
+
+func TestPadOrTrunc(t *testing.T) {
+	assert.Equal(t, "ab  ", PadOrTrunc("ab", 4))
+	assert.Equal(t, "abcd", PadOrTrunc("abcd", 4))
+	assert.Equal(t, "abc…", PadOrTrunc("abcde", 4))
+	assert.Empty(t, PadOrTrunc("abc", 0))
+}
@@ -0,0 +1,163 @@
+package fuda
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/arloliu/fuda/internal/loader"
+)
+
+// TraceRecorder is an io.Writer for Builder.WithTrace that keeps the
+// provenance report of the most recent successful load, so it can be included
+// in config dumps long after startup. It is safe for concurrent use.
+type TraceRecorder struct {
+	mu             sync.Mutex
+	pending        bytes.Buffer
+	last           []byte
+	pendingRecords []loader.TraceRecord
+	lastRecords    []loader.TraceRecord
+}
+
+// DumpOptions configures DumpOnSignal.
+type DumpOptions struct {
+	// Snapshot returns the effective config to dump. It is called on every
+	// signal, so return the current value when the config is hot-reloaded.
+	Snapshot func() any
+	// Trace, if set, supplies the provenance report appended to the dump.
+	// Pass the same recorder to Builder.WithTrace.
+	Trace *TraceRecorder
+	// Path is the file the dump is written to, atomically replacing any
+	// previous dump.
+	// Empty writes to Output.
+	Path string
+	// Output receives the dump when Path is empty. Defaults to os.Stderr.
+	Output io.Writer
+	// EncryptionKey, if set, encrypts the dump file with EncryptSnapshot so
+	// it never lands on disk in plaintext. Read it back with DecryptSnapshot.
+	// Ignored when writing to Output.
+	EncryptionKey []byte
+}
+
+var _ io.Writer = (*TraceRecorder)(nil)
+
+// Write appends trace output of the load in progress.
+func (r *TraceRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.pending.Write(p)
+}
+
+// String returns the provenance report of the most recent successful load.
+func (r *TraceRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return string(r.last)
+}
+
+// begin discards trace output of any unfinished load.
+func (r *TraceRecorder) begin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending.Reset()
+	r.pendingRecords = nil
+}
+
+// record collects the structured trace of a field of the load in progress.
+func (r *TraceRecorder) record(rec loader.TraceRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pendingRecords = append(r.pendingRecords, rec)
+}
+
+// commit publishes the trace of the load that just succeeded.
+func (r *TraceRecorder) commit() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last = bytes.Clone(r.pending.Bytes())
+	r.pending.Reset()
+	r.lastRecords = r.pendingRecords
+	r.pendingRecords = nil
+}
+
+// dump writes the redacted config and provenance report to the configured
+// destination.
+func (o DumpOptions) dump() error {
+	data, err := o.render()
+	if err != nil {
+		return err
+	}
+
+	if o.Path == "" {
+		out := o.Output
+		if out == nil {
+			out = os.Stderr
+		}
+		_, err = out.Write(data)
+
+		return err
+	}
+
+	if len(o.EncryptionKey) > 0 {
+		if data, err = EncryptSnapshot(o.EncryptionKey, data); err != nil {
+			return fmt.Errorf("encrypt dump: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(o.Path, data); err != nil {
+		return fmt.Errorf("write dump file: %w", err)
+	}
+
+	return nil
+}
+
+// render formats the redacted config followed by the provenance report.
+func (o DumpOptions) render() ([]byte, error) {
+	var cfg any
+	if o.Snapshot != nil {
+		cfg = o.Snapshot()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# effective config\n")
+	if err := DumpRedacted(&buf, cfg); err != nil {
+		return nil, fmt.Errorf("render config: %w", err)
+	}
+
+	if o.Trace != nil {
+		buf.WriteString("# provenance\n")
+		buf.WriteString(o.Trace.String())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic replaces path with data via a temporary file in the same
+// directory, so readers never observe a partially written file. The file is
+// created with mode 0600.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package fuda
+
+// DumpOnSignal is a no-op on platforms without SIGUSR1.
+// See the unix implementation for details.
+func DumpOnSignal(_ DumpOptions) (stop func()) {
+	return func() {}
+}
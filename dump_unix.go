@@ -0,0 +1,61 @@
+//go:build unix
+
+package fuda
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// DumpOnSignal installs a SIGUSR1 handler that writes the effective config,
+// with sensitive fields redacted, followed by the provenance report to a
+// file or stderr. Call the returned function to remove the handler.
+//
+// Secret fields (see Redact) are masked in both the config and the
+// provenance report.
+//
+// Example:
+//
+//	trace := &fuda.TraceRecorder{}
+//	loader, _ := fuda.New().FromFile("config.yaml").WithTrace(trace).Build()
+//	_ = loader.Load(&cfg)
+//
+//	stop := fuda.DumpOnSignal(fuda.DumpOptions{
+//	    Snapshot: func() any { return &cfg },
+//	    Trace:    trace,
+//	})
+//	defer stop()
+//
+//	// kill -USR1 <pid>
+//
+// On platforms without SIGUSR1 the handler is not installed.
+func DumpOnSignal(opts DumpOptions) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGUSR1)
+
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				if err := opts.dump(); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "fuda: config dump failed: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+}
@@ -92,6 +92,23 @@ func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
 	return fmt.Errorf("invalid duration value: %s", node.Value)
 }
 
+// Scan implements Scanner for the default, env, and ref tags, parsing a
+// duration string such as "1h30m" or "7d".
+func (d *Duration) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("fuda.Duration: cannot scan %T", src)
+	}
+
+	parsed, err := parseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("invalid duration string %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
+
 // parseDuration extends time.ParseDuration to support days with 'd' suffix.
 // Examples: "5d" -> 5 days, "1d12h" -> 1 day and 12 hours, "2d30m" -> 2 days and 30 minutes.
 func parseDuration(s string) (time.Duration, error) {
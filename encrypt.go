@@ -0,0 +1,126 @@
+package fuda
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// snapshotMagic prefixes encrypted snapshots and identifies the format version.
+var snapshotMagic = []byte("FUDAENC1")
+
+// EncryptSnapshot seals plaintext (typically rendered config) with AES-GCM so
+// resolved secrets never land on disk in plaintext. key must be 16, 24, or 32
+// bytes, selecting AES-128, AES-192, or AES-256.
+//
+// The output is self-describing: a format header, a random nonce, and the
+// authenticated ciphertext. Decrypt it with DecryptSnapshot.
+//
+// Example:
+//
+//	key, err := fuda.SnapshotKeyFromEnv("APP_SNAPSHOT_KEY")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	sealed, err := fuda.EncryptSnapshot(key, rendered)
+func EncryptSnapshot(key, plaintext []byte) ([]byte, error) {
+	aead, err := newSnapshotAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(snapshotMagic)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, snapshotMagic...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plaintext, snapshotMagic), nil
+}
+
+// DecryptSnapshot opens data produced by EncryptSnapshot. It fails if the key
+// is wrong or the data has been tampered with.
+//
+// Example:
+//
+//	plain, err := fuda.DecryptSnapshot(key, sealed)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	loader, _ := fuda.New().FromBytes(plain).Build()
+func DecryptSnapshot(key, data []byte) ([]byte, error) {
+	if !IsEncryptedSnapshot(data) {
+		return nil, &FieldError{Message: "data is not an encrypted fuda snapshot"}
+	}
+
+	aead, err := newSnapshotAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[len(snapshotMagic):]
+	if len(data) < aead.NonceSize() {
+		return nil, &FieldError{Message: "encrypted snapshot is truncated"}
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, snapshotMagic)
+	if err != nil {
+		return nil, &FieldError{Message: "failed to decrypt snapshot (wrong key or corrupted data)", Err: err}
+	}
+
+	return plaintext, nil
+}
+
+// IsEncryptedSnapshot reports whether data starts with the header written
+// by EncryptSnapshot.
+func IsEncryptedSnapshot(data []byte) bool {
+	return bytes.HasPrefix(data, snapshotMagic)
+}
+
+// SnapshotKeyFromEnv reads a base64-encoded AES key from the environment
+// variable name. Generate a 256-bit key with:
+//
+//	openssl rand -base64 32
+//
+// To use a key managed by a KMS, decrypt it with your KMS client and pass the
+// raw bytes to EncryptSnapshot directly.
+func SnapshotKeyFromEnv(name string) ([]byte, error) {
+	encoded, ok := os.LookupEnv(name)
+	if !ok || encoded == "" {
+		return nil, &FieldError{Message: fmt.Sprintf("snapshot key environment variable %s is not set", name)}
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, &FieldError{Message: fmt.Sprintf("snapshot key in %s is not valid base64", name), Err: err}
+	}
+
+	if _, err := newSnapshotAEAD(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// newSnapshotAEAD creates the AES-GCM cipher for snapshot encryption.
+func newSnapshotAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, &FieldError{Message: "invalid snapshot key: must be 16, 24, or 32 bytes", Err: err}
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM cipher: %w", err)
+	}
+
+	return aead, nil
+}
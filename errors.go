@@ -1,8 +1,15 @@
 package fuda
 
-import "github.com/arloliu/fuda/internal/types"
+import (
+	"errors"
+
+	"github.com/arloliu/fuda/internal/types"
+)
 
 // FieldError represents an error that occurred while processing a specific field.
+// Path is the dotted field path, Tag the tag whose source failed, and Source
+// where the failing value came from, such as an environment variable, a
+// flag, or a ref URI.
 type FieldError = types.FieldError
 
 // LoadError represents an error that occurred during the configuration loading process.
@@ -10,3 +17,45 @@ type LoadError = types.LoadError
 
 // ValidationError wraps validation errors from the validator package.
 type ValidationError = types.ValidationError
+
+// FieldErrors returns the field errors held by err, in field order: those of
+// a LoadError or ValidationError, or err itself if it is a FieldError. It
+// returns nil if err holds none. Use it to handle or render each failing
+// field on its own, for example in a CLI:
+//
+//	for _, fe := range fuda.FieldErrors(err) {
+//	    if fe.Tag == "env" && fe.Source != "" {
+//	        fmt.Fprintf(os.Stderr, "check %s: %v\n", fe.Source, fe.Err)
+//	    }
+//	}
+func FieldErrors(err error) []*FieldError {
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) {
+		errs := make([]*FieldError, len(loadErr.Errors))
+		for i := range loadErr.Errors {
+			errs[i] = &loadErr.Errors[i]
+		}
+
+		return errs
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		var errs []*FieldError
+		for _, e := range validationErr.Errors {
+			var fe *FieldError
+			if errors.As(e, &fe) {
+				errs = append(errs, fe)
+			}
+		}
+
+		return errs
+	}
+
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return []*FieldError{fe}
+	}
+
+	return nil
+}
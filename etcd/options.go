@@ -0,0 +1,81 @@
+package etcd
+
+import (
+	"crypto/tls"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Option configures an etcd resolver.
+type Option func(*resolverConfig)
+
+// WithEndpoints sets the etcd cluster endpoints.
+// Either endpoints or an existing client (WithClient) is required.
+//
+// Example:
+//
+//	etcd.WithEndpoints("https://etcd-0:2379", "https://etcd-1:2379")
+func WithEndpoints(endpoints ...string) Option {
+	return func(c *resolverConfig) {
+		c.endpoints = endpoints
+	}
+}
+
+// WithAuth sets username/password authentication for the etcd client.
+//
+// Example:
+//
+//	etcd.WithAuth("app", os.Getenv("ETCD_PASSWORD"))
+func WithAuth(username, password string) Option {
+	return func(c *resolverConfig) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithTLS sets the TLS configuration used to connect to etcd.
+//
+// Example:
+//
+//	etcd.WithTLS(&tls.Config{RootCAs: pool})
+func WithTLS(cfg *tls.Config) Option {
+	return func(c *resolverConfig) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithDialTimeout sets the timeout for establishing the initial connection.
+//
+// Default is 5 seconds.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *resolverConfig) {
+		c.dialTimeout = timeout
+	}
+}
+
+// WithKeyPrefix sets a prefix prepended to every key before it is read
+// from etcd. This allows refs to use short keys within a namespace.
+//
+// Example:
+//
+//	// ref:"etcd://db/password" reads /myapp/db/password
+//	etcd.WithKeyPrefix("/myapp/")
+func WithKeyPrefix(prefix string) Option {
+	return func(c *resolverConfig) {
+		c.keyPrefix = prefix
+	}
+}
+
+// WithClient uses an existing etcd client instead of creating a new one.
+// The resolver does not close a client supplied this way.
+//
+// Example:
+//
+//	cli, _ := clientv3.New(clientv3.Config{Endpoints: endpoints})
+//	resolver, _ := etcd.NewResolver(etcd.WithClient(cli))
+func WithClient(client *clientv3.Client) Option {
+	return func(c *resolverConfig) {
+		c.client = client
+	}
+}
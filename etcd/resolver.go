@@ -0,0 +1,270 @@
+// Package etcd provides an etcd v3 resolver for fuda.
+//
+// This package implements [fuda.RefResolver] to fetch values from etcd using
+// the etcd:// URI scheme, and can also serve the main configuration document
+// from an etcd key. The resolver implements the watcher package's
+// WatchableResolver interface, so etcd watch events trigger a hot reload
+// immediately instead of waiting for the polling ticker.
+//
+// Basic usage:
+//
+//	resolver, err := etcd.NewResolver(
+//	    etcd.WithEndpoints("http://localhost:2379"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer resolver.Close()
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithRefResolver(resolver).
+//	    Build()
+//
+// # URI Format
+//
+// Everything after the scheme is used verbatim as the etcd key:
+//
+//	etcd://<key>
+//
+// Examples:
+//   - etcd:///myapp/db/password (key "/myapp/db/password")
+//   - etcd://myapp/api_key (key "myapp/api_key")
+//
+// A missing key is reported as [os.ErrNotExist], so the `default` tag
+// applies as a fallback.
+//
+// # Configuration Source
+//
+// The main configuration document can also live in etcd:
+//
+//	data, err := resolver.Get(ctx, "/myapp/config.yaml")
+//	loader, _ := fuda.New().FromBytes(data).WithRefResolver(resolver).Build()
+//
+// For hot reload, pass [Resolver.Source] to the watcher:
+//
+//	w, _ := watcher.New().
+//	    FromSource(resolver.Source("/myapp/config.yaml")).
+//	    WithRefResolver(resolver).
+//	    Build()
+package etcd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// defaultDialTimeout is the default timeout for connecting to etcd.
+const defaultDialTimeout = 5 * time.Second
+
+// Resolver implements fuda.RefResolver for etcd.
+// It resolves etcd:// URIs by reading keys from an etcd cluster and
+// remembers every key it has read so that Watch can subscribe to them.
+type Resolver struct {
+	kv        clientv3.KV
+	watcher   clientv3.Watcher
+	client    *clientv3.Client
+	ownClient bool
+	keyPrefix string
+
+	mu       sync.Mutex
+	keys     map[string]struct{}
+	watchCtx context.Context //nolint:containedctx // lifetime of the active Watch call
+	changes  chan struct{}
+}
+
+// resolverConfig holds internal configuration for the resolver.
+type resolverConfig struct {
+	endpoints   []string
+	username    string
+	password    string
+	tlsConfig   *tls.Config
+	dialTimeout time.Duration
+	keyPrefix   string
+	client      *clientv3.Client
+}
+
+// NewResolver creates a new etcd resolver with the given options.
+//
+// Either endpoints or an existing client must be provided:
+//
+//	resolver, err := etcd.NewResolver(
+//	    etcd.WithEndpoints("http://localhost:2379"),
+//	)
+//
+// Available options:
+//   - [WithEndpoints] - etcd cluster endpoints
+//   - [WithClient] - Reuse an existing etcd client
+//   - [WithAuth] - Username/password authentication
+//   - [WithTLS] - Custom TLS configuration
+//   - [WithDialTimeout] - Connection timeout
+//   - [WithKeyPrefix] - Prefix prepended to every key
+func NewResolver(opts ...Option) (*Resolver, error) {
+	cfg := &resolverConfig{
+		dialTimeout: defaultDialTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.client != nil {
+		r := newResolver(cfg.client.KV, cfg.client.Watcher, cfg.keyPrefix)
+		r.client = cfg.client
+
+		return r, nil
+	}
+
+	if len(cfg.endpoints) == 0 {
+		return nil, errors.New("etcd endpoints are required: use WithEndpoints() or WithClient()")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.endpoints,
+		Username:    cfg.username,
+		Password:    cfg.password,
+		TLS:         cfg.tlsConfig,
+		DialTimeout: cfg.dialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+
+	r := newResolver(client.KV, client.Watcher, cfg.keyPrefix)
+	r.client = client
+	r.ownClient = true
+
+	return r, nil
+}
+
+// newResolver creates a resolver on top of the given KV and Watcher.
+func newResolver(kv clientv3.KV, w clientv3.Watcher, keyPrefix string) *Resolver {
+	return &Resolver{
+		kv:        kv,
+		watcher:   w,
+		keyPrefix: keyPrefix,
+		keys:      make(map[string]struct{}),
+	}
+}
+
+// Resolve fetches the value of the etcd key referenced by the given URI.
+//
+// URI format: etcd://<key>
+func (r *Resolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	if !strings.HasPrefix(uri, "etcd://") {
+		return nil, fmt.Errorf("unsupported scheme for etcd resolver: %s", uri)
+	}
+
+	key := strings.TrimPrefix(uri, "etcd://")
+	if key == "" {
+		return nil, fmt.Errorf("etcd URI missing key: %s", uri)
+	}
+
+	return r.Get(ctx, key)
+}
+
+// Get reads the value stored at key (after applying the key prefix).
+// It returns an error wrapping [os.ErrNotExist] if the key does not exist.
+// The key is remembered so that Watch reports changes to it, including
+// its later creation.
+func (r *Resolver) Get(ctx context.Context, key string) ([]byte, error) {
+	fullKey := r.keyPrefix + key
+	r.track(fullKey)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.kv.Get(ctx, fullKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read etcd key %q: %w", fullKey, err)
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("etcd key %q not found: %w", fullKey, os.ErrNotExist)
+	}
+
+	return resp.Kvs[0].Value, nil
+}
+
+// Source returns a function that fetches the configuration document stored
+// at key. It is intended for watcher.Builder.FromSource.
+func (r *Resolver) Source(key string) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		return r.Get(ctx, key)
+	}
+}
+
+// Watch subscribes to every key read so far, and every key read later, and
+// returns a channel that receives a value whenever one of them changes.
+// Notifications are coalesced: the channel has a buffer of one and a pending
+// notification is not duplicated. Watching stops when ctx is canceled.
+//
+// Only one Watch may be active at a time.
+func (r *Resolver) Watch(ctx context.Context) (<-chan struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watchCtx != nil && r.watchCtx.Err() == nil {
+		return nil, errors.New("etcd resolver is already being watched")
+	}
+
+	r.watchCtx = ctx
+	r.changes = make(chan struct{}, 1)
+	for key := range r.keys {
+		go r.watchKey(ctx, key, r.changes)
+	}
+
+	return r.changes, nil
+}
+
+// Close closes the underlying etcd client if it was created by NewResolver.
+// Clients supplied via WithClient are left open.
+func (r *Resolver) Close() error {
+	if r.ownClient && r.client != nil {
+		return r.client.Close()
+	}
+
+	return nil
+}
+
+// Client returns the underlying etcd client for advanced usage.
+func (r *Resolver) Client() *clientv3.Client {
+	return r.client
+}
+
+// track records key and starts watching it if a Watch is active.
+func (r *Resolver) track(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key]; ok {
+		return
+	}
+	r.keys[key] = struct{}{}
+
+	if r.watchCtx != nil && r.watchCtx.Err() == nil {
+		go r.watchKey(r.watchCtx, key, r.changes)
+	}
+}
+
+// watchKey forwards etcd watch events for key to ch until ctx is canceled.
+func (r *Resolver) watchKey(ctx context.Context, key string, ch chan struct{}) {
+	for resp := range r.watcher.Watch(ctx, key) {
+		if resp.Err() != nil || len(resp.Events) == 0 {
+			continue
+		}
+
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
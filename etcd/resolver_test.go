@@ -0,0 +1,210 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeKV is an in-memory clientv3.KV supporting Get only.
+type fakeKV struct {
+	clientv3.KV
+
+	mu     sync.Mutex
+	values map[string]string
+	err    error
+}
+
+func (f *fakeKV) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	resp := &clientv3.GetResponse{}
+	if v, ok := f.values[key]; ok {
+		resp.Kvs = []*mvccpb.KeyValue{{Key: []byte(key), Value: []byte(v)}}
+	}
+
+	return resp, nil
+}
+
+// fakeWatcher is a clientv3.Watcher whose events are injected by the test.
+type fakeWatcher struct {
+	clientv3.Watcher
+
+	mu      sync.Mutex
+	chans   map[string]chan clientv3.WatchResponse
+	started chan string
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{
+		chans:   make(map[string]chan clientv3.WatchResponse),
+		started: make(chan string, 16),
+	}
+}
+
+func (f *fakeWatcher) Watch(ctx context.Context, key string, _ ...clientv3.OpOption) clientv3.WatchChan {
+	ch := make(chan clientv3.WatchResponse)
+
+	f.mu.Lock()
+	f.chans[key] = ch
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		delete(f.chans, key)
+		f.mu.Unlock()
+		close(ch)
+	}()
+	f.started <- key
+
+	return ch
+}
+
+func (f *fakeWatcher) emit(key string) {
+	f.mu.Lock()
+	ch := f.chans[key]
+	f.mu.Unlock()
+
+	ch <- clientv3.WatchResponse{Events: []*clientv3.Event{{Type: mvccpb.PUT}}}
+}
+
+func TestNewResolver(t *testing.T) {
+	t.Run("requires endpoints or client", func(t *testing.T) {
+		_, err := NewResolver()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "endpoints are required")
+	})
+
+	t.Run("creates resolver with endpoints", func(t *testing.T) {
+		r, err := NewResolver(
+			WithEndpoints("http://127.0.0.1:2379"),
+			WithDialTimeout(time.Second),
+			WithKeyPrefix("/app/"),
+		)
+		require.NoError(t, err)
+		defer r.Close()
+
+		assert.NotNil(t, r.Client())
+		assert.Equal(t, "/app/", r.keyPrefix)
+	})
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	kv := &fakeKV{values: map[string]string{
+		"/myapp/db/password": "secret",
+		"myapp/api_key":      "key-123",
+	}}
+	r := newResolver(kv, newFakeWatcher(), "")
+
+	tests := []struct {
+		name    string
+		uri     string
+		want    string
+		wantErr string
+	}{
+		{name: "absolute key", uri: "etcd:///myapp/db/password", want: "secret"},
+		{name: "relative key", uri: "etcd://myapp/api_key", want: "key-123"},
+		{name: "wrong scheme", uri: "vault:///secret#x", wantErr: "unsupported scheme"},
+		{name: "missing key", uri: "etcd://", wantErr: "missing key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(t.Context(), tt.uri)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("not found is ErrNotExist", func(t *testing.T) {
+		_, err := r.Resolve(t.Context(), "etcd:///missing")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("client error is wrapped", func(t *testing.T) {
+		failing := newResolver(&fakeKV{err: errors.New("connection refused")}, newFakeWatcher(), "")
+		_, err := failing.Resolve(t.Context(), "etcd:///any")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, os.ErrNotExist)
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+
+	t.Run("key prefix", func(t *testing.T) {
+		prefixed := newResolver(kv, newFakeWatcher(), "/myapp/")
+		got, err := prefixed.Resolve(t.Context(), "etcd://db/password")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(got))
+	})
+}
+
+func TestResolver_Source(t *testing.T) {
+	kv := &fakeKV{values: map[string]string{"/myapp/config.yaml": "host: etcd.local\n"}}
+	r := newResolver(kv, newFakeWatcher(), "")
+
+	data, err := r.Source("/myapp/config.yaml")(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "host: etcd.local\n", string(data))
+}
+
+func TestResolver_Watch(t *testing.T) {
+	kv := &fakeKV{values: map[string]string{"/a": "1"}}
+	fw := newFakeWatcher()
+	r := newResolver(kv, fw, "")
+
+	_, err := r.Resolve(t.Context(), "etcd:///a")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	changes, err := r.Watch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "/a", <-fw.started)
+
+	t.Run("rejects concurrent watch", func(t *testing.T) {
+		_, err := r.Watch(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("notifies on change of known key", func(t *testing.T) {
+		fw.emit("/a")
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for change notification")
+		}
+	})
+
+	t.Run("watches keys resolved after Watch", func(t *testing.T) {
+		_, err := r.Resolve(t.Context(), "etcd:///b")
+		require.ErrorIs(t, err, os.ErrNotExist)
+		assert.Equal(t, "/b", <-fw.started)
+
+		fw.emit("/b")
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for change notification")
+		}
+	})
+}
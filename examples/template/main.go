@@ -1,3 +1,5 @@
+//go:build !fuda_minimal
+
 // Example: Template processing
 //
 // This example demonstrates using Go templates to dynamically
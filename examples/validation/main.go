@@ -1,3 +1,5 @@
+//go:build !fuda_minimal
+
 // Example: Validation with go-playground/validator
 //
 // This example demonstrates using the validate tag for
@@ -9,10 +9,10 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/arloliu/fuda"
 	"github.com/arloliu/fuda/watcher"
 )
 
@@ -23,7 +23,8 @@ type Config struct {
 	Timeout  string `yaml:"timeout" default:"30s"`
 }
 
-var globalConfig atomic.Pointer[Config]
+// globalConfig always holds the latest config; safe to read from any goroutine.
+var globalConfig fuda.Value[Config]
 
 func main() {
 	// Create config file
@@ -39,24 +40,18 @@ func main() {
 	}
 	defer w.Stop()
 
-	var cfg Config
-	updates, err := w.Watch(&cfg)
-	if err != nil {
-		log.Fatal(err)
+	// Report each changed value
+	for _, path := range []string{"app_name", "log_level", "max_connections", "timeout"} {
+		w.OnChange(path, func(oldValue, newValue any) {
+			fmt.Printf("Config updated: %s %v -> %v\n", path, oldValue, newValue)
+		})
 	}
 
-	// Store initial config
-	globalConfig.Store(&cfg)
-	printConfig("Initial config", &cfg)
-
-	// Handle updates in a goroutine
-	go func() {
-		for newCfg := range updates {
-			c := newCfg.(*Config)
-			globalConfig.Store(c)
-			printConfig("Config updated", c)
-		}
-	}()
+	// Keep globalConfig up to date with every reload
+	if err := watcher.Bind(w, &globalConfig); err != nil {
+		log.Fatal(err)
+	}
+	printConfig("Initial config", globalConfig.Get())
 
 	fmt.Println("\n=== Watching for changes ===")
 	fmt.Println("Edit config.yaml to see hot-reload in action!")
@@ -0,0 +1,255 @@
+package fuda
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arloliu/fuda/docfmt"
+	"github.com/arloliu/fuda/internal/loader"
+)
+
+// ExplainFormat selects the output format of TraceRecorder.Explain.
+type ExplainFormat int
+
+const (
+	// ExplainText renders ASCII tables for terminals and logs, laid out like
+	// fuda-doc's terminal output. This is the default.
+	ExplainText ExplainFormat = iota
+	// ExplainMarkdown renders Markdown tables, laid out like fuda-doc's
+	// Markdown output.
+	ExplainMarkdown
+)
+
+// explainSection groups the traced fields of one struct.
+type explainSection struct {
+	path    string
+	doc     string
+	records []loader.TraceRecord
+}
+
+// Explain writes the provenance report of the most recent successful load as
+// tables: for every field, the source that supplied its value and the other
+// sources consulted. Fields are grouped by struct, and each nested struct's
+// doc tag is printed under its heading, so the report doubles as a guide to
+// the running configuration.
+//
+// Like the plain trace, the report never includes resolved ref contents, and
+// values of sensitive fields (see Redact) are masked.
+//
+// Example:
+//
+//	trace := &fuda.TraceRecorder{}
+//	loader, _ := fuda.New().FromFile("config.yaml").WithTrace(trace).Build()
+//	_ = loader.Load(&cfg)
+//
+//	_ = trace.Explain(os.Stdout, fuda.ExplainText)
+func (r *TraceRecorder) Explain(w io.Writer, format ExplainFormat) error {
+	r.mu.Lock()
+	records := r.lastRecords
+	r.mu.Unlock()
+
+	sections := explainSections(records)
+	if format == ExplainMarkdown {
+		return writeExplainMarkdown(w, sections)
+	}
+
+	return writeExplainText(w, "Provenance", sections, false)
+}
+
+// Explain loads target with l and writes a table of every field to w: its
+// final value, the source that supplied it, and the other sources consulted
+// on the way, such as a ref that was not found before its default applied.
+// It is the configuration counterpart of kubectl explain, answering "where
+// did this value come from?" for the whole config at once:
+//
+//	var cfg Config
+//	if err := fuda.Explain(loader, &cfg, os.Stdout); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Values of sensitive fields (see Redact), which include ref fields unless
+// tagged `secret:"false"`, are masked. If the load fails once fields were
+// processed, as when validation fails, the table is still written so the
+// offending values can be seen, and the load error is returned. Explain is
+// equivalent to ExplainContext with context.Background().
+func Explain(l *Loader, target any, w io.Writer) error {
+	return ExplainContext(context.Background(), l, target, w)
+}
+
+// ExplainContext is like Explain, aborting the load when ctx is canceled or
+// its deadline passes.
+func ExplainContext(ctx context.Context, l *Loader, target any, w io.Writer) error {
+	rec := &TraceRecorder{}
+	loadErr := l.load(ctx, target, "", nil, rec)
+
+	records := rec.lastRecords
+	if loadErr != nil {
+		records = rec.pendingRecords
+	}
+	if len(records) > 0 {
+		if err := writeExplainText(w, "Configuration", explainSections(records), true); err != nil {
+			return err
+		}
+	}
+
+	return loadErr
+}
+
+// explainSections groups records by their parent struct, in load order with
+// the top-level fields first.
+func explainSections(records []loader.TraceRecord) []*explainSection {
+	docs := make(map[string]string)
+	for _, rec := range records {
+		if rec.Section {
+			docs[rec.Path] = rec.Doc
+		}
+	}
+
+	root := &explainSection{}
+	sections := []*explainSection{root}
+	byPath := map[string]*explainSection{"": root}
+	for _, rec := range records {
+		if rec.Section {
+			continue
+		}
+
+		parent, _ := splitFieldPath(rec.Path)
+		sec, ok := byPath[parent]
+		if !ok {
+			sec = &explainSection{path: parent, doc: docs[trimIndex(parent)]}
+			byPath[parent] = sec
+			sections = append(sections, sec)
+		}
+		sec.records = append(sec.records, rec)
+	}
+
+	return sections
+}
+
+// writeExplainText renders sections as ASCII tables under title, with a
+// Value column if withValues is set.
+func writeExplainText(w io.Writer, title string, sections []*explainSection, withValues bool) error {
+	var b strings.Builder
+	b.WriteString(docfmt.SectionTitle(title, nil))
+	b.WriteString("\n")
+
+	for _, sec := range sections {
+		if len(sec.records) == 0 {
+			continue
+		}
+
+		depth := 0
+		if sec.path != "" {
+			depth = strings.Count(sec.path, ".") + 1
+			b.WriteString(docfmt.SubsectionTitle(sec.path, depth-1, nil))
+			b.WriteString("\n")
+		}
+		indent := strings.Repeat("  ", depth)
+		if sec.doc != "" {
+			fmt.Fprintf(&b, "%s  %s\n\n", indent, sec.doc)
+		}
+
+		table := explainTable(sec, withValues)
+		table.MaxWidths = []int{32, 40, 60, 40}
+		if withValues {
+			table.MaxWidths = []int{32, 40, 40, 60, 40}
+		}
+		if err := table.WriteASCII(&b, indent); err != nil {
+			return err
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// writeExplainMarkdown renders sections as Markdown tables.
+func writeExplainMarkdown(w io.Writer, sections []*explainSection) error {
+	var b strings.Builder
+	b.WriteString("## Provenance\n\n")
+
+	for _, sec := range sections {
+		if len(sec.records) == 0 {
+			continue
+		}
+
+		if sec.path != "" {
+			fmt.Fprintf(&b, "### %s\n\n", sec.path)
+		}
+		if sec.doc != "" {
+			fmt.Fprintf(&b, "%s\n\n", sec.doc)
+		}
+
+		if err := explainTable(sec, false).WriteMarkdown(&b); err != nil {
+			return err
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// explainTable builds the table of a section's fields, with a Value column
+// if withValues is set. The Description column is included only when a
+// field has a doc tag.
+func explainTable(sec *explainSection, withValues bool) docfmt.Table {
+	withDoc := false
+	for _, rec := range sec.records {
+		withDoc = withDoc || rec.Doc != ""
+	}
+
+	table := docfmt.Table{Headers: []string{"Field", "Source", "Also consulted"}}
+	if withValues {
+		table.Headers = []string{"Field", "Value", "Source", "Also consulted"}
+	}
+	if withDoc {
+		table.Headers = append(table.Headers, "Description")
+	}
+
+	for _, rec := range sec.records {
+		_, name := splitFieldPath(rec.Path)
+		consulted := strings.Join(rec.Consulted, ", ")
+		if consulted == "" {
+			consulted = "-"
+		}
+
+		row := []string{name, rec.Source, consulted}
+		if withValues {
+			row = []string{name, rec.Value, rec.Source, consulted}
+		}
+		if withDoc {
+			row = append(row, rec.Doc)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+// splitFieldPath splits a dotted field path into its parent and last field.
+func splitFieldPath(path string) (parent, name string) {
+	i := strings.LastIndexByte(path, '.')
+	if i < 0 {
+		return "", path
+	}
+
+	return path[:i], path[i+1:]
+}
+
+// trimIndex removes a trailing slice or map index, so the fields of
+// "Servers[0]" are documented by the doc tag of "Servers".
+func trimIndex(path string) string {
+	if strings.HasSuffix(path, "]") {
+		if i := strings.LastIndexByte(path, '['); i >= 0 {
+			return path[:i]
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,60 @@
+package fuda
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/spf13/pflag"
+)
+
+// flagSetLookup returns a lookup of the flags explicitly set in fs.
+func flagSetLookup(fs *flag.FlagSet) tags.FlagLookup {
+	return func(name string) (string, bool) {
+		set := false
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == name {
+				set = true
+			}
+		})
+		if !set {
+			return "", false
+		}
+
+		return fs.Lookup(name).Value.String(), true
+	}
+}
+
+// pflagSetLookup returns a lookup of the flags explicitly set in fs. List
+// flags are joined with commas, the format fuda uses for slices.
+func pflagSetLookup(fs *pflag.FlagSet) tags.FlagLookup {
+	return func(name string) (string, bool) {
+		f := fs.Lookup(name)
+		if f == nil || !f.Changed {
+			return "", false
+		}
+
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			return strings.Join(sv.GetSlice(), ","), true
+		}
+
+		return f.Value.String(), true
+	}
+}
+
+// chainFlagLookups returns a lookup that tries each lookup in order.
+func chainFlagLookups(lookups []tags.FlagLookup) tags.FlagLookup {
+	if len(lookups) == 0 {
+		return nil
+	}
+
+	return func(name string) (string, bool) {
+		for _, lookup := range lookups {
+			if v, ok := lookup(name); ok {
+				return v, true
+			}
+		}
+
+		return "", false
+	}
+}
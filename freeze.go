@@ -0,0 +1,142 @@
+package fuda
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Frozen holds a loaded config that is shared read-only, and detects code
+// that modifies it anyway. Go cannot make a struct immutable, so Freeze keeps
+// a private snapshot and Check compares the config against it.
+type Frozen[T any] struct {
+	cfg      *T
+	snapshot T
+}
+
+// Freeze records the current state of cfg, which must not be nil, and
+// returns a handle for sharing it. Code that needs a modified config should
+// take a Copy instead of changing the shared one; call Check, for example in
+// tests or periodically in debug builds, to catch code that does not.
+//
+// Example:
+//
+//	var cfg Config
+//	if err := fuda.LoadFile("config.yaml", &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//	frozen := fuda.Freeze(&cfg)
+//	run(frozen.Get())
+//	if err := frozen.Check(); err != nil {
+//	    log.Printf("config modified at runtime: %v", err)
+//	}
+func Freeze[T any](cfg *T) *Frozen[T] {
+	f := &Frozen[T]{cfg: cfg}
+	deepCopyValue(reflect.ValueOf(&f.snapshot).Elem(), reflect.ValueOf(cfg).Elem(), make(map[uintptr]reflect.Value))
+
+	return f
+}
+
+// Get returns the shared config. It must not be modified.
+func (f *Frozen[T]) Get() *T {
+	return f.cfg
+}
+
+// Copy returns a deep copy of the config as it was when frozen, which the
+// caller may modify freely. Unexported fields are copied shallowly.
+func (f *Frozen[T]) Copy() T {
+	var c T
+	deepCopyValue(reflect.ValueOf(&c).Elem(), reflect.ValueOf(&f.snapshot).Elem(), make(map[uintptr]reflect.Value))
+
+	return c
+}
+
+// Check returns a *MutationError listing the values changed since Freeze, or
+// nil if the config is unchanged. Only exported fields are compared, as by
+// Diff, and sensitive values are redacted.
+func (f *Frozen[T]) Check() error {
+	changes := Diff(&f.snapshot, f.cfg)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return &MutationError{Changes: changes}
+}
+
+// MutationError reports a frozen config that was modified after Freeze.
+type MutationError struct {
+	Changes []FieldChange
+}
+
+// Error returns the changed values, e.g.
+// "config modified after Freeze: database.port: 5432 -> 6543".
+func (e *MutationError) Error() string {
+	parts := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		parts[i] = c.String()
+	}
+
+	return fmt.Sprintf("config modified after Freeze: %s", strings.Join(parts, "; "))
+}
+
+// deepCopyValue copies src into dst, which must be settable, duplicating
+// pointers, slices, and maps. copies maps each pointer already copied to its
+// copy, so shared and cyclic pointers keep their shape.
+func deepCopyValue(dst, src reflect.Value, copies map[uintptr]reflect.Value) {
+	switch src.Kind() { //nolint:exhaustive // other kinds are copied by value
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if c, ok := copies[src.Pointer()]; ok {
+			dst.Set(c)
+
+			return
+		}
+		c := reflect.New(src.Type().Elem())
+		copies[src.Pointer()] = c
+		deepCopyValue(c.Elem(), src.Elem(), copies)
+		dst.Set(c)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		c := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := range src.Len() {
+			deepCopyValue(c.Index(i), src.Index(i), copies)
+		}
+		dst.Set(c)
+	case reflect.Array:
+		for i := range src.Len() {
+			deepCopyValue(dst.Index(i), src.Index(i), copies)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		c := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(src.Type().Elem()).Elem()
+			deepCopyValue(elem, iter.Value(), copies)
+			c.SetMapIndex(iter.Key(), elem)
+		}
+		dst.Set(c)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		deepCopyValue(elem, src.Elem(), copies)
+		dst.Set(elem)
+	case reflect.Struct:
+		dst.Set(src)
+		for i := range src.NumField() {
+			if dst.Field(i).CanSet() {
+				deepCopyValue(dst.Field(i), src.Field(i), copies)
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
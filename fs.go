@@ -1,6 +1,14 @@
 package fuda
 
-import "github.com/spf13/afero"
+import (
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
 
 // DefaultFs is the default filesystem used by fuda for all file operations.
 // It defaults to the OS filesystem but can be overridden for testing.
@@ -16,6 +24,20 @@ import "github.com/spf13/afero"
 //	}
 var DefaultFs afero.Fs = afero.NewOsFs()
 
+// ioFs normalizes OS-style paths into valid io/fs paths before delegating.
+type ioFs struct {
+	afero.FromIOFS
+}
+
+// NewIOFS adapts an io/fs filesystem (such as embed.FS or fstest.MapFS) to
+// afero.Fs for use with WithFilesystem, SetDefaultFs, or the watcher.
+//
+// The returned filesystem is read-only. Paths are cleaned and interpreted
+// relative to the root of fsys, so a leading "/" or "./" is ignored.
+func NewIOFS(fsys iofs.FS) afero.Fs {
+	return ioFs{FromIOFS: afero.FromIOFS{FS: fsys}}
+}
+
 // SetDefaultFs sets the global default filesystem.
 // This is useful for testing scenarios where all file operations
 // should use a memory filesystem or other custom implementation.
@@ -34,3 +56,33 @@ func SetDefaultFs(fs afero.Fs) {
 func ResetDefaultFs() {
 	DefaultFs = afero.NewOsFs()
 }
+
+// ioFsPath converts an OS-style path into a valid io/fs path.
+func ioFsPath(name string) string {
+	p := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if p == "" {
+		return "."
+	}
+
+	return p
+}
+
+// Open opens the named file.
+func (f ioFs) Open(name string) (afero.File, error) {
+	return f.FromIOFS.Open(ioFsPath(name))
+}
+
+// OpenFile opens the named file; flag and perm are ignored (read-only).
+func (f ioFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	return f.FromIOFS.OpenFile(ioFsPath(name), flag, perm)
+}
+
+// Stat returns file info for the named file.
+func (f ioFs) Stat(name string) (os.FileInfo, error) {
+	return f.FromIOFS.Stat(ioFsPath(name))
+}
+
+// Name returns the name of this filesystem.
+func (f ioFs) Name() string {
+	return "iofs"
+}
@@ -31,15 +31,26 @@ package fuda
 
 import (
 	"bytes"
+	"context"
+	"flag"
+	"fmt"
 	"io"
+	iofs "io/fs"
+	"log/slog"
+	"maps"
+	"net/url"
 	"reflect"
-	"text/template"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"filippo.io/age"
 	"github.com/arloliu/fuda/internal/loader"
 	"github.com/arloliu/fuda/internal/resolver"
-	"github.com/go-playground/validator/v10"
+	"github.com/arloliu/fuda/internal/tags"
 	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 	"sigs.k8s.io/yaml/kyaml"
 )
@@ -47,24 +58,60 @@ import (
 // Loader is responsible for loading configuration from various sources.
 type Loader struct {
 	loaderConfig
+	mu         sync.RWMutex // guards source and layers, which Reload replaces
 	source     []byte
+	layers     []loader.Layer // set instead of source by FromFiles
 	sourceName string
+	sourcePath string                                    // file read by FromFile, for includes
+	fetch      func(ctx context.Context) ([]byte, error) // set instead of source by FromURL
+	open       func() (io.ReadCloser, error)             // set instead of source by FromFileStream
 }
 
 // loaderConfig holds the configuration for the loader.
 type loaderConfig struct {
 	fs           afero.Fs // Filesystem for file operations
 	envPrefix    string
-	validator    *validator.Validate
+	validator    *loader.Validator
+	skipValidate bool // Skip Validate methods; set by SetDefaults
+	validateMode ValidationMode
 	refResolver  RefResolver
+	resolvers    map[string]RefResolver // Per-loader scheme resolvers
+	middleware   []ResolverMiddleware   // Wraps the ref resolver, first outermost
+	refRateLimit float64                // Max network ref resolutions per second (0 = unlimited)
+	startSpan    spanFunc               // Starts tracing spans (nil disables tracing)
+	metrics      Metrics                // Receives ref and reload measurements
+	logger       *slog.Logger           // Receives debug logs (nil disables logging)
 	timeout      time.Duration
+	refWorkers   int           // Max concurrent ref prefetches (<= 1 means sequential)
+	refAttempts  int           // Total attempts for failed refs (<= 1 disables retries)
+	refBackoff   time.Duration // Initial delay between ref attempts
+	secretsDir   string        // Directory of secretName files ("" = /run/secrets)
 	tmplConfig   *templateConfig
+	refHTTP      *refHTTPConfig // Client of the http and https resolvers
 	tmplData     any
+	tagFuncs     map[string]any // Extra functions for ref and dsn tag templates
 	dotenvConfig *dotenvConfig  // dotenv file loading configuration
 	overrides    map[string]any // Programmatic value overrides
+	defaults     reflect.Value  // Snapshot of the WithDefaults instance
 	// Preprocessing toggles (nil means default true)
 	enableSizePreprocess     *bool
 	enableDurationPreprocess *bool
+	trace                    io.Writer                 // Per-field resolution log (nil disables tracing)
+	decrypters               map[string]tags.Decrypter // kms tag providers by name
+	ageIdentities            []age.Identity            // Identities for "enc:age:" values
+	strictKeys               bool                      // Reject unknown source keys
+	explicitZeros            bool                      // Keep zero values set by the source
+	autoEnv                  bool                      // Derive env names from field paths
+	expandEnv                bool                      // Expand ${VAR} placeholders in the source
+	includes                 bool                      // Splice `!include` files into the source
+	profile                  string                    // Profile merged over the default profile
+	flags                    []tags.FlagLookup         // Command-line flag sets, first wins
+	precedence               []Source                  // Source order, lowest first (nil = default)
+	onConflicts              func([]Conflict)          // Receives shadowed keys on each load
+	tagProcessors            map[string]TagProcessor   // Custom tags by name
+	decoders                 map[string]Decoder        // Field decoders by name
+	hooks                    map[HookPoint][]Hook      // Load hooks by stage
+	decodeHooks              []DecodeHook              // String conversions ahead of the built-in ones
 }
 
 // dotenvConfig holds dotenv file loading configuration.
@@ -86,58 +133,11 @@ func DotEnvOverride() DotEnvOption {
 	}
 }
 
-// templateConfig holds template parsing configuration.
-type templateConfig struct {
-	leftDelim  string
-	rightDelim string
-	missingKey string
-	funcMap    template.FuncMap
-}
-
-// TemplateOption configures template parsing behavior.
-type TemplateOption func(*templateConfig)
-
-// WithDelimiters sets custom delimiters for template parsing.
-// Use this when your configuration contains literal "{{" sequences that
-// should not be interpreted as template syntax.
-//
-// Example:
-//
-//	loader, _ := fuda.New().
-//	    FromFile("config.yaml").
-//	    WithTemplate(data, fuda.WithDelimiters("<{", "}>")).
-//	    Build()
-func WithDelimiters(left, right string) TemplateOption {
-	return func(c *templateConfig) {
-		c.leftDelim = left
-		c.rightDelim = right
-	}
-}
-
-// WithMissingKey controls behavior when a map is indexed with a key not in the map.
-// Valid values:
-//   - "invalid" (default): no error, outputs "<no value>"
-//   - "zero": outputs the zero value for the type
-//   - "error": template execution stops with an error
-func WithMissingKey(behavior string) TemplateOption {
-	return func(c *templateConfig) {
-		c.missingKey = behavior
-	}
-}
-
-// WithFuncs adds custom template functions.
-// These are merged with the template's built-in functions.
-func WithFuncs(funcMap template.FuncMap) TemplateOption {
-	return func(c *templateConfig) {
-		c.funcMap = funcMap
-	}
-}
-
 // New creates a new configuration Builder.
 func New() *Builder {
 	return &Builder{
 		config: loaderConfig{
-			validator: validator.New(),
+			validator: newValidator(),
 		},
 	}
 }
@@ -146,7 +146,12 @@ func New() *Builder {
 type Builder struct {
 	config loaderConfig
 	source []byte
+	layers []loader.Layer
 	name   string
+	path   string
+	fetch  func(ctx context.Context) ([]byte, error)
+	open   func() (io.ReadCloser, error)
+	object string // URI set by FromObjectStore, resolved in Build
 	err    error
 }
 
@@ -170,7 +175,48 @@ func (b *Builder) FromFile(path string) *Builder {
 	}
 
 	b.source = data
+	b.layers = nil
 	b.name = path
+	b.path = path
+	b.fetch = nil
+	b.open = nil
+	b.object = ""
+
+	return b
+}
+
+// FromObjectStore reads the configuration document from an object store,
+// such as "s3://bucket/config.yaml" or "gs://bucket/app/config.yaml", at
+// every load. The URI is resolved like a ref, by the resolver for its scheme
+// registered with RegisterResolver or WithResolver (or by WithRefResolver),
+// so any store with a resolver can hold the whole config file. Resolver
+// middleware and WithTimeout apply to the fetch.
+//
+// Example:
+//
+//	fuda.RegisterResolver("s3", myS3Resolver)
+//	loader, err := fuda.New().
+//	    FromObjectStore("s3://acme-config/billing/prod.yaml").
+//	    Build()
+func (b *Builder) FromObjectStore(uri string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		b.err = &FieldError{Message: fmt.Sprintf("FromObjectStore requires a URI with a scheme, e.g. s3://bucket/key, got %q", uri)}
+
+		return b
+	}
+
+	b.source = nil
+	b.layers = nil
+	b.name = uri
+	b.path = ""
+	b.fetch = nil
+	b.open = nil
+	b.object = uri
 
 	return b
 }
@@ -190,7 +236,12 @@ func (b *Builder) FromReader(r io.Reader) *Builder {
 	}
 
 	b.source = data
+	b.layers = nil
 	b.name = "reader"
+	b.path = ""
+	b.fetch = nil
+	b.open = nil
+	b.object = ""
 
 	return b
 }
@@ -199,7 +250,101 @@ func (b *Builder) FromReader(r io.Reader) *Builder {
 // The content format (YAML or JSON) is auto-detected.
 func (b *Builder) FromBytes(data []byte) *Builder {
 	b.source = data
+	b.layers = nil
 	b.name = "bytes"
+	b.path = ""
+	b.fetch = nil
+	b.open = nil
+	b.object = ""
+
+	return b
+}
+
+// FromFileStream is like FromFile for large files, such as multi-megabyte
+// generated configs. The file is not read by Build, only checked to exist.
+// Each load opens it and decodes it as a stream, so the raw file is never
+// held in memory whole and nothing is retained between loads. Templates,
+// env expansion, includes, overrides, and conflict reports work on the raw
+// document, so with any of them the file is read into memory during the
+// load and released afterwards.
+//
+// Example:
+//
+//	loader, err := fuda.New().
+//	    FromFileStream("/var/lib/app/routes.generated.yaml").
+//	    Build()
+func (b *Builder) FromFileStream(path string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	fs := b.config.fs
+	if fs == nil {
+		fs = DefaultFs
+	}
+
+	if _, err := fs.Stat(path); err != nil {
+		b.err = err
+
+		return b
+	}
+
+	b.source = nil
+	b.layers = nil
+	b.name = path
+	b.path = path
+	b.fetch = nil
+	b.open = func() (io.ReadCloser, error) { return fs.Open(path) }
+	b.object = ""
+
+	return b
+}
+
+// FromFiles reads configuration from several files and deep-merges them in
+// order, for layered setups such as a base file with per-environment and
+// secret overlays. Mappings are merged key by key; any other value in a later
+// file, including a list, replaces the earlier one. Each file may be YAML or
+// JSON, and templates (see WithTemplate) are processed per file before the
+// merge.
+//
+// Example:
+//
+//	loader, err := fuda.New().
+//	    FromFiles("base.yaml", "prod.yaml", "secrets.yaml").
+//	    Build()
+func (b *Builder) FromFiles(paths ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(paths) == 0 {
+		b.err = &FieldError{Message: "FromFiles requires at least one path"}
+
+		return b
+	}
+
+	fs := b.config.fs
+	if fs == nil {
+		fs = DefaultFs
+	}
+
+	layers := make([]loader.Layer, 0, len(paths))
+	for _, path := range paths {
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			b.err = err
+
+			return b
+		}
+		layers = append(layers, loader.Layer{Name: path, Data: data})
+	}
+
+	b.source = nil
+	b.layers = layers
+	b.name = strings.Join(paths, ", ")
+	b.path = ""
+	b.fetch = nil
+	b.open = nil
+	b.object = ""
 
 	return b
 }
@@ -212,22 +357,178 @@ func (b *Builder) WithEnvPrefix(prefix string) *Builder {
 	return b
 }
 
-// WithValidator sets a custom validator instance.
-// If not set, a default validator is used.
-func (b *Builder) WithValidator(v *validator.Validate) *Builder {
-	b.config.validator = v
+// WithAutoEnv lets environment variables override fields that have no env
+// tag, deriving each name from the field path: the Go field names are split
+// into upper-case words and joined with underscores, after the env prefix.
+//
+//	Database.Primary.Host  →  DATABASE_PRIMARY_HOST
+//	Server.MaxConns        →  SERVER_MAX_CONNS
+//	HTTPServer.Port        →  HTTP_SERVER_PORT
+//
+// An env tag still takes precedence, and `env:"-"` excludes a field. Nested
+// structs are mapped field by field. Elements of slices of structs are mapped
+// by index, and an index past the end of the slice appends elements:
+//
+//	Servers[0].Host        →  SERVERS_0_HOST
+//
+// Map values, and fields under nil pointers, are not mapped.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithEnvPrefix("APP_").   // Database.Host reads $APP_DATABASE_HOST
+//	    WithAutoEnv().
+//	    Build()
+func (b *Builder) WithAutoEnv() *Builder {
+	b.config.autoEnv = true
+
+	return b
+}
+
+// WithFlagSet binds fields with a `flag:"name"` tag to the flags of fs. A
+// flag set on the command line overrides the config file and env vars; a
+// flag left at its default is ignored, so the field keeps its file, env, or
+// default tag value. Flags are read at load time, so fs may be parsed after
+// Build. Repeated calls add more flag sets, the first taking precedence.
+//
+// Example:
+//
+//	type Config struct {
+//	    Port int `yaml:"port" env:"PORT" flag:"port" default:"8080"`
+//	}
+//
+//	fs := flag.NewFlagSet("app", flag.ExitOnError)
+//	fs.Int("port", 8080, "listen port")
+//	_ = fs.Parse(os.Args[1:])
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithFlagSet(fs).
+//	    Build()
+func (b *Builder) WithFlagSet(fs *flag.FlagSet) *Builder {
+	b.config.flags = append(b.config.flags, flagSetLookup(fs))
+
+	return b
+}
+
+// WithPFlagSet is like WithFlagSet for a github.com/spf13/pflag flag set,
+// as used by cobra commands. Slice flags such as StringSlice bind to slice
+// fields.
+//
+// Example:
+//
+//	cmd.Flags().Int("port", 8080, "listen port")
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithPFlagSet(cmd.Flags()).
+//	    Build()
+func (b *Builder) WithPFlagSet(fs *pflag.FlagSet) *Builder {
+	b.config.flags = append(b.config.flags, pflagSetLookup(fs))
 
 	return b
 }
 
 // WithRefResolver sets a custom reference resolver for ref/refFrom tags.
-// The default resolver supports file://, http://, and https:// schemes.
+// The default resolver supports file://, http://, https://, and env:// schemes,
+// plus any scheme added via RegisterResolver or WithResolver. A custom
+// resolver replaces the default one entirely.
 func (b *Builder) WithRefResolver(r RefResolver) *Builder {
 	b.config.refResolver = r
 
 	return b
 }
 
+// WithResolver registers r as the resolver for URIs with the given scheme for
+// this loader only. It takes precedence over resolvers registered globally via
+// RegisterResolver and over the built-in resolver for the same scheme.
+// Ignored when WithRefResolver is used.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithResolver("vault", vaultResolver).
+//	    WithResolver("etcd", etcdResolver).
+//	    Build()
+func (b *Builder) WithResolver(scheme string, r RefResolver) *Builder {
+	if b.config.resolvers == nil {
+		b.config.resolvers = make(map[string]RefResolver)
+	}
+	b.config.resolvers[scheme] = r
+
+	return b
+}
+
+// WithResolverMiddleware wraps the loader's ref resolver with mw, adding
+// cross-cutting behavior such as logging, caching, metrics, or rate limiting
+// to every ref, refFrom, and ${ref:...} resolution. Middleware applies to the
+// custom resolver from WithRefResolver, or to the default scheme-dispatching
+// resolver otherwise. Multiple calls append to the chain; the first
+// middleware is the outermost. With WithRefRetry, each attempt passes
+// through the chain.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithResolver("vault", vaultResolver).
+//	    WithResolverMiddleware(resolver.Logging(slog.Default())).
+//	    Build()
+func (b *Builder) WithResolverMiddleware(mw ...ResolverMiddleware) *Builder {
+	b.config.middleware = append(b.config.middleware, mw...)
+
+	return b
+}
+
+// WithMetrics reports the latency and outcome of every ref resolution, and
+// the outcome of every ReloadInto, to m, so reload health and slow secret
+// stores can be alerted on. The fuda/prometheus module exports them as
+// Prometheus metrics. Resolution latency excludes waiting for
+// WithRefRateLimit.
+//
+// Example:
+//
+//	m, _ := prometheus.New(prom.DefaultRegisterer)
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithMetrics(m).
+//	    Build()
+func (b *Builder) WithMetrics(m Metrics) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if m == nil {
+		b.err = &FieldError{Message: "nil metrics"}
+
+		return b
+	}
+	b.config.metrics = m
+
+	return b
+}
+
+// WithRefRateLimit limits network ref resolutions (everything except
+// file://, env:// and age://) to rps per second for this loader, so a config
+// with many refs or a burst of reloads stays within the rate limits of
+// backends such as Vault. Resolutions beyond the limit wait for their turn, bounded by the
+// load's context and WithTimeout. Retry attempts count against the limit.
+// A value <= 0 disables limiting (the default).
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithResolver("vault", vaultResolver).
+//	    WithRefRateLimit(20). // at most 20 Vault reads per second
+//	    Build()
+func (b *Builder) WithRefRateLimit(rps float64) *Builder {
+	b.config.refRateLimit = rps
+
+	return b
+}
+
 // WithFilesystem sets a custom filesystem for file operations.
 // This is useful for testing with in-memory filesystems.
 //
@@ -245,14 +546,106 @@ func (b *Builder) WithFilesystem(fs afero.Fs) *Builder {
 	return b
 }
 
+// WithIOFS sets an io/fs filesystem, such as an embed.FS, for file operations.
+// FromFile, dotenv files, and file:// refs all resolve against fsys, so
+// tests and embedded deployments don't depend on the real filesystem layout.
+//
+// Paths are interpreted relative to the root of fsys; a leading "/" or "./"
+// is ignored, so a file:///secrets/db ref reads "secrets/db" from fsys.
+// Call WithIOFS before FromFile.
+//
+// Example:
+//
+//	//go:embed config
+//	var configFS embed.FS
+//
+//	loader, _ := fuda.New().
+//	    WithIOFS(configFS).
+//	    FromFile("config/app.yaml").
+//	    WithDotEnv("config/.env").
+//	    Build()
+func (b *Builder) WithIOFS(fsys iofs.FS) *Builder {
+	b.config.fs = NewIOFS(fsys)
+
+	return b
+}
+
 // WithTimeout sets a timeout for reference resolution (ref/refFrom tags).
 // Default is 0 (no timeout). Set explicitly for network refs.
+// To cancel a load from your own context, use Loader.LoadContext.
 func (b *Builder) WithTimeout(timeout time.Duration) *Builder {
 	b.config.timeout = timeout
 
 	return b
 }
 
+// WithSecretsDir sets the directory of the files read by secretName tags.
+// The default is /run/secrets, where Docker and Swarm mount secrets; set it
+// to the mountPath of a Kubernetes secret volume. Files are read through the
+// loader's filesystem.
+//
+// Example:
+//
+//	type Config struct {
+//	    Password string `secretName:"db_password"`
+//	}
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithSecretsDir("/etc/app/secrets"). // reads /etc/app/secrets/db_password
+//	    Build()
+func (b *Builder) WithSecretsDir(dir string) *Builder {
+	b.config.secretsDir = dir
+
+	return b
+}
+
+// WithRefConcurrency resolves independent refs concurrently with up to n
+// workers before fields are processed, which shortens startup when a config
+// has many remote secrets (e.g., dozens of Vault lookups).
+//
+// Only static `ref` tags on fields that are still unset after the config file
+// and env are prefetched. Templated refs (${...}) and refFrom depend on other
+// fields and are still resolved in field order. The resolver must be safe for
+// concurrent use. Default is 0 (sequential).
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithRefResolver(vaultResolver).
+//	    WithRefConcurrency(8).
+//	    Build()
+func (b *Builder) WithRefConcurrency(n int) *Builder {
+	b.config.refWorkers = n
+
+	return b
+}
+
+// WithRefRetry retries transient ref resolution failures (e.g., HTTP or Vault
+// errors) instead of failing the whole Load. attempts is the total number of
+// tries per ref; backoff is the initial delay, doubled after each retry.
+// Not-found results and context cancellation are never retried.
+//
+// Individual fields can override the number of attempts with the refRetry tag:
+//
+//	APIKey string `ref:"vault:///secret/data/app#api_key" refRetry:"5"`
+//
+// Default is no retries.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithRefRetry(3, 200*time.Millisecond). // 200ms, then 400ms
+//	    Build()
+func (b *Builder) WithRefRetry(attempts int, backoff time.Duration) *Builder {
+	b.config.refAttempts = attempts
+	b.config.refBackoff = backoff
+
+	return b
+}
+
 // WithOverrides sets programmatic overrides that take precedence over config file values.
 // These are applied after template processing but before struct unmarshaling.
 // Keys use dot notation for nested values: "database.host" overrides database.host.
@@ -272,6 +665,99 @@ func (b *Builder) WithOverrides(overrides map[string]any) *Builder {
 	return b
 }
 
+// WithDefaults seeds the target of every load with a copy of defaults, a
+// struct or pointer to a struct of the target's type, before the config
+// file, env vars, and other tags are processed. Use it for defaults too
+// complex for `default` tags, such as slices and maps of structs:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithDefaults(Config{
+//	        Servers: []Server{{Host: "a.internal"}, {Host: "b.internal"}},
+//	        Limits:  map[string]Limit{"api": {RPS: 100}},
+//	    }).
+//	    Build()
+//
+// Seeded values rank as defaults: the file, overrides, env vars, flags,
+// and refs replace them, and they replace `default` tags. A slice set by
+// the file replaces the default slice, while a map set by the file keeps
+// the default entries it does not set. defaults is copied deeply when
+// WithDefaults is called, except for unexported fields.
+func (b *Builder) WithDefaults(defaults any) *Builder {
+	v, isNil := derefValue(reflect.ValueOf(defaults))
+	if isNil || v.Kind() != reflect.Struct {
+		b.err = &FieldError{Message: "WithDefaults requires a struct or pointer to struct"}
+
+		return b
+	}
+
+	snapshot := reflect.New(v.Type()).Elem()
+	deepCopyValue(snapshot, v, make(map[uintptr]reflect.Value))
+	b.config.defaults = snapshot
+
+	return b
+}
+
+// WithPrecedence replaces the order in which sources win, listed from lowest
+// to highest priority. Every source must be listed exactly once. For each
+// field, the highest-ranked source that supplies a value is used:
+//
+//   - SourceFile and SourceOverride supply a value when they set the field
+//     to a non-zero value.
+//   - SourceEnv and SourceFlag supply a value when the variable or flag is
+//     set, even to a zero value.
+//   - SourceRef and SourceDefault supply a value when the tag yields one;
+//     refs ranked below another source that supplies a value are not
+//     resolved.
+//
+// The default order is DefaultPrecedence:
+//
+//	SourceDefault, SourceRef, SourceFile, SourceOverride, SourceEnv, SourceFlag
+//
+// Example (a local file beats the environment during development):
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.dev.yaml").
+//	    WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceEnv,
+//	        fuda.SourceFile, fuda.SourceOverride, fuda.SourceFlag).
+//	    Build()
+func (b *Builder) WithPrecedence(sources ...Source) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if err := loader.ValidatePrecedence(sources); err != nil {
+		b.err = fmt.Errorf("invalid precedence: %w", err)
+
+		return b
+	}
+	b.config.precedence = slices.Clone(sources)
+
+	return b
+}
+
+// WithConflictReport calls fn on every load with the keys that more than one
+// source document sets to different values: a later file of FromFiles over
+// an earlier one, or an override over the file. Each Conflict names the
+// winning source and value and the values it shadowed, so unexpected
+// shadowing in layered setups can be audited. fn receives an empty slice
+// when nothing is shadowed, and is called before fields are decoded.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFiles("base.yaml", "prod.yaml").
+//	    WithConflictReport(func(conflicts []fuda.Conflict) {
+//	        for _, c := range conflicts {
+//	            log.Printf("config: %s", c)
+//	        }
+//	    }).
+//	    Build()
+func (b *Builder) WithConflictReport(fn func([]Conflict)) *Builder {
+	b.config.onConflicts = fn
+
+	return b
+}
+
 // WithSizePreprocess enables or disables size-string preprocessing.
 // Default is enabled for backward compatibility.
 func (b *Builder) WithSizePreprocess(enabled bool) *Builder {
@@ -288,6 +774,209 @@ func (b *Builder) WithDurationPreprocess(enabled bool) *Builder {
 	return b
 }
 
+// WithTrace writes one line per field to w describing every source consulted
+// and the decision made, which is useful for ad-hoc debugging of precedence:
+//
+//	Port: yaml=9090 (used), env APP_PORT unset, default=8080
+//	Host: yaml unset, env APP_HOST=db.local (used), default=localhost
+//
+// Resolved ref contents are never printed; only the ref URI is shown, and the
+// file and env values of secret fields (see Redact) are masked.
+// Pass a *TraceRecorder to keep the report of the latest load for DumpOnSignal.
+// Tracing is disabled by default.
+func (b *Builder) WithTrace(w io.Writer) *Builder {
+	b.config.trace = w
+
+	return b
+}
+
+// WithLogger logs the decisions of every load to logger at debug level:
+// the config document and dotenv files read, refs that were not found and
+// fell back to a default, and errors that do not fail the load, such as
+// validation errors under ValidationWarn. Values are never logged. A nil
+// logger disables logging, the default.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithLogger(logger).
+//	    Build()
+func (b *Builder) WithLogger(logger *slog.Logger) *Builder {
+	b.config.logger = logger
+
+	return b
+}
+
+// WithKMS registers d to decrypt fields tagged `kms:"<provider>"`. Such
+// fields hold base64-encoded KMS ciphertext in the config file (or env/ref),
+// which is decrypted during Load, so encrypted values can live directly in
+// YAML. Defaults are treated as plaintext and never decrypted.
+//
+// Example:
+//
+//	type Config struct {
+//	    DBPassword string `yaml:"db_password" kms:"aws"`
+//	}
+//
+//	awsKMS := kms.NewAWS(kmsClient) // github.com/arloliu/fuda/kms
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithKMS("aws", awsKMS).
+//	    Build()
+//
+// KMS-decrypted fields are treated as secret by Redact, DumpRedacted, and
+// WithTrace.
+//
+// The decrypters registered as "aws" and "gcp" also decrypt the data key of
+// SOPS files encrypted with `kms` and `gcp_kms` keys (see WithAgeIdentity).
+func (b *Builder) WithKMS(provider string, d KMSDecrypter) *Builder {
+	if b.config.decrypters == nil {
+		b.config.decrypters = make(map[string]tags.Decrypter)
+	}
+	b.config.decrypters[provider] = d
+
+	return b
+}
+
+// WithAgeIdentity adds an age identity used to decrypt "enc:age:" values in
+// the config source. identity is the content of an age key file, such as the
+// output of age-keygen; it may hold several keys and "#" comments.
+//
+// Any string value may be encrypted individually, without encrypting the
+// whole file:
+//
+//	db_password: enc:age:YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBh...
+//
+// where the part after the prefix is the base64-encoded binary age output:
+//
+//	printf 's3cret' | age -r age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p | base64 -w0
+//
+// Values are decrypted before decoding, so numbers and durations still parse
+// into typed fields. Loading fails if an encrypted value is found and no
+// configured identity can decrypt it. Decrypted fields are not masked by
+// Redact or WithTrace unless tagged `secret:"true"`.
+//
+// The identities also decrypt whole files encrypted with SOPS for an age
+// recipient. A source with SOPS metadata is detected and decrypted before
+// any other processing, and its MAC is verified.
+//
+// Files referenced by ref and refFrom tags with the age:// scheme are
+// decrypted with the same identities:
+//
+//	Password string `ref:"age:///run/secrets/db_password.age"`
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithAgeIdentity(os.Getenv("AGE_SECRET_KEY")).
+//	    Build()
+func (b *Builder) WithAgeIdentity(identity string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	identities, err := age.ParseIdentities(strings.NewReader(identity))
+	if err != nil {
+		b.err = fmt.Errorf("invalid age identity: %w", err)
+
+		return b
+	}
+	b.config.ageIdentities = append(b.config.ageIdentities, identities...)
+
+	return b
+}
+
+// WithAgeIdentityFile is like WithAgeIdentity, but reads the identities from
+// the age key file at path, such as the key.txt written by age-keygen. The
+// file is read immediately from the configured filesystem; an error is
+// returned by Build.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithAgeIdentityFile("/etc/app/age-key.txt").
+//	    Build()
+func (b *Builder) WithAgeIdentityFile(path string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	fs := b.config.fs
+	if fs == nil {
+		fs = DefaultFs
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		b.err = fmt.Errorf("failed to read age identity file %s: %w", path, err)
+
+		return b
+	}
+
+	identities, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		b.err = fmt.Errorf("invalid age identity file %s: %w", path, err)
+
+		return b
+	}
+	b.config.ageIdentities = append(b.config.ageIdentities, identities...)
+
+	return b
+}
+
+// WithStrictKeys makes Load fail when the config source contains keys that
+// do not match any field, catching typos such as "databse:" that would
+// otherwise be silently ignored. The returned *LoadError lists every unknown
+// key with its path and line, and suggests the nearest field name:
+//
+//	failed to load configuration from config.yaml:
+//	  field 'databse': unknown key at line 3, did you mean "database"?
+//
+// Keys are matched the way YAML decoding does (yaml tag name, or the
+// lowercased field name). Fields with inline maps accept any key, and types
+// with custom unmarshaling are not inspected.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithStrictKeys().
+//	    Build()
+func (b *Builder) WithStrictKeys() *Builder {
+	b.config.strictKeys = true
+
+	return b
+}
+
+// WithExplicitZeros keeps zero values that the config source sets
+// explicitly, so `port: 0` or `enabled: false` in the file is not replaced
+// by a default, ref, or env fallback that ranks below the file:
+//
+//	type Config struct {
+//	    Port    int  `yaml:"port" default:"8080"`
+//	    Enabled bool `yaml:"enabled" default:"true"`
+//	}
+//
+//	loader, _ := fuda.New().
+//	    FromBytes([]byte("port: 0\nenabled: false")).
+//	    WithExplicitZeros().
+//	    Build()
+//	// cfg.Port == 0, cfg.Enabled == false
+//
+// Only keys present in the source count; absent keys and null values such
+// as `port: ~` still get their defaults. Without this option, a zero value
+// in the source counts as unset, so a placeholder such as `password: ""`
+// can be filled in by a ref.
+func (b *Builder) WithExplicitZeros() *Builder {
+	b.config.explicitZeros = true
+
+	return b
+}
+
 // Apply applies a configuration function to the builder.
 // This enables reusable configuration bundles:
 //
@@ -301,32 +990,96 @@ func (b *Builder) Apply(fn func(*Builder)) *Builder {
 	return b
 }
 
-// WithTemplate enables Go template processing on configuration content before YAML parsing.
-// The data parameter provides template context, and opts configure template behavior.
+// WithEnvExpansion expands ${VAR} placeholders in the configuration content
+// before YAML parsing, like Docker Compose, as a lighter alternative to
+// WithTemplate:
 //
-// Template processing occurs BEFORE YAML unmarshaling. If your configuration contains
-// literal "{{" or "}}" sequences that should not be interpreted as template delimiters,
-// use WithDelimiters to specify alternative delimiters.
+//	${VAR}          value of VAR, or empty if unset
+//	${VAR:-word}    word if VAR is unset or empty (${VAR-word}: only if unset)
+//	${VAR:+word}    word if VAR is set and not empty (${VAR+word}: if set)
+//	${VAR:?message} fail if VAR is unset or empty (${VAR?message}: only if unset)
+//	$$              a literal $
+//
+// Expansion is textual and runs after template processing and dotenv
+// loading; the env prefix does not apply. Quote placeholders whose values
+// may contain YAML syntax, such as `password: "${DB_PASSWORD}"`.
 //
 // Example:
 //
-//	type TemplateData struct {
-//	    Host string
-//	    Port int
-//	}
+//	// config.yaml:
+//	//   host: ${DB_HOST:-localhost}
+//	//   port: ${DB_PORT:-5432}
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithEnvExpansion().
+//	    Build()
+func (b *Builder) WithEnvExpansion() *Builder {
+	b.config.expandEnv = true
+
+	return b
+}
+
+// WithIncludes splices other files into the config: a value tagged
+// `!include <file>` is replaced by the document in that file, read from the
+// loader's filesystem (see WithFilesystem). A relative file is resolved
+// against the directory of the file that includes it, or the working
+// directory for FromReader and FromBytes. Included files may include others;
+// an include cycle fails the load.
+//
+// Includes are resolved at each load, after template processing and before
+// env expansion. Included files are not processed as templates. With
+// FromFiles, each file's includes are resolved before the merge.
+//
+// Example:
 //
+//	// config.yaml:
+//	//   database: !include database.yaml
+//	//   logging:
+//	//     <<: !include shared/logging.yaml
+//	//     level: debug
 //	loader, _ := fuda.New().
 //	    FromFile("config.yaml").
-//	    WithTemplate(TemplateData{Host: "localhost", Port: 8080}).
+//	    WithIncludes().
 //	    Build()
-func (b *Builder) WithTemplate(data any, opts ...TemplateOption) *Builder {
-	cfg := &templateConfig{}
-	for _, opt := range opts {
-		opt(cfg)
+func (b *Builder) WithIncludes() *Builder {
+	b.config.includes = true
+
+	return b
+}
+
+// WithProfile selects a profile of the source document. A document with a
+// top-level profiles key keeps one section per environment, and the
+// selected profile is deep-merged over the default profile, which is merged
+// over the rest of the document:
+//
+//	// config.yaml:
+//	//   server:
+//	//     port: 8080
+//	//   profiles:
+//	//     default:
+//	//       database: {host: localhost, pool: 5}
+//	//     prod:
+//	//       database: {host: db.prod.internal}
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithProfile(cmp.Or(os.Getenv("APP_PROFILE"), "default")).
+//	    Build()
+//
+// Profiles may share values with YAML anchors and merge keys, such as
+// `staging: {<<: *prod}`. With FromFiles, each file's profiles are resolved
+// before the merge. Profiles are resolved at each load, after include
+// resolution and env expansion. The load fails if no source document
+// defines the profile. An empty name is an error.
+func (b *Builder) WithProfile(name string) *Builder {
+	if b.err != nil {
+		return b
 	}
+	if name == "" {
+		b.err = &FieldError{Message: "empty profile"}
 
-	b.config.tmplConfig = cfg
-	b.config.tmplData = data
+		return b
+	}
+	b.config.profile = name
 
 	return b
 }
@@ -338,7 +1091,8 @@ func (b *Builder) WithTemplate(data any, opts ...TemplateOption) *Builder {
 // By default, existing environment variables take precedence over dotenv values.
 // Use DotEnvOverride() option to reverse this behavior.
 //
-// Missing files are silently ignored, making this safe for optional .env.local files.
+// Missing files are skipped, making this safe for optional .env.local files;
+// WithLogger logs them at debug level.
 //
 // Example:
 //
@@ -373,7 +1127,7 @@ func (b *Builder) WithDotEnv(file string, opts ...DotEnvOption) *Builder {
 //	    WithDotEnvFiles([]string{".env", ".env.local", ".env.production"}).
 //	    Build()
 //
-// Missing files are silently ignored.
+// Missing files are skipped; WithLogger logs them at debug level.
 // Use DotEnvOverride() option to override existing env vars.
 func (b *Builder) WithDotEnvFiles(files []string, opts ...DotEnvOption) *Builder {
 	cfg := &dotenvConfig{
@@ -418,51 +1172,166 @@ func (b *Builder) Build() (*Loader, error) {
 		return nil, b.err
 	}
 
+	fs := b.config.fs
+	if fs == nil {
+		fs = DefaultFs
+	}
+
 	// Use default resolver if not provided
 	refResolver := b.config.refResolver
 	if refResolver == nil {
-		fs := b.config.fs
-		if fs == nil {
-			fs = DefaultFs
+		composite := resolver.New(fs)
+		if err := b.config.refHTTP.register(composite); err != nil {
+			return nil, err
+		}
+		composite.Register("age", resolver.NewAgeResolver(fs, slices.Clone(b.config.ageIdentities)))
+		for scheme, r := range registeredResolvers() {
+			composite.Register(scheme, r)
+		}
+		for scheme, r := range b.config.resolvers {
+			composite.Register(scheme, r)
+		}
+		refResolver = composite
+	}
+	middleware := slices.Clone(b.config.middleware)
+	if b.config.startSpan != nil {
+		middleware = append(middleware, traceRefMiddleware(b.config.startSpan))
+	}
+	if b.config.metrics != nil {
+		middleware = append(middleware, metricsMiddleware(b.config.metrics))
+	}
+	if b.config.refRateLimit > 0 {
+		middleware = append(middleware, rateLimitMiddleware(b.config.refRateLimit))
+	}
+	refResolver = applyMiddleware(refResolver, middleware)
+
+	fetch := b.fetch
+	if b.object != "" {
+		uri := b.object
+		fetch = func(ctx context.Context) ([]byte, error) {
+			data, err := refResolver.Resolve(ctx, uri)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch %s: %w", uri, err)
+			}
+
+			return data, nil
 		}
-		refResolver = resolver.New(fs)
 	}
 
 	return &Loader{
 		loaderConfig: loaderConfig{
+			fs:                       fs,
 			envPrefix:                b.config.envPrefix,
 			validator:                b.config.validator,
+			skipValidate:             b.config.skipValidate,
+			validateMode:             b.config.validateMode,
+			defaults:                 b.config.defaults,
 			refResolver:              refResolver,
 			timeout:                  b.config.timeout,
+			startSpan:                b.config.startSpan,
+			metrics:                  b.config.metrics,
+			logger:                   b.config.logger,
+			refWorkers:               b.config.refWorkers,
+			refAttempts:              b.config.refAttempts,
+			refBackoff:               b.config.refBackoff,
+			secretsDir:               b.config.secretsDir,
 			tmplConfig:               b.config.tmplConfig,
 			tmplData:                 b.config.tmplData,
+			tagFuncs:                 maps.Clone(b.config.tagFuncs),
 			dotenvConfig:             b.config.dotenvConfig,
 			overrides:                b.config.overrides,
 			enableSizePreprocess:     b.config.enableSizePreprocess,
 			enableDurationPreprocess: b.config.enableDurationPreprocess,
+			trace:                    b.config.trace,
+			decrypters:               maps.Clone(b.config.decrypters),
+			ageIdentities:            slices.Clone(b.config.ageIdentities),
+			strictKeys:               b.config.strictKeys,
+			explicitZeros:            b.config.explicitZeros,
+			autoEnv:                  b.config.autoEnv,
+			expandEnv:                b.config.expandEnv,
+			includes:                 b.config.includes,
+			profile:                  b.config.profile,
+			flags:                    slices.Clone(b.config.flags),
+			precedence:               b.config.precedence,
+			onConflicts:              b.config.onConflicts,
+			tagProcessors:            registeredTagProcessors(),
+			decoders:                 registeredDecoders(),
+			hooks:                    cloneHooks(b.config.hooks),
+			decodeHooks:              slices.Clone(b.config.decodeHooks),
 		},
 		source:     b.source,
+		layers:     b.layers,
 		sourceName: b.name,
+		sourcePath: b.path,
+		fetch:      fetch,
+		open:       b.open,
 	}, nil
 }
 
 // Load populates the target struct with configuration.
+// It is equivalent to LoadContext with context.Background().
 func (l *Loader) Load(target any) error {
+	return l.LoadContext(context.Background(), target)
+}
+
+// LoadContext populates the target struct with configuration, aborting when
+// ctx is canceled or its deadline passes. Cancellation covers the whole load,
+// including ref and dsn resolution, retries, and template processing. If
+// WithTimeout is also set, the shorter of the two deadlines applies.
+//
+// Example:
+//
+//	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer cancel()
+//	if err := loader.LoadContext(ctx, &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+func (l *Loader) LoadContext(ctx context.Context, target any) error {
+	return l.load(ctx, target, "", nil, nil)
+}
+
+// load populates target from the subtree of the source document at section
+// (the whole document if empty), recording the outcome in report and the
+// trace of every field in explain, if not nil.
+func (l *Loader) load(ctx context.Context, target any, section string, report *LoadReport, explain *TraceRecorder) (err error) {
+	if l.startSpan != nil {
+		var end func(error)
+		ctx, end = l.startSpan(ctx, spanLoad, attrSource, l.sourceName)
+		defer func() { end(err) }()
+	}
+
 	targetVal := reflect.ValueOf(target)
 	if targetVal.Kind() != reflect.Pointer || targetVal.IsNil() {
 		return &FieldError{Message: "target must be a non-nil pointer"}
 	}
 
-	var tmplCfg *loader.TemplateConfig
-	if l.tmplConfig != nil {
-		tmplCfg = &loader.TemplateConfig{
-			LeftDelim:  l.tmplConfig.leftDelim,
-			RightDelim: l.tmplConfig.rightDelim,
-			MissingKey: l.tmplConfig.missingKey,
-			FuncMap:    l.tmplConfig.funcMap,
+	if l.defaults.IsValid() {
+		if targetVal.Elem().Type() != l.defaults.Type() {
+			return &FieldError{Message: fmt.Sprintf("defaults of type %s do not match target type %s", l.defaults.Type(), targetVal.Elem().Type())}
 		}
+		deepCopyValue(targetVal.Elem(), l.defaults, make(map[uintptr]reflect.Value))
+	}
+
+	engine := l.newEngine(section)
+	if l.validateMode == ValidationWarn {
+		engine.OnInvalid = l.warnInvalid(ctx, report)
 	}
+	recorders := l.traceRecorders(engine, explain)
 
+	if err := engine.LoadContext(ctx, target); err != nil {
+		return err
+	}
+
+	for _, rec := range recorders {
+		rec.commit()
+	}
+
+	return nil
+}
+
+// newEngine returns the engine loading the subtree of the source document
+// at section (the whole document if empty).
+func (l *Loader) newEngine(section string) *loader.Engine {
 	var dotenvCfg *loader.DotenvConfig
 	if l.dotenvConfig != nil {
 		dotenvCfg = &loader.DotenvConfig{
@@ -473,22 +1342,135 @@ func (l *Loader) Load(target any) error {
 		}
 	}
 
-	engine := &loader.Engine{
+	source, layers := l.snapshot()
+
+	return &loader.Engine{
 		Validator:                l.validator,
+		SkipValidate:             l.skipValidate,
+		Seeded:                   l.defaults.IsValid(),
 		RefResolver:              l.refResolver,
 		EnvPrefix:                l.envPrefix,
-		Source:                   l.source,
+		Source:                   source,
+		Layers:                   layers,
 		SourceName:               l.sourceName,
+		SourcePath:               l.sourcePath,
+		Fetch:                    l.fetch,
+		Open:                     l.open,
 		Timeout:                  l.timeout,
-		TemplateConfig:           tmplCfg,
+		RefConcurrency:           l.refWorkers,
+		RefRetryAttempts:         l.refAttempts,
+		RefRetryBackoff:          l.refBackoff,
+		SecretsDir:               l.secretsDir,
+		TemplateConfig:           l.tmplConfig.engineConfig(),
 		TemplateData:             l.tmplData,
+		TagTemplateFuncs:         l.tagFuncs,
 		DotenvConfig:             dotenvCfg,
+		Fs:                       l.fs,
 		Overrides:                l.overrides,
 		EnableSizePreprocess:     l.enableSizePreprocess,
 		EnableDurationPreprocess: l.enableDurationPreprocess,
+		Trace:                    l.trace,
+		Decrypters:               l.decrypters,
+		AgeIdentities:            l.ageIdentities,
+		StrictKeys:               l.strictKeys,
+		ExplicitZeros:            l.explicitZeros,
+		AutoEnv:                  l.autoEnv,
+		ExpandEnv:                l.expandEnv,
+		Includes:                 l.includes,
+		Profile:                  l.profile,
+		Flags:                    chainFlagLookups(l.flags),
+		Precedence:               l.precedence,
+		Conflicts:                l.onConflicts,
+		TagProcessors:            l.tagProcessors,
+		Decoders:                 l.decoders,
+		Hooks:                    l.hooks,
+		DecodeHooks:              l.decodeHooks,
+		StartSpan:                l.startSpan,
+		Logger:                   l.logger,
+		Section:                  section,
 	}
+}
 
-	return engine.Load(target)
+// warnInvalid returns the handler of validation errors in ValidationWarn
+// mode, which records them in report, if any, or logs them.
+func (l *Loader) warnInvalid(ctx context.Context, report *LoadReport) func(*ValidationError) {
+	return func(err *ValidationError) {
+		if report != nil {
+			report.Warnings = err
+		} else if l.logger != nil {
+			l.logger.DebugContext(ctx, "validation errors ignored", "error", err)
+		}
+	}
+}
+
+// traceRecorders returns the TraceRecorders of the load, the one given to
+// WithTrace and explain, and has engine record the trace of each field in
+// them. Their records are committed if the load succeeds.
+func (l *Loader) traceRecorders(engine *loader.Engine, explain *TraceRecorder) []*TraceRecorder {
+	var recorders []*TraceRecorder
+	if rec, ok := l.trace.(*TraceRecorder); ok && rec != nil {
+		recorders = append(recorders, rec)
+	}
+	if explain != nil {
+		recorders = append(recorders, explain)
+	}
+	for _, rec := range recorders {
+		rec.begin()
+	}
+	if len(recorders) > 0 {
+		engine.TraceRecord = func(tr loader.TraceRecord) {
+			for _, rec := range recorders {
+				rec.record(tr)
+			}
+		}
+	}
+
+	return recorders
+}
+
+// rawSource returns the source document, merging the files given to
+// FromFiles. Templates are not processed.
+func (l *Loader) rawSource() ([]byte, error) {
+	source, sourceLayers := l.snapshot()
+	if l.fetch != nil {
+		data, err := l.fetch(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		source = data
+	}
+	if l.open != nil {
+		data, err := loader.ReadAll(l.open)
+		if err != nil {
+			return nil, err
+		}
+		source = data
+	}
+	if len(sourceLayers) > 0 {
+		layers := make([]loader.Layer, len(sourceLayers))
+		for i, layer := range sourceLayers {
+			data, err := loader.DecodeSource(layer.Data)
+			if err != nil {
+				return nil, &FieldError{Message: "failed to decode " + layer.Name, Err: err}
+			}
+			layers[i] = loader.Layer{Name: layer.Name, Data: data}
+		}
+		merged, err := loader.MergeLayers(layers)
+		if err != nil {
+			return nil, &FieldError{Message: "source is not valid YAML/JSON", Err: err}
+		}
+		source = merged
+	}
+	if len(source) == 0 {
+		return nil, &FieldError{Message: "no source data to convert"}
+	}
+
+	source, err := loader.DecodeSource(source)
+	if err != nil {
+		return nil, &FieldError{Message: "failed to decode source", Err: err}
+	}
+
+	return source, nil
 }
 
 // ToKYAML converts the loader's source to KYAML format.
@@ -496,20 +1478,21 @@ func (l *Loader) Load(target any) error {
 // KYAML is a strict subset of YAML that is explicit and unambiguous,
 // designed to be halfway between YAML and JSON.
 func (l *Loader) ToKYAML() ([]byte, error) {
-	if len(l.source) == 0 {
-		return nil, &FieldError{Message: "no source data to convert"}
+	source, err := l.rawSource()
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate that the source is valid YAML by unmarshaling to a generic type
 	var test any
-	if err := yaml.Unmarshal(l.source, &test); err != nil {
+	if err := yaml.Unmarshal(source, &test); err != nil {
 		return nil, &FieldError{Message: "source is not valid YAML format", Err: err}
 	}
 
 	// Convert to KYAML format
 	var buf bytes.Buffer
 	encoder := &kyaml.Encoder{}
-	if err := encoder.FromYAML(bytes.NewReader(l.source), &buf); err != nil {
+	if err := encoder.FromYAML(bytes.NewReader(source), &buf); err != nil {
 		return nil, &FieldError{Message: "failed to convert to KYAML format", Err: err}
 	}
 
@@ -521,12 +1504,13 @@ func (l *Loader) ToKYAML() ([]byte, error) {
 // Useful for debugging, logging, or passing configuration to other systems.
 // Returns an error if no source is set or if YAML parsing fails.
 func (l *Loader) ToMap() (map[string]any, error) {
-	if len(l.source) == 0 {
-		return nil, &FieldError{Message: "no source data to convert"}
+	source, err := l.rawSource()
+	if err != nil {
+		return nil, err
 	}
 
 	var result map[string]any
-	if err := yaml.Unmarshal(l.source, &result); err != nil {
+	if err := yaml.Unmarshal(source, &result); err != nil {
 		return nil, &FieldError{Message: "source is not valid YAML/JSON", Err: err}
 	}
 
@@ -536,8 +1520,9 @@ func (l *Loader) ToMap() (map[string]any, error) {
 // Convenience Functions
 
 // LoadFile parses the file at path and populates target.
+// Defaults registered with SetDefaultBuilder apply.
 func LoadFile(path string, target any) error {
-	l, err := New().FromFile(path).Build()
+	l, err := newDefault().FromFile(path).Build()
 	if err != nil {
 		return err
 	}
@@ -546,8 +1531,9 @@ func LoadFile(path string, target any) error {
 }
 
 // LoadBytes parses the raw bytes and populates target.
+// Defaults registered with SetDefaultBuilder apply.
 func LoadBytes(data []byte, target any) error {
-	l, err := New().FromBytes(data).Build()
+	l, err := newDefault().FromBytes(data).Build()
 	if err != nil {
 		return err
 	}
@@ -556,8 +1542,9 @@ func LoadBytes(data []byte, target any) error {
 }
 
 // LoadReader reads from r and populates target.
+// Defaults registered with SetDefaultBuilder apply.
 func LoadReader(r io.Reader, target any) error {
-	l, err := New().FromReader(r).Build()
+	l, err := newDefault().FromReader(r).Build()
 	if err != nil {
 		return err
 	}
@@ -598,9 +1585,10 @@ func SetDefaults(target any, opts ...Option) error {
 
 	builder := New()
 	if !cfg.validate {
-		builder.WithValidator(nil)
+		builder.config.validator = nil
+		builder.config.skipValidate = true
 	} else if cfg.validator != nil {
-		builder.WithValidator(cfg.validator)
+		builder.config.validator = cfg.validator
 	}
 
 	l, err := builder.Build()
@@ -643,23 +1631,6 @@ func MustLoadReader(r io.Reader, target any) {
 	}
 }
 
-// Validate runs validation on target using the `validate` tag.
-// No loading, default processing, or env resolution occurs.
-// Only validation is performed.
-func Validate(target any, opts ...Option) error {
-	cfg := &config{}
-	for _, opt := range opts {
-		opt(cfg)
-	}
-
-	v := cfg.validator
-	if v == nil {
-		v = validator.New()
-	}
-
-	return v.Struct(target)
-}
-
 // LoadEnv applies environment variables to target via `env` tags.
 // No file source is read and no defaults are applied.
 // Only env tag processing occurs.
@@ -670,7 +1641,10 @@ func LoadEnv(target any) error {
 // LoadEnvWithPrefix is like LoadEnv but prepends prefix to env var names.
 // For example, with prefix "APP_", an `env:"HOST"` tag reads APP_HOST.
 func LoadEnvWithPrefix(prefix string, target any) error {
-	l, err := New().WithEnvPrefix(prefix).WithValidator(nil).Build()
+	b := New().WithEnvPrefix(prefix)
+	b.config.validator = nil
+	b.config.skipValidate = true
+	l, err := b.Build()
 	if err != nil {
 		return err
 	}
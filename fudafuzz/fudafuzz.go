@@ -0,0 +1,158 @@
+//go:build !fuda_minimal
+
+// Package fudafuzz generates random configs that pass fuda validation, for
+// property-based testing of application behavior across the config space.
+//
+// Values are drawn from the rules in each field's validate tag (required,
+// omitempty, oneof, min/max/len, gt/gte/lt/lte, formats such as email, url,
+// hostname, ip, or uuid, precision, and dive rules for elements). Fields with
+// a default tag sometimes keep their default instead, so default paths are
+// exercised too. A generated config is validated with fuda.Validate and
+// regenerated if it fails, for example because of cross-field rules.
+//
+// Basic usage:
+//
+//	func TestServer_AnyConfig(t *testing.T) {
+//	    fudafuzz.Run(t, 100, func(t *testing.T, cfg *Config) {
+//	        srv, err := NewServer(cfg)
+//	        require.NoError(t, err)
+//	        require.NoError(t, srv.Close())
+//	    })
+//	}
+//
+// Each run is a subtest named after its seed; a failure is reproduced with
+// Generate[Config](seed). Generate also fits Go's native fuzzing:
+//
+//	f.Fuzz(func(t *testing.T, seed uint64) {
+//	    cfg, err := fudafuzz.Generate[Config](seed)
+//	    ...
+//	})
+package fudafuzz
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/arloliu/fuda"
+)
+
+// DefaultMaxAttempts is the number of configs generated per call before
+// giving up on finding one that passes validation.
+const DefaultMaxAttempts = 100
+
+// Option configures generation.
+type Option func(*config)
+
+type config struct {
+	maxAttempts int
+	generators  map[reflect.Type]func(*rand.Rand) reflect.Value
+	seed        *uint64
+}
+
+// WithMaxAttempts sets how many configs are generated before giving up on
+// finding a valid one (default DefaultMaxAttempts).
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithGenerator registers fn to generate every value of type T, such as a
+// decimal type or a type whose constraints are not expressed in tags.
+//
+// Example:
+//
+//	fudafuzz.WithGenerator(func(r *rand.Rand) Region {
+//	    return []Region{"us", "eu"}[r.IntN(2)]
+//	})
+func WithGenerator[T any](fn func(r *rand.Rand) T) Option {
+	return func(c *config) {
+		c.generators[reflect.TypeFor[T]()] = func(r *rand.Rand) reflect.Value {
+			return reflect.ValueOf(fn(r))
+		}
+	}
+}
+
+// WithSeed makes Run use the seeds seed, seed+1, ... instead of random ones,
+// for reproducible runs.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = &seed
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		maxAttempts: DefaultMaxAttempts,
+		generators:  make(map[reflect.Type]func(*rand.Rand) reflect.Value),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Generate returns a random config of type T that passes fuda.Validate. The
+// same seed always yields the same config. T must be a struct type.
+func Generate[T any](seed uint64, opts ...Option) (*T, error) {
+	cfg := new(T)
+	if err := Fill(cfg, rand.New(rand.NewPCG(seed, seed)), opts...); err != nil { //nolint:gosec // test data, not security sensitive
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Fill sets target, a pointer to a struct, to a random config drawn from r
+// that passes fuda.Validate. It returns the last validation error if no
+// valid config is found within the maximum number of attempts.
+func Fill(target any, r *rand.Rand, opts ...Option) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("fudafuzz: target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	c := newConfig(opts)
+	g := &generator{rnd: r, generators: c.generators}
+
+	var err error
+	for range max(c.maxAttempts, 1) {
+		fresh := reflect.New(v.Elem().Type())
+		if err = g.fillStruct(fresh.Elem(), 0); err != nil {
+			return err
+		}
+		if err = fuda.Validate(fresh.Interface()); err == nil {
+			v.Elem().Set(fresh.Elem())
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("fudafuzz: no valid %s found in %d attempts: %w", v.Elem().Type(), max(c.maxAttempts, 1), err)
+}
+
+// Run calls fn with n random configs, each in a subtest named "seed=N". A
+// config that cannot be generated fails the subtest.
+func Run[T any](t *testing.T, n int, fn func(t *testing.T, cfg *T), opts ...Option) {
+	t.Helper()
+
+	base := rand.Uint64() //nolint:gosec // test data, not security sensitive
+	if c := newConfig(opts); c.seed != nil {
+		base = *c.seed
+	}
+
+	for i := range n {
+		seed := base + uint64(i) //nolint:gosec // i is non-negative
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			cfg, err := Generate[T](seed, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fn(t, cfg)
+		})
+	}
+}
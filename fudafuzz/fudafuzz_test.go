@@ -0,0 +1,180 @@
+//go:build !fuda_minimal
+
+package fudafuzz
+
+import (
+	"math/rand/v2"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/fuda"
+)
+
+type fuzzDB struct {
+	Host     string        `yaml:"host" validate:"required,hostname"`
+	Port     int           `yaml:"port" default:"5432" validate:"min=1,max=65535"`
+	Timeout  time.Duration `yaml:"timeout" default:"5s" validate:"gte=1s,lte=1m"`
+	Replicas []string      `yaml:"replicas" validate:"max=3,dive,ip"`
+}
+
+type fuzzConfig struct {
+	Name     string            `yaml:"name" validate:"required,min=3,max=10"`
+	Mode     string            `yaml:"mode" default:"dev" validate:"oneof=dev staging prod"`
+	Admin    string            `yaml:"admin" validate:"omitempty,email"`
+	Ratio    float64           `yaml:"ratio" validate:"gt=0,lt=1"`
+	Workers  uint8             `yaml:"workers" validate:"required"`
+	Debug    bool              `yaml:"debug"`
+	DB       fuzzDB            `yaml:"db"`
+	Cache    *fuzzDB           `yaml:"cache"`
+	Labels   map[string]string `yaml:"labels" validate:"max=4,dive,alphanum"`
+	Region   region            `yaml:"region"`
+	internal int
+}
+
+type region string
+
+func TestGenerate_Valid(t *testing.T) {
+	for seed := range uint64(200) {
+		cfg, err := Generate[fuzzConfig](seed)
+		require.NoError(t, err, "seed %d", seed)
+		require.NoError(t, fuda.Validate(cfg), "seed %d", seed)
+
+		assert.Contains(t, []string{"dev", "staging", "prod"}, cfg.Mode)
+		assert.GreaterOrEqual(t, len(cfg.Name), 3)
+		assert.LessOrEqual(t, len(cfg.Name), 10)
+		assert.Greater(t, cfg.Ratio, 0.0)
+		assert.Less(t, cfg.Ratio, 1.0)
+		assert.NotZero(t, cfg.Workers)
+		assert.LessOrEqual(t, len(cfg.Labels), 4)
+		if cfg.Admin != "" {
+			_, err := mail.ParseAddress(cfg.Admin)
+			assert.NoError(t, err)
+		}
+		assert.Zero(t, cfg.internal)
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	a, err := Generate[fuzzConfig](42)
+	require.NoError(t, err)
+	b, err := Generate[fuzzConfig](42)
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+}
+
+func TestGenerate_CoversSpace(t *testing.T) {
+	modes := map[string]bool{}
+	var defaultPort, otherPort, nilCache, setCache bool
+
+	for seed := range uint64(200) {
+		cfg, err := Generate[fuzzConfig](seed)
+		require.NoError(t, err)
+
+		modes[cfg.Mode] = true
+		if cfg.DB.Port == 5432 {
+			defaultPort = true
+		} else {
+			otherPort = true
+		}
+		if cfg.Cache == nil {
+			nilCache = true
+		} else {
+			setCache = true
+		}
+	}
+
+	assert.Len(t, modes, 3)
+	assert.True(t, defaultPort, "default value never used")
+	assert.True(t, otherPort, "random value never used")
+	assert.True(t, nilCache, "pointer never nil")
+	assert.True(t, setCache, "pointer never set")
+}
+
+func TestGenerate_WithGenerator(t *testing.T) {
+	opt := WithGenerator(func(r *rand.Rand) region {
+		return []region{"us", "eu"}[r.IntN(2)]
+	})
+
+	for seed := range uint64(20) {
+		cfg, err := Generate[fuzzConfig](seed, opt)
+		require.NoError(t, err)
+		assert.Contains(t, []region{"us", "eu"}, cfg.Region)
+	}
+}
+
+func TestGenerate_CrossFieldRetry(t *testing.T) {
+	type window struct {
+		Min int `validate:"min=0,max=10"`
+		Max int `validate:"min=0,max=10,gtefield=Min"`
+	}
+
+	for seed := range uint64(20) {
+		cfg, err := Generate[window](seed)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, cfg.Max, cfg.Min)
+	}
+}
+
+func TestGenerate_Unsatisfiable(t *testing.T) {
+	type impossible struct {
+		Name string `validate:"min=5,max=1"`
+	}
+
+	_, err := Generate[impossible](1, WithMaxAttempts(3))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no valid fudafuzz.impossible found in 3 attempts")
+}
+
+func TestGenerate_Recursive(t *testing.T) {
+	type node struct {
+		Value    int     `validate:"min=0,max=9"`
+		Children []*node `validate:"max=2"`
+	}
+
+	for seed := range uint64(20) {
+		_, err := Generate[node](seed)
+		require.NoError(t, err)
+	}
+}
+
+func TestFill_InvalidTarget(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 1)) //nolint:gosec // test data
+
+	err := Fill(fuzzConfig{}, r)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "non-nil pointer to a struct")
+
+	var n int
+	require.Error(t, Fill(&n, r))
+}
+
+func TestRun_WithSeed(t *testing.T) {
+	var names []string
+	Run(t, 3, func(t *testing.T, cfg *fuzzConfig) {
+		names = append(names, t.Name())
+		require.NoError(t, fuda.Validate(cfg))
+	}, WithSeed(7))
+
+	require.Len(t, names, 3)
+	assert.True(t, strings.HasSuffix(names[0], "/seed=7"))
+	assert.True(t, strings.HasSuffix(names[2], "/seed=9"))
+}
+
+func TestParseRules(t *testing.T) {
+	r := parseRules("required,min=2,lt=5,dive,oneof=a 'b c' d")
+	assert.True(t, r.required)
+	assert.InDelta(t, 2.0, *r.min, 0)
+	assert.InDelta(t, 5.0, *r.max, 0)
+	assert.True(t, r.maxOpen)
+	assert.Equal(t, []string{"a", "b c", "d"}, r.elem.oneof)
+
+	r = parseRules("gte=1s,lte=1m")
+	assert.InDelta(t, float64(time.Second), *r.min, 0)
+	assert.InDelta(t, float64(time.Minute), *r.max, 0)
+}
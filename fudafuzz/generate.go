@@ -0,0 +1,527 @@
+//go:build !fuda_minimal
+
+package fudafuzz
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// maxDepth bounds recursion into nested pointers, slices, and maps.
+const maxDepth = 8
+
+// defaultSpread is the width of a numeric range missing a bound.
+const defaultSpread = 1000
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	fudaDurationType    = reflect.TypeFor[fuda.Duration]()
+	timeType            = reflect.TypeFor[time.Time]()
+	scannerType         = reflect.TypeFor[types.Scanner]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// generator fills values at random.
+type generator struct {
+	rnd        *rand.Rand
+	generators map[reflect.Type]func(*rand.Rand) reflect.Value
+}
+
+// fillStruct fills the exported fields of struct v.
+func (g *generator) fillStruct(v reflect.Value, depth int) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+
+		r := parseRules(tags.Get(field, "validate"))
+
+		// Sometimes keep the default, or leave an optional field empty.
+		if def := tags.Get(field, "default"); def != "" && def != "-" && g.rnd.IntN(4) == 0 {
+			if err := types.Convert(def, fv); err != nil {
+				return fmt.Errorf("fudafuzz: invalid default for %s.%s: %w", t.Name(), field.Name, err)
+			}
+
+			continue
+		}
+		if r.omitempty && g.rnd.IntN(4) == 0 {
+			continue
+		}
+
+		if err := g.fill(fv, r, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fill sets v to a random value satisfying r.
+func (g *generator) fill(v reflect.Value, r *rules, depth int) error {
+	if gen, ok := g.generators[v.Type()]; ok {
+		v.Set(gen(g.rnd))
+
+		return nil
+	}
+
+	switch v.Type() {
+	case durationType, fudaDurationType:
+		v.SetInt(g.duration(r))
+
+		return nil
+	case timeType:
+		v.Set(reflect.ValueOf(time.Unix(946684800+g.rnd.Int64N(30*365*24*3600), 0).UTC()))
+
+		return nil
+	}
+
+	if g.fillScalar(v, r) {
+		return nil
+	}
+
+	//nolint:exhaustive // Unsupported kinds are left zero
+	switch v.Kind() {
+	case reflect.Pointer:
+		return g.fillPointer(v, r, depth)
+	case reflect.Slice, reflect.Array:
+		return g.fillSequence(v, r, depth)
+	case reflect.Map:
+		return g.fillMap(v, r, depth)
+	case reflect.Struct:
+		ptr := reflect.PointerTo(v.Type())
+		if ptr.Implements(scannerType) || ptr.Implements(textUnmarshalerType) {
+			return nil // opaque; use WithGenerator
+		}
+
+		return g.fillStruct(v, depth)
+	}
+
+	return nil
+}
+
+// fillScalar sets v to a random value satisfying r when v is a string, bool
+// or number, and reports whether it did.
+func (g *generator) fillScalar(v reflect.Value, r *rules) bool {
+	//nolint:exhaustive // Other kinds are handled by fill
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(g.string(r))
+	case reflect.Bool:
+		v.SetBool(r.required || g.rnd.IntN(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := v.Type().Bits()
+		v.SetInt(g.int(r, -(1 << (bits - 1)), 1<<(bits-1)-1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		hi := int64(math.MaxInt64)
+		if bits := v.Type().Bits(); bits < 64 {
+			hi = 1<<bits - 1
+		}
+		v.SetUint(uint64(g.int(r, 0, hi))) //nolint:gosec // g.int returns values within [0, hi]
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(g.float(r))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// fillPointer allocates the element of pointer v and fills it, leaving
+// optional pointers nil at random and past maxDepth.
+func (g *generator) fillPointer(v reflect.Value, r *rules, depth int) error {
+	if depth >= maxDepth || (!r.required && g.rnd.IntN(3) == 0) {
+		return nil
+	}
+	elem := reflect.New(v.Type().Elem())
+	if err := g.fill(elem.Elem(), r, depth+1); err != nil {
+		return err
+	}
+	v.Set(elem)
+
+	return nil
+}
+
+// fillSequence fills the elements of slice or array v with values
+// satisfying r.elem, choosing a random length for slices.
+func (g *generator) fillSequence(v reflect.Value, r *rules, depth int) error {
+	s := v
+	if v.Kind() == reflect.Slice {
+		n := g.count(r, depth)
+		s = reflect.MakeSlice(v.Type(), n, n)
+	}
+	for i := range s.Len() {
+		if err := g.fill(s.Index(i), r.elem, depth+1); err != nil {
+			return err
+		}
+	}
+	if v.Kind() == reflect.Slice {
+		v.Set(s)
+	}
+
+	return nil
+}
+
+// fillMap fills map v with random keys and values satisfying r.
+func (g *generator) fillMap(v reflect.Value, r *rules, depth int) error {
+	n := g.count(r, depth)
+	m := reflect.MakeMapWithSize(v.Type(), n)
+	keyRules := &rules{min: ptrTo(1.0), max: ptrTo(12.0)}
+
+	for range n {
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := g.fill(key, keyRules, depth+1); err != nil {
+			return err
+		}
+		val := reflect.New(v.Type().Elem()).Elem()
+		if err := g.fill(val, r.elem, depth+1); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, val)
+	}
+	v.Set(m)
+
+	return nil
+}
+
+// count returns a random collection length satisfying r.
+func (g *generator) count(r *rules, depth int) int {
+	if r.length != nil {
+		return int(*r.length)
+	}
+
+	lo, hi := r.bounds(0, 3)
+	lo = max(lo, 0)
+	if r.required && lo < 1 {
+		lo = 1
+	}
+	if depth >= maxDepth || hi < lo {
+		hi = lo
+	}
+
+	return int(lo) + g.rnd.IntN(int(hi-lo)+1)
+}
+
+// int returns a random integer in [limitLo, limitHi] satisfying r.
+func (g *generator) int(r *rules, limitLo, limitHi int64) int64 {
+	if len(r.oneof) > 0 {
+		n, _ := strconv.ParseInt(r.oneof[g.rnd.IntN(len(r.oneof))], 10, 64)
+
+		return n
+	}
+	if r.length != nil {
+		return int64(*r.length)
+	}
+
+	lo, hi := r.bounds(max(-defaultSpread, float64(limitLo)), defaultSpread)
+	lo, hi = math.Ceil(max(lo, float64(limitLo))), math.Floor(min(hi, float64(limitHi)))
+	if r.minOpen && lo == *r.min {
+		lo++
+	}
+	if r.maxOpen && hi == *r.max {
+		hi--
+	}
+	if hi < lo {
+		return int64(lo) // unsatisfiable; left to validation
+	}
+
+	for {
+		n := int64(lo) + g.rnd.Int64N(int64(hi-lo)+1)
+		if n != 0 || !r.required || (lo == 0 && hi == 0) {
+			return n
+		}
+	}
+}
+
+// float returns a random float satisfying r.
+func (g *generator) float(r *rules) float64 {
+	if len(r.oneof) > 0 {
+		f, _ := strconv.ParseFloat(r.oneof[g.rnd.IntN(len(r.oneof))], 64)
+
+		return f
+	}
+
+	lo, hi := r.bounds(-defaultSpread, defaultSpread)
+	for range 100 {
+		f := lo + g.rnd.Float64()*(hi-lo)
+		if r.precision >= 0 {
+			scale := math.Pow10(r.precision)
+			f = math.Round(f*scale) / scale
+		}
+		if (f > lo || !r.minOpen && f == lo) && (f < hi || !r.maxOpen && f == hi) && (f != 0 || !r.required) {
+			return f
+		}
+	}
+
+	return lo // unsatisfiable; left to validation
+}
+
+// duration returns a random duration in nanoseconds satisfying r.
+func (g *generator) duration(r *rules) int64 {
+	if len(r.oneof) > 0 {
+		d, _ := time.ParseDuration(r.oneof[g.rnd.IntN(len(r.oneof))])
+
+		return int64(d)
+	}
+
+	lo, hi := r.bounds(0, float64(time.Hour))
+	if r.minOpen {
+		lo++
+	}
+	if r.maxOpen {
+		hi--
+	}
+	if r.required && lo < 1 {
+		lo = 1
+	}
+	if hi < lo {
+		return int64(lo)
+	}
+
+	return int64(lo) + g.rnd.Int64N(int64(hi-lo)+1)
+}
+
+// string returns a random string satisfying r.
+func (g *generator) string(r *rules) string {
+	if len(r.oneof) > 0 {
+		return r.oneof[g.rnd.IntN(len(r.oneof))]
+	}
+	if r.format != "" {
+		return g.format(r.format)
+	}
+	if r.precision >= 0 {
+		return strconv.FormatFloat(g.float(&rules{precision: r.precision}), 'f', -1, 64)
+	}
+
+	n := 0
+	if r.length != nil {
+		n = int(*r.length)
+	} else {
+		lo, hi := r.bounds(0, 16)
+		lo = max(lo, 0)
+		if r.minOpen {
+			lo++
+		}
+		if r.maxOpen {
+			hi--
+		}
+		if r.required && lo < 1 {
+			lo = 1
+		}
+		n = int(lo) + g.rnd.IntN(max(int(hi-lo), 0)+1)
+	}
+
+	return g.chars(n, alnum)
+}
+
+const (
+	lower  = "abcdefghijklmnopqrstuvwxyz"
+	upper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits = "0123456789"
+	alnum  = lower + upper + digits
+)
+
+// chars returns n random characters from set.
+func (g *generator) chars(n int, set string) string {
+	var sb strings.Builder
+	for range n {
+		sb.WriteByte(set[g.rnd.IntN(len(set))])
+	}
+
+	return sb.String()
+}
+
+// word returns a short lowercase word.
+func (g *generator) word() string {
+	return g.chars(3+g.rnd.IntN(6), lower)
+}
+
+// format returns a random string in the given validator format.
+func (g *generator) format(name string) string {
+	switch name {
+	case "email":
+		return g.word() + "@" + g.word() + ".com"
+	case "url", "http_url", "uri":
+		return "https://" + g.word() + ".example.com/" + g.word()
+	case "hostname", "hostname_rfc1123", "fqdn":
+		return g.word() + ".example.com"
+	case "hostname_port":
+		return g.word() + ".example.com:" + strconv.Itoa(1+g.rnd.IntN(65535))
+	case "ip", "ipv4", "ip_addr", "ip4_addr":
+		return fmt.Sprintf("%d.%d.%d.%d", 1+g.rnd.IntN(254), g.rnd.IntN(256), g.rnd.IntN(256), 1+g.rnd.IntN(254))
+	case "ipv6", "ip6_addr":
+		return fmt.Sprintf("2001:db8::%x:%x", g.rnd.IntN(0xffff), 1+g.rnd.IntN(0xfffe))
+	case "cidr", "cidrv4":
+		return fmt.Sprintf("10.%d.0.0/16", g.rnd.IntN(256))
+	case "uuid", "uuid4", "uuid_rfc4122", "uuid4_rfc4122":
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(g.rnd.IntN(256))
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "alpha":
+		return g.chars(1+g.rnd.IntN(12), lower+upper)
+	case "alphanum":
+		return g.chars(1+g.rnd.IntN(12), alnum)
+	case "numeric", "number":
+		return g.chars(1+g.rnd.IntN(9), digits)
+	case "lowercase":
+		return g.chars(1+g.rnd.IntN(12), lower)
+	case "uppercase":
+		return g.chars(1+g.rnd.IntN(12), upper)
+	case "base64":
+		return base64.StdEncoding.EncodeToString([]byte(g.word()))
+	case "json":
+		return fmt.Sprintf(`{%q:%d}`, g.word(), g.rnd.IntN(1000))
+	default:
+		return g.word()
+	}
+}
+
+// rules holds the validate rules that guide generation.
+type rules struct {
+	required  bool
+	omitempty bool
+	oneof     []string
+	min, max  *float64 // bounds on the value, or on the length
+	minOpen   bool     // min is exclusive (gt)
+	maxOpen   bool     // max is exclusive (lt)
+	length    *float64 // exact value or length (len, eq)
+	format    string
+	precision int // -1 if unset
+	elem      *rules
+}
+
+// formats lists the string formats that format generates.
+var formats = map[string]bool{
+	"email": true, "url": true, "http_url": true, "uri": true,
+	"hostname": true, "hostname_rfc1123": true, "fqdn": true, "hostname_port": true,
+	"ip": true, "ipv4": true, "ipv6": true, "ip_addr": true, "ip4_addr": true, "ip6_addr": true,
+	"cidr": true, "cidrv4": true,
+	"uuid": true, "uuid4": true, "uuid_rfc4122": true, "uuid4_rfc4122": true,
+	"alpha": true, "alphanum": true, "numeric": true, "number": true,
+	"lowercase": true, "uppercase": true, "base64": true, "json": true,
+}
+
+// parseRules parses a validate tag. Rules after dive go to elem, and
+// alternatives joined with "|" are ignored.
+func parseRules(tag string) *rules {
+	r := &rules{precision: -1}
+	cur := r
+
+	for part := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.Contains(part, "|") {
+			continue
+		}
+
+		switch name {
+		case "dive":
+			cur.elem = &rules{precision: -1}
+			cur = cur.elem
+		case "required":
+			cur.required = true
+		case "omitempty":
+			cur.omitempty = true
+		case "oneof":
+			cur.oneof = splitOneof(param)
+		case "min", "gte":
+			cur.min, cur.minOpen = parseParam(param), false
+		case "gt":
+			cur.min, cur.minOpen = parseParam(param), true
+		case "max", "lte":
+			cur.max, cur.maxOpen = parseParam(param), false
+		case "lt":
+			cur.max, cur.maxOpen = parseParam(param), true
+		case "len", "eq":
+			cur.length = parseParam(param)
+		case "precision":
+			if p, err := strconv.Atoi(param); err == nil {
+				cur.precision = p
+			}
+		default:
+			if formats[name] {
+				cur.format = name
+			}
+		}
+	}
+
+	if r.elem == nil {
+		r.elem = &rules{precision: -1}
+	}
+
+	return r
+}
+
+// bounds returns the inclusive range of r, using defLo and defHi for
+// missing bounds. A range with one bound spans defaultSpread from it.
+func (r *rules) bounds(defLo, defHi float64) (float64, float64) {
+	lo, hi := defLo, defHi
+	switch {
+	case r.min != nil && r.max != nil:
+		lo, hi = *r.min, *r.max
+	case r.min != nil:
+		lo = *r.min
+		hi = max(defHi, lo+min(defHi-defLo, defaultSpread))
+	case r.max != nil:
+		hi = *r.max
+		lo = min(defLo, hi-min(defHi-defLo, defaultSpread))
+	}
+
+	return lo, hi
+}
+
+// parseParam parses a numeric or duration rule parameter.
+func parseParam(param string) *float64 {
+	if f, err := strconv.ParseFloat(param, 64); err == nil {
+		return &f
+	}
+	if d, err := time.ParseDuration(param); err == nil {
+		f := float64(d)
+
+		return &f
+	}
+
+	return nil
+}
+
+// splitOneof splits a oneof parameter; single quotes group a value with spaces.
+func splitOneof(param string) []string {
+	var values []string
+	for param = strings.TrimSpace(param); param != ""; param = strings.TrimSpace(param) {
+		if param[0] == '\'' {
+			if end := strings.IndexByte(param[1:], '\''); end >= 0 {
+				values = append(values, param[1:end+1])
+				param = param[end+2:]
+
+				continue
+			}
+		}
+
+		value, rest, _ := strings.Cut(param, " ")
+		values = append(values, value)
+		param = rest
+	}
+
+	return values
+}
+
+func ptrTo(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,93 @@
+package fuda
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/arloliu/fuda/internal/loader"
+)
+
+// HookPoint is a stage of Load at which hooks registered with
+// Builder.WithHook run.
+type HookPoint = loader.HookPoint
+
+// Hook is called with the load context and the target struct pointer at a
+// HookPoint. A returned error aborts the load and is returned by Load,
+// wrapped with the hook point, e.g. "AfterValidate hook: ...".
+type Hook = loader.Hook
+
+const (
+	// BeforeLoad hooks run before the source is read, with the target as
+	// passed to Load. Dotenv files are already loaded.
+	BeforeLoad = loader.BeforeLoad
+	// AfterLoad hooks run once every field is set from its sources and
+	// Setter methods, before validation, so they can normalize values that
+	// the validator then checks.
+	AfterLoad = loader.AfterLoad
+	// AfterValidate hooks run after the target passed validation, as the
+	// last step of a successful load.
+	AfterValidate = loader.AfterValidate
+)
+
+// WithHook registers fn to run at point on every load, after the hooks
+// registered for it before. Hooks see the target struct and the load
+// context, including the WithTimeout deadline, so they suit cross-field
+// normalization, metrics, or logging that does not belong in a Setter:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithHook(fuda.AfterLoad, func(_ context.Context, target any) error {
+//	        cfg := target.(*Config)
+//	        cfg.Host = strings.ToLower(cfg.Host)
+//	        return nil
+//	    }).
+//	    WithHook(fuda.AfterValidate, func(ctx context.Context, _ any) error {
+//	        configLoads.Inc()
+//	        return nil
+//	    }).
+//	    Build()
+//
+// Build fails if point is not a defined HookPoint or fn is nil.
+func (b *Builder) WithHook(point HookPoint, fn Hook) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if !point.Valid() {
+		b.err = fmt.Errorf("unknown hook point %s", point)
+
+		return b
+	}
+	if fn == nil {
+		b.err = fmt.Errorf("nil %s hook", point)
+
+		return b
+	}
+
+	if b.config.hooks == nil {
+		b.config.hooks = make(map[HookPoint][]Hook)
+	}
+	b.config.hooks[point] = append(b.config.hooks[point], fn)
+
+	return b
+}
+
+// WithHook returns an option that runs fn at point on every load. See
+// Builder.WithHook.
+func WithHook(point HookPoint, fn Hook) LoaderOption {
+	return func(b *Builder) { b.WithHook(point, fn) }
+}
+
+// cloneHooks copies hooks, so a Builder reused after Build does not change
+// the hooks of the loaders it built.
+func cloneHooks(hooks map[HookPoint][]Hook) map[HookPoint][]Hook {
+	if hooks == nil {
+		return nil
+	}
+
+	clone := make(map[HookPoint][]Hook, len(hooks))
+	for point, fns := range hooks {
+		clone[point] = slices.Clone(fns)
+	}
+
+	return clone
+}
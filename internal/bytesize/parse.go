@@ -116,5 +116,6 @@ func splitNumberUnit(s string) (numStr, unitStr string, err error) {
 		return "", "", fmt.Errorf("invalid size format: %s", s)
 	}
 
-	return s[:unitStart], s[unitStart:], nil
+	// Allow a space between number and unit, as in "512 MiB"
+	return strings.TrimSpace(s[:unitStart]), strings.TrimSpace(s[unitStart:]), nil
 }
@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+// agePrefix marks a YAML scalar as an age-encrypted value. The rest of the
+// scalar is the base64-encoded binary age ciphertext.
+const agePrefix = "enc:age:"
+
+// decryptAgeNodes walks a YAML node tree and replaces every "enc:age:" scalar
+// value with its plaintext. Mapping keys are never decrypted. The decrypted
+// scalar is re-resolved, so "8080" still decodes into an int field.
+func decryptAgeNodes(node *yaml.Node, identities []age.Identity) error {
+	if node == nil {
+		return nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := decryptAgeNodes(child, identities); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := decryptAgeNodes(node.Content[i], identities); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" || !strings.HasPrefix(node.Value, agePrefix) {
+			return nil
+		}
+
+		plaintext, err := decryptAgeValue(strings.TrimPrefix(node.Value, agePrefix), identities)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt age value at line %d: %w", node.Line, err)
+		}
+
+		node.Value = plaintext
+		node.Style = 0
+		node.Tag = ""
+		node.Tag = node.ShortTag()
+	case yaml.AliasNode:
+		// Aliases share their anchor's node, which is decrypted in place
+	}
+
+	return nil
+}
+
+// decryptAgeValue decrypts one base64-encoded age ciphertext.
+func decryptAgeValue(encoded string, identities []age.Identity) (string, error) {
+	if len(identities) == 0 {
+		return "", errors.New("no age identity configured (use WithAgeIdentity)")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", fmt.Errorf("ciphertext is not valid base64: %w", err)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
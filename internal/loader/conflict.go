@@ -0,0 +1,186 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict is a key set to different values by more than one source
+// document, such as two files given to FromFiles or a file and an override.
+type Conflict struct {
+	// Key is the dotted key path, e.g. "database.host".
+	Key string
+	// Winner is the value that was kept.
+	Winner ConflictValue
+	// Shadowed lists the values replaced by Winner, lowest priority first.
+	Shadowed []ConflictValue
+}
+
+// ConflictValue is the value one source document gave a conflicting key.
+type ConflictValue struct {
+	// Source is the document name, e.g. "prod.yaml" or "overrides".
+	Source string
+	// Value is the value as text; mappings and lists are shown as JSON.
+	Value string
+}
+
+// String returns the conflict as a single line, e.g.
+//
+//	database.host: "db.prod" from prod.yaml shadows "localhost" from base.yaml
+func (c Conflict) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %q from %s shadows ", c.Key, c.Winner.Value, c.Winner.Source)
+	for i, v := range c.Shadowed {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%q from %s", v.Value, v.Source)
+	}
+
+	return sb.String()
+}
+
+// FindConflicts returns the keys set to different values by more than one of
+// layers, merged in order as by MergeLayers. A key repeated with the same
+// value is not a conflict. Conflicts are ordered by where the key first
+// appears.
+func FindConflicts(layers []Layer) ([]Conflict, error) {
+	f := &conflictFinder{
+		origins:   make(map[string]string),
+		conflicts: make(map[string]*Conflict),
+		rank:      make(map[string]int),
+	}
+
+	var merged *yaml.Node
+	for i, layer := range layers {
+		f.rank[layer.Name] = i
+		var doc yaml.Node
+		if err := yaml.Unmarshal(layer.Data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", layer.Name, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		if merged == nil {
+			merged = doc.Content[0]
+			f.record(merged, "", layer.Name)
+		} else {
+			merged = f.merge(merged, doc.Content[0], "", layer.Name)
+		}
+	}
+
+	conflicts := make([]Conflict, 0, len(f.order))
+	for _, key := range f.order {
+		if c, ok := f.conflicts[key]; ok {
+			conflicts = append(conflicts, *c)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// conflictFinder merges documents like mergeNodes while tracking the source
+// of every key.
+type conflictFinder struct {
+	origins   map[string]string // key path → source of its current value
+	conflicts map[string]*Conflict
+	order     []string       // keys with conflicts, in order of first appearance
+	rank      map[string]int // source → layer index
+}
+
+// merge merges overlay, from source, into base at path and returns the
+// merged node.
+func (f *conflictFinder) merge(base, overlay *yaml.Node, path, source string) *yaml.Node {
+	if base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+			if j := mappingKeyIndex(base, key.Value); j >= 0 {
+				base.Content[j+1] = f.merge(base.Content[j+1], value, keyPath, source)
+			} else {
+				base.Content = append(base.Content, key, value)
+				f.record(value, keyPath, source)
+			}
+		}
+
+		return base
+	}
+
+	if prev, next := nodeString(base), nodeString(overlay); path != "" && prev != next {
+		c, ok := f.conflicts[path]
+		if !ok {
+			c = &Conflict{Key: path}
+			f.conflicts[path] = c
+			f.order = append(f.order, path)
+		}
+		c.Shadowed = append(c.Shadowed, ConflictValue{Source: f.sourceOf(base, path), Value: prev})
+		c.Winner = ConflictValue{Source: source, Value: next}
+
+		// Conflicts inside the replaced value no longer have their winner
+		for key := range f.conflicts {
+			if strings.HasPrefix(key, path+".") {
+				delete(f.conflicts, key)
+			}
+		}
+	}
+	f.record(overlay, path, source)
+
+	return overlay
+}
+
+// record sets source as the origin of node at path and of all its keys.
+func (f *conflictFinder) record(node *yaml.Node, path, source string) {
+	f.origins[path] = source
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		f.record(node.Content[i+1], joinPath(path, node.Content[i].Value), source)
+	}
+}
+
+// sourceOf returns the source of node at path. A mapping merged from
+// several documents lists each of them, lowest priority first.
+func (f *conflictFinder) sourceOf(node *yaml.Node, path string) string {
+	seen := make(map[string]bool)
+	var walk func(n *yaml.Node, p string)
+	walk = func(n *yaml.Node, p string) {
+		if n.Kind != yaml.MappingNode || len(n.Content) == 0 {
+			seen[f.origins[p]] = true
+
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			walk(n.Content[i+1], joinPath(p, n.Content[i].Value))
+		}
+	}
+	walk(node, path)
+
+	sources := slices.Collect(maps.Keys(seen))
+	slices.SortFunc(sources, func(a, b string) int { return f.rank[a] - f.rank[b] })
+
+	return strings.Join(sources, ", ")
+}
+
+// nodeString renders node for a ConflictValue.
+func nodeString(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+
+	var v any
+	if err := node.Decode(&v); err == nil {
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	data, _ := yaml.Marshal(node)
+
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []Layer
+		want   []Conflict
+	}{
+		{
+			name: "scalar shadowed",
+			layers: []Layer{
+				{Name: "base", Data: []byte("db:\n  host: base\n  port: 5432\nname: app\n")},
+				{Name: "prod", Data: []byte("db:\n  host: prod\n  port: 5432\n")},
+			},
+			want: []Conflict{{
+				Key:      "db.host",
+				Winner:   ConflictValue{Source: "prod", Value: "prod"},
+				Shadowed: []ConflictValue{{Source: "base", Value: "base"}},
+			}},
+		},
+		{
+			name: "three layers",
+			layers: []Layer{
+				{Name: "a", Data: []byte("port: 1\n")},
+				{Name: "b", Data: []byte("port: 2\n")},
+				{Name: "c", Data: []byte("port: 3\n")},
+			},
+			want: []Conflict{{
+				Key:      "port",
+				Winner:   ConflictValue{Source: "c", Value: "3"},
+				Shadowed: []ConflictValue{{Source: "a", Value: "1"}, {Source: "b", Value: "2"}},
+			}},
+		},
+		{
+			name: "sequences and mappings rendered as json",
+			layers: []Layer{
+				{Name: "base", Data: []byte("tags: [a, b]\ndb:\n  host: x\n")},
+				{Name: "prod", Data: []byte("tags: [c]\ndb: ~\n")},
+			},
+			want: []Conflict{
+				{
+					Key:      "tags",
+					Winner:   ConflictValue{Source: "prod", Value: `["c"]`},
+					Shadowed: []ConflictValue{{Source: "base", Value: `["a","b"]`}},
+				},
+				{
+					Key:      "db",
+					Winner:   ConflictValue{Source: "prod", Value: "~"},
+					Shadowed: []ConflictValue{{Source: "base", Value: `{"host":"x"}`}},
+				},
+			},
+		},
+		{
+			name: "conflicts inside a replaced mapping are dropped",
+			layers: []Layer{
+				{Name: "a", Data: []byte("db:\n  host: a\n")},
+				{Name: "b", Data: []byte("db:\n  host: b\n")},
+				{Name: "c", Data: []byte("db: none\n")},
+			},
+			want: []Conflict{{
+				Key:      "db",
+				Winner:   ConflictValue{Source: "c", Value: "none"},
+				Shadowed: []ConflictValue{{Source: "b", Value: `{"host":"b"}`}},
+			}},
+		},
+		{
+			name: "mapping merged from several layers",
+			layers: []Layer{
+				{Name: "a", Data: []byte("db:\n  host: a\n")},
+				{Name: "b", Data: []byte("db:\n  port: 1\n")},
+				{Name: "c", Data: []byte("db: [x]\n")},
+			},
+			want: []Conflict{{
+				Key:      "db",
+				Winner:   ConflictValue{Source: "c", Value: `["x"]`},
+				Shadowed: []ConflictValue{{Source: "a, b", Value: `{"host":"a","port":1}`}},
+			}},
+		},
+		{
+			name: "no conflicts",
+			layers: []Layer{
+				{Name: "a", Data: []byte("host: x\n")},
+				{Name: "b", Data: nil},
+				{Name: "c", Data: []byte("host: x\nport: 1\n")},
+			},
+			want: []Conflict{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindConflicts(tt.layers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFindConflicts_InvalidLayer(t *testing.T) {
+	_, err := FindConflicts([]Layer{{Name: "bad.yaml", Data: []byte("a: [")}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.yaml")
+}
+
+func TestConflict_String(t *testing.T) {
+	c := Conflict{
+		Key:      "db.host",
+		Winner:   ConflictValue{Source: "prod.yaml", Value: "db.prod"},
+		Shadowed: []ConflictValue{{Source: "base.yaml", Value: "localhost"}, {Source: "dev.yaml", Value: "dev"}},
+	}
+	assert.Equal(t, `db.host: "db.prod" from prod.yaml shadows "localhost" from base.yaml, "dev" from dev.yaml`, c.String())
+}
@@ -0,0 +1,62 @@
+package loader
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// TagField is the field a TagProcessor is called for.
+type TagField struct {
+	// Path is the dotted path of the field, e.g. "Database.Host".
+	Path string
+	// Tag is the value of the custom tag on the field.
+	Tag string
+	// Field describes the struct field.
+	Field reflect.StructField
+	// Value is the settable field value, holding what the built-in sources
+	// set so far.
+	Value reflect.Value
+	// Parent is the struct containing the field; fields before it in the
+	// struct have been fully processed.
+	Parent reflect.Value
+	// Resolver resolves ref URIs as for the ref tag, including registered
+	// schemes, middleware, and retries. It is nil if refs are disabled.
+	Resolver RefResolver
+}
+
+// TagProcessor implements a custom struct tag. It is called once for every
+// field carrying the tag, after the field's built-in sources are applied
+// and before its dsn and expr tags, and may set f.Value.
+type TagProcessor func(ctx context.Context, f TagField) error
+
+// applyTagProcessors runs the processors of the custom tags on field, in
+// order of tag name.
+func (e *Engine) applyTagProcessors(ctx context.Context, field reflect.StructField, fieldVal, parentVal reflect.Value, path string, resolver RefResolver) error {
+	for _, name := range slices.Sorted(maps.Keys(e.TagProcessors)) {
+		tag, ok := field.Tag.Lookup(name)
+		if !ok {
+			continue
+		}
+
+		f := TagField{Path: path, Tag: tag, Field: field, Value: fieldVal, Parent: parentVal, Resolver: resolver}
+		if err := e.TagProcessors[name](ctx, f); err != nil {
+			return fieldError(path, name, err)
+		}
+	}
+
+	return nil
+}
+
+// customTagParts returns the trace parts of the custom tags on field.
+func (e *Engine) customTagParts(field reflect.StructField) []string {
+	var parts []string
+	for _, name := range slices.Sorted(maps.Keys(e.TagProcessors)) {
+		if tag, ok := field.Tag.Lookup(name); ok {
+			parts = append(parts, name+"="+tag)
+		}
+	}
+
+	return parts
+}
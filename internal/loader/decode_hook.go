@@ -0,0 +1,252 @@
+package loader
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// decodedScalars holds the values of the scalar nodes converted by decode
+// hooks, by node. yaml.v3 cannot decode these nodes itself, so they are
+// replaced with the encoded zero value of their type before node.Decode,
+// and the converted values are stored afterwards by set.
+type decodedScalars map[*yaml.Node]reflect.Value
+
+// decodeHookScalars runs hooks on the scalars of node whose target field in
+// targetType they apply to, and replaces those nodes with placeholders. It
+// returns the converted values, or nil if no hook applied.
+func decodeHookScalars(node *yaml.Node, targetType reflect.Type, hooks []types.DecodeHook) (decodedScalars, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+
+	decoded := make(decodedScalars)
+	if err := decoded.collect(node, targetType, "", hooks); err != nil {
+		return nil, err
+	}
+	if len(decoded) == 0 {
+		return nil, nil
+	}
+
+	return decoded, nil
+}
+
+// collect converts the scalars under node, which decodes into type t.
+func (d decodedScalars) collect(node *yaml.Node, t reflect.Type, path string, hooks []types.DecodeHook) error {
+	if node == nil || t == nil {
+		return nil
+	}
+
+	if node.Kind == yaml.ScalarNode && node.ShortTag() != "!!null" {
+		return d.collectScalar(node, t, path, hooks)
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if decodesItself(t) {
+		return nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := d.collect(child, t, path, hooks); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		return d.collectSequence(node, t, path, hooks)
+	case yaml.MappingNode:
+		return d.collectMapping(node, t, path, hooks)
+	case yaml.ScalarNode, yaml.AliasNode:
+		// Unconverted scalars decode normally; aliases decode their anchor
+	}
+
+	return nil
+}
+
+// collectScalar converts the scalar node, which decodes into type t, if a
+// hook applies to it.
+func (d decodedScalars) collectScalar(node *yaml.Node, t reflect.Type, path string, hooks []types.DecodeHook) error {
+	v, ok, err := types.Decode(hooks, node.Value, t)
+	if err != nil {
+		return &types.FieldError{Path: path, Err: err}
+	}
+	if !ok {
+		return nil
+	}
+
+	var placeholder yaml.Node
+	if err := placeholder.Encode(reflect.Zero(t).Interface()); err != nil {
+		return &types.FieldError{Path: path, Err: err}
+	}
+	*node = placeholder
+	d[node] = v
+
+	return nil
+}
+
+// collectSequence converts the scalars under the elements of a sequence
+// node, which decodes into slice or array type t.
+func (d decodedScalars) collectSequence(node *yaml.Node, t reflect.Type, path string, hooks []types.DecodeHook) error {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil
+	}
+	for i, child := range node.Content {
+		if err := d.collect(child, t.Elem(), path+"["+strconv.Itoa(i)+"]", hooks); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectMapping converts the scalars under the values of a mapping node,
+// which decodes into map or struct type t.
+func (d decodedScalars) collectMapping(node *yaml.Node, t reflect.Type, path string, hooks []types.DecodeHook) error {
+	switch t.Kind() { //nolint:exhaustive // only structs and maps have keys
+	case reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := d.collect(node.Content[i+1], t.Elem(), joinKeyPath(path, node.Content[i].Value), hooks); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields, _ := strictFieldTypes(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if fieldType, ok := fields[key]; ok {
+				if err := d.collect(node.Content[i+1], fieldType, joinKeyPath(path, key), hooks); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// set stores the converted values under node into v, which node was
+// decoded into.
+func (d decodedScalars) set(node *yaml.Node, v reflect.Value) {
+	if len(d) == 0 || node == nil {
+		return
+	}
+	if converted, ok := d[node]; ok {
+		v.Set(converted)
+
+		return
+	}
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if decodesItself(v.Type()) {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			d.set(child, v)
+		}
+	case yaml.SequenceNode:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return
+		}
+		for i, child := range node.Content {
+			if i < v.Len() {
+				d.set(child, v.Index(i))
+			}
+		}
+	case yaml.MappingNode:
+		switch v.Kind() { //nolint:exhaustive // only structs and maps have keys
+		case reflect.Map:
+			d.setMap(node, v)
+		case reflect.Struct:
+			d.setStruct(node, v)
+		}
+	case yaml.ScalarNode, yaml.AliasNode:
+		// Nothing below to set
+	}
+}
+
+// setMap stores the converted values under the values of a mapping node
+// into map v.
+func (d decodedScalars) setMap(node *yaml.Node, v reflect.Value) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := reflect.New(v.Type().Key())
+		if node.Content[i].Decode(key.Interface()) != nil {
+			continue
+		}
+		elem := v.MapIndex(key.Elem())
+		if !elem.IsValid() {
+			continue
+		}
+		// Map values are not addressable, so set a copy back
+		c := reflect.New(elem.Type()).Elem()
+		c.Set(elem)
+		d.set(node.Content[i+1], c)
+		v.SetMapIndex(key.Elem(), c)
+	}
+}
+
+// setStruct stores the converted values under the values of a mapping node
+// into the fields of struct v.
+func (d decodedScalars) setStruct(node *yaml.Node, v reflect.Value) {
+	fields := yamlFieldIndexes(v.Type())
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if index, ok := fields[node.Content[i].Value]; ok {
+			d.set(node.Content[i+1], v.FieldByIndex(index))
+		}
+	}
+}
+
+// yamlFieldIndexes maps the keys yaml.v3 accepts for struct type t to the
+// indexes of their fields, like strictFieldTypes maps them to types.
+func yamlFieldIndexes(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+
+	var walk func(reflect.Type, []int)
+	walk = func(t reflect.Type, parent []int) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+
+			tag := field.Tag.Get("yaml")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			index := append(append([]int(nil), parent...), i)
+
+			if strings.Contains(","+opts+",", ",inline,") {
+				if field.Type.Kind() == reflect.Struct {
+					walk(field.Type, index)
+				}
+
+				continue
+			}
+
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fields[name] = index
+		}
+	}
+	walk(t, nil)
+
+	return fields
+}
@@ -0,0 +1,171 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/arloliu/fuda/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// fieldDecoder returns the decoder named by the decoder tag of field, and
+// whether the field has one.
+func (e *Engine) fieldDecoder(field reflect.StructField) (types.FieldDecoder, bool, error) {
+	name := tags.Get(field, "decoder")
+	if name == "" || name == "-" {
+		return nil, false, nil
+	}
+
+	dec, ok := e.Decoders[name]
+	if !ok {
+		return nil, false, fmt.Errorf("no decoder registered as %q", name)
+	}
+
+	return dec, true, nil
+}
+
+// decodeTaggedFields runs the decoders of the fields with a decoder tag on
+// their content under node, which decodes into type t, and replaces those
+// nodes with null placeholders, as decodeHookScalars does. It returns the
+// decoded values, to be stored after node.Decode by set.
+func (e *Engine) decodeTaggedFields(node *yaml.Node, t reflect.Type) (decodedScalars, error) {
+	decoded := make(decodedScalars)
+	if len(e.Decoders) == 0 {
+		return decoded, nil
+	}
+
+	return decoded, e.collectDecoded(decoded, node, t, "")
+}
+
+// collectDecoded decodes the tagged fields under node, which decodes into
+// type t.
+func (e *Engine) collectDecoded(d decodedScalars, node *yaml.Node, t reflect.Type, path string) error {
+	if node == nil || t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if decodesItself(t) {
+		return nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := e.collectDecoded(d, child, t, path); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		return e.collectDecodedSequence(d, node, t, path)
+	case yaml.MappingNode:
+		switch t.Kind() { //nolint:exhaustive // only structs and maps have keys
+		case reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if err := e.collectDecoded(d, node.Content[i+1], t.Elem(), joinKeyPath(path, node.Content[i].Value)); err != nil {
+					return err
+				}
+			}
+		case reflect.Struct:
+			return e.collectDecodedStruct(d, node, t, path)
+		}
+	case yaml.ScalarNode, yaml.AliasNode:
+		// Only the values of tagged fields are decoded
+	}
+
+	return nil
+}
+
+// collectDecodedSequence decodes the tagged fields under the elements of a
+// sequence node, which decodes into slice or array type t.
+func (e *Engine) collectDecodedSequence(d decodedScalars, node *yaml.Node, t reflect.Type, path string) error {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil
+	}
+	for i, child := range node.Content {
+		if err := e.collectDecoded(d, child, t.Elem(), path+"["+strconv.Itoa(i)+"]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectDecodedStruct decodes the fields of struct type t set by a mapping
+// node: with their decoder if tagged, and the tagged fields under them
+// otherwise.
+func (e *Engine) collectDecodedStruct(d decodedScalars, node *yaml.Node, t reflect.Type, path string) error {
+	fields := yamlFieldIndexes(t)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		index, ok := fields[key]
+		if !ok {
+			continue
+		}
+		field := t.FieldByIndex(index)
+		fieldPath := joinKeyPath(path, key)
+
+		dec, ok, err := e.fieldDecoder(field)
+		if err == nil && ok {
+			err = d.decodeWith(node.Content[i+1], field.Type, dec)
+		} else if err == nil {
+			err = e.collectDecoded(d, node.Content[i+1], field.Type, fieldPath)
+		}
+		if err != nil {
+			return &types.FieldError{Path: fieldPath, Tag: "decoder", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// decodeWith runs dec on the content of node into a new value of type t,
+// and replaces node with a null placeholder. Null values are left to decode
+// as zero.
+func (d decodedScalars) decodeWith(node *yaml.Node, t reflect.Type, dec types.FieldDecoder) error {
+	src := node
+	if src.Kind == yaml.AliasNode && src.Alias != nil {
+		src = src.Alias
+	}
+	if src.Kind == yaml.ScalarNode && src.ShortTag() == "!!null" {
+		return nil
+	}
+
+	data, err := nodeContent(src)
+	if err != nil {
+		return err
+	}
+	v := reflect.New(t).Elem()
+	if err := dec(data, v); err != nil {
+		return err
+	}
+
+	*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	d[node] = v
+
+	return nil
+}
+
+// nodeContent returns the content a decoder receives for node: the value of
+// a scalar, such as a PEM block in a literal block scalar, or the JSON
+// encoding of a mapping or sequence, such as a JWKS set written in YAML.
+func nodeContent(node *yaml.Node) ([]byte, error) {
+	if node.Kind == yaml.ScalarNode {
+		return []byte(node.Value), nil
+	}
+
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("decoder content must be JSON-compatible: %w", err)
+	}
+
+	return data, nil
+}
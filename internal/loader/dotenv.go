@@ -1,10 +1,14 @@
 package loader
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/joho/godotenv"
+	"github.com/spf13/afero"
 )
 
 // DotenvConfig holds dotenv file loading configuration.
@@ -17,28 +21,52 @@ type DotenvConfig struct {
 
 // loadDotenvFiles loads dotenv files based on configuration.
 // This is called at the start of Load() before any env tag processing.
-func (e *Engine) loadDotenvFiles() error {
+func (e *Engine) loadDotenvFiles(ctx context.Context) error {
 	if e.DotenvConfig == nil {
 		return nil
 	}
 
-	files := e.resolveEnvFiles()
-	if len(files) == 0 {
-		return nil
+	files := e.resolveEnvFiles(ctx)
+	for _, file := range files {
+		if err := e.loadDotenvFile(file); err != nil {
+			return err
+		}
+		e.logDebug(ctx, "dotenv file loaded", "file", file)
+	}
+
+	return nil
+}
+
+// loadDotenvFile parses a single dotenv file from the engine filesystem and
+// exports its variables. Existing variables are kept unless Override is set.
+func (e *Engine) loadDotenvFile(file string) error {
+	data, err := afero.ReadFile(e.fs(), file)
+	if err != nil {
+		return err
+	}
+
+	vars, err := godotenv.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
 	}
 
-	if e.DotenvConfig.Override {
-		return godotenv.Overload(files...)
+	for key, value := range vars {
+		if _, exists := os.LookupEnv(key); exists && !e.DotenvConfig.Override {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
 	}
 
-	return godotenv.Load(files...)
+	return nil
 }
 
 // resolveEnvFiles returns the list of env files to load.
 // Priority: explicit files > search paths
-func (e *Engine) resolveEnvFiles() []string {
+func (e *Engine) resolveEnvFiles(ctx context.Context) []string {
 	if len(e.DotenvConfig.Files) > 0 {
-		return filterExistingFiles(e.DotenvConfig.Files)
+		return e.filterExistingFiles(ctx, e.DotenvConfig.Files)
 	}
 
 	if len(e.DotenvConfig.SearchPaths) > 0 && e.DotenvConfig.SearchName != "" {
@@ -49,12 +77,15 @@ func (e *Engine) resolveEnvFiles() []string {
 }
 
 // filterExistingFiles returns only files that exist on disk.
-// Missing files are silently ignored to support optional .env.local patterns.
-func filterExistingFiles(files []string) []string {
+// Missing files are skipped, with a debug log, to support optional
+// .env.local patterns.
+func (e *Engine) filterExistingFiles(ctx context.Context, files []string) []string {
 	var existing []string
 	for _, f := range files {
-		if _, err := os.Stat(f); err == nil {
+		if _, err := e.fs().Stat(f); err == nil {
 			existing = append(existing, f)
+		} else {
+			e.logDebug(ctx, "dotenv file skipped", "file", f, "error", err)
 		}
 	}
 
@@ -66,7 +97,7 @@ func filterExistingFiles(files []string) []string {
 func (e *Engine) searchForEnvFiles() []string {
 	for _, dir := range e.DotenvConfig.SearchPaths {
 		path := filepath.Join(dir, e.DotenvConfig.SearchName)
-		if _, err := os.Stat(path); err == nil {
+		if _, err := e.fs().Stat(path); err == nil {
 			return []string{path}
 		}
 	}
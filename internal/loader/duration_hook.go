@@ -18,52 +18,14 @@ import (
 // between an integer meant for time.Duration vs a regular int field at the YAML level.
 // For integer duration values, use fuda.Duration type which has custom UnmarshalYAML.
 func preprocessDurationNodesForType(node *yaml.Node, targetType reflect.Type) {
-	if node == nil {
-		return
-	}
-	if targetType != nil && targetType.Kind() == reflect.Pointer {
-		targetType = targetType.Elem()
-	}
-
-	switch node.Kind {
-	case yaml.DocumentNode, yaml.SequenceNode:
-		for _, child := range node.Content {
-			preprocessDurationNodesForType(child, targetType)
-		}
-	case yaml.MappingNode:
-		switch {
-		case targetType != nil && targetType.Kind() == reflect.Struct:
-			fieldMap := yamlFieldTypeMap(targetType)
-			for i := 0; i < len(node.Content); i += 2 {
-				keyNode := node.Content[i]
-				valNode := node.Content[i+1]
-				if keyNode.Kind != yaml.ScalarNode {
-					continue
-				}
-				fieldType, ok := fieldMap[keyNode.Value]
-				if !ok {
-					continue
-				}
-				preprocessDurationNodesForType(valNode, fieldType)
-			}
-		case targetType != nil && targetType.Kind() == reflect.Map:
-			valType := targetType.Elem()
-			for i := 0; i < len(node.Content); i += 2 {
-				preprocessDurationNodesForType(node.Content[i+1], valType)
-			}
-		default:
-			// Unknown target type; avoid coercion
-		}
-	case yaml.ScalarNode:
+	walkScalarNodes(node, targetType, func(node *yaml.Node, targetType reflect.Type) {
 		// Convert 'd' suffix to hours (e.g., "2d" → "48h") only for time.Duration
 		if node.Tag == "!!str" && isDurationType(targetType) && hasDaySuffix(node.Value) {
 			if converted, ok := convertDaysToHours(node.Value); ok {
 				node.Value = converted
 			}
 		}
-	case yaml.AliasNode:
-		// Aliases are resolved by yaml.Decode, no preprocessing needed
-	}
+	})
 }
 
 func isDurationType(t reflect.Type) bool {
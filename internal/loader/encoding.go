@@ -0,0 +1,111 @@
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks recognized by DecodeSource.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF32LE = []byte{0xFF, 0xFE, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xFE, 0xFF}
+)
+
+// DecodeSource returns source as UTF-8 with "\n" line endings, as expected
+// by the YAML parser. It strips a UTF-8 byte order mark, decodes UTF-16
+// (with a byte order mark, or detected from the zero bytes of an ASCII
+// first character, as saved by some Windows editors), and converts "\r\n"
+// and "\r" line endings. Input that is not valid UTF-8 or holds NUL bytes
+// is rejected with its position, rather than failing later in the parser.
+// Plain UTF-8 with "\n" line endings is returned unchanged.
+func DecodeSource(source []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(source, bomUTF32LE), bytes.HasPrefix(source, bomUTF32BE):
+		return nil, errors.New("UTF-32 encoding is not supported; save the file as UTF-8")
+	case bytes.HasPrefix(source, bomUTF8):
+		source = source[len(bomUTF8):]
+	case bytes.HasPrefix(source, bomUTF16LE):
+		return decodeSourceUTF16(source[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(source, bomUTF16BE):
+		return decodeSourceUTF16(source[len(bomUTF16BE):], true)
+	case len(source) >= 2 && source[0] != 0 && source[1] == 0:
+		return decodeSourceUTF16(source, false)
+	case len(source) >= 2 && source[0] == 0 && source[1] != 0:
+		return decodeSourceUTF16(source, true)
+	}
+
+	return normalizeSource(source)
+}
+
+// decodeSourceUTF16 converts UTF-16 source, without its byte order mark, to
+// UTF-8.
+func decodeSourceUTF16(source []byte, bigEndian bool) ([]byte, error) {
+	if len(source)%2 != 0 {
+		return nil, errors.New("invalid UTF-16: odd number of bytes")
+	}
+
+	units := make([]uint16, len(source)/2)
+	for i := range units {
+		hi, lo := source[2*i+1], source[2*i]
+		if bigEndian {
+			hi, lo = lo, hi
+		}
+		units[i] = uint16(hi)<<8 | uint16(lo)
+	}
+
+	for i := 0; i < len(units); i++ {
+		if !utf16.IsSurrogate(rune(units[i])) {
+			continue
+		}
+		if i+1 == len(units) || utf16.DecodeRune(rune(units[i]), rune(units[i+1])) == utf8.RuneError {
+			return nil, fmt.Errorf("invalid UTF-16: unpaired surrogate at byte %d", 2*i)
+		}
+		i++
+	}
+
+	return normalizeSource([]byte(string(utf16.Decode(units))))
+}
+
+// normalizeSource checks that source is valid UTF-8 without NUL bytes and
+// converts its line endings to "\n".
+func normalizeSource(source []byte) ([]byte, error) {
+	if i := bytes.IndexByte(source, 0); i >= 0 {
+		return nil, fmt.Errorf("unexpected NUL byte at %s; the file looks binary", sourcePos(source, i))
+	}
+
+	if !utf8.Valid(source) {
+		i := 0
+		for i < len(source) {
+			r, size := utf8.DecodeRune(source[i:])
+			if r == utf8.RuneError && size == 1 {
+				break
+			}
+			i += size
+		}
+
+		return nil, fmt.Errorf("invalid UTF-8 at %s; save the file as UTF-8", sourcePos(source, i))
+	}
+
+	if bytes.IndexByte(source, '\r') < 0 {
+		return source, nil
+	}
+
+	source = bytes.ReplaceAll(source, []byte("\r\n"), []byte("\n"))
+
+	return bytes.ReplaceAll(source, []byte("\r"), []byte("\n")), nil
+}
+
+// sourcePos returns the position of byte offset i in source, e.g.
+// "line 3, column 7". Columns count bytes.
+func sourcePos(source []byte, i int) string {
+	line := bytes.Count(source[:i], []byte("\n")) + 1
+	col := i - bytes.LastIndexByte(source[:i], '\n')
+
+	return fmt.Sprintf("line %d, column %d", line, col)
+}
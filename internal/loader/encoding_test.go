@@ -0,0 +1,80 @@
+package loader
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// utf16Bytes encodes s as UTF-16 in the given byte order, without a BOM.
+func utf16Bytes(s string, order binary.AppendByteOrder) []byte {
+	var out []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = order.AppendUint16(out, u)
+	}
+
+	return out
+}
+
+func TestDecodeSource(t *testing.T) {
+	const want = "name: café\nemoji: \"😀\"\n"
+
+	tests := []struct {
+		name   string
+		source []byte
+	}{
+		{"utf-8", []byte(want)},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, want...)},
+		{"crlf", []byte("name: café\r\nemoji: \"😀\"\r\n")},
+		{"cr", []byte("name: café\remoji: \"😀\"\r")},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16Bytes(want, binary.LittleEndian)...)},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, utf16Bytes(want, binary.BigEndian)...)},
+		{"utf-16le no bom", utf16Bytes(want, binary.LittleEndian)},
+		{"utf-16be no bom", utf16Bytes(want, binary.BigEndian)},
+		{"utf-16le bom crlf", append([]byte{0xFF, 0xFE}, utf16Bytes("name: café\r\nemoji: \"😀\"\r\n", binary.LittleEndian)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeSource(tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+		})
+	}
+}
+
+func TestDecodeSource_Unchanged(t *testing.T) {
+	source := []byte("host: localhost\n")
+	got, err := DecodeSource(source)
+	require.NoError(t, err)
+	assert.Same(t, &source[0], &got[0], "plain UTF-8 is not copied")
+
+	got, err = DecodeSource(nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestDecodeSource_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source []byte
+		want   string
+	}{
+		{"latin-1", []byte("host: db\nname: caf\xe9\n"), "invalid UTF-8 at line 2, column 10; save the file as UTF-8"},
+		{"binary", []byte("host: db\n\x00\x01\x02"), "unexpected NUL byte at line 2, column 1; the file looks binary"},
+		{"utf-32", []byte{0xFF, 0xFE, 0x00, 0x00, 'a', 0, 0, 0}, "UTF-32 encoding is not supported"},
+		{"odd utf-16", []byte{0xFF, 0xFE, 'a', 0, 'b'}, "invalid UTF-16: odd number of bytes"},
+		{"unpaired surrogate", []byte{0xFF, 0xFE, 'a', 0, 0x00, 0xD8, 'b', 0}, "invalid UTF-16: unpaired surrogate at byte 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeSource(tt.source)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
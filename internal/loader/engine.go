@@ -1,15 +1,21 @@
 package loader
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"reflect"
 	"strings"
 	"time"
 
+	"filippo.io/age"
 	"github.com/arloliu/fuda/internal/tags"
 	"github.com/arloliu/fuda/internal/types"
-	"github.com/go-playground/validator/v10"
+	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,112 +24,588 @@ type RefResolver interface {
 	Resolve(ctx context.Context, uri string) ([]byte, error)
 }
 
+// LoadScoper is implemented by resolvers that keep state for the duration of
+// a single load, such as a per-load cache.
+type LoadScoper interface {
+	// BeginLoad returns the context passed to Resolve during one load.
+	BeginLoad(ctx context.Context) context.Context
+}
+
 // Engine is the internal configuration processing engine.
 // It handles YAML unmarshaling, tag processing (env, ref, default), and validation.
 type Engine struct {
-	Validator      *validator.Validate
-	RefResolver    RefResolver
-	EnvPrefix      string
-	Source         []byte
-	SourceName     string // Name of the source (e.g., "config.yaml", "reader", "bytes")
+	Validator *Validator
+	// SkipValidate disables the Validate methods of config structs (see
+	// types.Validator); Validator disables tag validation.
+	SkipValidate bool
+	// Seeded reports that the target was seeded with default values before
+	// the load: values the source document does not set rank as defaults,
+	// below refs and above default tags.
+	Seeded bool
+	// Logger, if set, receives debug logs of source selection, fallback
+	// decisions, and errors that do not fail the load.
+	Logger *slog.Logger
+	// StartSpan, if set, starts a tracing span for a stage of the load,
+	// such as "fuda.template", and returns the context holding it and a
+	// function that ends it with the outcome of the stage.
+	StartSpan func(ctx context.Context, name string, attrs ...string) (context.Context, func(error))
+	// OnInvalid, if set, receives the validation error of a load instead of
+	// failing it, for warn-only validation.
+	OnInvalid   func(err *types.ValidationError)
+	RefResolver RefResolver
+	EnvPrefix   string
+	Source      []byte
+	SourceName  string // Name of the source (e.g., "config.yaml", "reader", "bytes")
+	// SourcePath is the file Source was read from, if any; includes are
+	// resolved relative to it.
+	SourcePath string
+	// Fetch, if set, replaces Source with the document it returns at each
+	// load, such as a config fetched over HTTP.
+	Fetch func(ctx context.Context) ([]byte, error)
+	// Open, if set, replaces Source with the document read from the reader
+	// it returns at each load. Unless the raw document is needed (see
+	// streamable), it is decoded as a stream without reading it whole.
+	Open func() (io.ReadCloser, error)
+	// Layers, when set, replaces Source: each layer is templated, then all are
+	// deep-merged in order (see MergeLayers). Layers are named by file path.
+	Layers         []Layer
 	Timeout        time.Duration
 	TemplateConfig *TemplateConfig
 	TemplateData   any
-	DotenvConfig   *DotenvConfig
-	Overrides      map[string]any // Programmatic value overrides (dot-notation supported)
-	// EnableSizePreprocess controls size-string preprocessing (default: true).
+	// TagTemplateFuncs adds functions to the ${...} templates of ref and
+	// dsn tags (see tags.TemplateConfig.Funcs).
+	TagTemplateFuncs map[string]any
+	// TagProcessors implement custom struct tags, keyed by tag name.
+	TagProcessors map[string]TagProcessor
+	// Decoders decode the content of fields with a decoder tag, keyed by
+	// decoder name.
+	Decoders map[string]types.FieldDecoder
+	// Hooks run at each stage of a load, in order (see HookPoint).
+	Hooks map[HookPoint][]Hook
+	// DecodeHooks convert string values to field types, before the
+	// built-in conversions.
+	DecodeHooks  []types.DecodeHook
+	DotenvConfig *DotenvConfig
+	Overrides    map[string]any // Programmatic value overrides (dot-notation supported)
+	// EnableSizePreprocess controls size strings such as "10MiB" for integer
+	// fields, in sources and tags (default: true).
 	EnableSizePreprocess *bool
-	// EnableDurationPreprocess controls duration-string preprocessing (default: true).
+	// EnableDurationPreprocess controls day suffixes such as "7d" for
+	// time.Duration fields, in sources and tags (default: true).
 	EnableDurationPreprocess *bool
+	// RefConcurrency bounds concurrent prefetching of independent refs (<= 1 resolves sequentially).
+	RefConcurrency int
+	// RefRetryAttempts is the total number of attempts for failed refs (<= 1 disables retries).
+	RefRetryAttempts int
+	// RefRetryBackoff is the initial delay between attempts, doubled after each retry.
+	RefRetryBackoff time.Duration
+	// SecretsDir holds the files read by secretName tags (empty means
+	// tags.DefaultSecretsDir).
+	SecretsDir string
+	// Fs is the filesystem used for dotenv files (nil means the OS filesystem).
+	Fs afero.Fs
+	// Trace receives one line per field describing how its value was resolved (nil disables tracing).
+	Trace io.Writer
+	// TraceRecord, if set, receives the structured trace of every field.
+	TraceRecord func(TraceRecord)
+	// Decrypters decrypt kms-tagged fields, keyed by provider name.
+	Decrypters map[string]tags.Decrypter
+	// AgeIdentities decrypt "enc:age:" values in the source.
+	AgeIdentities []age.Identity
+	// StrictKeys rejects source keys that do not match any field.
+	StrictKeys bool
+	// ExplicitZeros keeps zero values that the source document sets, which
+	// otherwise count as unset.
+	ExplicitZeros bool
+	// ExpandEnv expands ${VAR} placeholders in the source (see ExpandEnv).
+	ExpandEnv bool
+	// Includes splices `!include` files into the source (see ResolveIncludes).
+	Includes bool
+	// AutoEnv reads fields without an env tag from a variable named after
+	// their path (see tags.AutoEnvName).
+	AutoEnv bool
+	// Flags looks up command-line flags bound by flag tags; a flag that was
+	// set overrides env vars (nil disables flags).
+	Flags tags.FlagLookup
+	// Precedence orders the sources of field values, lowest first (nil
+	// means DefaultPrecedence). It must list every source once.
+	Precedence []Source
+	// Conflicts, if set, receives the keys set to different values by more
+	// than one source document or override (see FindConflicts).
+	Conflicts func([]Conflict)
+	// Profile, if set, selects the profile of each source document that
+	// is merged over its default profile (see ApplyProfile).
+	Profile string
+	// Section, if set, loads only the subtree of the source document at
+	// this dot-separated path of keys, such as "database". Overrides are
+	// keyed by their path in the whole document.
+	Section string
+
+	// docTop is the higher-ranked of the file and the overrides, and
+	// docPaths the fields it set (see mergeDocuments).
+	docTop   Source
+	docPaths map[string]bool
+	// sourcePaths holds the fields set by the source document, including
+	// overrides, when the target is Seeded or ExplicitZeros is set.
+	sourcePaths map[string]bool
+
+	// fieldErrors collects the errors of fields that failed to load, such as
+	// an invalid default or a failed ref, and the unset env vars of
+	// `env:",required"` fields, so they are reported together after
+	// processing.
+	fieldErrors []types.FieldError
+	// mapDepth counts the maps being walked; AutoEnv does not map fields
+	// under map values, whose keys do not fit variable names.
+	mapDepth int
 }
 
+// Load populates target using a background context bounded by Timeout.
 func (e *Engine) Load(target any) error {
-	// Load dotenv files first, before any env tag processing
-	if err := e.loadDotenvFiles(); err != nil {
-		return fmt.Errorf("failed to load dotenv files: %w", err)
+	return e.LoadContext(context.Background(), target)
+}
+
+// LoadContext populates target, aborting when ctx is canceled or its deadline
+// passes. Timeout, if set, further bounds ctx.
+func (e *Engine) LoadContext(ctx context.Context, target any) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("load canceled: %w", err)
+	}
+
+	if err := e.checkEnvConflicts(reflect.TypeOf(target)); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := e.loadContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err := e.runHooks(ctx, BeforeLoad, target); err != nil {
+		return err
+	}
+
+	node, err := e.sourceNode(ctx, reflect.TypeOf(target))
+	if err != nil {
+		return err
+	}
+	if e.Section != "" {
+		if node, err = selectSection(node, e.Section); err != nil {
+			return e.sourceError(err)
+		}
+	}
+	e.logSource(ctx, node)
+	if e.Seeded || e.ExplicitZeros {
+		e.sourcePaths = make(map[string]bool)
+		collectFieldPaths(node, reflect.TypeOf(target), "", e.sourcePaths)
 	}
 
-	ctx := context.Background()
+	if node != nil {
+		if err := decryptAgeNodes(node, e.AgeIdentities); err != nil {
+			return e.sourceError(err)
+		}
+		if err := e.decodeNode(node, target); err != nil {
+			return err
+		}
+	}
+
+	if err := e.processTags(ctx, target); err != nil {
+		return err
+	}
+
+	if err := e.runHooks(ctx, AfterLoad, target); err != nil {
+		return err
+	}
+
+	if err := e.validate(ctx, target); err != nil {
+		return err
+	}
+
+	return e.runHooks(ctx, AfterValidate, target)
+}
+
+// loadContext returns the context of a load: dotenv files are loaded into
+// the environment first, before any env tag processing, then ctx is bounded
+// by Timeout and scoped by the resolver, if it is a LoadScoper.
+func (e *Engine) loadContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if err := e.loadDotenvFiles(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to load dotenv files: %w", err)
+	}
+
+	cancel := context.CancelFunc(func() {})
 	if e.Timeout > 0 {
-		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
-		defer cancel()
 	}
 
-	// Process template if configured
-	source := e.Source
-	if e.TemplateData != nil && len(source) > 0 {
-		processed, err := ProcessTemplate(source, e.TemplateData, e.TemplateConfig)
+	if scoper, ok := e.RefResolver.(LoadScoper); ok {
+		ctx = scoper.BeginLoad(ctx)
+	}
+	ctx = types.WithDecodeHooks(ctx, e.DecodeHooks)
+	ctx = types.WithUnits(ctx, resolvePreprocessFlag(e.EnableDurationPreprocess), resolvePreprocessFlag(e.EnableSizePreprocess))
+
+	return ctx, cancel, nil
+}
+
+// decodeNode decodes node into target, checking its keys first with
+// StrictKeys.
+func (e *Engine) decodeNode(node *yaml.Node, target any) error {
+	targetType := reflect.TypeOf(target)
+
+	// Decode fields with a decoder tag from their raw content first, so
+	// later passes see placeholders instead of foreign formats
+	decoded, err := e.decodeTaggedFields(node, targetType)
+	if err != nil {
+		return e.decodeError(err)
+	}
+
+	if e.StrictKeys {
+		if err := checkUnknownKeys(node, targetType, e.SourceName); err != nil {
+			return err
+		}
+	}
+
+	// Preprocess nodes
+	if resolvePreprocessFlag(e.EnableSizePreprocess) {
+		preprocessSizeNodesForType(node, targetType)
+	}
+	if resolvePreprocessFlag(e.EnableDurationPreprocess) {
+		preprocessDurationNodesForType(node, targetType)
+	}
+
+	// Decode to target struct, with the values decode hooks convert
+	// set afterwards
+	hooked, err := decodeHookScalars(node, targetType, e.DecodeHooks)
+	if err == nil {
+		maps.Copy(decoded, hooked)
+		err = node.Decode(target)
+	}
+	if err != nil {
+		return e.decodeError(err)
+	}
+	decoded.set(node, reflect.ValueOf(target))
+
+	return nil
+}
+
+// processTags applies the tags of every field of target.
+func (e *Engine) processTags(ctx context.Context, target any) error {
+	targetVal := reflect.ValueOf(target)
+
+	// Prefetch independent refs concurrently, if enabled
+	eng := e
+	if e.RefConcurrency > 1 && e.RefResolver != nil {
+		prefetched := *e
+		prefetched.RefResolver = e.prefetchRefs(ctx, targetVal)
+		eng = &prefetched
+	}
+
+	// Process recursive tags with cycle detection
+	// Pass the original pointer so cycle detection can track it
+	visited := make(map[uintptr]bool)
+	eng.fieldErrors = nil
+	if err := eng.processStructWithVisited(ctx, targetVal, "", visited); err != nil {
+		return err
+	}
+	if len(eng.fieldErrors) > 0 {
+		return &types.LoadError{Source: e.SourceName, Errors: eng.fieldErrors}
+	}
+
+	return nil
+}
+
+// validate checks the validate tags of target, then the structs' own
+// Validate methods. Errors go to OnInvalid, if set, instead of failing the
+// load.
+func (e *Engine) validate(ctx context.Context, target any) error {
+	_, end := e.startSpan(ctx, "fuda.validate")
+	var errs []error
+	if e.Validator != nil {
+		errs = e.addSettingHints(target, Validate(e.Validator, target))
+	}
+	if !e.SkipValidate {
+		errs = append(errs, ValidateSelf(target)...)
+	}
+	if len(errs) == 0 {
+		end(nil)
+
+		return nil
+	}
+
+	err := &types.ValidationError{Errors: errs}
+	end(err)
+	if e.OnInvalid == nil {
+		return err
+	}
+	e.OnInvalid(err)
+
+	return nil
+}
+
+// sourceError prefixes err with SourceName, if set.
+func (e *Engine) sourceError(err error) error {
+	if e.SourceName != "" {
+		return fmt.Errorf("%s: %w", e.SourceName, err)
+	}
+
+	return err
+}
+
+// decodeError wraps an error decoding the source document.
+func (e *Engine) decodeError(err error) error {
+	if e.SourceName != "" {
+		return fmt.Errorf("failed to decode %s: %w", e.SourceName, err)
+	}
+
+	return fmt.Errorf("failed to decode source: %w", err)
+}
+
+// sourceNode returns the node tree of the source document, after all source
+// processing and overrides, or nil if there is no document.
+func (e *Engine) sourceNode(ctx context.Context, targetType reflect.Type) (*yaml.Node, error) {
+	if e.Open != nil && e.streamable() {
+		return e.decodeStream(ctx)
+	}
+
+	// Process templates and env placeholders if configured, and merge layered sources
+	layers, err := e.prepareLayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if e.Profile != "" {
+		if layers, err = e.applyProfile(layers); err != nil {
+			return nil, err
+		}
+	}
+	source := layers[0].Data
+	if len(e.Layers) > 0 {
+		if source, err = MergeLayers(layers); err != nil {
+			return nil, err
+		}
+	}
+
+	if e.Conflicts != nil {
+		if err := e.reportConflicts(layers); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply overrides even if source is empty (allows creating config purely from overrides)
+	if len(e.Overrides) > 0 {
+		source, err = e.mergeDocuments(source, targetType)
 		if err != nil {
-			if e.SourceName != "" {
-				return fmt.Errorf("failed to process template in %s: %w", e.SourceName, err)
+			return nil, fmt.Errorf("failed to apply overrides: %w", err)
+		}
+	}
+
+	if len(source) == 0 {
+		return nil, nil //nolint:nilnil // no document
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(source, &node); err != nil {
+		if e.SourceName != "" {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", e.SourceName, err)
+		}
+
+		return nil, fmt.Errorf("failed to unmarshal source: %w", err)
+	}
+
+	return &node, nil
+}
+
+// streamable reports whether the document from Open can be decoded as a
+// stream: no processing that needs the raw document is configured.
+func (e *Engine) streamable() bool {
+	return len(e.Layers) == 0 && e.TemplateData == nil && !e.ExpandEnv && !e.Includes &&
+		len(e.Overrides) == 0 && e.Conflicts == nil && e.Profile == ""
+}
+
+// decodeStream decodes the document from Open into a node tree without
+// reading it into memory whole. The YAML decoder itself handles byte order
+// marks, UTF-16, and line endings. SOPS-encrypted documents are decrypted.
+func (e *Engine) decodeStream(ctx context.Context) (*yaml.Node, error) {
+	r, err := e.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var node yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&node); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil //nolint:nilnil // empty file, no document
+		}
+
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", e.SourceName, err)
+	}
+
+	if _, err := decryptSOPSNode(ctx, &node, e.AgeIdentities, e.Decrypters); err != nil {
+		return nil, fmt.Errorf("failed to decrypt sops file %s: %w", e.SourceName, err)
+	}
+
+	return &node, nil
+}
+
+// ReadAll reads the whole document from open.
+func ReadAll(open func() (io.ReadCloser, error)) ([]byte, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// prepareLayers returns the source documents after template processing and
+// env expansion: each of Layers, or else Source alone.
+func (e *Engine) prepareLayers(ctx context.Context) ([]Layer, error) {
+	if len(e.Layers) == 0 {
+		source := e.Source
+		if e.Fetch != nil {
+			var err error
+			if source, err = e.Fetch(ctx); err != nil {
+				return nil, err
 			}
+		}
+		if e.Open != nil {
+			var err error
+			if source, err = ReadAll(e.Open); err != nil {
+				return nil, err
+			}
+		}
 
-			return fmt.Errorf("failed to process template: %w", err)
+		data, err := e.processLayer(ctx, source, e.SourceName, e.SourcePath)
+		if err != nil {
+			return nil, err
 		}
 
-		source = processed
+		return []Layer{{Name: e.SourceName, Data: data}}, nil
+	}
+
+	layers := make([]Layer, len(e.Layers))
+	for i, layer := range e.Layers {
+		data, err := e.processLayer(ctx, layer.Data, layer.Name, layer.Name)
+		if err != nil {
+			return nil, err
+		}
+		layers[i] = Layer{Name: layer.Name, Data: data}
+	}
+
+	return layers, nil
+}
+
+// reportConflicts passes to Conflicts the keys set differently by the
+// source documents and the overrides, ordered by precedence.
+func (e *Engine) reportConflicts(layers []Layer) error {
+	docs := make([]Layer, 0, len(layers)+1)
+	for _, layer := range layers {
+		if layer.Name == "" {
+			layer.Name = "source"
+		}
+		docs = append(docs, layer)
 	}
 
-	// 1. Apply overrides and unmarshal Source
-	// Handle overrides even if source is empty (allows creating config purely from overrides)
 	if len(e.Overrides) > 0 {
-		var err error
-		source, err = e.applyOverrides(source)
+		overrides, err := e.applyOverrides(nil)
 		if err != nil {
 			return fmt.Errorf("failed to apply overrides: %w", err)
 		}
+		doc := Layer{Name: "overrides", Data: overrides}
+		if e.ranksAbove(SourceFile, SourceOverride) {
+			docs = append([]Layer{doc}, docs...)
+		} else {
+			docs = append(docs, doc)
+		}
 	}
 
-	if len(source) > 0 {
-		// Unmarshal to node tree for duration preprocessing
-		var node yaml.Node
-		if err := yaml.Unmarshal(source, &node); err != nil {
-			if e.SourceName != "" {
-				return fmt.Errorf("failed to unmarshal %s: %w", e.SourceName, err)
-			}
+	conflicts, err := FindConflicts(docs)
+	if err != nil {
+		return err
+	}
+	e.Conflicts(conflicts)
 
-			return fmt.Errorf("failed to unmarshal source: %w", err)
-		}
+	return nil
+}
 
-		// Preprocess nodes
-		if resolvePreprocessFlag(e.EnableSizePreprocess) {
-			preprocessSizeNodesForType(&node, reflect.TypeOf(target))
+// processLayer runs encoding normalization, SOPS decryption, template
+// processing, include resolution, and then env expansion on one source
+// document, read from the file at path if any.
+func (e *Engine) processLayer(ctx context.Context, source []byte, name, path string) ([]byte, error) {
+	source, err := DecodeSource(source)
+	if err != nil {
+		if name != "" {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
 		}
-		if resolvePreprocessFlag(e.EnableDurationPreprocess) {
-			preprocessDurationNodesForType(&node, reflect.TypeOf(target))
+
+		return nil, fmt.Errorf("failed to decode source: %w", err)
+	}
+
+	source, err = DecryptSOPS(ctx, source, e.AgeIdentities, e.Decrypters)
+	if err != nil {
+		if name != "" {
+			return nil, fmt.Errorf("failed to decrypt sops file %s: %w", name, err)
 		}
 
-		// Decode to target struct
-		if err := node.Decode(target); err != nil {
-			if e.SourceName != "" {
-				return fmt.Errorf("failed to decode %s: %w", e.SourceName, err)
+		return nil, fmt.Errorf("failed to decrypt sops document: %w", err)
+	}
+
+	source, err = e.processTemplate(ctx, source, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Includes {
+		if source, err = ResolveIncludes(e.fs(), source, path); err != nil {
+			if name != "" {
+				return nil, fmt.Errorf("failed to resolve includes in %s: %w", name, err)
 			}
 
-			return fmt.Errorf("failed to decode source: %w", err)
+			return nil, fmt.Errorf("failed to resolve includes: %w", err)
 		}
 	}
 
-	targetVal := reflect.ValueOf(target)
+	if !e.ExpandEnv {
+		return source, nil
+	}
 
-	// Process recursive tags with cycle detection
-	// Pass the original pointer so cycle detection can track it
-	visited := make(map[uintptr]bool)
-	if err := e.processStructWithVisited(ctx, targetVal, visited); err != nil {
-		return err
+	expanded, err := ExpandEnv(source)
+	if err != nil {
+		if name != "" {
+			return nil, fmt.Errorf("failed to expand env in %s: %w", name, err)
+		}
+
+		return nil, fmt.Errorf("failed to expand env: %w", err)
 	}
 
-	// 5. Validate
-	if e.Validator != nil {
-		if err := e.Validator.Struct(target); err != nil {
-			return &types.ValidationError{Errors: []error{err}}
+	return expanded, nil
+}
+
+// processTemplate executes source as a template if template data is set.
+func (e *Engine) processTemplate(ctx context.Context, source []byte, name string) ([]byte, error) {
+	if e.TemplateData == nil || len(source) == 0 {
+		return source, nil
+	}
+
+	_, end := e.startSpan(ctx, "fuda.template", "fuda.source", name)
+	processed, err := ProcessTemplate(source, e.TemplateData, e.TemplateConfig)
+	end(err)
+	if err != nil {
+		if name != "" {
+			return nil, fmt.Errorf("failed to process template in %s: %w", name, err)
 		}
+
+		return nil, fmt.Errorf("failed to process template: %w", err)
 	}
 
-	return nil
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("load canceled: %w", err)
+	}
+
+	return processed, nil
 }
 
-func (e *Engine) processStructWithVisited(ctx context.Context, v reflect.Value, visited map[uintptr]bool) error {
+func (e *Engine) processStructWithVisited(ctx context.Context, v reflect.Value, path string, visited map[uintptr]bool) error {
 	if v.Kind() == reflect.Pointer {
 		if v.IsNil() {
 			return nil
@@ -151,15 +633,37 @@ func (e *Engine) processStructWithVisited(ctx context.Context, v reflect.Value,
 			continue
 		}
 
-		// Process nested elements
-		if err := e.processNestedElementsWithVisited(ctx, fieldVal, visited); err != nil {
-			return err
+		fieldPath := joinPath(path, field.Name)
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("load canceled at %s: %w", fieldPath, err)
+		}
+
+		// Grow slices named by indexed env vars, then process nested
+		// elements, except in fields set as a whole by a decoder
+		decoded := tags.Get(field, "decoder") != ""
+		if !decoded {
+			if err := e.growSliceFromEnv(field, fieldVal, fieldPath); e.collect(err) != nil {
+				return err
+			}
+			if err := e.processNestedElementsWithVisited(ctx, fieldVal, fieldPath, visited); err != nil {
+				return err
+			}
 		}
 
 		// Apply tags
-		if err := e.applyTags(ctx, field, fieldVal, v); err != nil {
+		wasEmpty := isCollection(fieldVal) && fieldVal.IsZero()
+		if err := e.applyTags(ctx, field, fieldVal, v, fieldPath); e.collect(err) != nil {
 			return err
 		}
+
+		// Process the elements of a slice or map set by a tag, such as a
+		// JSON default, so their own tags apply too
+		if wasEmpty && !decoded && !fieldVal.IsZero() {
+			if err := e.processNestedElementsWithVisited(ctx, fieldVal, fieldPath, visited); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Handle Setter interface (Dynamic Defaults)
@@ -173,6 +677,79 @@ func (e *Engine) processStructWithVisited(ctx context.Context, v reflect.Value,
 	return nil
 }
 
+// collect records err in fieldErrors if it is a FieldError, so loading
+// continues with the next field, and returns any other error. Errors of a
+// canceled load are returned, as every later field would fail too.
+func (e *Engine) collect(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var fe *types.FieldError
+	if errors.As(err, &fe) {
+		e.fieldErrors = append(e.fieldErrors, *fe)
+
+		return nil
+	}
+
+	return err
+}
+
+// fieldError returns err as the error of the field at path. A FieldError
+// from tag processing, which carries the tag and source of the failing value,
+// gets the path filled in; other errors are wrapped as failures of tag.
+func fieldError(path, tag string, err error) *types.FieldError {
+	var fe *types.FieldError
+	if errors.As(err, &fe) && fe.Path == "" {
+		out := *fe
+		out.Path = path
+		out.Tag = cmp.Or(out.Tag, tag)
+
+		return &out
+	}
+
+	return &types.FieldError{Path: path, Tag: tag, Err: err}
+}
+
+// logDebug logs msg at debug level to Logger, if set.
+func (e *Engine) logDebug(ctx context.Context, msg string, args ...any) {
+	if e.Logger != nil {
+		e.Logger.DebugContext(ctx, msg, args...)
+	}
+}
+
+// logSource logs which document the load reads, if any.
+func (e *Engine) logSource(ctx context.Context, node *yaml.Node) {
+	switch {
+	case node == nil && e.Section != "":
+		e.logDebug(ctx, "config section not set, using env, refs, and defaults", "section", e.Section)
+	case node == nil:
+		e.logDebug(ctx, "no config document, using env, refs, and defaults")
+	case len(e.Layers) > 0:
+		e.logDebug(ctx, "config document read", "source", e.SourceName, "layers", len(e.Layers))
+	default:
+		e.logDebug(ctx, "config document read", "source", e.SourceName)
+	}
+}
+
+// startSpan starts a tracing span with StartSpan, if set.
+func (e *Engine) startSpan(ctx context.Context, name string, attrs ...string) (context.Context, func(error)) {
+	if e.StartSpan == nil {
+		return ctx, func(error) {}
+	}
+
+	return e.StartSpan(ctx, name, attrs...)
+}
+
+// fs returns the engine filesystem, defaulting to the OS filesystem.
+func (e *Engine) fs() afero.Fs {
+	if e.Fs == nil {
+		return afero.NewOsFs()
+	}
+
+	return e.Fs
+}
+
 func resolvePreprocessFlag(flag *bool) bool {
 	if flag == nil {
 		return true
@@ -181,34 +758,56 @@ func resolvePreprocessFlag(flag *bool) bool {
 	return *flag
 }
 
+// joinPath appends a field name to a dotted field path.
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// isCollection reports whether v is a slice or map.
+func isCollection(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Map
+}
+
 // processNestedElementsWithVisited recursively processes nested structs, slices, and maps with cycle detection.
-func (e *Engine) processNestedElementsWithVisited(ctx context.Context, fieldVal reflect.Value, visited map[uintptr]bool) error {
+func (e *Engine) processNestedElementsWithVisited(ctx context.Context, fieldVal reflect.Value, path string, visited map[uintptr]bool) error {
 	//nolint:exhaustive // Only struct-like types need processing
 	switch fieldVal.Kind() {
 	case reflect.Struct:
-		return e.processStructWithVisited(ctx, fieldVal, visited)
+		return e.processStructWithVisited(ctx, fieldVal, path, visited)
 	case reflect.Pointer:
 		if fieldVal.Type().Elem().Kind() == reflect.Struct {
-			return e.processStructWithVisited(ctx, fieldVal, visited)
+			return e.processStructWithVisited(ctx, fieldVal, path, visited)
 		}
 	case reflect.Slice:
-		return e.processSliceElementsWithVisited(ctx, fieldVal, visited)
+		if _, ok := orderedMapValue(fieldVal.Type()); ok {
+			e.mapDepth++
+			defer func() { e.mapDepth-- }()
+		}
+
+		return e.processSliceElementsWithVisited(ctx, fieldVal, path, visited)
 	case reflect.Map:
-		return e.processMapValuesWithVisited(ctx, fieldVal, visited)
+		e.mapDepth++
+		defer func() { e.mapDepth-- }()
+
+		return e.processMapValuesWithVisited(ctx, fieldVal, path, visited)
 	}
 
 	return nil
 }
 
 // processSliceElementsWithVisited recursively processes struct elements in a slice with cycle detection.
-func (e *Engine) processSliceElementsWithVisited(ctx context.Context, sliceVal reflect.Value, visited map[uintptr]bool) error {
+func (e *Engine) processSliceElementsWithVisited(ctx context.Context, sliceVal reflect.Value, path string, visited map[uintptr]bool) error {
 	for j := range sliceVal.Len() {
 		elem := sliceVal.Index(j)
 		// Check if element is a struct or pointer to struct
 		isStruct := elem.Kind() == reflect.Struct
 		isPtrToStruct := elem.Kind() == reflect.Pointer && !elem.IsNil() && elem.Elem().Kind() == reflect.Struct
 		if isStruct || isPtrToStruct {
-			if err := e.processStructWithVisited(ctx, elem, visited); err != nil {
+			if err := e.processStructWithVisited(ctx, elem, fmt.Sprintf("%s[%d]", path, j), visited); err != nil {
 				return err
 			}
 		}
@@ -218,7 +817,7 @@ func (e *Engine) processSliceElementsWithVisited(ctx context.Context, sliceVal r
 }
 
 // processMapValuesWithVisited recursively processes struct values in a map with cycle detection.
-func (e *Engine) processMapValuesWithVisited(ctx context.Context, mapVal reflect.Value, visited map[uintptr]bool) error {
+func (e *Engine) processMapValuesWithVisited(ctx context.Context, mapVal reflect.Value, path string, visited map[uintptr]bool) error {
 	iter := mapVal.MapRange()
 	for iter.Next() {
 		val := iter.Value()
@@ -226,7 +825,7 @@ func (e *Engine) processMapValuesWithVisited(ctx context.Context, mapVal reflect
 			// Map values are not addressable, so we need to copy, process, and set back
 			valCopy := reflect.New(val.Type()).Elem()
 			valCopy.Set(val)
-			if err := e.processStructWithVisited(ctx, valCopy, visited); err != nil {
+			if err := e.processStructWithVisited(ctx, valCopy, fmt.Sprintf("%s[%v]", path, iter.Key()), visited); err != nil {
 				return err
 			}
 			mapVal.SetMapIndex(iter.Key(), valCopy)
@@ -236,45 +835,299 @@ func (e *Engine) processMapValuesWithVisited(ctx context.Context, mapVal reflect
 	return nil
 }
 
+// fieldTags is the state of applyTags for one field, shared by the
+// methods applying each source.
+type fieldTags struct {
+	field     reflect.StructField
+	fieldVal  reflect.Value
+	parentVal reflect.Value
+	path      string
+	envKey    string
+	resolver  RefResolver
+	seeded    bool // a seeded value the document did not set
+	refMissed bool // a ref was tried and not found
+
+	templateData any
+}
+
+// data returns the template data of the parent struct, computed on first
+// use, for ref, dsn, and expr tags.
+func (ft *fieldTags) data() any {
+	if ft.templateData == nil {
+		ft.templateData = tags.StructToData(ft.parentVal)
+	}
+
+	return ft.templateData
+}
+
 // applyTags applies env, ref, and default tags to a field.
-func (e *Engine) applyTags(ctx context.Context, field reflect.StructField, fieldVal, parentVal reflect.Value) error {
-	// Apply Env Overrides
-	envApplied, err := tags.ProcessEnv(field, fieldVal, e.EnvPrefix)
+//
+// Sources are tried from the highest priority down (see Precedence), and
+// the first one that supplies a value wins.
+func (e *Engine) applyTags(ctx context.Context, field reflect.StructField, fieldVal, parentVal reflect.Value, path string) error {
+	if err := tags.CheckFudaTag(field); err != nil {
+		return fieldError(path, "fuda", err)
+	}
+
+	ft := &fieldTags{
+		field:     field,
+		fieldVal:  fieldVal,
+		parentVal: parentVal,
+		path:      path,
+		envKey:    e.envKey(field, path),
+		// A seeded value is a default unless the document set the field
+		seeded: e.Seeded && !fieldVal.IsZero() && !e.sourcePaths[path],
+	}
+
+	// Values from every source go through the field's decoder, if any
+	dec, _, err := e.fieldDecoder(field)
 	if err != nil {
-		return &types.FieldError{Path: field.Name, Tag: "env", Err: err}
+		return fieldError(path, "decoder", err)
 	}
+	ctx = types.WithFieldDecoder(ctx, dec)
 
-	// Lazy template data computation - only computed once if either ref or dsn needs it
-	var templateData any
-	getTemplateData := func() any {
-		if templateData == nil {
-			templateData = tags.StructToData(parentVal)
+	var tr *fieldTrace
+	if e.Trace != nil || e.TraceRecord != nil {
+		tr = newFieldTrace(field, fieldVal, ft.envKey, e.Flags)
+		tr.custom = e.customTagParts(field)
+	}
+
+	// Reject a malformed env tag even when a higher source wins
+	if tag := tags.Get(field, "env"); tag != "" && tag != "-" {
+		if _, err := tags.ParseEnvTag(tag); err != nil {
+			return fieldError(path, "env", err)
 		}
-		return templateData
 	}
 
-	// Resolve Refs
-	refResolved, err := tags.ProcessRef(ctx, field, fieldVal, parentVal, e.RefResolver, e.EnvPrefix, getTemplateData())
+	if ft.resolver, err = e.resolverFor(field); err != nil {
+		return fieldError(path, "refRetry", err)
+	}
+
+	var applied Source
+	for _, src := range e.precedence() {
+		ok, err := e.applySource(ctx, ft, src)
+		if err != nil {
+			return err
+		}
+		if ok {
+			applied = src
+
+			break
+		}
+	}
+
+	if ft.refMissed && e.Logger != nil {
+		if applied == SourceDefault {
+			e.logDebug(ctx, "ref not found, using default", "field", path)
+		} else {
+			e.logDebug(ctx, "ref not found, leaving field unset", "field", path)
+		}
+	}
+
+	if _, envSet := tags.LookupEnv(ft.envKey); !envSet && applied != SourceFlag {
+		e.checkRequiredEnv(field, path)
+	}
+
+	return e.finishTags(ctx, ft, applied, tr)
+}
+
+// applySource applies src to the field, reporting whether it supplied the
+// value.
+func (e *Engine) applySource(ctx context.Context, ft *fieldTags, src Source) (bool, error) {
+	switch src {
+	case SourceFlag:
+		return e.applyFlag(ctx, ft)
+	case SourceEnv:
+		return e.applyEnv(ctx, ft)
+	case SourceFile, SourceOverride:
+		set := !ft.fieldVal.IsZero() || (e.ExplicitZeros && e.sourcePaths[ft.path])
+
+		return set && !ft.seeded && e.docSource(ft.path) == src, nil
+	case SourceRef:
+		return e.applyRef(ctx, ft)
+	case SourceDefault:
+		return e.applyDefault(ctx, ft)
+	}
+
+	return false, nil
+}
+
+// applyFlag applies the flag bound to the field.
+func (e *Engine) applyFlag(ctx context.Context, ft *fieldTags) (bool, error) {
+	ok, err := tags.ProcessFlag(ctx, ft.field, ft.fieldVal, e.Flags)
+	if err != nil {
+		return false, fieldError(ft.path, "flag", err)
+	}
+
+	return ok, nil
+}
+
+// applyEnv applies the environment variable of the field.
+func (e *Engine) applyEnv(ctx context.Context, ft *fieldTags) (bool, error) {
+	if ft.envKey == "" {
+		return false, nil
+	}
+
+	envCtx, err := tags.WithEnvSeparators(ctx, ft.field)
+	if err != nil {
+		return false, fieldError(ft.path, "env", err)
+	}
+	ok, err := tags.ProcessEnvVar(envCtx, ft.envKey, ft.fieldVal)
+	if err != nil {
+		return false, fieldError(ft.path, "env", err)
+	}
+
+	return ok, nil
+}
+
+// applyRef applies the ref, refFrom, or secretName tag of the field.
+func (e *Engine) applyRef(ctx context.Context, ft *fieldTags) (bool, error) {
+	ok, err := replaceIfSet(ft.fieldVal, func() (bool, error) {
+		ok, err := tags.ProcessRef(ctx, ft.field, ft.fieldVal, ft.parentVal, ft.resolver, e.EnvPrefix, ft.data(), e.TagTemplateFuncs)
+		if ok || err != nil {
+			return ok, err
+		}
+
+		return tags.ProcessSecretName(ctx, ft.field, ft.fieldVal, ft.resolver, e.SecretsDir)
+	})
 	if err != nil {
-		return &types.FieldError{Path: field.Name, Tag: "ref", Err: err}
+		return false, fieldError(ft.path, "ref", err)
+	}
+	ft.refMissed = !ok && hasRefTag(ft.field)
+
+	return ok, nil
+}
+
+// applyDefault applies the default tag of the field, or keeps its seeded
+// value.
+func (e *Engine) applyDefault(ctx context.Context, ft *fieldTags) (bool, error) {
+	if ft.seeded {
+		return true, nil
 	}
 
-	// Apply Defaults (skip if env was applied or ref resolved a value)
-	// This ensures env-set zero values (like "false") aren't overwritten by defaults
-	if !envApplied && !refResolved {
-		if err := tags.ProcessDefault(field, fieldVal); err != nil {
-			return &types.FieldError{Path: field.Name, Tag: "default", Err: err}
+	// Defaults only count when they set a value, so env-set zero values
+	// (like "false") aren't overwritten by them
+	return replaceIfSet(ft.fieldVal, func() (bool, error) {
+		if err := tags.ProcessDefault(ctx, ft.field, ft.fieldVal); err != nil {
+			return false, fieldError(ft.path, "default", err)
+		}
+		if err := tags.ProcessEnvFallback(ctx, ft.field, ft.fieldVal); err != nil {
+			return false, fieldError(ft.path, "env", err)
+		}
+
+		return !ft.fieldVal.IsZero(), nil
+	})
+}
+
+// finishTags runs the tags computed from the applied value: KMS
+// decryption, custom tags, DSN templates, and expressions, then records the
+// field's trace, if any.
+func (e *Engine) finishTags(ctx context.Context, ft *fieldTags, applied Source, tr *fieldTrace) error {
+	// Decrypt KMS ciphertext from the file, env, or ref (defaults are plaintext)
+	if applied != SourceDefault {
+		if _, err := tags.ProcessKMS(ctx, ft.field, ft.fieldVal, e.Decrypters); err != nil {
+			return fieldError(ft.path, "kms", err)
 		}
 	}
 
-	// Process DSN templates (after all other tags, so referenced fields have their values)
-	if err := tags.ProcessDSN(ctx, field, fieldVal, parentVal, e.RefResolver, e.EnvPrefix, getTemplateData()); err != nil {
-		return &types.FieldError{Path: field.Name, Tag: "dsn", Err: err}
+	// Run custom tags, then DSN templates and expressions (after all other
+	// tags, so referenced fields have their values)
+	wasZero := ft.fieldVal.IsZero()
+	if err := e.applyTagProcessors(ctx, ft.field, ft.fieldVal, ft.parentVal, ft.path, ft.resolver); err != nil {
+		return err
+	}
+	if err := tags.ProcessDSN(ctx, ft.field, ft.fieldVal, ft.parentVal, ft.resolver, e.EnvPrefix, ft.data(), e.TagTemplateFuncs); err != nil {
+		return fieldError(ft.path, "dsn", err)
+	}
+	if err := tags.ProcessExpr(ctx, ft.field, ft.fieldVal, ft.parentVal, ft.resolver, e.EnvPrefix, ft.data(), e.TagTemplateFuncs); err != nil {
+		return fieldError(ft.path, "expr", err)
+	}
+	computed := wasZero && !ft.fieldVal.IsZero()
+
+	if tr != nil {
+		tr.record(applied, computed)
+		if e.Trace != nil {
+			tr.write(e.Trace, ft.path)
+		}
+		if e.TraceRecord != nil {
+			e.TraceRecord(tr.toRecord(ft.path))
+		}
 	}
 
 	return nil
 }
 
+// hasRefTag reports whether field is resolved by a ref, refFrom, or
+// secretName tag.
+func hasRefTag(field reflect.StructField) bool {
+	return tags.Get(field, "ref") != "" || tags.Get(field, "refFrom") != "" || tags.Get(field, "secretName") != ""
+}
+
+// replaceIfSet runs apply on a zeroed value, for sources that only fill
+// zero fields but may rank above the current value. The previous value is
+// restored if apply reports that it set nothing.
+func replaceIfSet(value reflect.Value, apply func() (bool, error)) (bool, error) {
+	var saved reflect.Value
+	if !value.IsZero() {
+		saved = reflect.New(value.Type()).Elem()
+		saved.Set(value)
+		value.SetZero()
+	}
+
+	ok, err := apply()
+	if err != nil || ok {
+		return ok, err
+	}
+	if saved.IsValid() {
+		value.Set(saved)
+	}
+
+	return false, nil
+}
+
+// envKey returns the environment variable read for field, or "" if none.
+func (e *Engine) envKey(field reflect.StructField, path string) string {
+	if key := e.autoEnvKey(field, path); key != "" {
+		return key
+	}
+
+	return tags.EnvKey(field, e.EnvPrefix)
+}
+
+// autoEnvKey returns the variable derived from path when AutoEnv is enabled
+// and field has no env tag. Elements of slices are mapped by index, as in
+// SERVERS_0_HOST, but map values are not, and `env:"-"` opts a field out.
+func (e *Engine) autoEnvKey(field reflect.StructField, path string) string {
+	if !e.AutoEnv || tags.Get(field, "env") != "" || e.mapDepth > 0 {
+		return ""
+	}
+	if !tags.AutoEnvSupported(field.Type) {
+		return ""
+	}
+
+	return e.EnvPrefix + tags.AutoEnvName(path)
+}
+
+// checkRequiredEnv records the env var of field as missing if its env tag
+// has the required option.
+func (e *Engine) checkRequiredEnv(field reflect.StructField, path string) {
+	et, err := tags.ParseEnvTag(tags.Get(field, "env"))
+	if err != nil || !et.Required {
+		return
+	}
+
+	message := fmt.Sprintf("required environment variable %s is not set", e.EnvPrefix+et.Name)
+	if name := tags.FlagName(field); name != "" && e.Flags != nil {
+		message += " (or set flag --" + name + ")"
+	}
+
+	e.fieldErrors = append(e.fieldErrors, types.FieldError{
+		Path:    path,
+		Tag:     "env",
+		Message: message,
+	})
+}
+
 // applyOverrides applies programmatic overrides to the source YAML.
 // Returns the modified source as YAML bytes.
 func (e *Engine) applyOverrides(source []byte) ([]byte, error) {
@@ -0,0 +1,90 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// checkEnvConflicts reports fields of struct type t that read the same
+// environment variable, such as `env:"DB_HOST"` on two nested structs, or a
+// tagged field whose variable matches the AutoEnv name of another. Without
+// this check one variable would silently set both fields. A wildcard like
+// `env:"FEATURE_*"` conflicts with every field reading a matching variable.
+//
+// Fields of slice and map elements are not checked, since every element
+// reads the same variables by design.
+func (e *Engine) checkEnvConflicts(t reflect.Type) error {
+	type reader struct{ key, path string }
+	var (
+		readers []reader // env var and path of each field, in field order
+		errs    []types.FieldError
+	)
+	e.walkEnvKeys(t, "", make(map[reflect.Type]bool), func(key, path string) {
+		for _, first := range readers {
+			name, ok := envOverlap(first.key, key)
+			if !ok {
+				continue
+			}
+			errs = append(errs, types.FieldError{
+				Path:    path,
+				Tag:     "env",
+				Message: fmt.Sprintf("environment variable %s is also read by field '%s'", name, first.path),
+			})
+
+			return
+		}
+		readers = append(readers, reader{key: key, path: path})
+	})
+
+	if len(errs) > 0 {
+		return &types.LoadError{Source: e.SourceName, Errors: errs}
+	}
+
+	return nil
+}
+
+// envOverlap reports whether keys a and b can read the same variable and
+// names it, preferring a specific name over a wildcard. Two wildcards
+// overlap if one matches the other, like FEATURE_* and FEATURE_UI_*.
+func envOverlap(a, b string) (string, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case tags.EnvMatches(a, b):
+		return b, true
+	case tags.EnvMatches(b, a):
+		return a, true
+	}
+
+	return "", false
+}
+
+// walkEnvKeys calls visit with the env var and path of every field of
+// struct type t that reads one, in field order. active guards against
+// recursive types.
+func (e *Engine) walkEnvKeys(t reflect.Type, path string, active map[reflect.Type]bool, visit func(key, path string)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || active[t] {
+		return
+	}
+	active[t] = true
+	defer delete(active, t)
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		if key := e.envKey(field, fieldPath); key != "" {
+			visit(key, fieldPath)
+		}
+		e.walkEnvKeys(field.Type, fieldPath, active, visit)
+	}
+}
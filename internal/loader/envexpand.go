@@ -0,0 +1,173 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExpandEnv replaces ${VAR} placeholders in source with environment
+// variables, following Docker Compose interpolation:
+//
+//	${VAR}          value of VAR, or empty if unset
+//	${VAR:-word}    word if VAR is unset or empty
+//	${VAR-word}     word if VAR is unset
+//	${VAR:+word}    word if VAR is set and not empty, otherwise empty
+//	${VAR+word}     word if VAR is set, otherwise empty
+//	${VAR:?message} error if VAR is unset or empty
+//	${VAR?message}  error if VAR is unset
+//	$$              a literal $
+//
+// Placeholders may be nested inside word. A $ not followed by { or $ is
+// kept as is.
+func ExpandEnv(source []byte) ([]byte, error) {
+	if !bytes.ContainsRune(source, '$') {
+		return source, nil
+	}
+
+	expanded, err := expandEnv(string(source))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(expanded), nil
+}
+
+// expandEnv expands the placeholders in s.
+func expandEnv(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("line %d: unterminated placeholder %q", lineOf(s, i), firstLine(s[i:]))
+			}
+
+			value, err := expandPlaceholder(s[i+2 : end])
+			if err != nil {
+				return "", fmt.Errorf("line %d: %w", lineOf(s, i), err)
+			}
+			sb.WriteString(value)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// expandPlaceholder evaluates the body of a ${...} placeholder.
+func expandPlaceholder(body string) (string, error) {
+	n := 0
+	for n < len(body) && isEnvNameByte(body[n], n == 0) {
+		n++
+	}
+	if n == 0 {
+		return "", fmt.Errorf("invalid placeholder ${%s}", body)
+	}
+
+	name, op := body[:n], body[n:]
+	value, set := os.LookupEnv(name)
+	if op == "" {
+		return value, nil
+	}
+
+	colon := strings.HasPrefix(op, ":")
+	op = strings.TrimPrefix(op, ":")
+	if op == "" {
+		return "", fmt.Errorf("invalid placeholder ${%s}", body)
+	}
+	word := op[1:]
+
+	// With a colon, an empty value counts as unset.
+	present := set && (!colon || value != "")
+
+	switch op[0] {
+	case '-':
+		if present {
+			return value, nil
+		}
+
+		return expandEnv(word)
+	case '+':
+		if present {
+			return expandEnv(word)
+		}
+
+		return "", nil
+	case '?':
+		if present {
+			return value, nil
+		}
+
+		msg, err := expandEnv(word)
+		if err != nil {
+			return "", err
+		}
+		if msg == "" {
+			return "", fmt.Errorf("required variable %s is missing a value", name)
+		}
+
+		return "", fmt.Errorf("required variable %s is missing a value: %s", name, msg)
+	default:
+		return "", fmt.Errorf("invalid placeholder ${%s}", body)
+	}
+}
+
+// closingBrace returns the index of the } closing a placeholder whose body
+// starts at start, skipping nested placeholders, or -1.
+func closingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '\n':
+			return -1
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// isEnvNameByte reports whether c may appear in a variable name.
+func isEnvNameByte(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	default:
+		return !first && c >= '0' && c <= '9'
+	}
+}
+
+// lineOf returns the 1-based line number of offset i in s.
+func lineOf(s string, i int) int {
+	return strings.Count(s[:i], "\n") + 1
+}
+
+// firstLine returns s up to the first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+
+	return line
+}
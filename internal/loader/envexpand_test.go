@@ -0,0 +1,74 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("EXP_HOST", "db.example.com")
+	t.Setenv("EXP_EMPTY", "")
+	t.Setenv("EXP_PORT", "6543")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "no placeholders", input: "host: localhost\n", want: "host: localhost\n"},
+		{name: "set variable", input: "host: ${EXP_HOST}", want: "host: db.example.com"},
+		{name: "unset variable", input: "host: ${EXP_UNSET}", want: "host: "},
+		{name: "default when unset", input: "port: ${EXP_UNSET:-5432}", want: "port: 5432"},
+		{name: "default when empty", input: "host: ${EXP_EMPTY:-localhost}", want: "host: localhost"},
+		{name: "dash default keeps empty", input: "host: ${EXP_EMPTY-localhost}", want: "host: "},
+		{name: "dash default when unset", input: "host: ${EXP_UNSET-localhost}", want: "host: localhost"},
+		{name: "value wins over default", input: "port: ${EXP_PORT:-5432}", want: "port: 6543"},
+		{name: "alternative when set", input: "tls: ${EXP_HOST:+true}", want: "tls: true"},
+		{name: "alternative when empty", input: "tls: ${EXP_EMPTY:+true}", want: "tls: "},
+		{name: "plus alternative when empty", input: "tls: ${EXP_EMPTY+true}", want: "tls: true"},
+		{name: "nested default", input: "url: ${EXP_UNSET:-http://${EXP_HOST}:${EXP_PORT}}", want: "url: http://db.example.com:6543"},
+		{name: "escaped dollar", input: "price: $$5 and $${EXP_HOST}", want: "price: $5 and ${EXP_HOST}"},
+		{name: "lone dollar kept", input: "pattern: ^a$ and $HOME", want: "pattern: ^a$ and $HOME"},
+		{name: "trailing dollar", input: "x: $", want: "x: $"},
+		{name: "required when set", input: "host: ${EXP_HOST:?host is required}", want: "host: db.example.com"},
+		{name: "multiple lines", input: "a: ${EXP_HOST}\nb: ${EXP_PORT}\n", want: "a: db.example.com\nb: 6543\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestExpandEnv_Errors(t *testing.T) {
+	t.Setenv("EXP_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "required unset", input: "a: 1\nhost: ${EXP_UNSET:?set the DB host}", wantErr: "line 2: required variable EXP_UNSET is missing a value: set the DB host"},
+		{name: "required empty", input: "host: ${EXP_EMPTY:?}", wantErr: "required variable EXP_EMPTY is missing a value"},
+		{name: "question mark allows empty", input: "host: ${EXP_UNSET?}", wantErr: "required variable EXP_UNSET is missing a value"},
+		{name: "unterminated", input: "host: ${EXP_HOST\nport: 1", wantErr: `line 1: unterminated placeholder "${EXP_HOST"`},
+		{name: "empty name", input: "host: ${}", wantErr: "invalid placeholder ${}"},
+		{name: "bad name", input: "host: ${1HOST}", wantErr: "invalid placeholder ${1HOST}"},
+		{name: "unknown operator", input: "host: ${EXP_HOST#x}", wantErr: "invalid placeholder ${EXP_HOST#x}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExpandEnv([]byte(tt.input))
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := ExpandEnv([]byte("host: ${EXP_EMPTY?}"))
+	require.NoError(t, err, "? without a colon accepts an empty value")
+}
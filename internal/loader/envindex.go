@@ -0,0 +1,55 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// maxEnvSliceLen bounds the length of slices grown from indexed env vars, so
+// a mistyped index such as APP_SERVERS_10000_HOST fails instead of
+// allocating thousands of elements.
+const maxEnvSliceLen = 1024
+
+// growSliceFromEnv appends elements to a slice of structs when AutoEnv is
+// enabled and an indexed variable such as APP_SERVERS_2_HOST names an index
+// past its end. New elements start as zero values; their fields are then set
+// from env vars and defaults like those of the existing elements.
+func (e *Engine) growSliceFromEnv(field reflect.StructField, fieldVal reflect.Value, path string) error {
+	if !e.AutoEnv || e.mapDepth > 0 || fieldVal.Kind() != reflect.Slice || tags.Get(field, "env") != "" {
+		return nil
+	}
+	if _, ok := orderedMapValue(fieldVal.Type()); ok {
+		return nil
+	}
+
+	elemType := fieldVal.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct || tags.AutoEnvSupported(structType) {
+		return nil // elements set from a single value are not walked
+	}
+
+	n, name := tags.EnvSliceLen(e.EnvPrefix + tags.AutoEnvName(path))
+	if n > maxEnvSliceLen {
+		return &types.FieldError{
+			Path:    path,
+			Tag:     "env",
+			Message: fmt.Sprintf("index of environment variable %s exceeds the limit of %d elements", name, maxEnvSliceLen),
+		}
+	}
+
+	for fieldVal.Len() < n {
+		elem := reflect.New(elemType).Elem()
+		if elemType.Kind() == reflect.Pointer {
+			elem = reflect.New(structType)
+		}
+		fieldVal.Set(reflect.Append(fieldVal, elem))
+	}
+
+	return nil
+}
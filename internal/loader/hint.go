@@ -0,0 +1,170 @@
+package loader
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// settingHint lists the ways to set the field at path, a dotted field path
+// such as "Database.Password" or "Servers[0].Host" in struct type t, as
+// derived from its tags: "set yaml key database.password, env DB_PASSWORD,
+// or file /run/secrets/db_password". It returns "" if path is not found.
+func (e *Engine) settingHint(t reflect.Type, path string) string {
+	field, parent, keyPath, ok := fieldAtPath(t, path)
+	if !ok {
+		return ""
+	}
+
+	var ways []string
+	if keyPath != "" {
+		ways = append(ways, "yaml key "+keyPath)
+	}
+	if key := e.envKey(field, path); key != "" {
+		ways = append(ways, "env "+key)
+	}
+	if name := tags.FlagName(field); name != "" {
+		ways = append(ways, "flag --"+name)
+	}
+	if refFrom := tags.Get(field, "refFrom"); refFrom != "" {
+		name, _, _ := tags.ParseRefTag(refFrom)
+		if _, _, srcKey, ok := fieldAtPath(parent, name); ok {
+			key := name
+			if srcKey != "" {
+				key = joinKeyPath(parentKeyPath(keyPath), srcKey)
+			}
+			ways = append(ways, "a ref URI in "+key)
+		}
+	}
+	if ref := tags.Get(field, "ref"); ref != "" {
+		uri, _, _ := tags.ParseRefTag(ref)
+		if file, ok := strings.CutPrefix(tags.NormalizeURI(uri), "file://"); ok {
+			ways = append(ways, "file "+file)
+		} else {
+			ways = append(ways, "ref "+uri)
+		}
+	}
+	if uri, _, err := tags.SecretNameURI(field, e.SecretsDir); err == nil && uri != "" {
+		ways = append(ways, "file "+strings.TrimPrefix(uri, "file://"))
+	}
+
+	switch len(ways) {
+	case 0:
+		return ""
+	case 1:
+		return "set " + ways[0]
+	case 2:
+		return "set " + ways[0] + " or " + ways[1]
+	default:
+		return "set " + strings.Join(ways[:len(ways)-1], ", ") + ", or " + ways[len(ways)-1]
+	}
+}
+
+// fieldAtPath finds the field at the dotted field path in struct type t. It
+// also returns the struct type holding the field and the YAML key path of
+// the field ("" if the field is not read from YAML).
+func fieldAtPath(t reflect.Type, path string) (reflect.StructField, reflect.Type, string, bool) {
+	var (
+		field   reflect.StructField
+		keyPath string
+		keyed   = true
+	)
+	segments := splitFieldPath(path)
+	for i, segment := range segments {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return reflect.StructField{}, nil, "", false
+		}
+
+		name, index, _ := strings.Cut(segment, "[")
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return reflect.StructField{}, nil, "", false
+		}
+		key, inline := fieldKeyName(f)
+		switch {
+		case key == "" && !inline:
+			keyed = false
+		case !inline:
+			keyPath = joinKeyPath(keyPath, key)
+		}
+
+		elemType := f.Type
+		if index != "" {
+			keyPath += "[" + index
+			for range strings.Count(segment, "[") {
+				for elemType.Kind() == reflect.Pointer {
+					elemType = elemType.Elem()
+				}
+				//nolint:exhaustive // only containers are indexed
+				switch elemType.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					elemType = elemType.Elem()
+				default:
+					return reflect.StructField{}, nil, "", false
+				}
+			}
+		}
+
+		field = f
+		if i < len(segments)-1 {
+			t = elemType
+		}
+	}
+	if !keyed {
+		keyPath = ""
+	}
+
+	return field, t, keyPath, true
+}
+
+// fieldKeyName returns the YAML key of field, or "" if it has none, and
+// whether its fields are inlined into the parent mapping.
+func fieldKeyName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if strings.Contains(","+opts+",", ",inline,") {
+		return "", true
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name, false
+}
+
+// splitFieldPath splits a dotted field path into its segments, keeping map
+// keys in brackets whole even if they contain dots.
+func splitFieldPath(path string) []string {
+	var segments []string
+	depth, start := 0, 0
+	for i := range len(path) {
+		switch path[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(segments, path[start:])
+}
+
+// parentKeyPath returns the YAML key path of the mapping holding keyPath.
+func parentKeyPath(keyPath string) string {
+	segments := splitFieldPath(keyPath)
+
+	return strings.Join(segments[:len(segments)-1], ".")
+}
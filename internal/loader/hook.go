@@ -0,0 +1,59 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+)
+
+// HookPoint is a stage of a load at which hooks run.
+type HookPoint int
+
+const (
+	// BeforeLoad hooks run before the source is read, with the target as
+	// passed to Load.
+	BeforeLoad HookPoint = iota
+	// AfterLoad hooks run once every field is set, after Setter methods
+	// and before validation.
+	AfterLoad
+	// AfterValidate hooks run after the target passed validation, as the
+	// last step of a successful load.
+	AfterValidate
+)
+
+// hookPoints is the number of hook points.
+const hookPoints = 3
+
+// Hook is called with the load context and the target at a HookPoint. An
+// error aborts the load.
+type Hook func(ctx context.Context, target any) error
+
+// String returns the name of p, such as "AfterLoad".
+func (p HookPoint) String() string {
+	switch p {
+	case BeforeLoad:
+		return "BeforeLoad"
+	case AfterLoad:
+		return "AfterLoad"
+	case AfterValidate:
+		return "AfterValidate"
+	default:
+		return fmt.Sprintf("HookPoint(%d)", int(p))
+	}
+}
+
+// Valid reports whether p is one of the defined hook points.
+func (p HookPoint) Valid() bool {
+	return p >= 0 && p < hookPoints
+}
+
+// runHooks calls the hooks registered for point in order, stopping at the
+// first error.
+func (e *Engine) runHooks(ctx context.Context, point HookPoint, target any) error {
+	for _, hook := range e.Hooks[point] {
+		if err := hook(ctx, target); err != nil {
+			return fmt.Errorf("%s hook: %w", point, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// includeTag marks a node replaced by the contents of a file.
+const includeTag = "!include"
+
+// ResolveIncludes replaces every `!include <file>` node of source with the
+// document in that file, read from fsys:
+//
+//	database: !include database.yaml
+//	servers:
+//	  - !include servers/a.yaml
+//
+// A relative file is resolved against the directory of path, the file source
+// was read from (the working directory if path is empty). Included files may
+// include others; an include cycle is an error. Source without includes is
+// returned unchanged.
+func ResolveIncludes(fsys afero.Fs, source []byte, path string) ([]byte, error) {
+	if !bytes.Contains(source, []byte(includeTag)) {
+		return source, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, err
+	}
+
+	var stack []string
+	if path != "" {
+		stack = append(stack, filepath.Clean(path))
+	}
+	if err := resolveIncludeNodes(fsys, &doc, path, stack); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(&doc)
+}
+
+// resolveIncludeNodes splices the includes of node, a node of the file at
+// path. stack holds the files being included, outermost first.
+func resolveIncludeNodes(fsys afero.Fs, node *yaml.Node, path string, stack []string) error {
+	if node.Tag != includeTag {
+		for _, child := range node.Content {
+			if err := resolveIncludeNodes(fsys, child, path, stack); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return fmt.Errorf("%s: %s needs a file path", includePos(path, node), includeTag)
+	}
+
+	file := node.Value
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(filepath.Dir(path), file)
+	}
+	file = filepath.Clean(file)
+	if slices.Contains(stack, file) {
+		return fmt.Errorf("include cycle: %s", strings.Join(append(stack, file), " -> "))
+	}
+
+	data, err := afero.ReadFile(fsys, file)
+	if err != nil {
+		return fmt.Errorf("%s: failed to include %s: %w", includePos(path, node), node.Value, err)
+	}
+	if data, err = DecodeSource(data); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", file, err)
+	}
+
+	var included yaml.Node
+	if err := yaml.Unmarshal(data, &included); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", file, err)
+	}
+	if len(included.Content) == 0 {
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+
+		return nil
+	}
+
+	root := included.Content[0]
+	if err := resolveIncludeNodes(fsys, root, file, append(slices.Clip(stack), file)); err != nil {
+		return err
+	}
+	*node = *root
+
+	return nil
+}
+
+// includePos returns the position of node for error messages, e.g.
+// "db.yaml:3" or "line 3" when path is empty.
+func includePos(path string, node *yaml.Node) string {
+	if path == "" {
+		return fmt.Sprintf("line %d", node.Line)
+	}
+
+	return fmt.Sprintf("%s:%d", path, node.Line)
+}
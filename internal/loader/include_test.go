@@ -0,0 +1,88 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func includeFs(t *testing.T, files map[string]string) afero.Fs {
+	t.Helper()
+
+	fsys := afero.NewMemMapFs()
+	for name, content := range files {
+		require.NoError(t, afero.WriteFile(fsys, name, []byte(content), 0o644))
+	}
+
+	return fsys
+}
+
+func TestResolveIncludes(t *testing.T) {
+	fsys := includeFs(t, map[string]string{
+		"/etc/app/db.yaml":            "host: db.local\nport: 5432\n",
+		"/etc/app/shared/log.yaml":    "level: info\nformat: !include format.yaml\n",
+		"/etc/app/shared/format.yaml": "json\n",
+		"/etc/app/empty.yaml":         "",
+	})
+
+	source := []byte(`
+database: !include db.yaml
+logging:
+  <<: !include shared/log.yaml
+  level: debug
+hosts:
+  - !include /etc/app/shared/format.yaml
+empty: !include empty.yaml
+`)
+
+	out, err := ResolveIncludes(fsys, source, "/etc/app/config.yaml")
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, yaml.Unmarshal(out, &got))
+	assert.Equal(t, map[string]any{
+		"database": map[string]any{"host": "db.local", "port": 5432},
+		"logging":  map[string]any{"level": "debug", "format": "json"},
+		"hosts":    []any{"json"},
+		"empty":    nil,
+	}, got)
+}
+
+func TestResolveIncludes_NoIncludes(t *testing.T) {
+	source := []byte("# comment kept\nname: app\n")
+
+	out, err := ResolveIncludes(afero.NewMemMapFs(), source, "")
+	require.NoError(t, err)
+	assert.Equal(t, source, out)
+}
+
+func TestResolveIncludes_Errors(t *testing.T) {
+	fsys := includeFs(t, map[string]string{
+		"/a.yaml": "b: !include b.yaml\n",
+		"/b.yaml": "a: !include a.yaml\n",
+	})
+
+	tests := []struct {
+		name    string
+		source  string
+		path    string
+		wantErr string
+	}{
+		{"cycle", "x: !include a.yaml\n", "/config.yaml", "include cycle: /config.yaml -> /a.yaml -> /b.yaml -> /a.yaml"},
+		{"cycle from reader", "x: !include /a.yaml\n", "", "include cycle: /a.yaml -> /b.yaml -> /a.yaml"},
+		{"self", "x: !include config.yaml\n", "/config.yaml", "include cycle: /config.yaml -> /config.yaml"},
+		{"missing", "x: !include nope.yaml\n", "/config.yaml", "/config.yaml:1: failed to include nope.yaml"},
+		{"not a path", "x: !include [a.yaml]\n", "", "line 1: !include needs a file path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveIncludes(fsys, []byte(tt.source), tt.path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
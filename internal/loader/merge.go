@@ -0,0 +1,72 @@
+package loader
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layer is one document of a layered configuration, such as base.yaml with
+// prod.yaml merged over it.
+type Layer struct {
+	Name string
+	Data []byte
+}
+
+// MergeLayers deep-merges the YAML (or JSON) documents of layers in order and
+// returns the result as YAML. Mappings are merged key by key; any other value
+// in a later layer, including a sequence, replaces the earlier one. Empty
+// documents are skipped, and nil is returned if every layer is empty.
+func MergeLayers(layers []Layer) ([]byte, error) {
+	var merged *yaml.Node
+	for _, layer := range layers {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(layer.Data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", layer.Name, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		if merged == nil {
+			merged = doc.Content[0]
+		} else {
+			merged = mergeNodes(merged, doc.Content[0])
+		}
+	}
+
+	if merged == nil {
+		return nil, nil
+	}
+
+	return yaml.Marshal(merged)
+}
+
+// mergeNodes merges overlay into base and returns the merged node.
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		if j := mappingKeyIndex(base, key.Value); j >= 0 {
+			base.Content[j+1] = mergeNodes(base.Content[j+1], value)
+		} else {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+
+	return base
+}
+
+// mappingKeyIndex returns the index of key in mapping node m, or -1.
+func mappingKeyIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,71 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeLayers(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []string
+		want   map[string]any
+	}{
+		{
+			name:   "nested mappings merge key by key",
+			layers: []string{"db:\n  host: base\n  port: 5432\n", "db:\n  host: prod\n"},
+			want:   map[string]any{"db": map[string]any{"host": "prod", "port": 5432}},
+		},
+		{
+			name:   "sequences are replaced",
+			layers: []string{"hosts: [a, b]\n", "hosts: [c]\n"},
+			want:   map[string]any{"hosts": []any{"c"}},
+		},
+		{
+			name:   "scalar replaces mapping",
+			layers: []string{"db:\n  host: base\n", "db: ~\n"},
+			want:   map[string]any{"db": nil},
+		},
+		{
+			name:   "empty layers are skipped",
+			layers: []string{"", "name: app\n", ""},
+			want:   map[string]any{"name": "app"},
+		},
+		{
+			name:   "json layers",
+			layers: []string{`{"name": "app", "port": 80}`, `{"port": 8080}`},
+			want:   map[string]any{"name": "app", "port": 8080},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layers := make([]Layer, len(tt.layers))
+			for i, data := range tt.layers {
+				layers[i] = Layer{Name: "layer", Data: []byte(data)}
+			}
+
+			merged, err := MergeLayers(layers)
+			require.NoError(t, err)
+
+			var got map[string]any
+			require.NoError(t, yaml.Unmarshal(merged, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeLayers_Empty(t *testing.T) {
+	merged, err := MergeLayers([]Layer{{Name: "a.yaml"}, {Name: "b.yaml", Data: []byte("\n")}})
+	require.NoError(t, err)
+	assert.Nil(t, merged)
+}
+
+func TestMergeLayers_InvalidLayer(t *testing.T) {
+	_, err := MergeLayers([]Layer{{Name: "base.yaml", Data: []byte("a: 1\n")}, {Name: "prod.yaml", Data: []byte("a: [\n")}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "prod.yaml")
+}
@@ -0,0 +1,292 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source identifies where a field value comes from, for ordering sources
+// with Engine.Precedence.
+type Source int
+
+const (
+	// SourceDefault is the default tag, including env tag fallbacks.
+	SourceDefault Source = iota + 1
+	// SourceRef is the ref and refFrom tags.
+	SourceRef
+	// SourceFile is the config file or other source document.
+	SourceFile
+	// SourceOverride is the programmatic overrides.
+	SourceOverride
+	// SourceEnv is the env tag, or the automatic env name.
+	SourceEnv
+	// SourceFlag is the flag tag.
+	SourceFlag
+)
+
+// DefaultPrecedence is the built-in order of sources, lowest first.
+var DefaultPrecedence = []Source{SourceDefault, SourceRef, SourceFile, SourceOverride, SourceEnv, SourceFlag}
+
+// String returns the source name, as used in error messages.
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceRef:
+		return "ref"
+	case SourceFile:
+		return "file"
+	case SourceOverride:
+		return "override"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "Source(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// ValidatePrecedence checks that order lists every source exactly once.
+func ValidatePrecedence(order []Source) error {
+	seen := make(map[Source]bool, len(order))
+	for _, s := range order {
+		if s < SourceDefault || s > SourceFlag {
+			return fmt.Errorf("unknown source %v", s)
+		}
+		if seen[s] {
+			return fmt.Errorf("source %v listed more than once", s)
+		}
+		seen[s] = true
+	}
+
+	var missing []string
+	for _, s := range DefaultPrecedence {
+		if !seen[s] {
+			missing = append(missing, s.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing sources: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// precedence returns the sources from highest to lowest priority.
+func (e *Engine) precedence() []Source {
+	order := e.Precedence
+	if order == nil {
+		order = DefaultPrecedence
+	}
+
+	desc := make([]Source, len(order))
+	for i, s := range order {
+		desc[len(order)-1-i] = s
+	}
+
+	return desc
+}
+
+// ranksAbove reports whether a has a higher priority than b.
+func (e *Engine) ranksAbove(a, b Source) bool {
+	for _, s := range e.precedence() {
+		switch s {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+
+	return false
+}
+
+// mergeDocuments combines the source document and the overrides, letting
+// the higher-ranked of the two win where both set a key. With a custom
+// precedence it also records the fields set by the winning document, so
+// docSource can tell file values from overrides.
+func (e *Engine) mergeDocuments(source []byte, target reflect.Type) ([]byte, error) {
+	if !e.ranksAbove(SourceFile, SourceOverride) && e.Precedence == nil {
+		return e.applyOverrides(source)
+	}
+
+	overrides, err := e.applyOverrides(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	top, topDoc := SourceOverride, overrides
+	var merged []byte
+	if e.ranksAbove(SourceFile, SourceOverride) {
+		top, topDoc = SourceFile, source
+		merged, err = MergeLayers([]Layer{{Name: "overrides", Data: overrides}, {Name: e.SourceName, Data: source}})
+	} else {
+		merged, err = e.applyOverrides(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(topDoc, &node); err != nil {
+		return nil, err
+	}
+	topNode := &node
+	if e.Section != "" {
+		if topNode, err = selectSection(topNode, e.Section); err != nil {
+			return nil, err
+		}
+	}
+	e.docTop = top
+	e.docPaths = make(map[string]bool)
+	collectFieldPaths(topNode, target, "", e.docPaths)
+
+	return merged, nil
+}
+
+// docSource returns the document, file or overrides, that supplied the
+// decoded value of the field at path.
+func (e *Engine) docSource(path string) Source {
+	if e.docPaths == nil {
+		if len(e.Overrides) > 0 && e.ranksAbove(SourceOverride, SourceFile) {
+			return SourceOverride
+		}
+
+		return SourceFile
+	}
+
+	if e.docPaths[path] {
+		return e.docTop
+	}
+	if e.docTop == SourceFile {
+		return SourceOverride
+	}
+
+	return SourceFile
+}
+
+// collectFieldPaths adds to paths the Go field path of every value set by
+// node, decoded as type t. Paths use the format of processStructWithVisited.
+// Null values, as in `port: ~`, leave their fields unset.
+func collectFieldPaths(node *yaml.Node, t reflect.Type, path string, paths map[string]bool) {
+	if node == nil || t == nil || isNullNode(node) {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if path != "" {
+		paths[path] = true
+	}
+	if valueType, ok := orderedMapValue(t); ok && node.Kind == yaml.MappingNode {
+		// Entries are processed as slice elements with a Value field
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			collectFieldPaths(node.Content[i+1], valueType, fmt.Sprintf("%s[%d].Value", path, i/2), paths)
+		}
+
+		return
+	}
+	if decodesItself(t) {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectFieldPaths(child, t, path, paths)
+		}
+	case yaml.SequenceNode:
+		collectSequencePaths(node, t, path, paths)
+	case yaml.MappingNode:
+		collectMappingPaths(node, t, path, paths)
+	case yaml.ScalarNode, yaml.AliasNode:
+		// Leaf values
+	}
+}
+
+// collectSequencePaths adds the paths of the elements of a sequence node
+// decoded as slice or array type t.
+func collectSequencePaths(node *yaml.Node, t reflect.Type, path string, paths map[string]bool) {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return
+	}
+	for i, child := range node.Content {
+		collectFieldPaths(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), paths)
+	}
+}
+
+// collectMappingPaths adds the paths of the values of a mapping node decoded
+// as map or struct type t.
+func collectMappingPaths(node *yaml.Node, t reflect.Type, path string, paths map[string]bool) {
+	switch t.Kind() { //nolint:exhaustive // only structs and maps have keys
+	case reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			collectFieldPaths(node.Content[i+1], t.Elem(), fmt.Sprintf("%s[%s]", path, node.Content[i].Value), paths)
+		}
+	case reflect.Struct:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if name, ft, ok := fieldForKey(t, node.Content[i].Value); ok {
+				collectFieldPaths(node.Content[i+1], ft, joinPath(path, name), paths)
+			}
+		}
+	}
+}
+
+// isNullNode reports whether node, or the node an alias refers to, is a
+// null scalar.
+func isNullNode(node *yaml.Node) bool {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+// fieldForKey returns the dotted Go path, relative to struct type t, and
+// the type of the field yaml.v3 decodes key into, looking through inline
+// structs.
+func fieldForKey(t reflect.Type, key string) (string, reflect.Type, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if strings.Contains(","+opts+",", ",inline,") {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if sub, subType, ok := fieldForKey(ft, key); ok {
+					return joinPath(field.Name, sub), subType, true
+				}
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key {
+			return field.Name, field.Type, true
+		}
+	}
+
+	return "", nil, false
+}
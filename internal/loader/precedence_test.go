@@ -0,0 +1,69 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCollectFieldPaths(t *testing.T) {
+	type server struct {
+		Host string `yaml:"host"`
+	}
+	type common struct {
+		Region string `yaml:"region"`
+	}
+	type config struct {
+		common  `yaml:",inline"`
+		Name    string            `yaml:"name"`
+		Servers []server          `yaml:"servers"`
+		Labels  map[string]string `yaml:"labels"`
+		Primary *server           `yaml:"primary"`
+		Skip    string            `yaml:"-"`
+		Port    int               `yaml:"port"`
+		Unset   *server           `yaml:"unset"`
+	}
+
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`
+region: eu
+name: app
+servers:
+  - host: a
+  - {}
+labels:
+  env: prod
+primary:
+  host: p
+unknown: x
+port: 0
+unset: ~
+`), &node))
+
+	paths := make(map[string]bool)
+	collectFieldPaths(&node, reflect.TypeFor[config](), "", paths)
+
+	assert.Equal(t, map[string]bool{
+		"common.Region":   true,
+		"Name":            true,
+		"Servers":         true,
+		"Servers[0]":      true,
+		"Servers[0].Host": true,
+		"Servers[1]":      true,
+		"Labels":          true,
+		"Labels[env]":     true,
+		"Primary":         true,
+		"Primary.Host":    true,
+		"Port":            true,
+	}, paths, "null values should leave their fields unset")
+}
+
+func TestValidatePrecedence(t *testing.T) {
+	require.NoError(t, ValidatePrecedence(DefaultPrecedence))
+	require.ErrorContains(t, ValidatePrecedence(nil), "missing sources: default, ref, file, override, env, flag")
+	require.ErrorContains(t, ValidatePrecedence([]Source{SourceEnv, SourceEnv}), "listed more than once")
+	require.ErrorContains(t, ValidatePrecedence([]Source{0}), "unknown source Source(0)")
+}
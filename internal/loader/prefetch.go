@@ -0,0 +1,167 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// prefetchResult holds the outcome of a single prefetched ref.
+type prefetchResult struct {
+	content []byte
+	err     error
+}
+
+// prefetchedResolver serves refs resolved ahead of time and delegates
+// everything else to the wrapped resolver.
+type prefetchedResolver struct {
+	inner   RefResolver
+	results map[string]prefetchResult
+}
+
+var _ RefResolver = (*prefetchedResolver)(nil)
+
+// Resolve returns the prefetched result for uri, or resolves it on demand.
+func (r *prefetchedResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	if res, ok := r.results[uri]; ok {
+		return res.content, res.err
+	}
+
+	return r.inner.Resolve(ctx, uri)
+}
+
+// prefetchRefs resolves independent ref URIs in target concurrently with at
+// most e.RefConcurrency workers, returning a resolver that serves the results.
+//
+// Only static ref tags (without ${...} templates) on fields that are still
+// zero and not overridden by env or flags are prefetched; templated refs and refFrom
+// depend on other fields and are resolved sequentially during processing.
+func (e *Engine) prefetchRefs(ctx context.Context, target reflect.Value) RefResolver {
+	seen := make(map[string]struct{})
+	var uris []string
+	e.collectRefURIs(target, "", make(map[uintptr]bool), func(uri string) {
+		if _, ok := seen[uri]; !ok {
+			seen[uri] = struct{}{}
+			uris = append(uris, uri)
+		}
+	})
+
+	if len(uris) < 2 {
+		return e.RefResolver
+	}
+
+	results := make(map[string]prefetchResult, len(uris))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, e.RefConcurrency)
+
+	for _, uri := range uris {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			content, err := e.RefResolver.Resolve(ctx, uri)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				// Leave other failures to the sequential pass, which applies
+				// the retry policy and reports the error for the right field.
+				e.logDebug(ctx, "ref prefetch failed, resolving it again", "uri", uri, "error", err)
+
+				return
+			}
+
+			mu.Lock()
+			results[uri] = prefetchResult{content: content, err: err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return &prefetchedResolver{inner: e.RefResolver, results: results}
+}
+
+// collectRefURIs walks v, the value at field path path, and reports every
+// prefetchable ref URI to add. Paths and map depth are tracked as in
+// processStructWithVisited, so AutoEnv derives the same variables.
+func (e *Engine) collectRefURIs(v reflect.Value, path string, visited map[uintptr]bool, add func(string)) {
+	//nolint:exhaustive // Only struct-like types can carry ref tags
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		e.collectRefURIs(v.Elem(), path, visited, add)
+	case reflect.Slice:
+		if _, ok := orderedMapValue(v.Type()); ok {
+			e.mapDepth++
+			defer func() { e.mapDepth-- }()
+		}
+		for i := range v.Len() {
+			e.collectRefURIs(v.Index(i), fmt.Sprintf("%s[%d]", path, i), visited, add)
+		}
+	case reflect.Map:
+		e.mapDepth++
+		defer func() { e.mapDepth-- }()
+
+		iter := v.MapRange()
+		for iter.Next() {
+			e.collectRefURIs(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), visited, add)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range v.NumField() {
+			field := t.Field(i)
+			fieldVal := v.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := joinPath(path, field.Name)
+			e.collectRefURIs(fieldVal, fieldPath, visited, add)
+
+			if uri, ok := e.prefetchableRef(field, fieldVal, fieldPath); ok {
+				add(uri)
+			}
+		}
+	}
+}
+
+// prefetchableRef returns the normalized ref URI of field, at path, if it
+// will be resolved during processing and does not depend on other fields.
+func (e *Engine) prefetchableRef(field reflect.StructField, fieldVal reflect.Value, path string) (string, bool) {
+	ref := tags.Get(field, "ref")
+	if ref == "" || strings.Contains(ref, "${") || tags.Get(field, "refFrom") != "" {
+		return "", false
+	}
+
+	if !fieldVal.IsZero() {
+		return "", false
+	}
+
+	if key := e.envKey(field, path); key != "" {
+		if _, ok := tags.LookupEnv(key); ok {
+			return "", false
+		}
+	}
+
+	if name := tags.FlagName(field); name != "" && e.Flags != nil {
+		if _, ok := e.Flags(name); ok {
+			return "", false
+		}
+	}
+
+	uri, _, err := tags.ParseRefTag(ref)
+	if err != nil {
+		return "", false
+	}
+
+	return tags.NormalizeURI(uri), true
+}
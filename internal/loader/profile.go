@@ -0,0 +1,154 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// profilesKey is the top-level key holding the profiles of a document.
+	profilesKey = "profiles"
+	// defaultProfile is the profile every selected profile is merged over.
+	defaultProfile = "default"
+)
+
+// ApplyProfile resolves the profiles of source, a document with a top-level
+// profiles mapping:
+//
+//	server:
+//	  port: 8080
+//	profiles:
+//	  default:
+//	    database: {host: localhost, pool: 5}
+//	  prod:
+//	    database: {host: db.prod.internal}
+//
+// The profiles key is removed, and the default profile, then profile, are
+// deep-merged over the rest of the document (see MergeLayers). Anchors and
+// merge keys are expanded first, so profiles may build on each other with
+// `<<: *default`. found reports whether the document defines profile.
+// Source without a profiles key is returned unchanged.
+func ApplyProfile(source []byte, profile string) (result []byte, found bool, err error) {
+	if !bytes.Contains(source, []byte(profilesKey)) {
+		return source, false, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, false, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return source, false, nil
+	}
+	root := expandNode(doc.Content[0])
+	i := mappingKeyIndex(root, profilesKey)
+	if i < 0 {
+		return source, false, nil
+	}
+
+	profiles := root.Content[i+1]
+	root.Content = append(root.Content[:i], root.Content[i+2:]...)
+	if isNullNode(profiles) {
+		return marshalProfile(root, false)
+	}
+	if profiles.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("%s is not a mapping", profilesKey)
+	}
+
+	if base := mappingValue(profiles, defaultProfile); base != nil && !isNullNode(base) {
+		root = mergeNodes(root, base)
+	}
+	selected := mappingValue(profiles, profile)
+	if selected != nil && profile != defaultProfile && !isNullNode(selected) {
+		root = mergeNodes(root, selected)
+	}
+
+	return marshalProfile(root, selected != nil)
+}
+
+// marshalProfile returns the document of root, the result of ApplyProfile.
+func marshalProfile(root *yaml.Node, found bool) ([]byte, bool, error) {
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, found, nil
+}
+
+// applyProfile resolves Profile in each of layers (see ApplyProfile). At
+// least one layer must define the profile.
+func (e *Engine) applyProfile(layers []Layer) ([]Layer, error) {
+	resolved := make([]Layer, len(layers))
+	var found bool
+	for i, layer := range layers {
+		data, ok, err := ApplyProfile(layer.Data, e.Profile)
+		if err != nil {
+			if layer.Name != "" {
+				return nil, fmt.Errorf("failed to apply profile %q to %s: %w", e.Profile, layer.Name, err)
+			}
+
+			return nil, fmt.Errorf("failed to apply profile %q: %w", e.Profile, err)
+		}
+		found = found || ok
+		resolved[i] = Layer{Name: layer.Name, Data: data}
+	}
+	if !found {
+		return nil, fmt.Errorf("profile %q not found under %s", e.Profile, profilesKey)
+	}
+
+	return resolved, nil
+}
+
+// expandNode returns a copy of node with aliases replaced by copies of the
+// nodes they refer to and merge keys (`<<`) merged into their mappings, so
+// the copy can be moved around the document without its anchors.
+func expandNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return expandNode(node.Alias)
+	}
+
+	out := *node
+	out.Anchor = ""
+	out.Content = nil
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			out.Content = append(out.Content, expandNode(child))
+		}
+
+		return &out
+	}
+
+	// Keys merged in with `<<` come first, an earlier merged mapping winning
+	// over a later one; the mapping's own keys override them
+	var explicit []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], expandNode(node.Content[i+1])
+		if key.ShortTag() != "!!merge" {
+			explicit = append(explicit, expandNode(key), value)
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, src := range sources {
+			for j := 0; j+1 < len(src.Content); j += 2 {
+				if mappingKeyIndex(&out, src.Content[j].Value) < 0 {
+					out.Content = append(out.Content, src.Content[j], src.Content[j+1])
+				}
+			}
+		}
+	}
+	for i := 0; i+1 < len(explicit); i += 2 {
+		if j := mappingKeyIndex(&out, explicit[i].Value); j >= 0 {
+			out.Content[j+1] = explicit[i+1]
+		} else {
+			out.Content = append(out.Content, explicit[i], explicit[i+1])
+		}
+	}
+
+	return &out
+}
@@ -0,0 +1,71 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		profile   string
+		want      map[string]any
+		wantFound bool
+	}{
+		{
+			name:      "profile over default over document",
+			source:    "a: 1\nb: 1\nprofiles:\n  default: {b: 2, c: 2}\n  prod: {c: 3}\n",
+			profile:   "prod",
+			want:      map[string]any{"a": 1, "b": 2, "c": 3},
+			wantFound: true,
+		},
+		{
+			name:      "missing profile keeps default",
+			source:    "profiles:\n  default: {a: 1}\n",
+			profile:   "prod",
+			want:      map[string]any{"a": 1},
+			wantFound: false,
+		},
+		{
+			name:      "anchor outside profiles",
+			source:    "shared: &shared {host: h, port: 1}\nprofiles:\n  prod:\n    db:\n      <<: *shared\n      port: 2\n",
+			profile:   "prod",
+			want:      map[string]any{"shared": map[string]any{"host": "h", "port": 1}, "db": map[string]any{"host": "h", "port": 2}},
+			wantFound: true,
+		},
+		{
+			name:      "merge sequence, earlier wins",
+			source:    "profiles:\n  x: &x {a: x, b: x}\n  y: &y {a: y, c: y}\n  prod:\n    <<: [*x, *y]\n",
+			profile:   "prod",
+			want:      map[string]any{"a": "x", "b": "x", "c": "y"},
+			wantFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, found, err := ApplyProfile([]byte(tt.source), tt.profile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFound, found)
+
+			var got map[string]any
+			require.NoError(t, yaml.Unmarshal(data, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyProfile_NoProfiles(t *testing.T) {
+	source := []byte("a: 1 # profiles are not used\n")
+	data, found, err := ApplyProfile(source, "prod")
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, source, data)
+
+	_, _, err = ApplyProfile([]byte("profiles: [prod]\n"), "prod")
+	require.Error(t, err)
+}
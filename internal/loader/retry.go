@@ -0,0 +1,85 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// defaultRefRetryBackoff is the initial backoff when retries are enabled
+// via the refRetry tag without a global backoff.
+const defaultRefRetryBackoff = 100 * time.Millisecond
+
+// retryResolver retries transient resolution failures with exponential backoff.
+type retryResolver struct {
+	inner    RefResolver
+	attempts int
+	backoff  time.Duration
+}
+
+var _ RefResolver = (*retryResolver)(nil)
+
+// Resolve resolves uri, retrying failures other than not-found and context
+// cancellation up to r.attempts times in total.
+func (r *retryResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	delay := r.backoff
+	for attempt := 1; ; attempt++ {
+		content, err := r.inner.Resolve(ctx, uri)
+		if err == nil || !isRetryable(err) {
+			return content, err
+		}
+		if attempt >= r.attempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("retry aborted after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// isRetryable reports whether a resolution error may be transient.
+func isRetryable(err error) bool {
+	return !errors.Is(err, os.ErrNotExist) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// resolverFor returns the resolver to use for field, wrapped with the retry
+// policy from its refRetry tag or the engine-wide setting.
+func (e *Engine) resolverFor(field reflect.StructField) (RefResolver, error) {
+	if e.RefResolver == nil {
+		return nil, nil
+	}
+
+	attempts := e.RefRetryAttempts
+	if tag := tags.Get(field, "refRetry"); tag != "" {
+		n, err := strconv.Atoi(tag)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid refRetry value %q: must be a positive integer", tag)
+		}
+		attempts = n
+	}
+
+	if attempts <= 1 {
+		return e.RefResolver, nil
+	}
+
+	backoff := e.RefRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRefRetryBackoff
+	}
+
+	return &retryResolver{inner: e.RefResolver, attempts: attempts, backoff: backoff}, nil
+}
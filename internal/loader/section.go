@@ -0,0 +1,69 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// selectSection returns the subtree of node at section, a dot-separated path
+// of mapping keys such as "services.api", as a document node. It returns nil
+// if node is nil or has no such key, and an error if a value on the path is
+// not a mapping.
+func selectSection(node *yaml.Node, section string) (*yaml.Node, error) {
+	if node == nil {
+		return nil, nil //nolint:nilnil // no document
+	}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, nil //nolint:nilnil // empty document
+		}
+		node = node.Content[0]
+	}
+
+	keys := strings.Split(section, ".")
+	for i, key := range keys {
+		node = resolveAlias(node)
+		if isNullNode(node) {
+			return nil, nil //nolint:nilnil // section not set
+		}
+		if node.Kind != yaml.MappingNode {
+			if i == 0 {
+				return nil, fmt.Errorf("section %q: document is not a mapping", section)
+			}
+
+			return nil, fmt.Errorf("section %q: %q is not a mapping", section, strings.Join(keys[:i], "."))
+		}
+		node = mappingValue(node, key)
+		if node == nil {
+			return nil, nil //nolint:nilnil // section not set
+		}
+	}
+	node = resolveAlias(node)
+	if isNullNode(node) {
+		return nil, nil //nolint:nilnil // section not set
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}, nil
+}
+
+// mappingValue returns the value of key in the mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// resolveAlias returns the node an alias node refers to, or node itself.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return node.Alias
+	}
+
+	return node
+}
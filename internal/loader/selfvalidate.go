@@ -0,0 +1,120 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+var validatorType = reflect.TypeFor[types.Validator]()
+
+// ValidateSelf calls Validate on target and every struct below it that
+// implements types.Validator, nested structs first, and returns their
+// errors. Errors of nested structs are FieldErrors at the struct's path; a
+// returned FieldError's Path is relative to the struct, and every error of
+// an errors.Join is reported on its own.
+func ValidateSelf(target any) []error {
+	var errs []error
+	validateSelf(reflect.ValueOf(target), "", make(map[uintptr]bool), &errs)
+
+	return errs
+}
+
+// validateSelf validates the structs in v, which is at path.
+func validateSelf(v reflect.Value, path string, visited map[uintptr]bool, errs *[]error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Pointer {
+			if visited[v.Pointer()] {
+				return
+			}
+			visited[v.Pointer()] = true
+		}
+		v = v.Elem()
+	}
+
+	//nolint:exhaustive // Only struct-like types hold structs
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() {
+				validateSelf(v.Field(i), joinPath(path, t.Field(i).Name), visited, errs)
+			}
+		}
+		callValidate(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		if !mayHoldStruct(v.Type().Elem()) {
+			return
+		}
+		for i := range v.Len() {
+			validateSelf(v.Index(i), fmt.Sprintf("%s[%d]", path, i), visited, errs)
+		}
+	case reflect.Map:
+		if !mayHoldStruct(v.Type().Elem()) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			validateSelf(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), visited, errs)
+		}
+	}
+}
+
+// mayHoldStruct reports whether values of t can hold a struct, so slices
+// of scalars are not walked element by element.
+func mayHoldStruct(t reflect.Type) bool {
+	//nolint:exhaustive // Other kinds hold no structs
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return mayHoldStruct(t.Elem())
+	default:
+		return false
+	}
+}
+
+// callValidate calls Validate on the struct v if it implements
+// types.Validator, with a value or pointer receiver.
+func callValidate(v reflect.Value, path string, errs *[]error) {
+	if !reflect.PointerTo(v.Type()).Implements(validatorType) {
+		return
+	}
+	if !v.CanAddr() {
+		// Map values are not addressable; validate a copy
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+
+	validator := v.Addr().Interface().(types.Validator) //nolint:forcetypeassert // checked above
+	err := validator.Validate()
+	if err == nil {
+		return
+	}
+
+	list := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint // only a top-level join is split
+		list = joined.Unwrap()
+	}
+	for _, err := range list {
+		var fe *types.FieldError
+		switch {
+		case err == nil:
+			continue
+		case errors.As(err, &fe):
+			out := *fe
+			out.Path = joinPath(path, fe.Path)
+			*errs = append(*errs, &out)
+		case path != "":
+			*errs = append(*errs, &types.FieldError{Path: path, Err: err})
+		default:
+			*errs = append(*errs, err)
+		}
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var sizePattern = regexp.MustCompile(`^([\d.]+)([a-zA-Z]+)$`)
+var sizePattern = regexp.MustCompile(`^([\d.]+)\s*([a-zA-Z]+)$`)
 
 // preprocessSizeNodesForType walks a YAML node tree and converts size string values
 // to integer values (bytes), but only for numeric target fields.
@@ -18,43 +18,7 @@ var sizePattern = regexp.MustCompile(`^([\d.]+)([a-zA-Z]+)$`)
 // This avoids coercing values for string fields while still supporting size strings
 // for numeric fields in structs.
 func preprocessSizeNodesForType(node *yaml.Node, targetType reflect.Type) {
-	if node == nil {
-		return
-	}
-	if targetType != nil && targetType.Kind() == reflect.Pointer {
-		targetType = targetType.Elem()
-	}
-
-	switch node.Kind {
-	case yaml.DocumentNode, yaml.SequenceNode:
-		for _, child := range node.Content {
-			preprocessSizeNodesForType(child, targetType)
-		}
-	case yaml.MappingNode:
-		switch {
-		case targetType != nil && targetType.Kind() == reflect.Struct:
-			fieldMap := yamlFieldTypeMap(targetType)
-			for i := 0; i < len(node.Content); i += 2 {
-				keyNode := node.Content[i]
-				valNode := node.Content[i+1]
-				if keyNode.Kind != yaml.ScalarNode {
-					continue
-				}
-				fieldType, ok := fieldMap[keyNode.Value]
-				if !ok {
-					continue
-				}
-				preprocessSizeNodesForType(valNode, fieldType)
-			}
-		case targetType != nil && targetType.Kind() == reflect.Map:
-			valType := targetType.Elem()
-			for i := 0; i < len(node.Content); i += 2 {
-				preprocessSizeNodesForType(node.Content[i+1], valType)
-			}
-		default:
-			// Unknown target type; avoid coercion
-		}
-	case yaml.ScalarNode:
+	walkScalarNodes(node, targetType, func(node *yaml.Node, targetType reflect.Type) {
 		// Only process string nodes that look like size strings and map to numeric types
 		if node.Tag == "!!str" && isNumericType(targetType) {
 			if matches := sizePattern.FindStringSubmatch(node.Value); len(matches) == 3 {
@@ -68,11 +32,72 @@ func preprocessSizeNodesForType(node *yaml.Node, targetType reflect.Type) {
 				}
 			}
 		}
+	})
+}
+
+// walkScalarNodes calls visit for each scalar of a YAML node tree with the
+// Go type it decodes into, following struct fields (including inline ones),
+// map values, and slice and array elements. Scalars whose type is unknown
+// are skipped, so that values are never coerced by guesswork.
+func walkScalarNodes(node *yaml.Node, targetType reflect.Type, visit func(*yaml.Node, reflect.Type)) {
+	if node == nil {
+		return
+	}
+	if targetType != nil && targetType.Kind() == reflect.Pointer {
+		targetType = targetType.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkScalarNodes(child, targetType, visit)
+		}
+	case yaml.SequenceNode:
+		walkSequenceScalars(node, targetType, visit)
+	case yaml.MappingNode:
+		walkMappingScalars(node, targetType, visit)
+	case yaml.ScalarNode:
+		visit(node, targetType)
 	case yaml.AliasNode:
 		// Aliases are resolved by yaml.Decode, no preprocessing needed
 	}
 }
 
+// walkSequenceScalars walks the elements of a sequence node decoded into a
+// slice or array of targetType.
+func walkSequenceScalars(node *yaml.Node, targetType reflect.Type, visit func(*yaml.Node, reflect.Type)) {
+	if targetType == nil || (targetType.Kind() != reflect.Slice && targetType.Kind() != reflect.Array) {
+		return
+	}
+	for _, child := range node.Content {
+		walkScalarNodes(child, targetType.Elem(), visit)
+	}
+}
+
+// walkMappingScalars walks the values of a mapping node decoded into a
+// struct or map of targetType.
+func walkMappingScalars(node *yaml.Node, targetType reflect.Type, visit func(*yaml.Node, reflect.Type)) {
+	switch {
+	case targetType != nil && targetType.Kind() == reflect.Struct:
+		fieldMap := yamlFieldTypeMap(targetType)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if keyNode.Kind != yaml.ScalarNode {
+				continue
+			}
+			if fieldType, ok := fieldMap[keyNode.Value]; ok {
+				walkScalarNodes(node.Content[i+1], fieldType, visit)
+			}
+		}
+	case targetType != nil && targetType.Kind() == reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkScalarNodes(node.Content[i+1], targetType.Elem(), visit)
+		}
+	default:
+		// Unknown target type; avoid coercion
+	}
+}
+
 func isNumericType(t reflect.Type) bool {
 	if t == nil {
 		return false
@@ -90,27 +115,26 @@ func isNumericType(t reflect.Type) bool {
 	}
 }
 
+// yamlFieldTypeMap maps the keys of struct type t to their field types: the
+// keys yaml.v3 decodes (see strictFieldTypes) and, for JSON-minded structs,
+// the names in json tags.
 func yamlFieldTypeMap(t reflect.Type) map[string]reflect.Type {
-	result := make(map[string]reflect.Type)
+	result, _ := strictFieldTypes(t)
 	for i := range t.NumField() {
 		field := t.Field(i)
 		if !field.IsExported() {
 			continue
 		}
-		addTag := func(tagKey string) {
-			tag := field.Tag.Get(tagKey)
-			name := strings.Split(tag, ",")[0]
-			if name == "-" {
-				return
-			}
-			if name == "" {
-				name = field.Name
-			}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := result[name]; !ok {
 			result[name] = field.Type
 		}
-
-		addTag("yaml")
-		addTag("json")
 	}
 
 	return result
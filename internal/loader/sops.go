@@ -0,0 +1,375 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/arloliu/fuda/internal/tags"
+	"gopkg.in/yaml.v3"
+)
+
+// sopsKey is the top-level key holding the metadata of a SOPS-encrypted
+// document.
+const sopsKey = "sops"
+
+// sopsValue matches a value encrypted by SOPS.
+var sopsValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// sopsMetadata is the part of the `sops` key needed for decryption.
+type sopsMetadata struct {
+	KeyGroups         []sopsKeyGroup `yaml:"key_groups"`
+	ShamirThreshold   int            `yaml:"shamir_threshold"`
+	LastModified      string         `yaml:"lastmodified"`
+	MAC               string         `yaml:"mac"`
+	MACOnlyEncrypted  bool           `yaml:"mac_only_encrypted"`
+	UnencryptedSuffix string         `yaml:"unencrypted_suffix"`
+	EncryptedSuffix   string         `yaml:"encrypted_suffix"`
+	UnencryptedRegex  string         `yaml:"unencrypted_regex"`
+	EncryptedRegex    string         `yaml:"encrypted_regex"`
+	sopsKeyGroup      `yaml:",inline"`
+}
+
+// sopsKeyGroup lists the master keys the data key is encrypted for.
+type sopsKeyGroup struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	KMS []struct {
+		ARN string `yaml:"arn"`
+		Enc string `yaml:"enc"`
+	} `yaml:"kms"`
+	GCPKMS []struct {
+		ResourceID string `yaml:"resource_id"`
+		Enc        string `yaml:"enc"`
+	} `yaml:"gcp_kms"`
+	PGP []struct {
+		Fingerprint string `yaml:"fp"`
+	} `yaml:"pgp"`
+}
+
+// DecryptSOPS returns source decrypted if it is a YAML or JSON document
+// encrypted with SOPS (https://github.com/getsops/sops), and source
+// unchanged otherwise. The data key is decrypted with an age identity or
+// with the KMS decrypter registered as "aws" (for `kms` keys) or "gcp" (for
+// `gcp_kms` keys). The document's MAC is verified, so a file edited without
+// re-encrypting is rejected.
+func DecryptSOPS(ctx context.Context, source []byte, identities []age.Identity, decrypters map[string]tags.Decrypter) ([]byte, error) {
+	if !bytes.Contains(source, []byte(sopsKey)) {
+		return source, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, err
+	}
+
+	decrypted, err := decryptSOPSNode(ctx, &doc, identities, decrypters)
+	if err != nil {
+		return nil, err
+	}
+	if !decrypted {
+		return source, nil
+	}
+
+	return yaml.Marshal(&doc)
+}
+
+// decryptSOPSNode decrypts doc in place if it is a SOPS-encrypted document,
+// as DecryptSOPS does, and reports whether it was.
+func decryptSOPSNode(ctx context.Context, doc *yaml.Node, identities []age.Identity, decrypters map[string]tags.Decrypter) (bool, error) {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false, nil
+	}
+
+	root := doc.Content[0]
+	i := mappingKeyIndex(root, sopsKey)
+	if i < 0 || root.Content[i+1].Kind != yaml.MappingNode || mappingKeyIndex(root.Content[i+1], "mac") < 0 {
+		return false, nil
+	}
+
+	var meta sopsMetadata
+	if err := root.Content[i+1].Decode(&meta); err != nil {
+		return false, fmt.Errorf("invalid sops metadata: %w", err)
+	}
+	root.Content = append(root.Content[:i], root.Content[i+2:]...)
+
+	key, err := meta.dataKey(ctx, identities, decrypters)
+	if err != nil {
+		return false, err
+	}
+
+	d := &sopsDecrypter{meta: &meta, key: key, hash: sha512.New()}
+	if err := d.compileRules(); err != nil {
+		return false, err
+	}
+	if err := d.walk(root, nil); err != nil {
+		return false, err
+	}
+
+	return true, d.verifyMAC()
+}
+
+// dataKey decrypts the document's data key with the first master key that
+// can be used.
+func (m *sopsMetadata) dataKey(ctx context.Context, identities []age.Identity, decrypters map[string]tags.Decrypter) ([]byte, error) {
+	if m.ShamirThreshold > 1 || len(m.KeyGroups) > 1 {
+		return nil, errors.New("sops files with several key groups are not supported")
+	}
+
+	groups := m.KeyGroups
+	if len(groups) == 0 {
+		groups = []sopsKeyGroup{m.sopsKeyGroup}
+	}
+
+	var errs []error
+	var kinds []string
+	for _, g := range groups {
+		if len(g.Age) > 0 {
+			kinds = append(kinds, "age")
+		}
+		if len(identities) > 0 {
+			for _, k := range g.Age {
+				r, err := age.Decrypt(armor.NewReader(strings.NewReader(k.Enc)), identities...)
+				if err == nil {
+					var key []byte
+					if key, err = io.ReadAll(r); err == nil {
+						return key, nil
+					}
+				}
+				errs = append(errs, fmt.Errorf("age recipient %s: %w", k.Recipient, err))
+			}
+		}
+
+		if len(g.KMS) > 0 {
+			kinds = append(kinds, "kms")
+		}
+		if d := decrypters["aws"]; d != nil {
+			for _, k := range g.KMS {
+				key, err := decryptSOPSKMS(ctx, d, k.Enc)
+				if err == nil {
+					return key, nil
+				}
+				errs = append(errs, fmt.Errorf("kms key %s: %w", k.ARN, err))
+			}
+		}
+
+		if len(g.GCPKMS) > 0 {
+			kinds = append(kinds, "gcp_kms")
+		}
+		if d := decrypters["gcp"]; d != nil {
+			for _, k := range g.GCPKMS {
+				key, err := decryptSOPSKMS(ctx, d, k.Enc)
+				if err == nil {
+					return key, nil
+				}
+				errs = append(errs, fmt.Errorf("gcp_kms key %s: %w", k.ResourceID, err))
+			}
+		}
+
+		if len(g.PGP) > 0 {
+			kinds = append(kinds, "pgp (not supported)")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no configured key can decrypt the sops data key; the file is encrypted for: %s", strings.Join(kinds, ", "))
+	}
+
+	return nil, fmt.Errorf("failed to decrypt the sops data key: %w", errors.Join(errs...))
+}
+
+// decryptSOPSKMS decrypts a base64-encoded data key with a KMS decrypter.
+func decryptSOPSKMS(ctx context.Context, d tags.Decrypter, enc string) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Decrypt(ctx, ciphertext)
+}
+
+// sopsDecrypter decrypts the values of a document in place and computes its
+// MAC, as SOPS does.
+type sopsDecrypter struct {
+	meta *sopsMetadata
+	key  []byte
+	hash hash.Hash
+	rule *regexp.Regexp
+}
+
+// compileRules prepares the regex selecting the encrypted keys, if any.
+func (d *sopsDecrypter) compileRules() error {
+	expr := d.meta.EncryptedRegex
+	if expr == "" {
+		expr = d.meta.UnencryptedRegex
+	}
+	if expr == "" {
+		return nil
+	}
+
+	rule, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid sops metadata: %w", err)
+	}
+	d.rule = rule
+
+	return nil
+}
+
+// encrypted reports whether the value at path is encrypted, following the
+// suffix and regex rules of the metadata.
+func (d *sopsDecrypter) encrypted(path []string) bool {
+	matches := func(match func(string) bool) bool {
+		for _, key := range path {
+			if match(key) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch m := d.meta; {
+	case m.UnencryptedSuffix != "":
+		return !matches(func(k string) bool { return strings.HasSuffix(k, m.UnencryptedSuffix) })
+	case m.EncryptedSuffix != "":
+		return matches(func(k string) bool { return strings.HasSuffix(k, m.EncryptedSuffix) })
+	case m.UnencryptedRegex != "":
+		return !matches(d.rule.MatchString)
+	case m.EncryptedRegex != "":
+		return matches(d.rule.MatchString)
+	default:
+		return true
+	}
+}
+
+// walk decrypts the values under node, at path. As in SOPS, list items share
+// the path of their list.
+func (d *sopsDecrypter) walk(node *yaml.Node, path []string) error {
+	switch node.Kind { //nolint:exhaustive // documents are unwrapped by the caller
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := d.walk(node.Content[i+1], append(path, node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := d.walk(child, path); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return d.leaf(node, path)
+	}
+
+	return nil
+}
+
+// leaf decrypts one scalar, if encrypted, and adds it to the MAC.
+func (d *sopsDecrypter) leaf(node *yaml.Node, path []string) error {
+	encrypted := d.encrypted(path)
+	if encrypted && node.Tag == "!!str" && node.Value != "" {
+		kind, plaintext, err := decryptSOPSValue(node.Value, d.key, strings.Join(path, ":")+":")
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+		}
+		node.Value, node.Style = plaintext, 0
+		node.Tag = map[string]string{"int": "!!int", "float": "!!float", "bool": "!!bool"}[kind]
+		if node.Tag == "" {
+			node.Tag = "!!str"
+		}
+	}
+
+	if d.meta.MACOnlyEncrypted && !encrypted {
+		return nil
+	}
+
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+	case bool:
+		if v {
+			_, _ = io.WriteString(d.hash, "True")
+		} else {
+			_, _ = io.WriteString(d.hash, "False")
+		}
+	case float64:
+		_, _ = io.WriteString(d.hash, strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		_, _ = fmt.Fprint(d.hash, v)
+	}
+
+	return nil
+}
+
+// verifyMAC compares the MAC of the decrypted values with the one stored,
+// encrypted, in the metadata.
+func (d *sopsDecrypter) verifyMAC() error {
+	lastModified, err := time.Parse(time.RFC3339, d.meta.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid sops metadata: lastmodified: %w", err)
+	}
+
+	_, mac, err := decryptSOPSValue(d.meta.MAC, d.key, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt sops MAC: %w", err)
+	}
+	if !strings.EqualFold(mac, fmt.Sprintf("%X", d.hash.Sum(nil))) {
+		return errors.New("sops MAC mismatch: the file was modified after it was encrypted")
+	}
+
+	return nil
+}
+
+// decryptSOPSValue decrypts an "ENC[AES256_GCM,...]" value with key and
+// returns its type ("str", "int", ...) and plaintext.
+func decryptSOPSValue(value string, key []byte, additionalData string) (string, string, error) {
+	m := sopsValue.FindStringSubmatch(value)
+	if m == nil {
+		return "", "", errors.New("value is not encrypted by sops")
+	}
+
+	var parts [3][]byte
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(m[i+1])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid encrypted value: %w", err)
+		}
+		parts[i] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", err
+	}
+
+	return m[4], string(plaintext), nil
+}
@@ -0,0 +1,243 @@
+package loader
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const sopsLastModified = "2025-03-04T05:06:07Z"
+
+// sopsEncrypt encrypts plain the way `sops encrypt --age` does, with the
+// default "_unencrypted" suffix, so tests need no sops binary. extraMeta is
+// added to the sops metadata.
+func sopsEncrypt(t *testing.T, plain string, dataKey []byte, recipient age.Recipient, extraMeta string) string {
+	t.Helper()
+
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(plain), &doc))
+
+	macOnlyEncrypted := strings.Contains(extraMeta, "mac_only_encrypted: true")
+	mac := sha512.New()
+	var walk func(n *yaml.Node, path []string)
+	walk = func(n *yaml.Node, path []string) {
+		switch n.Kind { //nolint:exhaustive // test documents hold no aliases
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				walk(n.Content[i+1], append(path, n.Content[i].Value))
+			}
+		case yaml.ScalarNode:
+			encrypted := true
+			for _, key := range path {
+				if strings.HasSuffix(key, "_unencrypted") {
+					encrypted = false
+				}
+			}
+
+			var v any
+			require.NoError(t, n.Decode(&v))
+			text, kind, hashed := fmt.Sprint(v), "str", fmt.Sprint(v)
+			switch v := v.(type) {
+			case bool:
+				text, kind = strconv.FormatBool(v), "bool"
+				hashed = map[bool]string{true: "True", false: "False"}[v]
+			case int:
+				kind = "int"
+			case float64:
+				text, kind = strconv.FormatFloat(v, 'f', -1, 64), "float"
+				hashed = text
+			}
+			if encrypted || !macOnlyEncrypted {
+				mac.Write([]byte(hashed))
+			}
+
+			if encrypted && text != "" {
+				n.Value = sopsSeal(t, dataKey, text, kind, strings.Join(path, ":")+":")
+				n.Tag, n.Style = "!!str", 0
+			}
+		}
+	}
+	walk(&doc, nil)
+
+	var wrapped strings.Builder
+	aw := armor.NewWriter(&wrapped)
+	w, err := age.Encrypt(aw, recipient)
+	require.NoError(t, err)
+	_, err = w.Write(dataKey)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, aw.Close())
+
+	out, err := yaml.Marshal(&doc)
+	require.NoError(t, err)
+
+	var meta strings.Builder
+	meta.WriteString("sops:\n  age:\n    - recipient: test\n      enc: |\n")
+	for line := range strings.Lines(wrapped.String()) {
+		meta.WriteString("        " + line)
+	}
+	fmt.Fprintf(&meta, "  lastmodified: %q\n", sopsLastModified)
+	fmt.Fprintf(&meta, "  mac: %s\n", sopsSeal(t, dataKey, fmt.Sprintf("%X", mac.Sum(nil)), "str", sopsLastModified))
+	meta.WriteString("  unencrypted_suffix: _unencrypted\n  version: 3.9.0\n")
+	meta.WriteString(extraMeta)
+
+	return string(out) + meta.String()
+}
+
+// sopsSeal encrypts one value in the sops ENC[...] format.
+func sopsSeal(t *testing.T, key []byte, text, kind, additionalData string) string {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	require.NoError(t, err)
+
+	iv := make([]byte, 32)
+	_, _ = rand.Read(iv)
+	sealed := gcm.Seal(nil, iv, []byte(text), []byte(additionalData))
+	data, tag := sealed[:len(sealed)-16], sealed[len(sealed)-16:]
+
+	enc := base64.StdEncoding.EncodeToString
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), kind)
+}
+
+func sopsTestKey(t *testing.T) ([]byte, *age.X25519Identity) {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	return key, identity
+}
+
+const sopsPlain = `database:
+  host: db.internal
+  port: 5432
+  password: s3cr3t
+  ssl: true
+  ratio: 0.75
+  empty: ""
+servers:
+  - a.internal
+  - b.internal
+public_unencrypted: visible
+`
+
+func TestDecryptSOPS(t *testing.T) {
+	key, identity := sopsTestKey(t)
+	encrypted := sopsEncrypt(t, sopsPlain, key, identity.Recipient(), "")
+	assert.NotContains(t, encrypted, "s3cr3t")
+	assert.Contains(t, encrypted, "public_unencrypted: visible")
+
+	out, err := DecryptSOPS(context.Background(), []byte(encrypted), []age.Identity{identity}, nil)
+	require.NoError(t, err)
+
+	var got, want map[string]any
+	require.NoError(t, yaml.Unmarshal(out, &got))
+	require.NoError(t, yaml.Unmarshal([]byte(sopsPlain), &want))
+	assert.Equal(t, want, got, "values keep their types and the sops key is removed")
+}
+
+func TestDecryptSOPS_NotEncrypted(t *testing.T) {
+	for _, source := range []string{
+		"host: localhost\n",
+		"sops: the band\n",
+		"sops:\n  version: 1\n",
+		"- sops\n",
+		"",
+	} {
+		out, err := DecryptSOPS(context.Background(), []byte(source), nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, source, string(out))
+	}
+}
+
+func TestDecryptSOPS_Tampered(t *testing.T) {
+	key, identity := sopsTestKey(t)
+	encrypted := sopsEncrypt(t, sopsPlain, key, identity.Recipient(), "")
+
+	tampered := strings.Replace(encrypted, "public_unencrypted: visible", "public_unencrypted: changed", 1)
+	_, err := DecryptSOPS(context.Background(), []byte(tampered), []age.Identity{identity}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sops MAC mismatch")
+
+	// An encrypted value moved to another key fails authentication
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(encrypted), &doc))
+	db := doc["database"].(map[string]any)
+	db["host"], db["password"] = db["password"], db["host"]
+	moved, err := yaml.Marshal(doc)
+	require.NoError(t, err)
+	_, err = DecryptSOPS(context.Background(), moved, []age.Identity{identity}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt database.")
+}
+
+func TestDecryptSOPS_Keys(t *testing.T) {
+	key, identity := sopsTestKey(t)
+	encrypted := sopsEncrypt(t, sopsPlain, key, identity.Recipient(), "")
+
+	_, err := DecryptSOPS(context.Background(), []byte(encrypted), nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no configured key can decrypt the sops data key; the file is encrypted for: age")
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, err = DecryptSOPS(context.Background(), []byte(encrypted), []age.Identity{other}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt the sops data key: age recipient test")
+
+	// A KMS master key is decrypted by the "aws" decrypter
+	wrapped := base64.StdEncoding.EncodeToString(append([]byte("kms:"), key...))
+	kmsOnly := sopsEncrypt(t, sopsPlain, key, other.Recipient(), "  kms:\n    - arn: arn:aws:kms:us-east-1:1:key/app\n      enc: "+wrapped+"\n")
+	decrypters := map[string]tags.Decrypter{"aws": fakeDecrypter(func(ciphertext []byte) ([]byte, error) {
+		return []byte(strings.TrimPrefix(string(ciphertext), "kms:")), nil
+	})}
+	out, err := DecryptSOPS(context.Background(), []byte(kmsOnly), nil, decrypters)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "password: s3cr3t")
+
+	_, err = DecryptSOPS(context.Background(), []byte(encrypted+"  key_groups: [{}, {}]\n"), []age.Identity{identity}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "several key groups are not supported")
+}
+
+func TestDecryptSOPS_MACOnlyEncrypted(t *testing.T) {
+	key, identity := sopsTestKey(t)
+	encrypted := sopsEncrypt(t, "name_unencrypted: app\ntoken: abc\n", key, identity.Recipient(), "  mac_only_encrypted: true\n")
+
+	// Unencrypted values are not covered by the MAC
+	edited := strings.Replace(encrypted, "name_unencrypted: app", "name_unencrypted: other", 1)
+	out, err := DecryptSOPS(context.Background(), []byte(edited), []age.Identity{identity}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "token: abc")
+	assert.Contains(t, string(out), "name_unencrypted: other")
+}
+
+type fakeDecrypter func(ciphertext []byte) ([]byte, error)
+
+func (f fakeDecrypter) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	return f(ciphertext)
+}
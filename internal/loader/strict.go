@@ -0,0 +1,245 @@
+package loader
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlUnmarshalerType = reflect.TypeFor[yaml.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	scannerType         = reflect.TypeFor[types.Scanner]()
+)
+
+// checkUnknownKeys walks a YAML node tree alongside targetType and returns a
+// LoadError listing every mapping key that does not correspond to a field,
+// with the nearest field name as a suggestion. It returns nil if all keys
+// are known.
+func checkUnknownKeys(node *yaml.Node, targetType reflect.Type, source string) error {
+	var errs []types.FieldError
+	collectUnknownKeys(node, targetType, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &types.LoadError{Source: source, Errors: errs}
+}
+
+// collectUnknownKeys appends an error for each unknown key under node.
+func collectUnknownKeys(node *yaml.Node, t reflect.Type, path string, errs *[]types.FieldError) {
+	if node == nil || t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if valueType, ok := orderedMapValue(t); ok && node.Kind == yaml.MappingNode {
+		collectUnknownValueKeys(node, valueType, path, errs)
+
+		return
+	}
+	if decodesItself(t) {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectUnknownKeys(child, t, path, errs)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for i, child := range node.Content {
+			collectUnknownKeys(child, t.Elem(), path+"["+strconv.Itoa(i)+"]", errs)
+		}
+	case yaml.MappingNode:
+		switch t.Kind() { //nolint:exhaustive // only structs and maps have keys
+		case reflect.Map:
+			collectUnknownValueKeys(node, t.Elem(), path, errs)
+		case reflect.Struct:
+			collectUnknownStructKeys(node, t, path, errs)
+		}
+	case yaml.ScalarNode, yaml.AliasNode:
+		// Scalars have no keys; aliases are checked where the anchor is defined
+	}
+}
+
+// collectUnknownValueKeys appends an error for each unknown key under the
+// values of a mapping node decoded into a map or ordered map, whose values
+// are of type valueType. Its own keys are all known.
+func collectUnknownValueKeys(node *yaml.Node, valueType reflect.Type, path string, errs *[]types.FieldError) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		collectUnknownKeys(node.Content[i+1], valueType, joinKeyPath(path, node.Content[i].Value), errs)
+	}
+}
+
+// collectUnknownStructKeys appends an error for each key of a mapping node
+// that is not a field of struct type t, and for each unknown key under the
+// values of the others.
+func collectUnknownStructKeys(node *yaml.Node, t reflect.Type, path string, errs *[]types.FieldError) {
+	fields, anyKey := strictFieldTypes(t)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+		if keyNode.Kind != yaml.ScalarNode || keyNode.Value == "<<" {
+			continue
+		}
+
+		keyPath := joinKeyPath(path, keyNode.Value)
+		fieldType, ok := fields[keyNode.Value]
+		if ok {
+			collectUnknownKeys(valNode, fieldType, keyPath, errs)
+
+			continue
+		}
+		if anyKey {
+			continue
+		}
+
+		msg := fmt.Sprintf("unknown key at line %d", keyNode.Line)
+		if suggestion := nearestKey(keyNode.Value, fields); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		*errs = append(*errs, types.FieldError{Path: keyPath, Message: msg})
+	}
+}
+
+// strictFieldTypes maps the keys yaml.v3 accepts for struct type t to their
+// field types, flattening inline structs. anyKey is true if t has an inline
+// map, which absorbs every otherwise unknown key.
+func strictFieldTypes(t reflect.Type) (fields map[string]reflect.Type, anyKey bool) {
+	fields = make(map[string]reflect.Type)
+
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+
+			tag := field.Tag.Get("yaml")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+
+			if strings.Contains(","+opts+",", ",inline,") {
+				ft := field.Type
+				for ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				switch ft.Kind() { //nolint:exhaustive // yaml.v3 inlines only structs and maps
+				case reflect.Struct:
+					walk(ft)
+				case reflect.Map:
+					anyKey = true
+				}
+
+				continue
+			}
+
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fields[name] = field.Type
+		}
+	}
+	walk(t)
+
+	return fields, anyKey
+}
+
+// decodesItself reports whether values of type t are decoded by custom code
+// rather than field by field, so their keys cannot be checked.
+func decodesItself(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+
+	pt := reflect.PointerTo(t)
+
+	return pt.Implements(yamlUnmarshalerType) ||
+		pt.Implements(textUnmarshalerType) ||
+		pt.Implements(scannerType)
+}
+
+// orderedMapValue returns the value type of t if t is an ordered map such
+// as fuda.OrderedMap: a slice of Key/Value entries that decodes itself from
+// a mapping.
+func orderedMapValue(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice || !decodesItself(t) {
+		return nil, false
+	}
+
+	entry := t.Elem()
+	if entry.Kind() != reflect.Struct || entry.NumField() != 2 ||
+		entry.Field(0).Name != "Key" || entry.Field(1).Name != "Value" {
+		return nil, false
+	}
+
+	return entry.Field(1).Type, true
+}
+
+// nearestKey returns the known key closest to key by edit distance, or ""
+// if none is close enough to be a likely typo.
+func nearestKey(key string, fields map[string]reflect.Type) string {
+	candidates := make([]string, 0, len(fields))
+	for name := range fields {
+		candidates = append(candidates, name)
+	}
+	sort.Strings(candidates) // deterministic choice among equal distances
+
+	best, bestDist := "", max(2, len(key)/3)+1
+	for _, name := range candidates {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// joinKeyPath appends key to a dotted YAML key path.
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,39 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"host", "host", 0},
+		{"databse", "database", 1},
+		{"hots", "host", 2},
+		{"", "port", 4},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, editDistance(tt.a, tt.b), "%q vs %q", tt.a, tt.b)
+	}
+}
+
+func TestNearestKey(t *testing.T) {
+	fields := map[string]reflect.Type{
+		"database": reflect.TypeFor[string](),
+		"host":     reflect.TypeFor[string](),
+		"port":     reflect.TypeFor[int](),
+	}
+
+	assert.Equal(t, "database", nearestKey("databse", fields))
+	assert.Equal(t, "host", nearestKey("Host", fields))
+	assert.Equal(t, "port", nearestKey("prot", fields))
+	assert.Empty(t, nearestKey("completely_unrelated", fields))
+}
@@ -0,0 +1,14 @@
+//go:build fuda_minimal
+
+package loader
+
+import "errors"
+
+// TemplateConfig holds template parsing configuration. fuda_minimal builds
+// have no template support, so it is always empty.
+type TemplateConfig struct{}
+
+// ProcessTemplate fails: fuda_minimal builds have no template support.
+func ProcessTemplate(_ []byte, _ any, _ *TemplateConfig) ([]byte, error) {
+	return nil, errors.New("templates are not supported in fuda_minimal builds")
+}
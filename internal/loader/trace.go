@@ -0,0 +1,263 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// TraceRecord is the structured form of a field's trace line, for reports
+// such as TraceRecorder.Explain.
+type TraceRecord struct {
+	// Path is the dotted Go field path, e.g. "Database.Port".
+	Path string
+	// Doc is the field's doc tag.
+	Doc string
+	// Section is set for an untagged nested struct, whose own fields are
+	// recorded individually; it carries only Path and Doc.
+	Section bool
+	// Source is the source that supplied the value, or "zero value".
+	Source string
+	// Value is the final value of the field, masked if it is sensitive.
+	Value string
+	// Consulted lists the other sources considered, in priority order.
+	Consulted []string
+}
+
+// fieldTrace collects the sources consulted for a single field and the
+// decision made, for WithTrace output.
+type fieldTrace struct {
+	field   reflect.StructField
+	value   reflect.Value
+	yamlSet bool
+	yamlVal string
+	envKey  string
+	envVal  string
+	envSet  bool
+	flag    string
+	flagVal string
+	flagSet bool
+	custom  []string // name=value of the custom tags on the field
+	parts   []string
+	source  string
+}
+
+// newFieldTrace snapshots the field state before any tag is applied.
+func newFieldTrace(field reflect.StructField, value reflect.Value, envKey string, flags tags.FlagLookup) *fieldTrace {
+	t := &fieldTrace{field: field, value: value}
+
+	sensitive := tags.IsSensitive(field)
+
+	if !value.IsZero() {
+		t.yamlSet = true
+		t.yamlVal = formatTraceValue(value)
+		if sensitive {
+			t.yamlVal = tags.RedactedValue
+		}
+	}
+
+	if t.envKey = envKey; envKey != "" {
+		t.envVal, t.envSet = tags.LookupEnv(t.envKey)
+		if sensitive && t.envSet {
+			t.envVal = tags.RedactedValue
+		}
+	}
+
+	if t.flag = tags.FlagName(field); t.flag != "" && flags != nil {
+		t.flagVal, t.flagSet = flags(t.flag)
+		if sensitive && t.flagSet {
+			t.flagVal = tags.RedactedValue
+		}
+	}
+
+	return t
+}
+
+// traceOutcome is the outcome of tag processing for a field.
+type traceOutcome struct {
+	env      bool // the env var supplied the value
+	flag     bool // the flag supplied the value
+	ref      bool // a ref, refFrom, or secretName tag supplied the value
+	fallback bool // the default or env fallback supplied the value
+	computed bool // a custom, dsn, or expr tag computed the value
+}
+
+// yamlUsed reports whether the value from the document was kept.
+func (o traceOutcome) yamlUsed() bool {
+	return !o.env && !o.flag && !o.ref && !o.fallback && !o.computed
+}
+
+// record builds the trace parts from the outcome of tag processing: the
+// source applied, and whether a tag computed the value afterwards.
+func (t *fieldTrace) record(applied Source, computed bool) {
+	o := traceOutcome{
+		env:      applied == SourceEnv,
+		flag:     applied == SourceFlag,
+		ref:      applied == SourceRef,
+		fallback: applied == SourceDefault,
+		computed: computed,
+	}
+
+	yamlUsed := t.yamlSet && o.yamlUsed()
+	if t.yamlSet {
+		t.use("yaml="+t.yamlVal, yamlUsed)
+	} else {
+		t.parts = append(t.parts, "yaml unset")
+	}
+
+	t.recordEnv(o)
+	t.recordFlag(o)
+	t.recordRef(o)
+	t.recordDefault(o)
+	t.recordComputed(o)
+
+	if !yamlUsed && o.yamlUsed() {
+		t.parts = append(t.parts, "zero value")
+		t.source = "zero value"
+	}
+}
+
+// use adds part, marking it as the source of the value if ok.
+func (t *fieldTrace) use(part string, ok bool) {
+	if ok {
+		t.source = part
+		part += " (used)"
+	}
+	t.parts = append(t.parts, part)
+}
+
+// recordEnv adds the env var of the field, if any.
+func (t *fieldTrace) recordEnv(o traceOutcome) {
+	switch {
+	case t.envKey == "":
+	case t.envSet:
+		t.use("env "+t.envKey+"="+t.envVal, o.env && !o.flag && !o.computed)
+	default:
+		t.parts = append(t.parts, "env "+t.envKey+" unset")
+	}
+}
+
+// recordFlag adds the flag of the field, if any.
+func (t *fieldTrace) recordFlag(o traceOutcome) {
+	switch {
+	case t.flag == "":
+	case t.flagSet:
+		t.use("flag "+t.flag+"="+t.flagVal, o.flag && !o.computed)
+	default:
+		t.parts = append(t.parts, "flag "+t.flag+" unset")
+	}
+}
+
+// recordRef adds the refFrom, ref, and secretName tags of the field, marking
+// the last one skipped if a higher source supplied the value.
+func (t *fieldTrace) recordRef(o traceOutcome) {
+	refFrom := tags.Get(t.field, "refFrom")
+	ref := tags.Get(t.field, "ref")
+	secretName := tags.Get(t.field, "secretName")
+	if refFrom != "" {
+		t.use("refFrom="+refFrom, o.ref && ref == "")
+	}
+	if ref != "" {
+		t.use("ref="+ref, o.ref)
+	}
+	if secretName != "" {
+		t.use("secretName="+secretName, o.ref && refFrom == "" && ref == "")
+	}
+	if (refFrom != "" || ref != "" || secretName != "") && !o.ref && (t.yamlSet || o.env || o.flag) {
+		t.parts[len(t.parts)-1] += " (skipped)"
+	}
+}
+
+// recordDefault adds the default tag of the field, or its env fallback.
+func (t *fieldTrace) recordDefault(o traceOutcome) {
+	if tag := tags.Get(t.field, "default"); tag != "" && tag != "-" {
+		t.use("default="+tag, o.fallback)
+	} else if et, err := tags.ParseEnvTag(tags.Get(t.field, "env")); err == nil && et.HasFallback {
+		t.use("env fallback="+et.Fallback, o.fallback)
+	}
+}
+
+// recordComputed adds the custom, dsn, and expr tags of the field.
+func (t *fieldTrace) recordComputed(o traceOutcome) {
+	for _, part := range t.custom {
+		t.use(part, o.computed)
+	}
+	if tag := tags.Get(t.field, "dsn"); tag != "" {
+		t.use("dsn="+tag, o.computed)
+	}
+	if tag := tags.Get(t.field, "expr"); tag != "" {
+		t.use("expr="+tag, o.computed)
+	}
+}
+
+// write prints the trace line for the field, unless it is an untagged
+// nested struct whose own fields are traced individually.
+func (t *fieldTrace) write(w io.Writer, path string) {
+	if t.isNestedStruct() {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s: %s\n", path, strings.Join(t.parts, ", "))
+}
+
+// toRecord returns the structured trace of the field.
+func (t *fieldTrace) toRecord(path string) TraceRecord {
+	rec := TraceRecord{Path: path, Doc: tags.Get(t.field, "doc")}
+	if t.isNestedStruct() {
+		rec.Section = true
+
+		return rec
+	}
+
+	rec.Source = t.source
+	rec.Value = formatTraceValue(t.value)
+	if tags.IsSensitive(t.field) && !t.value.IsZero() {
+		rec.Value = tags.RedactedValue
+	}
+	for _, part := range t.parts {
+		if !strings.HasSuffix(part, " (used)") && part != "zero value" {
+			rec.Consulted = append(rec.Consulted, part)
+		}
+	}
+
+	return rec
+}
+
+// isNestedStruct reports whether the field is a struct (or pointer to struct)
+// without any fuda tags.
+func (t *fieldTrace) isNestedStruct() bool {
+	typ := t.field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, key := range []string{"env", "ref", "refFrom", "secretName", "default", "dsn"} {
+		if tags.Get(t.field, key) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatTraceValue renders a field value for trace output.
+func formatTraceValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+
+	if !v.CanInterface() {
+		return v.String()
+	}
+
+	return fmt.Sprintf("%v", v.Interface())
+}
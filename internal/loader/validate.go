@@ -0,0 +1,156 @@
+//go:build !fuda_minimal
+
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/arloliu/fuda/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator checks struct fields against their `validate` tags.
+type Validator = validator.Validate
+
+// Validate validates target with v. Besides the `validate` tags checked by
+// v, it applies the rules given in 'fuda' tags, which the validator does
+// not read. Those rules see only the field value, so cross-field rules need
+// a separate `validate` tag.
+func Validate(v *Validator, target any) []error {
+	var errs []error
+	if err := v.Struct(target); err != nil {
+		errs = append(errs, err)
+	}
+
+	val := reflect.ValueOf(target)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() == reflect.Struct {
+		validateFudaRules(v, val, "", make(map[uintptr]bool), &errs)
+	}
+
+	return errs
+}
+
+// validateFudaRules checks the fields of struct val whose validate rules
+// come only from a 'fuda' tag.
+func validateFudaRules(v *validator.Validate, val reflect.Value, path string, visited map[uintptr]bool, errs *[]error) {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldVal := val.Field(i)
+		fieldPath := joinPath(path, field.Name)
+
+		if _, ok := field.Tag.Lookup("validate"); !ok {
+			if rules := tags.Get(field, "validate"); rules != "" && rules != "-" {
+				if err := v.Var(fieldVal.Interface(), rules); err != nil {
+					*errs = append(*errs, fudaRuleError(fieldPath, err))
+				}
+			}
+		}
+
+		for fieldVal.Kind() == reflect.Pointer && !fieldVal.IsNil() {
+			if visited[fieldVal.Pointer()] {
+				break
+			}
+			visited[fieldVal.Pointer()] = true
+			fieldVal = fieldVal.Elem()
+		}
+		if fieldVal.Kind() == reflect.Struct {
+			validateFudaRules(v, fieldVal, fieldPath, visited, errs)
+		}
+	}
+}
+
+// fudaRuleError converts a validator error for a single value into a
+// FieldError naming the failed rule.
+func fudaRuleError(path string, err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) == 0 {
+		return &types.FieldError{Path: path, Tag: "validate", Err: err}
+	}
+
+	rule := verrs[0].Tag()
+	if param := verrs[0].Param(); param != "" {
+		rule += "=" + param
+	}
+
+	return &types.FieldError{Path: path, Tag: "validate", Message: fmt.Sprintf("failed on the '%s' rule", rule)}
+}
+
+// addSettingHints appends the ways to set a field, derived from its tags, to
+// the errors in errs reporting a missing value of target: validator errors
+// of the 'required' rules and fuda tag 'required' rules.
+func (e *Engine) addSettingHints(target any, errs []error) []error {
+	t := reflect.TypeOf(target)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return errs
+	}
+
+	hinted := make([]error, len(errs))
+	for i, err := range errs {
+		hinted[i] = err
+
+		var verrs validator.ValidationErrors
+		var ferr *types.FieldError
+		switch {
+		case errors.As(err, &verrs):
+			hints := make([]string, len(verrs))
+			found := false
+			for j, fe := range verrs {
+				if !strings.HasPrefix(fe.Tag(), "required") {
+					continue
+				}
+				_, path, _ := strings.Cut(fe.StructNamespace(), ".")
+				hints[j] = e.settingHint(t, path)
+				found = found || hints[j] != ""
+			}
+			if found {
+				hinted[i] = &hintedValidationErrors{errs: verrs, hints: hints}
+			}
+		case errors.As(err, &ferr) && ferr.Tag == "validate" && strings.HasPrefix(ferr.Message, "failed on the 'required"):
+			if hint := e.settingHint(t, ferr.Path); hint != "" {
+				withHint := *ferr
+				withHint.Message += "; " + hint
+				hinted[i] = &withHint
+			}
+		}
+	}
+
+	return hinted
+}
+
+// hintedValidationErrors is validator.ValidationErrors with a hint on how
+// to set the field appended to some of the messages. It unwraps to the
+// validator's errors.
+type hintedValidationErrors struct {
+	errs  validator.ValidationErrors
+	hints []string // per error, "" for none
+}
+
+func (h *hintedValidationErrors) Error() string {
+	lines := make([]string, len(h.errs))
+	for i, fe := range h.errs {
+		lines[i] = fe.Error()
+		if h.hints[i] != "" {
+			lines[i] += "; " + h.hints[i]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (h *hintedValidationErrors) Unwrap() error {
+	return h.errs
+}
@@ -0,0 +1,18 @@
+//go:build fuda_minimal
+
+package loader
+
+// Validator stands in for the validator of full builds: fuda_minimal builds
+// do not validate, so Engine.Validator is always nil.
+type Validator struct{}
+
+// Validate reports no errors: fuda_minimal builds do not validate.
+func Validate(_ *Validator, _ any) []error {
+	return nil
+}
+
+// addSettingHints returns errs unchanged: fuda_minimal builds do not
+// validate.
+func (e *Engine) addSettingHints(_ any, errs []error) []error {
+	return errs
+}
@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/spf13/afero"
+)
+
+// AgeResolver resolves references using the age:// scheme. It reads an
+// age-encrypted file, binary or ASCII-armored, and returns the plaintext.
+type AgeResolver struct {
+	fs         afero.Fs
+	identities []age.Identity
+}
+
+// NewAgeResolver creates a new AgeResolver that decrypts with identities.
+// If fs is nil, the OS filesystem is used.
+func NewAgeResolver(fs afero.Fs, identities []age.Identity) *AgeResolver {
+	if fs == nil {
+		fs = afero.NewOsFs()
+	}
+
+	return &AgeResolver{fs: fs, identities: identities}
+}
+
+// Resolve reads and decrypts the file at the given URI.
+// Supports both age://path and age:///path formats.
+func (r *AgeResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+
+	if u.Scheme != "age" {
+		return nil, fmt.Errorf("unsupported scheme for age resolver: %s", u.Scheme)
+	}
+
+	if len(r.identities) == 0 {
+		return nil, errors.New("no age identity configured (use WithAgeIdentity or WithAgeIdentityFile)")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := r.fs.Open(uriPath(u))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var src io.Reader = br
+	if header, _ := br.Peek(len(armor.Header)); bytes.Equal(header, []byte(armor.Header)) {
+		src = armor.NewReader(br)
+	}
+
+	plaintext, err := age.Decrypt(src, r.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", uri, err)
+	}
+
+	return io.ReadAll(plaintext)
+}
@@ -25,10 +25,7 @@ func New(fs afero.Fs) *CompositeResolver {
 		resolvers: make(map[string]SubResolver),
 	}
 	cr.Register("file", NewFileResolver(fs))
-
-	httpResolver := NewHTTPResolver()
-	cr.Register("http", httpResolver)
-	cr.Register("https", httpResolver)
+	registerHTTP(cr)
 	cr.Register("env", NewEnvResolver())
 
 	return cr
@@ -39,6 +36,20 @@ func (r *CompositeResolver) Register(scheme string, resolver SubResolver) {
 	r.resolvers[scheme] = resolver
 }
 
+// BeginLoad gives each sub-resolver that keeps per-load state a chance to
+// attach it to ctx.
+func (r *CompositeResolver) BeginLoad(ctx context.Context) context.Context {
+	for _, resolver := range r.resolvers {
+		if scoper, ok := resolver.(interface {
+			BeginLoad(ctx context.Context) context.Context
+		}); ok {
+			ctx = scoper.BeginLoad(ctx)
+		}
+	}
+
+	return ctx
+}
+
 // Resolve delegates resolution to the appropriate sub-resolver.
 func (r *CompositeResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
 	parts := strings.SplitN(uri, "://", 2)
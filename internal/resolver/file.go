@@ -35,19 +35,23 @@ func (r *FileResolver) Resolve(ctx context.Context, uri string) ([]byte, error)
 		return nil, fmt.Errorf("unsupported scheme for file resolver: %s", u.Scheme)
 	}
 
-	// Handle both file://path (host=path, path="") and file:///path (host="", path="/path")
-	// The standard file URI format is file:///absolute/path or file://host/path
-	// For convenience, we also support file://relative/path where the path is treated as Host
-	path := u.Path
-	if path == "" && u.Host != "" {
-		// file://relative/path format - Host contains the path
-		path = u.Host + u.Path
-	}
-
 	// Check context before reading
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	return afero.ReadFile(r.fs, path)
+	return afero.ReadFile(r.fs, uriPath(u))
+}
+
+// uriPath returns the file path of a file-like URI.
+// Handles both scheme://path (host=path, path="") and scheme:///path (host="", path="/path").
+// The standard file URI format is file:///absolute/path or file://host/path.
+// For convenience, we also support file://relative/path where the path is treated as Host.
+func uriPath(u *url.URL) string {
+	if u.Path == "" && u.Host != "" {
+		// scheme://relative format - Host contains the path
+		return u.Host
+	}
+
+	return u.Path
 }
@@ -1,3 +1,5 @@
+//go:build !fuda_minimal
+
 package resolver
 
 import (
@@ -22,6 +24,13 @@ func NewHTTPResolver() *HTTPResolver {
 	}
 }
 
+// registerHTTP registers the http:// and https:// resolvers on cr.
+func registerHTTP(cr *CompositeResolver) {
+	httpResolver := NewHTTPResolver()
+	cr.Register("http", httpResolver)
+	cr.Register("https", httpResolver)
+}
+
 // Resolve fetches content from the given URI using an HTTP GET request.
 func (r *HTTPResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
 	u, err := url.Parse(uri)
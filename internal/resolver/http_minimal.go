@@ -0,0 +1,7 @@
+//go:build fuda_minimal
+
+package resolver
+
+// registerHTTP registers nothing: fuda_minimal builds have no network
+// resolvers.
+func registerHTTP(*CompositeResolver) {}
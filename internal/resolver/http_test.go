@@ -0,0 +1,59 @@
+//go:build !fuda_minimal
+
+package resolver_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPResolver(t *testing.T) {
+	r := resolver.NewHTTPResolver()
+	ctx := context.Background()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/timeout" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+		_, _ = fmt.Fprint(w, "response")
+	}))
+	defer ts.Close()
+
+	t.Run("valid url", func(t *testing.T) {
+		content, err := r.Resolve(ctx, ts.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("response"), content)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		_, err := r.Resolve(ctx, ts.URL+"/error")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "status: 500")
+	})
+
+	t.Run("invalid scheme", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "ftp://example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := r.Resolve(ctx, ts.URL+"/timeout")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "context deadline exceeded")
+	})
+}
@@ -1,16 +1,17 @@
 package resolver_test
 
 import (
+	"bytes"
 	"context"
-	"fmt"
-	"net/http"
-	"net/http/httptest"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
-	"time"
 
+	"filippo.io/age"
+	"filippo.io/age/armor"
 	"github.com/arloliu/fuda/internal/resolver"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,49 +55,68 @@ func TestFileResolver(t *testing.T) {
 	})
 }
 
-func TestHTTPResolver(t *testing.T) {
-	r := resolver.NewHTTPResolver()
+func TestAgeResolver(t *testing.T) {
 	ctx := context.Background()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
 
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/timeout" {
-			time.Sleep(200 * time.Millisecond)
-		}
-		if r.URL.Path == "/error" {
-			w.WriteHeader(http.StatusInternalServerError)
-
-			return
-		}
-		_, _ = fmt.Fprint(w, "response")
-	}))
-	defer ts.Close()
-
-	t.Run("valid url", func(t *testing.T) {
-		content, err := r.Resolve(ctx, ts.URL)
+	var binary, armored bytes.Buffer
+	for _, out := range []io.WriteCloser{nopCloser{&binary}, armor.NewWriter(&armored)} {
+		w, err := age.Encrypt(out, identity.Recipient())
 		require.NoError(t, err)
-		assert.Equal(t, []byte("response"), content)
+		_, err = io.WriteString(w, "s3cret")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, out.Close())
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/secret.age", binary.Bytes(), 0o600))
+	require.NoError(t, afero.WriteFile(fs, "secret.age.asc", armored.Bytes(), 0o600))
+	require.NoError(t, afero.WriteFile(fs, "/plain.txt", []byte("s3cret"), 0o600))
+
+	r := resolver.NewAgeResolver(fs, []age.Identity{identity})
+
+	t.Run("binary", func(t *testing.T) {
+		content, err := r.Resolve(ctx, "age:///secret.age")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("s3cret"), content)
 	})
 
-	t.Run("http error", func(t *testing.T) {
-		_, err := r.Resolve(ctx, ts.URL+"/error")
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "status: 500")
+	t.Run("armored relative path", func(t *testing.T) {
+		content, err := r.Resolve(ctx, "age://secret.age.asc")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("s3cret"), content)
 	})
 
-	t.Run("invalid scheme", func(t *testing.T) {
-		_, err := r.Resolve(ctx, "ftp://example.com")
-		assert.Error(t, err)
+	t.Run("not encrypted", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "age:///plain.txt")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to decrypt age:///plain.txt")
 	})
 
-	t.Run("timeout", func(t *testing.T) {
-		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
-		defer cancel()
-		_, err := r.Resolve(ctx, ts.URL+"/timeout")
+	t.Run("missing file", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "age:///missing.age")
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "context deadline exceeded")
+	})
+
+	t.Run("no identities", func(t *testing.T) {
+		_, err := resolver.NewAgeResolver(fs, nil).Resolve(ctx, "age:///secret.age")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no age identity configured")
+	})
+
+	t.Run("wrong scheme", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "file:///secret.age")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported scheme")
 	})
 }
 
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
 func TestCompositeResolver(t *testing.T) {
 	r := resolver.New(nil)
 	ctx := context.Background()
@@ -1,14 +1,15 @@
 package tags
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/arloliu/fuda/internal/types"
 )
 
 // ProcessDefault processes the 'default' tag for a field.
-func ProcessDefault(field reflect.StructField, value reflect.Value) error {
-	tag := field.Tag.Get("default")
+func ProcessDefault(ctx context.Context, field reflect.StructField, value reflect.Value) error {
+	tag := Get(field, "default")
 	if tag == "" || tag == "-" {
 		return nil
 	}
@@ -18,5 +19,5 @@ func ProcessDefault(field reflect.StructField, value reflect.Value) error {
 		return nil
 	}
 
-	return types.Convert(tag, value)
+	return types.ConvertContext(ctx, tag, value)
 }
@@ -28,13 +28,16 @@ type DSNConfig struct {
 //   - ${.Nested.Field} - references nested struct fields
 //   - ${ref "uri"} - resolves a URI inline using the resolver
 //   - ${env "KEY"} - reads an environment variable
+//   - ${.Field | urlquery} - percent-encodes a value, e.g. a password
 //
 // The templateData parameter is pre-computed struct data for template execution.
-// Pass nil to have it computed on-demand (for backward compatibility).
+// Pass nil to have it computed on-demand (for backward compatibility). funcs
+// adds template functions to the library (nil for none).
 //
 // Tag options:
 //   - dsn:"template" - the template string
 //   - dsnStrict:"true" - enable strict mode (error on empty values)
+//   - dsnEscape:"url" - percent-encode every ${...} value
 //
 // Example:
 //
@@ -52,8 +55,9 @@ func ProcessDSN(
 	resolver Resolver,
 	envPrefix string,
 	templateData any,
+	funcs map[string]any,
 ) error {
-	tag := field.Tag.Get("dsn")
+	tag := Get(field, "dsn")
 	if tag == "" {
 		return nil
 	}
@@ -70,9 +74,11 @@ func ProcessDSN(
 
 	// Build template config from DSN options
 	config := TemplateConfig{
-		Strict:    field.Tag.Get("dsnStrict") == "true",
+		Strict:    Get(field, "dsnStrict") == "true",
 		Resolver:  resolver,
 		EnvPrefix: envPrefix,
+		Escape:    Get(field, "dsnEscape"),
+		Funcs:     funcs,
 	}
 
 	// Use pre-computed data if available, otherwise compute on-demand
@@ -87,5 +93,5 @@ func ProcessDSN(
 		return fmt.Errorf("dsn: %w", err)
 	}
 
-	return types.Convert(result, value)
+	return types.ConvertContext(ctx, result, value)
 }
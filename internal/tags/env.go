@@ -1,30 +1,340 @@
 package tags
 
 import (
+	"cmp"
+	"context"
+	"encoding"
+	"fmt"
+	"maps"
+	"math"
 	"os"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/arloliu/fuda/internal/types"
 )
 
+// EnvTag is a parsed 'env' tag of the form NAME[=fallback][,required].
+type EnvTag struct {
+	// Name is the environment variable name, without the prefix. A trailing
+	// * makes it a wildcard reading all variables with the name as prefix.
+	Name string
+	// Fallback is used like a default tag when the variable is unset.
+	Fallback    string
+	HasFallback bool
+	// Required reports a missing variable as an error.
+	Required bool
+}
+
+// ParseEnvTag parses an 'env' tag value. Options follow the name after
+// commas; with a fallback, only recognized trailing options are split off, so
+// the fallback itself may contain commas.
+func ParseEnvTag(tag string) (EnvTag, error) {
+	var et EnvTag
+
+	for {
+		i := strings.LastIndexByte(tag, ',')
+		if i < 0 {
+			break
+		}
+
+		opt := strings.TrimSpace(tag[i+1:])
+		if opt != "required" {
+			if !strings.Contains(tag[:i], "=") {
+				return EnvTag{}, fmt.Errorf("unknown env tag option %q", opt)
+			}
+
+			break
+		}
+
+		et.Required = true
+		tag = tag[:i]
+	}
+
+	et.Name, et.Fallback, et.HasFallback = strings.Cut(tag, "=")
+	et.Name = strings.TrimSpace(et.Name)
+
+	if et.Name == "" {
+		return EnvTag{}, fmt.Errorf("env tag %q has no variable name", tag)
+	}
+	if i := strings.IndexByte(et.Name, '*'); i >= 0 && i != len(et.Name)-1 {
+		return EnvTag{}, fmt.Errorf("env tag %q can only have * at the end of the name", tag)
+	}
+	if et.Required && et.HasFallback {
+		return EnvTag{}, fmt.Errorf("env tag %q cannot have both a fallback and the required option", tag)
+	}
+
+	return et, nil
+}
+
+// EnvKey returns the full environment variable name of field, or "" if the
+// field has no valid 'env' tag.
+func EnvKey(field reflect.StructField, prefix string) string {
+	tag := Get(field, "env")
+	if tag == "" || tag == "-" {
+		return ""
+	}
+
+	et, err := ParseEnvTag(tag)
+	if err != nil {
+		return ""
+	}
+
+	return prefix + et.Name
+}
+
 // ProcessEnv processes the 'env' tag for a field.
 // Returns true if an environment variable was found and applied, false otherwise.
 // Environment variables always override current values when the env var is set.
-func ProcessEnv(field reflect.StructField, value reflect.Value, prefix string) (bool, error) {
-	tag := field.Tag.Get("env")
-	if tag == "" {
+func ProcessEnv(ctx context.Context, field reflect.StructField, value reflect.Value, prefix string) (bool, error) {
+	tag := Get(field, "env")
+	if tag == "" || tag == "-" {
 		return false, nil
 	}
 
-	envKey := tag
-	if prefix != "" {
-		envKey = prefix + envKey
+	et, err := ParseEnvTag(tag)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, err = WithEnvSeparators(ctx, field)
+	if err != nil {
+		return false, err
 	}
 
-	envVal, ok := os.LookupEnv(envKey)
+	return ProcessEnvVar(ctx, prefix+et.Name, value)
+}
+
+// WithEnvSeparators returns a copy of ctx carrying the separators of the
+// field's envSeparator and envKeyValSeparator tags, which replace the comma
+// between slice items or map entries and the colon between map keys and
+// values in its env var and env fallback:
+//
+//	Hosts  []string          `env:"HOSTS" envSeparator:";"`                            // HOSTS=a;b;c
+//	Labels map[string]string `env:"LABELS" envSeparator:";" envKeyValSeparator:"="` // LABELS=k1=v1;k2=v2
+func WithEnvSeparators(ctx context.Context, field reflect.StructField) (context.Context, error) {
+	item := Get(field, "envSeparator")
+	keyValue := Get(field, "envKeyValSeparator")
+	if item == "" && keyValue == "" {
+		return ctx, nil
+	}
+
+	t := field.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map {
+		effItem, effKeyValue := cmp.Or(item, ","), cmp.Or(keyValue, ":")
+		if strings.Contains(effItem, effKeyValue) || strings.Contains(effKeyValue, effItem) {
+			return ctx, fmt.Errorf("envSeparator %q and envKeyValSeparator %q must differ", effItem, effKeyValue)
+		}
+	}
+
+	return types.WithSeparators(ctx, item, keyValue), nil
+}
+
+// ProcessEnvVar applies the environment variable key to value if it is set.
+// Returns true if the variable was found and applied. A wildcard key like
+// FEATURE_* fills a map instead, see processEnvWildcard.
+func ProcessEnvVar(ctx context.Context, key string, value reflect.Value) (bool, error) {
+	if IsEnvWildcard(key) {
+		return processEnvWildcard(ctx, key, value)
+	}
+
+	envVal, ok := os.LookupEnv(key)
 	if !ok {
 		return false, nil
 	}
+	if err := types.ConvertContext(ctx, envVal, value); err != nil {
+		return true, &types.FieldError{Tag: "env", Source: key, Err: err}
+	}
+
+	return true, nil
+}
+
+// IsEnvWildcard reports whether key is a wildcard like FEATURE_*, which
+// reads all environment variables starting with FEATURE_ into a map.
+func IsEnvWildcard(key string) bool {
+	return strings.HasSuffix(key, "*")
+}
+
+// EnvMatches reports whether the environment variable name is read by key,
+// which is either the same name or a wildcard matching it.
+func EnvMatches(key, name string) bool {
+	if IsEnvWildcard(key) {
+		prefix := strings.TrimSuffix(key, "*")
+
+		return len(name) > len(prefix) && strings.HasPrefix(name, prefix)
+	}
+
+	return key == name
+}
+
+// LookupEnv is like os.LookupEnv, but also accepts a wildcard key. Its value
+// then lists the matching variables as SUFFIX=value, sorted by name.
+func LookupEnv(key string) (string, bool) {
+	if !IsEnvWildcard(key) {
+		return os.LookupEnv(key)
+	}
+
+	vars := envWithPrefix(strings.TrimSuffix(key, "*"))
+	if len(vars) == 0 {
+		return "", false
+	}
+
+	parts := make([]string, 0, len(vars))
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		parts = append(parts, name+"="+vars[name])
+	}
+
+	return strings.Join(parts, ","), true
+}
+
+// envWithPrefix returns the environment variables starting with prefix,
+// keyed by the rest of their name.
+func envWithPrefix(prefix string) map[string]string {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, val, _ := strings.Cut(kv, "=")
+		if suffix, ok := strings.CutPrefix(name, prefix); ok && suffix != "" {
+			vars[suffix] = val
+		}
+	}
+
+	return vars
+}
+
+// processEnvWildcard sets an entry of the map value for every environment
+// variable matching the wildcard key, keyed by the rest of the variable name
+// and converted to the map element type:
+//
+//	Features map[string]bool `env:"FEATURE_*"` // FEATURE_NEW_UI=true -> {"NEW_UI": true}
+//
+// Entries from other sources are kept unless a variable replaces them.
+func processEnvWildcard(ctx context.Context, key string, value reflect.Value) (bool, error) {
+	t := value.Type()
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false, fmt.Errorf("wildcard %s requires a map with string keys, got %s", key, t)
+	}
+
+	prefix := strings.TrimSuffix(key, "*")
+	vars := envWithPrefix(prefix)
+	if len(vars) == 0 {
+		return false, nil
+	}
+
+	if value.IsNil() {
+		value.Set(reflect.MakeMapWithSize(t, len(vars)))
+	}
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := types.ConvertContext(ctx, vars[name], elem); err != nil {
+			return false, &types.FieldError{Tag: "env", Source: prefix + name, Err: err}
+		}
+		value.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+	}
+
+	return true, nil
+}
+
+// AutoEnvName derives an environment variable name from a dotted field path,
+// splitting camel case into words: "Database.MaxConns" becomes
+// "DATABASE_MAX_CONNS" and "HTTPServer.Port" becomes "HTTP_SERVER_PORT".
+// Slice indexes are segments of their own, so "Servers[0].Host" becomes
+// "SERVERS_0_HOST".
+func AutoEnvName(path string) string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	var b strings.Builder
+	for i, part := range strings.Split(path, ".") {
+		if i > 0 {
+			b.WriteByte('_')
+		}
+
+		runes := []rune(part)
+		for j, r := range runes {
+			if j > 0 && unicode.IsUpper(r) {
+				prev := runes[j-1]
+				nextLower := j+1 < len(runes) && unicode.IsLower(runes[j+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+
+	return b.String()
+}
+
+// EnvSliceLen returns the slice length implied by indexed environment
+// variables named key_N_..., such as APP_SERVERS_1_HOST for key APP_SERVERS:
+// one more than the highest index N, or 0 if none is set. name is the
+// variable with the highest index.
+func EnvSliceLen(key string) (n int, name string) {
+	for suffix := range envWithPrefix(key + "_") {
+		index, _, ok := strings.Cut(suffix, "_")
+		if !ok || index == "" || strings.Trim(index, "0123456789") != "" {
+			continue
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			i = math.MaxInt - 1 // out of range, reported by the caller
+		}
+		if i >= n {
+			n, name = i+1, key+"_"+suffix
+		}
+	}
+
+	return n, name
+}
+
+// AutoEnvSupported reports whether a field of type t can be set from a
+// single environment variable. Structs are walked field by field instead,
+// unless they convert from a string themselves.
+func AutoEnvSupported(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	//nolint:exhaustive // Remaining kinds convert from strings
+	switch t.Kind() {
+	case reflect.Struct:
+		ptr := reflect.PointerTo(t)
+
+		return ptr.Implements(scannerType) || ptr.Implements(textUnmarshalerType)
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Array, reflect.Complex64, reflect.Complex128:
+		return false
+	default:
+		return true
+	}
+}
+
+var (
+	scannerType         = reflect.TypeFor[types.Scanner]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// ProcessEnvFallback applies the fallback of an 'env' tag if the value is
+// still zero. It runs with the default tag, which takes precedence.
+func ProcessEnvFallback(ctx context.Context, field reflect.StructField, value reflect.Value) error {
+	tag := Get(field, "env")
+	if tag == "" || !value.IsZero() {
+		return nil
+	}
+
+	et, err := ParseEnvTag(tag)
+	if err != nil || !et.HasFallback {
+		return err
+	}
+
+	ctx, err = WithEnvSeparators(ctx, field)
+	if err != nil {
+		return err
+	}
 
-	return true, types.Convert(envVal, value)
+	return types.ConvertContext(ctx, et.Fallback, value)
 }
@@ -0,0 +1,383 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// exprOperandPrefix names the identifiers standing in for ${...} operands
+// while the expression is parsed as Go.
+const exprOperandPrefix = "_fudaOperand"
+
+// ProcessExpr processes the 'expr' tag for a field. The tag is an expression
+// over ${...} template values, evaluated after all other tags of the field,
+// so computed fields need not be strings like dsn fields:
+//
+//	Workers    int  `default:"4"`
+//	MaxConns   int  `expr:"${.Workers} * 2"`
+//	Production bool `expr:"${.Env} == \"prod\""`
+//
+// Operands are Go literals (numbers, "strings", true, false) and ${...}
+// templates, which keep the type of the value they produce: ${.Workers} is
+// an int, so ${.Workers} / 3 is integer division, while ${ref:uri} is a
+// string. Operators are those of Go: arithmetic, comparisons, &&, ||, and !.
+// Mixing strings and numbers is an error.
+//
+// Like dsn, the field is only set if it is still zero, and fields referenced
+// in templates must appear earlier in the struct.
+func ProcessExpr(
+	ctx context.Context,
+	field reflect.StructField,
+	value reflect.Value,
+	parentVal reflect.Value,
+	resolver Resolver,
+	envPrefix string,
+	templateData any,
+	funcs map[string]any,
+) error {
+	tag := Get(field, "expr")
+	if tag == "" {
+		return nil
+	}
+	if Get(field, "dsn") != "" {
+		return errors.New("expr and dsn tags cannot be combined")
+	}
+
+	// Only process if field is zero (don't overwrite existing values)
+	if !value.IsZero() {
+		return nil
+	}
+
+	src, pipelines, err := splitExprOperands(tag)
+	if err != nil {
+		return err
+	}
+	node, err := parser.ParseExpr(src)
+	if err != nil {
+		return fmt.Errorf("invalid expression %q: %w", tag, err)
+	}
+
+	config := TemplateConfig{
+		Resolver:  resolver,
+		EnvPrefix: envPrefix,
+		Funcs:     funcs,
+	}
+	data := templateData
+	if data == nil {
+		data = StructToData(parentVal)
+	}
+
+	operands := make(map[string]constant.Value, len(pipelines))
+	for i, pipeline := range pipelines {
+		v, err := evalTemplateValue(ctx, pipeline, data, config)
+		if err != nil {
+			return fmt.Errorf("${%s}: %w", pipeline, err)
+		}
+		c, err := constantOf(v)
+		if err != nil {
+			return fmt.Errorf("${%s}: %w", pipeline, err)
+		}
+		operands[exprOperandPrefix+strconv.Itoa(i)] = c
+	}
+
+	result, err := evalExpr(node, operands)
+	if err != nil {
+		return fmt.Errorf("expression %q: %w", tag, err)
+	}
+
+	return setConstant(value, result)
+}
+
+// splitExprOperands replaces the ${...} operands of expr with identifiers
+// and returns the rewritten expression and the operand pipelines in order.
+func splitExprOperands(expr string) (string, []string, error) {
+	var (
+		sb        strings.Builder
+		pipelines []string
+	)
+	for {
+		start := strings.Index(expr, "${")
+		if start < 0 {
+			sb.WriteString(expr)
+
+			break
+		}
+		end := findClosingBrace(expr[start+2:])
+		if end < 0 {
+			return "", nil, fmt.Errorf("unclosed ${ in expression %q", expr)
+		}
+
+		sb.WriteString(expr[:start])
+		sb.WriteString(exprOperandPrefix + strconv.Itoa(len(pipelines)))
+		pipelines = append(pipelines, strings.TrimSpace(expr[start+2:start+2+end]))
+		expr = expr[start+2+end+1:]
+	}
+
+	return sb.String(), pipelines, nil
+}
+
+// constantOf converts a template value to an expression operand.
+func constantOf(v any) (constant.Value, error) {
+	rv := reflect.ValueOf(v)
+	//nolint:exhaustive // other kinds are formatted or rejected below
+	switch rv.Kind() {
+	case reflect.Bool:
+		return constant.MakeBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return constant.MakeInt64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return constant.MakeUint64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return constant.MakeFloat64(rv.Float()), nil
+	case reflect.String:
+		return constant.MakeString(rv.String()), nil
+	case reflect.Invalid:
+		return nil, errors.New("no value")
+	}
+
+	if s, ok := v.(fmt.Stringer); ok {
+		return constant.MakeString(s.String()), nil
+	}
+
+	return nil, fmt.Errorf("unsupported operand type %T", v)
+}
+
+// evalExpr evaluates a parsed expression with Go constant semantics.
+func evalExpr(node ast.Expr, operands map[string]constant.Value) (constant.Value, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind == token.IMAG {
+			return nil, fmt.Errorf("unsupported literal %s", n.Value)
+		}
+
+		return constant.MakeFromLiteral(n.Value, n.Kind, 0), nil
+	case *ast.Ident:
+		switch n.Name {
+		case "true", "false":
+			return constant.MakeBool(n.Name == "true"), nil
+		}
+		if c, ok := operands[n.Name]; ok {
+			return c, nil
+		}
+
+		return nil, fmt.Errorf("unknown identifier %s (write fields as ${.%s})", n.Name, n.Name)
+	case *ast.ParenExpr:
+		return evalExpr(n.X, operands)
+	case *ast.UnaryExpr:
+		x, err := evalExpr(n.X, operands)
+		if err != nil {
+			return nil, err
+		}
+
+		return unaryOp(n.Op, x)
+	case *ast.BinaryExpr:
+		x, err := evalExpr(n.X, operands)
+		if err != nil {
+			return nil, err
+		}
+		y, err := evalExpr(n.Y, operands)
+		if err != nil {
+			return nil, err
+		}
+
+		return binaryOp(x, n.Op, y)
+	default:
+		return nil, fmt.Errorf("unsupported expression %T", node)
+	}
+}
+
+// unaryOp applies a unary operator, checking the operand type.
+func unaryOp(op token.Token, x constant.Value) (constant.Value, error) {
+	//nolint:exhaustive // remaining operators are unsupported
+	switch op {
+	case token.ADD, token.SUB:
+		if isNumeric(x) {
+			return constant.UnaryOp(op, x, 0), nil
+		}
+	case token.NOT:
+		if x.Kind() == constant.Bool {
+			return constant.UnaryOp(op, x, 0), nil
+		}
+	case token.XOR:
+		if x.Kind() == constant.Int {
+			return constant.UnaryOp(op, x, 0), nil
+		}
+	}
+
+	return nil, undefinedOp(op, x)
+}
+
+// binaryOp applies a binary operator, checking the operand types so that
+// go/constant does not panic. Like Go, dividing integers truncates.
+func binaryOp(x constant.Value, op token.Token, y constant.Value) (constant.Value, error) {
+	if x.Kind() != y.Kind() && !(isNumeric(x) && isNumeric(y)) {
+		return nil, fmt.Errorf("mismatched types %s and %s for %s", kindName(x), kindName(y), op)
+	}
+
+	//nolint:exhaustive // remaining operators are arithmetic or unsupported
+	switch op {
+	case token.EQL, token.NEQ:
+		return constant.MakeBool(constant.Compare(x, op, y)), nil
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if x.Kind() != constant.Bool {
+			return constant.MakeBool(constant.Compare(x, op, y)), nil
+		}
+	case token.LAND, token.LOR:
+		if x.Kind() == constant.Bool {
+			return constant.BinaryOp(x, op, y), nil
+		}
+	case token.SHL, token.SHR:
+		return shiftOp(x, op, y)
+	default:
+		return arithmeticOp(x, op, y)
+	}
+
+	return nil, undefinedOp(op, x)
+}
+
+// arithmeticOp applies an arithmetic or bitwise operator to operands of
+// matching type, rejecting division by zero.
+func arithmeticOp(x constant.Value, op token.Token, y constant.Value) (constant.Value, error) {
+	valid := false
+	//nolint:exhaustive // remaining operators are unsupported
+	switch op {
+	case token.ADD:
+		valid = isNumeric(x) || x.Kind() == constant.String
+	case token.SUB, token.MUL:
+		valid = isNumeric(x)
+	case token.QUO:
+		if isNumeric(x) && constant.Sign(y) == 0 {
+			return nil, errors.New("division by zero")
+		}
+		if x.Kind() == constant.Int && y.Kind() == constant.Int {
+			op = token.QUO_ASSIGN // integer division, see constant.BinaryOp
+		}
+		valid = isNumeric(x)
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		if op == token.REM && x.Kind() == constant.Int && constant.Sign(y) == 0 {
+			return nil, errors.New("division by zero")
+		}
+		valid = x.Kind() == constant.Int && y.Kind() == constant.Int
+	}
+	if !valid {
+		return nil, undefinedOp(op, x)
+	}
+
+	return constant.BinaryOp(x, op, y), nil
+}
+
+// shiftOp shifts the integer x by y bits.
+func shiftOp(x constant.Value, op token.Token, y constant.Value) (constant.Value, error) {
+	if x.Kind() != constant.Int {
+		return nil, undefinedOp(op, x)
+	}
+	n, ok := constant.Uint64Val(y)
+	if !ok || n > 64 { //nolint:mnd // wider shifts overflow any field
+		return nil, fmt.Errorf("invalid shift count %s", y)
+	}
+
+	return constant.Shift(x, op, uint(n)), nil
+}
+
+// undefinedOp returns the error for an operator applied to operands of a
+// type it does not support.
+func undefinedOp(op token.Token, x constant.Value) error {
+	return fmt.Errorf("operator %s not defined on %s", op, kindName(x))
+}
+
+// isNumeric reports whether c is an integer or floating-point number.
+func isNumeric(c constant.Value) bool {
+	return c.Kind() == constant.Int || c.Kind() == constant.Float
+}
+
+// kindName names the type of c for error messages.
+func kindName(c constant.Value) string {
+	//nolint:exhaustive // operands are never complex or unknown
+	switch c.Kind() {
+	case constant.Bool:
+		return "bool"
+	case constant.String:
+		return "string"
+	case constant.Int:
+		return "int"
+	default:
+		return "float"
+	}
+}
+
+// setConstant stores the result of an expression in value. Numbers are set
+// directly on numeric fields (time.Duration fields take nanoseconds), and
+// strings are converted like any other source value.
+func setConstant(value reflect.Value, c constant.Value) error {
+	if value.Kind() == reflect.Pointer {
+		ptr := reflect.New(value.Type().Elem())
+		if err := setConstant(ptr.Elem(), c); err != nil {
+			return err
+		}
+		value.Set(ptr)
+
+		return nil
+	}
+
+	if c.Kind() == constant.String {
+		return types.Convert(constant.StringVal(c), value)
+	}
+
+	//nolint:exhaustive // other kinds cannot hold a bool or number
+	switch value.Kind() {
+	case reflect.Bool:
+		if c.Kind() == constant.Bool {
+			value.SetBool(constant.BoolVal(c))
+
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := constant.Int64Val(constant.ToInt(c)); ok && isNumeric(c) {
+			if value.OverflowInt(n) {
+				return fmt.Errorf("result %s overflows %s", c, value.Type())
+			}
+			value.SetInt(n)
+
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := constant.Uint64Val(constant.ToInt(c)); ok && isNumeric(c) {
+			if value.OverflowUint(n) {
+				return fmt.Errorf("result %s overflows %s", c, value.Type())
+			}
+			value.SetUint(n)
+
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if isNumeric(c) {
+			f, _ := constant.Float64Val(constant.ToFloat(c))
+			if value.OverflowFloat(f) {
+				return fmt.Errorf("result %s overflows %s", c, value.Type())
+			}
+			value.SetFloat(f)
+
+			return nil
+		}
+	case reflect.String:
+		s := c.ExactString()
+		if c.Kind() == constant.Float {
+			f, _ := constant.Float64Val(c)
+			s = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+
+		return types.Convert(s, value)
+	}
+
+	return fmt.Errorf("cannot assign %s result %s to %s field", kindName(c), c, value.Type())
+}
@@ -0,0 +1,95 @@
+//go:build !fuda_minimal
+
+package tags_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessExpr(t *testing.T) {
+	type ExprStruct struct {
+		Env      string
+		Workers  int
+		Ratio    float64
+		Timeout  time.Duration
+		Hosts    []string
+		Conns    int           `expr:"${.Workers} * 2 + 1"`
+		Third    int           `expr:"${.Workers} / 3"`
+		Scaled   float64       `expr:"${.Workers} * ${.Ratio}"`
+		Prod     bool          `expr:"${.Env} == \"prod\" && !(${.Workers} < 2)"`
+		Label    string        `expr:"${.Env | upper} + \"-\" + \"x\""`
+		Double   time.Duration `expr:"${.Timeout} * 2"`
+		Count    uint8         `expr:"${len .Hosts} << 2"`
+		Pointer  *int          `expr:"-${.Workers}"`
+		Preset   int           `expr:"${.Workers}"`
+		Fraction string        `expr:"1.0 / 4"`
+	}
+
+	s := ExprStruct{Env: "prod", Workers: 4, Ratio: 1.5, Timeout: time.Second, Hosts: []string{"a", "b"}, Preset: 9}
+	v := reflect.ValueOf(&s).Elem()
+	ctx := context.Background()
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		require.NoError(t, tags.ProcessExpr(ctx, field, v.Field(i), v, nil, "", nil, nil), field.Name)
+	}
+
+	assert.Equal(t, 9, s.Conns)
+	assert.Equal(t, 1, s.Third, "integer division truncates")
+	assert.InDelta(t, 6.0, s.Scaled, 1e-9)
+	assert.True(t, s.Prod)
+	assert.Equal(t, "PROD-x", s.Label)
+	assert.Equal(t, 2*time.Second, s.Double)
+	assert.Equal(t, uint8(8), s.Count)
+	require.NotNil(t, s.Pointer)
+	assert.Equal(t, -4, *s.Pointer)
+	assert.Equal(t, 9, s.Preset, "non-zero fields are kept")
+	assert.Equal(t, "0.25", s.Fraction)
+}
+
+func TestProcessExpr_Errors(t *testing.T) {
+	type Source struct {
+		Env     string
+		Workers int
+	}
+
+	tests := []struct {
+		name    string
+		tag     reflect.StructTag
+		value   any
+		wantErr string
+	}{
+		{"mismatched types", `expr:"${.Env} + 1"`, int(0), "mismatched types string and int for +"},
+		{"bool arithmetic", `expr:"true * 2"`, int(0), "mismatched types bool and int"},
+		{"string operator", `expr:"${.Env} - \"x\""`, "", "operator - not defined on string"},
+		{"division by zero", `expr:"${.Workers} / 0"`, int(0), "division by zero"},
+		{"bare identifier", `expr:"Workers * 2"`, int(0), "unknown identifier Workers (write fields as ${.Workers})"},
+		{"syntax", `expr:"${.Workers} *"`, int(0), "invalid expression"},
+		{"unclosed", `expr:"${.Workers * 2"`, int(0), "unclosed ${"},
+		{"unknown field", `expr:"${.Missing}"`, int(0), "can't evaluate field Missing"},
+		{"overflow", `expr:"${.Workers} * 100"`, int8(0), "result 400 overflows int8"},
+		{"fraction to int", `expr:"${.Workers} / 8.0"`, int(0), "cannot assign float result 0.5 to int field"},
+		{"bool to int", `expr:"${.Workers} > 1"`, int(0), "cannot assign bool result true to int field"},
+		{"with dsn", `expr:"1" dsn:"x"`, "", "expr and dsn tags cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Source{Env: "prod", Workers: 4}
+			parent := reflect.ValueOf(&src).Elem()
+			field := reflect.StructField{Name: "Out", Type: reflect.TypeOf(tt.value), Tag: tt.tag}
+			value := reflect.New(field.Type).Elem()
+
+			err := tags.ProcessExpr(context.Background(), field, value, parent, nil, "", nil, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package tags
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// FlagLookup returns the value of the named command-line flag and whether it
+// was explicitly set. Flags left at their default are reported as unset.
+type FlagLookup func(name string) (string, bool)
+
+// FlagName returns the flag bound to field by its 'flag' tag, or "" if none.
+func FlagName(field reflect.StructField) string {
+	name := Get(field, "flag")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// ProcessFlag processes the 'flag' tag, overriding value with the flag's
+// value when it was set on the command line.
+// Returns (applied, error) where applied is true if the flag was set.
+func ProcessFlag(ctx context.Context, field reflect.StructField, value reflect.Value, lookup FlagLookup) (bool, error) {
+	name := FlagName(field)
+	if name == "" || lookup == nil {
+		return false, nil
+	}
+
+	flagVal, ok := lookup(name)
+	if !ok {
+		return false, nil
+	}
+
+	if err := types.ConvertContext(ctx, flagVal, value); err != nil {
+		return true, &types.FieldError{Tag: "flag", Source: "--" + name, Err: err}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,179 @@
+package tags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fudaKeys lists the tags that may be given inside a 'fuda' tag as
+// key=value. Bare words set the boolean tags to "true"; bare "required"
+// adds the required validate rule.
+var fudaKeys = map[string]bool{
+	"default":            true,
+	"env":                true,
+	"envSeparator":       true,
+	"envKeyValSeparator": true,
+	"flag":               true,
+	"ref":                true,
+	"refFrom":            true,
+	"refRetry":           true,
+	"secretName":         true,
+	"kms":                true,
+	"dsn":                true,
+	"dsnStrict":          true,
+	"dsnEscape":          true,
+	"expr":               true,
+	"decoder":            true,
+	"validate":           true,
+	"doc":                true,
+	"secret":             true,
+	"sensitive":          true,
+	"logctx":             true,
+}
+
+var fudaFlags = map[string]bool{"dsnStrict": true, "secret": true, "sensitive": true, "logctx": true}
+
+// IsBuiltin reports whether name is a tag read by fuda or its decoders,
+// which a custom tag processor cannot take over.
+func IsBuiltin(name string) bool {
+	return fudaKeys[name] || name == "fuda" || name == "yaml" || name == "json"
+}
+
+type fudaTag struct {
+	values map[string]string
+	err    error
+}
+
+// fudaTagCache caches parsed 'fuda' tags by tag value.
+var fudaTagCache sync.Map // string → fudaTag
+
+// ParseFudaTag parses a combined 'fuda' tag such as
+//
+//	fuda:"default=8080,env=APP_PORT,validate='min=1,max=65535'"
+//
+// into the separate tags it stands for. Items are separated by commas; a
+// value containing commas is wrapped in single quotes.
+func ParseFudaTag(tag string) (map[string]string, error) {
+	if cached, ok := fudaTagCache.Load(tag); ok {
+		ft := cached.(fudaTag) //nolint:forcetypeassert // only fudaTag is stored
+
+		return ft.values, ft.err
+	}
+
+	values, err := parseFudaTag(tag)
+	fudaTagCache.Store(tag, fudaTag{values: values, err: err})
+
+	return values, err
+}
+
+func parseFudaTag(tag string) (map[string]string, error) {
+	values := make(map[string]string)
+	var rules []string
+
+	for rest := tag; rest != ""; {
+		item, next, err := nextFudaItem(rest)
+		if err != nil {
+			return nil, fmt.Errorf("fuda tag %q: %w", tag, err)
+		}
+		rest = next
+
+		key, value, hasValue := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		switch {
+		case !hasValue && key == "required":
+			rules = append(rules, "required")
+		case !hasValue && fudaFlags[key]:
+			values[key] = "true"
+		case !hasValue || !fudaKeys[key]:
+			return nil, fmt.Errorf("fuda tag %q: unknown option %q", tag, item)
+		default:
+			if _, dup := values[key]; dup {
+				return nil, fmt.Errorf("fuda tag %q: %s given more than once", tag, key)
+			}
+			values[key] = unquote(strings.TrimSpace(value))
+		}
+	}
+
+	if len(rules) > 0 {
+		if v := values["validate"]; v != "" {
+			rules = append(rules, v)
+		}
+		values["validate"] = strings.Join(rules, ",")
+	}
+
+	return values, nil
+}
+
+// nextFudaItem splits the first item off s at a comma outside single quotes.
+func nextFudaItem(s string) (item, rest string, err error) {
+	quoted := false
+	for i := range len(s) {
+		switch s[i] {
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				return s[:i], s[i+1:], nil
+			}
+		}
+	}
+	if quoted {
+		return "", "", fmt.Errorf("unterminated quote")
+	}
+
+	return s, "", nil
+}
+
+// unquote strips the single quotes around a fuda tag value.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
+
+// Lookup returns the value of the tag named key on field. A separate tag
+// takes precedence; otherwise the value is read from the 'fuda' tag.
+func Lookup(field reflect.StructField, key string) (string, bool) {
+	if v, ok := field.Tag.Lookup(key); ok {
+		return v, true
+	}
+
+	tag, ok := field.Tag.Lookup("fuda")
+	if !ok {
+		return "", false
+	}
+
+	values, err := ParseFudaTag(tag)
+	if err != nil {
+		return "", false
+	}
+	v, ok := values[key]
+
+	return v, ok
+}
+
+// Get returns the value of the tag named key on field, or "" (see Lookup).
+func Get(field reflect.StructField, key string) string {
+	v, _ := Lookup(field, key)
+
+	return v
+}
+
+// CheckFudaTag returns the parse error of field's 'fuda' tag, if any.
+func CheckFudaTag(field reflect.StructField) error {
+	tag, ok := field.Tag.Lookup("fuda")
+	if !ok {
+		return nil
+	}
+	_, err := ParseFudaTag(tag)
+
+	return err
+}
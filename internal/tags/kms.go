@@ -0,0 +1,48 @@
+package tags
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Decrypter decrypts KMS ciphertext.
+type Decrypter interface {
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// ProcessKMS processes the 'kms' tag for a field: the field's current value
+// is base64-decoded and decrypted with the decrypter registered for the
+// tag's provider name. Zero values are left untouched.
+// Returns true if a value was decrypted.
+func ProcessKMS(ctx context.Context, field reflect.StructField, value reflect.Value, decrypters map[string]Decrypter) (bool, error) {
+	provider := Get(field, "kms")
+	if provider == "" || value.IsZero() {
+		return false, nil
+	}
+
+	decrypter, ok := decrypters[provider]
+	if !ok {
+		return false, fmt.Errorf("no KMS decrypter registered for provider %q", provider)
+	}
+
+	if value.Kind() != reflect.String {
+		return false, fmt.Errorf("kms tag requires a string field, got %s", value.Type())
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value.String()))
+	if err != nil {
+		return false, fmt.Errorf("kms ciphertext is not valid base64: %w", err)
+	}
+
+	plaintext, err := decrypter.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return false, fmt.Errorf("kms %s decrypt failed: %w", provider, err)
+	}
+
+	value.SetString(string(plaintext))
+
+	return true, nil
+}
@@ -36,8 +36,16 @@ type Resolver interface {
 //
 // Note: Fields referenced in templates must appear earlier in the struct.
 //
+// Modifiers after the URI, or after the field name of refFrom, post-process
+// the resolved content in order (see ParseRefTag):
+//
+//   - base64 - decodes standard base64 content
+//
+//   - jsonpath=$.db.password - extracts one value from JSON content
+//
 // The templateData parameter is pre-computed struct data for template execution.
-// Pass nil to have it computed on-demand (for backward compatibility).
+// Pass nil to have it computed on-demand (for backward compatibility). funcs
+// adds template functions to the library (nil for none).
 //
 // Example:
 //
@@ -54,6 +62,7 @@ func ProcessRef(
 	resolver Resolver,
 	envPrefix string,
 	templateData any,
+	funcs map[string]any,
 ) (bool, error) {
 	if resolver == nil {
 		return false, nil
@@ -65,11 +74,15 @@ func ProcessRef(
 	}
 
 	// Create resolver helper
-	resolveURI := newURIResolver(ctx, resolver, envPrefix, templateData, parentVal)
+	resolveURI := newURIResolver(ctx, resolver, envPrefix, templateData, funcs, parentVal)
 
 	// Try refFrom first
-	if refFrom := field.Tag.Get("refFrom"); refFrom != "" {
-		resolved, found, err := processRefFrom(refFrom, parentVal, value, resolveURI)
+	if refFrom := Get(field, "refFrom"); refFrom != "" {
+		refFrom, mods, err := ParseRefTag(refFrom)
+		if err != nil {
+			return false, err
+		}
+		resolved, found, err := processRefFrom(ctx, refFrom, mods, parentVal, value, resolveURI)
 		if err != nil {
 			return false, err
 		}
@@ -80,15 +93,17 @@ func ProcessRef(
 	}
 
 	// Try ref tag as fallback
-	if refTag := field.Tag.Get("ref"); refTag != "" {
-		content, found, err := resolveURI(refTag)
+	if refTag := Get(field, "ref"); refTag != "" {
+		uri, mods, err := ParseRefTag(refTag)
+		if err != nil {
+			return false, err
+		}
+		found, err := resolveURI(uri, mods, value)
 		if err != nil {
 			return false, err
 		}
 		if found {
-			err := types.Convert(string(content), value)
-
-			return err == nil, err
+			return true, nil
 		}
 		// Not found - return false to allow default tag to apply
 	}
@@ -96,8 +111,10 @@ func ProcessRef(
 	return false, nil
 }
 
-// uriResolverFunc is a function type for resolving URIs.
-type uriResolverFunc func(uri string) (content []byte, found bool, err error)
+// uriResolverFunc is a function type for resolving URIs, applying the
+// modifiers to the content, and setting value to the result. Errors are
+// FieldErrors with the resolved URI as their source.
+type uriResolverFunc func(uri string, mods []RefModifier, value reflect.Value) (found bool, err error)
 
 // newURIResolver creates a URI resolver function with template support.
 func newURIResolver(
@@ -105,15 +122,17 @@ func newURIResolver(
 	resolver Resolver,
 	envPrefix string,
 	templateData any,
+	funcs map[string]any,
 	parentVal reflect.Value,
 ) uriResolverFunc {
-	return func(uri string) (content []byte, found bool, err error) {
+	return func(uri string, mods []RefModifier, value reflect.Value) (bool, error) {
 		// Process template expressions in URI if present
 		if strings.Contains(uri, "${") {
 			config := TemplateConfig{
 				Strict:    false, // ref uses permissive mode by default
 				Resolver:  resolver,
 				EnvPrefix: envPrefix,
+				Funcs:     funcs,
 			}
 
 			// Use pre-computed data if available, otherwise compute on-demand
@@ -124,26 +143,38 @@ func newURIResolver(
 
 			expanded, err := ProcessTemplate(ctx, uri, data, config)
 			if err != nil {
-				return nil, false, fmt.Errorf("failed to expand ref template: %w", err)
+				return false, &types.FieldError{Tag: "ref", Source: uri, Message: "failed to expand ref template", Err: err}
 			}
 
 			uri = expanded
 		}
 
-		// Normalize URI (add file:// prefix if needed)
-		uri = normalizeURI(uri)
-
-		content, err = resolver.Resolve(ctx, uri)
-		if err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				return nil, false, nil // Not found, allow fallback
-			}
+		return resolveInto(ctx, resolver, "ref", NormalizeURI(uri), mods, value)
+	}
+}
 
-			return nil, false, fmt.Errorf("failed to resolve ref '%s': %w", uri, err)
+// resolveInto resolves uri, applies the modifiers to the content, and sets
+// value to the result. It returns false if the URI does not exist. Errors
+// are FieldErrors for tag with uri as their source.
+func resolveInto(ctx context.Context, resolver Resolver, tag, uri string, mods []RefModifier, value reflect.Value) (bool, error) {
+	content, err := resolver.Resolve(ctx, uri)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil // Not found, allow fallback
 		}
 
-		return content, true, nil
+		return false, &types.FieldError{Tag: tag, Source: uri, Message: "failed to resolve", Err: err}
+	}
+
+	content, err = applyRefModifiers(content, mods)
+	if err != nil {
+		return false, &types.FieldError{Tag: tag, Source: uri, Err: err}
+	}
+	if err := types.ConvertContext(ctx, string(content), value); err != nil {
+		return false, &types.FieldError{Tag: tag, Source: uri, Err: err}
 	}
+
+	return true, nil
 }
 
 // processRefFrom handles the refFrom tag logic.
@@ -151,19 +182,21 @@ func newURIResolver(
 // - resolved: true if a value was set (even if empty)
 // - found: true if refFrom should stop the fallback chain (value was resolved or explicitly empty)
 func processRefFrom(
+	ctx context.Context,
 	refFrom string,
+	mods []RefModifier,
 	parentVal reflect.Value,
 	value reflect.Value,
 	resolveURI uriResolverFunc,
 ) (resolved, found bool, err error) {
-	// Find the referenced field in parent
-	refField := parentVal.FieldByName(refFrom)
-	if !refField.IsValid() {
-		return false, false, fmt.Errorf("refFrom field '%s' not found", refFrom)
+	// Find the referenced field, a sibling or a dotted path into nested structs
+	refField, refStructField, err := refFromField(parentVal, refFrom)
+	if err != nil {
+		return false, false, err
 	}
 
 	// Extract URI value from source field
-	uriVal, isExplicitlySet, err := extractRefFromValue(refFrom, refField, parentVal)
+	uriVal, isExplicitlySet, err := extractRefFromValue(refFrom, refField, refStructField)
 	if err != nil {
 		return false, false, err
 	}
@@ -175,61 +208,96 @@ func processRefFrom(
 
 	// Special case: Explicitly set empty string means "use empty value, stop fallback"
 	if uriVal == "" && isExplicitlySet {
-		err := types.Convert("", value)
+		err := types.ConvertContext(ctx, "", value)
 
 		return err == nil, true, err
 	}
 
 	// Resolve the URI
-	content, resolvedFromURI, err := resolveURI(uriVal)
+	resolvedFromURI, err := resolveURI(uriVal, mods, value)
 	if err != nil {
 		return false, false, err
 	}
 	if resolvedFromURI {
-		err := types.Convert(string(content), value)
-
-		return err == nil, true, err
+		return true, true, nil
 	}
 
 	// URI not found - allow fallback to ref tag
 	return false, false, nil
 }
 
+// refFromField finds the field named by a refFrom tag in parentVal: a
+// sibling field name, or a dotted path such as "TLS.CertPath" through nested
+// structs and pointers to structs. The returned value is invalid if a pointer
+// on the way is nil, so the field counts as unset.
+func refFromField(parentVal reflect.Value, path string) (reflect.Value, reflect.StructField, error) {
+	val, typ := parentVal, parentVal.Type()
+	var field reflect.StructField
+
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			for typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+				if val.IsValid() {
+					val = val.Elem()
+				}
+			}
+			if typ.Kind() != reflect.Struct {
+				return reflect.Value{}, field, fmt.Errorf("refFrom path '%s': '%s' is not a struct", path, field.Name)
+			}
+		}
+
+		f, ok := typ.FieldByName(name)
+		if !ok {
+			return reflect.Value{}, field, fmt.Errorf("refFrom field '%s' not found", path)
+		}
+		field, typ = f, f.Type
+		if val.IsValid() {
+			// A nil embedded pointer leaves the field unset
+			val, _ = val.FieldByIndexErr(f.Index)
+		}
+	}
+
+	return val, field, nil
+}
+
 // extractRefFromValue extracts the URI value from a refFrom source field.
+// refField is invalid if the field is behind a nil pointer.
 func extractRefFromValue(
 	refFrom string,
 	refField reflect.Value,
-	parentVal reflect.Value,
+	refStructField reflect.StructField,
 ) (uriVal string, isExplicitlySet bool, err error) {
 	// refFrom supports string or *string fields
+	refType := refStructField.Type
 	switch {
-	case refField.Kind() == reflect.String:
-		uriVal = refField.String()
+	case refType.Kind() == reflect.String:
+		if refField.IsValid() {
+			uriVal = refField.String()
+		}
 		// Basic strings are not "explicitly set" if empty, maintaining old behavior
-	case refField.Kind() == reflect.Pointer && refField.Type().Elem().Kind() == reflect.String:
-		if !refField.IsNil() {
+	case refType.Kind() == reflect.Pointer && refType.Elem().Kind() == reflect.String:
+		if refField.IsValid() && !refField.IsNil() {
 			uriVal = refField.Elem().String()
 			isExplicitlySet = true
 		}
 	default:
-		return "", false, fmt.Errorf("refFrom field '%s' must be string or *string, got %s", refFrom, refField.Kind())
+		return "", false, fmt.Errorf("refFrom field '%s' must be string or *string, got %s", refFrom, refType.Kind())
 	}
 
 	// "Peek" logic: if value is missing (empty and not explicit), check its default tag
 	if uriVal == "" && !isExplicitlySet {
-		parentType := parentVal.Type()
-		if f, ok := parentType.FieldByName(refFrom); ok {
-			defaultTag := f.Tag.Get("default")
-			if defaultTag != "" && defaultTag != "-" {
-				uriVal = defaultTag
-			}
+		defaultTag := Get(refStructField, "default")
+		if defaultTag != "" && defaultTag != "-" {
+			uriVal = defaultTag
 		}
 	}
 
 	return uriVal, isExplicitlySet, nil
 }
 
-func normalizeURI(uri string) string {
+// NormalizeURI adds the file:// scheme to URIs without a scheme.
+func NormalizeURI(uri string) string {
 	if strings.Contains(uri, "://") {
 		return uri
 	}
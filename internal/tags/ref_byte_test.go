@@ -58,7 +58,7 @@ func TestProcessRef_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved)
 		assert.Equal(t, []byte("binary-content-here"), s.Data)
@@ -74,7 +74,7 @@ func TestProcessRef_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved)
 		assert.Equal(t, []byte("binary-content-here"), s.Data)
@@ -89,7 +89,7 @@ func TestProcessRef_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved)
 		assert.Equal(t, []byte("binary-content-here"), s.Data)
@@ -115,7 +115,7 @@ func TestProcessRef_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("BinaryData")
 		val := v.FieldByName("BinaryData")
 
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved)
 		assert.Equal(t, binaryData, s.BinaryData)
@@ -131,7 +131,7 @@ func TestProcessDefault_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		err := tags.ProcessDefault(field, val)
+		err := tags.ProcessDefault(context.Background(), field, val)
 		require.NoError(t, err)
 		assert.Equal(t, []byte("default-content"), s.Data)
 	})
@@ -146,7 +146,7 @@ func TestProcessDefault_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		err := tags.ProcessDefault(field, val)
+		err := tags.ProcessDefault(context.Background(), field, val)
 		require.NoError(t, err)
 		assert.Equal(t, []byte("existing"), s.Data, "Should not overwrite existing value")
 	})
@@ -167,7 +167,7 @@ func TestProcessEnv_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		applied, err := tags.ProcessEnv(field, val, "")
+		applied, err := tags.ProcessEnv(context.Background(), field, val, "")
 		require.NoError(t, err)
 		require.True(t, applied)
 		assert.Equal(t, []byte("env-binary-content"), s.Data)
@@ -183,7 +183,7 @@ func TestProcessEnv_ByteSlice(t *testing.T) {
 		field, _ := typ.FieldByName("Data")
 		val := v.FieldByName("Data")
 
-		applied, err := tags.ProcessEnv(field, val, "APP_")
+		applied, err := tags.ProcessEnv(context.Background(), field, val, "APP_")
 		require.NoError(t, err)
 		require.True(t, applied)
 		assert.Equal(t, []byte("prefixed-env-content"), s.Data)
@@ -0,0 +1,227 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RefModifier post-processes the content resolved for a 'ref' or 'refFrom'
+// tag, such as "base64" or "jsonpath=$.db.password".
+type RefModifier struct {
+	// Name is "base64" or "jsonpath".
+	Name string
+	path []jsonPathStep // parsed path of a jsonpath modifier
+}
+
+// ParseRefTag splits the modifiers off a 'ref' or 'refFrom' tag value of the
+// form URI[,modifier...]. Only recognized trailing modifiers are split off,
+// so the URI itself may contain commas. Modifiers apply in the order given.
+func ParseRefTag(tag string) (string, []RefModifier, error) {
+	var mods []RefModifier
+
+	for {
+		i := strings.LastIndexByte(tag, ',')
+		if i < 0 {
+			break
+		}
+
+		opt := strings.TrimSpace(tag[i+1:])
+		var mod RefModifier
+		switch {
+		case opt == "base64":
+			mod.Name = opt
+		case strings.HasPrefix(opt, "jsonpath="):
+			path, err := parseJSONPath(strings.TrimPrefix(opt, "jsonpath="))
+			if err != nil {
+				return "", nil, fmt.Errorf("ref tag %q: %w", tag, err)
+			}
+			mod = RefModifier{Name: "jsonpath", path: path}
+		default:
+			return strings.TrimSpace(tag), mods, nil
+		}
+
+		mods = append([]RefModifier{mod}, mods...)
+		tag = tag[:i]
+	}
+
+	return strings.TrimSpace(tag), mods, nil
+}
+
+// applyRefModifiers runs content through mods in order.
+func applyRefModifiers(content []byte, mods []RefModifier) ([]byte, error) {
+	for _, mod := range mods {
+		var err error
+		switch mod.Name {
+		case "base64":
+			content, err = decodeRefBase64(content)
+		case "jsonpath":
+			content, err = extractJSONPath(content, mod.path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// decodeRefBase64 decodes standard base64, ignoring line breaks and other
+// whitespace such as those written by `base64` without -w0.
+func decodeRefBase64(content []byte) ([]byte, error) {
+	compact := bytes.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, content)
+
+	decoded, err := base64.StdEncoding.DecodeString(string(compact))
+	if err != nil {
+		return nil, fmt.Errorf("base64 modifier: content is not valid base64: %w", err)
+	}
+
+	return decoded, nil
+}
+
+// jsonPathStep is one step of a JSONPath: an object key or an array index.
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s jsonPathStep) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+
+	return "." + s.key
+}
+
+// parseJSONPath parses the supported JSONPath subset: "$" followed by
+// ".key", ["key"] and [index] steps.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("jsonpath %q must start with $", path)
+	}
+
+	var steps []jsonPathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("jsonpath %q has an empty key", path)
+			}
+			steps = append(steps, jsonPathStep{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q has an unclosed [", path)
+			}
+			inner := rest[1:end]
+			if key, err := strconv.Unquote(inner); err == nil && strings.HasPrefix(inner, `"`) {
+				steps = append(steps, jsonPathStep{key: key})
+			} else if index, err := strconv.Atoi(inner); err == nil && index >= 0 {
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			} else {
+				return nil, fmt.Errorf("jsonpath %q: [%s] is not an index or a double-quoted key", path, inner)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q", path, rest[0])
+		}
+	}
+
+	return steps, nil
+}
+
+// extractJSONPath returns the value at path in the JSON document content in
+// the text form expected by the field conversion: strings unquoted, arrays
+// of scalars as a CSV line (for slice fields), and other values as compact
+// JSON (numbers, booleans, and objects for struct fields).
+func extractJSONPath(content []byte, path []jsonPathStep) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("jsonpath modifier: content is not valid JSON: %w", err)
+	}
+
+	at := "$"
+	for _, step := range path {
+		var ok bool
+		switch node := v.(type) {
+		case map[string]any:
+			if !step.isIndex {
+				v, ok = node[step.key]
+			}
+		case []any:
+			if step.isIndex && step.index < len(node) {
+				v, ok = node[step.index], true
+			}
+		}
+		at += step.String()
+		if !ok {
+			return nil, fmt.Errorf("jsonpath modifier: no value at %s", at)
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case nil:
+		return nil, nil
+	case []any:
+		if line, ok := csvLine(v); ok {
+			return line, nil
+		}
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath modifier: %w", err)
+	}
+
+	return out, nil
+}
+
+// csvLine renders an array of scalars as one CSV line. It reports false if
+// the array holds objects or arrays.
+func csvLine(values []any) ([]byte, bool) {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case string:
+			record[i] = v
+		case json.Number:
+			record[i] = v.String()
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case nil:
+		default:
+			return nil, false
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(record)
+	w.Flush()
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), true
+}
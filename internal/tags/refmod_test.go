@@ -0,0 +1,127 @@
+package tags_test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolveWithModifiers resolves a string field tagged `ref:"file://x<mods>"`
+// against content.
+func resolveWithModifiers(t *testing.T, mods, content string) (string, error) {
+	t.Helper()
+
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeFor[string](),
+		Tag:  reflect.StructTag("ref:" + strconv.Quote("file://x"+mods)),
+	}})
+	v := reflect.New(typ).Elem()
+	resolver := &mockByteResolver{data: map[string][]byte{"file://x": []byte(content)}}
+
+	_, err := tags.ProcessRef(context.Background(), typ.Field(0), v.Field(0), v, resolver, "", nil, nil)
+
+	return v.Field(0).String(), err
+}
+
+func TestParseRefTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		uri  string
+		mods []string
+	}{
+		{"file:///run/secrets/blob", "file:///run/secrets/blob", nil},
+		{"file:///run/secrets/blob,base64", "file:///run/secrets/blob", []string{"base64"}},
+		{"http://cfg/app.json, jsonpath=$.db.password", "http://cfg/app.json", []string{"jsonpath"}},
+		{"file:///blob.json,base64,jsonpath=$.key", "file:///blob.json", []string{"base64", "jsonpath"}},
+		{"http://cfg/a,b.json", "http://cfg/a,b.json", nil},
+		{"http://cfg/a,b.json,base64", "http://cfg/a,b.json", []string{"base64"}},
+		{"Path,base64", "Path", []string{"base64"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			uri, mods, err := tags.ParseRefTag(tt.tag)
+			require.NoError(t, err)
+			assert.Equal(t, tt.uri, uri)
+
+			var names []string
+			for _, mod := range mods {
+				names = append(names, mod.Name)
+			}
+			assert.Equal(t, tt.mods, names)
+		})
+	}
+}
+
+func TestParseRefTag_InvalidJSONPath(t *testing.T) {
+	for tag, want := range map[string]string{
+		"file:///a.json,jsonpath=db.password": "must start with $",
+		"file:///a.json,jsonpath=$..a":        "has an empty key",
+		"file:///a.json,jsonpath=$.a[0":       "has an unclosed [",
+		"file:///a.json,jsonpath=$.a[x]":      "[x] is not an index or a double-quoted key",
+		"file:///a.json,jsonpath=$a":          "unexpected 'a'",
+	} {
+		_, _, err := tags.ParseRefTag(tag)
+		require.Error(t, err, tag)
+		assert.Contains(t, err.Error(), want, tag)
+	}
+}
+
+func TestProcessRef_Modifiers(t *testing.T) {
+	const doc = `{"db": {"password": "s3cret", "port": 5432, "hosts": ["a", "b"], "tls": null, "dotted.key": true}}`
+
+	tests := []struct {
+		tag     string
+		content string
+		want    string
+	}{
+		{",base64", "aGVsbG8=", "hello"},
+		{",base64", "aGVs\nbG8=\n", "hello"},
+		{",jsonpath=$.db.password", doc, "s3cret"},
+		{",jsonpath=$.db.port", doc, "5432"},
+		{",jsonpath=$.db.hosts[1]", doc, "b"},
+		{",jsonpath=$.db.hosts", doc, "a,b"},
+		{",jsonpath=$", `{"n": [1, "x,y", [2]]}`, `{"n":[1,"x,y",[2]]}`},
+		{",jsonpath=$.db.n", `{"db": {"n": [1, "x,y", true]}}`, `1,"x,y",true`},
+		{`,jsonpath=$.db["dotted.key"]`, doc, "true"},
+		{",jsonpath=$.db.tls", doc, ""},
+		{",jsonpath=$", `"root"`, "root"},
+		{",base64,jsonpath=$.k", "eyJrIjogInYifQ==", "v"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := resolveWithModifiers(t, tt.tag, tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProcessRef_ModifierErrors(t *testing.T) {
+	tests := []struct {
+		tag     string
+		content string
+		want    string
+	}{
+		{",base64", "not base64!", "content is not valid base64"},
+		{",jsonpath=$.a", "a: b", "content is not valid JSON"},
+		{",jsonpath=$.db.user", `{"db": {}}`, "no value at $.db.user"},
+		{",jsonpath=$.db[2]", `{"db": [1]}`, "no value at $.db[2]"},
+		{",jsonpath=$.db.a", `{"db": [1]}`, "no value at $.db.a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			_, err := resolveWithModifiers(t, tt.tag, tt.content)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
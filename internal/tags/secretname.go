@@ -0,0 +1,62 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// DefaultSecretsDir is where Docker, Swarm, and Kubernetes conventionally
+// mount secrets, one file per secret.
+const DefaultSecretsDir = "/run/secrets"
+
+// SecretNameURI returns the file URI read for the field's 'secretName' tag
+// in dir (DefaultSecretsDir if empty), with the modifiers of the tag, or ""
+// if the field has no such tag.
+func SecretNameURI(field reflect.StructField, dir string) (string, []RefModifier, error) {
+	tag := Get(field, "secretName")
+	if tag == "" {
+		return "", nil, nil
+	}
+
+	name, mods, err := ParseRefTag(tag)
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", nil, fmt.Errorf("secretName %q must be a file name without a directory", name)
+	}
+
+	if dir == "" {
+		dir = DefaultSecretsDir
+	}
+
+	return NormalizeURI(path.Join(dir, name)), mods, nil
+}
+
+// ProcessSecretName processes the 'secretName' tag, setting a zero value to
+// the content of the named file in dir:
+//
+//	Password string `secretName:"db_password"` // reads /run/secrets/db_password
+//
+// Modifiers follow the name as in a ref tag. Returns true if the file
+// exists and value was set; a missing file allows the default to apply.
+func ProcessSecretName(ctx context.Context, field reflect.StructField, value reflect.Value, resolver Resolver, dir string) (bool, error) {
+	if resolver == nil || !value.IsZero() {
+		return false, nil
+	}
+
+	uri, mods, err := SecretNameURI(field, dir)
+	if err != nil {
+		return false, &types.FieldError{Tag: "secretName", Err: err}
+	}
+	if uri == "" {
+		return false, nil
+	}
+
+	return resolveInto(ctx, resolver, "secretName", uri, mods, value)
+}
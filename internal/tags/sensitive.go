@@ -0,0 +1,34 @@
+package tags
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// RedactedValue replaces the value of sensitive fields in dumps and traces.
+const RedactedValue = "[REDACTED]"
+
+// IsSensitive reports whether a field's value must not appear in dumps or
+// trace output.
+//
+// An explicit `secret` (or `sensitive`) tag decides; otherwise fields
+// populated from refs (typically secret stores), secret files, KMS-decrypted
+// fields, and composed DSNs (which usually embed credentials) are treated as
+// sensitive.
+func IsSensitive(field reflect.StructField) bool {
+	for _, key := range []string{"secret", "sensitive"} {
+		if tag, ok := Lookup(field, key); ok {
+			secret, err := strconv.ParseBool(tag)
+
+			return err != nil || secret // unparsable values fail closed
+		}
+	}
+
+	for _, key := range []string{"ref", "refFrom", "secretName", "dsn", "kms"} {
+		if Get(field, key) != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+package tags_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSensitive(t *testing.T) {
+	type sample struct {
+		Plain     string
+		Secret    string `secret:"true"`
+		Sensitive string `sensitive:"1"`
+		Ref       string `ref:"file://token"`
+		RefFrom   string `refFrom:"Path"`
+		DSN       string `dsn:"postgres://{{.Plain}}"`
+		RefPublic string `ref:"file://hostname" secret:"false"`
+		Invalid   string `secret:"maybe"`
+		Default   string `default:"x"`
+	}
+
+	typ := reflect.TypeFor[sample]()
+	tests := map[string]bool{
+		"Plain":     false,
+		"Secret":    true,
+		"Sensitive": true,
+		"Ref":       true,
+		"RefFrom":   true,
+		"DSN":       true,
+		"RefPublic": false,
+		"Invalid":   true,
+		"Default":   false,
+	}
+
+	for name, want := range tests {
+		field, ok := typ.FieldByName(name)
+		assert.True(t, ok)
+		assert.Equal(t, want, tags.IsSensitive(field), name)
+	}
+}
@@ -29,7 +29,7 @@ func TestProcessDefault(t *testing.T) {
 	t.Run("apply default", func(t *testing.T) {
 		field, _ := typ.FieldByName("Field")
 		val := v.FieldByName("Field")
-		err := tags.ProcessDefault(field, val)
+		err := tags.ProcessDefault(context.Background(), field, val)
 		require.NoError(t, err)
 		assert.Equal(t, "default_val", s.Field)
 	})
@@ -38,7 +38,7 @@ func TestProcessDefault(t *testing.T) {
 		s.Field = "existing"
 		field, _ := typ.FieldByName("Field")
 		val := v.FieldByName("Field")
-		err := tags.ProcessDefault(field, val)
+		err := tags.ProcessDefault(context.Background(), field, val)
 		require.NoError(t, err)
 		assert.Equal(t, "existing", s.Field)
 	})
@@ -46,7 +46,7 @@ func TestProcessDefault(t *testing.T) {
 	t.Run("no default tag", func(t *testing.T) {
 		field, _ := typ.FieldByName("Empty")
 		val := v.FieldByName("Empty")
-		err := tags.ProcessDefault(field, val)
+		err := tags.ProcessDefault(context.Background(), field, val)
 		require.NoError(t, err)
 		assert.Equal(t, "", s.Empty)
 	})
@@ -63,7 +63,7 @@ func TestProcessEnv(t *testing.T) {
 
 		field, _ := typ.FieldByName("EnvField")
 		val := v.FieldByName("EnvField")
-		applied, err := tags.ProcessEnv(field, val, "")
+		applied, err := tags.ProcessEnv(context.Background(), field, val, "")
 		require.NoError(t, err)
 		require.True(t, applied)
 		assert.Equal(t, "env_val", s.EnvField)
@@ -75,13 +75,156 @@ func TestProcessEnv(t *testing.T) {
 
 		field, _ := typ.FieldByName("EnvField")
 		val := v.FieldByName("EnvField")
-		applied, err := tags.ProcessEnv(field, val, "APP_")
+		applied, err := tags.ProcessEnv(context.Background(), field, val, "APP_")
 		require.NoError(t, err)
 		require.True(t, applied)
 		assert.Equal(t, "prefixed_val", s.EnvField)
 	})
 }
 
+func TestParseEnvTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    tags.EnvTag
+		wantErr string
+	}{
+		{tag: "DB_HOST", want: tags.EnvTag{Name: "DB_HOST"}},
+		{tag: "DB_HOST,required", want: tags.EnvTag{Name: "DB_HOST", Required: true}},
+		{tag: "DB_HOST=localhost", want: tags.EnvTag{Name: "DB_HOST", Fallback: "localhost", HasFallback: true}},
+		{tag: "HOSTS=a,b", want: tags.EnvTag{Name: "HOSTS", Fallback: "a,b", HasFallback: true}},
+		{tag: "DB_HOST=", want: tags.EnvTag{Name: "DB_HOST", HasFallback: true}},
+		{tag: "DB_HOST,optional", wantErr: `unknown env tag option "optional"`},
+		{tag: ",required", wantErr: "no variable name"},
+		{tag: "DB_HOST=localhost,required", wantErr: "cannot have both"},
+		{tag: "FEATURE_*", want: tags.EnvTag{Name: "FEATURE_*"}},
+		{tag: "FEATURE_*_ON", wantErr: "only have * at the end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := tags.ParseEnvTag(tt.tag)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAutoEnvName(t *testing.T) {
+	tests := map[string]string{
+		"Host":                  "HOST",
+		"Database.Primary.Host": "DATABASE_PRIMARY_HOST",
+		"Server.MaxConns":       "SERVER_MAX_CONNS",
+		"HTTPServer.Port":       "HTTP_SERVER_PORT",
+		"DBHost":                "DB_HOST",
+		"Shard2Name":            "SHARD2_NAME",
+		"OAuth2":                "O_AUTH2",
+		"APIKey":                "API_KEY",
+		"Servers[0].Host":       "SERVERS_0_HOST",
+		"Groups[1].Servers[12]": "GROUPS_1_SERVERS_12",
+	}
+
+	for path, want := range tests {
+		assert.Equal(t, want, tags.AutoEnvName(path), path)
+	}
+}
+
+func TestProcessFlag(t *testing.T) {
+	type flagStruct struct {
+		Port    int    `flag:"port"`
+		Host    string `flag:"host"`
+		Ignored string `flag:"-"`
+		Plain   string
+	}
+
+	lookup := func(name string) (string, bool) {
+		switch name {
+		case "port":
+			return "7070", true
+		case "-", "Plain":
+			return "wrong", true
+		}
+
+		return "", false
+	}
+
+	var s flagStruct
+	val := reflect.ValueOf(&s).Elem()
+	typ := val.Type()
+
+	for i := range typ.NumField() {
+		applied, err := tags.ProcessFlag(context.Background(), typ.Field(i), val.Field(i), lookup)
+		require.NoError(t, err)
+		assert.Equal(t, typ.Field(i).Name == "Port", applied, typ.Field(i).Name)
+	}
+	assert.Equal(t, flagStruct{Port: 7070}, s)
+
+	applied, err := tags.ProcessFlag(context.Background(), typ.Field(0), val.Field(0), nil)
+	require.NoError(t, err)
+	assert.False(t, applied)
+}
+
+func TestParseFudaTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    map[string]string
+		wantErr string
+	}{
+		{tag: "", want: map[string]string{}},
+		{
+			tag:  "default=8080,env=APP_PORT,required",
+			want: map[string]string{"default": "8080", "env": "APP_PORT", "validate": "required"},
+		},
+		{
+			tag:  "validate='min=1,max=10', required ,secret",
+			want: map[string]string{"validate": "required,min=1,max=10", "secret": "true"},
+		},
+		{
+			tag:  "default='a,b',env='HOSTS=x,y'",
+			want: map[string]string{"default": "a,b", "env": "HOSTS=x,y"},
+		},
+		{tag: "port=80", wantErr: `unknown option "port=80"`},
+		{tag: "env", wantErr: `unknown option "env"`},
+		{tag: "env=A,env=B", wantErr: "env given more than once"},
+		{tag: "default='a", wantErr: "unterminated quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := tags.ParseFudaTag(tt.tag)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLookup_FudaTag(t *testing.T) {
+	type s struct {
+		Port int `env:"PORT" fuda:"env=APP_PORT,default=80"`
+		Bad  int `fuda:"nope=1"`
+	}
+	typ := reflect.TypeFor[s]()
+
+	assert.Equal(t, "PORT", tags.Get(typ.Field(0), "env"), "separate tag wins")
+	assert.Equal(t, "80", tags.Get(typ.Field(0), "default"))
+	_, ok := tags.Lookup(typ.Field(0), "ref")
+	assert.False(t, ok)
+
+	_, ok = tags.Lookup(typ.Field(1), "default")
+	assert.False(t, ok)
+	require.Error(t, tags.CheckFudaTag(typ.Field(1)))
+	require.NoError(t, tags.CheckFudaTag(typ.Field(0)))
+}
+
 type mockResolver struct {
 	data map[string][]byte
 }
@@ -108,7 +251,7 @@ func TestProcessRef(t *testing.T) {
 	t.Run("ref tag", func(t *testing.T) {
 		field, _ := typ.FieldByName("RefField")
 		val := v.FieldByName("RefField")
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "resolved_content", s.RefField)
 	})
@@ -122,7 +265,7 @@ func TestProcessRef(t *testing.T) {
 		field, _ := typ.FieldByName("RefFrom")
 		val := v.FieldByName("RefFrom") // RefFrom field
 
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "resolved_content", s.RefFrom)
 	})
@@ -134,150 +277,104 @@ func TestProcessRef(t *testing.T) {
 		field, _ := typ.FieldByName("RefFrom")
 		val := v.FieldByName("RefFrom")
 
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "resolved_content", s.RefFrom)
 	})
 }
 
-// Test struct for ref template tests
-type RefTemplateStruct struct {
-	SecretDir string `default:"/etc/secrets"`
-	Account   string `default:"admin"`
-	Password  string `ref:"file://${.SecretDir}/${.Account}-password"`
-}
-
-type RefTemplateNestedDatabase struct {
-	CredDir string
-}
-
-type RefTemplateNestedStruct struct {
-	Database RefTemplateNestedDatabase
-	Account  string
-	Password string `ref:"file://${.Database.CredDir}/${.Account}-password"`
-}
-
-type RefTemplateEnvStruct struct {
-	FileName string
-	Content  string `ref:"file://${env:TEST_REF_DIR}/${.FileName}"`
-}
+func TestRefFromNestedPath(t *testing.T) {
+	type TLS struct {
+		CertPath string
+		KeyPath  string `default:"key.pem"`
+	}
+	type Config struct {
+		TLS    TLS
+		Client *TLS
+		Name   string
+
+		Cert       string `refFrom:"TLS.CertPath"`
+		Key        string `refFrom:"TLS.KeyPath"`
+		ClientCert string `refFrom:"Client.CertPath" ref:"file://fallback.pem"`
+		ClientKey  string `refFrom:"Client.KeyPath"`
+		Bad        string `refFrom:"Name.Path"`
+		Missing    string `refFrom:"TLS.Nope"`
+	}
 
-func TestProcessRef_Template(t *testing.T) {
 	ctx := context.Background()
+	resolver := &mockResolver{
+		data: map[string][]byte{
+			"file://cert.pem":     []byte("cert"),
+			"file://key.pem":      []byte("key"),
+			"file://fallback.pem": []byte("fallback"),
+		},
+	}
+	process := func(s *Config, name string) (bool, error) {
+		v := reflect.ValueOf(s).Elem()
+		field, _ := v.Type().FieldByName(name)
 
-	t.Run("template with field references", func(t *testing.T) {
-		s := RefTemplateStruct{
-			SecretDir: "/data/secrets",
-			Account:   "myuser",
-		}
-		v := reflect.ValueOf(&s).Elem()
-		typ := v.Type()
-
-		resolver := &mockResolver{
-			data: map[string][]byte{
-				"file:///data/secrets/myuser-password": []byte("secret123"),
-			},
-		}
+		return tags.ProcessRef(ctx, field, v.FieldByName(name), v, resolver, "", nil, nil)
+	}
 
-		field, _ := typ.FieldByName("Password")
-		val := v.FieldByName("Password")
+	t.Run("nested struct", func(t *testing.T) {
+		s := Config{TLS: TLS{CertPath: "cert.pem"}}
+		_, err := process(&s, "Cert")
+		require.NoError(t, err)
+		assert.Equal(t, "cert", s.Cert)
 
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		_, err = process(&s, "Key")
 		require.NoError(t, err)
-		assert.Equal(t, "secret123", s.Password)
+		assert.Equal(t, "key", s.Key, "default of the nested field is peeked")
 	})
 
-	t.Run("template with nested field references", func(t *testing.T) {
-		s := RefTemplateNestedStruct{
-			Account: "testuser",
-		}
-		s.Database.CredDir = "/var/creds"
-
-		v := reflect.ValueOf(&s).Elem()
-		typ := v.Type()
-
-		resolver := &mockResolver{
-			data: map[string][]byte{
-				"file:///var/creds/testuser-password": []byte("nestedpass"),
-			},
-		}
-
-		field, _ := typ.FieldByName("Password")
-		val := v.FieldByName("Password")
-
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+	t.Run("through pointer", func(t *testing.T) {
+		s := Config{Client: &TLS{CertPath: "cert.pem"}}
+		_, err := process(&s, "ClientCert")
 		require.NoError(t, err)
-		assert.Equal(t, "nestedpass", s.Password)
+		assert.Equal(t, "cert", s.ClientCert)
 	})
 
-	t.Run("template with env function", func(t *testing.T) {
-		t.Setenv("TEST_REF_DIR", "/secrets")
-
-		s := RefTemplateEnvStruct{
-			FileName: "password.txt",
-		}
-		v := reflect.ValueOf(&s).Elem()
-		typ := v.Type()
-
-		resolver := &mockResolver{
-			data: map[string][]byte{
-				"file:///secrets/password.txt": []byte("envpass"),
-			},
-		}
-
-		field, _ := typ.FieldByName("Content")
-		val := v.FieldByName("Content")
+	t.Run("nil pointer counts as unset", func(t *testing.T) {
+		var s Config
+		_, err := process(&s, "ClientCert")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", s.ClientCert)
 
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		_, err = process(&s, "ClientKey")
 		require.NoError(t, err)
-		assert.Equal(t, "envpass", s.Content)
+		assert.Equal(t, "key", s.ClientKey, "default is peeked behind a nil pointer")
 	})
 
-	t.Run("template with env function and prefix", func(t *testing.T) {
-		t.Setenv("APP_TEST_REF_DIR", "/app/secrets")
-
-		s := RefTemplateEnvStruct{
-			FileName: "creds.txt",
-		}
-		v := reflect.ValueOf(&s).Elem()
-		typ := v.Type()
-
-		resolver := &mockResolver{
-			data: map[string][]byte{
-				"file:///app/secrets/creds.txt": []byte("prefixedpass"),
-			},
-		}
-
-		field, _ := typ.FieldByName("Content")
-		val := v.FieldByName("Content")
+	t.Run("invalid paths", func(t *testing.T) {
+		var s Config
+		_, err := process(&s, "Bad")
+		require.ErrorContains(t, err, "refFrom path 'Name.Path': 'Name' is not a struct")
 
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "APP_", nil)
-		require.NoError(t, err)
-		assert.Equal(t, "prefixedpass", s.Content)
+		_, err = process(&s, "Missing")
+		require.ErrorContains(t, err, "refFrom field 'TLS.Nope' not found")
 	})
+}
 
-	t.Run("template with missing field uses empty string", func(t *testing.T) {
-		// When a referenced field is empty, template should produce empty string
-		s := RefTemplateStruct{
-			SecretDir: "/etc/secrets",
-			Account:   "", // Empty account
-		}
-		v := reflect.ValueOf(&s).Elem()
-		typ := v.Type()
+// Test struct for ref template tests
+type RefTemplateStruct struct {
+	SecretDir string `default:"/etc/secrets"`
+	Account   string `default:"admin"`
+	Password  string `ref:"file://${.SecretDir}/${.Account}-password"`
+}
 
-		resolver := &mockResolver{
-			data: map[string][]byte{
-				"file:///etc/secrets/-password": []byte("emptyaccount"),
-			},
-		}
+type RefTemplateNestedDatabase struct {
+	CredDir string
+}
 
-		field, _ := typ.FieldByName("Password")
-		val := v.FieldByName("Password")
+type RefTemplateNestedStruct struct {
+	Database RefTemplateNestedDatabase
+	Account  string
+	Password string `ref:"file://${.Database.CredDir}/${.Account}-password"`
+}
 
-		_, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
-		require.NoError(t, err)
-		assert.Equal(t, "emptyaccount", s.Password)
-	})
+type RefTemplateEnvStruct struct {
+	FileName string
+	Content  string `ref:"file://${env:TEST_REF_DIR}/${.FileName}"`
 }
 
 func TestRefFromPointerSupport(t *testing.T) {
@@ -315,7 +412,7 @@ func TestRefFromPointerSupport(t *testing.T) {
 	t.Run("nil pointer falls back", func(t *testing.T) {
 		field, _ := typ.FieldByName("SecretNil")
 		val := v.FieldByName("SecretNil")
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved, "Should resolve from ref tag")
 		assert.Equal(t, "fallback-used", s.SecretNil)
@@ -324,7 +421,7 @@ func TestRefFromPointerSupport(t *testing.T) {
 	t.Run("empty pointer stops fallback", func(t *testing.T) {
 		field, _ := typ.FieldByName("SecretEmpty")
 		val := v.FieldByName("SecretEmpty")
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved, "Explicit empty pointer should mark as resolved")
 		assert.Equal(t, "", s.SecretEmpty, "Should use empty value from source")
@@ -333,7 +430,7 @@ func TestRefFromPointerSupport(t *testing.T) {
 	t.Run("value pointer uses value", func(t *testing.T) {
 		field, _ := typ.FieldByName("SecretVal")
 		val := v.FieldByName("SecretVal")
-		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil)
+		resolved, err := tags.ProcessRef(ctx, field, val, v, resolver, "", nil, nil)
 		require.NoError(t, err)
 		assert.True(t, resolved, "Value pointer should resolve")
 		assert.Equal(t, "resolved-from-source", s.SecretVal)
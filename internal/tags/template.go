@@ -1,3 +1,5 @@
+//go:build !fuda_minimal
+
 package tags
 
 import (
@@ -5,26 +7,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/url"
 	"os"
-	"reflect"
 	"strings"
 	"text/template"
+	"text/template/parse"
 )
 
-// TemplateConfig holds configuration for template processing.
-type TemplateConfig struct {
-	// Strict controls error behavior for empty/undefined values.
-	// If true, returns an error when a referenced field is empty or undefined.
-	// If false (default), outputs empty string for missing values.
-	Strict bool
-
-	// Resolver for ${ref:uri} function in templates.
-	Resolver Resolver
-
-	// EnvPrefix for ${env:KEY} function in templates.
-	EnvPrefix string
-}
-
 // ProcessTemplate expands ${...} template expressions in a string.
 //
 // Template syntax (uses ${...} delimiters):
@@ -32,6 +23,12 @@ type TemplateConfig struct {
 //   - ${.Nested.Field} - references nested struct fields
 //   - ${ref:uri} or ${ref "uri"} - resolves a URI inline using the resolver
 //   - ${env:KEY} or ${env "KEY"} - reads an environment variable
+//   - ${.Field | urlquery} - percent-encodes a value for use in a URL
+//   - ${.Name | upper} - library functions (upper, lower, trimSuffix, join,
+//     b64enc, sha256, default) and config.Funcs
+//
+// With config.Escape set to "url", every ${...} value is percent-encoded as
+// if it ended with "| urlquery".
 //
 // Note: Fields referenced in templates must appear earlier in the struct
 // to have their values available (due to sequential field processing).
@@ -41,27 +38,19 @@ func ProcessTemplate(ctx context.Context, templateStr string, data any, config T
 	// ${env:KEY} -> ${env "KEY"}
 	processedTemplate := preprocessTemplate(templateStr)
 
-	// Build template with custom functions and ${...} delimiters
-	funcMap := template.FuncMap{
-		"ref": makeRefFunc(ctx, config.Resolver),
-		"env": makeEnvFunc(config.EnvPrefix),
-	}
-
-	// Configure missing key behavior based on strict mode
-	missingKeyOpt := "missingkey=zero" // Default: return zero value
-	if config.Strict {
-		missingKeyOpt = "missingkey=error" // Strict: return error on missing field
-	}
-
-	tmpl, err := template.New("template").
-		Delims("${", "}").
-		Funcs(funcMap).
-		Option(missingKeyOpt).
-		Parse(processedTemplate)
+	tmpl, err := newTemplate(ctx, config).Parse(processedTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
+	switch config.Escape {
+	case "":
+	case "url":
+		escapeActions(tmpl.Tree, tmpl.Tree.Root)
+	default:
+		return "", fmt.Errorf("unknown escape mode '%s', want 'url'", config.Escape)
+	}
+
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
 		// In strict mode, missing keys return an exec error
@@ -79,6 +68,107 @@ func ProcessTemplate(ctx context.Context, templateStr string, data any, config T
 	return buf.String(), nil
 }
 
+// newTemplate returns an empty template with ${...} delimiters, the
+// function library, and the missing key behavior of config.
+func newTemplate(ctx context.Context, config TemplateConfig) *template.Template {
+	// Custom functions may replace library functions, but not ref, env, and
+	// urlquery
+	funcMap := template.FuncMap{}
+	maps.Copy(funcMap, templateFuncs)
+	maps.Copy(funcMap, config.Funcs)
+	funcMap["ref"] = makeRefFunc(ctx, config.Resolver)
+	funcMap["env"] = makeEnvFunc(config.EnvPrefix)
+	// Replaces the builtin, which encodes spaces as "+" (only valid in
+	// query strings, not in userinfo or paths).
+	funcMap["urlquery"] = urlEscape
+
+	// Configure missing key behavior based on strict mode
+	missingKeyOpt := "missingkey=zero" // Default: return zero value
+	if config.Strict {
+		missingKeyOpt = "missingkey=error" // Strict: return error on missing field
+	}
+
+	return template.New("template").
+		Delims("${", "}").
+		Funcs(funcMap).
+		Option(missingKeyOpt)
+}
+
+// evalTemplateValue evaluates the pipeline of a single ${...} expression,
+// such as ".Workers" or "ref:file:///n", and returns its value with its Go
+// type, rather than formatted as text.
+func evalTemplateValue(ctx context.Context, pipeline string, data any, config TemplateConfig) (any, error) {
+	// Expand shorthand calls, then wrap the pipeline to capture its value
+	processed := preprocessTemplate("${" + pipeline + "}")
+	pipeline = processed[len("${") : len(processed)-len("}")]
+
+	var result any
+	capture := template.FuncMap{"exprValue": func(v any) string {
+		result = v
+
+		return ""
+	}}
+	tmpl, err := newTemplate(ctx, config).Funcs(capture).Parse("${exprValue (" + pipeline + ")}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return result, nil
+}
+
+// urlEscape percent-encodes its arguments, formatted as by fmt.Sprint, for
+// use anywhere in a URL: "p@ss w/rd" becomes "p%40ss%20w%2Frd".
+func urlEscape(args ...any) string {
+	return strings.ReplaceAll(url.QueryEscape(fmt.Sprint(args...)), "+", "%20")
+}
+
+// escapeActions appends urlquery to the pipeline of every action in list
+// that prints a value, unless it already ends with urlquery. Variable
+// declarations print nothing and are left alone.
+func escapeActions(tree *parse.Tree, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) > 0 || endsWithURLQuery(n.Pipe) {
+				continue
+			}
+			ident := parse.NewIdentifier("urlquery").SetTree(tree).SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{ident},
+			})
+		case *parse.IfNode:
+			escapeActions(tree, n.List)
+			escapeActions(tree, n.ElseList)
+		case *parse.RangeNode:
+			escapeActions(tree, n.List)
+			escapeActions(tree, n.ElseList)
+		case *parse.WithNode:
+			escapeActions(tree, n.List)
+			escapeActions(tree, n.ElseList)
+		}
+	}
+}
+
+// endsWithURLQuery reports whether pipe already ends with a urlquery call.
+func endsWithURLQuery(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) == 0 {
+		return false
+	}
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	ident, ok := last.Args[0].(*parse.IdentifierNode)
+
+	return ok && ident.Ident == "urlquery"
+}
+
 // preprocessTemplate converts shorthand syntax to Go template function calls.
 //   - ${ref:uri} -> ${ref "uri"}
 //   - ${env:KEY} -> ${env "KEY"}
@@ -132,24 +222,6 @@ func preprocessFunc(tag, funcName string) string {
 	return result.String()
 }
 
-// findClosingBrace finds the index of the closing } for a template expression.
-func findClosingBrace(s string) int {
-	depth := 0
-	for i, c := range s {
-		switch c {
-		case '{':
-			depth++
-		case '}':
-			if depth == 0 {
-				return i
-			}
-			depth--
-		}
-	}
-
-	return -1
-}
-
 // makeRefFunc creates a template function that resolves URIs.
 // Accepts variadic args to support both quoted and unquoted usage:
 //   - ${ref "vault:///secret#pass"} - quoted string
@@ -168,7 +240,7 @@ func makeRefFunc(ctx context.Context, resolver Resolver) func(...string) (string
 		uri := strings.Join(parts, " ")
 
 		// Normalize the URI (add file:// prefix if needed)
-		uri = normalizeURI(uri)
+		uri = NormalizeURI(uri)
 
 		content, err := resolver.Resolve(ctx, uri)
 		if err != nil {
@@ -200,61 +272,3 @@ func makeEnvFunc(prefix string) func(...string) string {
 		return os.Getenv(envKey)
 	}
 }
-
-// StructToData converts a reflect.Value to an interface suitable for template execution.
-// This preserves struct types so that nested field access works (e.g., ${.Database.Host}).
-func StructToData(v reflect.Value) any {
-	if v.Kind() == reflect.Pointer {
-		if v.IsNil() {
-			return nil
-		}
-		v = v.Elem()
-	}
-
-	if v.Kind() != reflect.Struct {
-		return nil
-	}
-
-	// Return the struct directly to allow nested field access
-	if v.CanInterface() {
-		return v.Interface()
-	}
-
-	// Fallback to map for unexported structs
-	return structToMap(v)
-}
-
-// structToMap converts a reflect.Value of a struct to a map[string]any.
-// This is a fallback when the struct cannot be used directly.
-func structToMap(v reflect.Value) map[string]any {
-	if v.Kind() == reflect.Pointer {
-		if v.IsNil() {
-			return nil
-		}
-		v = v.Elem()
-	}
-
-	if v.Kind() != reflect.Struct {
-		return nil
-	}
-
-	result := make(map[string]any)
-	t := v.Type()
-
-	for i := range v.NumField() {
-		field := t.Field(i)
-		fieldVal := v.Field(i)
-
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
-		}
-
-		// Get the interface value
-		if fieldVal.CanInterface() {
-			result[field.Name] = fieldVal.Interface()
-		}
-	}
-
-	return result
-}
@@ -0,0 +1,101 @@
+package tags
+
+import "reflect"
+
+// TemplateConfig holds configuration for template processing.
+type TemplateConfig struct {
+	// Strict controls error behavior for empty/undefined values.
+	// If true, returns an error when a referenced field is empty or undefined.
+	// If false (default), outputs empty string for missing values.
+	Strict bool
+
+	// Resolver for ${ref:uri} function in templates.
+	Resolver Resolver
+
+	// EnvPrefix for ${env:KEY} function in templates.
+	EnvPrefix string
+
+	// Escape sets how ${...} values are escaped. "url" percent-encodes
+	// every value; "" (default) inserts values as-is.
+	Escape string
+
+	// Funcs adds template functions, replacing library functions of the
+	// same name (see WithTagTemplateFuncs).
+	Funcs map[string]any
+}
+
+// StructToData converts a reflect.Value to an interface suitable for template execution.
+// This preserves struct types so that nested field access works (e.g., ${.Database.Host}).
+func StructToData(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Return the struct directly to allow nested field access
+	if v.CanInterface() {
+		return v.Interface()
+	}
+
+	// Fallback to map for unexported structs
+	return structToMap(v)
+}
+
+// structToMap converts a reflect.Value of a struct to a map[string]any.
+// This is a fallback when the struct cannot be used directly.
+func structToMap(v reflect.Value) map[string]any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	result := make(map[string]any)
+	t := v.Type()
+
+	for i := range v.NumField() {
+		field := t.Field(i)
+		fieldVal := v.Field(i)
+
+		// Skip unexported fields
+		if !field.IsExported() {
+			continue
+		}
+
+		// Get the interface value
+		if fieldVal.CanInterface() {
+			result[field.Name] = fieldVal.Interface()
+		}
+	}
+
+	return result
+}
+
+// findClosingBrace finds the index of the closing } for a template expression.
+func findClosingBrace(s string) int {
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
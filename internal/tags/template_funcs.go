@@ -0,0 +1,66 @@
+//go:build !fuda_minimal
+
+package tags
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// templateFuncs is the function library of ${...} templates in ref and dsn
+// tags, a subset of the sprig functions with the same names and argument
+// order, so the last argument can be piped in: ${.Name | trimSuffix "-"}.
+var templateFuncs = map[string]any{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"join":       joinFunc,
+	"b64enc":     func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"sha256":     sha256Func,
+	"default":    defaultFunc,
+}
+
+// joinFunc joins the elements of a slice or array, formatted as by
+// fmt.Sprint, with sep: ${join "," .Hosts}.
+func joinFunc(sep string, list any) (string, error) {
+	v := reflect.ValueOf(list)
+	//nolint:exhaustive // only lists can be joined
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Invalid:
+		return "", nil
+	default:
+		return "", fmt.Errorf("join: want a slice, got %T", list)
+	}
+
+	parts := make([]string, v.Len())
+	for i := range v.Len() {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+
+	return strings.Join(parts, sep), nil
+}
+
+// sha256Func returns the hex SHA-256 digest of s.
+func sha256Func(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// defaultFunc returns given, or def if given is empty (nil or a zero
+// value): ${.Port | default 5432}.
+func defaultFunc(def any, given ...any) any {
+	if len(given) == 0 || given[0] == nil {
+		return def
+	}
+	if v := reflect.ValueOf(given[0]); v.IsZero() {
+		return def
+	}
+
+	return given[0]
+}
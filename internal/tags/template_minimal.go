@@ -0,0 +1,18 @@
+//go:build fuda_minimal
+
+package tags
+
+import (
+	"context"
+	"errors"
+)
+
+// ProcessTemplate fails: fuda_minimal builds have no template support.
+func ProcessTemplate(_ context.Context, _ string, _ any, _ TemplateConfig) (string, error) {
+	return "", errors.New("${...} templates are not supported in fuda_minimal builds")
+}
+
+// evalTemplateValue fails: fuda_minimal builds have no template support.
+func evalTemplateValue(_ context.Context, _ string, _ any, _ TemplateConfig) (any, error) {
+	return nil, errors.New("${...} templates are not supported in fuda_minimal builds")
+}
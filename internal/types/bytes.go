@@ -85,5 +85,5 @@ func splitNumberUnit(s string) (numStr, unitStr string, err error) {
 		return "", "", fmt.Errorf("invalid size format: %s", s)
 	}
 
-	return s[:unitStart], s[unitStart:], nil
+	return strings.TrimSpace(s[:unitStart]), strings.TrimSpace(s[unitStart:]), nil
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Scanner is an interface for custom string-to-type conversion.
@@ -18,10 +21,42 @@ type Scanner interface {
 
 // Convert converts a string value to the target reflect.Value's type.
 func Convert(value string, target reflect.Value) error {
+	return convert(value, target, options{})
+}
+
+// options tune convert for one load: decode hooks, which take precedence
+// over the built-in conversions, and the units integers accept.
+type options struct {
+	hooks          []DecodeHook
+	plainDurations bool   // time.Duration takes no day suffix
+	plainSizes     bool   // integers take no byte-size units
+	itemSep        string // splits slice items and map entries instead of CSV
+	keyValueSep    string // splits map keys from values instead of ':'
+}
+
+// elem returns the options for the elements of a slice or map, which are
+// split with the default separators.
+func (o options) elem() options {
+	o.itemSep, o.keyValueSep = "", ""
+
+	return o
+}
+
+// convert is Convert with opts.
+func convert(value string, target reflect.Value, opts options) error {
 	if !target.CanSet() {
 		return nil
 	}
 
+	if v, ok, err := Decode(opts.hooks, value, target.Type()); ok {
+		if err != nil {
+			return err
+		}
+		target.Set(v)
+
+		return nil
+	}
+
 	// Handle custom types that implement Scanner
 	if target.CanAddr() {
 		if scanner, ok := target.Addr().Interface().(Scanner); ok {
@@ -36,19 +71,19 @@ func Convert(value string, target reflect.Value) error {
 	case reflect.Bool:
 		return convertBool(value, target)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return convertInt(value, target)
+		return convertInt(value, target, opts)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return convertUint(value, target)
+		return convertUint(value, target, opts)
 	case reflect.Float32, reflect.Float64:
 		return convertFloat(value, target)
 	case reflect.Slice:
-		return convertSlice(value, target)
+		return convertSlice(value, target, opts)
 	case reflect.Map:
-		return convertMap(value, target)
+		return convertMap(value, target, opts)
 	case reflect.Struct:
 		return convertStruct(value, target)
 	case reflect.Pointer:
-		return convertPointer(value, target)
+		return convertPointer(value, target, opts)
 	default:
 		return fmt.Errorf("unsupported type: %s", target.Kind())
 	}
@@ -66,10 +101,14 @@ func convertBool(value string, target reflect.Value) error {
 	return nil
 }
 
-func convertInt(value string, target reflect.Value) error {
+func convertInt(value string, target reflect.Value, opts options) error {
 	// Special handling for Duration
 	if target.Type() == reflect.TypeFor[time.Duration]() {
-		d, err := parseDuration(value)
+		parse := parseDuration
+		if opts.plainDurations {
+			parse = time.ParseDuration
+		}
+		d, err := parse(value)
 		if err != nil {
 			return err
 		}
@@ -79,7 +118,11 @@ func convertInt(value string, target reflect.Value) error {
 	}
 
 	// 1. Try generic byte parsing (handles raw numbers and size strings)
-	v, err := ParseBytes(value)
+	parse := ParseBytes
+	if opts.plainSizes {
+		parse = func(s string) (int64, error) { return strconv.ParseInt(strings.TrimSpace(s), 10, 64) }
+	}
+	v, err := parse(value)
 	if err != nil {
 		return err
 	}
@@ -143,8 +186,12 @@ func parseDuration(s string) (time.Duration, error) {
 	return time.ParseDuration(result.String())
 }
 
-func convertUint(value string, target reflect.Value) error {
-	v, err := ParseBytesUint(value)
+func convertUint(value string, target reflect.Value, opts options) error {
+	parse := ParseBytesUint
+	if opts.plainSizes {
+		parse = func(s string) (uint64, error) { return strconv.ParseUint(strings.TrimSpace(s), 10, 64) }
+	}
+	v, err := parse(value)
 	if err != nil {
 		return err
 	}
@@ -169,23 +216,25 @@ func convertFloat(value string, target reflect.Value) error {
 	return nil
 }
 
-func convertSlice(value string, target reflect.Value) error {
+func convertSlice(value string, target reflect.Value, opts options) error {
 	// Special case: []byte should receive raw bytes, not CSV-parsed
 	if target.Type().Elem().Kind() == reflect.Uint8 {
 		target.SetBytes([]byte(value))
 		return nil
 	}
 
-	reader := csv.NewReader(strings.NewReader(value))
-	reader.TrimLeadingSpace = true
-	parts, err := reader.Read()
+	if isJSONLiteral(value, '[', ']') {
+		return convertJSON(value, target)
+	}
+
+	parts, err := splitItems(value, opts.itemSep)
 	if err != nil {
 		return fmt.Errorf("failed to parse csv slice: %w", err)
 	}
 
 	slice := reflect.MakeSlice(target.Type(), len(parts), len(parts))
 	for i, part := range parts {
-		if err := Convert(part, slice.Index(i)); err != nil {
+		if err := convert(part, slice.Index(i), opts.elem()); err != nil {
 			return err
 		}
 	}
@@ -194,21 +243,27 @@ func convertSlice(value string, target reflect.Value) error {
 	return nil
 }
 
-func convertMap(value string, target reflect.Value) error {
+func convertMap(value string, target reflect.Value, opts options) error {
+	if isJSONLiteral(value, '{', '}') {
+		return convertJSON(value, target)
+	}
+
 	// format: key:value,key2:value2 (supports quoting via CSV)
-	reader := csv.NewReader(strings.NewReader(value))
-	reader.TrimLeadingSpace = true
-	parts, err := reader.Read()
+	parts, err := splitItems(value, opts.itemSep)
 	if err != nil {
 		return fmt.Errorf("failed to parse csv map: %w", err)
 	}
+	keyValueSep := opts.keyValueSep
+	if keyValueSep == "" {
+		keyValueSep = ":"
+	}
 
 	resultMap := reflect.MakeMap(target.Type())
 	keyType := target.Type().Key()
 	elemType := target.Type().Elem()
 
 	for _, part := range parts {
-		kv := strings.SplitN(part, ":", 2)
+		kv := strings.SplitN(part, keyValueSep, 2)
 		if len(kv) != 2 {
 			return fmt.Errorf("invalid map item format: %s", part)
 		}
@@ -216,12 +271,12 @@ func convertMap(value string, target reflect.Value) error {
 		valStr := strings.TrimSpace(kv[1])
 
 		keyVal := reflect.New(keyType).Elem()
-		if err := Convert(keyStr, keyVal); err != nil {
+		if err := convert(keyStr, keyVal, opts.elem()); err != nil {
 			return err
 		}
 
 		elemVal := reflect.New(elemType).Elem()
-		if err := Convert(valStr, elemVal); err != nil {
+		if err := convert(valStr, elemVal, opts.elem()); err != nil {
 			return err
 		}
 
@@ -232,7 +287,62 @@ func convertMap(value string, target reflect.Value) error {
 	return nil
 }
 
+// isJSONLiteral reports whether value is a JSON array or object, as marked
+// by its first and last bytes. Values that only look like one, such as
+// "[a]", keep their CSV meaning.
+func isJSONLiteral(value string, first, last byte) bool {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) < 2 || trimmed[0] != first || trimmed[len(trimmed)-1] != last {
+		return false
+	}
+
+	return json.Valid([]byte(trimmed))
+}
+
+// convertJSON decodes a JSON array or object into a slice or map, such as
+// `[{"host": "a"}, {"host": "b"}]` for a []Server. JSON is decoded as YAML,
+// so struct fields are matched by their yaml names, as in a config file.
+func convertJSON(value string, target reflect.Value) error {
+	v := reflect.New(target.Type())
+	dec := yaml.NewDecoder(strings.NewReader(value))
+	dec.KnownFields(true)
+	if err := dec.Decode(v.Interface()); err != nil {
+		return fmt.Errorf("failed to unmarshal json to %s: %w", target.Type(), err)
+	}
+	target.Set(v.Elem())
+
+	return nil
+}
+
+// splitItems splits the items of a slice or map value at sep, trimming
+// spaces around them, or as a CSV record if sep is empty, so items may be
+// quoted. An empty value has no items.
+func splitItems(value, sep string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	if sep != "" {
+		parts := strings.Split(value, sep)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		return parts, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.TrimLeadingSpace = true
+
+	return reader.Read()
+}
+
 func convertStruct(value string, target reflect.Value) error {
+	// Types such as decimals and time.Time parse their own text form
+	if u, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
 	// Attempt JSON unmarshal if value looks like JSON object
 	trimmed := strings.TrimSpace(value)
 	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
@@ -246,10 +356,10 @@ func convertStruct(value string, target reflect.Value) error {
 	return fmt.Errorf("unsupported conversion to struct for value: %s", value)
 }
 
-func convertPointer(value string, target reflect.Value) error {
+func convertPointer(value string, target reflect.Value, opts options) error {
 	if target.IsNil() {
 		target.Set(reflect.New(target.Type().Elem()))
 	}
 
-	return Convert(value, target.Elem())
+	return convert(value, target.Elem(), opts)
 }
@@ -82,14 +82,23 @@ func TestConvert(t *testing.T) {
 		// Slices
 		{"slice string", "a,b,c", new([]string), []string{"a", "b", "c"}, false},
 		{"slice int", "1,2,3", new([]int), []int{1, 2, 3}, false},
+		{"slice json", `[1, 2]`, new([]int), []int{1, 2}, false},
+		{"slice json structs", `[{"val":"a"},{"val":"b"}]`, new([]Nested), []Nested{{Val: "a"}, {Val: "b"}}, false},
+		{"slice not json", "[a]", new([]string), []string{"[a]"}, false},
+		{"slice json unknown key", `[{"other":"a"}]`, new([]Nested), nil, true},
 
 		// Maps
 		{"map string", "key:val,key2:val2", new(map[string]string), map[string]string{"key": "val", "key2": "val2"}, false},
 		{"map int", "key:1,key2:2", new(map[string]int), map[string]int{"key": 1, "key2": 2}, false},
+		{"map json structs", `{"a":{"val":"x"}}`, new(map[string]Nested), map[string]Nested{"a": {Val: "x"}}, false},
+		{"map json nested", `{"a":{"b":1}}`, new(map[string]map[string]int), map[string]map[string]int{"a": {"b": 1}}, false},
 
 		// Struct (JSON)
 		{"struct", `{"Val":"test"}`, new(Nested), Nested{Val: "test"}, false},
 
+		// Struct (TextUnmarshaler)
+		{"text unmarshaler", "2024-01-02T03:04:05Z", new(time.Time), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+
 		// Pointer
 		{"pointer", "123", new(*int), func() *int {
 			i := 123
@@ -101,6 +110,7 @@ func TestConvert(t *testing.T) {
 		{"invalid int", "abc", new(int), nil, true},
 		{"invalid bool", "notbool", new(bool), nil, true},
 		{"invalid map", "invalid", new(map[string]string), nil, true},
+		{"invalid text unmarshaler", "yesterday", new(time.Time), nil, true},
 	}
 
 	for _, tt := range tests {
@@ -6,9 +6,12 @@ import (
 )
 
 // FieldError represents an error that occurred while processing a specific field.
+// Tag processing returns it without a Path; the loader fills in the path of
+// the field.
 type FieldError struct {
 	Path    string // e.g., "Database.Port"
-	Tag     string // e.g., "env", "default"
+	Tag     string // the tag whose source failed, e.g., "env", "default"
+	Source  string // where the failing value came from, e.g., "APP_PORT", "vault:///secret/db#password"
 	Value   string // the invalid value
 	Message string
 	Err     error
@@ -21,9 +24,20 @@ func (e *FieldError) Error() string {
 	sb.WriteString(e.Path)
 	sb.WriteString("'")
 
-	if e.Tag != "" {
+	switch {
+	case e.Tag != "" && e.Source != "":
 		sb.WriteString(" (tag '")
 		sb.WriteString(e.Tag)
+		sb.WriteString("', source '")
+		sb.WriteString(e.Source)
+		sb.WriteString("')")
+	case e.Tag != "":
+		sb.WriteString(" (tag '")
+		sb.WriteString(e.Tag)
+		sb.WriteString("')")
+	case e.Source != "":
+		sb.WriteString(" (source '")
+		sb.WriteString(e.Source)
 		sb.WriteString("')")
 	}
 
@@ -52,6 +66,7 @@ func (e *FieldError) Unwrap() error {
 }
 
 // LoadError represents an error that occurred during the configuration loading process.
+// It collects the errors of every field that failed, in field order.
 type LoadError struct {
 	Source string // file path or source name
 	Errors []FieldError
@@ -78,6 +93,17 @@ func (e *LoadError) Error() string {
 	return sb.String()
 }
 
+// Unwrap returns the field errors, so errors.As finds a *FieldError in
+// any of them.
+func (e *LoadError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = &e.Errors[i]
+	}
+
+	return errs
+}
+
 // ValidationError wraps validation errors from the validator package.
 type ValidationError struct {
 	Errors []error
@@ -105,11 +131,8 @@ func (e *ValidationError) Error() string {
 	return sb.String()
 }
 
-// Unwrap returns the first error in the list.
-func (e *ValidationError) Unwrap() error {
-	if len(e.Errors) > 0 {
-		return e.Errors[0]
-	}
-
-	return nil
+// Unwrap returns the errors in the list, so errors.As finds a *FieldError
+// in any of them.
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
 }
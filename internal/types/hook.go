@@ -0,0 +1,132 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// DecodeHook converts the string form of a value to type to, for types that
+// Convert and YAML decoding do not handle or handle differently. It returns
+// nil and no error if it does not apply to the type, leaving the value to
+// the next hook or the built-in conversion.
+type DecodeHook func(value string, to reflect.Type) (any, error)
+
+// decodeHooksKey is the context key of the hooks used by ConvertContext.
+type decodeHooksKey struct{}
+
+// WithDecodeHooks returns a copy of ctx carrying hooks for ConvertContext.
+func WithDecodeHooks(ctx context.Context, hooks []DecodeHook) context.Context {
+	if len(hooks) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, decodeHooksKey{}, hooks)
+}
+
+// DecodeHooks returns the hooks carried by ctx.
+func DecodeHooks(ctx context.Context) []DecodeHook {
+	hooks, _ := ctx.Value(decodeHooksKey{}).([]DecodeHook)
+
+	return hooks
+}
+
+// unitsKey is the context key of the units disabled by WithUnits.
+type unitsKey struct{}
+
+// WithUnits returns a copy of ctx telling ConvertContext whether
+// time.Duration values may use a day suffix ("7d") and whether integers may
+// use byte-size units ("10MiB"). Both are allowed by default.
+func WithUnits(ctx context.Context, days, sizes bool) context.Context {
+	if days && sizes {
+		return ctx
+	}
+
+	return context.WithValue(ctx, unitsKey{}, options{plainDurations: !days, plainSizes: !sizes})
+}
+
+// separatorsKey is the context key of the separators set by WithSeparators.
+type separatorsKey struct{}
+
+// separators holds the separators set by WithSeparators.
+type separators struct{ item, keyValue string }
+
+// WithSeparators returns a copy of ctx telling ConvertContext to split slice
+// items and map entries at item, and map keys from values at keyValue,
+// instead of reading a CSV record of key:value pairs. An empty separator
+// keeps the default. They apply to the target only, not to its elements.
+func WithSeparators(ctx context.Context, item, keyValue string) context.Context {
+	if item == "" && keyValue == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, separatorsKey{}, separators{item: item, keyValue: keyValue})
+}
+
+// FieldDecoder decodes the raw content of a field, as named by its decoder
+// tag, into target.
+type FieldDecoder func(data []byte, target reflect.Value) error
+
+// fieldDecoderKey is the context key of the decoder set by WithFieldDecoder.
+type fieldDecoderKey struct{}
+
+// WithFieldDecoder returns a copy of ctx telling ConvertContext to hand
+// values to dec instead of converting them. A nil dec keeps ctx.
+func WithFieldDecoder(ctx context.Context, dec FieldDecoder) context.Context {
+	if dec == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, fieldDecoderKey{}, dec)
+}
+
+// ConvertContext is like Convert, but tries the decode hooks carried by ctx
+// first, for the target and for the elements of slices, maps, and pointers,
+// and honors the units and separators set by WithUnits and WithSeparators.
+// A decoder set by WithFieldDecoder replaces the conversion altogether.
+func ConvertContext(ctx context.Context, value string, target reflect.Value) error {
+	if dec, ok := ctx.Value(fieldDecoderKey{}).(FieldDecoder); ok {
+		return dec([]byte(value), target)
+	}
+
+	opts, _ := ctx.Value(unitsKey{}).(options)
+	opts.hooks = DecodeHooks(ctx)
+	if seps, ok := ctx.Value(separatorsKey{}).(separators); ok {
+		opts.itemSep, opts.keyValueSep = seps.item, seps.keyValue
+	}
+
+	return convert(value, target, opts)
+}
+
+// Decode runs hooks on value for type to, in order, and returns the result
+// of the first hook that applies, and whether one did.
+func Decode(hooks []DecodeHook, value string, to reflect.Type) (reflect.Value, bool, error) {
+	for _, hook := range hooks {
+		result, err := hook(value, to)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		if result == nil {
+			continue
+		}
+
+		v := reflect.ValueOf(result)
+		switch {
+		case v.Type().AssignableTo(to):
+		case v.Kind() == reflect.Pointer && v.Type().Elem().AssignableTo(to):
+			if v.IsNil() {
+				return reflect.Zero(to), true, nil
+			}
+			v = v.Elem()
+		default:
+			return reflect.Value{}, true, fmt.Errorf("decode hook returned %s for %s", v.Type(), to)
+		}
+
+		c := reflect.New(to).Elem()
+		c.Set(v)
+
+		return c, true, nil
+	}
+
+	return reflect.Value{}, false, nil
+}
@@ -6,3 +6,10 @@ type Setter interface {
 	// SetDefaults sets default values for the struct.
 	SetDefaults()
 }
+
+// Validator is an interface for checking rules across the fields of a
+// struct after loading.
+type Validator interface {
+	// Validate returns an error if the struct is invalid.
+	Validate() error
+}
@@ -0,0 +1,20 @@
+package fuda
+
+import "context"
+
+// KMSDecrypter decrypts ciphertext with a cloud KMS (AWS KMS, GCP Cloud KMS,
+// ...). It backs the `kms` struct tag; see Builder.WithKMS.
+// Implementations MUST be safe for concurrent use by multiple goroutines.
+// Ready-made AWS and GCP implementations live in the fuda/kms module.
+type KMSDecrypter interface {
+	// Decrypt returns the plaintext for ciphertext.
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// KMSDecrypterFunc adapts a function to KMSDecrypter.
+type KMSDecrypterFunc func(ctx context.Context, ciphertext []byte) ([]byte, error)
+
+// Decrypt calls f(ctx, ciphertext).
+func (f KMSDecrypterFunc) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	return f(ctx, ciphertext)
+}
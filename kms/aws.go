@@ -0,0 +1,67 @@
+// Package kms provides AWS KMS and GCP Cloud KMS decrypters for the fuda
+// `kms` struct tag, so encrypted values can live directly in config files.
+//
+// Basic usage:
+//
+//	type Config struct {
+//	    DBPassword string `yaml:"db_password" kms:"aws"`
+//	}
+//
+//	awsCfg, _ := config.LoadDefaultConfig(ctx)
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithKMS("aws", kms.NewAWS(awskms.NewFromConfig(awsCfg))).
+//	    Build()
+//
+// The config file holds the base64-encoded ciphertext, e.g. the output of:
+//
+//	aws kms encrypt --key-id alias/app --plaintext fileb://<(printf s3cret) \
+//	    --query CiphertextBlob --output text
+package kms
+
+import (
+	"context"
+	"fmt"
+
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// AWSClient is the subset of the AWS KMS client used by AWSDecrypter.
+// *kms.Client from github.com/aws/aws-sdk-go-v2/service/kms satisfies it.
+type AWSClient interface {
+	Decrypt(ctx context.Context, params *awskms.DecryptInput, optFns ...func(*awskms.Options)) (*awskms.DecryptOutput, error)
+}
+
+// AWSDecrypter decrypts ciphertext with AWS KMS.
+type AWSDecrypter struct {
+	client AWSClient
+	config awsConfig
+}
+
+// NewAWS creates a decrypter backed by client.
+func NewAWS(client AWSClient, opts ...AWSOption) *AWSDecrypter {
+	d := &AWSDecrypter{client: client}
+	for _, opt := range opts {
+		opt(&d.config)
+	}
+
+	return d
+}
+
+// Decrypt returns the plaintext for an AWS KMS ciphertext blob.
+func (d *AWSDecrypter) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	input := &awskms.DecryptInput{
+		CiphertextBlob:    ciphertext,
+		EncryptionContext: d.config.encryptionContext,
+	}
+	if d.config.keyID != "" {
+		input.KeyId = &d.config.keyID
+	}
+
+	out, err := d.client.Decrypt(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("aws kms decrypt: %w", err)
+	}
+
+	return out.Plaintext, nil
+}
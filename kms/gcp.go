@@ -0,0 +1,72 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// crc32c is the Castagnoli table used by Cloud KMS integrity checks.
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// GCPClient is the subset of the Cloud KMS client used by GCPDecrypter.
+// *kms.KeyManagementClient from cloud.google.com/go/kms/apiv1 satisfies it.
+type GCPClient interface {
+	Decrypt(ctx context.Context, req *kmspb.DecryptRequest, opts ...gax.CallOption) (*kmspb.DecryptResponse, error)
+}
+
+// GCPDecrypter decrypts ciphertext with a GCP Cloud KMS symmetric key.
+type GCPDecrypter struct {
+	client  GCPClient
+	keyName string
+	config  gcpConfig
+}
+
+// NewGCP creates a decrypter for the key named keyName, in the form
+// projects/*/locations/*/keyRings/*/cryptoKeys/*.
+//
+// Example:
+//
+//	client, _ := gcpkms.NewKeyManagementClient(ctx)
+//	d := kms.NewGCP(client, "projects/acme/locations/global/keyRings/app/cryptoKeys/config")
+func NewGCP(client GCPClient, keyName string, opts ...GCPOption) *GCPDecrypter {
+	d := &GCPDecrypter{client: client, keyName: keyName}
+	for _, opt := range opts {
+		opt(&d.config)
+	}
+
+	return d
+}
+
+// Decrypt returns the plaintext for a Cloud KMS ciphertext, verifying the
+// CRC32C checksums in both directions.
+func (d *GCPDecrypter) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	req := &kmspb.DecryptRequest{
+		Name:                              d.keyName,
+		Ciphertext:                        ciphertext,
+		CiphertextCrc32C:                  wrapperspb.Int64(checksum(ciphertext)),
+		AdditionalAuthenticatedData:       d.config.additionalData,
+		AdditionalAuthenticatedDataCrc32C: wrapperspb.Int64(checksum(d.config.additionalData)),
+	}
+
+	resp, err := d.client.Decrypt(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("gcp kms decrypt: %w", err)
+	}
+
+	if sum := resp.GetPlaintextCrc32C(); sum != nil && sum.GetValue() != checksum(resp.GetPlaintext()) {
+		return nil, errors.New("gcp kms decrypt: plaintext checksum mismatch")
+	}
+
+	return resp.GetPlaintext(), nil
+}
+
+// checksum returns the CRC32C of data as used by the Cloud KMS API.
+func checksum(data []byte) int64 {
+	return int64(crc32.Checksum(data, crc32c))
+}
@@ -0,0 +1,116 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// fakeAWS records the last request and answers with a fixed result.
+type fakeAWS struct {
+	input     *awskms.DecryptInput
+	plaintext []byte
+	err       error
+}
+
+func (f *fakeAWS) Decrypt(_ context.Context, in *awskms.DecryptInput, _ ...func(*awskms.Options)) (*awskms.DecryptOutput, error) {
+	f.input = in
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &awskms.DecryptOutput{Plaintext: f.plaintext}, nil
+}
+
+// fakeGCP records the last request and answers with a fixed response.
+type fakeGCP struct {
+	req  *kmspb.DecryptRequest
+	resp *kmspb.DecryptResponse
+	err  error
+}
+
+func (f *fakeGCP) Decrypt(_ context.Context, req *kmspb.DecryptRequest, _ ...gax.CallOption) (*kmspb.DecryptResponse, error) {
+	f.req = req
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return f.resp, nil
+}
+
+func TestAWS_Decrypt(t *testing.T) {
+	client := &fakeAWS{plaintext: []byte("s3cret")}
+	d := NewAWS(client,
+		WithKeyID("alias/app"),
+		WithEncryptionContext(map[string]string{"app": "billing"}),
+	)
+
+	plain, err := d.Decrypt(t.Context(), []byte("blob"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(plain))
+
+	assert.Equal(t, []byte("blob"), client.input.CiphertextBlob)
+	require.NotNil(t, client.input.KeyId)
+	assert.Equal(t, "alias/app", *client.input.KeyId)
+	assert.Equal(t, map[string]string{"app": "billing"}, client.input.EncryptionContext)
+}
+
+func TestAWS_DecryptNoKeyID(t *testing.T) {
+	client := &fakeAWS{plaintext: []byte("s3cret")}
+
+	_, err := NewAWS(client).Decrypt(t.Context(), []byte("blob"))
+	require.NoError(t, err)
+	assert.Nil(t, client.input.KeyId)
+}
+
+func TestAWS_DecryptError(t *testing.T) {
+	denied := errors.New("AccessDeniedException")
+
+	_, err := NewAWS(&fakeAWS{err: denied}).Decrypt(t.Context(), []byte("blob"))
+	require.ErrorIs(t, err, denied)
+	assert.Contains(t, err.Error(), "aws kms decrypt")
+}
+
+func TestGCP_Decrypt(t *testing.T) {
+	const key = "projects/acme/locations/global/keyRings/app/cryptoKeys/config"
+	client := &fakeGCP{resp: &kmspb.DecryptResponse{
+		Plaintext:       []byte("s3cret"),
+		PlaintextCrc32C: wrapperspb.Int64(checksum([]byte("s3cret"))),
+	}}
+	d := NewGCP(client, key, WithAdditionalData([]byte("billing")))
+
+	plain, err := d.Decrypt(t.Context(), []byte("blob"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(plain))
+
+	assert.Equal(t, key, client.req.GetName())
+	assert.Equal(t, []byte("blob"), client.req.GetCiphertext())
+	assert.Equal(t, checksum([]byte("blob")), client.req.GetCiphertextCrc32C().GetValue())
+	assert.Equal(t, []byte("billing"), client.req.GetAdditionalAuthenticatedData())
+}
+
+func TestGCP_DecryptChecksumMismatch(t *testing.T) {
+	client := &fakeGCP{resp: &kmspb.DecryptResponse{
+		Plaintext:       []byte("s3cret"),
+		PlaintextCrc32C: wrapperspb.Int64(1),
+	}}
+
+	_, err := NewGCP(client, "key").Decrypt(t.Context(), []byte("blob"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestGCP_DecryptError(t *testing.T) {
+	denied := errors.New("PermissionDenied")
+
+	_, err := NewGCP(&fakeGCP{err: denied}, "key").Decrypt(t.Context(), []byte("blob"))
+	require.ErrorIs(t, err, denied)
+	assert.Contains(t, err.Error(), "gcp kms decrypt")
+}
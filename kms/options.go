@@ -0,0 +1,50 @@
+package kms
+
+// AWSOption configures an AWS KMS decrypter.
+type AWSOption func(*awsConfig)
+
+// GCPOption configures a GCP Cloud KMS decrypter.
+type GCPOption func(*gcpConfig)
+
+// awsConfig holds AWS KMS decrypter configuration.
+type awsConfig struct {
+	keyID             string
+	encryptionContext map[string]string
+}
+
+// gcpConfig holds GCP Cloud KMS decrypter configuration.
+type gcpConfig struct {
+	additionalData []byte
+}
+
+// WithKeyID sets the KMS key used to decrypt. It is optional for symmetric
+// keys (the ciphertext identifies its key) and required for asymmetric keys.
+//
+// Example:
+//
+//	kms.WithKeyID("arn:aws:kms:us-east-1:111122223333:key/1234abcd-...")
+func WithKeyID(keyID string) AWSOption {
+	return func(c *awsConfig) {
+		c.keyID = keyID
+	}
+}
+
+// WithEncryptionContext sets the encryption context that was supplied when
+// the values were encrypted. Decryption fails if it does not match.
+//
+// Example:
+//
+//	kms.WithEncryptionContext(map[string]string{"app": "billing"})
+func WithEncryptionContext(ctx map[string]string) AWSOption {
+	return func(c *awsConfig) {
+		c.encryptionContext = ctx
+	}
+}
+
+// WithAdditionalData sets the additional authenticated data that was
+// supplied when the values were encrypted.
+func WithAdditionalData(aad []byte) GCPOption {
+	return func(c *gcpConfig) {
+		c.additionalData = aad
+	}
+}
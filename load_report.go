@@ -0,0 +1,76 @@
+package fuda
+
+import (
+	"context"
+	"fmt"
+)
+
+// ValidationMode controls whether validation errors fail a load.
+type ValidationMode int
+
+const (
+	// ValidationStrict fails the load with a *ValidationError when
+	// validation fails. This is the default.
+	ValidationStrict ValidationMode = iota
+	// ValidationWarn completes the load despite validation errors, which
+	// are reported by LoadWithReport instead.
+	ValidationWarn
+)
+
+// WithValidationMode sets whether validation errors fail a load. With
+// ValidationWarn, `validate` tag and Validate method errors are collected
+// and reported by LoadWithReport while the load succeeds, so new
+// constraints can be rolled out before existing configs satisfy them:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithValidationMode(fuda.ValidationWarn).
+//	    Build()
+//
+//	report, err := loader.LoadWithReport(&cfg)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if report.Warnings != nil {
+//	    log.Printf("config: %v", report.Warnings)
+//	}
+//
+// Errors other than validation errors, such as a bad value in the file,
+// still fail the load, and AfterValidate hooks run as after a valid load.
+func (b *Builder) WithValidationMode(mode ValidationMode) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if mode != ValidationStrict && mode != ValidationWarn {
+		b.err = &FieldError{Message: fmt.Sprintf("unknown validation mode %d", mode)}
+
+		return b
+	}
+	b.config.validateMode = mode
+
+	return b
+}
+
+// LoadReport describes a successful load.
+type LoadReport struct {
+	// Warnings holds the validation errors that did not fail the load
+	// because of ValidationWarn, or nil if validation passed.
+	Warnings *ValidationError
+}
+
+// LoadWithReport is like Load, and also returns a report of the load.
+// It is equivalent to LoadWithReportContext with context.Background().
+func (l *Loader) LoadWithReport(target any) (*LoadReport, error) {
+	return l.LoadWithReportContext(context.Background(), target)
+}
+
+// LoadWithReportContext is like LoadContext, and also returns a report of
+// the load. The report is nil if the load fails.
+func (l *Loader) LoadWithReportContext(ctx context.Context, target any) (*LoadReport, error) {
+	report := &LoadReport{}
+	if err := l.load(ctx, target, "", report, nil); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,297 @@
+package fuda
+
+import (
+	"flag"
+	"io"
+	iofs "io/fs"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+)
+
+// LoaderOption configures a Loader created with NewLoader. Each option
+// mirrors the Builder method of the same name.
+//
+// A LoaderOption is a func(*Builder), so options can also be passed to
+// Builder.Apply, and custom options can be written inline:
+//
+//	opt := fuda.LoaderOption(func(b *fuda.Builder) {
+//	    b.WithEnvPrefix("APP_").WithTimeout(5 * time.Second)
+//	})
+type LoaderOption func(*Builder)
+
+// NewLoader creates a Loader from functional options, as an alternative to
+// the Builder chain. Options are applied in order, exactly as the equivalent
+// Builder calls would be, so WithFilesystem must precede FromFile. Nil
+// options are ignored.
+//
+// Options are plain values, so they can be collected in slices, shared
+// across packages, and appended conditionally:
+//
+//	opts := []fuda.LoaderOption{
+//	    fuda.FromFile("config.yaml"),
+//	    fuda.WithEnvPrefix("APP_"),
+//	}
+//	if vaultEnabled {
+//	    opts = append(opts, fuda.WithResolver("vault", vaultResolver))
+//	}
+//	loader, err := fuda.NewLoader(opts...)
+func NewLoader(opts ...LoaderOption) (*Loader, error) {
+	b := New()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(b)
+		}
+	}
+
+	return b.Build()
+}
+
+// FromFile returns an option that reads configuration from the file at path.
+// See Builder.FromFile.
+func FromFile(path string) LoaderOption {
+	return func(b *Builder) { b.FromFile(path) }
+}
+
+// FromFileStream returns an option that decodes the file at path as a
+// stream at every load. See Builder.FromFileStream.
+func FromFileStream(path string) LoaderOption {
+	return func(b *Builder) { b.FromFileStream(path) }
+}
+
+// FromFiles returns an option that reads and deep-merges several files.
+// See Builder.FromFiles.
+func FromFiles(paths ...string) LoaderOption {
+	return func(b *Builder) { b.FromFiles(paths...) }
+}
+
+// FromObjectStore returns an option that reads configuration from an object
+// store URI, such as "s3://bucket/config.yaml", at every load.
+// See Builder.FromObjectStore.
+func FromObjectStore(uri string) LoaderOption {
+	return func(b *Builder) { b.FromObjectStore(uri) }
+}
+
+// FromReader returns an option that reads configuration from r.
+// See Builder.FromReader.
+func FromReader(r io.Reader) LoaderOption {
+	return func(b *Builder) { b.FromReader(r) }
+}
+
+// FromBytes returns an option that uses data as configuration.
+// See Builder.FromBytes.
+func FromBytes(data []byte) LoaderOption {
+	return func(b *Builder) { b.FromBytes(data) }
+}
+
+// WithEnvPrefix returns an option that sets the environment variable prefix.
+// See Builder.WithEnvPrefix.
+func WithEnvPrefix(prefix string) LoaderOption {
+	return func(b *Builder) { b.WithEnvPrefix(prefix) }
+}
+
+// WithAutoEnv returns an option that derives env var names from field paths.
+// See Builder.WithAutoEnv.
+func WithAutoEnv() LoaderOption {
+	return func(b *Builder) { b.WithAutoEnv() }
+}
+
+// WithFlagSet returns an option that binds flag-tagged fields to fs.
+// See Builder.WithFlagSet.
+func WithFlagSet(fs *flag.FlagSet) LoaderOption {
+	return func(b *Builder) { b.WithFlagSet(fs) }
+}
+
+// WithPFlagSet returns an option that binds flag-tagged fields to a pflag
+// flag set. See Builder.WithPFlagSet.
+func WithPFlagSet(fs *pflag.FlagSet) LoaderOption {
+	return func(b *Builder) { b.WithPFlagSet(fs) }
+}
+
+// WithRefResolver returns an option that replaces the default ref resolver.
+// See Builder.WithRefResolver.
+func WithRefResolver(r RefResolver) LoaderOption {
+	return func(b *Builder) { b.WithRefResolver(r) }
+}
+
+// WithResolver returns an option that registers r for URIs with the given
+// scheme. See Builder.WithResolver.
+func WithResolver(scheme string, r RefResolver) LoaderOption {
+	return func(b *Builder) { b.WithResolver(scheme, r) }
+}
+
+// WithResolverMiddleware returns an option that wraps the ref resolver with
+// mw. See Builder.WithResolverMiddleware.
+func WithResolverMiddleware(mw ...ResolverMiddleware) LoaderOption {
+	return func(b *Builder) { b.WithResolverMiddleware(mw...) }
+}
+
+// WithMetrics returns an option that reports ref resolutions and reloads
+// to m. See Builder.WithMetrics.
+func WithMetrics(m Metrics) LoaderOption {
+	return func(b *Builder) { b.WithMetrics(m) }
+}
+
+// WithRefRateLimit returns an option that limits network ref resolutions to
+// rps per second. See Builder.WithRefRateLimit.
+func WithRefRateLimit(rps float64) LoaderOption {
+	return func(b *Builder) { b.WithRefRateLimit(rps) }
+}
+
+// WithFilesystem returns an option that sets the filesystem for file
+// operations. See Builder.WithFilesystem.
+func WithFilesystem(fs afero.Fs) LoaderOption {
+	return func(b *Builder) { b.WithFilesystem(fs) }
+}
+
+// WithIOFS returns an option that sets an io/fs filesystem for file
+// operations. See Builder.WithIOFS.
+func WithIOFS(fsys iofs.FS) LoaderOption {
+	return func(b *Builder) { b.WithIOFS(fsys) }
+}
+
+// WithTimeout returns an option that sets the timeout for ref resolution.
+// See Builder.WithTimeout.
+func WithTimeout(timeout time.Duration) LoaderOption {
+	return func(b *Builder) { b.WithTimeout(timeout) }
+}
+
+// WithSecretsDir returns an option that sets the directory of the files
+// read by secretName tags. See Builder.WithSecretsDir.
+func WithSecretsDir(dir string) LoaderOption {
+	return func(b *Builder) { b.WithSecretsDir(dir) }
+}
+
+// WithRefConcurrency returns an option that resolves up to n refs in
+// parallel. See Builder.WithRefConcurrency.
+func WithRefConcurrency(n int) LoaderOption {
+	return func(b *Builder) { b.WithRefConcurrency(n) }
+}
+
+// WithRefRetry returns an option that retries failed ref resolutions.
+// See Builder.WithRefRetry.
+func WithRefRetry(attempts int, backoff time.Duration) LoaderOption {
+	return func(b *Builder) { b.WithRefRetry(attempts, backoff) }
+}
+
+// WithOverrides returns an option that overrides configuration values.
+// See Builder.WithOverrides.
+func WithOverrides(overrides map[string]any) LoaderOption {
+	return func(b *Builder) { b.WithOverrides(overrides) }
+}
+
+// WithPrecedence returns an option that reorders which sources win.
+// See Builder.WithPrecedence.
+func WithPrecedence(sources ...Source) LoaderOption {
+	return func(b *Builder) { b.WithPrecedence(sources...) }
+}
+
+// WithSizePreprocess returns an option that toggles size string conversion.
+// See Builder.WithSizePreprocess.
+func WithSizePreprocess(enabled bool) LoaderOption {
+	return func(b *Builder) { b.WithSizePreprocess(enabled) }
+}
+
+// WithDurationPreprocess returns an option that toggles duration string
+// conversion. See Builder.WithDurationPreprocess.
+func WithDurationPreprocess(enabled bool) LoaderOption {
+	return func(b *Builder) { b.WithDurationPreprocess(enabled) }
+}
+
+// WithDefaults returns an option that seeds the target with a default
+// instance. See Builder.WithDefaults.
+func WithDefaults(defaults any) LoaderOption {
+	return func(b *Builder) { b.WithDefaults(defaults) }
+}
+
+// WithValidationMode returns an option that sets whether validation errors
+// fail a load. See Builder.WithValidationMode.
+func WithValidationMode(mode ValidationMode) LoaderOption {
+	return func(b *Builder) { b.WithValidationMode(mode) }
+}
+
+// WithConflictReport returns an option that reports keys shadowed between
+// source documents to fn. See Builder.WithConflictReport.
+func WithConflictReport(fn func([]Conflict)) LoaderOption {
+	return func(b *Builder) { b.WithConflictReport(fn) }
+}
+
+// WithLogger returns an option that logs load decisions to logger at debug
+// level. See Builder.WithLogger.
+func WithLogger(logger *slog.Logger) LoaderOption {
+	return func(b *Builder) { b.WithLogger(logger) }
+}
+
+// WithTrace returns an option that writes value provenance to w.
+// See Builder.WithTrace.
+func WithTrace(w io.Writer) LoaderOption {
+	return func(b *Builder) { b.WithTrace(w) }
+}
+
+// WithKMS returns an option that registers a KMS decrypter for provider.
+// See Builder.WithKMS.
+func WithKMS(provider string, d KMSDecrypter) LoaderOption {
+	return func(b *Builder) { b.WithKMS(provider, d) }
+}
+
+// WithAgeIdentity returns an option that adds age identities for decrypting
+// enc:age: values. See Builder.WithAgeIdentity.
+func WithAgeIdentity(identity string) LoaderOption {
+	return func(b *Builder) { b.WithAgeIdentity(identity) }
+}
+
+// WithAgeIdentityFile returns an option that adds the age identities in the
+// key file at path. See Builder.WithAgeIdentityFile.
+func WithAgeIdentityFile(path string) LoaderOption {
+	return func(b *Builder) { b.WithAgeIdentityFile(path) }
+}
+
+// WithStrictKeys returns an option that rejects unknown config keys.
+// See Builder.WithStrictKeys.
+func WithStrictKeys() LoaderOption {
+	return func(b *Builder) { b.WithStrictKeys() }
+}
+
+// WithExplicitZeros returns an option that keeps zero values set by the
+// config source. See Builder.WithExplicitZeros.
+func WithExplicitZeros() LoaderOption {
+	return func(b *Builder) { b.WithExplicitZeros() }
+}
+
+// WithEnvExpansion returns an option that expands ${VAR} placeholders in the
+// source. See Builder.WithEnvExpansion.
+func WithEnvExpansion() LoaderOption {
+	return func(b *Builder) { b.WithEnvExpansion() }
+}
+
+// WithIncludes returns an option that splices `!include` files into the
+// source. See Builder.WithIncludes.
+func WithIncludes() LoaderOption {
+	return func(b *Builder) { b.WithIncludes() }
+}
+
+// WithProfile returns an option that selects a profile of the source
+// document. See Builder.WithProfile.
+func WithProfile(name string) LoaderOption {
+	return func(b *Builder) { b.WithProfile(name) }
+}
+
+// WithDotEnv returns an option that loads a single dotenv file.
+// See Builder.WithDotEnv.
+func WithDotEnv(file string, opts ...DotEnvOption) LoaderOption {
+	return func(b *Builder) { b.WithDotEnv(file, opts...) }
+}
+
+// WithDotEnvFiles returns an option that loads multiple dotenv files.
+// See Builder.WithDotEnvFiles.
+func WithDotEnvFiles(files []string, opts ...DotEnvOption) LoaderOption {
+	return func(b *Builder) { b.WithDotEnvFiles(files, opts...) }
+}
+
+// WithDotEnvSearch returns an option that searches for a dotenv file.
+// See Builder.WithDotEnvSearch.
+func WithDotEnvSearch(name string, searchPaths []string, opts ...DotEnvOption) LoaderOption {
+	return func(b *Builder) { b.WithDotEnvSearch(name, searchPaths, opts...) }
+}
@@ -0,0 +1,135 @@
+package fuda
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// LogFields returns the fields of cfg tagged `logctx:"true"` keyed by their
+// dotted yaml paths, for attaching config metadata such as the region,
+// cluster, and app version to loggers and metrics at startup:
+//
+//	type Config struct {
+//	    Version string `yaml:"version" logctx:"true"`
+//	    Cluster struct {
+//	        Region string `yaml:"region" logctx:"true"`
+//	        Name   string `yaml:"name" logctx:"cluster"`
+//	    } `yaml:"cluster"`
+//	}
+//
+//	fields, err := fuda.LogFields(&cfg)
+//	// {"version": "1.4.2", "cluster.region": "eu-west-1", "cluster": "prod-7"}
+//
+// A tag value other than true or false is the key to use instead of the
+// path. Tagging a struct field selects all fields below it, keyed under the
+// struct's key. Fields behind nil pointers are left out, and sensitive
+// fields are masked as in Redact. Two fields with the same key are an error.
+//
+// cfg must be a struct or a pointer to a struct.
+func LogFields(cfg any) (map[string]any, error) {
+	attrs, err := LogAttrs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		fields[attr.Key] = attr.Value.Any()
+	}
+
+	return fields, nil
+}
+
+// LogAttrs returns the fields selected by LogFields as slog attributes, in
+// field order:
+//
+//	attrs, err := fuda.LogAttrs(&cfg)
+//	if err == nil {
+//	    logger = slog.New(handler.WithAttrs(attrs))
+//	}
+func LogAttrs(cfg any) ([]slog.Attr, error) {
+	v, isNil := derefValue(reflect.ValueOf(cfg))
+	if isNil || v.Kind() != reflect.Struct {
+		return nil, &FieldError{Message: "config must be a struct or pointer to struct"}
+	}
+
+	c := logCollector{paths: make(map[string]string)}
+	if err := c.structFields("", "", false, v); err != nil {
+		return nil, err
+	}
+
+	return c.attrs, nil
+}
+
+// logCollector gathers the attributes of logctx fields.
+type logCollector struct {
+	attrs []slog.Attr
+	paths map[string]string // field path by key, to report duplicates
+}
+
+// structFields collects the selected fields of the struct v. path is the
+// dotted yaml path of v and key the key prefix of its fields; selected
+// reports whether v itself is tagged, which selects all its fields.
+func (c *logCollector) structFields(path, key string, selected bool, v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, inline, skip := yamlFieldName(field)
+		if skip || !dumpable(field.Type) {
+			continue
+		}
+		if !field.IsExported() && !(field.Anonymous && inline) {
+			continue
+		}
+
+		fieldPath, fieldKey := joinDiffPath(path, name), joinDiffPath(key, name)
+		if inline {
+			fieldPath, fieldKey = path, key
+		}
+
+		fieldSelected := selected
+		if tag, ok := tags.Lookup(field, "logctx"); ok {
+			on, err := strconv.ParseBool(tag)
+			switch {
+			case err != nil && tag != "":
+				fieldSelected, fieldKey = true, tag
+			case err != nil:
+				return &FieldError{Path: fieldPath, Tag: "logctx", Message: "empty key"}
+			default:
+				fieldSelected = on
+			}
+		}
+
+		fieldVal, isNil := derefValue(v.Field(i))
+		if isNil {
+			continue
+		}
+		if fieldVal.Kind() == reflect.Struct && !encodesItself(fieldVal.Type()) {
+			if err := c.structFields(fieldPath, fieldKey, fieldSelected, fieldVal); err != nil {
+				return err
+			}
+
+			continue
+		}
+		if !fieldSelected || !fieldVal.CanInterface() {
+			continue
+		}
+
+		if other, ok := c.paths[fieldKey]; ok {
+			return &FieldError{Path: fieldPath, Tag: "logctx", Message: fmt.Sprintf("key %q is also used by '%s'", fieldKey, other)}
+		}
+		c.paths[fieldKey] = fieldPath
+
+		value := fieldVal.Interface()
+		if tags.IsSensitive(field) {
+			value = redactedValue(fieldVal)
+		}
+		c.attrs = append(c.attrs, slog.Any(fieldKey, value))
+	}
+
+	return nil
+}
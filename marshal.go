@@ -0,0 +1,25 @@
+package fuda
+
+// Marshal serializes cfg back to YAML in the shape of the config file, for
+// implementing `--dump-config` style commands. Keys follow the yaml struct
+// tags, and every field is emitted (including zero values) so the output
+// documents all available settings.
+//
+// Secret fields (see Redact) are omitted entirely, so the output can be
+// shared or committed safely. A field's `doc` tag, when present, is written
+// as a comment above its key:
+//
+//	type Config struct {
+//	    Port     int    `yaml:"port" default:"8080" doc:"HTTP listen port"`
+//	    Password string `yaml:"password" secret:"true"`
+//	}
+//
+//	out, _ := fuda.Marshal(&cfg)
+//	// # HTTP listen port
+//	// port: 8080
+//
+// time.Duration values are written as strings like "30s", which fuda parses
+// back when loading.
+func Marshal(cfg any) ([]byte, error) {
+	return yamlRenderer{omitSecrets: true, comments: true}.marshal(cfg)
+}
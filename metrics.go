@@ -0,0 +1,38 @@
+package fuda
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Metrics receives measurements of config loading, so reload health and slow
+// secret stores can be alerted on. Pass an implementation to
+// Builder.WithMetrics, or to the watcher's Builder.WithMetrics to also
+// measure its reloads. The fuda/prometheus module provides one for
+// Prometheus. Methods are called from the loading goroutine and must be
+// safe for concurrent use.
+type Metrics interface {
+	// RefResolved records one ref resolution of a URI with scheme, which
+	// took d and failed with err, if not nil. With WithRefRetry, every
+	// attempt is recorded.
+	RefResolved(scheme string, d time.Duration, err error)
+
+	// Reloaded records a reload finished at t, which failed with err, if
+	// not nil. A failed reload keeps the previous config.
+	Reloaded(t time.Time, err error)
+}
+
+// metricsMiddleware records the latency and outcome of every ref resolution.
+func metricsMiddleware(m Metrics) ResolverMiddleware {
+	return func(next RefResolver) RefResolver {
+		return RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+			scheme, _, _ := strings.Cut(uri, "://")
+			start := time.Now()
+			data, err := next.Resolve(ctx, uri)
+			m.RefResolved(scheme, time.Since(start), err)
+
+			return data, err
+		})
+	}
+}
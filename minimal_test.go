@@ -0,0 +1,48 @@
+//go:build fuda_minimal
+
+package fuda_test
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimal_DefaultsEnvYAML(t *testing.T) {
+	type config struct {
+		Host string `yaml:"host" default:"localhost"`
+		Port int    `yaml:"port" env:"FUDA_MINIMAL_PORT" default:"8080" validate:"min=10000"`
+		Name string `yaml:"name" validate:"required"`
+	}
+	t.Setenv("FUDA_MINIMAL_PORT", "9090")
+
+	var cfg config
+	require.NoError(t, fuda.LoadBytes([]byte("host: example.com\n"), &cfg), "validate tags are ignored")
+	assert.Equal(t, "example.com", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)
+}
+
+func TestMinimal_NoNetworkResolvers(t *testing.T) {
+	type config struct {
+		Token string `ref:"https://config.internal/token"`
+	}
+
+	var cfg config
+	err := fuda.LoadBytes(nil, &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported scheme: https")
+}
+
+func TestMinimal_NoTemplates(t *testing.T) {
+	type config struct {
+		User string `default:"app"`
+		DSN  string `dsn:"postgres://${.User}@db/app"`
+	}
+
+	var cfg config
+	err := fuda.LoadBytes(nil, &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported in fuda_minimal builds")
+}
@@ -1,11 +1,11 @@
 package fuda
 
-import "github.com/go-playground/validator/v10"
+import "github.com/arloliu/fuda/internal/loader"
 
 // config holds configuration for Fuda functions.
 type config struct {
 	validate  bool
-	validator *validator.Validate
+	validator *loader.Validator
 }
 
 // Option configures Fuda behavior.
@@ -27,24 +27,3 @@ func WithValidation(enabled bool) Option {
 		c.validate = enabled
 	}
 }
-
-// WithValidator sets a custom validator instance for SetDefaults or Validate.
-// Use this to apply custom validation rules or tags.
-//
-// For SetDefaults:
-// This option only takes effect if validation is enabled via WithValidation(true).
-//
-// For Validate:
-// This option overrides the default validator.
-//
-// Example:
-//
-//	v := validator.New()
-//	v.RegisterValidation("custom", customFunc)
-//
-//	fuda.SetDefaults(&cfg, fuda.WithValidation(true), fuda.WithValidator(v))
-func WithValidator(v *validator.Validate) Option {
-	return func(c *config) {
-		c.validator = v
-	}
-}
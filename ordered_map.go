@@ -0,0 +1,355 @@
+package fuda
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is a map that keeps its keys in the order they are written in
+// the config source. Use it instead of a Go map where order matters, such as
+// a middleware chain or the stages of a pipeline.
+//
+// Example:
+//
+//	type Config struct {
+//	    Middleware fuda.OrderedMap[string, MiddlewareConfig] `yaml:"middleware"`
+//	}
+//
+//	// middleware:
+//	//   auth: {required: true}
+//	//   ratelimit: {rps: 100}
+//	//   gzip: {level: 5}
+//
+//	for name, mw := range cfg.Middleware.All() {
+//	    // auth, ratelimit, gzip
+//	}
+//
+// An OrderedMap decodes from a YAML or JSON mapping and encodes back to one
+// in the same order, so Dump and DumpRedacted keep it. Struct values get
+// their own default, env, ref, and secret tags applied like any nested
+// struct. The default, env, and ref tags accept the same "key:value,..."
+// form as Go maps, in the given order.
+//
+// The entries are exposed as a slice so they can be ranged over by index;
+// use Set and Delete rather than append to keep keys unique.
+type OrderedMap[K comparable, V any] []MapEntry[K, V]
+
+// MapEntry is one key/value pair of an OrderedMap.
+type MapEntry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// orderedMap is implemented by every OrderedMap instantiation, so reflection
+// based helpers such as Redact and Diff can recognize it.
+type orderedMap interface{ isOrderedMap() }
+
+var orderedMapType = reflect.TypeFor[orderedMap]()
+
+func (m OrderedMap[K, V]) isOrderedMap() {}
+
+// isOrderedMapType reports whether t is an OrderedMap instantiation. Its
+// entries are structs whose fields 0 and 1 are the key and the value.
+func isOrderedMapType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Implements(orderedMapType)
+}
+
+// Len returns the number of entries.
+func (m OrderedMap[K, V]) Len() int {
+	return len(m)
+}
+
+// Get returns the value for key and whether it is present.
+func (m OrderedMap[K, V]) Get(key K) (V, bool) {
+	for _, e := range m {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// Set replaces the value of key in place, or appends key if it is not
+// present.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	for i := range *m {
+		if (*m)[i].Key == key {
+			(*m)[i].Value = value
+
+			return
+		}
+	}
+	*m = append(*m, MapEntry[K, V]{Key: key, Value: value})
+}
+
+// Delete removes key, keeping the order of the other entries.
+func (m *OrderedMap[K, V]) Delete(key K) {
+	for i := range *m {
+		if (*m)[i].Key == key {
+			*m = append((*m)[:i], (*m)[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// Keys returns the keys in order.
+func (m OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, len(m))
+	for i, e := range m {
+		keys[i] = e.Key
+	}
+
+	return keys
+}
+
+// All returns an iterator over the entries in order.
+func (m OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, e := range m {
+			if !yield(e.Key, e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Merge keys ("<<") add the
+// merged entries at their position unless the key is also set explicitly.
+func (m *OrderedMap[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	if m == nil {
+		return errors.New("fuda.OrderedMap: UnmarshalYAML on nil pointer")
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		*m = nil
+
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: cannot decode %s into an ordered map, expected a mapping", node.Line, node.ShortTag())
+	}
+
+	pairs, err := orderedMapPairs(node)
+	if err != nil {
+		return err
+	}
+
+	entries := make(OrderedMap[K, V], 0, len(pairs))
+	seen := make(map[K]bool, len(pairs))
+	for _, pair := range pairs {
+		var e MapEntry[K, V]
+		if err := pair.key.Decode(&e.Key); err != nil {
+			return err
+		}
+		if err := pair.value.Decode(&e.Value); err != nil {
+			return err
+		}
+
+		switch {
+		case !seen[e.Key]:
+			seen[e.Key] = true
+			entries = append(entries, e)
+		case !pair.merged:
+			return fmt.Errorf("line %d: mapping key %q already defined", pair.key.Line, pair.key.Value)
+		}
+		// A merged key never overrides an explicit or earlier merged one
+	}
+	*m = entries
+
+	return nil
+}
+
+// orderedMapPair is one key/value node pair of a mapping, with merge keys
+// expanded.
+type orderedMapPair struct {
+	key, value *yaml.Node
+	merged     bool
+}
+
+// orderedMapPairs lists the pairs of mapping node in order. Explicit keys
+// come before the keys of merged mappings so they take precedence, while
+// each merged key keeps the position of its "<<".
+func orderedMapPairs(node *yaml.Node) ([]orderedMapPair, error) {
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; key.Tag != "!!merge" {
+			explicit[key.Value] = true
+		}
+	}
+
+	var pairs []orderedMapPair
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag != "!!merge" {
+			pairs = append(pairs, orderedMapPair{key: key, value: value})
+
+			continue
+		}
+
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, src := range sources {
+			if src.Kind == yaml.AliasNode {
+				src = src.Alias
+			}
+			if src.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: map merge requires a mapping or a list of mappings", src.Line)
+			}
+			merged, err := orderedMapPairs(src)
+			if err != nil {
+				return nil, err
+			}
+			for _, pair := range merged {
+				if !explicit[pair.key.Value] {
+					pair.merged = true
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+	}
+
+	return pairs, nil
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding the entries as a mapping
+// in order.
+func (m OrderedMap[K, V]) MarshalYAML() (any, error) {
+	if m == nil {
+		return nil, nil //nolint:nilnil // nil is valid YAML null
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, e := range m {
+		var key, value yaml.Node
+		if err := key.Encode(e.Key); err != nil {
+			return nil, err
+		}
+		if err := value.Encode(e.Value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &key, &value)
+	}
+
+	return node, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. JSON is decoded as YAML, which
+// keeps the order of object keys.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	if len(node.Content) == 0 {
+		return errors.New("fuda.OrderedMap: empty JSON input")
+	}
+
+	return m.UnmarshalYAML(node.Content[0])
+}
+
+// MarshalJSON implements json.Marshaler, encoding the entries as an object
+// in order. Non-string keys are written as their text form, like
+// encoding/json does for maps.
+func (m OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(orderedMapKeyText(e.Key))
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// orderedMapKeyText returns the JSON object key for key.
+func orderedMapKeyText(key any) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	if tm, ok := key.(interface{ MarshalText() ([]byte, error) }); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	return fmt.Sprint(key)
+}
+
+// Scan implements Scanner for the default, env, and ref tags, parsing
+// "key:value,key:value" in order. Items containing commas can be quoted as
+// in CSV.
+func (m *OrderedMap[K, V]) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("fuda.OrderedMap: cannot scan %T", src)
+	}
+
+	if strings.TrimSpace(s) == "" {
+		*m = nil
+
+		return nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(s))
+	reader.TrimLeadingSpace = true
+	parts, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to parse csv map: %w", err)
+	}
+
+	var entries OrderedMap[K, V]
+	for _, part := range parts {
+		k, v, ok := strings.Cut(part, ":")
+		if !ok {
+			return fmt.Errorf("invalid map item format: %s", part)
+		}
+
+		var e MapEntry[K, V]
+		if err := types.Convert(strings.TrimSpace(k), reflect.ValueOf(&e.Key).Elem()); err != nil {
+			return err
+		}
+		if err := types.Convert(strings.TrimSpace(v), reflect.ValueOf(&e.Value).Elem()); err != nil {
+			return err
+		}
+		entries.Set(e.Key, e.Value)
+	}
+	*m = entries
+
+	return nil
+}
@@ -0,0 +1,72 @@
+//go:build !fuda_minimal
+
+package fuda
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies fuda's spans in OpenTelemetry.
+const tracerName = "github.com/arloliu/fuda"
+
+// WithTracerProvider records OpenTelemetry spans for every load with tracer
+// provider tp, so slow secret stores show up in traces:
+//
+//   - fuda.Load covers the whole load, with the source name as fuda.source
+//   - fuda.template covers template processing of each source document
+//   - fuda.ref covers each ref resolution, with fuda.ref.scheme and
+//     fuda.ref.uri; passwords and query values in the URI are redacted
+//   - fuda.validate covers validation
+//
+// Spans are children of the span in the context passed to LoadContext.
+// Failed stages record the error and set an error status.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithTracerProvider(otel.GetTracerProvider()).
+//	    Build()
+func (b *Builder) WithTracerProvider(tp trace.TracerProvider) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if tp == nil {
+		b.err = &FieldError{Message: "nil tracer provider"}
+
+		return b
+	}
+	b.config.startSpan = otelSpans(tp.Tracer(tracerName))
+
+	return b
+}
+
+// WithTracerProvider returns an option that records OpenTelemetry spans
+// with tp. See Builder.WithTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) LoaderOption {
+	return func(b *Builder) { b.WithTracerProvider(tp) }
+}
+
+// otelSpans returns a spanFunc that starts spans with tracer.
+func otelSpans(tracer trace.Tracer) spanFunc {
+	return func(ctx context.Context, name string, attrs ...string) (context.Context, func(error)) {
+		kvs := make([]attribute.KeyValue, 0, len(attrs)/2)
+		for i := 0; i+1 < len(attrs); i += 2 {
+			kvs = append(kvs, attribute.String(attrs[i], attrs[i+1]))
+		}
+
+		ctx, span := tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+
+		return ctx, func(err error) {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}
+}
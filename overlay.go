@@ -0,0 +1,349 @@
+package fuda
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// Overlay is a request-scoped view of a config: a shared base config with a
+// few values replaced by path, such as the limits of one tenant. Creating an
+// overlay does not copy the base, so services can derive one per request;
+// read values through a Field, or call Copy for a config struct.
+//
+// Paths are yaml paths as reported by Diff, such as "limits.rps", and name
+// struct fields; elements of slices and maps cannot be replaced. An Overlay
+// is safe for concurrent use as long as the base is not modified.
+//
+// Example:
+//
+//	var rps = fuda.MustField[Config, int]("limits.rps")
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//	    view, err := fuda.NewOverlay(cfg.Get(), tenantLimits[tenantOf(r)])
+//	    if err != nil {
+//	        ...
+//	    }
+//	    limiter.SetLimit(rps.In(view))
+//	}
+type Overlay[T any] struct {
+	base   *T
+	values map[string]any  // overlaid values by path, of the field types
+	parent map[string]bool // paths of structs containing overlaid values
+}
+
+// Field is a typed accessor for the value at a yaml path of config type T.
+// The path is resolved once, so reading the value with Get or In costs
+// little more than a map lookup. Fields are usually package variables.
+type Field[T, V any] struct {
+	path  string
+	index []int // field indexes from T, pointers followed in between
+}
+
+// overlayPath is a path of a config type resolved to struct fields.
+type overlayPath struct {
+	index []int
+	typ   reflect.Type
+}
+
+// overlayPathKey identifies a path of a config type in overlayPaths.
+type overlayPathKey struct {
+	typ  reflect.Type
+	path string
+}
+
+// overlayPaths caches resolved paths, so overlays created per request only
+// pay for map lookups.
+var overlayPaths sync.Map // overlayPathKey → overlayPath
+
+// NewOverlay returns a view of base, which must not be nil, with values
+// replacing the fields at their paths. A value may have the field type, be
+// a number convertible to it without loss, or be a string converted like
+// an env var; nil means the zero value. An unknown path or unconvertible
+// value is an error.
+func NewOverlay[T any](base *T, values map[string]any) (*Overlay[T], error) {
+	o := &Overlay[T]{base: base, values: make(map[string]any, len(values))}
+	for path, value := range values {
+		p, err := resolveOverlayPath(reflect.TypeFor[T](), path)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := overlayValue(p.typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %q: %w", path, err)
+		}
+		o.values[path] = v.Interface()
+
+		for i := strings.LastIndexByte(path, '.'); i > 0; i = strings.LastIndexByte(path[:i], '.') {
+			if o.parent == nil {
+				o.parent = make(map[string]bool)
+			}
+			o.parent[path[:i]] = true
+		}
+	}
+
+	return o, nil
+}
+
+// Base returns the config the overlay is based on. It must not be modified.
+func (o *Overlay[T]) Base() *T {
+	return o.base
+}
+
+// Lookup returns the value at path, from the overlay or else from the base
+// config, and whether path names a field. A nil pointer on the path yields
+// the zero value of the field.
+func (o *Overlay[T]) Lookup(path string) (any, bool) {
+	if v, ok := o.values[path]; ok {
+		return v, true
+	}
+
+	p, err := resolveOverlayPath(reflect.TypeFor[T](), path)
+	if err != nil {
+		return nil, false
+	}
+
+	return o.valueAt(path, p).Interface(), true
+}
+
+// Copy returns the base config with the overlay applied. The copy is
+// shallow like Value.Snapshot, except that structs behind pointers on the
+// overlaid paths are copied, so the base is never modified.
+func (o *Overlay[T]) Copy() T {
+	c := *o.base
+	cv := reflect.ValueOf(&c).Elem()
+	for path, value := range o.values {
+		p, _ := resolveOverlayPath(reflect.TypeFor[T](), path) // resolved in NewOverlay
+		setOverlayValue(cv, p, value)
+	}
+
+	return c
+}
+
+// valueAt returns the value of the resolved path, with the overlay applied
+// to a copy if the path is a struct containing overlaid values.
+func (o *Overlay[T]) valueAt(path string, p overlayPath) reflect.Value {
+	v, ok := fieldByIndex(reflect.ValueOf(o.base).Elem(), p.index)
+	if !ok {
+		v = reflect.Zero(p.typ)
+	}
+	if !o.parent[path] {
+		return v
+	}
+
+	c := reflect.New(p.typ).Elem()
+	c.Set(v)
+	for sub, value := range o.values {
+		rest, ok := strings.CutPrefix(sub, path+".")
+		if !ok {
+			continue
+		}
+		sp, _ := resolveOverlayPath(p.typ, rest)
+		setOverlayValue(c, sp, value)
+	}
+
+	return c
+}
+
+// NewField returns the accessor of the field at path in config type T,
+// which must have type V.
+func NewField[T, V any](path string) (*Field[T, V], error) {
+	p, err := resolveOverlayPath(reflect.TypeFor[T](), path)
+	if err != nil {
+		return nil, err
+	}
+	if want := reflect.TypeFor[V](); p.typ != want {
+		return nil, fmt.Errorf("field %q has type %s, not %s", path, p.typ, want)
+	}
+
+	return &Field[T, V]{path: path, index: p.index}, nil
+}
+
+// MustField is like NewField but panics on error, for package-level
+// accessors.
+func MustField[T, V any](path string) *Field[T, V] {
+	f, err := NewField[T, V](path)
+	if err != nil {
+		panic("fuda: " + err.Error())
+	}
+
+	return f
+}
+
+// Path returns the yaml path of the field.
+func (f *Field[T, V]) Path() string {
+	return f.path
+}
+
+// Get returns the value of the field in cfg, or the zero value if a nil
+// pointer lies on the path.
+func (f *Field[T, V]) Get(cfg *T) V {
+	v, ok := fieldByIndex(reflect.ValueOf(cfg).Elem(), f.index)
+	if !ok {
+		var zero V
+
+		return zero
+	}
+
+	return *v.Addr().Interface().(*V)
+}
+
+// In returns the value of the field in the overlay: the overlaid value if
+// there is one, or else the value of the base config.
+func (f *Field[T, V]) In(o *Overlay[T]) V {
+	if v, ok := o.values[f.path]; ok {
+		// A nil value of an interface field fails the assertion and
+		// yields the zero value, as it should
+		value, _ := v.(V)
+
+		return value
+	}
+	if !o.parent[f.path] {
+		return f.Get(o.base)
+	}
+
+	value, _ := o.valueAt(f.path, overlayPath{index: f.index, typ: reflect.TypeFor[V]()}).Interface().(V)
+
+	return value
+}
+
+// resolveOverlayPath resolves a yaml path of config type t to field indexes.
+func resolveOverlayPath(t reflect.Type, path string) (overlayPath, error) {
+	key := overlayPathKey{typ: t, path: path}
+	if p, ok := overlayPaths.Load(key); ok {
+		return p.(overlayPath), nil
+	}
+
+	var p overlayPath
+	typ := t
+	for segment := range strings.SplitSeq(path, ".") {
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return overlayPath{}, fmt.Errorf("unknown config path %q", path)
+		}
+		index, ft, ok := fieldByKey(typ, segment)
+		if !ok {
+			return overlayPath{}, fmt.Errorf("unknown config path %q", path)
+		}
+		p.index = append(p.index, index...)
+		typ = ft
+	}
+	p.typ = typ
+
+	overlayPaths.Store(key, p)
+
+	return p, nil
+}
+
+// fieldByKey returns the index and type of the field of struct type t with
+// the yaml key, looking into inline fields.
+func fieldByKey(t reflect.Type, key string) ([]int, reflect.Type, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, inline, skip := yamlFieldName(field)
+		if skip || (!field.IsExported() && !(field.Anonymous && inline)) {
+			continue
+		}
+
+		if inline {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				continue
+			}
+			if index, typ, ok := fieldByKey(ft, key); ok {
+				return append([]int{i}, index...), typ, true
+			}
+
+			continue
+		}
+		if name == key {
+			return []int{i}, field.Type, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// fieldByIndex returns the field of struct v at index, following pointers,
+// and false if a pointer on the way is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+
+	return v, true
+}
+
+// setOverlayValue sets the field of struct v at path p to value, replacing
+// the pointers on the way with pointers to copies, or to new zero structs
+// if they are nil, so the values they pointed to stay unchanged.
+func setOverlayValue(v reflect.Value, p overlayPath, value any) {
+	for _, i := range p.index {
+		for v.Kind() == reflect.Pointer {
+			c := reflect.New(v.Type().Elem())
+			if !v.IsNil() {
+				c.Elem().Set(v.Elem())
+			}
+			v.Set(c)
+			v = c.Elem()
+		}
+		v = v.Field(i)
+	}
+
+	if value == nil {
+		v.SetZero()
+
+		return
+	}
+	v.Set(reflect.ValueOf(value))
+}
+
+// overlayValue converts an overlay value to type t.
+func overlayValue(t reflect.Type, value any) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		return reflect.Zero(t), nil
+	case v.Type().AssignableTo(t):
+		c := reflect.New(t).Elem()
+		c.Set(v)
+
+		return c, nil
+	case isNumberKind(v.Kind()) && isNumberKind(t.Kind()) && v.CanConvert(t):
+		c := v.Convert(t)
+		if !c.Convert(v.Type()).Equal(v) {
+			return reflect.Value{}, fmt.Errorf("%v does not fit %s", value, t)
+		}
+
+		return c, nil
+	case v.Kind() == reflect.String:
+		c := reflect.New(t).Elem()
+		if err := types.Convert(v.String(), c); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return c, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot use %T as %s", value, t)
+	}
+}
+
+// isNumberKind reports whether k is an integer or floating-point kind.
+func isNumberKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
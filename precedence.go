@@ -0,0 +1,29 @@
+package fuda
+
+import "github.com/arloliu/fuda/internal/loader"
+
+// Source identifies a source of field values, for ordering sources with
+// Builder.WithPrecedence.
+type Source = loader.Source
+
+// Value sources, from lowest to highest priority in the default order.
+const (
+	// SourceDefault is the default tag, including env tag fallbacks.
+	SourceDefault = loader.SourceDefault
+	// SourceRef is the ref and refFrom tags.
+	SourceRef = loader.SourceRef
+	// SourceFile is the config file, reader, or bytes (see FromFile).
+	SourceFile = loader.SourceFile
+	// SourceOverride is the programmatic overrides (see WithOverrides).
+	SourceOverride = loader.SourceOverride
+	// SourceEnv is the env tag and automatic env names (see WithAutoEnv).
+	SourceEnv = loader.SourceEnv
+	// SourceFlag is the flag tag (see WithFlagSet).
+	SourceFlag = loader.SourceFlag
+)
+
+// DefaultPrecedence returns the built-in order of sources, lowest priority
+// first: default, ref, file, override, env, flag.
+func DefaultPrecedence() []Source {
+	return append([]Source(nil), loader.DefaultPrecedence...)
+}
@@ -0,0 +1,133 @@
+// Package prometheus exports fuda loader and watcher measurements as
+// Prometheus metrics, so config reload health can be alerted on.
+//
+// Basic usage:
+//
+//	m, err := prometheus.New(prom.DefaultRegisterer)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	w, _ := watcher.New().
+//	    FromFile("config.yaml").
+//	    WithMetrics(m).
+//	    Build()
+//
+// Metrics implements fuda.Metrics and exports, with the default namespace:
+//
+//	fuda_reloads_total                     reloads, successful or not
+//	fuda_reload_failures_total             reloads that kept the previous config
+//	fuda_last_reload_timestamp_seconds     time of the last reload
+//	fuda_last_reload_successful            1 if the last reload succeeded, else 0
+//	fuda_ref_resolution_duration_seconds   ref resolution latency, by scheme and result
+//
+// A typical alert fires when fuda_last_reload_successful is 0 for longer
+// than a few minutes.
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Result label values of fuda_ref_resolution_duration_seconds.
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+// Metrics records fuda measurements as Prometheus metrics. It satisfies
+// fuda.Metrics and is safe for concurrent use.
+type Metrics struct {
+	reloads        prom.Counter
+	reloadFailures prom.Counter
+	lastReload     prom.Gauge
+	lastSuccessful prom.Gauge
+	refDuration    *prom.HistogramVec
+}
+
+// New creates Metrics and registers them with reg. A nil reg leaves them
+// unregistered. It returns an error if a metric of the same name is already
+// registered, as when two loaders share a registry without distinct
+// WithConstLabels or WithNamespace.
+func New(reg prom.Registerer, opts ...Option) (*Metrics, error) {
+	cfg := config{namespace: "fuda", buckets: prom.DefBuckets}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	m := &Metrics{
+		reloads: prom.NewCounter(prom.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "reloads_total",
+			Help:        "Total number of config reloads, successful or not.",
+			ConstLabels: cfg.constLabels,
+		}),
+		reloadFailures: prom.NewCounter(prom.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "reload_failures_total",
+			Help:        "Total number of failed config reloads, which kept the previous config.",
+			ConstLabels: cfg.constLabels,
+		}),
+		lastReload: prom.NewGauge(prom.GaugeOpts{
+			Namespace:   cfg.namespace,
+			Name:        "last_reload_timestamp_seconds",
+			Help:        "Unix time of the last config reload.",
+			ConstLabels: cfg.constLabels,
+		}),
+		lastSuccessful: prom.NewGauge(prom.GaugeOpts{
+			Namespace:   cfg.namespace,
+			Name:        "last_reload_successful",
+			Help:        "Whether the last config reload succeeded (1) or failed (0).",
+			ConstLabels: cfg.constLabels,
+		}),
+		refDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "ref_resolution_duration_seconds",
+			Help:        "Latency of ref resolutions by URI scheme and result.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}, []string{"scheme", "result"}),
+	}
+
+	if reg != nil {
+		for _, c := range m.collectors() {
+			if err := reg.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// RefResolved records the latency d of a ref resolution of scheme.
+func (m *Metrics) RefResolved(scheme string, d time.Duration, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+
+	m.refDuration.WithLabelValues(scheme, result).Observe(d.Seconds())
+}
+
+// Reloaded records a reload finished at t, failed if err is not nil.
+func (m *Metrics) Reloaded(t time.Time, err error) {
+	m.reloads.Inc()
+	m.lastReload.Set(float64(t.UnixNano()) / 1e9)
+
+	if err != nil {
+		m.reloadFailures.Inc()
+		m.lastSuccessful.Set(0)
+
+		return
+	}
+
+	m.lastSuccessful.Set(1)
+}
+
+// collectors returns the metrics to register.
+func (m *Metrics) collectors() []prom.Collector {
+	return []prom.Collector{m.reloads, m.reloadFailures, m.lastReload, m.lastSuccessful, m.refDuration}
+}
@@ -0,0 +1,92 @@
+package prometheus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Reloaded(t *testing.T) {
+	reg := prom.NewRegistry()
+	m, err := New(reg)
+	require.NoError(t, err)
+
+	m.Reloaded(time.Unix(100, 0), nil)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.reloads), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.reloadFailures), 0)
+	assert.InDelta(t, 100, testutil.ToFloat64(m.lastReload), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.lastSuccessful), 0)
+
+	m.Reloaded(time.Unix(200, 500_000_000), errors.New("invalid config"))
+	assert.InDelta(t, 2, testutil.ToFloat64(m.reloads), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.reloadFailures), 0)
+	assert.InDelta(t, 200.5, testutil.ToFloat64(m.lastReload), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.lastSuccessful), 0)
+
+	expected := `
+# HELP fuda_reload_failures_total Total number of failed config reloads, which kept the previous config.
+# TYPE fuda_reload_failures_total counter
+fuda_reload_failures_total 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "fuda_reload_failures_total"))
+}
+
+func TestMetrics_RefResolved(t *testing.T) {
+	reg := prom.NewRegistry()
+	m, err := New(reg, WithBuckets([]float64{0.1, 1}))
+	require.NoError(t, err)
+
+	m.RefResolved("vault", 50*time.Millisecond, nil)
+	m.RefResolved("vault", 2*time.Second, nil)
+	m.RefResolved("vault", time.Second, errors.New("denied"))
+
+	expected := `
+# HELP fuda_ref_resolution_duration_seconds Latency of ref resolutions by URI scheme and result.
+# TYPE fuda_ref_resolution_duration_seconds histogram
+fuda_ref_resolution_duration_seconds_bucket{result="error",scheme="vault",le="0.1"} 0
+fuda_ref_resolution_duration_seconds_bucket{result="error",scheme="vault",le="1"} 1
+fuda_ref_resolution_duration_seconds_bucket{result="error",scheme="vault",le="+Inf"} 1
+fuda_ref_resolution_duration_seconds_sum{result="error",scheme="vault"} 1
+fuda_ref_resolution_duration_seconds_count{result="error",scheme="vault"} 1
+fuda_ref_resolution_duration_seconds_bucket{result="success",scheme="vault",le="0.1"} 1
+fuda_ref_resolution_duration_seconds_bucket{result="success",scheme="vault",le="1"} 1
+fuda_ref_resolution_duration_seconds_bucket{result="success",scheme="vault",le="+Inf"} 2
+fuda_ref_resolution_duration_seconds_sum{result="success",scheme="vault"} 2.05
+fuda_ref_resolution_duration_seconds_count{result="success",scheme="vault"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "fuda_ref_resolution_duration_seconds"))
+}
+
+func TestNew_Options(t *testing.T) {
+	reg := prom.NewRegistry()
+	_, err := New(reg, WithNamespace("billing"), WithConstLabels(prom.Labels{"config": "main"}))
+	require.NoError(t, err)
+
+	m, err := New(reg, WithNamespace("billing"), WithConstLabels(prom.Labels{"config": "main"}))
+	require.Error(t, err, "registering the same metrics twice should fail")
+	assert.Nil(t, m)
+
+	_, err = New(reg, WithNamespace("billing"), WithConstLabels(prom.Labels{"config": "audit"}))
+	require.NoError(t, err, "distinct const labels should not conflict")
+
+	m, err = New(reg, WithNamespace("billing"), WithConstLabels(prom.Labels{"config": "other"}))
+	require.NoError(t, err)
+	m.Reloaded(time.Unix(1, 0), nil)
+
+	count, err := testutil.GatherAndCount(reg, "billing_reloads_total")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestNew_NilRegisterer(t *testing.T) {
+	m, err := New(nil)
+	require.NoError(t, err)
+	m.Reloaded(time.Now(), nil)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.reloads), 0)
+}
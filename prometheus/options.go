@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Option configures Metrics.
+type Option func(*config)
+
+// config holds Metrics configuration.
+type config struct {
+	namespace   string
+	constLabels prom.Labels
+	buckets     []float64
+}
+
+// WithNamespace sets the prefix of the metric names. Default is "fuda".
+//
+// Example:
+//
+//	prometheus.WithNamespace("billing_config") // billing_config_reloads_total
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels adds labels with fixed values to every metric, for
+// example to tell apart several loaders in one process.
+//
+// Example:
+//
+//	prometheus.WithConstLabels(prom.Labels{"config": "billing"})
+func WithConstLabels(labels prom.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// WithBuckets sets the histogram buckets, in seconds, of the ref resolution
+// latency. Default is prom.DefBuckets (5ms to 10s).
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
@@ -0,0 +1,484 @@
+//go:build !fuda_minimal
+
+package fuda
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Media types of the OCI artifact pushed by PublishSchema.
+const (
+	SchemaArtifactType    = "application/vnd.fuda.config-schema.v1"
+	schemaConfigMediaType = "application/vnd.fuda.config-schema.config.v1+json"
+	schemaLayerMediaType  = "application/schema+json"
+	docsLayerMediaType    = "text/markdown"
+	ociManifestMediaType  = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// maxPublishErrorBody bounds the response body quoted in publish errors.
+const maxPublishErrorBody = 512
+
+// ociTagPattern matches valid OCI tags.
+var ociTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+
+// SchemaBundle is the JSON document PublishSchema sends to an HTTP target.
+type SchemaBundle struct {
+	// Name identifies the config in the catalog.
+	Name string `json:"name"`
+	// Version is the version tag of this schema.
+	Version string `json:"version"`
+	// Schema is the JSON Schema returned by Schema.
+	Schema json.RawMessage `json:"schema"`
+	// Docs is the Markdown documentation of the config.
+	Docs string `json:"docs"`
+}
+
+// PublishOptions configures PublishSchema.
+type PublishOptions struct {
+	// Target is where the bundle is published: an http or https URL the
+	// SchemaBundle is POSTed to, or an OCI repository such as
+	// "oci://ghcr.io/acme/config-schemas/billing".
+	Target string
+	// Version tags the published bundle, e.g. "1.4.0" or a commit hash.
+	// For OCI targets it is the tag of the pushed artifact.
+	Version string
+	// Name identifies the config in the catalog. Defaults to the struct name.
+	Name string
+	// Docs is the Markdown documentation published with the schema, such as
+	// the output of "fuda-doc -markdown". Empty generates a table of the
+	// config keys from the schema.
+	Docs []byte
+	// Username and Password authenticate with HTTP basic auth, or exchange
+	// for a bearer token when an OCI registry asks for one.
+	Username string
+	Password string
+	// Header is sent with every request, e.g. an API key for the catalog.
+	Header http.Header
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// PlainHTTP talks to an OCI registry over http instead of https, for
+	// local test registries.
+	PlainHTTP bool
+}
+
+// PublishSchema publishes the JSON Schema of target, a struct or pointer to
+// struct, together with its Markdown docs, so platform teams can keep a
+// catalog of service config schemas generated from code.
+//
+// For an http or https Target, the SchemaBundle is POSTed as JSON and any 2xx
+// response counts as success. For an oci:// Target, the schema and docs are
+// pushed as an OCI artifact (type SchemaArtifactType) tagged with Version,
+// with the layers titled "config.schema.json" and "README.md", so
+// "oras pull" fetches them as files.
+//
+// Example:
+//
+//	err := fuda.PublishSchema(ctx, &Config{}, fuda.PublishOptions{
+//	    Target:   "oci://ghcr.io/acme/config-schemas/billing",
+//	    Version:  version,
+//	    Username: os.Getenv("REGISTRY_USER"),
+//	    Password: os.Getenv("REGISTRY_TOKEN"),
+//	})
+func PublishSchema(ctx context.Context, target any, opts PublishOptions) error {
+	root, err := schemaDocument(target)
+	if err != nil {
+		return err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name, _ = root["title"].(string)
+	}
+	if name == "" {
+		return errors.New("publish schema: Name is required for anonymous struct types")
+	}
+	if opts.Version == "" {
+		return errors.New("publish schema: Version is required")
+	}
+
+	schema, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return err
+	}
+	docs := opts.Docs
+	if len(docs) == 0 {
+		docs = schemaDocs(name, opts.Version, root)
+	}
+
+	u, err := url.Parse(opts.Target)
+	if err != nil {
+		return fmt.Errorf("publish schema: invalid target: %w", err)
+	}
+
+	p := &publisher{opts: opts, client: opts.Client}
+	if p.client == nil {
+		p.client = http.DefaultClient
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		bundle, err := json.Marshal(SchemaBundle{Name: name, Version: opts.Version, Schema: schema, Docs: string(docs)})
+		if err != nil {
+			return err
+		}
+
+		return p.post(ctx, u, bundle)
+	case "oci":
+		if !ociTagPattern.MatchString(opts.Version) {
+			return fmt.Errorf("publish schema: version %q is not a valid OCI tag", opts.Version)
+		}
+
+		return p.pushArtifact(ctx, u, name, schema, docs)
+	default:
+		return fmt.Errorf("publish schema: invalid target %q: scheme must be http, https, or oci", u.Redacted())
+	}
+}
+
+// publisher sends the requests of one PublishSchema call.
+type publisher struct {
+	opts   PublishOptions
+	client *http.Client
+	token  string // registry bearer token, once obtained
+}
+
+// post sends the bundle to an HTTP catalog endpoint.
+func (p *publisher) post(ctx context.Context, u *url.URL, bundle []byte) error {
+	resp, err := p.do(ctx, http.MethodPost, u.String(), "application/json", bundle)
+	if err != nil {
+		return fmt.Errorf("failed to publish schema to %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 { //nolint:mnd // any 2xx status
+		return publishStatusError(u.Redacted(), resp)
+	}
+
+	return nil
+}
+
+// pushArtifact pushes the schema and docs to the OCI repository at u as an
+// artifact tagged with the version.
+func (p *publisher) pushArtifact(ctx context.Context, u *url.URL, name string, schema, docs []byte) error {
+	repo := strings.Trim(u.Path, "/")
+	if u.Host == "" || repo == "" {
+		return fmt.Errorf("publish schema: invalid target %q: want oci://registry/repository", u.Redacted())
+	}
+	scheme := "https"
+	if p.opts.PlainHTTP {
+		scheme = "http"
+	}
+	base := scheme + "://" + u.Host + "/v2/" + repo
+
+	config, err := json.Marshal(map[string]string{"name": name, "version": p.opts.Version})
+	if err != nil {
+		return err
+	}
+
+	configDesc := ociDescriptor(schemaConfigMediaType, config, "")
+	layers := []map[string]any{
+		ociDescriptor(schemaLayerMediaType, schema, "config.schema.json"),
+		ociDescriptor(docsLayerMediaType, docs, "README.md"),
+	}
+	for _, blob := range [][]byte{config, schema, docs} {
+		if err := p.pushBlob(ctx, base, blob); err != nil {
+			return fmt.Errorf("failed to publish schema to %s: %w", u.Redacted(), err)
+		}
+	}
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2, //nolint:mnd // OCI manifest schema version
+		"mediaType":     ociManifestMediaType,
+		"artifactType":  SchemaArtifactType,
+		"config":        configDesc,
+		"layers":        layers,
+		"annotations": map[string]string{
+			"org.opencontainers.image.title":   name,
+			"org.opencontainers.image.version": p.opts.Version,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	manifestURL := base + "/manifests/" + p.opts.Version
+	resp, err := p.do(ctx, http.MethodPut, manifestURL, ociManifestMediaType, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to publish schema to %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return publishStatusError(u.Redacted(), resp)
+	}
+
+	return nil
+}
+
+// pushBlob uploads blob to the repository at base unless it already exists,
+// using a monolithic upload (POST then PUT with the digest).
+func (p *publisher) pushBlob(ctx context.Context, base string, blob []byte) error {
+	digest := ociDigest(blob)
+
+	resp, err := p.do(ctx, http.MethodHead, base+"/blobs/"+digest, "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = p.do(ctx, http.MethodPost, base+"/blobs/uploads/", "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("blob upload: unexpected status %s", resp.Status)
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return errors.New("blob upload: registry returned no upload location")
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = p.do(ctx, http.MethodPut, location.String(), "application/octet-stream", blob)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("blob upload %s: unexpected status %s", digest, resp.Status)
+	}
+
+	return nil
+}
+
+// do sends a request with the configured credentials. When a registry
+// answers 401 with a Bearer challenge, it obtains a token and retries once.
+func (p *publisher) do(ctx context.Context, method, rawURL, contentType string, body []byte) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if p.opts.Header != nil {
+			req.Header = p.opts.Header.Clone()
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		switch {
+		case p.token != "":
+			req.Header.Set("Authorization", "Bearer "+p.token)
+		case p.opts.Username != "" || p.opts.Password != "":
+			req.SetBasicAuth(p.opts.Username, p.opts.Password)
+		}
+
+		return p.client.Do(req)
+	}
+
+	resp, err := send()
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if resp.StatusCode != http.StatusUnauthorized || p.token != "" ||
+		!strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if err := p.fetchToken(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return send()
+}
+
+// fetchToken exchanges the credentials for a registry bearer token, as
+// described by the challenge in a WWW-Authenticate header.
+func (p *publisher) fetchToken(ctx context.Context, challenge string) error {
+	params := parseChallenge(challenge[len("bearer "):])
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("registry token: invalid realm in challenge %q", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if p.opts.Username != "" || p.opts.Password != "" {
+		req.SetBasicAuth(p.opts.Username, p.opts.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return publishStatusError("registry token "+realm.Redacted(), resp)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("registry token: %w", err)
+	}
+	p.token = cmp.Or(token.Token, token.AccessToken)
+	if p.token == "" {
+		return errors.New("registry token: response has no token")
+	}
+
+	return nil
+}
+
+// parseChallenge parses the comma-separated key="value" parameters of a
+// WWW-Authenticate challenge.
+func parseChallenge(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(s, ", "), "=")
+		if !ok {
+			break
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, s = rest[1:end+1], rest[end+2:]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return params
+}
+
+// publishStatusError describes an unexpected response, quoting the start of
+// its body since registries explain errors there.
+func publishStatusError(target string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxPublishErrorBody))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("failed to publish schema to %s: unexpected status %s: %s", target, resp.Status, msg)
+	}
+
+	return fmt.Errorf("failed to publish schema to %s: unexpected status %s", target, resp.Status)
+}
+
+// ociDescriptor returns the OCI descriptor of blob, with title as its file
+// name annotation if set.
+func ociDescriptor(mediaType string, blob []byte, title string) map[string]any {
+	desc := map[string]any{
+		"mediaType": mediaType,
+		"digest":    ociDigest(blob),
+		"size":      len(blob),
+	}
+	if title != "" {
+		desc["annotations"] = map[string]string{"org.opencontainers.image.title": title}
+	}
+
+	return desc
+}
+
+// ociDigest returns the sha256 digest of blob in OCI form.
+func ociDigest(blob []byte) string {
+	sum := sha256.Sum256(blob)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// schemaDocs renders a Markdown table of the config keys described by the
+// schema, for bundles published without docs.
+func schemaDocs(name, version string, schema map[string]any) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\nVersion `%s`\n\n", name, version)
+	buf.WriteString("| Key | Type | Required | Default | Description |\n")
+	buf.WriteString("|-----|------|----------|---------|-------------|\n")
+	writeSchemaRows(&buf, "", schema)
+
+	return buf.Bytes()
+}
+
+// writeSchemaRows writes one table row per property of schema, recursing
+// into nested objects, array items, and map values.
+func writeSchemaRows(buf *bytes.Buffer, prefix string, schema map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	required, _ := schema["required"].([]string)
+
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		prop, _ := properties[name].(map[string]any)
+		key := prefix + name
+
+		req := ""
+		if slices.Contains(required, name) {
+			req = "yes"
+		}
+		def := ""
+		if v, ok := prop["default"]; ok {
+			data, _ := json.Marshal(v)
+			def = "`" + string(data) + "`"
+		}
+		desc, _ := prop["description"].(string)
+		fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s |\n", key, schemaTypeName(prop), req, def,
+			strings.ReplaceAll(desc, "|", `\|`))
+
+		writeSchemaRows(buf, key+".", prop)
+		if items, ok := prop["items"].(map[string]any); ok {
+			writeSchemaRows(buf, key+"[].", items)
+		}
+		if values, ok := prop["additionalProperties"].(map[string]any); ok {
+			writeSchemaRows(buf, key+".<key>.", values)
+		}
+	}
+}
+
+// schemaTypeName returns the type of a property schema for the docs table.
+func schemaTypeName(prop map[string]any) string {
+	switch t := prop["type"].(type) {
+	case string:
+		if items, ok := prop["items"].(map[string]any); ok && t == "array" {
+			return "array of " + schemaTypeName(items)
+		}
+
+		return t
+	case []string:
+		return strings.Join(t, " or ")
+	default:
+		return "any"
+	}
+}
@@ -0,0 +1,77 @@
+package fuda
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localSchemes are the built-in ref schemes resolved without network access.
+var localSchemes = []string{"file://", "env://", "age://"}
+
+// rateLimiter spaces out calls so they start at most once per interval.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// rateLimitMiddleware limits resolutions of network refs to rps per second.
+// file://, env:// and age:// refs are local and never delayed.
+func rateLimitMiddleware(rps float64) ResolverMiddleware {
+	limiter := &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+
+	return func(next RefResolver) RefResolver {
+		return RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+			if isLocalRef(uri) {
+				return next.Resolve(ctx, uri)
+			}
+
+			if err := limiter.wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limit wait for %s: %w", uri, err)
+			}
+
+			return next.Resolve(ctx, uri)
+		})
+	}
+}
+
+// isLocalRef reports whether uri is resolved without network access.
+func isLocalRef(uri string) bool {
+	for _, scheme := range localSchemes {
+		if strings.HasPrefix(uri, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wait blocks until the caller's turn or until ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
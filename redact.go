@@ -0,0 +1,350 @@
+package fuda
+
+import (
+	"cmp"
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType      = reflect.TypeFor[time.Duration]()
+	timeType          = reflect.TypeFor[time.Time]()
+	yamlMarshalerType = reflect.TypeFor[yaml.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// yamlRenderer converts config values to YAML nodes, handling sensitive
+// fields and doc comments according to its settings.
+type yamlRenderer struct {
+	omitSecrets bool // drop sensitive fields instead of masking them
+	comments    bool // attach doc tags as head comments
+}
+
+// Redact returns cfg as a map keyed by the yaml field names, with the values
+// of sensitive fields replaced by "[REDACTED]", so the effective config can be
+// logged safely:
+//
+//	redacted, err := fuda.Redact(&cfg)
+//	if err == nil {
+//	    slog.Info("effective config", "config", redacted)
+//	}
+//
+// A field is sensitive when tagged `secret:"true"` (or `sensitive:"true"`),
+// or when it is populated by a ref, refFrom, or dsn tag. Use `secret:"false"`
+// to show a ref field that holds no secret. Zero values are never masked, so
+// an unset secret remains visibly empty.
+//
+// cfg must be a struct or a pointer to a struct.
+func Redact(cfg any) (map[string]any, error) {
+	node, err := yamlRenderer{}.node(reflect.ValueOf(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, &FieldError{Message: "config must be a struct or pointer to struct"}
+	}
+
+	var out map[string]any
+	if err := node.Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode redacted config: %w", err)
+	}
+
+	return out, nil
+}
+
+// DumpRedacted writes cfg to w as YAML with the values of sensitive fields
+// replaced by "[REDACTED]". See Redact for which fields are sensitive.
+//
+// Example:
+//
+//	_ = fuda.DumpRedacted(os.Stderr, &cfg)
+//	// server:
+//	//     host: 0.0.0.0
+//	// database:
+//	//     password: '[REDACTED]'
+func DumpRedacted(w io.Writer, cfg any) error {
+	data, err := yamlRenderer{}.marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// marshal renders cfg as YAML. Field names follow the yaml struct tags, so
+// the output has the same shape as the config file.
+func (r yamlRenderer) marshal(cfg any) ([]byte, error) {
+	node, err := r.node(reflect.ValueOf(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+
+	return yaml.Marshal(doc)
+}
+
+// node converts v to a YAML node.
+func (r yamlRenderer) node(v reflect.Value) (*yaml.Node, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+
+	if v.Type() == durationType {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.Interface().(time.Duration).String()}, nil
+	}
+
+	if isOrderedMapType(v.Type()) {
+		// Walked entry by entry, in order, so secrets in the values are masked
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := range v.Len() {
+			key, err := encodeNode(v.Index(i).Field(0))
+			if err != nil {
+				return nil, err
+			}
+			val, err := r.node(v.Index(i).Field(1))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, key, val)
+		}
+
+		return node, nil
+	}
+
+	if encodesItself(v.Type()) {
+		return encodeNode(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		if err := r.appendStructFields(node, v); err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return encodeNode(v)
+		}
+
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := range v.Len() {
+			elem, err := r.node(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, elem)
+		}
+
+		return node, nil
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, compareMapKeys)
+		for _, k := range keys {
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(k.Interface())}
+			val, err := r.node(v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, key, val)
+		}
+
+		return node, nil
+	default:
+		return encodeNode(v)
+	}
+}
+
+// appendStructFields adds the exported fields of struct v to the mapping node.
+// Inline fields are flattened into the parent mapping.
+func (r yamlRenderer) appendStructFields(node *yaml.Node, v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, inline, skip := yamlFieldName(field)
+		if skip || !dumpable(field.Type) {
+			continue
+		}
+
+		// Unexported fields are only reachable through inline embedding.
+		if !field.IsExported() && !(field.Anonymous && inline) {
+			continue
+		}
+
+		fieldVal := v.Field(i)
+		if inline {
+			for fieldVal.Kind() == reflect.Pointer {
+				if fieldVal.IsNil() {
+					break
+				}
+				fieldVal = fieldVal.Elem()
+			}
+			if fieldVal.Kind() == reflect.Struct {
+				if err := r.appendStructFields(node, fieldVal); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		sensitive := tags.IsSensitive(field)
+		if sensitive && r.omitSecrets {
+			continue
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+		if r.comments {
+			key.HeadComment = tags.Get(field, "doc")
+		}
+
+		var val *yaml.Node
+		if sensitive && !fieldVal.IsZero() {
+			val = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tags.RedactedValue}
+		} else {
+			var err error
+			if val, err = r.node(fieldVal); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+
+		node.Content = append(node.Content, key, val)
+	}
+
+	return nil
+}
+
+// yamlFieldName returns the mapping key for a field following yaml.v3 rules:
+// the yaml tag name if present, otherwise the lowercased field name.
+func yamlFieldName(field reflect.StructField) (name string, inline, skip bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name, inline, false
+}
+
+// dumpable reports whether values of t can be rendered as YAML.
+func dumpable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	default:
+		return true
+	}
+}
+
+// encodesItself reports whether t has its own YAML or text representation
+// and should be encoded as a whole rather than walked field by field.
+func encodesItself(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+
+	for _, iface := range []reflect.Type{yamlMarshalerType, textMarshalerType} {
+		if t.Implements(iface) || reflect.PointerTo(t).Implements(iface) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeNode encodes a leaf value with the yaml package.
+func encodeNode(v reflect.Value) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if !v.CanInterface() {
+		node.Kind = yaml.ScalarNode
+		node.Value = v.String()
+
+		return node, nil
+	}
+
+	if err := node.Encode(v.Interface()); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// compareMapKeys orders map keys for deterministic output: numbers and
+// booleans by value, and other keys by their formatted text. Keys that are
+// otherwise equal, such as 1 and "1" in a map[any]any, are ordered by type.
+func compareMapKeys(x, y reflect.Value) int {
+	for x.Kind() == reflect.Interface && !x.IsNil() {
+		x = x.Elem()
+	}
+	for y.Kind() == reflect.Interface && !y.IsNil() {
+		y = y.Elem()
+	}
+
+	c := 0
+	switch {
+	case x.CanInt() && y.CanInt():
+		c = cmp.Compare(x.Int(), y.Int())
+	case x.CanUint() && y.CanUint():
+		c = cmp.Compare(x.Uint(), y.Uint())
+	case x.CanFloat() && y.CanFloat():
+		c = cmp.Compare(x.Float(), y.Float())
+	case x.Kind() == reflect.Bool && y.Kind() == reflect.Bool:
+		c = cmp.Compare(boolRank(x.Bool()), boolRank(y.Bool()))
+	default:
+		c = strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+	}
+	if c != 0 {
+		return c
+	}
+
+	return strings.Compare(keyTypeName(x), keyTypeName(y))
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// keyTypeName returns the type of a map key, or "" for a nil interface.
+func keyTypeName(v reflect.Value) string {
+	if !v.IsValid() || v.Kind() == reflect.Interface {
+		return ""
+	}
+
+	return v.Type().String()
+}
@@ -0,0 +1,162 @@
+//go:build !fuda_minimal
+
+package fuda
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/arloliu/fuda/internal/resolver"
+)
+
+// refDialTimeout is the connect timeout of connections opened by a tuned
+// HTTPTransport, as in http.DefaultTransport.
+const refDialTimeout = 30 * time.Second
+
+// HTTPTransport tunes connection reuse of the client that resolves http://
+// and https:// refs, for Builder.WithRefTransport. Zero fields keep the
+// defaults of http.DefaultTransport.
+type HTTPTransport struct {
+	// MaxIdleConns bounds the idle connections kept across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost bounds the idle connections kept per host. Go
+	// keeps 2 by default, so refs resolved concurrently with
+	// WithRefConcurrency open new connections; raise it to the worker count.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost bounds all connections per host; 0 means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept for reuse.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period of new connections. A negative
+	// value disables TCP keep-alives.
+	KeepAlive time.Duration
+	// DisableKeepAlives closes each connection after one request.
+	DisableKeepAlives bool
+	// Proxy returns the proxy for a request, for example http.ProxyURL(u).
+	// nil uses the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY variables.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// transport returns a clone of http.DefaultTransport with t applied.
+func (t HTTPTransport) transport() *http.Transport {
+	tr := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		tr = base.Clone()
+	}
+
+	if t.MaxIdleConns > 0 {
+		tr.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+	if t.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = t.IdleConnTimeout
+	}
+	if t.KeepAlive != 0 {
+		dialer := &net.Dialer{Timeout: refDialTimeout, KeepAlive: t.KeepAlive}
+		tr.DialContext = dialer.DialContext
+	}
+	tr.DisableKeepAlives = t.DisableKeepAlives
+	if t.Proxy != nil {
+		tr.Proxy = t.Proxy
+	}
+
+	return tr
+}
+
+// refHTTPConfig holds the client settings of the http and https resolvers.
+type refHTTPConfig struct {
+	client    *http.Client
+	transport *HTTPTransport
+}
+
+// register replaces the http and https resolvers of cr with ones using the
+// configured client.
+func (c *refHTTPConfig) register(cr *resolver.CompositeResolver) error {
+	if c == nil {
+		return nil
+	}
+	if c.client != nil && c.transport != nil {
+		return &FieldError{Message: "WithRefHTTPClient and WithRefTransport cannot be combined; tune the client's transport instead"}
+	}
+
+	r := resolver.NewHTTPResolver()
+	if c.client != nil {
+		r.Client = c.client
+	} else {
+		r.Client = &http.Client{Transport: c.transport.transport()}
+	}
+	cr.Register("http", r)
+	cr.Register("https", r)
+
+	return nil
+}
+
+// WithRefHTTPClient sets the client that resolves http:// and https:// refs,
+// for example to share a connection pool with the application or to add
+// authentication in a custom RoundTripper. The default is
+// http.DefaultClient. It has no effect with WithRefResolver.
+//
+// Example:
+//
+//	client := &http.Client{Transport: authTransport{token: token}}
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithRefHTTPClient(client).
+//	    Build()
+func (b *Builder) WithRefHTTPClient(c *http.Client) *Builder {
+	if c == nil {
+		b.err = &FieldError{Message: "WithRefHTTPClient requires a non-nil client"}
+
+		return b
+	}
+	if b.config.refHTTP == nil {
+		b.config.refHTTP = &refHTTPConfig{}
+	}
+	b.config.refHTTP.client = c
+
+	return b
+}
+
+// WithRefTransport tunes the connections of the client that resolves
+// http:// and https:// refs. Configs with many refs to the same hosts,
+// especially with WithRefConcurrency, resolve faster when connections are
+// kept alive and reused. It cannot be combined with WithRefHTTPClient and
+// has no effect with WithRefResolver.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithRefConcurrency(16).
+//	    WithRefTransport(fuda.HTTPTransport{
+//	        MaxIdleConnsPerHost: 16,
+//	        IdleConnTimeout:     5 * time.Minute,
+//	    }).
+//	    Build()
+func (b *Builder) WithRefTransport(t HTTPTransport) *Builder {
+	if b.config.refHTTP == nil {
+		b.config.refHTTP = &refHTTPConfig{}
+	}
+	b.config.refHTTP.transport = &t
+
+	return b
+}
+
+// WithRefHTTPClient returns an option that sets the client resolving http://
+// and https:// refs. See Builder.WithRefHTTPClient.
+func WithRefHTTPClient(c *http.Client) LoaderOption {
+	return func(b *Builder) { b.WithRefHTTPClient(c) }
+}
+
+// WithRefTransport returns an option that tunes the connections used to
+// resolve http:// and https:// refs. See Builder.WithRefTransport.
+func WithRefTransport(t HTTPTransport) LoaderOption {
+	return func(b *Builder) { b.WithRefTransport(t) }
+}
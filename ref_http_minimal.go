@@ -0,0 +1,13 @@
+//go:build fuda_minimal
+
+package fuda
+
+import "github.com/arloliu/fuda/internal/resolver"
+
+// refHTTPConfig is empty: fuda_minimal builds have no http resolver.
+type refHTTPConfig struct{}
+
+// register does nothing: fuda_minimal builds have no http resolver.
+func (*refHTTPConfig) register(*resolver.CompositeResolver) error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package fuda
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/arloliu/fuda/internal/loader"
+	"github.com/spf13/afero"
+)
+
+// Reload re-reads the files given to FromFile or FromFiles, which Build
+// reads once, so later loads see their current content. Long-lived
+// processes can refresh their config on a signal without rebuilding the
+// loader:
+//
+//	loader, _ := fuda.New().FromFile("config.yaml").Build()
+//
+//	var cfg fuda.Value[Config]
+//	hup := make(chan os.Signal, 1)
+//	signal.Notify(hup, syscall.SIGHUP)
+//	for range hup {
+//	    if err := loader.Reload(); err != nil {
+//	        log.Printf("reload: %v", err)
+//	        continue
+//	    }
+//	    if err := cfg.Load(loader); err != nil {
+//	        log.Printf("reload: %v", err)
+//	    }
+//	}
+//
+// If a file cannot be read, the previous content is kept and the error is
+// returned. Sources read at every load, such as FromFileStream, FromURL, and
+// FromObjectStore, and data given to FromBytes or FromReader are left as
+// they are. Reload is safe to call while other goroutines load.
+func (l *Loader) Reload() error {
+	source, layers := l.snapshot()
+
+	switch {
+	case l.open != nil || l.fetch != nil:
+		return nil
+	case len(layers) > 0:
+		fresh := make([]loader.Layer, len(layers))
+		for i, layer := range layers {
+			data, err := afero.ReadFile(l.fs, layer.Name)
+			if err != nil {
+				return fmt.Errorf("failed to reload %s: %w", layer.Name, err)
+			}
+			fresh[i] = loader.Layer{Name: layer.Name, Data: data}
+		}
+		l.setSource(source, fresh)
+	case l.sourcePath != "":
+		data, err := afero.ReadFile(l.fs, l.sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to reload %s: %w", l.sourcePath, err)
+		}
+		l.setSource(data, nil)
+	}
+
+	return nil
+}
+
+// ReloadInto re-reads the source files (see Reload) and loads them into
+// target. It is equivalent to ReloadIntoContext with context.Background().
+func (l *Loader) ReloadInto(target any) error {
+	return l.ReloadIntoContext(context.Background(), target)
+}
+
+// ReloadIntoContext re-reads the source files (see Reload) and loads them
+// into target, a pointer to a struct. The load fills a new value, which
+// replaces *target only if the load succeeds, so a bad edit leaves the
+// current config intact:
+//
+//	if err := loader.ReloadIntoContext(ctx, &cfg); err != nil {
+//	    log.Printf("keeping the current config: %v", err)
+//	}
+//
+// Goroutines that read target while it is replaced need their own
+// synchronization; Value handles that for Load. The outcome is reported to
+// the Metrics set by WithMetrics.
+func (l *Loader) ReloadIntoContext(ctx context.Context, target any) error {
+	targetVal := reflect.ValueOf(target)
+	if targetVal.Kind() != reflect.Pointer || targetVal.IsNil() {
+		return &FieldError{Message: "target must be a non-nil pointer"}
+	}
+
+	err := l.reloadInto(ctx, targetVal)
+	if l.metrics != nil {
+		l.metrics.Reloaded(time.Now(), err)
+	}
+
+	return err
+}
+
+// reloadInto re-reads the source files and loads them into the value
+// targetVal points to.
+func (l *Loader) reloadInto(ctx context.Context, targetVal reflect.Value) error {
+	if err := l.Reload(); err != nil {
+		return err
+	}
+
+	fresh := reflect.New(targetVal.Elem().Type())
+	if err := l.LoadContext(ctx, fresh.Interface()); err != nil {
+		return err
+	}
+	targetVal.Elem().Set(fresh.Elem())
+
+	return nil
+}
+
+// snapshot returns the source document and the FromFiles layers.
+func (l *Loader) snapshot() ([]byte, []loader.Layer) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.source, l.layers
+}
+
+// setSource replaces the source document and the FromFiles layers.
+func (l *Loader) setSource(source []byte, layers []loader.Layer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.source, l.layers = source, layers
+}
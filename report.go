@@ -0,0 +1,234 @@
+package fuda
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// inlineRefPattern matches the URIs of ${ref:uri} and ${ref "uri"} template
+// calls in dsn, ref, and expr tags.
+var inlineRefPattern = regexp.MustCompile(`\$\{\s*ref(?::|\s+")([^"}]+)`)
+
+// ConfigReport summarizes the size and complexity of a config struct, for
+// tracking config sprawl across services. Fields are counted per type, so
+// the fields of a slice or map element struct are counted once.
+type ConfigReport struct {
+	// Type is the name of the config struct.
+	Type string `json:"type"`
+	// Fields is the number of leaf fields, which hold values.
+	Fields int `json:"fields"`
+	// Sections is the number of nested structs holding fields.
+	Sections int `json:"sections"`
+	// MaxDepth is the nesting depth of the deepest field; top-level fields
+	// have depth 1.
+	MaxDepth int `json:"max_depth"`
+	// Secrets is the number of sensitive fields (see Redact).
+	Secrets int `json:"secrets"`
+	// Refs counts ref and secretName tags and inline ${ref:...} calls by
+	// URI scheme. References without a scheme are counted as "file".
+	Refs map[string]int `json:"refs"`
+	// DynamicRefs counts references whose scheme is known only at load
+	// time: refFrom tags and refs with a templated scheme.
+	DynamicRefs int `json:"dynamic_refs"`
+	// EnvVars lists the env tag names, sorted, without the loader's prefix.
+	EnvVars []string `json:"env_vars"`
+	// Validated is the number of fields with a validate tag.
+	Validated int `json:"validated"`
+}
+
+// Report returns the ConfigReport of target, a struct or pointer to struct.
+// It inspects types and tags only, so target may be a zero value.
+//
+// Example:
+//
+//	report, err := fuda.Report(&Config{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Print(report)
+func Report(target any) (*ConfigReport, error) {
+	t := reflect.TypeOf(target)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, &FieldError{Message: "report target must be a struct or pointer to struct"}
+	}
+
+	r := &ConfigReport{Type: t.Name(), Refs: map[string]int{}, EnvVars: []string{}}
+	r.addStruct(t, 1, map[reflect.Type]bool{t: true})
+	sort.Strings(r.EnvVars)
+	r.EnvVars = slices.Compact(r.EnvVars)
+
+	return r, nil
+}
+
+// ValidationCoverage returns the share of fields with a validate tag, from
+// 0 to 1, or 0 if there are no fields.
+func (r *ConfigReport) ValidationCoverage() float64 {
+	if r.Fields == 0 {
+		return 0
+	}
+
+	return float64(r.Validated) / float64(r.Fields)
+}
+
+// RefCount returns the total number of references, including dynamic ones.
+func (r *ConfigReport) RefCount() int {
+	n := r.DynamicRefs
+	for _, count := range r.Refs {
+		n += count
+	}
+
+	return n
+}
+
+// String renders the report as aligned text, one metric per line.
+func (r *ConfigReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Config report: %s\n", r.Type)
+	fmt.Fprintf(&sb, "  Fields:              %d in %d sections\n", r.Fields, r.Sections)
+	fmt.Fprintf(&sb, "  Deepest nesting:     %d\n", r.MaxDepth)
+	fmt.Fprintf(&sb, "  Secrets:             %d\n", r.Secrets)
+	fmt.Fprintf(&sb, "  External refs:       %d%s\n", r.RefCount(), r.refBreakdown())
+	fmt.Fprintf(&sb, "  Env variables:       %d\n", len(r.EnvVars))
+	fmt.Fprintf(&sb, "  Validation coverage: %d/%d fields (%.0f%%)\n", r.Validated, r.Fields, r.ValidationCoverage()*100) //nolint:mnd // percent
+
+	return sb.String()
+}
+
+// refBreakdown returns the per-scheme counts of the refs, such as
+// " (file: 2, vault: 3, dynamic: 1)", or "" if there are none.
+func (r *ConfigReport) refBreakdown() string {
+	schemes := make([]string, 0, len(r.Refs))
+	for scheme := range r.Refs {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	parts := make([]string, 0, len(schemes)+1)
+	for _, scheme := range schemes {
+		parts = append(parts, fmt.Sprintf("%s: %d", scheme, r.Refs[scheme]))
+	}
+	if r.DynamicRefs > 0 {
+		parts = append(parts, fmt.Sprintf("dynamic: %d", r.DynamicRefs))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// addStruct adds the fields of struct type t, at nesting depth depth. seen
+// guards against recursive struct types.
+func (r *ConfigReport) addStruct(t reflect.Type, depth int, seen map[reflect.Type]bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		_, inline, skip := yamlFieldName(field)
+		if skip || !dumpable(field.Type) {
+			continue
+		}
+		if !field.IsExported() && !(field.Anonymous && inline) {
+			continue
+		}
+
+		if st, ok := reportSection(field.Type); ok {
+			if seen[st] {
+				continue
+			}
+			seen[st] = true
+			if inline {
+				r.addStruct(st, depth, seen)
+			} else {
+				r.Sections++
+				r.addStruct(st, depth+1, seen)
+			}
+			delete(seen, st)
+
+			continue
+		}
+
+		r.addField(field, depth)
+	}
+}
+
+// addField adds a leaf field at nesting depth depth.
+func (r *ConfigReport) addField(field reflect.StructField, depth int) {
+	r.Fields++
+	r.MaxDepth = max(r.MaxDepth, depth)
+
+	if tags.IsSensitive(field) {
+		r.Secrets++
+	}
+	if rules := tags.Get(field, "validate"); rules != "" && rules != "-" {
+		r.Validated++
+	}
+	if tag := tags.Get(field, "env"); tag != "" && tag != "-" {
+		if et, err := tags.ParseEnvTag(tag); err == nil {
+			r.EnvVars = append(r.EnvVars, et.Name)
+		}
+	}
+
+	if uri := tags.Get(field, "ref"); uri != "" {
+		r.addRef(uri)
+	}
+	if tags.Get(field, "refFrom") != "" {
+		r.DynamicRefs++
+	}
+	if tags.Get(field, "secretName") != "" {
+		r.Refs["file"]++
+	}
+	for _, key := range []string{"ref", "dsn", "expr"} {
+		for _, m := range inlineRefPattern.FindAllStringSubmatch(tags.Get(field, key), -1) {
+			r.addRef(strings.TrimSpace(m[1]))
+		}
+	}
+}
+
+// addRef counts a reference by the scheme of uri.
+func (r *ConfigReport) addRef(uri string) {
+	scheme, _, ok := strings.Cut(uri, "://")
+	switch {
+	case ok && !strings.Contains(scheme, "${"):
+		r.Refs[scheme]++
+	case !ok && !strings.HasPrefix(strings.TrimSpace(uri), "${"):
+		r.Refs["file"]++ // see tags.NormalizeURI
+	default:
+		r.DynamicRefs++
+	}
+}
+
+// reportSection returns the struct type whose fields make up the values of
+// type t, for structs and for slices, arrays, maps, and pointers of them.
+// Structs that convert themselves, such as time.Time, are values.
+func reportSection(t reflect.Type) (reflect.Type, bool) {
+	for {
+		//nolint:exhaustive // other kinds hold values
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			if isOrderedMapType(t) {
+				t = t.Elem().Field(1).Type // entry value
+
+				continue
+			}
+			t = t.Elem()
+
+			continue
+		case reflect.Struct:
+			if encodesItself(t) || reflect.PointerTo(t).Implements(scannerType) || reflect.PointerTo(t).Implements(decimalType) {
+				return nil, false
+			}
+
+			return t, true
+		default:
+			return nil, false
+		}
+	}
+}
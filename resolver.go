@@ -1,6 +1,17 @@
 package fuda
 
-import "context"
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// registry holds resolvers registered via RegisterResolver, keyed by scheme.
+var registry = struct {
+	mu        sync.RWMutex
+	resolvers map[string]RefResolver
+}{resolvers: make(map[string]RefResolver)}
 
 // RefResolver is an interface for resolving references.
 // It is used to mock reference resolution in tests or provide custom resolution logic.
@@ -9,3 +20,119 @@ type RefResolver interface {
 	// Resolve returns the content referenced by the uri.
 	Resolve(ctx context.Context, uri string) ([]byte, error)
 }
+
+// RefResolverFunc adapts a function to RefResolver.
+type RefResolverFunc func(ctx context.Context, uri string) ([]byte, error)
+
+// ResolverMiddleware wraps a RefResolver to add a cross-cutting concern such
+// as logging, caching, metrics, or rate limiting, and returns the wrapped
+// resolver. See Builder.WithResolverMiddleware.
+//
+// Example:
+//
+//	func countRefs(counter *atomic.Int64) fuda.ResolverMiddleware {
+//	    return func(next fuda.RefResolver) fuda.RefResolver {
+//	        return fuda.RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+//	            counter.Add(1)
+//	            return next.Resolve(ctx, uri)
+//	        })
+//	    }
+//	}
+type ResolverMiddleware func(next RefResolver) RefResolver
+
+// LoadScopedResolver is an optional interface for resolvers that keep state
+// for the duration of a single Load, such as a cache that lets many refs to
+// the same remote document share one fetch. BeginLoad is called once at the
+// start of every Load, and the context it returns is passed to each Resolve
+// call of that load; state attached to it is dropped when the load ends.
+//
+// Resolvers registered via WithResolver or RegisterResolver participate too.
+type LoadScopedResolver interface {
+	RefResolver
+	// BeginLoad returns a context carrying the resolver's per-load state.
+	BeginLoad(ctx context.Context) context.Context
+}
+
+// scopedChain is a middleware-wrapped resolver that keeps the BeginLoad hook
+// of the resolver it wraps.
+type scopedChain struct {
+	RefResolver
+	scope LoadScopedResolver
+}
+
+// RegisterResolver registers r as the resolver for URIs with the given scheme
+// (e.g., "vault" for vault:// URIs) in every Loader built afterwards.
+//
+// The default resolver dispatches each ref by scheme, so registered resolvers
+// coexist with the built-in file://, http://, https://, and env:// resolvers.
+// Registering a built-in scheme replaces the built-in resolver.
+// Registered resolvers are not consulted when WithRefResolver is used.
+//
+// RegisterResolver is typically called from init or main before any loader
+// is built. It panics if scheme is empty or r is nil.
+//
+// Example:
+//
+//	vaultResolver, _ := vault.NewResolver(...)
+//	fuda.RegisterResolver("vault", vaultResolver)
+func RegisterResolver(scheme string, r RefResolver) {
+	if scheme == "" {
+		panic("fuda: RegisterResolver called with empty scheme")
+	}
+	if r == nil {
+		panic("fuda: RegisterResolver called with nil resolver for scheme " + scheme)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.resolvers[scheme] = r
+}
+
+// UnregisterResolver removes the resolver registered for scheme, restoring
+// the built-in resolver if the scheme has one.
+func UnregisterResolver(scheme string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	delete(registry.resolvers, scheme)
+}
+
+// applyMiddleware wraps r with mws, the first middleware outermost. The
+// BeginLoad hook of r, if any, is preserved.
+func applyMiddleware(r RefResolver, mws []ResolverMiddleware) RefResolver {
+	if len(mws) == 0 {
+		return r
+	}
+
+	wrapped := r
+	for _, mw := range slices.Backward(mws) {
+		wrapped = mw(wrapped)
+	}
+
+	if scope, ok := r.(LoadScopedResolver); ok {
+		if _, ok := wrapped.(LoadScopedResolver); !ok {
+			return scopedChain{RefResolver: wrapped, scope: scope}
+		}
+	}
+
+	return wrapped
+}
+
+// registeredResolvers returns a snapshot of the globally registered resolvers.
+func registeredResolvers() map[string]RefResolver {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return maps.Clone(registry.resolvers)
+}
+
+// Resolve calls f(ctx, uri).
+func (f RefResolverFunc) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	return f(ctx, uri)
+}
+
+// BeginLoad forwards to the wrapped resolver's BeginLoad.
+func (c scopedChain) BeginLoad(ctx context.Context) context.Context {
+	return c.scope.BeginLoad(ctx)
+}
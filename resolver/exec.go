@@ -0,0 +1,197 @@
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/arloliu/fuda"
+)
+
+// maxResponseSize bounds a single plugin response line.
+const maxResponseSize = 16 << 20
+
+// ExecResolver resolves refs by delegating to an external plugin executable
+// speaking the fuda resolver plugin protocol. Requests are sent one at a
+// time over a single long-lived process. It is safe for concurrent use.
+type ExecResolver struct {
+	path string
+	args []string
+
+	mu     sync.Mutex
+	proc   *pluginProcess
+	nextID uint64
+}
+
+// pluginProcess is a running plugin and the decoder feeding its responses.
+type pluginProcess struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan Response
+	readErr   error // set before responses is closed
+}
+
+var _ fuda.RefResolver = (*ExecResolver)(nil)
+
+// Exec returns a resolver backed by the plugin executable at path, started
+// with args on first use. A path without a separator is looked up in PATH.
+//
+// The plugin is restarted transparently if it exits or a request is canceled
+// mid-flight. Call Close to stop it when the resolver is no longer needed.
+func Exec(path string, args ...string) *ExecResolver {
+	return &ExecResolver{path: path, args: args}
+}
+
+// Resolve sends uri to the plugin and returns the resolved content.
+// A plugin response with notFound set yields an error wrapping
+// os.ErrNotExist, so default tags still apply.
+func (r *ExecResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	proc, err := r.process()
+	if err != nil {
+		return nil, err
+	}
+
+	r.nextID++
+	req := Request{Protocol: Protocol, ID: r.nextID, URI: uri}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode plugin request: %w", err)
+	}
+
+	if _, err := proc.stdin.Write(append(line, '\n')); err != nil {
+		r.stop()
+
+		return nil, fmt.Errorf("resolver plugin %s: write request: %w", r.path, err)
+	}
+
+	select {
+	case resp, ok := <-proc.responses:
+		if !ok {
+			r.stop()
+
+			return nil, fmt.Errorf("resolver plugin %s exited: %w", r.path, proc.readErr)
+		}
+		if resp.ID != req.ID {
+			r.stop()
+
+			return nil, fmt.Errorf("resolver plugin %s: response id %d does not match request id %d", r.path, resp.ID, req.ID)
+		}
+
+		return resp.result(uri)
+	case <-ctx.Done():
+		// The plugin may still answer later; restart it so the next request
+		// does not read a stale response.
+		r.stop()
+
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the plugin process, if running.
+func (r *ExecResolver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stop()
+
+	return nil
+}
+
+// process returns the running plugin, starting it if needed.
+// Must be called with r.mu held.
+func (r *ExecResolver) process() (*pluginProcess, error) {
+	if r.proc != nil {
+		return r.proc, nil
+	}
+
+	cmd := exec.Command(r.path, r.args...) //nolint:gosec // plugin path is chosen by the application
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("resolver plugin %s: %w", r.path, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("resolver plugin %s: %w", r.path, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start resolver plugin %s: %w", r.path, err)
+	}
+
+	proc := &pluginProcess{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan Response, 1),
+	}
+	go proc.readResponses(stdout)
+
+	r.proc = proc
+
+	return proc, nil
+}
+
+// stop terminates the plugin process. Must be called with r.mu held.
+func (r *ExecResolver) stop() {
+	if r.proc == nil {
+		return
+	}
+
+	_ = r.proc.stdin.Close()
+	_ = r.proc.cmd.Process.Kill()
+	_ = r.proc.cmd.Wait()
+	r.proc = nil
+}
+
+// readResponses decodes response lines until the plugin's stdout closes.
+func (p *pluginProcess) readResponses(stdout io.Reader) {
+	defer close(p.responses)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseSize)
+
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			p.readErr = fmt.Errorf("invalid response: %w", err)
+
+			return
+		}
+		p.responses <- resp
+	}
+
+	p.readErr = scanner.Err()
+	if p.readErr == nil {
+		p.readErr = io.EOF
+	}
+}
+
+// result converts the response into Resolve return values.
+func (resp Response) result(uri string) ([]byte, error) {
+	switch {
+	case resp.NotFound:
+		if resp.Error == "" {
+			return nil, fmt.Errorf("%s: %w", uri, os.ErrNotExist)
+		}
+
+		return nil, fmt.Errorf("%s: %s: %w", uri, resp.Error, os.ErrNotExist)
+	case resp.Error != "":
+		return nil, errors.New(resp.Error)
+	default:
+		return resp.Data, nil
+	}
+}
@@ -0,0 +1,211 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/resolver/resolvertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pluginEnv makes the test binary act as a resolver plugin when re-executed.
+const pluginEnv = "FUDA_TEST_RESOLVER_PLUGIN"
+
+// pluginDataEnv names a JSON file of URIs and contents for the plugin to
+// serve instead of testPlugin's fixed set.
+const pluginDataEnv = "FUDA_TEST_RESOLVER_DATA"
+
+// testPlugin resolves a fixed set of URIs for the helper process.
+type testPlugin struct{}
+
+func (testPlugin) Resolve(_ context.Context, uri string) ([]byte, error) {
+	switch uri {
+	case "corp://db/password":
+		return []byte("s3cret"), nil
+	case "corp://pid":
+		return fmt.Appendf(nil, "%d", os.Getpid()), nil
+	case "corp://slow":
+		select {} // never answers
+	case "corp://exit":
+		os.Exit(3)
+	case "corp://broken":
+		return nil, errors.New("backend unavailable")
+	}
+
+	return nil, os.ErrNotExist
+}
+
+// dataPlugin resolves the URIs loaded from the pluginDataEnv file.
+type dataPlugin map[string][]byte
+
+func (p dataPlugin) Resolve(_ context.Context, uri string) ([]byte, error) {
+	if data, ok := p[uri]; ok {
+		return data, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) == "1" {
+		var plugin fuda.RefResolver = testPlugin{}
+		if path := os.Getenv(pluginDataEnv); path != "" {
+			data, err := os.ReadFile(path)
+			var p dataPlugin
+			if err == nil {
+				err = json.Unmarshal(data, &p)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			plugin = p
+		}
+		if err := ServeStdio(plugin); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func newTestExec(t *testing.T) *ExecResolver {
+	t.Helper()
+	t.Setenv(pluginEnv, "1")
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	r := Exec(exe, "-test.run=^$")
+	t.Cleanup(func() { _ = r.Close() })
+
+	return r
+}
+
+func TestExec_Conformance(t *testing.T) {
+	resolvertest.Run(t, func(t *testing.T, values map[string][]byte) resolvertest.Fixture {
+		uris := make(map[string]string, len(values))
+		data := make(dataPlugin, len(values))
+		for name, value := range values {
+			uris[name] = "corp://" + name
+			data[uris[name]] = value
+		}
+		encoded, err := json.Marshal(data)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "data.json")
+		require.NoError(t, os.WriteFile(path, encoded, 0o600))
+		t.Setenv(pluginDataEnv, path)
+
+		return resolvertest.Fixture{Resolver: newTestExec(t), URIs: uris, MissingURI: "corp://missing"}
+	})
+}
+
+func TestExec_Resolve(t *testing.T) {
+	r := newTestExec(t)
+
+	data, err := r.Resolve(t.Context(), "corp://db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(data))
+
+	_, err = r.Resolve(t.Context(), "corp://unknown")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = r.Resolve(t.Context(), "corp://broken")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend unavailable")
+	assert.NotErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestExec_ReusesProcess(t *testing.T) {
+	r := newTestExec(t)
+
+	first, err := r.Resolve(t.Context(), "corp://pid")
+	require.NoError(t, err)
+	second, err := r.Resolve(t.Context(), "corp://pid")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestExec_RestartsAfterExit(t *testing.T) {
+	r := newTestExec(t)
+
+	_, err := r.Resolve(t.Context(), "corp://exit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exited")
+
+	data, err := r.Resolve(t.Context(), "corp://db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(data))
+}
+
+func TestExec_ContextCancel(t *testing.T) {
+	r := newTestExec(t)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := r.Resolve(ctx, "corp://slow")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The stuck plugin is replaced, so later requests still work.
+	data, err := r.Resolve(t.Context(), "corp://db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(data))
+}
+
+func TestExec_StartFailure(t *testing.T) {
+	r := Exec("/nonexistent/fuda-resolver-plugin")
+
+	_, err := r.Resolve(t.Context(), "corp://db/password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start resolver plugin")
+}
+
+func TestExec_WithLoader(t *testing.T) {
+	type Config struct {
+		Password string `ref:"corp://db/password"`
+		Region   string `ref:"corp://region" default:"us-east-1"`
+	}
+
+	loader, err := fuda.New().WithResolver("corp", newTestExec(t)).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "s3cret", cfg.Password)
+	assert.Equal(t, "us-east-1", cfg.Region)
+}
+
+func TestServe(t *testing.T) {
+	in := strings.NewReader(
+		`{"protocol":"fuda-resolver/v1","id":1,"uri":"corp://db/password"}` + "\n" +
+			`{"protocol":"fuda-resolver/v1","id":2,"uri":"corp://unknown"}` + "\n" +
+			`{"protocol":"fuda-resolver/v9","id":3,"uri":"corp://db/password"}` + "\n")
+	var out bytes.Buffer
+
+	require.NoError(t, Serve(t.Context(), testPlugin{}, in, &out))
+	assert.Equal(t,
+		`{"id":1,"data":"czNjcmV0"}`+"\n"+
+			`{"id":2,"error":"file does not exist","notFound":true}`+"\n"+
+			`{"id":3,"error":"unsupported protocol \"fuda-resolver/v9\", want \"fuda-resolver/v1\""}`+"\n",
+		out.String())
+}
+
+func TestServe_InvalidRequest(t *testing.T) {
+	var out bytes.Buffer
+	err := Serve(t.Context(), testPlugin{}, strings.NewReader("not json\n"), &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "decode request")
+}
@@ -0,0 +1,57 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/arloliu/fuda"
+)
+
+// Middleware wraps a fuda.RefResolver to add a cross-cutting concern. It is
+// an alias of fuda.ResolverMiddleware, so functions in this package can be
+// passed directly to Builder.WithResolverMiddleware.
+type Middleware = fuda.ResolverMiddleware
+
+// Chain composes mws into a single middleware; the first is the outermost.
+//
+// Example:
+//
+//	observe := resolver.Chain(resolver.Logging(logger), metricsMiddleware)
+//	loader, _ := fuda.New().WithResolverMiddleware(observe).Build()
+func Chain(mws ...Middleware) Middleware {
+	return func(next fuda.RefResolver) fuda.RefResolver {
+		for _, mw := range slices.Backward(mws) {
+			next = mw(next)
+		}
+
+		return next
+	}
+}
+
+// Logging returns middleware that logs every resolution to logger with the
+// URI and duration: at debug level on success or when the reference is not
+// found, and at warn level on failure. Resolved content is never logged.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next fuda.RefResolver) fuda.RefResolver {
+		return fuda.RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+			start := time.Now()
+			data, err := next.Resolve(ctx, uri)
+			elapsed := time.Since(start)
+
+			switch {
+			case err == nil:
+				logger.DebugContext(ctx, "ref resolved", "uri", uri, "duration", elapsed, "bytes", len(data))
+			case errors.Is(err, os.ErrNotExist):
+				logger.DebugContext(ctx, "ref not found", "uri", uri, "duration", elapsed)
+			default:
+				logger.WarnContext(ctx, "ref resolution failed", "uri", uri, "duration", elapsed, "error", err)
+			}
+
+			return data, err
+		})
+	}
+}
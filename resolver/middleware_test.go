@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagging returns middleware that records its name before calling next.
+func tagging(name string, order *[]string) Middleware {
+	return func(next fuda.RefResolver) fuda.RefResolver {
+		return fuda.RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+			*order = append(*order, name)
+
+			return next.Resolve(ctx, uri)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	base := fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+		order = append(order, "base")
+
+		return []byte("ok"), nil
+	})
+
+	r := Chain(tagging("a", &order), tagging("b", &order))(base)
+	data, err := r.Resolve(t.Context(), "corp://x")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(data))
+	assert.Equal(t, []string{"a", "b", "base"}, order)
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	base := fuda.RefResolverFunc(func(_ context.Context, uri string) ([]byte, error) {
+		switch uri {
+		case "corp://ok":
+			return []byte("s3cret"), nil
+		case "corp://missing":
+			return nil, os.ErrNotExist
+		default:
+			return nil, errors.New("backend down")
+		}
+	})
+	r := Logging(logger)(base)
+
+	_, err := r.Resolve(t.Context(), "corp://ok")
+	require.NoError(t, err)
+	_, err = r.Resolve(t.Context(), "corp://missing")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = r.Resolve(t.Context(), "corp://broken")
+	require.Error(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `level=DEBUG msg="ref resolved" uri=corp://ok`)
+	assert.Contains(t, out, "bytes=6")
+	assert.Contains(t, out, `level=DEBUG msg="ref not found" uri=corp://missing`)
+	assert.Contains(t, out, `level=WARN msg="ref resolution failed" uri=corp://broken`)
+	assert.Contains(t, out, `error="backend down"`)
+	assert.NotContains(t, out, "s3cret")
+}
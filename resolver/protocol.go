@@ -0,0 +1,67 @@
+// Package resolver provides building blocks for fuda ref resolvers:
+// middleware for cross-cutting concerns, and a plugin protocol that lets
+// external executables implement resolvers, so proprietary secret stores can
+// be supported without compiling their clients into the application binary.
+//
+// # Middleware
+//
+// Middleware wraps every ref resolution of a loader:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithResolverMiddleware(resolver.Logging(slog.Default())).
+//	    Build()
+//
+// # Plugins
+//
+// Plugins speak the "fuda resolver plugin" protocol: newline-delimited JSON
+// over the plugin's stdin and stdout. The host writes one Request per line
+// and waits for the matching Response line before sending the next request:
+//
+//	-> {"protocol":"fuda-resolver/v1","id":1,"uri":"corp://db/password"}
+//	<- {"id":1,"data":"czNjcmV0"}
+//	-> {"protocol":"fuda-resolver/v1","id":2,"uri":"corp://missing"}
+//	<- {"id":2,"error":"no such secret","notFound":true}
+//
+// Data is base64 encoded (the JSON encoding of []byte). Plugins should log to
+// stderr, which is passed through to the host's stderr. The host starts the
+// plugin on first use and keeps it running until Close.
+//
+// Use Exec on the host side and Serve (or ServeStdio) to write a plugin in Go:
+//
+//	// host
+//	fuda.RegisterResolver("corp", resolver.Exec("corp-secrets-plugin"))
+//
+//	// plugin (corp-secrets-plugin/main.go)
+//	func main() {
+//	    if err := resolver.ServeStdio(corpResolver{}); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+package resolver
+
+// Protocol identifies the plugin protocol version sent with every request.
+const Protocol = "fuda-resolver/v1"
+
+// Request asks a plugin to resolve a single URI.
+type Request struct {
+	// Protocol is always the Protocol constant for this version.
+	Protocol string `json:"protocol"`
+	// ID correlates the request with its Response.
+	ID uint64 `json:"id"`
+	// URI is the full ref URI, including the scheme.
+	URI string `json:"uri"`
+}
+
+// Response carries the result of a Request.
+type Response struct {
+	// ID echoes the Request ID.
+	ID uint64 `json:"id"`
+	// Data is the resolved content.
+	Data []byte `json:"data,omitempty"`
+	// Error describes a failed resolution. Empty on success.
+	Error string `json:"error,omitempty"`
+	// NotFound reports that the referenced value does not exist, letting
+	// fuda fall back to defaults instead of failing the load.
+	NotFound bool `json:"notFound,omitempty"`
+}
@@ -0,0 +1,284 @@
+// Package resolvertest provides a conformance test suite for
+// fuda.RefResolver implementations, so authors of resolvers for secret
+// stores such as Vault, AWS Secrets Manager, or GCP Secret Manager can check
+// that theirs behaves as the loader expects.
+//
+// Basic usage:
+//
+//	func TestConformance(t *testing.T) {
+//	    resolvertest.Run(t, func(t *testing.T, values map[string][]byte) resolvertest.Fixture {
+//	        backend := newFakeBackend(t)
+//	        uris := make(map[string]string, len(values))
+//	        for name, value := range values {
+//	            backend.Put("app/"+name, value)
+//	            uris[name] = "corp://app/" + name
+//	        }
+//
+//	        return resolvertest.Fixture{
+//	            Resolver:   corp.NewResolver(backend.URL),
+//	            URIs:       uris,
+//	            MissingURI: "corp://app/missing",
+//	        }
+//	    })
+//	}
+//
+// Run the suite with -race, as one of its checks is concurrent use.
+package resolvertest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+)
+
+// LargeSize is the size in bytes of the "large" value, which checks that
+// payloads are not truncated.
+const LargeSize = 1 << 20
+
+// resolveTimeout bounds every Resolve call, so a resolver that ignores
+// cancellation or deadlocks fails the suite instead of hanging it.
+const resolveTimeout = 10 * time.Second
+
+// Concurrency of the concurrent use check.
+const (
+	goroutines = 8
+	iterations = 10
+)
+
+// Fixture is a resolver under test and the URIs of the values it was
+// seeded with.
+type Fixture struct {
+	// Resolver is the resolver under test.
+	Resolver fuda.RefResolver
+	// URIs maps the name of each seeded value to the URI resolving it.
+	URIs map[string]string
+	// MissingURI is a URI that does not resolve, for the not-found check.
+	// Leave it empty to skip the check, for backends that cannot tell a
+	// missing value from a failure.
+	MissingURI string
+}
+
+// NewResolverFunc returns a Fixture whose resolver resolves each of values,
+// by name, to its content. It is called once per check; use t.Cleanup to
+// release what it starts.
+type NewResolverFunc func(t *testing.T, values map[string][]byte) Fixture
+
+// Values returns the values the suite seeds: short, multi-line, and
+// non-ASCII text, and LargeSize bytes of text. All are valid UTF-8, so
+// backends that store strings can hold them.
+func Values() map[string][]byte {
+	return map[string][]byte{
+		"simple":    []byte("s3cr3t"),
+		"multiline": []byte("-----BEGIN KEY-----\nMIIB\n-----END KEY-----\n"),
+		"unicode":   []byte("pässwörd-密碼-✓"),
+		"large":     []byte(strings.Repeat("0123456789abcdef", LargeSize/16)),
+	}
+}
+
+// Run checks that the resolvers returned by newResolver follow the
+// fuda.RefResolver contract:
+//
+//   - Every seeded URI resolves to its exact content, including large
+//     payloads.
+//   - A URI that does not exist fails with an error wrapping os.ErrNotExist,
+//     which lets the loader fall back to the default tag.
+//   - A canceled context or an expired deadline fails the call with an error
+//     wrapping the context's error, without blocking.
+//   - Concurrent calls return the same results as sequential ones.
+//   - Callers may modify the returned content without affecting later
+//     calls.
+func Run(t *testing.T, newResolver NewResolverFunc) {
+	t.Helper()
+
+	t.Run("resolves values", func(t *testing.T) { testValues(t, setup(t, newResolver)) })
+	t.Run("missing value is ErrNotExist", func(t *testing.T) { testMissing(t, setup(t, newResolver)) })
+	t.Run("canceled context", func(t *testing.T) { testCanceled(t, setup(t, newResolver)) })
+	t.Run("expired deadline", func(t *testing.T) { testDeadline(t, setup(t, newResolver)) })
+	t.Run("concurrent use", func(t *testing.T) { testConcurrent(t, setup(t, newResolver)) })
+	t.Run("returned content is not shared", func(t *testing.T) { testNotShared(t, setup(t, newResolver)) })
+}
+
+// testValues checks that every seeded URI resolves to its exact content.
+func testValues(t *testing.T, f Fixture) {
+	for name, want := range Values() {
+		uri := f.URIs[name]
+		got, err := resolve(t, uri, func() ([]byte, error) { return f.Resolver.Resolve(t.Context(), uri) })
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", uri, err)
+
+			continue
+		}
+		checkContent(t, uri, got, want)
+	}
+}
+
+// testMissing checks that a missing value fails with os.ErrNotExist.
+func testMissing(t *testing.T, f Fixture) {
+	if f.MissingURI == "" {
+		t.Skip("Fixture.MissingURI is empty")
+	}
+
+	uri := f.MissingURI
+	data, err := resolve(t, uri, func() ([]byte, error) { return f.Resolver.Resolve(t.Context(), uri) })
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Resolve(%q) error = %v, want an error wrapping os.ErrNotExist", uri, err)
+	}
+	if data != nil {
+		t.Errorf("Resolve(%q) returned %d bytes with an error, want nil", uri, len(data))
+	}
+}
+
+// testCanceled checks that a canceled context fails the call.
+func testCanceled(t *testing.T, f Fixture) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	uri := f.URIs["simple"]
+	_, err := resolve(t, uri, func() ([]byte, error) { return f.Resolver.Resolve(ctx, uri) })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Resolve(%q) with a canceled context: error = %v, want an error wrapping context.Canceled", uri, err)
+	}
+}
+
+// testDeadline checks that an expired deadline fails the call.
+func testDeadline(t *testing.T, f Fixture) {
+	ctx, cancel := context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	uri := f.URIs["simple"]
+	_, err := resolve(t, uri, func() ([]byte, error) { return f.Resolver.Resolve(ctx, uri) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Resolve(%q) past the deadline: error = %v, want an error wrapping context.DeadlineExceeded", uri, err)
+	}
+}
+
+// testConcurrent checks that concurrent calls return consistent results.
+// The large value is left out, as it checks payload size rather than
+// concurrency and would make slow transports time out.
+func testConcurrent(t *testing.T, f Fixture) {
+	values := Values()
+	delete(values, "large")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*iterations*len(values))
+	for range goroutines {
+		wg.Go(func() {
+			for range iterations {
+				for name, want := range values {
+					got, err := f.Resolver.Resolve(t.Context(), f.URIs[name])
+					switch {
+					case err != nil:
+						errs <- err
+					case !bytes.Equal(got, want):
+						errs <- errors.New(f.URIs[name] + ": content differs from a sequential call")
+					}
+				}
+			}
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(resolveTimeout):
+		t.Fatal("concurrent Resolve calls did not return; possible deadlock")
+	}
+	close(errs)
+
+	if err, ok := <-errs; ok {
+		t.Errorf("concurrent Resolve: %v", err) // one is enough
+	}
+}
+
+// testNotShared checks that callers own the returned content.
+func testNotShared(t *testing.T, f Fixture) {
+	uri, want := f.URIs["simple"], Values()["simple"]
+	call := func() ([]byte, error) { return f.Resolver.Resolve(t.Context(), uri) }
+
+	first, err := resolve(t, uri, call)
+	if err != nil {
+		t.Fatalf("Resolve(%q): %v", uri, err)
+	}
+	for i := range first {
+		first[i] = 'x'
+	}
+
+	second, err := resolve(t, uri, call)
+	if err != nil {
+		t.Fatalf("Resolve(%q): %v", uri, err)
+	}
+	checkContent(t, uri+" after modifying an earlier result", second, want)
+}
+
+// setup creates a fixture and checks that it covers every value.
+func setup(t *testing.T, newResolver NewResolverFunc) Fixture {
+	t.Helper()
+
+	f := newResolver(t, Values())
+	if f.Resolver == nil {
+		t.Fatal("Fixture.Resolver is nil")
+	}
+	for name := range Values() {
+		if f.URIs[name] == "" {
+			t.Fatalf("Fixture.URIs has no URI for value %q", name)
+		}
+	}
+
+	return f
+}
+
+// resolve runs call, a Resolve call for uri, failing the test if it does
+// not return within resolveTimeout.
+func resolve(t *testing.T, uri string, call func() ([]byte, error)) ([]byte, error) {
+	t.Helper()
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		data, err := call()
+		ch <- result{data, err}
+	}()
+
+	select {
+	case res := <-ch:
+		return res.data, res.err
+	case <-time.After(resolveTimeout):
+		t.Fatalf("Resolve(%q) did not return within %s", uri, resolveTimeout)
+
+		return nil, nil
+	}
+}
+
+// checkContent reports a mismatch between got and want, without printing
+// large payloads.
+func checkContent(t *testing.T, uri string, got, want []byte) {
+	t.Helper()
+
+	if bytes.Equal(got, want) {
+		return
+	}
+	if len(got) != len(want) {
+		t.Errorf("Resolve(%q) returned %d bytes, want %d", uri, len(got), len(want))
+
+		return
+	}
+	i := 0
+	for got[i] == want[i] {
+		i++
+	}
+	t.Errorf("Resolve(%q) content differs at byte %d", uri, i)
+}
@@ -0,0 +1,60 @@
+package resolvertest_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/arloliu/fuda/resolver/resolvertest"
+)
+
+// memResolver is a conforming in-memory resolver for mem:// URIs.
+type memResolver struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+func (r *memResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.values[uri]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", uri, os.ErrNotExist)
+	}
+
+	return slices.Clone(value), nil
+}
+
+func TestRun(t *testing.T) {
+	resolvertest.Run(t, func(_ *testing.T, values map[string][]byte) resolvertest.Fixture {
+		r := &memResolver{values: make(map[string][]byte)}
+		uris := make(map[string]string, len(values))
+		for name, value := range values {
+			uris[name] = "mem://app/" + name
+			r.values[uris[name]] = value
+		}
+
+		return resolvertest.Fixture{Resolver: r, URIs: uris, MissingURI: "mem://app/missing"}
+	})
+}
+
+func TestValues(t *testing.T) {
+	values := resolvertest.Values()
+	if got := len(values["large"]); got != resolvertest.LargeSize {
+		t.Errorf("large value has %d bytes, want %d", got, resolvertest.LargeSize)
+	}
+
+	// Each call returns fresh slices, so fixtures may keep them
+	values["simple"][0] = 'x'
+	if resolvertest.Values()["simple"][0] == 'x' {
+		t.Error("Values shares its slices between calls")
+	}
+}
@@ -0,0 +1,56 @@
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/arloliu/fuda"
+)
+
+// maxRequestSize bounds a single host request line.
+const maxRequestSize = 1 << 20
+
+// ServeStdio runs r as a resolver plugin on the process's stdin and stdout
+// until stdin is closed. It is the entry point for plugins written in Go.
+func ServeStdio(r fuda.RefResolver) error {
+	return Serve(context.Background(), r, os.Stdin, os.Stdout)
+}
+
+// Serve answers plugin protocol requests read from in with responses written
+// to out, resolving each URI with r. It returns nil when in reaches EOF, or
+// an error if a request cannot be decoded or a response cannot be written.
+//
+// Errors wrapping os.ErrNotExist are reported with notFound set.
+func Serve(ctx context.Context, r fuda.RefResolver, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequestSize)
+	enc := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return fmt.Errorf("decode request: %w", err)
+		}
+
+		resp := Response{ID: req.ID}
+		if req.Protocol != Protocol {
+			resp.Error = fmt.Sprintf("unsupported protocol %q, want %q", req.Protocol, Protocol)
+		} else if data, err := r.Resolve(ctx, req.URI); err != nil {
+			resp.Error = err.Error()
+			resp.NotFound = errors.Is(err, os.ErrNotExist)
+		} else {
+			resp.Data = data
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,256 @@
+// Package schedule lets configuration sections carry validity windows, so
+// planned changes such as a maintenance mode or new prices can be committed
+// ahead of time and take effect on their own.
+//
+// A section opts in by embedding Window inline:
+//
+//	type Maintenance struct {
+//	    schedule.Window `yaml:",inline"`
+//	    Enabled bool    `yaml:"enabled"`
+//	    Message string  `yaml:"message"`
+//	}
+//
+//	type Price struct {
+//	    schedule.Window `yaml:",inline"`
+//	    Amount float64  `yaml:"amount"`
+//	}
+//
+//	type Config struct {
+//	    Maintenance *Maintenance `yaml:"maintenance"`
+//	    Prices      []Price      `yaml:"prices"`
+//	}
+//
+// with the windows set in the config file:
+//
+//	maintenance:
+//	  active_from: 2026-11-01T02:00:00Z
+//	  active_until: 2026-11-01T04:00:00Z
+//	  enabled: true
+//	prices:
+//	  - active_until: 2026-12-01T00:00:00Z
+//	    amount: 10
+//	  - active_from: 2026-12-01T00:00:00Z
+//	    amount: 12
+//
+// After loading, Apply removes the sections that are not in effect:
+//
+//	next := schedule.Apply(&cfg, time.Now())
+//
+// With watcher.Builder.WithSchedule, the watcher calls Apply on every load
+// and reloads at each boundary, so the effective values switch on time.
+package schedule
+
+import (
+	"reflect"
+	"time"
+)
+
+// Window is the period a config section is in effect, from ActiveFrom
+// (inclusive) until ActiveUntil (exclusive). A zero time leaves that side
+// open, so a zero Window is always active. Embed it inline in the section.
+type Window struct {
+	ActiveFrom  time.Time `yaml:"active_from" doc:"Start of the period the section is in effect (RFC 3339)"`
+	ActiveUntil time.Time `yaml:"active_until" doc:"End of the period the section is in effect (RFC 3339)"`
+}
+
+// windowed is implemented by sections embedding Window.
+type windowed interface {
+	window() Window
+}
+
+// window returns w; it is promoted to the sections embedding Window.
+func (w Window) window() Window {
+	return w
+}
+
+// Active reports whether the window includes now.
+func (w Window) Active(now time.Time) bool {
+	return (w.ActiveFrom.IsZero() || !now.Before(w.ActiveFrom)) &&
+		(w.ActiveUntil.IsZero() || now.Before(w.ActiveUntil))
+}
+
+// Next returns the first boundary of the window after now, or the zero
+// time if there is none.
+func (w Window) Next(now time.Time) time.Time {
+	var next time.Time
+	for _, t := range []time.Time{w.ActiveFrom, w.ActiveUntil} {
+		next = earliest(next, t, now)
+	}
+
+	return next
+}
+
+// Apply removes the sections of cfg, a pointer to a config struct, that
+// are not in effect at now: nil is stored in pointers and interfaces,
+// struct fields are zeroed, and elements are dropped from slices and maps.
+// Sections are found at any depth; a section removed with its parent is
+// not inspected.
+//
+// Apply returns the next time a window of cfg opens or closes, before
+// sections were removed, or the zero time if no window changes after now.
+// Call Apply on a freshly loaded config, since removed sections cannot be
+// restored.
+func Apply(cfg any, now time.Time) time.Time {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return time.Time{}
+	}
+
+	a := applier{now: now, visited: make(map[uintptr]bool)}
+	a.walk(v.Elem())
+
+	return a.next
+}
+
+// Current returns the first of sections that is in effect at now, such as
+// the current price of a list of scheduled prices, and whether there was one.
+func Current[T interface{ Active(time.Time) bool }](sections []T, now time.Time) (T, bool) {
+	for _, s := range sections {
+		if s.Active(now) {
+			return s, true
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// applier walks a config value for Apply.
+type applier struct {
+	now     time.Time
+	next    time.Time
+	visited map[uintptr]bool // pointers already walked, against cycles
+}
+
+// active records the boundaries of v if it is a section, and reports
+// whether v is in effect.
+func (a *applier) active(v reflect.Value) bool {
+	if !v.CanInterface() || (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	s, ok := v.Interface().(windowed)
+	if !ok {
+		return true
+	}
+
+	w := s.window()
+	a.next = earliest(a.next, w.Next(a.now), a.now)
+
+	return w.Active(a.now)
+}
+
+// walk removes the inactive sections below v, which must be settable or a
+// value whose elements are.
+func (a *applier) walk(v reflect.Value) {
+	//nolint:exhaustive // other kinds hold no sections
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || a.visited[v.Pointer()] {
+			return
+		}
+		a.visited[v.Pointer()] = true
+		a.walk(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// Interface values are not addressable, so walk a copy
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		a.walk(elem)
+		if v.CanSet() {
+			v.Set(elem)
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return
+		}
+		for i := range v.NumField() {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			a.walkField(field)
+		}
+	case reflect.Slice, reflect.Array:
+		a.walkElems(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if !a.active(elem) {
+				v.SetMapIndex(iter.Key(), reflect.Value{})
+
+				continue
+			}
+			a.walk(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
+
+// walkField removes field if it is an inactive section, or walks it.
+// Embedded Windows belong to the enclosing section and are skipped.
+func (a *applier) walkField(field reflect.Value) {
+	if field.Type() == windowType {
+		return
+	}
+	if !a.active(field) {
+		field.SetZero()
+
+		return
+	}
+	a.walk(field)
+}
+
+// walkElems drops the inactive sections from slice v, or zeroes them in an
+// array, and walks the others.
+func (a *applier) walkElems(v reflect.Value) {
+	kept := 0
+	for i := range v.Len() {
+		elem := v.Index(i)
+		if !a.active(elem) {
+			if v.Kind() == reflect.Array {
+				elem.SetZero()
+			}
+
+			continue
+		}
+		a.walk(elem)
+		if v.Kind() == reflect.Slice {
+			v.Index(kept).Set(elem)
+			kept++
+		}
+	}
+
+	if v.Kind() == reflect.Slice && kept < v.Len() {
+		// Clear the tail so dropped sections are not kept alive
+		for i := kept; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+		v.SetLen(kept)
+	}
+}
+
+// earliest returns the earlier of next and t, ignoring t if it is zero or
+// not after now. A zero next means no boundary was found yet.
+func earliest(next, t, now time.Time) time.Time {
+	if t.IsZero() || !t.After(now) {
+		return next
+	}
+	if next.IsZero() || t.Before(next) {
+		return t
+	}
+
+	return next
+}
+
+var (
+	timeType   = reflect.TypeFor[time.Time]()
+	windowType = reflect.TypeFor[Window]()
+)
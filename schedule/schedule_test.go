@@ -0,0 +1,145 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Maintenance struct {
+	schedule.Window `yaml:",inline"`
+	Enabled         bool   `yaml:"enabled"`
+	Message         string `yaml:"message"`
+}
+
+type Price struct {
+	schedule.Window `yaml:",inline"`
+	Amount          float64 `yaml:"amount"`
+}
+
+type Banner struct {
+	schedule.Window `yaml:",inline"`
+	Text            string `yaml:"text"`
+}
+
+type Config struct {
+	Name        string            `yaml:"name"`
+	Maintenance *Maintenance      `yaml:"maintenance"`
+	Banner      Banner            `yaml:"banner"`
+	Prices      []Price           `yaml:"prices"`
+	Regions     map[string]Banner `yaml:"regions"`
+}
+
+const scheduled = `
+name: shop
+maintenance:
+  active_from: 2026-11-01T02:00:00Z
+  active_until: 2026-11-01T04:00:00Z
+  enabled: true
+  message: upgrading
+banner:
+  active_until: 2026-10-20T00:00:00Z
+  text: sale
+prices:
+  - active_until: 2026-12-01T00:00:00Z
+    amount: 10
+  - active_from: 2026-12-01T00:00:00Z
+    amount: 12
+regions:
+  eu:
+    active_from: 2027-01-01T00:00:00Z
+    text: hello eu
+  us:
+    text: hello us
+`
+
+func load(t *testing.T) Config {
+	t.Helper()
+
+	loader, err := fuda.New().FromBytes([]byte(scheduled)).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	return cfg
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestApply(t *testing.T) {
+	t.Run("before maintenance", func(t *testing.T) {
+		cfg := load(t)
+		next := schedule.Apply(&cfg, date("2026-10-17T00:00:00Z"))
+
+		assert.Equal(t, date("2026-10-20T00:00:00Z"), next)
+		assert.Equal(t, "shop", cfg.Name)
+		assert.Nil(t, cfg.Maintenance)
+		assert.Equal(t, "sale", cfg.Banner.Text)
+		require.Len(t, cfg.Prices, 1)
+		assert.InDelta(t, 10.0, cfg.Prices[0].Amount, 0)
+		assert.Equal(t, map[string]Banner{"us": {Text: "hello us"}}, cfg.Regions)
+	})
+
+	t.Run("during maintenance", func(t *testing.T) {
+		cfg := load(t)
+		next := schedule.Apply(&cfg, date("2026-11-01T02:00:00Z"))
+
+		assert.Equal(t, date("2026-11-01T04:00:00Z"), next)
+		require.NotNil(t, cfg.Maintenance)
+		assert.True(t, cfg.Maintenance.Enabled)
+		assert.Equal(t, Banner{}, cfg.Banner, "expired struct sections are zeroed")
+	})
+
+	t.Run("after all boundaries", func(t *testing.T) {
+		cfg := load(t)
+		next := schedule.Apply(&cfg, date("2027-06-01T00:00:00Z"))
+
+		assert.True(t, next.IsZero())
+		assert.Nil(t, cfg.Maintenance)
+		require.Len(t, cfg.Prices, 1)
+		assert.InDelta(t, 12.0, cfg.Prices[0].Amount, 0)
+		assert.Len(t, cfg.Regions, 2)
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		cfg := load(t)
+		assert.True(t, schedule.Apply(cfg, date("2026-10-17T00:00:00Z")).IsZero())
+		assert.NotNil(t, cfg.Maintenance)
+	})
+}
+
+func TestWindow(t *testing.T) {
+	w := schedule.Window{ActiveFrom: date("2026-11-01T02:00:00Z"), ActiveUntil: date("2026-11-01T04:00:00Z")}
+
+	assert.False(t, w.Active(date("2026-11-01T01:59:59Z")))
+	assert.True(t, w.Active(date("2026-11-01T02:00:00Z")), "ActiveFrom is inclusive")
+	assert.False(t, w.Active(date("2026-11-01T04:00:00Z")), "ActiveUntil is exclusive")
+	assert.True(t, schedule.Window{}.Active(time.Now()))
+
+	assert.Equal(t, date("2026-11-01T02:00:00Z"), w.Next(date("2026-10-01T00:00:00Z")))
+	assert.Equal(t, date("2026-11-01T04:00:00Z"), w.Next(date("2026-11-01T02:00:00Z")))
+	assert.True(t, w.Next(date("2026-11-01T04:00:00Z")).IsZero())
+}
+
+func TestCurrent(t *testing.T) {
+	prices := load(t).Prices
+
+	p, ok := schedule.Current(prices, date("2026-12-24T00:00:00Z"))
+	require.True(t, ok)
+	assert.InDelta(t, 12.0, p.Amount, 0)
+
+	_, ok = schedule.Current(prices[:1], date("2026-12-24T00:00:00Z"))
+	assert.False(t, ok)
+}
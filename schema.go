@@ -0,0 +1,323 @@
+package fuda
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/tags"
+	"github.com/arloliu/fuda/internal/types"
+)
+
+// schemaDraft is the JSON Schema dialect emitted by Schema.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	fudaDurationType = reflect.TypeFor[Duration]()
+	byteSizeType     = reflect.TypeFor[ByteSize]()
+	scannerType      = reflect.TypeFor[types.Scanner]()
+)
+
+// Schema returns a JSON Schema (draft 2020-12) describing the config file for
+// target, a struct or pointer to struct. Use it for editor autocomplete
+// (e.g., the YAML language server) and to validate config files in CI.
+//
+// The schema is derived from struct tags:
+//   - yaml: property names (inline structs are flattened)
+//   - default: "default" values
+//   - doc: "description"
+//   - validate: required, min/max/gt/gte/lt/lte/len (lengths for strings and
+//     slices, bounds for numbers), oneof (enum), and email/url/uri/hostname/
+//     ipv4/ipv6/uuid (format)
+//
+// A field is listed as required only if validate includes "required" and it
+// has no default, env, ref, refFrom, dsn, or expr tag, since those can supply
+// the value when the file omits it. time.Duration, Duration, and ByteSize
+// accept both strings ("30s", "10MiB") and integers.
+//
+// Example:
+//
+//	schema, err := fuda.Schema(&Config{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	os.WriteFile("config.schema.json", schema, 0o644)
+func Schema(target any) ([]byte, error) {
+	root, err := schemaDocument(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaDocument returns the root schema of target before encoding.
+func schemaDocument(target any) (map[string]any, error) {
+	t := reflect.TypeOf(target)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, &FieldError{Message: "schema target must be a struct or pointer to struct"}
+	}
+
+	root := typeSchema(t, map[reflect.Type]bool{})
+	root["$schema"] = schemaDraft
+	if t.Name() != "" {
+		root["title"] = t.Name()
+	}
+
+	return root, nil
+}
+
+// typeSchema returns the schema for values of type t. seen guards against
+// recursive struct types.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case durationType, fudaDurationType, byteSizeType:
+		return map[string]any{"type": []string{"string", "integer"}}
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	if reflect.PointerTo(t).Implements(decimalType) {
+		return map[string]any{"type": []string{"string", "number"}}
+	}
+	if isOrderedMapType(t) {
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem().Field(1).Type, seen)}
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return map[string]any{} // custom conversion; accept anything
+	}
+
+	//nolint:exhaustive // remaining kinds accept any value
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]any{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := map[string]any{"type": "object"}
+		properties := map[string]any{}
+		var required []string
+		addStructProperties(t, seen, properties, &required)
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
+
+// addStructProperties adds the properties of struct type t, flattening
+// inline fields into the same property set.
+func addStructProperties(t reflect.Type, seen map[reflect.Type]bool, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, inline, skip := yamlFieldName(field)
+		if skip || !dumpable(field.Type) {
+			continue
+		}
+		if !field.IsExported() && !(field.Anonymous && inline) {
+			continue
+		}
+
+		if inline {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructProperties(ft, seen, properties, required)
+
+				continue
+			}
+		}
+
+		prop := typeSchema(field.Type, seen)
+		if doc := tags.Get(field, "doc"); doc != "" {
+			prop["description"] = doc
+		}
+		if def := tags.Get(field, "default"); def != "" && def != "-" {
+			prop["default"] = schemaValue(def, field.Type)
+		}
+
+		if applyValidateRules(prop, field) && !hasAlternateSource(field) {
+			*required = append(*required, name)
+		}
+
+		properties[name] = prop
+	}
+}
+
+// applyValidateRules translates the field's validate tag into schema keywords
+// and reports whether the field is required. Rules after "dive" apply to
+// elements and are ignored, as are rules with no schema equivalent.
+func applyValidateRules(prop map[string]any, field reflect.StructField) bool {
+	tag := tags.Get(field, "validate")
+	if tag == "" {
+		return false
+	}
+
+	minKey, maxKey, exMinKey, exMaxKey := schemaBoundKeys(field.Type)
+	bound := func(key, param string) {
+		if key == "" {
+			return
+		}
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			prop[key] = jsonNumber(n)
+		}
+	}
+
+	required := false
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "min", "gte":
+			bound(minKey, param)
+		case "max", "lte":
+			bound(maxKey, param)
+		case "gt":
+			bound(exMinKey, param)
+		case "lt":
+			bound(exMaxKey, param)
+		case "len":
+			bound(minKey, param)
+			bound(maxKey, param)
+		case "oneof":
+			var enum []any
+			for v := range strings.FieldsSeq(param) {
+				enum = append(enum, schemaValue(v, field.Type))
+			}
+			prop["enum"] = enum
+		case "email", "hostname", "ipv4", "ipv6", "uuid":
+			prop["format"] = name
+		case "url", "uri":
+			prop["format"] = "uri"
+		}
+	}
+
+	return required
+}
+
+// schemaBoundKeys returns the keywords the min/max and gt/lt rules map to
+// for values of type t: lengths for strings and collections, including
+// ordered maps, and bounds for numbers.
+func schemaBoundKeys(t reflect.Type) (minKey, maxKey, exMinKey, exMaxKey string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Duration-like types are strings in the file, so numeric bounds don't apply.
+	switch t {
+	case durationType, fudaDurationType, byteSizeType:
+		return "", "", "", ""
+	}
+	if isOrderedMapType(t) {
+		return "minProperties", "maxProperties", "", ""
+	}
+
+	switch t.Kind() { //nolint:exhaustive // only sized and numeric kinds have bounds
+	case reflect.String:
+		return "minLength", "maxLength", "", ""
+	case reflect.Slice, reflect.Array:
+		return "minItems", "maxItems", "", ""
+	case reflect.Map:
+		return "minProperties", "maxProperties", "", ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"
+	default:
+		return "", "", "", ""
+	}
+}
+
+// hasAlternateSource reports whether a field can be populated from something
+// other than the config file.
+func hasAlternateSource(field reflect.StructField) bool {
+	for _, key := range []string{"default", "env", "ref", "refFrom", "secretName", "dsn", "expr"} {
+		if v := tags.Get(field, key); v != "" && v != "-" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schemaValue converts a tag value to the JSON value matching the field type,
+// falling back to the raw string for types written as strings in the file.
+func schemaValue(raw string, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case durationType, fudaDurationType, byteSizeType, timeType:
+		return raw
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return raw
+	}
+
+	v := reflect.New(t).Elem()
+	if err := types.Convert(raw, v); err != nil {
+		return raw
+	}
+
+	// A JSON literal keeps its keys, which are the yaml names of struct
+	// fields rather than the Go names
+	switch t.Kind() { //nolint:exhaustive // only collections and structs take JSON
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		if trimmed := strings.TrimSpace(raw); strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+			var doc any
+			if json.Unmarshal([]byte(trimmed), &doc) == nil {
+				return doc
+			}
+		}
+	}
+
+	return v.Interface()
+}
+
+// jsonNumber returns n as an int when it has no fractional part, so bounds
+// render as 1 rather than 1.0 in tools that distinguish them.
+func jsonNumber(n float64) any {
+	if n == float64(int64(n)) {
+		return int64(n)
+	}
+
+	return n
+}
@@ -0,0 +1,133 @@
+package fuda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// sections holds the targets registered via RegisterSection, keyed by
+// section.
+var sections = struct {
+	mu      sync.RWMutex
+	targets map[string]any
+}{targets: make(map[string]any)}
+
+// LoadSection is like Load, but populates target from the subtree of the
+// source document at section only, so a library can load just its part of
+// a large shared config file into a smaller struct:
+//
+//	# config.yaml
+//	server:
+//	  port: 8080
+//	database:
+//	  host: db.internal
+//	  pool:
+//	    size: 10
+//
+//	var dbCfg DatabaseConfig
+//	err := loader.LoadSection("database", &dbCfg)
+//
+// section is a dot-separated path of keys, such as "database.pool". Keys
+// outside the section are ignored, including with WithStrictKeys. If the
+// document does not set section, target is populated from env vars, refs,
+// and defaults alone; a non-mapping value on the path is an error. Keys of
+// WithOverrides stay relative to the whole document, such as
+// "database.host". Env, ref, and default tags, validation, and hooks apply
+// to target as with Load. LoadSection is equivalent to LoadSectionContext
+// with context.Background().
+func (l *Loader) LoadSection(section string, target any) error {
+	return l.LoadSectionContext(context.Background(), section, target)
+}
+
+// LoadSectionContext is like LoadSection, aborting when ctx is canceled or
+// its deadline passes, as with LoadContext.
+func (l *Loader) LoadSectionContext(ctx context.Context, section string, target any) error {
+	if section == "" {
+		return &FieldError{Message: "empty section"}
+	}
+
+	return l.load(ctx, target, section, nil, nil)
+}
+
+// RegisterSection registers target, a pointer to a module's config struct,
+// as the destination of section in the shared config file, for LoadAll. It
+// lets plugin-style applications assemble their config from modules that
+// own their config types:
+//
+//	// package kafka
+//	var Config KafkaConfig
+//
+//	func init() {
+//	    fuda.RegisterSection("kafka", &Config)
+//	}
+//
+//	// package main
+//	if err := loader.LoadAll(); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// section is a dot-separated path of keys, as for LoadSection.
+// RegisterSection is typically called from init. It panics if section is
+// empty or already registered, or if target is not a non-nil pointer.
+func RegisterSection(section string, target any) {
+	if section == "" {
+		panic("fuda: RegisterSection called with empty section")
+	}
+	targetVal := reflect.ValueOf(target)
+	if targetVal.Kind() != reflect.Pointer || targetVal.IsNil() {
+		panic("fuda: RegisterSection called with non-pointer target for section " + section)
+	}
+
+	sections.mu.Lock()
+	defer sections.mu.Unlock()
+
+	if _, ok := sections.targets[section]; ok {
+		panic("fuda: RegisterSection called twice for section " + section)
+	}
+	sections.targets[section] = target
+}
+
+// UnregisterSection removes the target registered for section.
+func UnregisterSection(section string) {
+	sections.mu.Lock()
+	defer sections.mu.Unlock()
+
+	delete(sections.targets, section)
+}
+
+// LoadAll populates every target registered via RegisterSection from its
+// section of the document. It is equivalent to LoadAllContext with
+// context.Background().
+func (l *Loader) LoadAll() error {
+	return l.LoadAllContext(context.Background())
+}
+
+// LoadAllContext populates every target registered via RegisterSection from
+// its section of the document (see LoadSectionContext), in order of section.
+// Each section loads into a new value, which replaces its target only if
+// that section loads, so a failing section keeps its current config while
+// the others are still loaded. The errors of all failing sections are
+// returned together, each prefixed with its section.
+func (l *Loader) LoadAllContext(ctx context.Context) error {
+	sections.mu.RLock()
+	targets := maps.Clone(sections.targets)
+	sections.mu.RUnlock()
+
+	var errs []error
+	for _, section := range slices.Sorted(maps.Keys(targets)) {
+		targetVal := reflect.ValueOf(targets[section])
+		fresh := reflect.New(targetVal.Elem().Type())
+		if err := l.LoadSectionContext(ctx, section, fresh.Interface()); err != nil {
+			errs = append(errs, fmt.Errorf("section %s: %w", section, err))
+			continue
+		}
+		targetVal.Elem().Set(fresh.Elem())
+	}
+
+	return errors.Join(errs...)
+}
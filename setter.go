@@ -17,3 +17,24 @@ import "github.com/arloliu/fuda/internal/types"
 //	    }
 //	}
 type Setter = types.Setter
+
+// Validator is implemented by config structs with rules across fields that
+// validate tags cannot express. Validate is called after tag validation,
+// nested structs first; its errors are merged into the ValidationError.
+// A returned FieldError's Path is relative to the struct, and each error of
+// an errors.Join is reported on its own.
+//
+// Example:
+//
+//	type TLS struct {
+//	    Enabled  bool   `yaml:"enabled"`
+//	    CertFile string `yaml:"cert_file"`
+//	}
+//
+//	func (t *TLS) Validate() error {
+//	    if t.Enabled && t.CertFile == "" {
+//	        return &fuda.FieldError{Path: "CertFile", Message: "required when TLS is enabled"}
+//	    }
+//	    return nil
+//	}
+type Validator = types.Validator
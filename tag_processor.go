@@ -0,0 +1,96 @@
+package fuda
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/arloliu/fuda/internal/loader"
+	"github.com/arloliu/fuda/internal/tags"
+)
+
+// TagProcessor implements a custom struct tag registered with
+// RegisterTagProcessor. It is called once for every field carrying the tag,
+// after the field's env, file, ref, and default sources are applied and
+// before its dsn and expr tags, and may set f.Value. A returned error fails
+// the load as a FieldError for the tag; return a FieldError without a Path
+// to set the Source of the failing value.
+//
+// Processors are called during Load and must be safe for concurrent use if
+// loaders are used from several goroutines.
+type TagProcessor = loader.TagProcessor
+
+// TagField is the field a TagProcessor is called for: its path, the value
+// of the custom tag, the settable field value, the enclosing struct, and
+// the loader's ref resolver.
+type TagField = loader.TagField
+
+// tagProcessors holds processors registered via RegisterTagProcessor, keyed
+// by tag name.
+var tagProcessors = struct {
+	mu         sync.RWMutex
+	processors map[string]TagProcessor
+}{processors: make(map[string]TagProcessor)}
+
+// RegisterTagProcessor registers fn as the processor of the struct tag name
+// (e.g., "consul" for `consul:"..."`) in every Loader built afterwards.
+// Registering a name again replaces its processor. Fields with several
+// custom tags run their processors in order of tag name.
+//
+// Custom tags are separate struct tags; they cannot be given inside a
+// 'fuda' tag.
+//
+// RegisterTagProcessor is typically called from init or main before any
+// loader is built. It panics if name is empty or a built-in tag such as
+// "env" or "yaml", or if fn is nil.
+//
+// Example:
+//
+//	fuda.RegisterTagProcessor("consul", func(ctx context.Context, f fuda.TagField) error {
+//	    if !f.Value.IsZero() {
+//	        return nil // keep values from the file, env, or defaults
+//	    }
+//	    data, err := f.Resolver.Resolve(ctx, "consul:///"+f.Tag)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    f.Value.SetString(string(data))
+//
+//	    return nil
+//	})
+func RegisterTagProcessor(name string, fn TagProcessor) {
+	if name == "" {
+		panic("fuda: RegisterTagProcessor called with empty tag name")
+	}
+	if tags.IsBuiltin(name) {
+		panic("fuda: RegisterTagProcessor called with built-in tag " + name)
+	}
+	if fn == nil {
+		panic("fuda: RegisterTagProcessor called with nil processor for tag " + name)
+	}
+
+	tagProcessors.mu.Lock()
+	defer tagProcessors.mu.Unlock()
+
+	tagProcessors.processors[name] = fn
+}
+
+// UnregisterTagProcessor removes the processor registered for the tag name.
+// Loaders built before keep using it.
+func UnregisterTagProcessor(name string) {
+	tagProcessors.mu.Lock()
+	defer tagProcessors.mu.Unlock()
+
+	delete(tagProcessors.processors, name)
+}
+
+// registeredTagProcessors returns a snapshot of the registered processors.
+func registeredTagProcessors() map[string]TagProcessor {
+	tagProcessors.mu.RLock()
+	defer tagProcessors.mu.RUnlock()
+
+	if len(tagProcessors.processors) == 0 {
+		return nil
+	}
+
+	return maps.Clone(tagProcessors.processors)
+}
@@ -0,0 +1,164 @@
+//go:build !fuda_minimal
+
+package fuda
+
+import (
+	"fmt"
+	"maps"
+	"text/template"
+
+	"github.com/arloliu/fuda/internal/loader"
+)
+
+// templateConfig holds template parsing configuration.
+type templateConfig struct {
+	leftDelim  string
+	rightDelim string
+	missingKey string
+	funcMap    template.FuncMap
+}
+
+// engineConfig returns c for the loader engine, or nil if c is nil.
+func (c *templateConfig) engineConfig() *loader.TemplateConfig {
+	if c == nil {
+		return nil
+	}
+
+	return &loader.TemplateConfig{
+		LeftDelim:  c.leftDelim,
+		RightDelim: c.rightDelim,
+		MissingKey: c.missingKey,
+		FuncMap:    c.funcMap,
+	}
+}
+
+// TemplateOption configures template parsing behavior.
+type TemplateOption func(*templateConfig)
+
+// WithDelimiters sets custom delimiters for template parsing.
+// Use this when your configuration contains literal "{{" sequences that
+// should not be interpreted as template syntax.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithTemplate(data, fuda.WithDelimiters("<{", "}>")).
+//	    Build()
+func WithDelimiters(left, right string) TemplateOption {
+	return func(c *templateConfig) {
+		c.leftDelim = left
+		c.rightDelim = right
+	}
+}
+
+// WithMissingKey controls behavior when a map is indexed with a key not in the map.
+// Valid values:
+//   - "invalid" (default): no error, outputs "<no value>"
+//   - "zero": outputs the zero value for the type
+//   - "error": template execution stops with an error
+func WithMissingKey(behavior string) TemplateOption {
+	return func(c *templateConfig) {
+		c.missingKey = behavior
+	}
+}
+
+// WithFuncs adds custom template functions.
+// These are merged with the template's built-in functions.
+func WithFuncs(funcMap template.FuncMap) TemplateOption {
+	return func(c *templateConfig) {
+		c.funcMap = funcMap
+	}
+}
+
+// WithTemplate enables Go template processing on configuration content before YAML parsing.
+// The data parameter provides template context, and opts configure template behavior.
+//
+// Template processing occurs BEFORE YAML unmarshaling. If your configuration contains
+// literal "{{" or "}}" sequences that should not be interpreted as template delimiters,
+// use WithDelimiters to specify alternative delimiters.
+//
+// Example:
+//
+//	type TemplateData struct {
+//	    Host string
+//	    Port int
+//	}
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithTemplate(TemplateData{Host: "localhost", Port: 8080}).
+//	    Build()
+func (b *Builder) WithTemplate(data any, opts ...TemplateOption) *Builder {
+	cfg := &templateConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	b.config.tmplConfig = cfg
+	b.config.tmplData = data
+
+	return b
+}
+
+// WithTemplate returns an option that enables template processing.
+// See Builder.WithTemplate.
+func WithTemplate(data any, opts ...TemplateOption) LoaderOption {
+	return func(b *Builder) { b.WithTemplate(data, opts...) }
+}
+
+// WithTagTemplateFuncs adds functions to the ${...} templates of ref and dsn
+// tags, next to ref, env, urlquery, and the built-in library (upper, lower,
+// trimSuffix, join, b64enc, sha256, default). A function named like a
+// library function replaces it; ref, env, and urlquery cannot be replaced.
+// Repeated calls add to the previous functions.
+//
+// Unlike WithFuncs, which applies to the config file template of
+// WithTemplate, these functions are called while tags are processed.
+//
+// Example:
+//
+//	loader, _ := fuda.New().
+//	    FromFile("config.yaml").
+//	    WithTagTemplateFuncs(template.FuncMap{
+//	        "region": func() string { return os.Getenv("AWS_REGION") },
+//	    }).
+//	    Build()
+//
+//	// ref:"vault:///secret/${region}/db#password"
+func (b *Builder) WithTagTemplateFuncs(funcMap template.FuncMap) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if err := checkFuncMap(funcMap); err != nil {
+		b.err = err
+
+		return b
+	}
+
+	if b.config.tagFuncs == nil {
+		b.config.tagFuncs = make(map[string]any, len(funcMap))
+	}
+	maps.Copy(b.config.tagFuncs, funcMap)
+
+	return b
+}
+
+// checkFuncMap reports a function map that text/template would reject with
+// a panic at load time, such as a value that is not a function.
+func checkFuncMap(funcMap template.FuncMap) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid tag template function: %v", r)
+		}
+	}()
+	template.New("").Funcs(funcMap)
+
+	return nil
+}
+
+// WithTagTemplateFuncs returns an option that adds functions to the
+// templates of ref and dsn tags. See Builder.WithTagTemplateFuncs.
+func WithTagTemplateFuncs(funcMap template.FuncMap) LoaderOption {
+	return func(b *Builder) { b.WithTagTemplateFuncs(funcMap) }
+}
@@ -0,0 +1,13 @@
+//go:build fuda_minimal
+
+package fuda
+
+import "github.com/arloliu/fuda/internal/loader"
+
+// templateConfig is empty: fuda_minimal builds have no template support.
+type templateConfig struct{}
+
+// engineConfig returns nil: fuda_minimal builds have no template support.
+func (*templateConfig) engineConfig() *loader.TemplateConfig {
+	return nil
+}
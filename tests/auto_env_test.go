@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type autoEnvPrimary struct {
+	Host     string        `yaml:"host" default:"localhost"`
+	Port     int           `yaml:"port"`
+	MaxConns int           `yaml:"max_conns"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+type autoEnvDatabase struct {
+	Primary  autoEnvPrimary   `yaml:"primary"`
+	Replica  *autoEnvPrimary  `yaml:"replica"`
+	Replicas []autoEnvPrimary `yaml:"replicas"`
+}
+
+type autoEnvConfig struct {
+	Name     string          `yaml:"name"`
+	Tags     []string        `yaml:"tags"`
+	Secret   string          `yaml:"secret" env:"-"`
+	Region   string          `yaml:"region" env:"AWS_REGION"`
+	Database autoEnvDatabase `yaml:"database"`
+}
+
+func TestWithAutoEnv(t *testing.T) {
+	t.Setenv("AE_NAME", "from-env")
+	t.Setenv("AE_TAGS", "a,b")
+	t.Setenv("AE_SECRET", "ignored")
+	t.Setenv("AE_REGION", "ignored")
+	t.Setenv("AE_AWS_REGION", "us-east-1")
+	t.Setenv("AE_DATABASE_PRIMARY_PORT", "6543")
+	t.Setenv("AE_DATABASE_PRIMARY_MAX_CONNS", "50")
+	t.Setenv("AE_DATABASE_PRIMARY_TIMEOUT", "5s")
+	t.Setenv("AE_DATABASE_REPLICA_HOST", "replica.example.com")
+	t.Setenv("AE_DATABASE_REPLICAS_HOST", "ignored")
+
+	yamlContent := `
+name: from-file
+secret: file-secret
+database:
+  primary:
+    port: 5432
+  replica:
+    port: 5433
+  replicas:
+    - port: 5434
+`
+	loader, err := fuda.New().
+		FromBytes([]byte(yamlContent)).
+		WithEnvPrefix("AE_").
+		WithAutoEnv().
+		Build()
+	require.NoError(t, err)
+
+	var cfg autoEnvConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "from-env", cfg.Name)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, "file-secret", cfg.Secret, "env:\"-\" opts out")
+	assert.Equal(t, "us-east-1", cfg.Region, "env tag takes precedence")
+	assert.Equal(t, "localhost", cfg.Database.Primary.Host, "default still applies")
+	assert.Equal(t, 6543, cfg.Database.Primary.Port)
+	assert.Equal(t, 50, cfg.Database.Primary.MaxConns)
+	assert.Equal(t, 5*time.Second, cfg.Database.Primary.Timeout)
+	require.NotNil(t, cfg.Database.Replica)
+	assert.Equal(t, "replica.example.com", cfg.Database.Replica.Host)
+	require.Len(t, cfg.Database.Replicas, 1)
+	assert.Equal(t, "localhost", cfg.Database.Replicas[0].Host, "slice elements are mapped by index only")
+}
+
+type autoEnvServer struct {
+	Host string   `yaml:"host"`
+	Port int      `yaml:"port" default:"80"`
+	Tags []string `yaml:"tags"`
+}
+
+type autoEnvCluster struct {
+	Servers  []autoEnvServer            `yaml:"servers"`
+	Backups  []*autoEnvServer           `yaml:"backups"`
+	Pinned   []autoEnvServer            `yaml:"pinned" env:"PINNED"`
+	ByRegion map[string][]autoEnvServer `yaml:"by_region"`
+}
+
+func TestWithAutoEnv_IndexedSlices(t *testing.T) {
+	t.Setenv("AEX_SERVERS_0_PORT", "8080")
+	t.Setenv("AEX_SERVERS_2_HOST", "c.example.com")
+	t.Setenv("AEX_SERVERS_2_TAGS", "x,y")
+	t.Setenv("AEX_BACKUPS_0_HOST", "backup.example.com")
+	t.Setenv("AEX_PINNED_0_HOST", "ignored")
+	t.Setenv("AEX_BY_REGION_EU_0_HOST", "ignored")
+
+	yamlContent := `
+servers:
+  - host: a.example.com
+  - host: b.example.com
+    port: 9090
+by_region:
+  eu:
+    - host: eu.example.com
+`
+	loader, err := fuda.New().
+		FromBytes([]byte(yamlContent)).
+		WithEnvPrefix("AEX_").
+		WithAutoEnv().
+		Build()
+	require.NoError(t, err)
+
+	var cfg autoEnvCluster
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, []autoEnvServer{
+		{Host: "a.example.com", Port: 8080},
+		{Host: "b.example.com", Port: 9090},
+		{Host: "c.example.com", Port: 80, Tags: []string{"x", "y"}}, // created, with defaults
+	}, cfg.Servers)
+	require.Len(t, cfg.Backups, 1)
+	assert.Equal(t, autoEnvServer{Host: "backup.example.com", Port: 80}, *cfg.Backups[0])
+	assert.Empty(t, cfg.Pinned, "a tagged slice reads its own variable")
+	require.Len(t, cfg.ByRegion["eu"], 1, "map values are not mapped")
+	assert.Equal(t, "eu.example.com", cfg.ByRegion["eu"][0].Host)
+}
+
+func TestWithAutoEnv_IndexTooLarge(t *testing.T) {
+	t.Setenv("AEL_SERVERS_100000_HOST", "x")
+
+	loader, err := fuda.New().WithEnvPrefix("AEL_").WithAutoEnv().Build()
+	require.NoError(t, err)
+
+	var cfg autoEnvCluster
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AEL_SERVERS_100000_HOST")
+}
+
+func TestWithAutoEnv_Disabled(t *testing.T) {
+	t.Setenv("NAME", "from-env")
+
+	loader, err := fuda.New().FromBytes([]byte("name: from-file\n")).Build()
+	require.NoError(t, err)
+
+	var cfg autoEnvConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "from-file", cfg.Name)
+}
+
+func TestWithAutoEnv_Option(t *testing.T) {
+	t.Setenv("AEO_DATABASE_PRIMARY_HOST", "db.example.com")
+
+	loader, err := fuda.NewLoader(fuda.WithEnvPrefix("AEO_"), fuda.WithAutoEnv())
+	require.NoError(t, err)
+
+	var cfg autoEnvConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "db.example.com", cfg.Database.Primary.Host)
+}
+
+func TestWithAutoEnv_Trace(t *testing.T) {
+	t.Setenv("AET_NAME", "from-env")
+
+	var out strings.Builder
+	loader, err := fuda.New().WithEnvPrefix("AET_").WithAutoEnv().WithTrace(&out).Build()
+	require.NoError(t, err)
+
+	var cfg autoEnvConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Contains(t, out.String(), "Name: yaml unset, env AET_NAME=from-env (used)")
+	assert.Contains(t, out.String(), "Database.Primary.Port: yaml unset, env AET_DATABASE_PRIMARY_PORT unset")
+}
+
+func TestWithAutoEnv_InvalidValue(t *testing.T) {
+	t.Setenv("AEI_DATABASE_PRIMARY_PORT", "not-a-number")
+
+	loader, err := fuda.New().WithEnvPrefix("AEI_").WithAutoEnv().Build()
+	require.NoError(t, err)
+
+	var cfg autoEnvConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Port")
+}
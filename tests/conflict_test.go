@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConflictReport_Files(t *testing.T) {
+	var got []fuda.Conflict
+	loader, err := fuda.New().
+		WithFilesystem(layeredFs(t)).
+		FromFiles("/base.yaml", "/prod.yaml", "/secrets.yaml").
+		WithConflictReport(func(c []fuda.Conflict) { got = c }).
+		Build()
+	require.NoError(t, err)
+
+	var cfg layeredConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	require.Len(t, got, 2)
+	assert.Equal(t, fuda.Conflict{
+		Key:      "database.host",
+		Winner:   fuda.ConflictValue{Source: "/prod.yaml", Value: "db.prod.internal"},
+		Shadowed: []fuda.ConflictValue{{Source: "/base.yaml", Value: "localhost"}},
+	}, got[0])
+	assert.Equal(t, `tags: "[\"prod\",\"eu\"]" from /prod.yaml shadows "[\"base\"]" from /base.yaml`, got[1].String())
+}
+
+func TestWithConflictReport_OverridesOnly(t *testing.T) {
+	var got []fuda.Conflict
+	loader, err := fuda.NewLoader(
+		fuda.WithConflictReport(func(c []fuda.Conflict) { got = c }),
+		fuda.WithOverrides(map[string]any{"database.host": "override-host", "name": "app"}),
+	)
+	require.NoError(t, err)
+
+	var cfg layeredConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.NotNil(t, got)
+	assert.Empty(t, got, "overrides alone shadow nothing")
+	assert.Equal(t, "override-host", cfg.Database.Host)
+}
+
+func TestWithConflictReport_OverridesAndPrecedence(t *testing.T) {
+	source := []byte("name: file-name\ndatabase:\n  host: file-host\n")
+	overrides := map[string]any{"database.host": "override-host", "name": "file-name"}
+
+	var got []fuda.Conflict
+	loader, err := fuda.New().
+		FromBytes(source).
+		WithOverrides(overrides).
+		WithConflictReport(func(c []fuda.Conflict) { got = c }).
+		Build()
+	require.NoError(t, err)
+
+	var cfg layeredConfig
+	require.NoError(t, loader.Load(&cfg))
+	require.Len(t, got, 1)
+	assert.Equal(t, "database.host", got[0].Key)
+	assert.Equal(t, fuda.ConflictValue{Source: "overrides", Value: "override-host"}, got[0].Winner)
+	assert.Equal(t, "override-host", cfg.Database.Host)
+
+	// With the file ranked above overrides, the file wins
+	loader, err = fuda.New().
+		FromBytes(source).
+		WithOverrides(overrides).
+		WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceOverride,
+			fuda.SourceFile, fuda.SourceEnv, fuda.SourceFlag).
+		WithConflictReport(func(c []fuda.Conflict) { got = c }).
+		Build()
+	require.NoError(t, err)
+
+	require.NoError(t, loader.Load(&cfg))
+	require.Len(t, got, 1)
+	assert.Equal(t, "file-host", got[0].Winner.Value)
+	assert.Equal(t, []fuda.ConflictValue{{Source: "overrides", Value: "override-host"}}, got[0].Shadowed)
+	assert.Equal(t, "file-host", cfg.Database.Host)
+}
@@ -0,0 +1,167 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exactDecimal is a minimal decimal type that keeps the text it was parsed
+// from, standing in for shopspring/decimal.
+type exactDecimal struct {
+	text string
+}
+
+func (d *exactDecimal) UnmarshalText(b []byte) error {
+	if _, ok := new(big.Rat).SetString(string(b)); !ok {
+		return fmt.Errorf("invalid decimal %q", b)
+	}
+	d.text = string(b)
+
+	return nil
+}
+
+func (d exactDecimal) MarshalText() ([]byte, error) { return []byte(d.text), nil }
+
+func (d exactDecimal) String() string { return d.text }
+
+var _ fuda.Decimal = (*exactDecimal)(nil)
+
+func TestDecimal_Load(t *testing.T) {
+	type Billing struct {
+		Price    exactDecimal  `yaml:"price"`
+		Discount *exactDecimal `yaml:"discount"`
+		TaxRate  exactDecimal  `yaml:"tax_rate" default:"0.0825"`
+		Fee      exactDecimal  `yaml:"fee" env:"BILLING_FEE"`
+	}
+
+	t.Setenv("BILLING_FEE", "0.30")
+
+	yamlContent := `
+price: 19.99
+discount: "0.1"
+`
+	var cfg Billing
+	loader, err := fuda.New().FromBytes([]byte(yamlContent)).Build()
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "19.99", cfg.Price.String())
+	require.NotNil(t, cfg.Discount)
+	assert.Equal(t, "0.1", cfg.Discount.String())
+	assert.Equal(t, "0.0825", cfg.TaxRate.String())
+	assert.Equal(t, "0.30", cfg.Fee.String())
+}
+
+func TestDecimal_InvalidValue(t *testing.T) {
+	type Billing struct {
+		Price exactDecimal `yaml:"price" default:"abc"`
+	}
+
+	var cfg Billing
+	err := fuda.SetDefaults(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid decimal "abc"`)
+}
+
+func TestDecimal_Precision(t *testing.T) {
+	type Billing struct {
+		Price   exactDecimal `yaml:"price" validate:"precision=2"`
+		Rate    float64      `yaml:"rate" validate:"precision=4"`
+		Amount  string       `yaml:"amount" validate:"omitempty,precision=2"`
+		Count   int          `yaml:"count" validate:"precision=0"`
+		Percent *float32     `yaml:"percent" validate:"omitempty,precision=1"`
+	}
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "within precision", yaml: "price: 19.99\nrate: 0.0825\namount: \"1.50\"\ncount: 3\npercent: 12.5"},
+		{name: "trailing zeros", yaml: "price: 19.9900\nrate: 1.25000"},
+		{name: "exponent", yaml: "price: 1.5e-1\nrate: 12e3"},
+		{name: "decimal too precise", yaml: "price: 19.999\nrate: 0.1", wantErr: "Price"},
+		{name: "float too precise", yaml: "price: 1\nrate: 0.00001", wantErr: "Rate"},
+		{name: "string too precise", yaml: "price: 1\nrate: 1\namount: \"0.125\"", wantErr: "Amount"},
+		{name: "string not a number", yaml: "price: 1\nrate: 1\namount: ten", wantErr: "Amount"},
+		{name: "pointer too precise", yaml: "price: 1\nrate: 1\npercent: 0.25", wantErr: "Percent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Billing
+			loader, err := fuda.New().FromBytes([]byte(tt.yaml)).Build()
+			require.NoError(t, err)
+
+			err = loader.Load(&cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *fuda.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Contains(t, err.Error(), "precision")
+		})
+	}
+}
+
+func TestDecimal_PrecisionStandalone(t *testing.T) {
+	type Billing struct {
+		Price exactDecimal `validate:"precision=2"`
+	}
+
+	require.NoError(t, fuda.Validate(&Billing{Price: exactDecimal{text: "9.95"}}))
+	require.Error(t, fuda.Validate(&Billing{Price: exactDecimal{text: "9.955"}}))
+}
+
+func TestDecimal_RegisterValidations(t *testing.T) {
+	type Billing struct {
+		Price exactDecimal `yaml:"price" validate:"precision=2"`
+	}
+
+	v := validator.New()
+	require.NoError(t, fuda.RegisterValidations(v))
+
+	var cfg Billing
+	loader, err := fuda.New().FromBytes([]byte("price: 0.125")).WithValidator(v).Build()
+	require.NoError(t, err)
+
+	err = loader.Load(&cfg)
+	var validationErr *fuda.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+}
+
+func TestDecimal_InvalidPrecisionParam(t *testing.T) {
+	type Billing struct {
+		Price exactDecimal `validate:"precision=two"`
+	}
+
+	assert.Panics(t, func() {
+		_ = fuda.Validate(&Billing{Price: exactDecimal{text: "1"}})
+	})
+}
+
+func TestDecimal_Schema(t *testing.T) {
+	type Billing struct {
+		Price exactDecimal `yaml:"price" validate:"precision=2"`
+	}
+
+	schema, err := fuda.Schema(&Billing{})
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), `"price": {
+      "type": [
+        "string",
+        "number"
+      ]
+    }`)
+}
@@ -0,0 +1,159 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decoderJWKS struct {
+	Keys []struct {
+		KeyID string `json:"kid"`
+		Type  string `json:"kty"`
+	} `json:"keys"`
+}
+
+type decoderTLS struct {
+	CA   *pem.Block `yaml:"ca" decoder:"pem"`
+	Cert *pem.Block `yaml:"cert" fuda:"decoder=pem,env=DEC_CERT"`
+	Key  *pem.Block `yaml:"key" decoder:"pem" ref:"file://${.KeyPath}"`
+
+	KeyPath string `yaml:"key_path"`
+}
+
+type decoderConfig struct {
+	TLS     decoderTLS   `yaml:"tls"`
+	JWKS    decoderJWKS  `yaml:"jwks" decoder:"json"`
+	Issuers []decoderTLS `yaml:"issuers"`
+}
+
+func registerTestDecoders(t *testing.T) {
+	t.Helper()
+
+	fuda.RegisterDecoder("pem", func(data []byte, target reflect.Value) error {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return errors.New("no PEM block found")
+		}
+		target.Set(reflect.ValueOf(block))
+
+		return nil
+	})
+	fuda.RegisterDecoder("json", func(data []byte, target reflect.Value) error {
+		return json.Unmarshal(data, target.Addr().Interface())
+	})
+	t.Cleanup(func() {
+		fuda.UnregisterDecoder("pem")
+		fuda.UnregisterDecoder("json")
+	})
+}
+
+func pemText(blockType string, body []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: body}))
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	registerTestDecoders(t)
+
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, []byte(pemText("PRIVATE KEY", []byte("key"))), 0o600))
+	t.Setenv("DEC_CERT", pemText("CERTIFICATE", []byte("cert")))
+
+	yamlContent := `
+tls:
+  ca: |
+    -----BEGIN CERTIFICATE-----
+    Y2E=
+    -----END CERTIFICATE-----
+  key_path: ` + keyPath + `
+jwks:
+  keys:
+    - kid: k1
+      kty: RSA
+    - {kid: k2, kty: EC}
+issuers:
+  - ca: |
+      -----BEGIN CERTIFICATE-----
+      aXNzdWVy
+      -----END CERTIFICATE-----
+`
+	loader, err := fuda.New().FromBytes([]byte(yamlContent)).Build()
+	require.NoError(t, err)
+
+	var cfg decoderConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	require.NotNil(t, cfg.TLS.CA, "decoded from the YAML value")
+	assert.Equal(t, "CERTIFICATE", cfg.TLS.CA.Type)
+	assert.Equal(t, []byte("ca"), cfg.TLS.CA.Bytes)
+	require.NotNil(t, cfg.TLS.Cert, "decoded from the env var")
+	assert.Equal(t, []byte("cert"), cfg.TLS.Cert.Bytes)
+	require.NotNil(t, cfg.TLS.Key, "decoded from the ref")
+	assert.Equal(t, "PRIVATE KEY", cfg.TLS.Key.Type)
+
+	require.Len(t, cfg.JWKS.Keys, 2, "a mapping is passed as JSON")
+	assert.Equal(t, "k1", cfg.JWKS.Keys[0].KeyID)
+	assert.Equal(t, "EC", cfg.JWKS.Keys[1].Type)
+
+	require.Len(t, cfg.Issuers, 1)
+	require.NotNil(t, cfg.Issuers[0].CA)
+	assert.Equal(t, []byte("issuer"), cfg.Issuers[0].CA.Bytes)
+}
+
+func TestRegisterDecoder_Errors(t *testing.T) {
+	registerTestDecoders(t)
+
+	t.Run("decoder error names the field", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte("tls:\n  ca: not a pem block\n")).Build()
+		require.NoError(t, err)
+
+		var cfg decoderConfig
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tls.ca")
+		assert.Contains(t, err.Error(), "no PEM block found")
+	})
+
+	t.Run("unknown decoder", func(t *testing.T) {
+		type Config struct {
+			Value string `yaml:"value" decoder:"missing"`
+		}
+
+		loader, err := fuda.New().FromBytes([]byte("value: x\n")).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no decoder registered as "missing"`)
+	})
+
+	t.Run("unknown decoder without YAML value", func(t *testing.T) {
+		type Config struct {
+			Value string `decoder:"missing" default:"x"`
+		}
+
+		loader, err := fuda.New().Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no decoder registered as "missing"`)
+	})
+}
+
+func TestRegisterDecoder_Panics(t *testing.T) {
+	assert.Panics(t, func() { fuda.RegisterDecoder("", func([]byte, reflect.Value) error { return nil }) })
+	assert.Panics(t, func() { fuda.RegisterDecoder("nil", nil) })
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDefaultBuilder(t *testing.T) {
+	type Config struct {
+		Host   string `yaml:"host"`
+		Region string `env:"REGION"`
+		Secret string `ref:"corp://secret"`
+	}
+
+	corp := fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+		return []byte("s3cret"), nil
+	})
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/app.yaml", []byte("host: from-file\n"), 0o644))
+
+	t.Setenv("ACME_REGION", "eu-west-1")
+	fuda.SetDefaultBuilder(func(b *fuda.Builder) {
+		b.WithFilesystem(fs).
+			WithEnvPrefix("ACME_").
+			WithResolver("corp", corp)
+	})
+	t.Cleanup(func() { fuda.SetDefaultBuilder(nil) })
+
+	t.Run("LoadFile", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, fuda.LoadFile("/etc/app.yaml", &cfg))
+		assert.Equal(t, Config{Host: "from-file", Region: "eu-west-1", Secret: "s3cret"}, cfg)
+	})
+
+	t.Run("LoadBytes", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, fuda.LoadBytes([]byte("host: from-bytes\n"), &cfg))
+		assert.Equal(t, Config{Host: "from-bytes", Region: "eu-west-1", Secret: "s3cret"}, cfg)
+	})
+
+	t.Run("MustLoadReader", func(t *testing.T) {
+		var cfg Config
+		fuda.MustLoadReader(strings.NewReader("host: from-reader\n"), &cfg)
+		assert.Equal(t, Config{Host: "from-reader", Region: "eu-west-1", Secret: "s3cret"}, cfg)
+	})
+
+	t.Run("New is unaffected", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte("host: x\n")).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err, "corp:// is not registered without the defaults")
+		assert.Contains(t, err.Error(), "unsupported scheme")
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		fuda.SetDefaultBuilder(nil)
+
+		var cfg Config
+		err := fuda.LoadBytes([]byte("host: x\n"), &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported scheme")
+	})
+}
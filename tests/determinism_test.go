@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stableConfig struct {
+	Ports   map[int]string     `yaml:"ports"`
+	Weights map[string]float64 `yaml:"weights"`
+	Ratio   float32            `yaml:"ratio"`
+	Mixed   map[any]string     `yaml:"mixed"`
+	Flags   map[bool]int       `yaml:"flags"`
+}
+
+func newStableConfig() *stableConfig {
+	return &stableConfig{
+		Ports:   map[int]string{10: "ten", 9: "nine", 100: "hundred", -1: "minus"},
+		Weights: map[string]float64{"b": 0.1, "a": 1e21, "c": 3},
+		Ratio:   0.1,
+		Mixed:   map[any]string{"1": "string", 1: "int", 2.5: "float"},
+		Flags:   map[bool]int{true: 1, false: 0},
+	}
+}
+
+func TestDumpRedacted_Stable(t *testing.T) {
+	var first bytes.Buffer
+	require.NoError(t, fuda.DumpRedacted(&first, newStableConfig()))
+
+	assert.Equal(t, `ports:
+    -1: minus
+    9: nine
+    10: ten
+    100: hundred
+weights:
+    a: 1e+21
+    b: 0.1
+    c: 3
+ratio: 0.1
+mixed:
+    1: int
+    1: string
+    2.5: float
+flags:
+    false: 0
+    true: 1
+`, first.String())
+
+	for range 20 {
+		var buf bytes.Buffer
+		require.NoError(t, fuda.DumpRedacted(&buf, newStableConfig()))
+		require.Equal(t, first.String(), buf.String())
+	}
+}
+
+func TestSerializations_Stable(t *testing.T) {
+	cfg := newStableConfig()
+	marshaled, err := fuda.Marshal(cfg)
+	require.NoError(t, err)
+	schema, err := fuda.Schema(cfg)
+	require.NoError(t, err)
+
+	other := newStableConfig()
+	other.Ports[9] = "changed"
+	changes := fuda.Diff(cfg, other)
+
+	for range 20 {
+		again, err := fuda.Marshal(newStableConfig())
+		require.NoError(t, err)
+		require.Equal(t, string(marshaled), string(again))
+
+		againSchema, err := fuda.Schema(newStableConfig())
+		require.NoError(t, err)
+		require.Equal(t, string(schema), string(againSchema))
+
+		require.Equal(t, changes, fuda.Diff(newStableConfig(), other))
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+)
+
+type diffServer struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+type diffBase struct {
+	Name string `yaml:"name"`
+}
+
+type diffConfig struct {
+	diffBase `yaml:",inline"`
+
+	Server   diffServer        `yaml:"server"`
+	Replicas []diffServer      `yaml:"replicas"`
+	Labels   map[string]string `yaml:"labels"`
+	Timeout  time.Duration     `yaml:"timeout"`
+	TLS      *diffServer       `yaml:"tls"`
+	Password string            `yaml:"password" secret:"true"`
+	Token    string            `yaml:"token" ref:"env://TOKEN"`
+	LogLevel string            // key "loglevel"
+	Ignored  string            `yaml:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	before := diffConfig{
+		diffBase: diffBase{Name: "svc"},
+		Server:   diffServer{Host: "a.com", Port: 80},
+		Replicas: []diffServer{{Host: "r1"}, {Host: "r2"}},
+		Labels:   map[string]string{"team": "core", "tier": "web"},
+		Timeout:  time.Second,
+		Password: "one",
+		Token:    "t1",
+		LogLevel: "info",
+		Ignored:  "x",
+	}
+
+	t.Run("equal configs", func(t *testing.T) {
+		same := before
+		assert.Nil(t, fuda.Diff(&before, &same))
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		after := before
+		after.Name = "svc2"
+		after.Server.Port = 8080
+		after.Replicas = []diffServer{{Host: "r1"}, {Host: "r2b"}, {Host: "r3"}}
+		after.Labels = map[string]string{"team": "payments", "zone": "eu"}
+		after.Timeout = 2 * time.Second
+		after.TLS = &diffServer{Host: "tls.com"}
+		after.Password = "two"
+		after.Token = ""
+		after.LogLevel = "debug"
+		after.Ignored = "y"
+
+		assert.Equal(t, []fuda.FieldChange{
+			{Path: "name", Old: "svc", New: "svc2"},
+			{Path: "server.port", Old: 80, New: 8080},
+			{Path: "replicas[1].host", Old: "r2", New: "r2b"},
+			{Path: "replicas[2]", New: diffServer{Host: "r3"}},
+			{Path: "labels.team", Old: "core", New: "payments"},
+			{Path: "labels.tier", Old: "web"},
+			{Path: "labels.zone", New: "eu"},
+			{Path: "timeout", Old: time.Second, New: 2 * time.Second},
+			{Path: "tls", New: diffServer{Host: "tls.com"}},
+			{Path: "password", Old: "[REDACTED]", New: "[REDACTED]", Redacted: true},
+			{Path: "token", Old: "[REDACTED]", New: "", Redacted: true},
+			{Path: "loglevel", Old: "info", New: "debug"},
+		}, fuda.Diff(&before, &after))
+	})
+
+	t.Run("nested pointer fields", func(t *testing.T) {
+		a := before
+		a.TLS = &diffServer{Host: "tls.com", Port: 443}
+		b := before
+		b.TLS = &diffServer{Host: "tls.com", Port: 8443}
+
+		assert.Equal(t, []fuda.FieldChange{
+			{Path: "tls.port", Old: 443, New: 8443},
+		}, fuda.Diff(&a, &b))
+	})
+}
+
+func TestFieldChange_String(t *testing.T) {
+	c := fuda.FieldChange{Path: "server.port", Old: 80, New: 8080}
+	assert.Equal(t, "server.port: 80 -> 8080", c.String())
+}
@@ -0,0 +1,157 @@
+//go:build unix && !fuda_minimal
+
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	type Database struct {
+		Host     string `yaml:"host" default:"localhost"`
+		Password string `yaml:"password" ref:"mem://db-password"`
+		URL      string `yaml:"url" dsn:"postgres://app:{{.Password}}@{{.Host}}/app"`
+	}
+	type Config struct {
+		Name     string            `yaml:"name"`
+		Timeout  time.Duration     `yaml:"timeout" default:"5s"`
+		Database Database          `yaml:"database"`
+		Labels   map[string]string `yaml:"labels"`
+		Internal string            `yaml:"-"`
+	}
+
+	trace := &fuda.TraceRecorder{}
+	loader, err := fuda.New().
+		FromBytes([]byte("name: api\nlabels:\n  zone: b\n  team: a\n")).
+		WithRefResolver(staticResolver{"mem://db-password": "s3cret"}).
+		WithTrace(trace).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	path := filepath.Join(t.TempDir(), "dump.yaml")
+	stop := fuda.DumpOnSignal(fuda.DumpOptions{
+		Snapshot: func() any { return &cfg },
+		Trace:    trace,
+		Path:     path,
+	})
+	defer stop()
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	var dump string
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		dump = string(data)
+
+		return err == nil &&
+			containsAll(dump, "# provenance", "Internal:")
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, `# effective config
+name: api
+timeout: 5s
+database:
+    host: localhost
+    password: '[REDACTED]'
+    url: '[REDACTED]'
+labels:
+    team: a
+    zone: b
+# provenance
+Name: yaml=api (used)
+Timeout: yaml unset, default=5s (used)
+Database.Host: yaml unset, default=localhost (used)
+Database.Password: yaml unset, ref=mem://db-password (used)
+Database.URL: yaml unset, dsn=postgres://app:{{.Password}}@{{.Host}}/app (used)
+Labels: yaml=map[team:a zone:b] (used)
+Internal: yaml unset, zero value
+`, dump)
+	assert.NotContains(t, dump, "s3cret")
+}
+
+func TestDumpOnSignal_Encrypted(t *testing.T) {
+	type Config struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" secret:"true"`
+	}
+
+	cfg := Config{Host: "db.internal", Password: "s3cret"}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "dump.enc")
+
+	stop := fuda.DumpOnSignal(fuda.DumpOptions{
+		Snapshot:      func() any { return &cfg },
+		Path:          path,
+		EncryptionKey: key,
+	})
+	defer stop()
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	var sealed []byte
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		sealed = data
+
+		return err == nil && len(data) > 0
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.True(t, fuda.IsEncryptedSnapshot(sealed))
+	assert.NotContains(t, string(sealed), "db.internal")
+
+	plain, err := fuda.DecryptSnapshot(key, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "# effective config\nhost: db.internal\npassword: '[REDACTED]'\n", string(plain))
+}
+
+func TestDumpOnSignal_StopTwice(t *testing.T) {
+	stop := fuda.DumpOnSignal(fuda.DumpOptions{})
+	stop()
+	stop()
+}
+
+func TestTraceRedactsSensitiveValues(t *testing.T) {
+	type Config struct {
+		Token string `yaml:"token" env:"TOKEN" ref:"mem://token"`
+	}
+
+	t.Setenv("REDACT_TOKEN", "from-env")
+
+	trace := &fuda.TraceRecorder{}
+	loader, err := fuda.New().
+		FromBytes([]byte("token: from-yaml\n")).
+		WithEnvPrefix("REDACT_").
+		WithRefResolver(staticResolver{}).
+		WithTrace(trace).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t,
+		"Token: yaml=[REDACTED], env REDACT_TOKEN=[REDACTED] (used), ref=mem://token (skipped)\n",
+		trace.String())
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encodingConfig struct {
+	Name  string   `yaml:"name"`
+	Hosts []string `yaml:"hosts"`
+	Motd  string   `yaml:"motd"`
+}
+
+const encodingSource = "name: café\r\nhosts:\r\n  - a\r\n  - b\r\nmotd: |\r\n  hello\r\n  world\r\n"
+
+func TestEncoding_BOMAndCRLF(t *testing.T) {
+	var cfg encodingConfig
+	require.NoError(t, fuda.LoadBytes(append([]byte{0xEF, 0xBB, 0xBF}, encodingSource...), &cfg))
+
+	assert.Equal(t, "café", cfg.Name)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, "hello\nworld\n", cfg.Motd, "block scalars get \\n line endings")
+}
+
+func TestEncoding_UTF16File(t *testing.T) {
+	data := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(encodingSource)) {
+		data = binary.LittleEndian.AppendUint16(data, u)
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/app/config.yaml", data, 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFile("/etc/app/config.yaml").Build()
+	require.NoError(t, err)
+
+	var cfg encodingConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "café", cfg.Name)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+
+	m, err := loader.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, "café", m["name"])
+}
+
+func TestEncoding_InvalidInput(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/latin1.yaml", []byte("name: caf\xe9\n"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFile("/latin1.yaml").Build()
+	require.NoError(t, err)
+
+	var cfg encodingConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode /latin1.yaml: invalid UTF-8 at line 1, column 10; save the file as UTF-8")
+
+	err = fuda.LoadBytes([]byte("\x89PNG\r\n\x1a\n\x00\x00"), &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the file looks binary")
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptSnapshot(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	plain := []byte("database:\n    password: s3cret\n")
+
+	sealed, err := fuda.EncryptSnapshot(key, plain)
+	require.NoError(t, err)
+	assert.True(t, fuda.IsEncryptedSnapshot(sealed))
+	assert.NotContains(t, string(sealed), "s3cret")
+
+	opened, err := fuda.DecryptSnapshot(key, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plain, opened)
+
+	again, err := fuda.EncryptSnapshot(key, plain)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "each encryption uses a fresh nonce")
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := fuda.DecryptSnapshot(bytes.Repeat([]byte{0x24}, 32), sealed)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "wrong key or corrupted data")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := bytes.Clone(sealed)
+		tampered[len(tampered)-1] ^= 0xff
+
+		_, err := fuda.DecryptSnapshot(key, tampered)
+		require.Error(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := fuda.DecryptSnapshot(key, sealed[:10])
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "truncated")
+	})
+
+	t.Run("plaintext input", func(t *testing.T) {
+		assert.False(t, fuda.IsEncryptedSnapshot(plain))
+
+		_, err := fuda.DecryptSnapshot(key, plain)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not an encrypted fuda snapshot")
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := fuda.EncryptSnapshot([]byte("short"), plain)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "16, 24, or 32 bytes")
+	})
+}
+
+func TestSnapshotKeyFromEnv(t *testing.T) {
+	key := bytes.Repeat([]byte{0x07}, 32)
+
+	t.Run("valid", func(t *testing.T) {
+		t.Setenv("FUDA_TEST_SNAPSHOT_KEY", base64.StdEncoding.EncodeToString(key))
+
+		got, err := fuda.SnapshotKeyFromEnv("FUDA_TEST_SNAPSHOT_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		unsetEnv(t, "FUDA_TEST_SNAPSHOT_KEY")
+
+		_, err := fuda.SnapshotKeyFromEnv("FUDA_TEST_SNAPSHOT_KEY")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not set")
+	})
+
+	t.Run("not base64", func(t *testing.T) {
+		t.Setenv("FUDA_TEST_SNAPSHOT_KEY", "not base64!")
+
+		_, err := fuda.SnapshotKeyFromEnv("FUDA_TEST_SNAPSHOT_KEY")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not valid base64")
+	})
+
+	t.Run("wrong length", func(t *testing.T) {
+		t.Setenv("FUDA_TEST_SNAPSHOT_KEY", base64.StdEncoding.EncodeToString([]byte("tiny")))
+
+		_, err := fuda.SnapshotKeyFromEnv("FUDA_TEST_SNAPSHOT_KEY")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "16, 24, or 32 bytes")
+	})
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type envConflictDB struct {
+	Host string `yaml:"host" env:"DB_HOST"`
+	Port int    `yaml:"port" env:"DB_PORT"`
+}
+
+type envConflictCache struct {
+	Host string `yaml:"host" env:"DB_HOST"`
+}
+
+type envConflictConfig struct {
+	Primary envConflictDB    `yaml:"primary"`
+	Cache   envConflictCache `yaml:"cache"`
+	Replica *envConflictDB   `yaml:"replica"`
+}
+
+func TestEnvConflict_NestedStructs(t *testing.T) {
+	loader, err := fuda.New().WithEnvPrefix("APP_").Build()
+	require.NoError(t, err)
+
+	var cfg envConflictConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+
+	var loadErr *fuda.LoadError
+	require.ErrorAs(t, err, &loadErr)
+	require.Len(t, loadErr.Errors, 3)
+	assert.Equal(t, "Cache.Host", loadErr.Errors[0].Path)
+	assert.Equal(t, "environment variable APP_DB_HOST is also read by field 'Primary.Host'", loadErr.Errors[0].Message)
+	assert.Equal(t, "Replica.Host", loadErr.Errors[1].Path)
+	assert.Equal(t, "Replica.Port", loadErr.Errors[2].Path)
+	assert.Contains(t, err.Error(), "APP_DB_PORT is also read by field 'Primary.Port'")
+}
+
+func TestEnvConflict_AutoEnv(t *testing.T) {
+	type DB struct {
+		Host string `yaml:"host"`
+	}
+	type Config struct {
+		DBHost string `yaml:"db_host"`
+		DB     DB     `yaml:"db"`
+	}
+
+	loader, err := fuda.New().WithAutoEnv().Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'DB.Host' (tag 'env'): environment variable DB_HOST is also read by field 'DBHost'")
+}
+
+func TestEnvConflict_None(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host" env:"SERVER_HOST"`
+	}
+	type Config struct {
+		Servers []Server          `yaml:"servers"`
+		ByName  map[string]Server `yaml:"by_name"`
+		Main    Server            `yaml:"main"`
+		Ignored string            `yaml:"ignored" env:"-"`
+	}
+
+	loader, err := fuda.New().
+		FromBytes([]byte("servers:\n  - host: a\n  - host: b\n")).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Len(t, cfg.Servers, 2)
+}
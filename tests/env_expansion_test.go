@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expansionConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Password string   `yaml:"password"`
+	Hosts    []string `yaml:"hosts"`
+}
+
+func TestWithEnvExpansion(t *testing.T) {
+	t.Setenv("EXPAND_HOST", "db.example.com")
+	t.Setenv("EXPAND_PASSWORD", "p@ss: word")
+
+	yamlContent := `
+host: ${EXPAND_HOST}
+port: ${EXPAND_PORT:-5432}
+password: "${EXPAND_PASSWORD}"
+hosts:
+  - ${EXPAND_HOST}
+  - ${EXPAND_REPLICA:-replica.example.com}
+`
+	loader, err := fuda.New().FromBytes([]byte(yamlContent)).WithEnvExpansion().Build()
+	require.NoError(t, err)
+
+	var cfg expansionConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "db.example.com", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, "p@ss: word", cfg.Password)
+	assert.Equal(t, []string{"db.example.com", "replica.example.com"}, cfg.Hosts)
+}
+
+func TestWithEnvExpansion_Disabled(t *testing.T) {
+	t.Setenv("EXPAND_HOST", "db.example.com")
+
+	loader, err := fuda.New().FromBytes([]byte("host: ${EXPAND_HOST}\n")).Build()
+	require.NoError(t, err)
+
+	var cfg expansionConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "${EXPAND_HOST}", cfg.Host)
+}
+
+func TestWithEnvExpansion_RequiredError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("host: localhost\npassword: ${EXPAND_MISSING:?password is required}\n"), 0o600))
+
+	loader, err := fuda.NewLoader(fuda.FromFile(path), fuda.WithEnvExpansion())
+	require.NoError(t, err)
+
+	var cfg expansionConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to expand env in "+path)
+	assert.Contains(t, err.Error(), "line 2: required variable EXPAND_MISSING is missing a value: password is required")
+}
+
+func TestWithEnvExpansion_FromFiles(t *testing.T) {
+	t.Setenv("EXPAND_PROD_HOST", "prod.example.com")
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("host: localhost\nport: ${EXPAND_BASE_PORT:-5432}\n"), 0o600))
+	require.NoError(t, os.WriteFile(prod, []byte("host: ${EXPAND_PROD_HOST}\n"), 0o600))
+
+	loader, err := fuda.New().FromFiles(base, prod).WithEnvExpansion().Build()
+	require.NoError(t, err)
+
+	var cfg expansionConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "prod.example.com", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSeparator_Defaults(t *testing.T) {
+	type Config struct {
+		Hosts  []string          `env:"SEP_HOSTS"`
+		Ports  []int             `env:"SEP_PORTS"`
+		Labels map[string]string `env:"SEP_LABELS"`
+	}
+
+	t.Setenv("SEP_HOSTS", `a, b,"c,d"`)
+	t.Setenv("SEP_PORTS", "80,443")
+	t.Setenv("SEP_LABELS", "k1:v1, k2:v2")
+
+	var cfg Config
+	require.NoError(t, fuda.LoadEnv(&cfg))
+	assert.Equal(t, []string{"a", "b", "c,d"}, cfg.Hosts)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, cfg.Labels)
+}
+
+func TestEnvSeparator_Custom(t *testing.T) {
+	type Config struct {
+		Hosts   []string            `env:"SEP_HOSTS" envSeparator:";"`
+		Labels  map[string]string   `env:"SEP_LABELS" envSeparator:";" envKeyValSeparator:"="`
+		Weights map[string]int      `fuda:"env=SEP_WEIGHTS,envSeparator=' ',envKeyValSeparator=:"`
+		Groups  map[string][]string `env:"SEP_GROUPS" envSeparator:"|"`
+		Peers   *[]string           `env:"SEP_PEERS" envSeparator:"||"`
+	}
+
+	t.Setenv("SEP_HOSTS", "a,1; b,2 ;c")
+	t.Setenv("SEP_LABELS", "url=http://x:80/?q=1;team=core")
+	t.Setenv("SEP_WEIGHTS", "a:1 b:2")
+	t.Setenv("SEP_GROUPS", "web:a,b|db:c")
+	t.Setenv("SEP_PEERS", "p1||p2")
+
+	var cfg Config
+	require.NoError(t, fuda.LoadEnv(&cfg))
+	assert.Equal(t, []string{"a,1", "b,2", "c"}, cfg.Hosts)
+	assert.Equal(t, map[string]string{"url": "http://x:80/?q=1", "team": "core"}, cfg.Labels)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, cfg.Weights)
+	assert.Equal(t, map[string][]string{"web": {"a", "b"}, "db": {"c"}}, cfg.Groups, "elements use the default separators")
+	require.NotNil(t, cfg.Peers)
+	assert.Equal(t, []string{"p1", "p2"}, *cfg.Peers)
+}
+
+func TestEnvSeparator_FallbackAndDefault(t *testing.T) {
+	type Config struct {
+		Hosts []string `env:"SEP_HOSTS=a;b" envSeparator:";"`
+		Zones []string `env:"SEP_ZONES" envSeparator:";" default:"x,y"`
+	}
+
+	var cfg Config
+	require.NoError(t, fuda.LoadEnv(&cfg))
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts, "the env fallback uses the separator")
+	assert.Equal(t, []string{"x", "y"}, cfg.Zones, "the default tag does not")
+}
+
+func TestEnvSeparator_EmptyValue(t *testing.T) {
+	type Config struct {
+		Hosts  []string          `env:"SEP_HOSTS" default:"a,b"`
+		Labels map[string]string `env:"SEP_LABELS" envSeparator:";"`
+	}
+
+	t.Setenv("SEP_HOSTS", "")
+	t.Setenv("SEP_LABELS", " ")
+
+	loader, err := fuda.New().FromBytes([]byte("hosts: [z]\n")).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Empty(t, cfg.Hosts, "an empty variable clears the slice")
+	assert.Empty(t, cfg.Labels)
+}
+
+func TestEnvSeparator_Conflict(t *testing.T) {
+	type Config struct {
+		Labels map[string]string `env:"SEP_LABELS" envSeparator:":"`
+	}
+
+	t.Setenv("SEP_LABELS", "a:1")
+
+	var cfg Config
+	err := fuda.LoadEnv(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `envSeparator ":" and envKeyValSeparator ":" must differ`)
+}
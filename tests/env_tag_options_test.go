@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvTag_Fallback(t *testing.T) {
+	type Config struct {
+		Host    string   `yaml:"host" env:"OPT_DB_HOST=localhost"`
+		Port    int      `yaml:"port" env:"OPT_DB_PORT=5432"`
+		Hosts   []string `yaml:"hosts" env:"OPT_DB_HOSTS=a,b"`
+		Timeout string   `yaml:"timeout" env:"OPT_DB_TIMEOUT=5s" default:"10s"`
+	}
+
+	t.Run("fallback used when unset", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, fuda.LoadEnv(&cfg))
+		assert.Equal(t, "localhost", cfg.Host)
+		assert.Equal(t, 5432, cfg.Port)
+		assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		assert.Equal(t, "10s", cfg.Timeout, "default tag takes precedence over the fallback")
+	})
+
+	t.Run("env var overrides fallback", func(t *testing.T) {
+		t.Setenv("OPT_DB_HOST", "db.example.com")
+
+		var cfg Config
+		require.NoError(t, fuda.LoadEnv(&cfg))
+		assert.Equal(t, "db.example.com", cfg.Host)
+	})
+
+	t.Run("file value overrides fallback", func(t *testing.T) {
+		var cfg Config
+		loader, err := fuda.New().FromBytes([]byte("host: file.example.com\nport: 6543\n")).Build()
+		require.NoError(t, err)
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "file.example.com", cfg.Host)
+		assert.Equal(t, 6543, cfg.Port)
+	})
+
+	t.Run("prefix applies to name only", func(t *testing.T) {
+		t.Setenv("APP_OPT_DB_PORT", "7000")
+
+		var cfg Config
+		loader, err := fuda.New().WithEnvPrefix("APP_").Build()
+		require.NoError(t, err)
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, 7000, cfg.Port)
+		assert.Equal(t, "localhost", cfg.Host)
+	})
+}
+
+func TestEnvTag_Required(t *testing.T) {
+	type Database struct {
+		Host     string `yaml:"host" env:"OPT_DB_HOST,required"`
+		Password string `yaml:"password" env:"OPT_DB_PASSWORD,required"`
+	}
+	type Config struct {
+		Name     string   `yaml:"name" env:"OPT_APP_NAME,required"`
+		Database Database `yaml:"database"`
+	}
+
+	t.Run("all set", func(t *testing.T) {
+		t.Setenv("OPT_APP_NAME", "svc")
+		t.Setenv("OPT_DB_HOST", "db")
+		t.Setenv("OPT_DB_PASSWORD", "")
+
+		var cfg Config
+		require.NoError(t, fuda.LoadEnv(&cfg))
+		assert.Equal(t, "svc", cfg.Name)
+		assert.Equal(t, "db", cfg.Database.Host)
+	})
+
+	t.Run("missing vars are aggregated", func(t *testing.T) {
+		t.Setenv("OPT_DB_HOST", "db")
+
+		var cfg Config
+		loader, err := fuda.New().FromBytes([]byte("name: from-file\n")).Build()
+		require.NoError(t, err)
+
+		err = loader.Load(&cfg)
+		var loadErr *fuda.LoadError
+		require.ErrorAs(t, err, &loadErr)
+		require.Len(t, loadErr.Errors, 2)
+		assert.Equal(t, "Name", loadErr.Errors[0].Path)
+		assert.Equal(t, "Database.Password", loadErr.Errors[1].Path)
+		assert.Contains(t, err.Error(), "required environment variable OPT_APP_NAME is not set")
+		assert.Contains(t, err.Error(), "required environment variable OPT_DB_PASSWORD is not set")
+		assert.NotContains(t, err.Error(), "OPT_DB_HOST")
+	})
+
+	t.Run("prefix in message", func(t *testing.T) {
+		t.Setenv("SVC_OPT_APP_NAME", "svc")
+		t.Setenv("SVC_OPT_DB_HOST", "db")
+
+		var cfg Config
+		loader, err := fuda.New().WithEnvPrefix("SVC_").Build()
+		require.NoError(t, err)
+
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required environment variable SVC_OPT_DB_PASSWORD is not set")
+		assert.Equal(t, 1, strings.Count(err.Error(), "required environment variable"))
+	})
+}
+
+func TestEnvTag_InvalidOptions(t *testing.T) {
+	type Config struct {
+		Host string `env:"OPT_DB_HOST,optional"`
+	}
+
+	var cfg Config
+	err := fuda.LoadEnv(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown env tag option "optional"`)
+}
+
+func TestEnvTag_FallbackTrace(t *testing.T) {
+	type Config struct {
+		Host string `yaml:"host" env:"TRACE_FB_HOST=localhost"`
+	}
+
+	var out strings.Builder
+	loader, err := fuda.New().WithTrace(&out).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Contains(t, out.String(), "env TRACE_FB_HOST unset")
+	assert.Contains(t, out.String(), "env fallback=localhost (used)")
+}
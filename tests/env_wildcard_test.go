@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvWildcard_Map(t *testing.T) {
+	type Config struct {
+		Features map[string]bool `yaml:"features" env:"FEATURE_*"`
+		Limits   map[string]int  `yaml:"limits" env:"LIMIT_*"`
+	}
+
+	t.Setenv("APP_FEATURE_NEW_UI", "true")
+	t.Setenv("APP_FEATURE_BETA", "false")
+	t.Setenv("APP_LIMIT_RPS", "100")
+	t.Setenv("APP_FEATURE_", "ignored")
+
+	loader, err := fuda.New().
+		FromBytes([]byte("features:\n  beta: true\n  legacy: true\n")).
+		WithEnvPrefix("APP_").
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, map[string]bool{"NEW_UI": true, "BETA": false, "beta": true, "legacy": true}, cfg.Features)
+	assert.Equal(t, map[string]int{"RPS": 100}, cfg.Limits)
+}
+
+func TestEnvWildcard_NoMatch(t *testing.T) {
+	type Config struct {
+		Features map[string]bool `yaml:"features" env:"WC_NONE_*"`
+	}
+
+	var cfg Config
+	require.NoError(t, fuda.LoadEnv(&cfg))
+	assert.Nil(t, cfg.Features)
+}
+
+func TestEnvWildcard_Errors(t *testing.T) {
+	t.Run("invalid value", func(t *testing.T) {
+		type Config struct {
+			Features map[string]bool `env:"WC_BAD_*"`
+		}
+		t.Setenv("WC_BAD_UI", "maybe")
+
+		var cfg Config
+		err := fuda.LoadEnv(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WC_BAD_UI")
+	})
+
+	t.Run("not a map", func(t *testing.T) {
+		type Config struct {
+			Features []string `env:"WC_LIST_*"`
+		}
+
+		var cfg Config
+		err := fuda.LoadEnv(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "wildcard WC_LIST_* requires a map with string keys")
+	})
+
+	t.Run("overlapping fields", func(t *testing.T) {
+		type Config struct {
+			NewUI    bool            `env:"WC_FEATURE_NEW_UI"`
+			Features map[string]bool `env:"WC_FEATURE_*"`
+		}
+
+		var cfg Config
+		err := fuda.LoadEnv(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "environment variable WC_FEATURE_NEW_UI is also read by field 'NewUI'")
+	})
+}
+
+func TestEnvWildcard_Trace(t *testing.T) {
+	type Config struct {
+		Features map[string]bool `env:"WC_TRACE_*"`
+	}
+	t.Setenv("WC_TRACE_B", "true")
+	t.Setenv("WC_TRACE_A", "false")
+
+	var buf bytes.Buffer
+	loader, err := fuda.New().WithTrace(&buf).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Contains(t, buf.String(), "env WC_TRACE_*=A=false,B=true (used)")
+}
@@ -0,0 +1,172 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRecorder_Explain(t *testing.T) {
+	type Database struct {
+		Password string `ref:"mem://db-password" doc:"Login password"`
+		Port     int    `yaml:"port" default:"5432"`
+	}
+	type Config struct {
+		Host     string   `yaml:"host" env:"HOST" default:"localhost"`
+		Debug    bool     `yaml:"debug"`
+		Database Database `yaml:"database" doc:"Primary database connection."`
+	}
+
+	t.Setenv("EXPLAIN_HOST", "env.example.com")
+
+	trace := &fuda.TraceRecorder{}
+	loader, err := fuda.New().
+		FromBytes([]byte("debug: true\n")).
+		WithEnvPrefix("EXPLAIN_").
+		WithRefResolver(staticResolver{"mem://db-password": "s3cret"}).
+		WithTrace(trace).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	t.Run("text", func(t *testing.T) {
+		var out strings.Builder
+		require.NoError(t, trace.Explain(&out, fuda.ExplainText))
+
+		assert.Equal(t, ""+
+			"  ┌────────────┐\n"+
+			"  │ Provenance │\n"+
+			"  └────────────┘\n"+
+			"\n"+
+			"  ────────────────────────────────────────────────────────────────────────\n"+
+			"  Field │ Source                           │ Also consulted\n"+
+			"  ────────────────────────────────────────────────────────────────────────\n"+
+			"  Host  │ env EXPLAIN_HOST=env.example.com │ yaml unset, default=localhost\n"+
+			"  Debug │ yaml=true                        │ -\n"+
+			"  ────────────────────────────────────────────────────────────────────────\n"+
+			"\n"+
+			"  ── Database ────────────\n"+
+			"\n"+
+			"    Primary database connection.\n"+
+			"\n"+
+			"    ──────────────────────────────────────────────────────────────────\n"+
+			"    Field    │ Source                │ Also consulted │ Description\n"+
+			"    ──────────────────────────────────────────────────────────────────\n"+
+			"    Password │ ref=mem://db-password │ yaml unset     │ Login password\n"+
+			"    Port     │ default=5432          │ yaml unset     │\n"+
+			"    ──────────────────────────────────────────────────────────────────\n"+
+			"\n", out.String())
+		assert.NotContains(t, out.String(), "s3cret")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var out strings.Builder
+		require.NoError(t, trace.Explain(&out, fuda.ExplainMarkdown))
+
+		assert.Equal(t, ""+
+			"## Provenance\n"+
+			"\n"+
+			"| Field | Source | Also consulted |\n"+
+			"|:-----|:------|:--------------|\n"+
+			"| Host | env EXPLAIN_HOST=env.example.com | yaml unset, default=localhost |\n"+
+			"| Debug | yaml=true | - |\n"+
+			"\n"+
+			"### Database\n"+
+			"\n"+
+			"Primary database connection.\n"+
+			"\n"+
+			"| Field | Source | Also consulted | Description |\n"+
+			"|:-----|:------|:--------------|:-----------|\n"+
+			"| Password | ref=mem://db-password | yaml unset | Login password |\n"+
+			"| Port | default=5432 | yaml unset |  |\n"+
+			"\n", out.String())
+	})
+
+	t.Run("empty before a load", func(t *testing.T) {
+		var out strings.Builder
+		require.NoError(t, (&fuda.TraceRecorder{}).Explain(&out, fuda.ExplainMarkdown))
+		assert.Equal(t, "## Provenance\n\n", out.String())
+	})
+}
+
+func TestExplain(t *testing.T) {
+	type Database struct {
+		Password string `ref:"mem://db-password" default:"changeme"`
+		Port     int    `yaml:"port" default:"5432" validate:"min=1024"`
+	}
+	type Config struct {
+		Host     string   `yaml:"host" env:"HOST" default:"localhost"`
+		Debug    bool     `yaml:"debug"`
+		Database Database `yaml:"database"`
+	}
+
+	newLoader := func(t *testing.T, yaml string) *fuda.Loader {
+		t.Helper()
+
+		loader, err := fuda.New().
+			FromBytes([]byte(yaml)).
+			WithEnvPrefix("EXPLAIN_").
+			WithRefResolver(fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+				return nil, os.ErrNotExist
+			})).
+			Build()
+		require.NoError(t, err)
+
+		return loader
+	}
+
+	t.Setenv("EXPLAIN_HOST", "env.example.com")
+
+	t.Run("loaded", func(t *testing.T) {
+		var cfg Config
+		var out strings.Builder
+		require.NoError(t, fuda.Explain(newLoader(t, "debug: true\n"), &cfg, &out))
+		assert.Equal(t, "env.example.com", cfg.Host, "Explain should load the target")
+
+		assert.Equal(t, ""+
+			"  ┌───────────────┐\n"+
+			"  │ Configuration │\n"+
+			"  └───────────────┘\n"+
+			"\n"+
+			"  ──────────────────────────────────────────────────────────────────────────────────────────\n"+
+			"  Field │ Value           │ Source                           │ Also consulted\n"+
+			"  ──────────────────────────────────────────────────────────────────────────────────────────\n"+
+			"  Host  │ env.example.com │ env EXPLAIN_HOST=env.example.com │ yaml unset, default=localhost\n"+
+			"  Debug │ true            │ yaml=true                        │ -\n"+
+			"  ──────────────────────────────────────────────────────────────────────────────────────────\n"+
+			"\n"+
+			"  ── Database ────────────\n"+
+			"\n"+
+			"    ────────────────────────────────────────────────────────────────────────────\n"+
+			"    Field    │ Value      │ Source           │ Also consulted\n"+
+			"    ────────────────────────────────────────────────────────────────────────────\n"+
+			"    Password │ [REDACTED] │ default=changeme │ yaml unset, ref=mem://db-password\n"+
+			"    Port     │ 5432       │ default=5432     │ yaml unset\n"+
+			"    ────────────────────────────────────────────────────────────────────────────\n"+
+			"\n", out.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var cfg Config
+		var out strings.Builder
+		err := fuda.Explain(newLoader(t, "database: {port: 80}\n"), &cfg, &out)
+		require.Error(t, err)
+		assert.Contains(t, out.String(), "Port     │ 80         │ yaml=80", "the table should show the offending value")
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		var cfg Config
+		var out strings.Builder
+		require.Error(t, fuda.Explain(newLoader(t, "debug: [\n"), &cfg, &out))
+		assert.Empty(t, out.String())
+	})
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type zeroServer struct {
+	Port int `yaml:"port" default:"80"`
+}
+
+type zeroConfig struct {
+	Port     int                   `yaml:"port" default:"8080"`
+	Enabled  bool                  `yaml:"enabled" default:"true"`
+	Name     string                `yaml:"name" default:"app"`
+	Ratio    float64               `yaml:"ratio" default:"0.5"`
+	Retries  int                   `yaml:"retries" default:"3" env:"ZERO_RETRIES"`
+	Servers  []zeroServer          `yaml:"servers"`
+	Backends map[string]zeroServer `yaml:"backends"`
+}
+
+func loadZeroConfig(t *testing.T, yaml string, opts ...fuda.LoaderOption) (*zeroConfig, error) {
+	t.Helper()
+
+	opts = append([]fuda.LoaderOption{fuda.FromBytes([]byte(yaml))}, opts...)
+	loader, err := fuda.NewLoader(opts...)
+	require.NoError(t, err)
+
+	var cfg zeroConfig
+
+	return &cfg, loader.Load(&cfg)
+}
+
+func TestExplicitZeros(t *testing.T) {
+	const doc = `
+port: 0
+enabled: false
+name: ""
+servers: [{port: 0}, {}]
+backends: {a: {port: 0}}
+`
+
+	t.Run("kept", func(t *testing.T) {
+		cfg, err := loadZeroConfig(t, doc, fuda.WithExplicitZeros())
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, cfg.Port)
+		assert.False(t, cfg.Enabled)
+		assert.Empty(t, cfg.Name)
+		assert.Equal(t, 0, cfg.Servers[0].Port)
+		assert.Equal(t, 80, cfg.Servers[1].Port, "absent keys should get defaults")
+		assert.Equal(t, 0, cfg.Backends["a"].Port)
+		assert.InDelta(t, 0.5, cfg.Ratio, 0, "absent keys should get defaults")
+	})
+
+	t.Run("replaced by default", func(t *testing.T) {
+		cfg, err := loadZeroConfig(t, doc)
+		require.NoError(t, err)
+
+		assert.Equal(t, 8080, cfg.Port)
+		assert.True(t, cfg.Enabled)
+		assert.Equal(t, "app", cfg.Name)
+		assert.Equal(t, 80, cfg.Servers[0].Port)
+	})
+
+	t.Run("null is unset", func(t *testing.T) {
+		cfg, err := loadZeroConfig(t, "port: ~\nenabled: null", fuda.WithExplicitZeros())
+		require.NoError(t, err)
+
+		assert.Equal(t, 8080, cfg.Port)
+		assert.True(t, cfg.Enabled)
+	})
+
+	t.Run("env still wins", func(t *testing.T) {
+		t.Setenv("ZERO_RETRIES", "5")
+
+		cfg, err := loadZeroConfig(t, "retries: 0", fuda.WithExplicitZeros())
+		require.NoError(t, err)
+		assert.Equal(t, 5, cfg.Retries)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		cfg, err := loadZeroConfig(t, "port: 9090",
+			fuda.WithExplicitZeros(),
+			fuda.WithOverrides(map[string]any{"port": 0}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, 0, cfg.Port)
+	})
+}
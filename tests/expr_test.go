@@ -0,0 +1,61 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ExprConfig struct {
+	Env        string `yaml:"env" default:"dev"`
+	Workers    int    `yaml:"workers" env:"TEST_EXPR_WORKERS" default:"4"`
+	MaxConns   int    `yaml:"max_conns" expr:"${.Workers} * 2"`
+	Production bool   `yaml:"production" expr:"${.Env} == \"prod\""`
+}
+
+func TestExpr_Integration(t *testing.T) {
+	t.Setenv("TEST_EXPR_WORKERS", "8")
+
+	var trace bytes.Buffer
+	loader, err := fuda.New().
+		FromBytes([]byte("env: prod\n")).
+		WithTrace(&trace).
+		Build()
+	require.NoError(t, err)
+
+	var cfg ExprConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, 16, cfg.MaxConns)
+	assert.True(t, cfg.Production)
+	assert.Contains(t, trace.String(), "expr=${.Workers} * 2 (used)")
+
+	// A value from the file wins over the expression
+	loader, err = fuda.New().FromBytes([]byte("max_conns: 3\n")).Build()
+	require.NoError(t, err)
+
+	cfg = ExprConfig{}
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, 3, cfg.MaxConns)
+	assert.False(t, cfg.Production)
+}
+
+func TestExpr_Integration_Error(t *testing.T) {
+	type Config struct {
+		Env   string `yaml:"env" default:"dev"`
+		Count int    `yaml:"count" expr:"${.Env} * 2"`
+	}
+
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'Count' (tag 'expr')")
+	assert.Contains(t, err.Error(), "mismatched types string and int for *")
+}
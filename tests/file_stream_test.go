@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamRoute struct {
+	Path    string `yaml:"path"`
+	Backend string `yaml:"backend"`
+	Weight  int    `yaml:"weight" default:"1"`
+}
+
+type streamConfig struct {
+	Name   string        `yaml:"name" default:"router"`
+	Routes []streamRoute `yaml:"routes"`
+}
+
+func TestFromFileStream_LargeFile(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("name: edge\nroutes:\n")
+	for i := range 20000 {
+		fmt.Fprintf(&sb, "  - path: /api/v1/resource-%d\n    backend: svc-%d.internal:8080\n", i, i%50)
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/routes.yaml", []byte(sb.String()), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFileStream("/routes.yaml").Build()
+	require.NoError(t, err)
+
+	var cfg streamConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "edge", cfg.Name)
+	require.Len(t, cfg.Routes, 20000)
+	assert.Equal(t, streamRoute{Path: "/api/v1/resource-19999", Backend: "svc-49.internal:8080", Weight: 1}, cfg.Routes[19999])
+}
+
+func TestFromFileStream_ReadAtEachLoad(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app.yaml", []byte("name: first\n"), 0o644))
+
+	loader, err := fuda.NewLoader(fuda.WithFilesystem(fs), fuda.FromFileStream("/app.yaml"))
+	require.NoError(t, err)
+
+	var cfg streamConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "first", cfg.Name)
+
+	require.NoError(t, afero.WriteFile(fs, "/app.yaml", []byte("name: second\n"), 0o644))
+	cfg = streamConfig{}
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "second", cfg.Name)
+
+	// An empty file is no document: defaults apply
+	require.NoError(t, afero.WriteFile(fs, "/app.yaml", nil, 0o644))
+	cfg = streamConfig{}
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "router", cfg.Name)
+
+	m, err := loader.ToMap()
+	require.Error(t, err, "no source data")
+	assert.Nil(t, m)
+}
+
+func TestFromFileStream_Errors(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := fuda.New().WithFilesystem(fs).FromFileStream("/missing.yaml").Build()
+	require.Error(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "/bad.yaml", []byte("name: [unclosed\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/extra.yaml", []byte("name: a\nbogus: 1\n"), 0o644))
+
+	var cfg streamConfig
+	loader, err := fuda.New().WithFilesystem(fs).FromFileStream("/bad.yaml").Build()
+	require.NoError(t, err)
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to unmarshal /bad.yaml")
+
+	loader, err = fuda.New().WithFilesystem(fs).FromFileStream("/extra.yaml").WithStrictKeys().Build()
+	require.NoError(t, err)
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bogus")
+
+	require.NoError(t, afero.WriteFile(fs, "/gone.yaml", []byte("name: a\n"), 0o644))
+	loader, err = fuda.New().WithFilesystem(fs).FromFileStream("/gone.yaml").Build()
+	require.NoError(t, err)
+	require.NoError(t, fs.Remove("/gone.yaml"))
+	require.Error(t, loader.Load(&cfg), "a file removed after Build fails the load")
+}
+
+func TestFromFileStream_RawDocumentFeatures(t *testing.T) {
+	t.Setenv("FUDA_STREAM_NAME", "from-env")
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app.yaml", []byte("name: ${FUDA_STREAM_NAME}\nroutes: !include routes.yaml\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/routes.yaml", []byte("- path: /a\n  backend: a\n"), 0o644))
+
+	loader, err := fuda.New().
+		WithFilesystem(fs).
+		FromFileStream("/app.yaml").
+		WithEnvExpansion().
+		WithIncludes().
+		WithOverrides(map[string]any{"routes": []any{map[string]any{"path": "/b", "backend": "b"}}}).
+		Build()
+	require.NoError(t, err)
+
+	var cfg streamConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "from-env", cfg.Name)
+	assert.Equal(t, []streamRoute{{Path: "/b", Backend: "b", Weight: 1}}, cfg.Routes)
+
+	m, err := loader.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, "${FUDA_STREAM_NAME}", m["name"])
+}
+
+func TestFromFileStream_SOPSAndEncodings(t *testing.T) {
+	encrypted, err := os.ReadFile("fixtures/sops.enc.yaml")
+	require.NoError(t, err)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/secrets.yaml", encrypted, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/bom.yaml", []byte("\xEF\xBB\xBFname: bom\r\n"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFileStream("/secrets.yaml").WithAgeIdentity(sopsIdentity).Build()
+	require.NoError(t, err)
+	var secrets sopsConfig
+	require.NoError(t, loader.Load(&secrets))
+	assert.Equal(t, "s3cr3t", secrets.Database.Password)
+
+	loader, err = fuda.New().WithFilesystem(fs).FromFileStream("/bom.yaml").Build()
+	require.NoError(t, err)
+	var cfg streamConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "bom", cfg.Name)
+}
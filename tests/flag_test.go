@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flagConfig struct {
+	Host    string        `yaml:"host" env:"FLAG_HOST" flag:"host" default:"localhost"`
+	Port    int           `yaml:"port" env:"FLAG_PORT" flag:"port" default:"8080"`
+	Debug   bool          `yaml:"debug" flag:"debug" default:"true"`
+	Timeout time.Duration `yaml:"timeout" flag:"timeout"`
+	Tags    []string      `yaml:"tags" flag:"tags"`
+	Ignored string        `yaml:"ignored" flag:"-"`
+}
+
+func TestWithFlagSet(t *testing.T) {
+	t.Setenv("FLAG_HOST", "env-host")
+	t.Setenv("FLAG_PORT", "9090")
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("host", "flag-default", "")
+	fs.Int("port", 0, "")
+	fs.Bool("debug", true, "")
+	fs.Duration("timeout", 0, "")
+	fs.String("tags", "", "")
+	require.NoError(t, fs.Parse([]string{"-port=7070", "-debug=false", "-timeout=3s", "-tags=a,b"}))
+
+	loader, err := fuda.New().
+		FromBytes([]byte("host: file-host\nport: 1000\n")).
+		WithFlagSet(fs).
+		Build()
+	require.NoError(t, err)
+
+	var cfg flagConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "env-host", cfg.Host, "unset flag keeps env value")
+	assert.Equal(t, 7070, cfg.Port, "set flag overrides env")
+	assert.False(t, cfg.Debug, "flag-set false is not replaced by default")
+	assert.Equal(t, 3*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Empty(t, cfg.Ignored)
+}
+
+func TestWithFlagSet_ParsedAfterBuild(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Int("port", 0, "")
+
+	loader, err := fuda.NewLoader(fuda.WithFlagSet(fs))
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Parse([]string{"-port", "6060"}))
+
+	var cfg flagConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, 6060, cfg.Port)
+	assert.Equal(t, "localhost", cfg.Host)
+}
+
+func TestWithPFlagSet(t *testing.T) {
+	fs := pflag.NewFlagSet("app", pflag.ContinueOnError)
+	fs.String("host", "", "")
+	fs.Int("port", 0, "")
+	fs.StringSlice("tags", nil, "")
+	require.NoError(t, fs.Parse([]string{"--host=pflag-host", "--tags=x,y", "--tags=z"}))
+
+	loader, err := fuda.New().
+		FromBytes([]byte("host: file-host\nport: 1000\n")).
+		WithPFlagSet(fs).
+		Build()
+	require.NoError(t, err)
+
+	var cfg flagConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "pflag-host", cfg.Host)
+	assert.Equal(t, 1000, cfg.Port, "unchanged pflag keeps file value")
+	assert.Equal(t, []string{"x", "y", "z"}, cfg.Tags)
+}
+
+func TestWithFlagSet_Precedence(t *testing.T) {
+	first := flag.NewFlagSet("first", flag.ContinueOnError)
+	first.Int("port", 0, "")
+	second := pflag.NewFlagSet("second", pflag.ContinueOnError)
+	second.Int("port", 0, "")
+	second.String("host", "", "")
+	require.NoError(t, first.Parse([]string{"-port=1"}))
+	require.NoError(t, second.Parse([]string{"--port=2", "--host=second"}))
+
+	loader, err := fuda.New().
+		WithFlagSet(first).
+		WithPFlagSet(second).
+		Build()
+	require.NoError(t, err)
+
+	var cfg flagConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, 1, cfg.Port)
+	assert.Equal(t, "second", cfg.Host)
+}
+
+func TestWithFlagSet_InvalidValue(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("port", "", "")
+	require.NoError(t, fs.Parse([]string{"-port=abc"}))
+
+	loader, err := fuda.New().WithFlagSet(fs).Build()
+	require.NoError(t, err)
+
+	var cfg flagConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+
+	var fieldErr *fuda.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "flag", fieldErr.Tag)
+}
+
+func TestWithFlagSet_SatisfiesRequiredEnv(t *testing.T) {
+	type config struct {
+		Token string `env:"FLAG_REQUIRED_TOKEN,required" flag:"token"`
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("token", "", "")
+	require.NoError(t, fs.Parse([]string{"-token=abc"}))
+
+	loader, err := fuda.New().WithFlagSet(fs).Build()
+	require.NoError(t, err)
+
+	var cfg config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "abc", cfg.Token)
+}
+
+func TestWithFlagSet_Trace(t *testing.T) {
+	t.Setenv("FLAG_PORT", "9090")
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Int("port", 0, "")
+	fs.String("host", "", "")
+	require.NoError(t, fs.Parse([]string{"-port=7070"}))
+
+	var buf bytes.Buffer
+	loader, err := fuda.New().
+		FromBytes([]byte("port: 1000\n")).
+		WithFlagSet(fs).
+		WithTrace(&buf).
+		Build()
+	require.NoError(t, err)
+
+	var cfg flagConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	out := buf.String()
+	assert.Contains(t, out, "Port: yaml=1000, env FLAG_PORT=9090, flag port=7070 (used), default=8080")
+	assert.Contains(t, out, "Host: yaml unset, env FLAG_HOST unset, flag host unset, default=localhost (used)")
+}
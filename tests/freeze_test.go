@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type freezeNode struct {
+	Name string `yaml:"name"`
+	Next *freezeNode
+}
+
+type freezeConfig struct {
+	Host     string            `yaml:"host"`
+	Password string            `yaml:"password" secret:"true"`
+	Peers    []string          `yaml:"peers"`
+	Labels   map[string]string `yaml:"labels"`
+	Database *struct {
+		Port int `yaml:"port"`
+	} `yaml:"database"`
+	Extra any         `yaml:"extra"`
+	Node  *freezeNode `yaml:"-"`
+}
+
+func loadFreezeConfig(t *testing.T) *freezeConfig {
+	t.Helper()
+
+	var cfg freezeConfig
+	require.NoError(t, fuda.LoadBytes([]byte(`
+host: localhost
+password: hunter2
+peers: [a, b]
+labels: {team: core}
+database: {port: 5432}
+extra: {list: [1, 2]}
+`), &cfg))
+
+	return &cfg
+}
+
+func TestFreeze_Unchanged(t *testing.T) {
+	cfg := loadFreezeConfig(t)
+	frozen := fuda.Freeze(cfg)
+
+	assert.Same(t, cfg, frozen.Get())
+	assert.NoError(t, frozen.Check())
+}
+
+func TestFreeze_DetectsMutation(t *testing.T) {
+	cfg := loadFreezeConfig(t)
+	frozen := fuda.Freeze(cfg)
+
+	cfg.Peers[0] = "z"
+	cfg.Labels["team"] = "edge"
+	cfg.Database.Port = 6543
+	cfg.Password = "changed"
+	cfg.Extra.(map[string]any)["list"].([]any)[0] = 9
+
+	err := frozen.Check()
+	require.Error(t, err)
+
+	var merr *fuda.MutationError
+	require.ErrorAs(t, err, &merr)
+	paths := make([]string, len(merr.Changes))
+	for i, c := range merr.Changes {
+		paths[i] = c.Path
+	}
+	assert.Equal(t, []string{"password", "peers[0]", "labels.team", "database.port", "extra.list[0]"}, paths)
+	assert.Contains(t, err.Error(), "config modified after Freeze: password: [REDACTED] -> [REDACTED]; peers[0]: a -> z")
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestFreeze_Copy(t *testing.T) {
+	cfg := loadFreezeConfig(t)
+	cfg.Node = &freezeNode{Name: "a"}
+	cfg.Node.Next = cfg.Node
+	frozen := fuda.Freeze(cfg)
+
+	c := frozen.Copy()
+	c.Peers[0] = "z"
+	c.Labels["team"] = "edge"
+	c.Database.Port = 1
+	c.Node.Name = "b"
+
+	require.NoError(t, frozen.Check(), "changing a copy leaves the shared config intact")
+	assert.Equal(t, "a", cfg.Node.Name)
+	assert.Same(t, c.Node, c.Node.Next, "cycles are preserved")
+	assert.Equal(t, "a", frozen.Copy().Peers[0])
+}
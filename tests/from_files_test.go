@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type layeredConfig struct {
+	Name     string `yaml:"name"`
+	Replicas int    `yaml:"replicas" default:"1"`
+	Database struct {
+		Host     string `yaml:"host"`
+		Port     int    `yaml:"port" default:"5432"`
+		Password string `yaml:"password"`
+	} `yaml:"database"`
+	Tags []string `yaml:"tags"`
+}
+
+func layeredFs(t *testing.T) afero.Fs {
+	t.Helper()
+
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/base.yaml": `
+name: app
+replicas: 2
+database:
+  host: localhost
+tags: [base]
+`,
+		"/prod.yaml": `
+database:
+  host: db.prod.internal
+tags: [prod, eu]
+`,
+		"/secrets.yaml": `{"database": {"password": "s3cret"}}`,
+	}
+	for name, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, name, []byte(content), 0o644))
+	}
+
+	return memFs
+}
+
+func TestFromFiles(t *testing.T) {
+	loader, err := fuda.New().
+		WithFilesystem(layeredFs(t)).
+		FromFiles("/base.yaml", "/prod.yaml", "/secrets.yaml").
+		Build()
+	require.NoError(t, err)
+
+	var cfg layeredConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, 2, cfg.Replicas)
+	assert.Equal(t, "db.prod.internal", cfg.Database.Host)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+	assert.Equal(t, []string{"prod", "eu"}, cfg.Tags)
+}
+
+func TestFromFiles_OrderMatters(t *testing.T) {
+	loader, err := fuda.New().
+		WithFilesystem(layeredFs(t)).
+		FromFiles("/prod.yaml", "/base.yaml").
+		Build()
+	require.NoError(t, err)
+
+	var cfg layeredConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, []string{"base"}, cfg.Tags)
+}
+
+func TestFromFiles_ToMap(t *testing.T) {
+	loader, err := fuda.New().
+		WithFilesystem(layeredFs(t)).
+		FromFiles("/base.yaml", "/secrets.yaml").
+		Build()
+	require.NoError(t, err)
+
+	m, err := loader.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "localhost", "password": "s3cret"}, m["database"])
+}
+
+func TestFromFiles_Errors(t *testing.T) {
+	t.Run("no paths", func(t *testing.T) {
+		_, err := fuda.New().FromFiles().Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one path")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := fuda.New().
+			WithFilesystem(layeredFs(t)).
+			FromFiles("/base.yaml", "/missing.yaml").
+			Build()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid file is named", func(t *testing.T) {
+		memFs := layeredFs(t)
+		require.NoError(t, afero.WriteFile(memFs, "/broken.yaml", []byte("name: [\n"), 0o644))
+
+		loader, err := fuda.New().
+			WithFilesystem(memFs).
+			FromFiles("/base.yaml", "/broken.yaml").
+			Build()
+		require.NoError(t, err)
+
+		var cfg layeredConfig
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "/broken.yaml")
+	})
+}
+
+func TestNewLoader_FromFiles(t *testing.T) {
+	loader, err := fuda.NewLoader(
+		fuda.WithFilesystem(layeredFs(t)),
+		fuda.FromFiles("/base.yaml", "/prod.yaml"),
+	)
+	require.NoError(t, err)
+
+	var cfg layeredConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "db.prod.internal", cfg.Database.Host)
+}
@@ -0,0 +1,110 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fudaTagConfig struct {
+	Host     string `yaml:"host" fuda:"default=localhost,env=FUDATAG_HOST"`
+	Port     int    `yaml:"port" fuda:"default=8080,env=FUDATAG_PORT,validate='min=1,max=65535'"`
+	Name     string `yaml:"name" fuda:"required"`
+	Password string `yaml:"password" fuda:"default=changeme,secret"`
+	Mixed    string `yaml:"mixed" env:"FUDATAG_MIXED" fuda:"env=FUDATAG_IGNORED,default=mixed-default"`
+	Database struct {
+		User string `yaml:"user" fuda:"default=admin,validate='oneof=admin root'"`
+	} `yaml:"database"`
+}
+
+func TestFudaTag_Load(t *testing.T) {
+	t.Setenv("FUDATAG_PORT", "9090")
+	t.Setenv("FUDATAG_MIXED", "from-env")
+	t.Setenv("FUDATAG_IGNORED", "ignored")
+
+	var cfg fudaTagConfig
+	require.NoError(t, fuda.LoadBytes([]byte("name: app\n"), &cfg))
+
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, "changeme", cfg.Password)
+	assert.Equal(t, "from-env", cfg.Mixed, "separate env tag wins over the fuda tag")
+	assert.Equal(t, "admin", cfg.Database.User)
+}
+
+func TestFudaTag_Validation(t *testing.T) {
+	t.Setenv("FUDATAG_PORT", "70000")
+
+	var cfg fudaTagConfig
+	err := fuda.LoadBytes([]byte("database:\n  user: guest\n"), &cfg)
+	require.Error(t, err)
+
+	var verr *fuda.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 3)
+	assert.Contains(t, err.Error(), "field 'Port' (tag 'validate'): failed on the 'max=65535' rule")
+	assert.Contains(t, err.Error(), "field 'Name' (tag 'validate'): failed on the 'required' rule")
+	assert.Contains(t, err.Error(), "field 'Database.User' (tag 'validate'): failed on the 'oneof=admin root' rule")
+
+	cfg = fudaTagConfig{Port: 80, Name: "app"}
+	cfg.Database.User = "root"
+	require.NoError(t, fuda.Validate(&cfg))
+
+	cfg.Name = ""
+	err = fuda.Validate(&cfg)
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 1)
+	var ferr *fuda.FieldError
+	require.ErrorAs(t, verr.Errors[0], &ferr)
+	assert.Equal(t, "Name", ferr.Path)
+}
+
+func TestFudaTag_ValidateTagStillWins(t *testing.T) {
+	type config struct {
+		Port int `yaml:"port" validate:"min=10" fuda:"default=5,validate='min=1'"`
+	}
+
+	var cfg config
+	err := fuda.LoadBytes(nil, &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'min' tag")
+
+	// A plain validator error is returned unchanged by Validate
+	err = fuda.Validate(&cfg)
+	var verr *fuda.ValidationError
+	assert.False(t, errors.As(err, &verr))
+}
+
+func TestFudaTag_Malformed(t *testing.T) {
+	type config struct {
+		Port int `yaml:"port" fuda:"default=1,port=2"`
+	}
+
+	var cfg config
+	err := fuda.LoadBytes(nil, &cfg)
+	require.Error(t, err)
+
+	var ferr *fuda.FieldError
+	require.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "fuda", ferr.Tag)
+	assert.Contains(t, err.Error(), `unknown option "port=2"`)
+}
+
+func TestFudaTag_SchemaAndRedact(t *testing.T) {
+	schema, err := fuda.Schema(&fudaTagConfig{})
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), `"maximum": 65535`)
+	assert.Contains(t, string(schema), `"default": "localhost"`)
+	assert.Contains(t, string(schema), `"required": [`)
+
+	redacted, err := fuda.Redact(&fudaTagConfig{Password: "hunter2", Host: "h"})
+	require.NoError(t, err)
+	assert.Equal(t, "h", redacted["host"])
+	assert.NotEqual(t, "hunter2", redacted["password"])
+}
@@ -1,3 +1,5 @@
+//go:build !fuda_minimal
+
 package tests
 
 import (
@@ -0,0 +1,117 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookConfig struct {
+	Host string `yaml:"host" default:"LOCALHOST" validate:"lowercase"`
+	Port int    `yaml:"port" default:"8080"`
+}
+
+func TestWithHook_Order(t *testing.T) {
+	var calls []string
+	record := func(name string) fuda.Hook {
+		return func(_ context.Context, target any) error {
+			cfg := target.(*hookConfig)
+			calls = append(calls, name+":"+cfg.Host)
+
+			return nil
+		}
+	}
+
+	loader, err := fuda.New().
+		FromBytes([]byte("port: 9090\n")).
+		WithHook(fuda.AfterValidate, record("validated")).
+		WithHook(fuda.BeforeLoad, record("before")).
+		WithHook(fuda.AfterLoad, func(_ context.Context, target any) error {
+			cfg := target.(*hookConfig)
+			cfg.Host = strings.ToLower(cfg.Host)
+
+			return nil
+		}).
+		WithHook(fuda.AfterLoad, record("loaded")).
+		Build()
+	require.NoError(t, err)
+
+	var cfg hookConfig
+	require.NoError(t, loader.Load(&cfg), "AfterLoad normalizes before validation")
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, []string{"before:", "loaded:localhost", "validated:localhost"}, calls)
+}
+
+func TestWithHook_Errors(t *testing.T) {
+	boom := errors.New("boom")
+
+	t.Run("aborts load", func(t *testing.T) {
+		validated := false
+		loader, err := fuda.New().
+			WithHook(fuda.AfterLoad, func(context.Context, any) error { return boom }).
+			WithHook(fuda.AfterValidate, func(context.Context, any) error {
+				validated = true
+
+				return nil
+			}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg hookConfig
+		err = loader.Load(&cfg)
+		require.ErrorIs(t, err, boom)
+		assert.EqualError(t, err, "AfterLoad hook: boom")
+		assert.False(t, validated)
+	})
+
+	t.Run("not run after failed validation", func(t *testing.T) {
+		called := false
+		loader, err := fuda.New().
+			WithHook(fuda.AfterValidate, func(context.Context, any) error {
+				called = true
+
+				return nil
+			}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg hookConfig
+		var validationErr *fuda.ValidationError
+		require.ErrorAs(t, loader.Load(&cfg), &validationErr)
+		assert.False(t, called)
+	})
+
+	t.Run("invalid registration", func(t *testing.T) {
+		_, err := fuda.New().WithHook(fuda.HookPoint(7), func(context.Context, any) error { return nil }).Build()
+		require.EqualError(t, err, "unknown hook point HookPoint(7)")
+
+		_, err = fuda.New().WithHook(fuda.BeforeLoad, nil).Build()
+		require.EqualError(t, err, "nil BeforeLoad hook")
+	})
+}
+
+func TestWithHook_Context(t *testing.T) {
+	var deadline bool
+	loader, err := fuda.NewLoader(
+		fuda.WithTimeout(time.Minute),
+		fuda.WithHook(fuda.BeforeLoad, func(ctx context.Context, _ any) error {
+			_, deadline = ctx.Deadline()
+
+			return ctx.Err()
+		}),
+	)
+	require.NoError(t, err)
+
+	cfg := hookConfig{Host: "x"}
+	require.NoError(t, loader.Load(&cfg))
+	assert.True(t, deadline, "hooks receive the load context")
+}
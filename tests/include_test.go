@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type includeConfig struct {
+	Name     string `yaml:"name"`
+	Database struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port" default:"5432"`
+	} `yaml:"database"`
+	Logging struct {
+		Level  string `yaml:"level"`
+		Format string `yaml:"format"`
+	} `yaml:"logging"`
+}
+
+func includeFs(t *testing.T) afero.Fs {
+	t.Helper()
+
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/app/config.yaml": `
+name: app
+database: !include parts/database.yaml
+logging:
+  <<: !include /shared/logging.yaml
+  level: debug
+`,
+		"/app/parts/database.yaml": "host: ${INCLUDE_DB_HOST}\n",
+		"/shared/logging.yaml":     "level: info\nformat: json\n",
+		"/app/prod.yaml":           "database: !include parts/prod-db.yaml\n",
+		"/app/parts/prod-db.yaml":  "host: db.prod\nport: 6432\n",
+		"/app/loop.yaml":           "name: !include loop.yaml\n",
+	}
+	for name, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, name, []byte(content), 0o644))
+	}
+
+	return memFs
+}
+
+func TestWithIncludes(t *testing.T) {
+	t.Setenv("INCLUDE_DB_HOST", "db.local")
+
+	loader, err := fuda.New().
+		WithFilesystem(includeFs(t)).
+		FromFile("/app/config.yaml").
+		WithIncludes().
+		WithEnvExpansion().
+		Build()
+	require.NoError(t, err)
+
+	var cfg includeConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, "db.local", cfg.Database.Host, "included files are env-expanded")
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "debug", cfg.Logging.Level)
+	assert.Equal(t, "json", cfg.Logging.Format)
+}
+
+func TestWithIncludes_FromFiles(t *testing.T) {
+	t.Setenv("INCLUDE_DB_HOST", "db.local")
+
+	loader, err := fuda.NewLoader(
+		fuda.WithFilesystem(includeFs(t)),
+		fuda.FromFiles("/app/config.yaml", "/app/prod.yaml"),
+		fuda.WithIncludes(),
+	)
+	require.NoError(t, err)
+
+	var cfg includeConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "db.prod", cfg.Database.Host)
+	assert.Equal(t, 6432, cfg.Database.Port)
+	assert.Equal(t, "json", cfg.Logging.Format)
+}
+
+func TestWithIncludes_Cycle(t *testing.T) {
+	loader, err := fuda.New().
+		WithFilesystem(includeFs(t)).
+		FromFile("/app/loop.yaml").
+		WithIncludes().
+		Build()
+	require.NoError(t, err)
+
+	var cfg includeConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to resolve includes in /app/loop.yaml: include cycle: /app/loop.yaml -> /app/loop.yaml")
+}
+
+func TestWithIncludes_Disabled(t *testing.T) {
+	loader, err := fuda.New().
+		WithFilesystem(includeFs(t)).
+		FromFile("/app/prod.yaml").
+		Build()
+	require.NoError(t, err)
+
+	var cfg includeConfig
+	require.Error(t, loader.Load(&cfg), "!include is not resolved without WithIncludes")
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unsetEnv clears key for the duration of the test and restores it afterwards.
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	require.NoError(t, os.Unsetenv(key))
+}
+
+// TestWithIOFS_FileDotEnvAndRef verifies FromFile, dotenv, and file:// refs
+// all resolve against an io/fs filesystem.
+func TestWithIOFS_FileDotEnvAndRef(t *testing.T) {
+	unsetEnv(t, "IOFS_HOST")
+
+	fsys := fstest.MapFS{
+		"config/app.yaml":    {Data: []byte("port: 9090\n")},
+		"config/.env":        {Data: []byte("IOFS_HOST=embedded-host\n")},
+		"secrets/db":         {Data: []byte("embedded-secret")},
+		"api-token":          {Data: []byte("token-123")},
+		"unrelated/file.txt": {Data: []byte("ignored")},
+	}
+
+	type Config struct {
+		Host     string `env:"IOFS_HOST"`
+		Port     int    `yaml:"port"`
+		Password string `ref:"file:///secrets/db"`
+		Token    string `ref:"file://api-token"`
+	}
+
+	loader, err := fuda.New().
+		WithIOFS(fsys).
+		FromFile("config/app.yaml").
+		WithDotEnv("config/.env").
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "embedded-host", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "embedded-secret", cfg.Password)
+	assert.Equal(t, "token-123", cfg.Token)
+}
+
+// TestWithIOFS_DotEnvSearch verifies dotenv search paths use the io/fs filesystem.
+func TestWithIOFS_DotEnvSearch(t *testing.T) {
+	unsetEnv(t, "IOFS_SEARCH")
+
+	fsys := fstest.MapFS{
+		"deploy/.env": {Data: []byte("IOFS_SEARCH=found\n")},
+	}
+
+	type Config struct {
+		Value string `env:"IOFS_SEARCH" default:"missing"`
+	}
+
+	loader, err := fuda.New().
+		WithIOFS(fsys).
+		WithDotEnvSearch(".env", []string{".", "./deploy"}).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "found", cfg.Value)
+}
+
+// TestWithFilesystem_DotEnv verifies dotenv files are read from a custom afero filesystem.
+func TestWithFilesystem_DotEnv(t *testing.T) {
+	unsetEnv(t, "MEMFS_DOTENV")
+
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "/app/.env", []byte("MEMFS_DOTENV=from-memory\n"), 0o644))
+
+	type Config struct {
+		Value string `env:"MEMFS_DOTENV"`
+	}
+
+	loader, err := fuda.New().
+		WithFilesystem(memFs).
+		WithDotEnv("/app/.env").
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "from-memory", cfg.Value)
+}
+
+// TestNewIOFS_ReadOnly verifies the adapter rejects writes.
+func TestNewIOFS_ReadOnly(t *testing.T) {
+	fs := fuda.NewIOFS(fstest.MapFS{"a.txt": {Data: []byte("a")}})
+
+	data, err := afero.ReadFile(fs, "/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+
+	_, err = fs.Create("b.txt")
+	require.Error(t, err)
+}
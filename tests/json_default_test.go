@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonDefaultServer struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port" default:"80"`
+	MaxConns int           `yaml:"max_conns"`
+	Timeout  time.Duration `yaml:"timeout" default:"5s"`
+}
+
+type jsonDefaultConfig struct {
+	Servers   []jsonDefaultServer          `yaml:"servers" default:"[{\"host\":\"a\"},{\"host\":\"b\",\"port\":8080,\"max_conns\":10}]"`
+	Endpoints map[string]jsonDefaultServer `yaml:"endpoints" default:"{\"api\":{\"host\":\"api.local\",\"timeout\":\"1m\"}}"`
+	Limits    map[string][]int             `yaml:"limits" default:"{\"read\":[1,2],\"write\":[3]}"`
+	Tags      []string                     `yaml:"tags" default:"[a]"`
+}
+
+func TestJSONDefault(t *testing.T) {
+	t.Run("applied", func(t *testing.T) {
+		var cfg jsonDefaultConfig
+		require.NoError(t, fuda.SetDefaults(&cfg))
+
+		require.Len(t, cfg.Servers, 2)
+		assert.Equal(t, "a", cfg.Servers[0].Host)
+		assert.Equal(t, "b", cfg.Servers[1].Host)
+		assert.Equal(t, 8080, cfg.Servers[1].Port)
+		assert.Equal(t, 10, cfg.Servers[1].MaxConns, "keys should be yaml names")
+
+		require.Contains(t, cfg.Endpoints, "api")
+		assert.Equal(t, "api.local", cfg.Endpoints["api"].Host)
+		assert.Equal(t, time.Minute, cfg.Endpoints["api"].Timeout)
+
+		assert.Equal(t, map[string][]int{"read": {1, 2}, "write": {3}}, cfg.Limits)
+		assert.Equal(t, []string{"[a]"}, cfg.Tags, "invalid JSON should keep the CSV meaning")
+	})
+
+	t.Run("element defaults", func(t *testing.T) {
+		var cfg jsonDefaultConfig
+		require.NoError(t, fuda.SetDefaults(&cfg))
+
+		assert.Equal(t, 80, cfg.Servers[0].Port, "default tags of elements should apply")
+		assert.Equal(t, 5*time.Second, cfg.Servers[0].Timeout)
+		assert.Equal(t, 80, cfg.Endpoints["api"].Port)
+	})
+
+	t.Run("file wins", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte("servers: [{host: c}]")).Build()
+		require.NoError(t, err)
+
+		var cfg jsonDefaultConfig
+		require.NoError(t, loader.Load(&cfg))
+		require.Len(t, cfg.Servers, 1)
+		assert.Equal(t, "c", cfg.Servers[0].Host)
+		assert.Equal(t, 80, cfg.Servers[0].Port)
+		assert.Equal(t, "api.local", cfg.Endpoints["api"].Host)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		type envConfig struct {
+			Servers []jsonDefaultServer `env:"SERVERS"`
+		}
+		t.Setenv("SERVERS", `[{"host": "e"}]`)
+
+		var cfg envConfig
+		require.NoError(t, fuda.SetDefaults(&cfg))
+		require.Len(t, cfg.Servers, 1)
+		assert.Equal(t, "e", cfg.Servers[0].Host)
+		assert.Equal(t, 80, cfg.Servers[0].Port)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		type badConfig struct {
+			Servers []jsonDefaultServer `default:"[{\"hots\":\"a\"}]"`
+		}
+
+		var cfg badConfig
+		err := fuda.SetDefaults(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Servers")
+		assert.Contains(t, err.Error(), "hots")
+	})
+}
+
+func TestJSONDefault_Schema(t *testing.T) {
+	data, err := fuda.Schema(&jsonDefaultConfig{})
+	require.NoError(t, err)
+
+	var schema struct {
+		Properties map[string]struct {
+			Default any `json:"default"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, []any{
+		map[string]any{"host": "a"},
+		map[string]any{"host": "b", "port": float64(8080), "max_conns": float64(10)},
+	}, schema.Properties["servers"].Default)
+	assert.Equal(t, []any{"[a]"}, schema.Properties["tags"].Default)
+}
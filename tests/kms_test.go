@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reverseKMS "encrypts" by reversing bytes, standing in for a cloud KMS.
+var reverseKMS = fuda.KMSDecrypterFunc(func(_ context.Context, ciphertext []byte) ([]byte, error) {
+	plain := slices.Clone(ciphertext)
+	slices.Reverse(plain)
+
+	return plain, nil
+})
+
+func kmsEncrypt(plain string) string {
+	b := []byte(plain)
+	slices.Reverse(b)
+
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func TestWithKMS(t *testing.T) {
+	type Config struct {
+		Password string `yaml:"password" kms:"aws"`
+		Token    string `yaml:"token" env:"KMS_TOKEN" kms:"aws"`
+		Fallback string `yaml:"fallback" kms:"aws" default:"plain-default"`
+		Unset    string `yaml:"unset" kms:"aws"`
+	}
+
+	t.Setenv("KMS_TOKEN", kmsEncrypt("env-token"))
+
+	source := "password: " + kmsEncrypt("s3cret") + "\n"
+
+	loader, err := fuda.New().
+		FromBytes([]byte(source)).
+		WithKMS("aws", reverseKMS).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "s3cret", cfg.Password)
+	assert.Equal(t, "env-token", cfg.Token)
+	assert.Equal(t, "plain-default", cfg.Fallback, "defaults are not decrypted")
+	assert.Empty(t, cfg.Unset)
+
+	redacted, err := fuda.Redact(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED]", redacted["password"])
+}
+
+func TestWithKMS_Errors(t *testing.T) {
+	type Config struct {
+		Password string `yaml:"password" kms:"gcp"`
+	}
+
+	t.Run("unknown provider", func(t *testing.T) {
+		loader, err := fuda.New().
+			FromBytes([]byte("password: "+kmsEncrypt("x")+"\n")).
+			WithKMS("aws", reverseKMS).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no KMS decrypter registered for provider "gcp"`)
+	})
+
+	t.Run("invalid base64", func(t *testing.T) {
+		loader, err := fuda.New().
+			FromBytes([]byte("password: not-base64!\n")).
+			WithKMS("gcp", reverseKMS).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not valid base64")
+	})
+
+	t.Run("decrypt failure", func(t *testing.T) {
+		failing := fuda.KMSDecrypterFunc(func(context.Context, []byte) ([]byte, error) {
+			return nil, errors.New("access denied")
+		})
+		loader, err := fuda.New().
+			FromBytes([]byte("password: "+kmsEncrypt("x")+"\n")).
+			WithKMS("gcp", failing).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "access denied")
+	})
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		type IntConfig struct {
+			Port int `yaml:"port" kms:"gcp"`
+		}
+
+		loader, err := fuda.New().
+			FromBytes([]byte("port: 8080\n")).
+			WithKMS("gcp", reverseKMS).
+			Build()
+		require.NoError(t, err)
+
+		var cfg IntConfig
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires a string field")
+	})
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+func TestLoadContext(t *testing.T) {
+	type Config struct {
+		Host   string `yaml:"host" default:"localhost"`
+		Secret string `ref:"mem://secret"`
+	}
+
+	// blocking waits for ctx to end, reporting why.
+	blocking := resolverFunc(func(ctx context.Context, _ string) ([]byte, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+
+	t.Run("propagates caller context to resolvers", func(t *testing.T) {
+		resolver := resolverFunc(func(ctx context.Context, _ string) ([]byte, error) {
+			v, _ := ctx.Value(ctxKey{}).(string)
+
+			return []byte(v), nil
+		})
+		loader, err := fuda.New().WithRefResolver(resolver).Build()
+		require.NoError(t, err)
+
+		ctx := context.WithValue(t.Context(), ctxKey{}, "from-ctx")
+
+		var cfg Config
+		require.NoError(t, loader.LoadContext(ctx, &cfg))
+		assert.Equal(t, "from-ctx", cfg.Secret)
+		assert.Equal(t, "localhost", cfg.Host)
+	})
+
+	t.Run("canceled before load", func(t *testing.T) {
+		loader, err := fuda.New().WithRefResolver(blocking).Build()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		var cfg Config
+		err = loader.LoadContext(ctx, &cfg)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, cfg.Host, "no field should be processed")
+	})
+
+	t.Run("deadline interrupts ref resolution", func(t *testing.T) {
+		loader, err := fuda.New().WithRefResolver(blocking).Build()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		defer cancel()
+
+		var cfg Config
+		err = loader.LoadContext(ctx, &cfg)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled while resolving", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		resolver := resolverFunc(func(ctx context.Context, _ string) ([]byte, error) {
+			cancel()
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		})
+		loader, err := fuda.New().WithRefResolver(resolver).WithRefRetry(5, time.Hour).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.LoadContext(ctx, &cfg)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("shorter builder timeout wins", func(t *testing.T) {
+		loader, err := fuda.New().
+			WithRefResolver(blocking).
+			WithTimeout(10 * time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.LoadContext(t.Context(), &cfg)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,161 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type loadErrorsConfig struct {
+	Name   string `yaml:"name" default:"app"`
+	Server struct {
+		Port    int           `yaml:"port" default:"not-a-port"`
+		Timeout time.Duration `yaml:"timeout" env:"LE_TIMEOUT"`
+	} `yaml:"server"`
+	Password string `yaml:"password" ref:"vault:///secret/password"`
+	Region   string `yaml:"region" env:"LE_REGION,required"`
+	Replicas []struct {
+		Weight int `yaml:"weight" default:"heavy"`
+	} `yaml:"replicas"`
+}
+
+func TestLoad_CollectsFieldErrors(t *testing.T) {
+	t.Setenv("LE_TIMEOUT", "soon")
+
+	loader, err := fuda.New().
+		FromBytes([]byte("replicas:\n  - {}\n")).
+		WithResolver("vault", resolverFunc(func(context.Context, string) ([]byte, error) {
+			return nil, errors.New("vault sealed")
+		})).
+		Build()
+	require.NoError(t, err)
+
+	var cfg loadErrorsConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+
+	var loadErr *fuda.LoadError
+	require.ErrorAs(t, err, &loadErr)
+
+	type entry struct{ path, tag string }
+	got := make([]entry, 0, len(loadErr.Errors))
+	for _, fe := range loadErr.Errors {
+		got = append(got, entry{fe.Path, fe.Tag})
+	}
+	assert.Equal(t, []entry{
+		{"Server.Port", "default"},
+		{"Server.Timeout", "env"},
+		{"Password", "ref"},
+		{"Region", "env"},
+		{"Replicas[0].Weight", "default"},
+	}, got)
+
+	// Fields without errors are still loaded
+	assert.Equal(t, "app", cfg.Name)
+
+	// errors.As finds the field errors through the LoadError
+	var fieldErr *fuda.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Server.Port", fieldErr.Path)
+}
+
+func TestLoad_FieldErrorSources(t *testing.T) {
+	t.Setenv("LES_PORT", "eighty")
+	t.Setenv("LES_LIMIT_CPU", "lots")
+
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.String("workers", "", "")
+	require.NoError(t, flags.Parse([]string{"--workers=many"}))
+
+	loader, err := fuda.New().
+		FromBytes([]byte("{}")).
+		WithFlagSet(flags).
+		WithResolver("vault", resolverFunc(func(context.Context, string) ([]byte, error) {
+			return nil, errors.New("vault sealed")
+		})).
+		Build()
+	require.NoError(t, err)
+
+	var cfg struct {
+		Port     int            `env:"LES_PORT"`
+		Limits   map[string]int `env:"LES_LIMIT_*"`
+		Workers  int            `flag:"workers"`
+		Password string         `ref:"vault:///secret/db#password"`
+		Timeout  time.Duration  `default:"soon"`
+	}
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+
+	type entry struct{ path, tag, source string }
+	var got []entry
+	for _, fe := range fuda.FieldErrors(err) {
+		got = append(got, entry{fe.Path, fe.Tag, fe.Source})
+	}
+	assert.Equal(t, []entry{
+		{"Port", "env", "LES_PORT"},
+		{"Limits", "env", "LES_LIMIT_CPU"},
+		{"Workers", "flag", "--workers"},
+		{"Password", "ref", "vault:///secret/db#password"},
+		{"Timeout", "default", ""},
+	}, got)
+
+	assert.Contains(t, err.Error(), "field 'Port' (tag 'env', source 'LES_PORT'): ")
+	assert.Contains(t, err.Error(), "field 'Password' (tag 'ref', source 'vault:///secret/db#password'): failed to resolve: vault sealed")
+	assert.Contains(t, err.Error(), "field 'Timeout' (tag 'default'): ")
+}
+
+func TestFieldErrors(t *testing.T) {
+	assert.Nil(t, fuda.FieldErrors(nil))
+	assert.Nil(t, fuda.FieldErrors(errors.New("plain")))
+
+	fe := &fuda.FieldError{Path: "Port", Tag: "env", Source: "PORT", Message: "bad"}
+	assert.Equal(t, []*fuda.FieldError{fe}, fuda.FieldErrors(fe))
+	assert.Equal(t, "field 'Port' (tag 'env', source 'PORT'): bad", fe.Error())
+
+	t.Run("validation", func(t *testing.T) {
+		var cfg struct {
+			Port int    `fuda:"default=0,validate='min=1'"`
+			Name string `fuda:"required"`
+		}
+		err := fuda.LoadBytes([]byte("{}"), &cfg)
+		require.Error(t, err)
+
+		var validationErr *fuda.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+
+		var paths []string
+		for _, fe := range fuda.FieldErrors(err) {
+			paths = append(paths, fe.Path)
+		}
+		assert.Equal(t, []string{"Port", "Name"}, paths)
+
+		// errors.As reaches every error of a ValidationError, not just the first
+		var fieldErr *fuda.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "validate", fieldErr.Tag)
+	})
+}
+
+func TestLoad_CanceledLoadIsNotCollected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+
+	var cfg loadErrorsConfig
+	err = loader.LoadContext(ctx, &cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	var loadErr *fuda.LoadError
+	assert.False(t, errors.As(err, &loadErr))
+}
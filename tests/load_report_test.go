@@ -0,0 +1,90 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type warnConfig struct {
+	Host string   `yaml:"host" validate:"required"`
+	Port int      `yaml:"port" validate:"max=65535"`
+	Pool selfPool `yaml:"pool"`
+}
+
+func TestValidationMode_Warn(t *testing.T) {
+	afterValidate := false
+	loader, err := fuda.New().
+		FromBytes([]byte("port: 70000\npool: {min: 3, max: 1}")).
+		WithValidationMode(fuda.ValidationWarn).
+		WithHook(fuda.AfterValidate, func(context.Context, any) error {
+			afterValidate = true
+
+			return nil
+		}).
+		Build()
+	require.NoError(t, err)
+
+	var cfg warnConfig
+	report, err := loader.LoadWithReport(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 70000, cfg.Port, "the config should be loaded despite the errors")
+	assert.True(t, afterValidate)
+
+	require.NotNil(t, report.Warnings)
+	require.Len(t, report.Warnings.Errors, 2, "tag errors, then the Validate method error")
+	assert.Contains(t, report.Warnings.Error(), "'required' tag")
+	assert.Contains(t, report.Warnings.Error(), "'max' tag")
+	assert.Contains(t, report.Warnings.Error(), "field 'Pool': min 3 exceeds max 1")
+
+	// Load ignores the errors
+	require.NoError(t, loader.Load(&warnConfig{}))
+}
+
+func TestValidationMode_WarnValid(t *testing.T) {
+	loader, err := fuda.NewLoader(
+		fuda.FromBytes([]byte("host: db\nport: 5432")),
+		fuda.WithValidationMode(fuda.ValidationWarn),
+	)
+	require.NoError(t, err)
+
+	var cfg warnConfig
+	report, err := loader.LoadWithReportContext(context.Background(), &cfg)
+	require.NoError(t, err)
+	assert.Nil(t, report.Warnings)
+}
+
+func TestValidationMode_WarnKeepsLoadErrors(t *testing.T) {
+	loader, err := fuda.New().
+		FromBytes([]byte("port: not-a-number")).
+		WithValidationMode(fuda.ValidationWarn).
+		Build()
+	require.NoError(t, err)
+
+	var cfg warnConfig
+	report, err := loader.LoadWithReport(&cfg)
+	require.Error(t, err)
+	assert.Nil(t, report)
+}
+
+func TestValidationMode_Strict(t *testing.T) {
+	loader, err := fuda.New().FromBytes([]byte("port: 80")).Build()
+	require.NoError(t, err)
+
+	var cfg warnConfig
+	report, err := loader.LoadWithReport(&cfg)
+	require.Error(t, err)
+	assert.Nil(t, report)
+
+	var verr *fuda.ValidationError
+	require.ErrorAs(t, err, &verr)
+
+	_, err = fuda.New().FromBytes([]byte("{}")).WithValidationMode(fuda.ValidationMode(7)).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown validation mode 7")
+}
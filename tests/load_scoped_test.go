@@ -0,0 +1,73 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scopeKey marks the context returned by scopedResolver.BeginLoad.
+type scopeKey struct{}
+
+// scopedResolver counts loads and checks that Resolve sees the load scope.
+type scopedResolver struct {
+	loads    atomic.Int32
+	unscoped atomic.Int32
+}
+
+func (r *scopedResolver) BeginLoad(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, r.loads.Add(1))
+}
+
+func (r *scopedResolver) Resolve(ctx context.Context, _ string) ([]byte, error) {
+	if ctx.Value(scopeKey{}) == nil {
+		r.unscoped.Add(1)
+	}
+
+	return []byte("value"), nil
+}
+
+var _ fuda.LoadScopedResolver = (*scopedResolver)(nil)
+
+func TestLoadScopedResolver(t *testing.T) {
+	type Config struct {
+		A string `ref:"corp://a"`
+		B string `ref:"corp://b"`
+		C string `dsn:"${ref:corp://c}"`
+	}
+
+	tests := []struct {
+		name  string
+		build func(r *scopedResolver) *fuda.Builder
+	}{
+		{"WithRefResolver", func(r *scopedResolver) *fuda.Builder { return fuda.New().WithRefResolver(r) }},
+		{"WithResolver", func(r *scopedResolver) *fuda.Builder { return fuda.New().WithResolver("corp", r) }},
+		{"WithRefConcurrency", func(r *scopedResolver) *fuda.Builder {
+			return fuda.New().WithResolver("corp", r).WithRefConcurrency(4)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &scopedResolver{}
+			loader, err := tt.build(r).Build()
+			require.NoError(t, err)
+
+			for range 2 {
+				var cfg Config
+				require.NoError(t, loader.Load(&cfg))
+				assert.Equal(t, "value", cfg.A)
+				assert.Equal(t, "value", cfg.C)
+			}
+
+			assert.Equal(t, int32(2), r.loads.Load(), "BeginLoad once per Load")
+			assert.Zero(t, r.unscoped.Load(), "every Resolve sees the load scope")
+		})
+	}
+}
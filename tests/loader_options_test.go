@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLoader(t *testing.T) {
+	type Config struct {
+		Host   string `yaml:"host"`
+		Region string `env:"REGION"`
+		Secret string `ref:"corp://secret"`
+		Port   int    `yaml:"port" validate:"min=1"`
+	}
+
+	corp := fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+		return []byte("s3cret"), nil
+	})
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/app.yaml", []byte("host: from-file\nport: 80\n"), 0o644))
+	t.Setenv("ACME_REGION", "eu-west-1")
+
+	t.Run("applies options in order", func(t *testing.T) {
+		opts := []fuda.LoaderOption{
+			fuda.WithFilesystem(fs),
+			fuda.FromFile("/etc/app.yaml"),
+			fuda.WithEnvPrefix("ACME_"),
+		}
+		opts = append(opts, fuda.WithResolver("corp", corp), nil)
+
+		loader, err := fuda.NewLoader(opts...)
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, Config{Host: "from-file", Region: "eu-west-1", Secret: "s3cret", Port: 80}, cfg)
+	})
+
+	t.Run("options work with Builder.Apply", func(t *testing.T) {
+		overrides := fuda.WithOverrides(map[string]any{"port": 8080})
+		resolver := fuda.WithResolver("corp", corp)
+
+		fromOpts, err := fuda.NewLoader(fuda.FromBytes([]byte("port: 80\n")), overrides, resolver)
+		require.NoError(t, err)
+		fromBuilder, err := fuda.New().FromBytes([]byte("port: 80\n")).Apply(overrides).Apply(resolver).Build()
+		require.NoError(t, err)
+
+		var a, b Config
+		require.NoError(t, fromOpts.Load(&a))
+		require.NoError(t, fromBuilder.Load(&b))
+		assert.Equal(t, 8080, a.Port)
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("reports option errors", func(t *testing.T) {
+		_, err := fuda.NewLoader(fuda.WithFilesystem(fs), fuda.FromFile("/missing.yaml"))
+		require.Error(t, err)
+
+		_, err = fuda.NewLoader(fuda.WithAgeIdentity("not-an-identity"))
+		require.ErrorContains(t, err, "invalid age identity")
+	})
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type logctxBuild struct {
+	Commit string    `yaml:"commit"`
+	Date   time.Time `yaml:"date"`
+	Debug  bool      `yaml:"debug" logctx:"false"`
+}
+
+type logctxConfig struct {
+	Version string `yaml:"version" logctx:"true"`
+	Name    string `yaml:"name" fuda:"default=billing,logctx"`
+	Port    int    `yaml:"port"`
+	Cluster struct {
+		Region string `yaml:"region" logctx:"true"`
+		Name   string `yaml:"name" logctx:"cluster"`
+		Zones  []string
+	} `yaml:"cluster"`
+	Build    logctxBuild   `yaml:"build" logctx:"true"`
+	Timeout  time.Duration `yaml:"timeout" logctx:"true"`
+	Token    string        `yaml:"token" logctx:"true" secret:"true"`
+	Empty    string        `yaml:"empty" logctx:"true" secret:"true"`
+	Optional *struct {
+		Tier string `yaml:"tier" logctx:"true"`
+	} `yaml:"optional"`
+}
+
+func newLogctxConfig() *logctxConfig {
+	cfg := &logctxConfig{Version: "1.4.2", Name: "billing", Port: 8080, Timeout: 5 * time.Second, Token: "s3cret"}
+	cfg.Cluster.Region = "eu-west-1"
+	cfg.Cluster.Name = "prod-7"
+	cfg.Build = logctxBuild{Commit: "abc123", Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Debug: true}
+
+	return cfg
+}
+
+func TestLogFields(t *testing.T) {
+	fields, err := fuda.LogFields(newLogctxConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"version":        "1.4.2",
+		"name":           "billing",
+		"cluster.region": "eu-west-1",
+		"cluster":        "prod-7",
+		"build.commit":   "abc123",
+		"build.date":     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		"timeout":        5 * time.Second,
+		"token":          "[REDACTED]",
+		"empty":          "",
+	}, fields)
+}
+
+func TestLogAttrs(t *testing.T) {
+	cfg := newLogctxConfig()
+	cfg.Optional = &struct {
+		Tier string `yaml:"tier" logctx:"true"`
+	}{Tier: "gold"}
+
+	attrs, err := fuda.LogAttrs(*cfg)
+	require.NoError(t, err)
+
+	keys := make([]string, len(attrs))
+	for i, attr := range attrs {
+		keys[i] = attr.Key
+	}
+	assert.Equal(t, []string{
+		"version", "name", "cluster.region", "cluster", "build.commit", "build.date",
+		"timeout", "token", "empty", "optional.tier",
+	}, keys)
+	assert.Equal(t, slog.KindDuration, attrs[6].Value.Kind())
+	assert.Equal(t, "gold", attrs[9].Value.String())
+}
+
+func TestLogFields_Errors(t *testing.T) {
+	_, err := fuda.LogFields(42)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "struct or pointer to struct")
+
+	var duplicate struct {
+		Region string `yaml:"region" logctx:"true"`
+		Zone   string `yaml:"zone" logctx:"region"`
+	}
+	_, err = fuda.LogFields(&duplicate)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `field 'zone' (tag 'logctx'): key "region" is also used by 'region'`)
+}
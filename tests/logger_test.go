@@ -0,0 +1,92 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDebugLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestWithLogger(t *testing.T) {
+	type Config struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" ref:"secret://db/password" default:"changeme"`
+		Token    string `ref:"secret://api/token"`
+		APIKey   string `ref:"secret://api/key"`
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: db.local"), 0o644))
+
+	var buf bytes.Buffer
+	loader, err := fuda.New().
+		WithFilesystem(fs).
+		FromFile("/config.yaml").
+		WithDotEnvFiles([]string{"/.env.local"}).
+		WithResolver("secret", fuda.RefResolverFunc(func(_ context.Context, uri string) ([]byte, error) {
+			if uri == "secret://api/key" {
+				return []byte("k3y"), nil
+			}
+
+			return nil, os.ErrNotExist
+		})).
+		WithLogger(newDebugLogger(&buf)).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="config document read" source=/config.yaml`)
+	assert.Contains(t, out, `msg="dotenv file skipped" file=/.env.local`)
+	assert.Contains(t, out, `msg="ref not found, using default" field=Password`)
+	assert.Contains(t, out, `msg="ref not found, leaving field unset" field=Token`)
+	assert.NotContains(t, out, "APIKey", "resolved refs are not fallbacks")
+	assert.NotContains(t, out, "k3y")
+	assert.NotContains(t, out, "changeme")
+}
+
+func TestWithLogger_ValidationWarn(t *testing.T) {
+	type Config struct {
+		Port int `yaml:"port" validate:"min=1"`
+	}
+
+	var buf bytes.Buffer
+	loader, err := fuda.New().
+		FromBytes([]byte("port: 0")).
+		WithValidationMode(fuda.ValidationWarn).
+		WithLogger(newDebugLogger(&buf)).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Contains(t, buf.String(), `msg="validation errors ignored"`)
+
+	buf.Reset()
+	report, err := loader.LoadWithReport(&cfg)
+	require.NoError(t, err)
+	require.NotNil(t, report.Warnings)
+	assert.NotContains(t, buf.String(), "validation errors ignored", "reported warnings are not suppressed")
+}
+
+func TestWithLogger_Nil(t *testing.T) {
+	loader, err := fuda.New().FromBytes([]byte("host: a")).WithLogger(nil).Build()
+	require.NoError(t, err)
+
+	var cfg reloadConfig
+	require.NoError(t, loader.Load(&cfg))
+}
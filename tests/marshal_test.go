@@ -0,0 +1,68 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	type Database struct {
+		Host     string `yaml:"host" default:"localhost" doc:"Database hostname"`
+		Password string `yaml:"password" secret:"true"`
+		URL      string `yaml:"url" dsn:"postgres://{{.Host}}/app"`
+	}
+	type Config struct {
+		Name     string        `yaml:"name" doc:"Service name.\nShown in logs and metrics."`
+		Port     int           `yaml:"port" default:"8080" doc:"HTTP listen port"`
+		Timeout  time.Duration `yaml:"timeout" default:"30s"`
+		Debug    bool          `yaml:"debug"`
+		Tags     []string      `yaml:"tags"`
+		Database Database      `yaml:"database" doc:"Primary database"`
+	}
+
+	loader, err := fuda.New().FromBytes([]byte("name: api\ndatabase:\n  password: s3cret\n")).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	out, err := fuda.Marshal(&cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# Service name.
+# Shown in logs and metrics.
+name: api
+# HTTP listen port
+port: 8080
+timeout: 30s
+debug: false
+tags: []
+# Primary database
+database:
+    # Database hostname
+    host: localhost
+`, string(out))
+	assert.NotContains(t, string(out), "s3cret")
+
+	t.Run("round trips through Load", func(t *testing.T) {
+		var reloaded Config
+		require.NoError(t, fuda.LoadBytes(out, &reloaded))
+
+		assert.Equal(t, cfg.Name, reloaded.Name)
+		assert.Equal(t, cfg.Port, reloaded.Port)
+		assert.Equal(t, cfg.Timeout, reloaded.Timeout)
+		assert.Equal(t, cfg.Database.Host, reloaded.Database.Host)
+	})
+}
+
+func TestMarshal_NotStruct(t *testing.T) {
+	out, err := fuda.Marshal(map[string]int{"b": 2, "a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1\nb: 2\n", string(out))
+}
@@ -0,0 +1,114 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refObservation is one ref resolution seen by recordingMetrics.
+type refObservation struct {
+	scheme string
+	err    error
+}
+
+// recordingMetrics is a fuda.Metrics that records what it receives.
+type recordingMetrics struct {
+	mu      sync.Mutex
+	refs    []refObservation
+	reloads []error
+}
+
+func (m *recordingMetrics) RefResolved(scheme string, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refs = append(m.refs, refObservation{scheme: scheme, err: err})
+}
+
+func (m *recordingMetrics) Reloaded(_ time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reloads = append(m.reloads, err)
+}
+
+func TestWithMetrics_Refs(t *testing.T) {
+	type Config struct {
+		Token  string `ref:"secret://app/token"`
+		Backup string `ref:"broken://app/backup" default:"none"`
+	}
+
+	metrics := &recordingMetrics{}
+	loader, err := fuda.New().
+		FromBytes([]byte("{}")).
+		WithResolver("secret", fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+			return []byte("s3cr3t"), nil
+		})).
+		WithResolver("broken", fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+			return nil, errors.New("unavailable")
+		})).
+		WithMetrics(metrics).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.Error(t, loader.Load(&cfg))
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	require.Len(t, metrics.refs, 2)
+	byScheme := map[string]error{}
+	for _, ref := range metrics.refs {
+		byScheme[ref.scheme] = ref.err
+	}
+	require.Contains(t, byScheme, "secret")
+	require.NoError(t, byScheme["secret"])
+	require.Error(t, byScheme["broken"])
+	assert.Empty(t, metrics.reloads, "Load is not a reload")
+}
+
+func TestWithMetrics_ReloadInto(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: a"), 0o644))
+
+	metrics := &recordingMetrics{}
+	loader, err := fuda.NewLoader(
+		fuda.WithFilesystem(fs),
+		fuda.FromFile("/config.yaml"),
+		fuda.WithMetrics(metrics),
+	)
+	require.NoError(t, err)
+
+	var cfg reloadConfig
+	require.NoError(t, loader.ReloadInto(&cfg))
+
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("port: 1"), 0o644))
+	require.Error(t, loader.ReloadInto(&cfg))
+
+	require.NoError(t, fs.Remove("/config.yaml"))
+	require.Error(t, loader.ReloadInto(&cfg))
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	require.Len(t, metrics.reloads, 3)
+	require.NoError(t, metrics.reloads[0])
+	require.Error(t, metrics.reloads[1], "a config failing validation is a failed reload")
+	require.Error(t, metrics.reloads[2])
+}
+
+func TestWithMetrics_Nil(t *testing.T) {
+	_, err := fuda.New().FromBytes([]byte("{}")).WithMetrics(nil).Build()
+	require.Error(t, err)
+}
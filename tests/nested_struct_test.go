@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -467,3 +468,36 @@ database:
 	assert.Equal(t, "db.local", cfg.Database.Host)
 	assert.Equal(t, "secret123", cfg.Database.Password, "Password should be resolved from file")
 }
+
+func TestRefFrom_NestedPath(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/certs/server.pem", []byte("server-cert"), 0o600))
+	require.NoError(t, afero.WriteFile(fs, "/certs/client.pem", []byte("client-cert"), 0o600))
+
+	type TLSConfig struct {
+		CertPath string `yaml:"cert_path"`
+	}
+	type Config struct {
+		TLS        TLSConfig  `yaml:"tls"`
+		Client     *TLSConfig `yaml:"client"`
+		Cert       string     `yaml:"cert" refFrom:"TLS.CertPath"`
+		ClientCert string     `yaml:"client_cert" refFrom:"Client.CertPath" ref:"file:///certs/server.pem"`
+	}
+
+	load := func(yamlContent string) Config {
+		var cfg Config
+		loader, err := fuda.New().FromBytes([]byte(yamlContent)).WithFilesystem(fs).Build()
+		require.NoError(t, err)
+		require.NoError(t, loader.Load(&cfg))
+
+		return cfg
+	}
+
+	cfg := load("tls:\n  cert_path: /certs/server.pem\nclient:\n  cert_path: /certs/client.pem\n")
+	assert.Equal(t, "server-cert", cfg.Cert)
+	assert.Equal(t, "client-cert", cfg.ClientCert)
+
+	cfg = load("tls:\n  cert_path: /certs/server.pem\n")
+	assert.Nil(t, cfg.Client)
+	assert.Equal(t, "server-cert", cfg.ClientCert, "nil pointer on the path falls back to ref")
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type objectStoreConfig struct {
+	Host   string `yaml:"host" default:"localhost"`
+	Port   int    `yaml:"port"`
+	Secret string `yaml:"secret" ref:"s3://acme/secrets/token"`
+}
+
+// objectStore serves objects from memory, keyed by URI.
+func objectStore(objects map[string]string, calls *atomic.Int32) fuda.RefResolverFunc {
+	return func(_ context.Context, uri string) ([]byte, error) {
+		calls.Add(1)
+		data, ok := objects[uri]
+		if !ok {
+			return nil, errors.New("no such key")
+		}
+
+		return []byte(data), nil
+	}
+}
+
+func TestFromObjectStore(t *testing.T) {
+	objects := map[string]string{
+		"s3://acme/app/config.yaml": "port: 9000\n",
+		"s3://acme/secrets/token":   "s3cr3t",
+	}
+	var calls atomic.Int32
+	loader, err := fuda.New().
+		FromObjectStore("s3://acme/app/config.yaml").
+		WithResolver("s3", objectStore(objects, &calls)).
+		Build()
+	require.NoError(t, err)
+
+	var cfg objectStoreConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, "s3cr3t", cfg.Secret, "refs use the same resolver")
+
+	// The document is fetched again at every load
+	objects["s3://acme/app/config.yaml"] = "port: 9001\n"
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, 9001, cfg.Port)
+}
+
+func TestFromObjectStore_RegisteredResolver(t *testing.T) {
+	var calls atomic.Int32
+	fuda.RegisterResolver("gs", objectStore(map[string]string{
+		"gs://acme/app.yaml": "host: db.internal\n",
+	}, &calls))
+	t.Cleanup(func() { fuda.UnregisterResolver("gs") })
+
+	type config struct {
+		Host string `yaml:"host"`
+	}
+
+	loader, err := fuda.NewLoader(fuda.FromObjectStore("gs://acme/app.yaml"))
+	require.NoError(t, err)
+
+	var cfg config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "db.internal", cfg.Host)
+}
+
+func TestFromObjectStore_Errors(t *testing.T) {
+	_, err := fuda.New().FromObjectStore("config.yaml").Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FromObjectStore requires a URI with a scheme")
+
+	var calls atomic.Int32
+	loader, err := fuda.New().
+		FromObjectStore("s3://acme/missing.yaml").
+		WithResolver("s3", objectStore(nil, &calls)).
+		Build()
+	require.NoError(t, err)
+
+	var cfg objectStoreConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch s3://acme/missing.yaml: no such key")
+
+	// A scheme without a resolver fails at load time
+	loader, err = fuda.New().FromObjectStore("azblob://acme/app.yaml").Build()
+	require.NoError(t, err)
+	require.Error(t, loader.Load(&cfg))
+}
+
+func TestFromObjectStore_ReplacedByOtherSource(t *testing.T) {
+	loader, err := fuda.New().
+		FromObjectStore("s3://acme/app/config.yaml").
+		FromBytes([]byte("port: 1234\n")).
+		Build()
+	require.NoError(t, err)
+
+	type config struct {
+		Port int `yaml:"port"`
+	}
+
+	var cfg config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, 1234, cfg.Port)
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type middlewareConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Timeout int    `yaml:"timeout" default:"30"`
+	Token   string `yaml:"token" secret:"true"`
+}
+
+type pipelineConfig struct {
+	Middleware fuda.OrderedMap[string, middlewareConfig] `yaml:"middleware"`
+	Weights    fuda.OrderedMap[string, int]              `yaml:"weights" default:"zeta:3,alpha:1,mid:2"`
+}
+
+const pipelineYAML = `middleware:
+  ratelimit:
+    timeout: 5
+  auth:
+    token: s3cret
+  gzip:
+    enabled: true
+`
+
+func TestOrderedMap_Load(t *testing.T) {
+	var cfg pipelineConfig
+	require.NoError(t, fuda.LoadBytes([]byte(pipelineYAML), &cfg))
+
+	assert.Equal(t, []string{"ratelimit", "auth", "gzip"}, cfg.Middleware.Keys())
+	auth, ok := cfg.Middleware.Get("auth")
+	require.True(t, ok)
+	assert.Equal(t, middlewareConfig{Timeout: 30, Token: "s3cret"}, auth, "defaults apply to the values")
+	gzip, _ := cfg.Middleware.Get("gzip")
+	assert.True(t, gzip.Enabled)
+	rl, _ := cfg.Middleware.Get("ratelimit")
+	assert.Equal(t, 5, rl.Timeout)
+
+	assert.Equal(t, []string{"zeta", "alpha", "mid"}, cfg.Weights.Keys(), "the default tag keeps its order")
+	var sum int
+	for _, w := range cfg.Weights.All() {
+		sum += w
+	}
+	assert.Equal(t, 6, sum)
+}
+
+func TestOrderedMap_EnvAndLayers(t *testing.T) {
+	t.Setenv("PIPELINE_WEIGHTS", "b:2,a:1")
+
+	type Config struct {
+		Weights fuda.OrderedMap[string, int] `yaml:"weights" env:"PIPELINE_WEIGHTS"`
+		Stages  fuda.OrderedMap[string, int] `yaml:"stages"`
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/base.yaml", []byte("stages:\n  build: 1\n  test: 2\n  deploy: 3\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/prod.yaml", []byte("stages:\n  test: 20\n  verify: 4\n"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFiles("/base.yaml", "/prod.yaml").Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, fuda.OrderedMap[string, int]{{Key: "b", Value: 2}, {Key: "a", Value: 1}}, cfg.Weights)
+	assert.Equal(t, fuda.OrderedMap[string, int]{
+		{Key: "build", Value: 1}, {Key: "test", Value: 20}, {Key: "deploy", Value: 3}, {Key: "verify", Value: 4},
+	}, cfg.Stages, "later layers override in place and append new keys")
+}
+
+func TestOrderedMap_Dump(t *testing.T) {
+	var cfg pipelineConfig
+	require.NoError(t, fuda.LoadBytes([]byte(pipelineYAML), &cfg))
+
+	var buf bytes.Buffer
+	require.NoError(t, fuda.DumpRedacted(&buf, &cfg))
+	out := buf.String()
+	assert.Contains(t, out, "token: '[REDACTED]'")
+	assert.NotContains(t, out, "s3cret")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("ratelimit:")), bytes.Index(buf.Bytes(), []byte("auth:")))
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("auth:")), bytes.Index(buf.Bytes(), []byte("gzip:")))
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("zeta:")), bytes.Index(buf.Bytes(), []byte("alpha:")))
+
+	out2, err := yaml.Marshal(cfg.Weights)
+	require.NoError(t, err)
+	assert.Equal(t, "zeta: 3\nalpha: 1\nmid: 2\n", string(out2))
+}
+
+func TestOrderedMap_JSON(t *testing.T) {
+	var m fuda.OrderedMap[string, int]
+	require.NoError(t, json.Unmarshal([]byte(`{"z": 1, "a": 2, "m": 3}`), &m))
+	assert.Equal(t, []string{"z", "a", "m"}, m.Keys())
+
+	m.Set("a", 20)
+	m.Set("b", 4)
+	m.Delete("z")
+	out, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": 20, "m": 3, "b": 4}`, string(out))
+	assert.Equal(t, `{"a":20,"m":3,"b":4}`, string(out))
+
+	ints := fuda.OrderedMap[int, string]{{Key: 2, Value: "two"}, {Key: 1, Value: "one"}}
+	out, err = json.Marshal(ints)
+	require.NoError(t, err)
+	assert.Equal(t, `{"2":"two","1":"one"}`, string(out))
+
+	var nilMap fuda.OrderedMap[string, int]
+	out, err = json.Marshal(nilMap)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(out))
+}
+
+func TestOrderedMap_YAMLFeatures(t *testing.T) {
+	var m fuda.OrderedMap[string, int]
+	src := "base: &base\n  a: 1\n  b: 2\nm:\n  c: 3\n  <<: *base\n  a: 10\n"
+	var doc struct {
+		M fuda.OrderedMap[string, int] `yaml:"m"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(src), &doc))
+	assert.Equal(t, fuda.OrderedMap[string, int]{{Key: "c", Value: 3}, {Key: "b", Value: 2}, {Key: "a", Value: 10}}, doc.M)
+
+	err := yaml.Unmarshal([]byte("a: 1\nb: 2\na: 3\n"), &m)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 3: mapping key "a" already defined`)
+
+	err = yaml.Unmarshal([]byte("- a\n- b\n"), &m)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a mapping")
+}
+
+func TestOrderedMap_StrictKeys(t *testing.T) {
+	loader, err := fuda.New().
+		FromBytes([]byte("middleware:\n  auth:\n    timout: 5\n")).
+		WithStrictKeys().
+		Build()
+	require.NoError(t, err)
+
+	var cfg pipelineConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "middleware.auth.timout")
+	assert.Contains(t, err.Error(), `did you mean "timeout"?`)
+}
+
+func TestOrderedMap_Diff(t *testing.T) {
+	before := pipelineConfig{Middleware: fuda.OrderedMap[string, middlewareConfig]{
+		{Key: "auth", Value: middlewareConfig{Timeout: 1, Token: "old"}},
+		{Key: "gzip", Value: middlewareConfig{Timeout: 1}},
+	}}
+	after := pipelineConfig{Middleware: fuda.OrderedMap[string, middlewareConfig]{
+		{Key: "gzip", Value: middlewareConfig{Timeout: 2}},
+		{Key: "auth", Value: middlewareConfig{Timeout: 1, Token: "new"}},
+		{Key: "cors", Value: middlewareConfig{}},
+	}}
+
+	changes := fuda.Diff(before, after)
+	require.Len(t, changes, 4)
+	assert.Equal(t, fuda.FieldChange{Path: "middleware.auth.token", Old: "[REDACTED]", New: "[REDACTED]", Redacted: true}, changes[0])
+	assert.Equal(t, fuda.FieldChange{Path: "middleware.gzip.timeout", Old: 1, New: 2}, changes[1])
+	assert.Equal(t, "middleware.cors", changes[2].Path)
+	assert.Equal(t, fuda.FieldChange{Path: "middleware", Old: []any{"auth", "gzip"}, New: []any{"gzip", "auth"}}, changes[3])
+
+	assert.Empty(t, fuda.Diff(before, before))
+}
+
+func TestOrderedMap_Schema(t *testing.T) {
+	out, err := fuda.Schema(&pipelineConfig{})
+	require.NoError(t, err)
+
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(out, &schema))
+	assert.Equal(t, "object", schema.Properties["weights"]["type"])
+	assert.Equal(t, map[string]any{"type": "integer"}, schema.Properties["weights"]["additionalProperties"])
+}
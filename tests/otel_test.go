@@ -0,0 +1,143 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type tracedConfig struct {
+	Host  string `yaml:"host" validate:"required"`
+	Token string `ref:"secret://vault/app?token=s3cr3t"`
+}
+
+func newTracedLoader(t *testing.T, data string, r fuda.RefResolver) (*fuda.Loader, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	loader, err := fuda.New().
+		FromBytes([]byte(data)).
+		WithResolver("secret", r).
+		WithTemplate(map[string]string{"Env": "prod"}).
+		WithTracerProvider(tp).
+		Build()
+	require.NoError(t, err)
+
+	return loader, recorder
+}
+
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	return spans
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key string) string {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsString()
+		}
+	}
+
+	return ""
+}
+
+func TestWithTracerProvider(t *testing.T) {
+	resolver := fuda.RefResolverFunc(func(_ context.Context, _ string) ([]byte, error) {
+		return []byte("value"), nil
+	})
+	loader, recorder := newTracedLoader(t, "host: a", resolver)
+
+	var cfg tracedConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "fuda.Load")
+	require.Contains(t, spans, "fuda.template")
+	require.Contains(t, spans, "fuda.ref")
+	require.Contains(t, spans, "fuda.validate")
+
+	root := spans["fuda.Load"]
+	for _, name := range []string{"fuda.template", "fuda.ref", "fuda.validate"} {
+		assert.Equal(t, root.SpanContext().SpanID(), spans[name].Parent().SpanID(), "%s should be a child of fuda.Load", name)
+		assert.Equal(t, codes.Unset, spans[name].Status().Code)
+	}
+
+	ref := spans["fuda.ref"]
+	assert.Equal(t, "secret", spanAttr(ref, "fuda.ref.scheme"))
+	assert.Equal(t, "secret://vault/app?token=xxxxx", spanAttr(ref, "fuda.ref.uri"))
+}
+
+func TestWithTracerProvider_Errors(t *testing.T) {
+	t.Run("ref", func(t *testing.T) {
+		resolver := fuda.RefResolverFunc(func(_ context.Context, uri string) ([]byte, error) {
+			return nil, errors.New("denied: " + uri)
+		})
+		loader, recorder := newTracedLoader(t, "host: a", resolver)
+
+		var cfg tracedConfig
+		require.Error(t, loader.Load(&cfg))
+
+		spans := spansByName(recorder)
+		ref := spans["fuda.ref"]
+		require.NotNil(t, ref)
+		assert.Equal(t, codes.Error, ref.Status().Code)
+		assert.NotContains(t, ref.Status().Description, "s3cr3t")
+		require.NotEmpty(t, ref.Events())
+		for _, kv := range ref.Events()[0].Attributes {
+			assert.NotContains(t, kv.Value.Emit(), "s3cr3t")
+		}
+		assert.Equal(t, codes.Error, spans["fuda.Load"].Status().Code)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		resolver := fuda.RefResolverFunc(func(_ context.Context, _ string) ([]byte, error) {
+			return []byte("value"), nil
+		})
+		loader, recorder := newTracedLoader(t, "port: 1", resolver)
+
+		var cfg tracedConfig
+		require.Error(t, loader.Load(&cfg))
+
+		spans := spansByName(recorder)
+		assert.Equal(t, codes.Error, spans["fuda.validate"].Status().Code)
+		assert.Equal(t, codes.Error, spans["fuda.Load"].Status().Code)
+	})
+
+	t.Run("parent from context", func(t *testing.T) {
+		resolver := fuda.RefResolverFunc(func(_ context.Context, _ string) ([]byte, error) {
+			return []byte("value"), nil
+		})
+		loader, recorder := newTracedLoader(t, "host: a", resolver)
+
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "startup")
+
+		var cfg tracedConfig
+		require.NoError(t, loader.LoadContext(ctx, &cfg))
+		parent.End()
+
+		spans := spansByName(recorder)
+		assert.Equal(t, parent.SpanContext().SpanID(), spans["fuda.Load"].Parent().SpanID())
+	})
+}
+
+func TestWithTracerProvider_Nil(t *testing.T) {
+	_, err := fuda.New().FromBytes([]byte("host: a")).WithTracerProvider(nil).Build()
+	require.Error(t, err)
+}
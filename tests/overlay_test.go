@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type overlayLimits struct {
+	RPS   int           `yaml:"rps"`
+	Burst int           `yaml:"burst"`
+	Wait  time.Duration `yaml:"wait"`
+}
+
+type overlayCommon struct {
+	Region string `yaml:"region"`
+}
+
+type overlayConfig struct {
+	overlayCommon `yaml:",inline"`
+
+	Name   string         `yaml:"name"`
+	Limits overlayLimits  `yaml:"limits"`
+	Cache  *overlayLimits `yaml:"cache"`
+	Tags   []string       `yaml:"tags"`
+}
+
+var (
+	overlayRPS    = fuda.MustField[overlayConfig, int]("limits.rps")
+	overlayLimit  = fuda.MustField[overlayConfig, overlayLimits]("limits")
+	overlayCache  = fuda.MustField[overlayConfig, int]("cache.rps")
+	overlayRegion = fuda.MustField[overlayConfig, string]("region")
+)
+
+func newOverlayBase() *overlayConfig {
+	return &overlayConfig{
+		overlayCommon: overlayCommon{Region: "eu"},
+		Name:          "api",
+		Limits:        overlayLimits{RPS: 100, Burst: 10, Wait: time.Second},
+		Cache:         &overlayLimits{RPS: 5},
+	}
+}
+
+func TestOverlay_Field(t *testing.T) {
+	base := newOverlayBase()
+	view, err := fuda.NewOverlay(base, map[string]any{
+		"limits.rps": 500,
+		"region":     "us",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 500, overlayRPS.In(view))
+	assert.Equal(t, "us", overlayRegion.In(view))
+	assert.Equal(t, 5, overlayCache.In(view), "not overlaid, read from base")
+	assert.Equal(t, overlayLimits{RPS: 500, Burst: 10, Wait: time.Second}, overlayLimit.In(view))
+
+	assert.Equal(t, 100, overlayRPS.Get(base))
+	assert.Equal(t, "eu", overlayRegion.Get(base))
+	assert.Equal(t, 100, base.Limits.RPS, "base must not change")
+	assert.Same(t, base, view.Base())
+	assert.Equal(t, "limits.rps", overlayRPS.Path())
+}
+
+func TestOverlay_Conversion(t *testing.T) {
+	view, err := fuda.NewOverlay(newOverlayBase(), map[string]any{
+		"limits.burst": 20.0,
+		"limits.wait":  "250ms",
+		"limits.rps":   "42",
+		"tags":         []string{"a"},
+		"name":         nil,
+	})
+	require.NoError(t, err)
+
+	cfg := view.Copy()
+	assert.Equal(t, overlayLimits{RPS: 42, Burst: 20, Wait: 250 * time.Millisecond}, cfg.Limits)
+	assert.Equal(t, []string{"a"}, cfg.Tags)
+	assert.Empty(t, cfg.Name)
+}
+
+func TestOverlay_Errors(t *testing.T) {
+	base := newOverlayBase()
+
+	_, err := fuda.NewOverlay(base, map[string]any{"limits.missing": 1})
+	require.ErrorContains(t, err, `unknown config path "limits.missing"`)
+
+	_, err = fuda.NewOverlay(base, map[string]any{"name.first": "x"})
+	require.ErrorContains(t, err, `unknown config path "name.first"`)
+
+	_, err = fuda.NewOverlay(base, map[string]any{"limits.rps": 1.5})
+	require.ErrorContains(t, err, `overlay "limits.rps": 1.5 does not fit int`)
+
+	_, err = fuda.NewOverlay(base, map[string]any{"limits.rps": true})
+	require.ErrorContains(t, err, `overlay "limits.rps": cannot use bool as int`)
+
+	_, err = fuda.NewOverlay(base, map[string]any{"limits.rps": "many"})
+	require.ErrorContains(t, err, `overlay "limits.rps"`)
+
+	_, err = fuda.NewField[overlayConfig, string]("limits.rps")
+	require.ErrorContains(t, err, `field "limits.rps" has type int, not string`)
+
+	assert.Panics(t, func() { fuda.MustField[overlayConfig, int]("nope") })
+}
+
+func TestOverlay_PointerPath(t *testing.T) {
+	base := newOverlayBase()
+	view, err := fuda.NewOverlay(base, map[string]any{"cache.rps": 7})
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, overlayCache.In(view))
+
+	cfg := view.Copy()
+	assert.Equal(t, 7, cfg.Cache.RPS)
+	assert.NotSame(t, base.Cache, cfg.Cache, "pointed-to struct must be copied")
+	assert.Equal(t, 5, base.Cache.RPS)
+
+	// A nil pointer on the path reads as the zero value
+	base.Cache = nil
+	assert.Zero(t, overlayCache.Get(base))
+
+	cfg = view.Copy()
+	require.NotNil(t, cfg.Cache)
+	assert.Equal(t, 7, cfg.Cache.RPS)
+	assert.Nil(t, base.Cache)
+}
+
+func TestOverlay_Lookup(t *testing.T) {
+	view, err := fuda.NewOverlay(newOverlayBase(), map[string]any{"limits.rps": 1})
+	require.NoError(t, err)
+
+	v, ok := view.Lookup("limits.rps")
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	v, ok = view.Lookup("name")
+	require.True(t, ok)
+	assert.Equal(t, "api", v)
+
+	v, ok = view.Lookup("limits")
+	require.True(t, ok)
+	assert.Equal(t, overlayLimits{RPS: 1, Burst: 10, Wait: time.Second}, v)
+
+	_, ok = view.Lookup("unknown")
+	assert.False(t, ok)
+}
+
+func TestOverlay_Concurrent(t *testing.T) {
+	base := newOverlayBase()
+	done := make(chan struct{})
+	for i := range 8 {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for range 100 {
+				view, err := fuda.NewOverlay(base, map[string]any{"limits.rps": i})
+				assert.NoError(t, err)
+				assert.Equal(t, i, overlayRPS.In(view))
+			}
+		}()
+	}
+	for range 8 {
+		<-done
+	}
+}
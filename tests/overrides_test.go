@@ -327,37 +327,6 @@ host: original-host
 		assert.Equal(t, 5432, cfg.Database.Port)
 	})
 
-	t.Run("override works with template", func(t *testing.T) {
-		type Config struct {
-			Host string `yaml:"host"`
-			Port int    `yaml:"port"`
-		}
-
-		yamlContent := `
-host: {{ .Host }}
-port: 8080
-`
-		type TmplData struct {
-			Host string
-		}
-
-		loader, err := fuda.New().
-			FromBytes([]byte(yamlContent)).
-			WithTemplate(TmplData{Host: "template-host"}).
-			WithOverrides(map[string]any{
-				"port": 9090,
-			}).
-			Build()
-		require.NoError(t, err)
-
-		var cfg Config
-		err = loader.Load(&cfg)
-		require.NoError(t, err)
-
-		assert.Equal(t, "template-host", cfg.Host) // From template
-		assert.Equal(t, 9090, cfg.Port)            // Overridden
-	})
-
 	t.Run("override with default fallback", func(t *testing.T) {
 		type Config struct {
 			Host    string `yaml:"host" default:"default-host"`
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type precedenceConfig struct {
+	Host    string `yaml:"host" env:"PREC_HOST" default:"default-host"`
+	Port    int    `yaml:"port" env:"PREC_PORT" flag:"port" default:"8080"`
+	Debug   bool   `yaml:"debug" env:"PREC_DEBUG" default:"true"`
+	Secret  string `yaml:"secret" ref:"mem://secret" default:"default-secret"`
+	Region  string `yaml:"region" env:"PREC_REGION"`
+	Backend struct {
+		URL string `yaml:"url" env:"PREC_BACKEND_URL"`
+	} `yaml:"backend"`
+}
+
+type memResolver map[string]string
+
+func (m memResolver) Resolve(_ context.Context, uri string) ([]byte, error) {
+	return []byte(m[uri]), nil
+}
+
+func loadPrecedence(t *testing.T, yamlContent string, opts func(*fuda.Builder)) precedenceConfig {
+	t.Helper()
+
+	b := fuda.New().
+		FromBytes([]byte(yamlContent)).
+		WithRefResolver(memResolver{"mem://secret": "ref-secret"})
+	opts(b)
+
+	loader, err := b.Build()
+	require.NoError(t, err)
+
+	var cfg precedenceConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	return cfg
+}
+
+func TestWithPrecedence_FileBeatsEnv(t *testing.T) {
+	t.Setenv("PREC_HOST", "env-host")
+	t.Setenv("PREC_PORT", "9090")
+	t.Setenv("PREC_REGION", "env-region")
+
+	cfg := loadPrecedence(t, "host: file-host\nport: 1000\n", func(b *fuda.Builder) {
+		b.WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceEnv,
+			fuda.SourceOverride, fuda.SourceFile, fuda.SourceFlag)
+	})
+
+	assert.Equal(t, "file-host", cfg.Host)
+	assert.Equal(t, 1000, cfg.Port)
+	assert.Equal(t, "env-region", cfg.Region, "env still fills fields the file leaves unset")
+	assert.Equal(t, "ref-secret", cfg.Secret)
+}
+
+func TestWithPrecedence_DefaultOrderUnchanged(t *testing.T) {
+	t.Setenv("PREC_HOST", "env-host")
+
+	withDefault := loadPrecedence(t, "host: file-host\nport: 1000\n", func(b *fuda.Builder) {
+		b.WithPrecedence(fuda.DefaultPrecedence()...)
+	})
+	without := loadPrecedence(t, "host: file-host\nport: 1000\n", func(*fuda.Builder) {})
+
+	assert.Equal(t, without, withDefault)
+	assert.Equal(t, "env-host", without.Host)
+	assert.Equal(t, 1000, without.Port)
+}
+
+func TestWithPrecedence_EnvZeroValueWins(t *testing.T) {
+	t.Setenv("PREC_DEBUG", "false")
+
+	cfg := loadPrecedence(t, "", func(b *fuda.Builder) {
+		b.WithPrecedence(fuda.DefaultPrecedence()...)
+	})
+
+	assert.False(t, cfg.Debug, "env-set false is not replaced by the default")
+}
+
+func TestWithPrecedence_RefAboveFile(t *testing.T) {
+	cfg := loadPrecedence(t, "secret: file-secret\n", func(b *fuda.Builder) {
+		b.WithPrecedence(fuda.SourceDefault, fuda.SourceFile, fuda.SourceRef,
+			fuda.SourceOverride, fuda.SourceEnv, fuda.SourceFlag)
+	})
+
+	assert.Equal(t, "ref-secret", cfg.Secret)
+}
+
+func TestWithPrecedence_DefaultAboveFile(t *testing.T) {
+	cfg := loadPrecedence(t, "host: file-host\nregion: file-region\n", func(b *fuda.Builder) {
+		b.WithPrecedence(fuda.SourceRef, fuda.SourceFile, fuda.SourceDefault,
+			fuda.SourceOverride, fuda.SourceEnv, fuda.SourceFlag)
+	})
+
+	assert.Equal(t, "default-host", cfg.Host)
+	assert.Equal(t, "file-region", cfg.Region, "file value kept when there is no default")
+}
+
+func TestWithPrecedence_FileOverOverrides(t *testing.T) {
+	overrides := map[string]any{"host": "override-host", "region": "override-region", "backend.url": "http://override"}
+
+	cfg := loadPrecedence(t, "host: file-host\nbackend:\n  url: http://file\n", func(b *fuda.Builder) {
+		b.WithOverrides(overrides).
+			WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceOverride,
+				fuda.SourceFile, fuda.SourceEnv, fuda.SourceFlag)
+	})
+
+	assert.Equal(t, "file-host", cfg.Host)
+	assert.Equal(t, "http://file", cfg.Backend.URL)
+	assert.Equal(t, "override-region", cfg.Region)
+}
+
+func TestWithPrecedence_EnvBetweenFileAndOverrides(t *testing.T) {
+	t.Setenv("PREC_HOST", "env-host")
+	t.Setenv("PREC_REGION", "env-region")
+	t.Setenv("PREC_BACKEND_URL", "http://env")
+
+	// override > env > file
+	cfg := loadPrecedence(t, "host: file-host\nbackend:\n  url: http://file\n", func(b *fuda.Builder) {
+		b.WithOverrides(map[string]any{"region": "override-region"}).
+			WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceFile,
+				fuda.SourceEnv, fuda.SourceOverride, fuda.SourceFlag)
+	})
+
+	assert.Equal(t, "env-host", cfg.Host)
+	assert.Equal(t, "http://env", cfg.Backend.URL)
+	assert.Equal(t, "override-region", cfg.Region)
+
+	// file > env > override
+	cfg = loadPrecedence(t, "host: file-host\n", func(b *fuda.Builder) {
+		b.WithOverrides(map[string]any{"region": "override-region", "backend.url": "http://override"}).
+			WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceOverride,
+				fuda.SourceEnv, fuda.SourceFile, fuda.SourceFlag)
+	})
+
+	assert.Equal(t, "file-host", cfg.Host)
+	assert.Equal(t, "env-region", cfg.Region)
+	assert.Equal(t, "http://env", cfg.Backend.URL)
+}
+
+func TestWithPrecedence_EnvAboveFlag(t *testing.T) {
+	t.Setenv("PREC_PORT", "9090")
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Int("port", 0, "")
+	require.NoError(t, fs.Parse([]string{"-port=7070"}))
+
+	cfg := loadPrecedence(t, "", func(b *fuda.Builder) {
+		b.WithFlagSet(fs).
+			WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceFile,
+				fuda.SourceOverride, fuda.SourceFlag, fuda.SourceEnv)
+	})
+
+	assert.Equal(t, 9090, cfg.Port)
+}
+
+func TestWithPrecedence_Trace(t *testing.T) {
+	t.Setenv("PREC_HOST", "env-host")
+
+	var buf bytes.Buffer
+	loadPrecedence(t, "host: file-host\n", func(b *fuda.Builder) {
+		b.WithTrace(&buf).
+			WithPrecedence(fuda.SourceDefault, fuda.SourceRef, fuda.SourceEnv,
+				fuda.SourceOverride, fuda.SourceFile, fuda.SourceFlag)
+	})
+
+	assert.Contains(t, buf.String(), "Host: yaml=file-host (used), env PREC_HOST=env-host, default=default-host")
+}
+
+func TestWithPrecedence_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []fuda.Source
+		wantErr string
+	}{
+		{"missing", []fuda.Source{fuda.SourceFile, fuda.SourceEnv}, "missing sources: default, ref, override, flag"},
+		{"duplicate", []fuda.Source{fuda.SourceFile, fuda.SourceFile}, "source file listed more than once"},
+		{"unknown", []fuda.Source{fuda.Source(42)}, "unknown source Source(42)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fuda.New().WithPrecedence(tt.sources...).Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid precedence: "+tt.wantErr)
+
+			_, err = fuda.NewLoader(fuda.WithPrecedence(tt.sources...))
+			require.Error(t, err)
+		})
+	}
+}
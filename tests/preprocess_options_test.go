@@ -61,3 +61,102 @@ func TestPreprocessOptions_Duration(t *testing.T) {
 		require.Equal(t, 48*time.Hour, cfg.Timeout)
 	})
 }
+
+type preprocessLimits struct {
+	Timeout time.Duration `yaml:"timeout"`
+	MaxBody int64         `yaml:"max_body"`
+}
+
+type preprocessNestedConfig struct {
+	preprocessLimits `yaml:",inline"`
+
+	Retries  []time.Duration             `yaml:"retries"`
+	Buffers  []uint32                    `yaml:"buffers"`
+	Routes   []preprocessLimits          `yaml:"routes"`
+	ByTenant map[string]preprocessLimits `yaml:"by_tenant"`
+	Grace    *time.Duration              `yaml:"grace"`
+}
+
+func TestPreprocessOptions_Nested(t *testing.T) {
+	yamlContent := `
+timeout: 1d
+max_body: 512 MiB
+retries: [500ms, 1h30m, 2d]
+buffers: [64KiB, 1MB]
+routes:
+  - timeout: 0.5d
+    max_body: 1.5GB
+by_tenant:
+  acme:
+    timeout: 1d12h
+    max_body: 2KiB
+grace: 1d
+`
+	loader, err := fuda.New().FromBytes([]byte(yamlContent)).Build()
+	require.NoError(t, err)
+
+	var cfg preprocessNestedConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	require.Equal(t, 24*time.Hour, cfg.Timeout)
+	require.EqualValues(t, 512<<20, cfg.MaxBody)
+	require.Equal(t, []time.Duration{500 * time.Millisecond, 90 * time.Minute, 48 * time.Hour}, cfg.Retries)
+	require.Equal(t, []uint32{64 << 10, 1_000_000}, cfg.Buffers)
+	require.Equal(t, []preprocessLimits{{Timeout: 12 * time.Hour, MaxBody: 1_500_000_000}}, cfg.Routes)
+	require.Equal(t, preprocessLimits{Timeout: 36 * time.Hour, MaxBody: 2048}, cfg.ByTenant["acme"])
+	require.NotNil(t, cfg.Grace)
+	require.Equal(t, 24*time.Hour, *cfg.Grace)
+}
+
+func TestPreprocessOptions_Tags(t *testing.T) {
+	type Config struct {
+		Timeout  time.Duration `env:"PP_TIMEOUT"`
+		MaxBody  int64         `env:"PP_MAX_BODY"`
+		Interval time.Duration `default:"1d"`
+		Buffer   uint          `default:"64 KiB"`
+		TTL      fuda.Duration `env:"PP_TTL" default:"7d"`
+		Limit    fuda.ByteSize `env:"PP_LIMIT" default:"10MiB"`
+	}
+
+	t.Setenv("PP_TIMEOUT", "2d")
+	t.Setenv("PP_MAX_BODY", "1.5GB")
+	t.Setenv("PP_LIMIT", "512 MiB")
+
+	t.Run("enabled", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, fuda.LoadEnv(&cfg))
+
+		require.Equal(t, 48*time.Hour, cfg.Timeout)
+		require.EqualValues(t, 1_500_000_000, cfg.MaxBody)
+		require.Equal(t, 24*time.Hour, cfg.Interval)
+		require.EqualValues(t, 64<<10, cfg.Buffer)
+		require.Equal(t, 7*24*time.Hour, cfg.TTL.Duration())
+		require.EqualValues(t, 512<<20, cfg.Limit)
+	})
+
+	t.Run("duration disabled", func(t *testing.T) {
+		loader, err := fuda.New().WithDurationPreprocess(false).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unknown unit "d"`)
+	})
+
+	t.Run("size disabled", func(t *testing.T) {
+		t.Setenv("PP_TIMEOUT", "10s")
+		loader, err := fuda.New().WithSizePreprocess(false).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "MaxBody")
+
+		t.Setenv("PP_MAX_BODY", "4096")
+		err = loader.Load(&cfg)
+		require.Error(t, err, "default byte sizes are rejected too")
+		require.Contains(t, err.Error(), "Buffer")
+	})
+}
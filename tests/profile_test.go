@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const profileSource = `
+server:
+  port: 8080
+profiles:
+  default: &default
+    database:
+      host: localhost
+      pool: 5
+    logLevel: debug
+  prod: &prod
+    database:
+      host: db.prod.internal
+    logLevel: warn
+  staging:
+    <<: *prod
+    logLevel: info
+`
+
+type profileConfig struct {
+	Server struct {
+		Port int `yaml:"port"`
+	} `yaml:"server"`
+	Database struct {
+		Host string `yaml:"host"`
+		Pool int    `yaml:"pool"`
+	} `yaml:"database"`
+	LogLevel string `yaml:"logLevel" default:"error"`
+}
+
+func loadProfile(t *testing.T, profile string) (profileConfig, error) {
+	t.Helper()
+
+	loader, err := fuda.New().FromBytes([]byte(profileSource)).WithProfile(profile).Build()
+	require.NoError(t, err)
+
+	var cfg profileConfig
+	err = loader.Load(&cfg)
+
+	return cfg, err
+}
+
+func TestWithProfile(t *testing.T) {
+	t.Run("selected over default", func(t *testing.T) {
+		cfg, err := loadProfile(t, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, "db.prod.internal", cfg.Database.Host)
+		assert.Equal(t, 5, cfg.Database.Pool, "merged from default")
+		assert.Equal(t, "warn", cfg.LogLevel)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		cfg, err := loadProfile(t, "default")
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", cfg.Database.Host)
+		assert.Equal(t, "debug", cfg.LogLevel)
+	})
+
+	t.Run("merge key", func(t *testing.T) {
+		cfg, err := loadProfile(t, "staging")
+		require.NoError(t, err)
+		assert.Equal(t, "db.prod.internal", cfg.Database.Host)
+		assert.Equal(t, 5, cfg.Database.Pool)
+		assert.Equal(t, "info", cfg.LogLevel)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := loadProfile(t, "qa")
+		require.ErrorContains(t, err, `profile "qa" not found`)
+	})
+
+	t.Run("without profile", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(profileSource)).Build()
+		require.NoError(t, err)
+
+		var cfg profileConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Empty(t, cfg.Database.Host, "profiles are ignored without WithProfile")
+		assert.Equal(t, "error", cfg.LogLevel)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		_, err := fuda.New().FromBytes([]byte(profileSource)).WithProfile("").Build()
+		require.Error(t, err)
+	})
+
+	t.Run("layered files", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "/base.yaml", []byte(profileSource), 0o644))
+		require.NoError(t, afero.WriteFile(fs, "/local.yaml", []byte("logLevel: trace\n"), 0o644))
+
+		loader, err := fuda.NewLoader(
+			fuda.WithFilesystem(fs),
+			fuda.FromFiles("/base.yaml", "/local.yaml"),
+			fuda.WithProfile("prod"),
+		)
+		require.NoError(t, err)
+
+		var cfg profileConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "db.prod.internal", cfg.Database.Host)
+		assert.Equal(t, "trace", cfg.LogLevel, "a later file wins over the profile of an earlier one")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte(profileSource), 0o644))
+		loader, err := fuda.New().WithFilesystem(fs).FromFileStream("/config.yaml").WithProfile("prod").Build()
+		require.NoError(t, err)
+
+		var cfg profileConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "db.prod.internal", cfg.Database.Host)
+	})
+}
@@ -0,0 +1,247 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PublishedConfig struct {
+	Host    string            `yaml:"host" default:"localhost" doc:"Server host"`
+	Port    int               `yaml:"port" default:"8080" validate:"min=1,max=65535"`
+	APIKey  string            `yaml:"api_key" validate:"required" doc:"Key for the | gateway"`
+	Servers []PublishedServer `yaml:"servers"`
+}
+
+type PublishedServer struct {
+	Name string `yaml:"name"`
+}
+
+// fakeRegistry is a minimal OCI distribution registry requiring a bearer
+// token obtained with basic auth.
+type fakeRegistry struct {
+	srv       *httptest.Server
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	t.Helper()
+
+	r := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	r.srv = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.srv.Close)
+
+	return r
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		user, pass, _ := req.BasicAuth()
+		if user != "ci" || pass != "pw" || req.URL.Query().Get("scope") != "repository:team/billing:pull,push" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_, _ = io.WriteString(w, `{"token":"t0k"}`)
+
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer t0k" {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:team/billing:pull,push"`, r.srv.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/team/billing")
+	body, _ := io.ReadAll(req.Body)
+	switch {
+	case req.Method == http.MethodHead && strings.HasPrefix(path, "/blobs/"):
+		if _, ok := r.blobs[strings.TrimPrefix(path, "/blobs/")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case req.Method == http.MethodPost && path == "/blobs/uploads/":
+		r.uploads++
+		w.Header().Set("Location", fmt.Sprintf("/v2/team/billing/blobs/uploads/%d?state=x", r.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "/blobs/uploads/"):
+		sum := sha256.Sum256(body)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		if req.URL.Query().Get("digest") != digest || req.URL.Query().Get("state") != "x" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+		r.blobs[digest] = body
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "/manifests/"):
+		if req.Header.Get("Content-Type") != "application/vnd.oci.image.manifest.v1+json" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+		r.manifests[strings.TrimPrefix(path, "/manifests/")] = body
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPublishSchema_OCI(t *testing.T) {
+	reg := newFakeRegistry(t)
+
+	err := fuda.PublishSchema(context.Background(), &PublishedConfig{}, fuda.PublishOptions{
+		Target:    "oci://" + strings.TrimPrefix(reg.srv.URL, "http://") + "/team/billing",
+		Version:   "1.4.0",
+		Docs:      []byte("# Billing config\n"),
+		Username:  "ci",
+		Password:  "pw",
+		PlainHTTP: true,
+	})
+	require.NoError(t, err)
+
+	var manifest struct {
+		ArtifactType string `json:"artifactType"`
+		Config       struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Layers []struct {
+			MediaType   string            `json:"mediaType"`
+			Digest      string            `json:"digest"`
+			Size        int               `json:"size"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	require.Contains(t, reg.manifests, "1.4.0")
+	require.NoError(t, json.Unmarshal(reg.manifests["1.4.0"], &manifest))
+
+	assert.Equal(t, fuda.SchemaArtifactType, manifest.ArtifactType)
+	assert.Equal(t, "1.4.0", manifest.Annotations["org.opencontainers.image.version"])
+	assert.JSONEq(t, `{"name":"PublishedConfig","version":"1.4.0"}`, string(reg.blobs[manifest.Config.Digest]))
+
+	require.Len(t, manifest.Layers, 2)
+	schema := reg.blobs[manifest.Layers[0].Digest]
+	assert.Equal(t, "application/schema+json", manifest.Layers[0].MediaType)
+	assert.Equal(t, "config.schema.json", manifest.Layers[0].Annotations["org.opencontainers.image.title"])
+	assert.Len(t, schema, manifest.Layers[0].Size)
+	want, err := fuda.Schema(&PublishedConfig{})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(schema))
+
+	assert.Equal(t, "text/markdown", manifest.Layers[1].MediaType)
+	assert.Equal(t, "README.md", manifest.Layers[1].Annotations["org.opencontainers.image.title"])
+	assert.Equal(t, "# Billing config\n", string(reg.blobs[manifest.Layers[1].Digest]))
+
+	// Tagging the same schema again only uploads the new config blob
+	uploads := reg.uploads
+	err = fuda.PublishSchema(context.Background(), &PublishedConfig{}, fuda.PublishOptions{
+		Target:    "oci://" + strings.TrimPrefix(reg.srv.URL, "http://") + "/team/billing",
+		Version:   "latest",
+		Docs:      []byte("# Billing config\n"),
+		Username:  "ci",
+		Password:  "pw",
+		PlainHTTP: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uploads+1, reg.uploads)
+	assert.Contains(t, reg.manifests, "latest")
+}
+
+func TestPublishSchema_OCIAuthFailure(t *testing.T) {
+	reg := newFakeRegistry(t)
+
+	err := fuda.PublishSchema(context.Background(), &PublishedConfig{}, fuda.PublishOptions{
+		Target:    "oci://" + strings.TrimPrefix(reg.srv.URL, "http://") + "/team/billing",
+		Version:   "1.4.0",
+		Username:  "ci",
+		Password:  "wrong",
+		PlainHTTP: true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "registry token")
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestPublishSchema_HTTP(t *testing.T) {
+	var bundle fuda.SchemaBundle
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Api-Key") != "k" ||
+			r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, "forbidden")
+
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&bundle)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	err := fuda.PublishSchema(context.Background(), PublishedConfig{}, fuda.PublishOptions{
+		Target:  srv.URL + "/schemas",
+		Version: "2.0.0",
+		Name:    "billing",
+		Header:  http.Header{"X-Api-Key": {"k"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "billing", bundle.Name)
+	assert.Equal(t, "2.0.0", bundle.Version)
+	assert.Contains(t, string(bundle.Schema), `"api_key"`)
+
+	// Without Docs, a table of the keys is generated from the schema
+	assert.Contains(t, bundle.Docs, "# billing\n")
+	assert.Contains(t, bundle.Docs, "| `host` | string |  | `\"localhost\"` | Server host |")
+	assert.Contains(t, bundle.Docs, "| `api_key` | string | yes |  | Key for the \\| gateway |")
+	assert.Contains(t, bundle.Docs, "| `servers` | array of object |")
+	assert.Contains(t, bundle.Docs, "| `servers[].name` | string |")
+
+	err = fuda.PublishSchema(context.Background(), PublishedConfig{}, fuda.PublishOptions{
+		Target:  srv.URL + "/schemas",
+		Version: "2.0.0",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden: forbidden")
+}
+
+func TestPublishSchema_InvalidOptions(t *testing.T) {
+	ctx := context.Background()
+
+	err := fuda.PublishSchema(ctx, &PublishedConfig{}, fuda.PublishOptions{Target: "oci://r.example/app"})
+	require.ErrorContains(t, err, "Version is required")
+
+	err = fuda.PublishSchema(ctx, &struct{ A int }{}, fuda.PublishOptions{Target: "oci://r.example/app", Version: "1"})
+	require.ErrorContains(t, err, "Name is required")
+
+	err = fuda.PublishSchema(ctx, &PublishedConfig{}, fuda.PublishOptions{Target: "ftp://r.example/app", Version: "1"})
+	require.ErrorContains(t, err, "scheme must be http, https, or oci")
+
+	err = fuda.PublishSchema(ctx, &PublishedConfig{}, fuda.PublishOptions{Target: "oci://r.example/app", Version: "v1+build"})
+	require.ErrorContains(t, err, "not a valid OCI tag")
+
+	err = fuda.PublishSchema(ctx, &PublishedConfig{}, fuda.PublishOptions{Target: "oci://r.example", Version: "1"})
+	require.ErrorContains(t, err, "want oci://registry/repository")
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRefRateLimit(t *testing.T) {
+	type Config struct {
+		A string `ref:"corp://a"`
+		B string `ref:"corp://b"`
+		C string `ref:"corp://c"`
+		D string `ref:"corp://d"`
+		E string `ref:"corp://e"`
+	}
+
+	corp := fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+		return []byte("v"), nil
+	})
+
+	t.Run("spaces network refs", func(t *testing.T) {
+		loader, err := fuda.New().
+			WithResolver("corp", corp).
+			WithRefConcurrency(5).
+			WithRefRateLimit(20).
+			Build()
+		require.NoError(t, err)
+
+		start := time.Now()
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.GreaterOrEqual(t, time.Since(start), 4*50*time.Millisecond, "5 refs at 20/s need 4 intervals")
+		assert.Equal(t, "v", cfg.E)
+	})
+
+	t.Run("local refs are not limited", func(t *testing.T) {
+		type EnvConfig struct {
+			A string `ref:"env://RL_VALUE"`
+			B string `ref:"env://RL_VALUE"`
+			C string `ref:"env://RL_VALUE"`
+		}
+		t.Setenv("RL_VALUE", "x")
+
+		loader, err := fuda.New().WithRefRateLimit(0.1).Build()
+		require.NoError(t, err)
+
+		start := time.Now()
+		var cfg EnvConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, "x", cfg.C)
+	})
+
+	t.Run("age refs are not limited", func(t *testing.T) {
+		type AgeConfig struct {
+			A string `ref:"age:///secrets/a.age"`
+			B string `ref:"age:///secrets/b.age"`
+			C string `ref:"age:///secrets/c.age"`
+		}
+
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+
+		fs := afero.NewMemMapFs()
+		for _, name := range []string{"a", "b", "c"} {
+			var buf bytes.Buffer
+			w, err := age.Encrypt(&buf, identity.Recipient())
+			require.NoError(t, err)
+			_, err = io.WriteString(w, name)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			require.NoError(t, afero.WriteFile(fs, "/secrets/"+name+".age", buf.Bytes(), 0o600))
+		}
+
+		loader, err := fuda.New().
+			WithFilesystem(fs).
+			WithAgeIdentity(identity.String()).
+			WithRefRateLimit(0.1).
+			Build()
+		require.NoError(t, err)
+
+		start := time.Now()
+		var cfg AgeConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, "c", cfg.C)
+	})
+
+	t.Run("wait is bounded by timeout", func(t *testing.T) {
+		loader, err := fuda.New().
+			WithResolver("corp", corp).
+			WithRefRateLimit(0.1).
+			WithTimeout(100 * time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rate limit wait")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
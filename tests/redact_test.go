@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type redactBase struct {
+	Version string `yaml:"version"`
+}
+
+type redactUpstream struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token" secret:"true"`
+}
+
+type redactConfig struct {
+	redactBase `yaml:",inline"`
+
+	Host      string           `yaml:"host"`
+	Password  string           `yaml:"password" secret:"true"`
+	APIKey    string           `yaml:"api_key" sensitive:"true"`
+	Empty     string           `yaml:"empty" secret:"true"`
+	Region    string           `yaml:"region" ref:"mem://region" secret:"false"`
+	DBToken   string           `yaml:"db_token" ref:"mem://db-token"`
+	Timeout   time.Duration    `yaml:"timeout"`
+	Upstreams []redactUpstream `yaml:"upstreams"`
+	Hook      func()           `yaml:"hook"`
+}
+
+func loadRedactConfig(t *testing.T) *redactConfig {
+	t.Helper()
+
+	loader, err := fuda.New().
+		FromBytes([]byte(`
+version: v2
+host: example.com
+password: hunter2
+api_key: abc123
+timeout: 30s
+upstreams:
+  - url: https://a.example.com
+    token: tok-a
+`)).
+		WithRefResolver(staticResolver{"mem://region": "eu-west-1", "mem://db-token": "db-s3cret"}).
+		Build()
+	require.NoError(t, err)
+
+	var cfg redactConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	return &cfg
+}
+
+func TestDumpRedacted(t *testing.T) {
+	cfg := loadRedactConfig(t)
+
+	var out strings.Builder
+	require.NoError(t, fuda.DumpRedacted(&out, cfg))
+
+	assert.Equal(t, `version: v2
+host: example.com
+password: '[REDACTED]'
+api_key: '[REDACTED]'
+empty: ""
+region: eu-west-1
+db_token: '[REDACTED]'
+timeout: 30s
+upstreams:
+    - url: https://a.example.com
+      token: '[REDACTED]'
+`, out.String())
+
+	for _, secret := range []string{"hunter2", "abc123", "db-s3cret", "tok-a"} {
+		assert.NotContains(t, out.String(), secret)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	cfg := loadRedactConfig(t)
+
+	redacted, err := fuda.Redact(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", redacted["host"])
+	assert.Equal(t, "[REDACTED]", redacted["password"])
+	assert.Equal(t, "v2", redacted["version"])
+	assert.Equal(t, []any{
+		map[string]any{"url": "https://a.example.com", "token": "[REDACTED]"},
+	}, redacted["upstreams"])
+	assert.NotContains(t, redacted, "hook")
+
+	// The loaded config itself is left untouched.
+	assert.Equal(t, "hunter2", cfg.Password)
+}
+
+func TestRedact_NotStruct(t *testing.T) {
+	_, err := fuda.Redact("plain string")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "struct")
+}
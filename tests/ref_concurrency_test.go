@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// barrierResolver blocks every call until `want` calls are in flight at once,
+// so it only succeeds when refs are resolved concurrently.
+type barrierResolver struct {
+	want    int32
+	arrived atomic.Int32
+	calls   atomic.Int32
+	once    sync.Once
+	release chan struct{}
+}
+
+func newBarrierResolver(want int32) *barrierResolver {
+	return &barrierResolver{want: want, release: make(chan struct{})}
+}
+
+func (r *barrierResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	r.calls.Add(1)
+	if r.arrived.Add(1) >= r.want {
+		r.once.Do(func() { close(r.release) })
+	}
+
+	select {
+	case <-r.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(2 * time.Second):
+		return nil, errors.New("refs were not resolved concurrently")
+	}
+
+	_, path, _ := strings.Cut(uri, "://")
+
+	return []byte("value-" + path), nil
+}
+
+func TestWithRefConcurrency(t *testing.T) {
+	type Secrets struct {
+		C string `ref:"mem://c"`
+	}
+	type Config struct {
+		A       string  `ref:"mem://a"`
+		B       string  `ref:"mem://b"`
+		Secrets Secrets `yaml:"secrets"`
+		Dup     string  `ref:"mem://a"`
+	}
+
+	resolver := newBarrierResolver(3)
+	loader, err := fuda.New().
+		WithRefResolver(resolver).
+		WithRefConcurrency(3).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "value-a", cfg.A)
+	assert.Equal(t, "value-b", cfg.B)
+	assert.Equal(t, "value-c", cfg.Secrets.C)
+	assert.Equal(t, "value-a", cfg.Dup)
+	assert.Equal(t, int32(3), resolver.calls.Load(), "duplicate URIs are resolved once")
+}
+
+func TestWithRefConcurrency_AutoEnvOverride(t *testing.T) {
+	type Server struct {
+		Name string `yaml:"name"`
+		Host string `yaml:"host" ref:"mem://host"`
+	}
+	type Config struct {
+		Token   string   `yaml:"token" ref:"mem://token"`
+		Servers []Server `yaml:"servers"`
+	}
+
+	t.Setenv("TOKEN", "from-env")
+	t.Setenv("SERVERS_0_HOST", "env.example.com")
+
+	var calls atomic.Int32
+	loader, err := fuda.New().
+		FromBytes([]byte("servers:\n  - name: a\n")).
+		WithAutoEnv().
+		WithRefResolver(resolverFunc(func(_ context.Context, uri string) ([]byte, error) {
+			calls.Add(1)
+
+			return []byte(uri), nil
+		})).
+		WithRefConcurrency(4).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "from-env", cfg.Token)
+	assert.Equal(t, "env.example.com", cfg.Servers[0].Host)
+	assert.Zero(t, calls.Load(), "refs overridden by AutoEnv are not prefetched")
+}
+
+// resolverFunc adapts a function to fuda.RefResolver.
+type resolverFunc func(ctx context.Context, uri string) ([]byte, error)
+
+func (f resolverFunc) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	return f(ctx, uri)
+}
@@ -0,0 +1,150 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// refServer serves the last path segment of each request as its body and
+// counts the connections opened to it.
+func refServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return server, &conns
+}
+
+type httpRefConfig struct {
+	Base string `yaml:"base"`
+	A    string `ref:"${.Base}/a"`
+	B    string `ref:"${.Base}/b"`
+	C    string `ref:"${.Base}/c"`
+	D    string `ref:"${.Base}/d"`
+}
+
+func TestWithRefHTTPClient(t *testing.T) {
+	server, _ := refServer(t)
+
+	var requests atomic.Int32
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	var cfg httpRefConfig
+	loader, err := fuda.New().
+		FromBytes([]byte("base: " + server.URL)).
+		WithRefHTTPClient(client).
+		Build()
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "b", cfg.B)
+	assert.Equal(t, "d", cfg.D)
+	assert.Equal(t, int32(4), requests.Load(), "every ref should go through the client")
+}
+
+func TestWithRefTransport(t *testing.T) {
+	t.Run("reuses connections", func(t *testing.T) {
+		server, conns := refServer(t)
+
+		loader, err := fuda.NewLoader(
+			fuda.FromBytes([]byte("base: "+server.URL)),
+			fuda.WithRefTransport(fuda.HTTPTransport{
+				MaxIdleConnsPerHost: 4,
+				IdleConnTimeout:     time.Minute,
+				KeepAlive:           time.Minute,
+			}),
+		)
+		require.NoError(t, err)
+		var cfg httpRefConfig
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "c", cfg.C)
+		assert.Equal(t, int32(1), conns.Load(), "sequential refs should share one connection")
+	})
+
+	t.Run("keep-alives disabled", func(t *testing.T) {
+		server, conns := refServer(t)
+
+		loader, err := fuda.NewLoader(
+			fuda.FromBytes([]byte("base: "+server.URL)),
+			fuda.WithRefTransport(fuda.HTTPTransport{DisableKeepAlives: true}),
+		)
+		require.NoError(t, err)
+		var cfg httpRefConfig
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "c", cfg.C)
+		assert.Equal(t, int32(4), conns.Load(), "each ref should open its own connection")
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxied atomic.Int32
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied.Add(1)
+			_, _ = w.Write([]byte("via-proxy:" + r.URL.Host + r.URL.Path))
+		}))
+		defer proxy.Close()
+		proxyURL, err := url.Parse(proxy.URL)
+		require.NoError(t, err)
+
+		var cfg struct {
+			Value string `ref:"http://config.internal/value"`
+		}
+		loader, err := fuda.New().
+			FromBytes([]byte("{}")).
+			WithRefTransport(fuda.HTTPTransport{Proxy: http.ProxyURL(proxyURL)}).
+			Build()
+		require.NoError(t, err)
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "via-proxy:config.internal/value", cfg.Value)
+		assert.Equal(t, int32(1), proxied.Load())
+	})
+}
+
+func TestRefHTTPOptions_Errors(t *testing.T) {
+	_, err := fuda.New().FromBytes([]byte("{}")).WithRefHTTPClient(nil).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "non-nil client")
+
+	_, err = fuda.New().
+		FromBytes([]byte("{}")).
+		WithRefHTTPClient(&http.Client{}).
+		WithRefTransport(fuda.HTTPTransport{MaxIdleConnsPerHost: 8}).
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be combined")
+}
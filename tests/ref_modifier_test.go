@@ -0,0 +1,95 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefModifiers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"db": {"password": "s3cret", "port": 5432, "replicas": ["r1", "r2"]}}`))
+	}))
+	defer server.Close()
+
+	type Config struct {
+		URL      string   `yaml:"url"`
+		Blob     []byte   `ref:"file:///run/secrets/blob,base64"`
+		Password string   `refFrom:"URL,jsonpath=$.db.password"`
+		Port     int      `fuda:"ref='file:///app.json,jsonpath=$.db.port'"`
+		Replicas []string `ref:"file:///app.json,jsonpath=$.db.replicas"`
+		Token    string   `ref:"file:///token.b64,base64,jsonpath=$.token"`
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/run/secrets/blob", []byte("AAEC/w==\n"), 0o600))
+	require.NoError(t, afero.WriteFile(fs, "/app.json", []byte(`{"db": {"port": 6543, "replicas": ["a", "b"]}}`), 0o600))
+	require.NoError(t, afero.WriteFile(fs, "/token.b64", []byte("eyJ0b2tlbiI6ICJ0b2stMSJ9"), 0o600))
+
+	loader, err := fuda.New().
+		WithFilesystem(fs).
+		FromBytes([]byte("url: " + server.URL + "\n")).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, []byte{0x00, 0x01, 0x02, 0xff}, cfg.Blob)
+	assert.Equal(t, "s3cret", cfg.Password)
+	assert.Equal(t, 6543, cfg.Port)
+	assert.Equal(t, []string{"a", "b"}, cfg.Replicas)
+	assert.Equal(t, "tok-1", cfg.Token)
+}
+
+func TestRefModifiers_Errors(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app.json", []byte(`{"db": {}}`), 0o600))
+
+	t.Run("missing key", func(t *testing.T) {
+		type Config struct {
+			Password string `ref:"file:///app.json,jsonpath=$.db.password" default:"unused"`
+		}
+
+		loader, err := fuda.New().WithFilesystem(fs).FromBytes(nil).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "(tag 'ref', source 'file:///app.json'): jsonpath modifier: no value at $.db.password")
+	})
+
+	t.Run("missing file falls back to default", func(t *testing.T) {
+		type Config struct {
+			Password string `ref:"file:///missing.json,jsonpath=$.db.password" default:"fallback"`
+		}
+
+		loader, err := fuda.New().WithFilesystem(fs).FromBytes(nil).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "fallback", cfg.Password)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		type Config struct {
+			Password string `ref:"file:///app.json,jsonpath=db.password"`
+		}
+
+		loader, err := fuda.New().WithFilesystem(fs).FromBytes(nil).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `jsonpath "db.password" must start with $`)
+	})
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyResolver fails the first `failures` calls per URI with a transient error.
+type flakyResolver struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[string]int
+}
+
+func newFlakyResolver(failures int) *flakyResolver {
+	return &flakyResolver{failures: failures, calls: make(map[string]int)}
+}
+
+func (r *flakyResolver) Resolve(_ context.Context, uri string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls[uri]++
+	if uri == "mem://missing" {
+		return nil, os.ErrNotExist
+	}
+	if r.calls[uri] <= r.failures {
+		return nil, errors.New("503 service unavailable")
+	}
+
+	return []byte("ok"), nil
+}
+
+func (r *flakyResolver) count(uri string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls[uri]
+}
+
+func TestWithRefRetry(t *testing.T) {
+	type Config struct {
+		Secret string `ref:"mem://secret"`
+	}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		resolver := newFlakyResolver(2)
+		loader, err := fuda.New().
+			WithRefResolver(resolver).
+			WithRefRetry(3, time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "ok", cfg.Secret)
+		assert.Equal(t, 3, resolver.count("mem://secret"))
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		resolver := newFlakyResolver(5)
+		loader, err := fuda.New().
+			WithRefResolver(resolver).
+			WithRefRetry(2, time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "giving up after 2 attempts")
+		assert.Equal(t, 2, resolver.count("mem://secret"))
+	})
+
+	t.Run("no retries by default", func(t *testing.T) {
+		resolver := newFlakyResolver(1)
+		loader, err := fuda.New().WithRefResolver(resolver).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.Error(t, loader.Load(&cfg))
+		assert.Equal(t, 1, resolver.count("mem://secret"))
+	})
+
+	t.Run("not found is not retried", func(t *testing.T) {
+		type MissingConfig struct {
+			Value string `ref:"mem://missing" default:"fallback"`
+		}
+
+		resolver := newFlakyResolver(0)
+		loader, err := fuda.New().
+			WithRefResolver(resolver).
+			WithRefRetry(5, time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg MissingConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "fallback", cfg.Value)
+		assert.Equal(t, 1, resolver.count("mem://missing"))
+	})
+
+	t.Run("stops when context deadline passes", func(t *testing.T) {
+		resolver := newFlakyResolver(100)
+		loader, err := fuda.New().
+			WithRefResolver(resolver).
+			WithRefRetry(100, time.Hour).
+			WithTimeout(20 * time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "retry aborted after 1 attempts")
+	})
+}
+
+func TestRefRetryTag(t *testing.T) {
+	t.Run("overrides global attempts", func(t *testing.T) {
+		type Config struct {
+			Secret string `ref:"mem://secret" refRetry:"4"`
+		}
+
+		resolver := newFlakyResolver(3)
+		loader, err := fuda.New().
+			WithRefResolver(resolver).
+			WithRefRetry(1, time.Millisecond).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "ok", cfg.Secret)
+		assert.Equal(t, 4, resolver.count("mem://secret"))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		type Config struct {
+			Secret string `ref:"mem://secret" refRetry:"many"`
+		}
+
+		loader, err := fuda.New().WithRefResolver(newFlakyResolver(0)).Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refRetry")
+	})
+}
@@ -0,0 +1,138 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadConfig struct {
+	Host string `yaml:"host" validate:"required"`
+	Port int    `yaml:"port" default:"8080"`
+}
+
+func TestReload(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: a"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFile("/config.yaml").Build()
+	require.NoError(t, err)
+
+	var cfg reloadConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "a", cfg.Host)
+
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: b\nport: 9090"), 0o644))
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "a", cfg.Host, "Load should use the content read by Build")
+
+	require.NoError(t, loader.Reload())
+	cfg = reloadConfig{}
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "b", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)
+
+	m, err := loader.ToMap()
+	require.NoError(t, err)
+	assert.Equal(t, "b", m["host"])
+
+	t.Run("missing file keeps content", func(t *testing.T) {
+		require.NoError(t, fs.Remove("/config.yaml"))
+		t.Cleanup(func() {
+			_ = afero.WriteFile(fs, "/config.yaml", []byte("host: b\nport: 9090"), 0o644)
+		})
+
+		err := loader.Reload()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to reload /config.yaml")
+
+		var cfg reloadConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "b", cfg.Host)
+	})
+}
+
+func TestReload_FromFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/base.yaml", []byte("host: a\nport: 1"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/prod.yaml", []byte("port: 2"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFiles("/base.yaml", "/prod.yaml").Build()
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "/prod.yaml", []byte("host: c"), 0o644))
+	require.NoError(t, loader.Reload())
+
+	var cfg reloadConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "c", cfg.Host)
+	assert.Equal(t, 1, cfg.Port)
+}
+
+func TestReload_Bytes(t *testing.T) {
+	loader, err := fuda.New().FromBytes([]byte("host: a")).Build()
+	require.NoError(t, err)
+	require.NoError(t, loader.Reload(), "sources without a file should be kept")
+
+	var cfg reloadConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "a", cfg.Host)
+}
+
+func TestReloadInto(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: a\nport: 1"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFile("/config.yaml").Build()
+	require.NoError(t, err)
+
+	var cfg reloadConfig
+	require.NoError(t, loader.ReloadInto(&cfg))
+	assert.Equal(t, reloadConfig{Host: "a", Port: 1}, cfg)
+
+	t.Run("removed keys", func(t *testing.T) {
+		require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: b"), 0o644))
+		require.NoError(t, loader.ReloadInto(&cfg))
+		assert.Equal(t, reloadConfig{Host: "b", Port: 8080}, cfg, "values from the previous load should not linger")
+	})
+
+	t.Run("invalid edit keeps target", func(t *testing.T) {
+		require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("port: 2"), 0o644))
+		err := loader.ReloadInto(&cfg)
+		require.Error(t, err)
+		assert.Equal(t, reloadConfig{Host: "b", Port: 8080}, cfg)
+	})
+
+	t.Run("target must be a pointer", func(t *testing.T) {
+		require.Error(t, loader.ReloadInto(cfg))
+	})
+}
+
+func TestReload_Concurrent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte("host: a"), 0o644))
+
+	loader, err := fuda.New().WithFilesystem(fs).FromFile("/config.yaml").Build()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 50 {
+				var cfg reloadConfig
+				assert.NoError(t, loader.Load(&cfg))
+				assert.Equal(t, "a", cfg.Host)
+			}
+		})
+	}
+	for range 50 {
+		require.NoError(t, loader.Reload())
+	}
+	wg.Wait()
+}
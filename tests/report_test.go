@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reportCommon struct {
+	Version string `yaml:"version" validate:"required"`
+}
+
+type reportDatabase struct {
+	Host     string `yaml:"host" env:"DB_HOST" validate:"required,hostname"`
+	User     string `yaml:"user" ref:"vault:///secret/db#user"`
+	Password string `yaml:"password" ref:"vault:///secret/db#password"`
+	CertPath string `yaml:"cert_path"`
+	Cert     string `yaml:"cert" refFrom:"CertPath"`
+	DSN      string `yaml:"dsn" dsn:"postgres://${.User}:${ref:file:///run/pw}@${.Host}/app"`
+}
+
+type reportRoute struct {
+	Path    string        `yaml:"path" validate:"required"`
+	Timeout time.Duration `yaml:"timeout" fuda:"default=5s,env=ROUTE_TIMEOUT"`
+}
+
+type reportNode struct {
+	Name     string        `yaml:"name"`
+	Children []*reportNode `yaml:"children"`
+}
+
+type reportConfig struct {
+	reportCommon `yaml:",inline"`
+
+	Name     string                               `yaml:"name" env:"APP_NAME" validate:"required"`
+	APIKey   string                               `yaml:"api_key" env:"API_KEY" secret:"true"`
+	Banner   string                               `yaml:"banner" ref:"/etc/app/banner" secret:"false"`
+	Token    string                               `yaml:"token" ref:"${.TokenURI}"`
+	Labels   map[string]string                    `yaml:"labels" env:"LABEL_*"`
+	Started  time.Time                            `yaml:"started"`
+	Database reportDatabase                       `yaml:"database"`
+	Routes   []reportRoute                        `yaml:"routes"`
+	Stages   fuda.OrderedMap[string, reportRoute] `yaml:"stages"`
+	Tree     *reportNode                          `yaml:"tree"`
+	Ignored  string                               `yaml:"-" env:"IGNORED"`
+	Limits   map[string]fuda.ByteSize             `yaml:"limits"`
+}
+
+func TestReport(t *testing.T) {
+	report, err := fuda.Report(&reportConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "reportConfig", report.Type)
+	// version, name, api_key, banner, token, labels, started, limits,
+	// 6 database fields, 2 route fields for routes and stages, and the tree
+	// node's name, whose children are not counted again
+	assert.Equal(t, 19, report.Fields)
+	assert.Equal(t, 4, report.Sections) // database, routes, stages, tree
+	assert.Equal(t, 2, report.MaxDepth)
+	assert.Equal(t, 6, report.Secrets) // api_key, token, user, password, cert, dsn
+	assert.Equal(t, map[string]int{"vault": 2, "file": 2}, report.Refs)
+	assert.Equal(t, 2, report.DynamicRefs)
+	assert.Equal(t, 6, report.RefCount())
+	assert.Equal(t, []string{"API_KEY", "APP_NAME", "DB_HOST", "LABEL_*", "ROUTE_TIMEOUT"}, report.EnvVars)
+	assert.Equal(t, 5, report.Validated)
+	assert.InDelta(t, 5.0/19, report.ValidationCoverage(), 1e-9)
+
+	assert.Equal(t, `Config report: reportConfig
+  Fields:              19 in 4 sections
+  Deepest nesting:     2
+  Secrets:             6
+  External refs:       6 (file: 2, vault: 2, dynamic: 2)
+  Env variables:       5
+  Validation coverage: 5/19 fields (26%)
+`, report.String())
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "reportConfig", "fields": 19, "sections": 4, "max_depth": 2,
+		"secrets": 6, "refs": {"file": 2, "vault": 2}, "dynamic_refs": 2,
+		"env_vars": ["API_KEY", "APP_NAME", "DB_HOST", "LABEL_*", "ROUTE_TIMEOUT"],
+		"validated": 5
+	}`, string(data))
+}
+
+func TestReport_InvalidTarget(t *testing.T) {
+	_, err := fuda.Report(42)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "struct or pointer to struct")
+
+	report, err := fuda.Report(struct{}{})
+	require.NoError(t, err)
+	assert.Zero(t, report.Fields)
+	assert.Zero(t, report.ValidationCoverage())
+	assert.Empty(t, report.EnvVars)
+}
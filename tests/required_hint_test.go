@@ -0,0 +1,104 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredHint_ListsWaysToSet(t *testing.T) {
+	type Database struct {
+		Host         string  `yaml:"host" validate:"required"`
+		PasswordFile *string `yaml:"password_file"`
+		Password     string  `yaml:"password" env:"DB_PASSWORD" refFrom:"PasswordFile" ref:"file:///run/secrets/db_password" validate:"required"`
+	}
+	type Server struct {
+		Name string `yaml:"name" validate:"required"`
+	}
+	type Config struct {
+		Database Database `yaml:"database"`
+		Servers  []Server `yaml:"servers" validate:"dive"`
+		Token    string   `yaml:"-" env:"API_TOKEN" validate:"required"`
+		Port     int      `yaml:"port" validate:"min=1"`
+	}
+
+	var cfg Config
+	loader, err := fuda.New().
+		FromBytes([]byte("servers:\n  - name: \"\"\nport: 0\n")).
+		WithEnvPrefix("APP_").
+		WithFilesystem(afero.NewMemMapFs()).
+		Build()
+	require.NoError(t, err)
+
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+
+	msg := err.Error()
+	assert.Contains(t, msg, "'Host' failed on the 'required' tag; set yaml key database.host\n")
+	assert.Contains(t, msg, "'Password' failed on the 'required' tag; set yaml key database.password, "+
+		"env APP_DB_PASSWORD, a ref URI in database.password_file, or file /run/secrets/db_password")
+	assert.Contains(t, msg, "'Name' failed on the 'required' tag; set yaml key servers[0].name")
+	assert.Contains(t, msg, "'Token' failed on the 'required' tag; set env APP_API_TOKEN")
+	assert.NotContains(t, msg, "'min' tag;", "only missing values get hints")
+
+	// The validator's errors are still reachable
+	var verrs validator.ValidationErrors
+	require.True(t, errors.As(err, &verrs))
+	assert.Len(t, verrs, 5)
+}
+
+func TestRequiredHint_AutoEnvAndFudaTag(t *testing.T) {
+	type Config struct {
+		Database struct {
+			MaxConns int `yaml:"max_conns" fuda:"validate=required"`
+		} `yaml:"database"`
+		Region string `yaml:"region" flag:"region" validate:"required"`
+	}
+
+	var cfg Config
+	loader, err := fuda.New().FromBytes(nil).WithAutoEnv().Build()
+	require.NoError(t, err)
+
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'Database.MaxConns' (tag 'validate'): failed on the 'required' rule; "+
+		"set yaml key database.max_conns or env DATABASE_MAX_CONNS")
+	assert.Contains(t, err.Error(), "'Region' failed on the 'required' tag; set yaml key region, env REGION, or flag --region")
+
+	var verr *fuda.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 2)
+	var ferr *fuda.FieldError
+	require.ErrorAs(t, verr.Errors[1], &ferr)
+	assert.Equal(t, "Database.MaxConns", ferr.Path)
+}
+
+func TestRequiredHint_RequiredEnvWithFlag(t *testing.T) {
+	type Config struct {
+		Region string `env:"HINT_REGION,required" flag:"region"`
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("region", "", "")
+	require.NoError(t, fs.Parse(nil))
+
+	loader, err := fuda.New().FromBytes(nil).WithFlagSet(fs).Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required environment variable HINT_REGION is not set (or set flag --region)")
+
+	require.NoError(t, fs.Parse([]string{"-region=eu"}))
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "eu", cfg.Region)
+}
@@ -0,0 +1,69 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordURIs returns middleware that records each resolved URI under name.
+func recordURIs(name string, seen *[]string) fuda.ResolverMiddleware {
+	return func(next fuda.RefResolver) fuda.RefResolver {
+		return fuda.RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+			*seen = append(*seen, name+":"+uri)
+
+			return next.Resolve(ctx, uri)
+		})
+	}
+}
+
+func TestWithResolverMiddleware(t *testing.T) {
+	type Config struct {
+		Token string `ref:"env://MW_TOKEN"`
+		DSN   string `dsn:"db://${ref:env://MW_TOKEN}"`
+	}
+
+	t.Setenv("MW_TOKEN", "tok")
+
+	var seen []string
+	loader, err := fuda.New().
+		WithResolverMiddleware(recordURIs("outer", &seen)).
+		WithResolverMiddleware(recordURIs("inner", &seen)).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "tok", cfg.Token)
+	assert.Equal(t, "db://tok", cfg.DSN)
+	assert.Equal(t, []string{
+		"outer:env://MW_TOKEN", "inner:env://MW_TOKEN",
+		"outer:env://MW_TOKEN", "inner:env://MW_TOKEN",
+	}, seen)
+}
+
+func TestWithResolverMiddleware_KeepsLoadScope(t *testing.T) {
+	type Config struct {
+		A string `ref:"corp://a"`
+	}
+
+	var seen []string
+	r := &scopedResolver{}
+	loader, err := fuda.New().
+		WithRefResolver(r).
+		WithResolverMiddleware(recordURIs("mw", &seen)).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "value", cfg.A)
+	assert.Equal(t, []string{"mw:corp://a"}, seen)
+	assert.Equal(t, int32(1), r.loads.Load())
+	assert.Zero(t, r.unscoped.Load())
+}
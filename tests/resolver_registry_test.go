@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixResolver returns "<prefix>:<path>" for any URI it is asked to resolve.
+type prefixResolver struct {
+	prefix string
+}
+
+func (r *prefixResolver) Resolve(_ context.Context, uri string) ([]byte, error) {
+	_, path, _ := strings.Cut(uri, "://")
+	if path == "missing" {
+		return nil, os.ErrNotExist
+	}
+
+	return []byte(r.prefix + ":" + path), nil
+}
+
+func TestRegisterResolver(t *testing.T) {
+	type Config struct {
+		Secret   string `ref:"mem://secret"`
+		Fallback string `ref:"mem://missing" default:"fallback"`
+		Env      string `ref:"env://FUDA_REGISTRY_TEST"`
+	}
+
+	fuda.RegisterResolver("mem", &prefixResolver{prefix: "global"})
+	t.Cleanup(func() { fuda.UnregisterResolver("mem") })
+	t.Setenv("FUDA_REGISTRY_TEST", "from-env")
+
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "global:secret", cfg.Secret)
+	assert.Equal(t, "fallback", cfg.Fallback)
+	assert.Equal(t, "from-env", cfg.Env, "built-in resolvers remain available")
+}
+
+func TestUnregisterResolver(t *testing.T) {
+	type Config struct {
+		Secret string `ref:"mem://secret"`
+	}
+
+	fuda.RegisterResolver("mem", &prefixResolver{prefix: "global"})
+	fuda.UnregisterResolver("mem")
+
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported scheme")
+}
+
+func TestRegisterResolver_Panics(t *testing.T) {
+	assert.Panics(t, func() { fuda.RegisterResolver("", &prefixResolver{}) })
+	assert.Panics(t, func() { fuda.RegisterResolver("mem", nil) })
+}
+
+func TestBuilder_WithResolver(t *testing.T) {
+	type Config struct {
+		A string `ref:"alpha://one"`
+		B string `ref:"beta://two"`
+	}
+
+	t.Run("multiple schemes coexist", func(t *testing.T) {
+		loader, err := fuda.New().
+			WithResolver("alpha", &prefixResolver{prefix: "a"}).
+			WithResolver("beta", &prefixResolver{prefix: "b"}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "a:one", cfg.A)
+		assert.Equal(t, "b:two", cfg.B)
+	})
+
+	t.Run("overrides global registration", func(t *testing.T) {
+		fuda.RegisterResolver("alpha", &prefixResolver{prefix: "global"})
+		t.Cleanup(func() { fuda.UnregisterResolver("alpha") })
+
+		loader, err := fuda.New().
+			WithResolver("alpha", &prefixResolver{prefix: "local"}).
+			WithResolver("beta", &prefixResolver{prefix: "b"}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "local:one", cfg.A)
+	})
+
+	t.Run("ignored with custom ref resolver", func(t *testing.T) {
+		loader, err := fuda.New().
+			WithResolver("alpha", &prefixResolver{prefix: "a"}).
+			WithRefResolver(&prefixResolver{prefix: "custom"}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg Config
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "custom:one", cfg.A)
+		assert.Equal(t, "custom:two", cfg.B)
+	})
+}
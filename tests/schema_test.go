@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaCommon struct {
+	Version string `yaml:"version" validate:"required"`
+}
+
+type schemaServer struct {
+	Host string `yaml:"host" default:"localhost" validate:"required,hostname" doc:"Listen host"`
+	Port int    `yaml:"port" default:"8080" validate:"min=1,max=65535"`
+}
+
+type schemaNode struct {
+	Name     string        `yaml:"name"`
+	Children []*schemaNode `yaml:"children"`
+}
+
+type schemaConfig struct {
+	schemaCommon `yaml:",inline"`
+
+	Name      string            `yaml:"name" validate:"required,min=3,max=32"`
+	Mode      string            `yaml:"mode" default:"dev" validate:"oneof=dev staging prod"`
+	Level     int               `yaml:"level" validate:"oneof=1 2 3"`
+	Ratio     float64           `yaml:"ratio" validate:"gt=0,lt=1.5"`
+	Enabled   bool              `yaml:"enabled" default:"true"`
+	Timeout   time.Duration     `yaml:"timeout" default:"30s"`
+	MaxSize   fuda.ByteSize     `yaml:"max_size" default:"10MiB"`
+	Admin     string            `yaml:"admin" validate:"email"`
+	Hosts     []string          `yaml:"hosts" validate:"min=1,dive,hostname"`
+	Labels    map[string]string `yaml:"labels"`
+	Token     string            `yaml:"token" env:"TOKEN" validate:"required"`
+	Server    schemaServer      `yaml:"server"`
+	Tree      *schemaNode       `yaml:"tree"`
+	Workers   uint              `yaml:"workers"`
+	CreatedAt time.Time         `yaml:"created_at"`
+	Ignored   string            `yaml:"-"`
+}
+
+func TestSchema(t *testing.T) {
+	data, err := fuda.Schema(&schemaConfig{})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "schemaConfig",
+		"type": "object",
+		"required": ["version", "name"],
+		"properties": {
+			"version": {"type": "string"},
+			"name": {"type": "string", "minLength": 3, "maxLength": 32},
+			"mode": {"type": "string", "default": "dev", "enum": ["dev", "staging", "prod"]},
+			"level": {"type": "integer", "enum": [1, 2, 3]},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1.5},
+			"enabled": {"type": "boolean", "default": true},
+			"timeout": {"type": ["string", "integer"], "default": "30s"},
+			"max_size": {"type": ["string", "integer"], "default": "10MiB"},
+			"admin": {"type": "string", "format": "email"},
+			"hosts": {"type": "array", "items": {"type": "string"}, "minItems": 1},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"token": {"type": "string"},
+			"server": {
+				"type": "object",
+				"properties": {
+					"host": {"type": "string", "default": "localhost", "format": "hostname", "description": "Listen host"},
+					"port": {"type": "integer", "default": 8080, "minimum": 1, "maximum": 65535}
+				}
+			},
+			"tree": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"children": {"type": "array", "items": {"type": "object"}}
+				}
+			},
+			"workers": {"type": "integer", "minimum": 0},
+			"created_at": {"type": "string", "format": "date-time"}
+		}
+	}`, string(data))
+}
+
+func TestSchema_ValidJSON(t *testing.T) {
+	data, err := fuda.Schema(schemaServer{})
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "schemaServer", decoded["title"])
+}
+
+func TestSchema_InvalidTarget(t *testing.T) {
+	for _, target := range []any{nil, "string", 42, []schemaServer{}} {
+		_, err := fuda.Schema(target)
+		require.Error(t, err, "%T", target)
+	}
+}
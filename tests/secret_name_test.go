@@ -0,0 +1,111 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretNameConfig struct {
+	Password string `yaml:"password" secretName:"db_password"`
+	APIKey   string `yaml:"api_key" secretName:"api,jsonpath=$.key"`
+	Token    string `yaml:"token" ref:"file:///etc/app/token" secretName:"token"`
+	Missing  string `yaml:"missing" secretName:"missing" default:"fallback"`
+	User     string `yaml:"user" env:"SN_USER" fuda:"secretName=db_user"`
+}
+
+func secretsFs(t *testing.T, dir string) afero.Fs {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, dir+"/db_password", []byte("hunter2"), 0o600))
+	require.NoError(t, afero.WriteFile(fs, dir+"/api", []byte(`{"key": "k-123"}`), 0o600))
+	require.NoError(t, afero.WriteFile(fs, dir+"/token", []byte("from-secret"), 0o600))
+	require.NoError(t, afero.WriteFile(fs, dir+"/db_user", []byte("admin"), 0o600))
+
+	return fs
+}
+
+func TestSecretName(t *testing.T) {
+	t.Run("default dir", func(t *testing.T) {
+		loader, err := fuda.New().WithFilesystem(secretsFs(t, "/run/secrets")).Build()
+		require.NoError(t, err)
+
+		var cfg secretNameConfig
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "hunter2", cfg.Password)
+		assert.Equal(t, "k-123", cfg.APIKey)
+		assert.Equal(t, "from-secret", cfg.Token, "secretName applies when the ref is not found")
+		assert.Equal(t, "fallback", cfg.Missing)
+		assert.Equal(t, "admin", cfg.User)
+	})
+
+	t.Run("custom dir", func(t *testing.T) {
+		loader, err := fuda.NewLoader(
+			fuda.WithFilesystem(secretsFs(t, "/etc/app/secrets")),
+			fuda.WithSecretsDir("/etc/app/secrets"),
+		)
+		require.NoError(t, err)
+
+		var cfg secretNameConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "hunter2", cfg.Password)
+	})
+
+	t.Run("other sources win", func(t *testing.T) {
+		t.Setenv("SN_USER", "from-env")
+		fs := secretsFs(t, "/run/secrets")
+		require.NoError(t, afero.WriteFile(fs, "/etc/app/token", []byte("from-ref"), 0o600))
+
+		loader, err := fuda.New().
+			FromBytes([]byte("password: from-file\n")).
+			WithFilesystem(fs).
+			Build()
+		require.NoError(t, err)
+
+		var cfg secretNameConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "from-file", cfg.Password)
+		assert.Equal(t, "from-ref", cfg.Token)
+		assert.Equal(t, "from-env", cfg.User)
+	})
+
+	t.Run("fields are sensitive", func(t *testing.T) {
+		redacted, err := fuda.Redact(&secretNameConfig{Password: "hunter2"})
+		require.NoError(t, err)
+		assert.Equal(t, "[REDACTED]", redacted["password"])
+	})
+}
+
+func TestSecretName_InvalidName(t *testing.T) {
+	var cfg struct {
+		Password string `secretName:"../etc/passwd"`
+	}
+
+	loader, err := fuda.New().WithFilesystem(afero.NewMemMapFs()).Build()
+	require.NoError(t, err)
+
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `field 'Password' (tag 'secretName'): secretName "../etc/passwd" must be a file name without a directory`)
+}
+
+func TestSecretName_RequiredHint(t *testing.T) {
+	type Config struct {
+		Password string `yaml:"password" secretName:"db_password" validate:"required"`
+	}
+
+	var cfg Config
+	loader, err := fuda.New().WithFilesystem(afero.NewMemMapFs()).WithSecretsDir("/secrets").Build()
+	require.NoError(t, err)
+
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set yaml key password or file /secrets/db_password")
+}
@@ -0,0 +1,185 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sectionSource = `
+server:
+  port: 8080
+database:
+  host: db.internal
+  pool:
+    size: 10
+cache: redis
+`
+
+type sectionPool struct {
+	Size    int `yaml:"size" default:"5"`
+	MaxIdle int `yaml:"maxIdle" default:"2"`
+}
+
+type sectionDatabase struct {
+	Host string      `yaml:"host" validate:"required"`
+	Port int         `yaml:"port" default:"5432"`
+	Pool sectionPool `yaml:"pool"`
+}
+
+func TestLoadSection(t *testing.T) {
+	t.Run("subtree", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+		require.NoError(t, err)
+
+		var cfg sectionDatabase
+		require.NoError(t, loader.LoadSection("database", &cfg))
+		assert.Equal(t, "db.internal", cfg.Host)
+		assert.Equal(t, 5432, cfg.Port)
+		assert.Equal(t, 10, cfg.Pool.Size)
+		assert.Equal(t, 2, cfg.Pool.MaxIdle)
+	})
+
+	t.Run("nested path", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+		require.NoError(t, err)
+
+		var cfg sectionPool
+		require.NoError(t, loader.LoadSection("database.pool", &cfg))
+		assert.Equal(t, sectionPool{Size: 10, MaxIdle: 2}, cfg)
+	})
+
+	t.Run("missing section uses defaults", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+		require.NoError(t, err)
+
+		var cfg sectionPool
+		require.NoError(t, loader.LoadSection("queue.pool", &cfg))
+		assert.Equal(t, sectionPool{Size: 5, MaxIdle: 2}, cfg)
+	})
+
+	t.Run("validation applies to the section", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+		require.NoError(t, err)
+
+		var cfg sectionDatabase
+		err = loader.LoadSection("server", &cfg)
+		var validationErr *fuda.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("strict keys ignore other sections", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).WithStrictKeys().Build()
+		require.NoError(t, err)
+
+		var cfg sectionPool
+		require.NoError(t, loader.LoadSection("database.pool", &cfg))
+
+		var db struct {
+			Host string `yaml:"host"`
+		}
+		require.ErrorContains(t, loader.LoadSection("database", &db), "pool")
+	})
+
+	t.Run("overrides use document paths", func(t *testing.T) {
+		loader, err := fuda.New().
+			FromBytes([]byte(sectionSource)).
+			WithOverrides(map[string]any{"database.host": "override.internal", "server.port": 9090}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg sectionDatabase
+		require.NoError(t, loader.LoadSection("database", &cfg))
+		assert.Equal(t, "override.internal", cfg.Host)
+		assert.Equal(t, 10, cfg.Pool.Size)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "/config.yaml", []byte(sectionSource), 0o644))
+		loader, err := fuda.New().WithFilesystem(fs).FromFileStream("/config.yaml").Build()
+		require.NoError(t, err)
+
+		var cfg sectionDatabase
+		require.NoError(t, loader.LoadSection("database", &cfg))
+		assert.Equal(t, "db.internal", cfg.Host)
+	})
+
+	t.Run("not a mapping", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+		require.NoError(t, err)
+
+		var cfg sectionPool
+		err = loader.LoadSection("cache.pool", &cfg)
+		require.ErrorContains(t, err, `"cache" is not a mapping`)
+	})
+
+	t.Run("empty section", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+		require.NoError(t, err)
+
+		var cfg sectionDatabase
+		require.Error(t, loader.LoadSection("", &cfg))
+	})
+}
+
+func TestRegisterSection_LoadAll(t *testing.T) {
+	type serverConfig struct {
+		Port int    `yaml:"port"`
+		Host string `yaml:"host" default:"0.0.0.0"`
+	}
+
+	var (
+		server serverConfig
+		db     sectionDatabase
+		pool   sectionPool
+	)
+	fuda.RegisterSection("server", &server)
+	fuda.RegisterSection("database", &db)
+	fuda.RegisterSection("database.pool", &pool)
+	t.Cleanup(func() {
+		fuda.UnregisterSection("server")
+		fuda.UnregisterSection("database")
+		fuda.UnregisterSection("database.pool")
+	})
+
+	loader, err := fuda.New().FromBytes([]byte(sectionSource)).Build()
+	require.NoError(t, err)
+	require.NoError(t, loader.LoadAll())
+
+	assert.Equal(t, serverConfig{Port: 8080, Host: "0.0.0.0"}, server)
+	assert.Equal(t, "db.internal", db.Host)
+	assert.Equal(t, sectionPool{Size: 10, MaxIdle: 2}, pool)
+
+	t.Run("failing section keeps its config", func(t *testing.T) {
+		loader, err := fuda.New().
+			FromBytes([]byte("server:\n  port: 9090\ndatabase:\n  port: 1\n")).
+			Build()
+		require.NoError(t, err)
+
+		err = loader.LoadAll()
+		require.ErrorContains(t, err, "section database:")
+		var validationErr *fuda.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+
+		assert.Equal(t, 9090, server.Port)
+		assert.Equal(t, "db.internal", db.Host, "failed section is not replaced")
+		assert.Equal(t, sectionPool{Size: 5, MaxIdle: 2}, pool)
+	})
+}
+
+func TestRegisterSection_Panics(t *testing.T) {
+	var cfg sectionPool
+	fuda.RegisterSection("panics", &cfg)
+	t.Cleanup(func() { fuda.UnregisterSection("panics") })
+
+	assert.Panics(t, func() { fuda.RegisterSection("panics", &cfg) })
+	assert.Panics(t, func() { fuda.RegisterSection("", &cfg) })
+	assert.Panics(t, func() { fuda.RegisterSection("other", cfg) })
+	assert.Panics(t, func() { fuda.RegisterSection("other", (*sectionPool)(nil)) })
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sopsIdentity decrypts fixtures/sops.enc.yaml. It is a test-only key.
+const sopsIdentity = "AGE-SECRET-KEY-17RGUUPQR30C4A5RANE3EDL2E87VFFFG3DGHACWZ4F6WC4TJJAFZS0KRV0N"
+
+type sopsConfig struct {
+	App struct {
+		Name  string `yaml:"name"`
+		Port  int    `yaml:"port"`
+		Debug bool   `yaml:"debug"`
+	} `yaml:"app"`
+	Database struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" secret:"true"`
+		Pool     int    `yaml:"pool_unencrypted"`
+	} `yaml:"database"`
+}
+
+func TestSOPS_FromFile(t *testing.T) {
+	loader, err := fuda.New().
+		FromFile("fixtures/sops.enc.yaml").
+		WithAgeIdentity(sopsIdentity).
+		WithStrictKeys().
+		Build()
+	require.NoError(t, err)
+
+	var cfg sopsConfig
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "billing", cfg.App.Name)
+	assert.Equal(t, 8443, cfg.App.Port)
+	assert.False(t, cfg.App.Debug)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, "s3cr3t", cfg.Database.Password)
+	assert.Equal(t, 10, cfg.Database.Pool)
+}
+
+func TestSOPS_Layered(t *testing.T) {
+	encrypted, err := os.ReadFile("fixtures/sops.enc.yaml")
+	require.NoError(t, err)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/base.yaml", []byte("app:\n  name: base\n  port: 80\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/secrets.enc.yaml", encrypted, 0o644))
+
+	loader, err := fuda.New().
+		WithFilesystem(fs).
+		FromFiles("/base.yaml", "/secrets.enc.yaml").
+		WithAgeIdentity(sopsIdentity).
+		Build()
+	require.NoError(t, err)
+
+	var cfg sopsConfig
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "billing", cfg.App.Name)
+	assert.Equal(t, "s3cr3t", cfg.Database.Password)
+}
+
+func TestSOPS_Errors(t *testing.T) {
+	var cfg sopsConfig
+
+	loader, err := fuda.New().FromFile("fixtures/sops.enc.yaml").Build()
+	require.NoError(t, err)
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt sops file fixtures/sops.enc.yaml: no configured key can decrypt the sops data key")
+
+	encrypted, err := os.ReadFile("fixtures/sops.enc.yaml")
+	require.NoError(t, err)
+	tampered := strings.Replace(string(encrypted), "pool_unencrypted: 10", "pool_unencrypted: 99", 1)
+	loader, err = fuda.New().
+		FromBytes([]byte(tampered)).
+		WithAgeIdentity(sopsIdentity).
+		Build()
+	require.NoError(t, err)
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sops MAC mismatch")
+}
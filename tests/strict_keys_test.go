@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictDatabase struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+type strictConfig struct {
+	Database strictDatabase            `yaml:"database"`
+	Replicas []strictDatabase          `yaml:"replicas"`
+	Shards   map[string]strictDatabase `yaml:"shards"`
+	Timeout  fuda.Duration             `yaml:"timeout"`
+	LogLevel string                    // key "loglevel"
+}
+
+func TestWithStrictKeys(t *testing.T) {
+	t.Run("known keys load", func(t *testing.T) {
+		source := `
+database:
+  host: db.internal
+  port: 5432
+replicas:
+  - host: r1
+shards:
+  eu:
+    host: eu.internal
+timeout: 30s
+loglevel: debug
+`
+		loader, err := fuda.New().FromBytes([]byte(source)).WithStrictKeys().Build()
+		require.NoError(t, err)
+
+		var cfg strictConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "db.internal", cfg.Database.Host)
+		assert.Equal(t, 30*time.Second, cfg.Timeout.Duration())
+		assert.Equal(t, "debug", cfg.LogLevel)
+	})
+
+	t.Run("unknown keys rejected", func(t *testing.T) {
+		source := `
+databse:
+  host: db.internal
+replicas:
+  - hots: r1
+shards:
+  eu:
+    prot: 5432
+zzz: 1
+`
+		loader, err := fuda.New().FromBytes([]byte(source)).WithStrictKeys().Build()
+		require.NoError(t, err)
+
+		var cfg strictConfig
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+
+		var loadErr *fuda.LoadError
+		require.True(t, errors.As(err, &loadErr))
+		require.Len(t, loadErr.Errors, 4)
+
+		assert.Equal(t, "databse", loadErr.Errors[0].Path)
+		assert.Equal(t, `unknown key at line 2, did you mean "database"?`, loadErr.Errors[0].Message)
+		assert.Equal(t, "replicas[0].hots", loadErr.Errors[1].Path)
+		assert.Contains(t, loadErr.Errors[1].Message, `did you mean "host"?`)
+		assert.Equal(t, "shards.eu.prot", loadErr.Errors[2].Path)
+		assert.Contains(t, loadErr.Errors[2].Message, `did you mean "port"?`)
+		assert.Equal(t, "zzz", loadErr.Errors[3].Path)
+		assert.Equal(t, "unknown key at line 9", loadErr.Errors[3].Message)
+	})
+
+	t.Run("unknown keys ignored by default", func(t *testing.T) {
+		loader, err := fuda.New().FromBytes([]byte("databse:\n  host: x\n")).Build()
+		require.NoError(t, err)
+
+		var cfg strictConfig
+		require.NoError(t, loader.Load(&cfg))
+	})
+
+	t.Run("inline fields", func(t *testing.T) {
+		type Base struct {
+			Name string `yaml:"name"`
+		}
+		type Inline struct {
+			Base  `yaml:",inline"`
+			Extra map[string]any `yaml:",inline"`
+		}
+
+		loader, err := fuda.New().
+			FromBytes([]byte("name: svc\nanything: goes\n")).
+			WithStrictKeys().
+			Build()
+		require.NoError(t, err)
+
+		var cfg Inline
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "svc", cfg.Name)
+		assert.Equal(t, "goes", cfg.Extra["anything"])
+	})
+
+	t.Run("json source", func(t *testing.T) {
+		loader, err := fuda.New().
+			FromBytes([]byte(`{"database": {"hots": "x"}}`)).
+			WithStrictKeys().
+			Build()
+		require.NoError(t, err)
+
+		var cfg strictConfig
+		err = loader.Load(&cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `field 'database.hots': unknown key at line 1, did you mean "host"?`)
+	})
+}
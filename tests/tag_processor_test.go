@@ -0,0 +1,152 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterTagProcessor(t *testing.T) {
+	type Database struct {
+		Host string `yaml:"host" consul:"db/host"`
+	}
+	type Config struct {
+		Name     string   `yaml:"name" default:"app"`
+		Database Database `yaml:"database"`
+		Token    string   `consul:"token"`
+		Beta     bool     `feature:"beta"`
+		DSN      string   `dsn:"${.Name}@${.Token}"`
+	}
+
+	var paths []string
+	fuda.RegisterTagProcessor("consul", func(ctx context.Context, f fuda.TagField) error {
+		paths = append(paths, f.Path)
+		if !f.Value.IsZero() {
+			return nil
+		}
+		data, err := f.Resolver.Resolve(ctx, "mem://"+f.Tag)
+		if err != nil {
+			return err
+		}
+		f.Value.SetString(string(data))
+
+		return nil
+	})
+	fuda.RegisterTagProcessor("feature", func(_ context.Context, f fuda.TagField) error {
+		on, err := strconv.ParseBool(map[string]string{"beta": "true"}[f.Tag])
+		if err != nil {
+			return err
+		}
+		f.Value.SetBool(on)
+
+		return nil
+	})
+	t.Cleanup(func() {
+		fuda.UnregisterTagProcessor("consul")
+		fuda.UnregisterTagProcessor("feature")
+	})
+
+	var trace bytes.Buffer
+	loader, err := fuda.New().
+		FromBytes([]byte("database:\n  host: file-host\n")).
+		WithResolver("mem", &prefixResolver{prefix: "kv"}).
+		WithTrace(&trace).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	assert.Equal(t, "file-host", cfg.Database.Host, "processor sees values of built-in sources")
+	assert.Equal(t, "kv:token", cfg.Token)
+	assert.True(t, cfg.Beta)
+	assert.Equal(t, "app@kv:token", cfg.DSN, "dsn runs after custom tags of earlier fields")
+	assert.Equal(t, []string{"Database.Host", "Token"}, paths)
+	assert.Contains(t, trace.String(), "Token: yaml unset, consul=token (used)")
+}
+
+func TestRegisterTagProcessor_Error(t *testing.T) {
+	type Config struct {
+		Region string `yaml:"region" region:"eu"`
+	}
+
+	fuda.RegisterTagProcessor("region", func(context.Context, fuda.TagField) error {
+		return errors.New("unknown region")
+	})
+	t.Cleanup(func() { fuda.UnregisterTagProcessor("region") })
+
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'Region' (tag 'region'): unknown region")
+}
+
+func TestRegisterTagProcessor_FieldErrorSource(t *testing.T) {
+	type Config struct {
+		Region string `yaml:"region" zone:"regions/eu"`
+	}
+
+	fuda.RegisterTagProcessor("zone", func(_ context.Context, f fuda.TagField) error {
+		return &fuda.FieldError{Source: "consul://" + f.Tag, Message: "key not found"}
+	})
+	t.Cleanup(func() { fuda.UnregisterTagProcessor("zone") })
+
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'Region' (tag 'zone', source 'consul://regions/eu'): key not found")
+}
+
+func TestRegisterTagProcessor_Snapshot(t *testing.T) {
+	type Config struct {
+		Name string `upper:"x"`
+	}
+
+	fuda.RegisterTagProcessor("upper", func(_ context.Context, f fuda.TagField) error {
+		f.Value.SetString("SET")
+
+		return nil
+	})
+	loader, err := fuda.New().Build()
+	require.NoError(t, err)
+	fuda.UnregisterTagProcessor("upper")
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "SET", cfg.Name, "loaders keep the processors registered when built")
+
+	loader, err = fuda.New().Build()
+	require.NoError(t, err)
+
+	cfg = Config{}
+	require.NoError(t, loader.Load(&cfg))
+	assert.Empty(t, cfg.Name)
+}
+
+func TestRegisterTagProcessor_Panics(t *testing.T) {
+	noop := func(context.Context, fuda.TagField) error { return nil }
+
+	assert.PanicsWithValue(t, "fuda: RegisterTagProcessor called with empty tag name", func() {
+		fuda.RegisterTagProcessor("", noop)
+	})
+	assert.PanicsWithValue(t, "fuda: RegisterTagProcessor called with built-in tag env", func() {
+		fuda.RegisterTagProcessor("env", noop)
+	})
+	assert.PanicsWithValue(t, "fuda: RegisterTagProcessor called with nil processor for tag consul", func() {
+		fuda.RegisterTagProcessor("consul", nil)
+	})
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticResolver map[string]string
+
+func (r staticResolver) Resolve(_ context.Context, uri string) ([]byte, error) {
+	return []byte(r[uri]), nil
+}
+
+func TestWithTrace(t *testing.T) {
+	type Database struct {
+		Password string `ref:"mem://db-password"`
+		Port     int    `yaml:"port" default:"5432"`
+	}
+	type Config struct {
+		Host     string   `yaml:"host" env:"HOST" default:"localhost"`
+		Port     int      `yaml:"port" env:"PORT" default:"8080"`
+		Debug    bool     `yaml:"debug"`
+		Database Database `yaml:"database"`
+	}
+
+	t.Setenv("TRACE_HOST", "env.example.com")
+
+	var out strings.Builder
+	loader, err := fuda.New().
+		FromBytes([]byte("port: 9090\n")).
+		WithEnvPrefix("TRACE_").
+		WithRefResolver(staticResolver{"mem://db-password": "s3cret"}).
+		WithTrace(&out).
+		Build()
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, loader.Load(&cfg))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"Host: yaml unset, env TRACE_HOST=env.example.com (used), default=localhost",
+		"Port: yaml=9090 (used), env TRACE_PORT unset, default=8080",
+		"Debug: yaml unset, zero value",
+		"Database.Password: yaml unset, ref=mem://db-password (used)",
+		"Database.Port: yaml unset, default=5432 (used)",
+	}, lines)
+	assert.NotContains(t, out.String(), "s3cret", "resolved ref contents must not be traced")
+}
@@ -0,0 +1,157 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type selfTLS struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+func (t *selfTLS) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if t.CertFile == "" {
+		errs = append(errs, &fuda.FieldError{Path: "CertFile", Message: "required when TLS is enabled"})
+	}
+	if t.KeyFile == "" {
+		errs = append(errs, &fuda.FieldError{Path: "KeyFile", Message: "required when TLS is enabled"})
+	}
+
+	return errors.Join(errs...)
+}
+
+type selfPool struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+// Validate has a value receiver.
+func (p selfPool) Validate() error {
+	if p.Min > p.Max {
+		return fmt.Errorf("min %d exceeds max %d", p.Min, p.Max)
+	}
+
+	return nil
+}
+
+type selfConfig struct {
+	Host    string              `yaml:"host" validate:"required"`
+	TLS     selfTLS             `yaml:"tls"`
+	Pools   []selfPool          `yaml:"pools"`
+	Tenants map[string]selfPool `yaml:"tenants"`
+}
+
+func (c *selfConfig) Validate() error {
+	if c.Host == "localhost" && c.TLS.Enabled {
+		return errors.New("TLS cannot be enabled for localhost")
+	}
+
+	return nil
+}
+
+func loadSelfConfig(t *testing.T, yaml string) (*selfConfig, error) {
+	t.Helper()
+
+	loader, err := fuda.New().FromBytes([]byte(yaml)).Build()
+	require.NoError(t, err)
+
+	var cfg selfConfig
+
+	return &cfg, loader.Load(&cfg)
+}
+
+func TestValidateMethod(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg, err := loadSelfConfig(t, "host: db\ntls: {enabled: true, cert_file: c, key_file: k}\npools: [{min: 1, max: 2}]")
+		require.NoError(t, err)
+		assert.Equal(t, "c", cfg.TLS.CertFile)
+	})
+
+	t.Run("nested errors", func(t *testing.T) {
+		_, err := loadSelfConfig(t, "host: db\ntls: {enabled: true}")
+		require.Error(t, err)
+
+		var verr *fuda.ValidationError
+		require.ErrorAs(t, err, &verr)
+		require.Len(t, verr.Errors, 2, "each joined error should be reported")
+		assert.Contains(t, err.Error(), "field 'TLS.CertFile': required when TLS is enabled")
+		assert.Contains(t, err.Error(), "field 'TLS.KeyFile': required when TLS is enabled")
+
+		paths := make([]string, 0, 2)
+		for _, fe := range fuda.FieldErrors(err) {
+			paths = append(paths, fe.Path)
+		}
+		assert.Equal(t, []string{"TLS.CertFile", "TLS.KeyFile"}, paths)
+	})
+
+	t.Run("root error", func(t *testing.T) {
+		_, err := loadSelfConfig(t, "host: localhost\ntls: {enabled: true, cert_file: c, key_file: k}")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TLS cannot be enabled for localhost")
+
+		var fe *fuda.FieldError
+		assert.False(t, errors.As(err, &fe), "a plain error at the root should not become a FieldError")
+	})
+
+	t.Run("value receivers in slices and maps", func(t *testing.T) {
+		_, err := loadSelfConfig(t, "host: db\npools: [{min: 1, max: 2}, {min: 5, max: 3}]\ntenants: {acme: {min: 9, max: 1}}")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field 'Pools[1]': min 5 exceeds max 3")
+		assert.Contains(t, err.Error(), "field 'Tenants[acme]': min 9 exceeds max 1")
+		assert.NotContains(t, err.Error(), "Pools[0]")
+	})
+
+	t.Run("merged with tag validation", func(t *testing.T) {
+		_, err := loadSelfConfig(t, "tls: {enabled: true, cert_file: c}")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'required' tag")
+		assert.Contains(t, err.Error(), "field 'TLS.KeyFile': required when TLS is enabled")
+	})
+
+	t.Run("AfterValidate skipped on failure", func(t *testing.T) {
+		called := false
+		loader, err := fuda.New().
+			FromBytes([]byte("host: db\npools: [{min: 2, max: 1}]")).
+			WithHook(fuda.AfterValidate, func(context.Context, any) error {
+				called = true
+
+				return nil
+			}).
+			Build()
+		require.NoError(t, err)
+
+		var cfg selfConfig
+		require.Error(t, loader.Load(&cfg))
+		assert.False(t, called)
+	})
+}
+
+func TestValidateMethod_SetDefaults(t *testing.T) {
+	cfg := selfConfig{Pools: []selfPool{{Min: 2, Max: 1}}}
+	require.NoError(t, fuda.SetDefaults(&cfg), "SetDefaults does not validate by default")
+
+	err := fuda.SetDefaults(&cfg, fuda.WithValidation(true))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'Pools[0]': min 2 exceeds max 1")
+}
+
+func TestValidateMethod_LoadEnv(t *testing.T) {
+	cfg := selfConfig{Pools: []selfPool{{Min: 2, Max: 1}}}
+	require.NoError(t, fuda.LoadEnv(&cfg), "LoadEnv does not validate")
+	require.NoError(t, fuda.LoadEnvWithPrefix("APP_", &cfg), "LoadEnvWithPrefix does not validate")
+}
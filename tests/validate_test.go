@@ -0,0 +1,22 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMethod_Standalone(t *testing.T) {
+	cfg := selfConfig{Host: "db", TLS: selfTLS{Enabled: true, CertFile: "c"}}
+
+	err := fuda.Validate(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field 'TLS.KeyFile': required when TLS is enabled")
+
+	cfg.TLS.KeyFile = "k"
+	require.NoError(t, fuda.Validate(&cfg))
+}
@@ -0,0 +1,75 @@
+//go:build !fuda_minimal
+
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type valueConfig struct {
+	Host  string   `yaml:"host" default:"localhost"`
+	Port  int      `yaml:"port" validate:"min=1"`
+	Hosts []string `yaml:"hosts"`
+}
+
+func TestValue_ZeroValue(t *testing.T) {
+	var v fuda.Value[valueConfig]
+
+	assert.Nil(t, v.Get())
+	assert.Equal(t, valueConfig{}, v.Snapshot())
+}
+
+func TestValue_StoreGetSnapshot(t *testing.T) {
+	v := fuda.NewValue(&valueConfig{Host: "a", Port: 1})
+	assert.Equal(t, "a", v.Get().Host)
+
+	snap := v.Snapshot()
+	snap.Host = "changed"
+	assert.Equal(t, "a", v.Get().Host, "modifying a snapshot must not affect the stored config")
+
+	next := &valueConfig{Host: "b", Port: 2}
+	v.Store(next)
+	assert.Same(t, next, v.Get())
+}
+
+func TestValue_Load(t *testing.T) {
+	var v fuda.Value[valueConfig]
+
+	loader, err := fuda.New().FromBytes([]byte("port: 8080\n")).Build()
+	require.NoError(t, err)
+	require.NoError(t, v.Load(loader))
+	assert.Equal(t, valueConfig{Host: "localhost", Port: 8080}, *v.Get())
+
+	// A failed load keeps the current config
+	loader, err = fuda.New().FromBytes([]byte("port: 0\n")).Build()
+	require.NoError(t, err)
+	require.Error(t, v.Load(loader))
+	assert.Equal(t, 8080, v.Get().Port)
+}
+
+func TestValue_Concurrent(t *testing.T) {
+	v := fuda.NewValue(&valueConfig{Port: 1})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 1000 {
+				cfg := v.Get()
+				assert.Positive(t, cfg.Port)
+			}
+		})
+	}
+	wg.Go(func() {
+		for i := range 1000 {
+			v.Store(&valueConfig{Port: i + 1})
+		}
+	})
+	wg.Wait()
+
+	assert.Equal(t, 1000, v.Get().Port)
+}
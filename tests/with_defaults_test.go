@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/arloliu/fuda"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type seedServer struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port" default:"80"`
+}
+
+type seedLimit struct {
+	RPS   int `yaml:"rps"`
+	Burst int `yaml:"burst"`
+}
+
+type seedConfig struct {
+	Name     string               `yaml:"name" default:"from-tag"`
+	Region   string               `yaml:"region" env:"SEED_REGION"`
+	Token    string               `yaml:"token" ref:"file:///secrets/token"`
+	Servers  []seedServer         `yaml:"servers"`
+	Limits   map[string]seedLimit `yaml:"limits"`
+	Replicas *int                 `yaml:"replicas"`
+}
+
+func seedDefaults() *seedConfig {
+	replicas := 3
+
+	return &seedConfig{
+		Name:     "from-instance",
+		Region:   "us-east-1",
+		Token:    "dev-token",
+		Servers:  []seedServer{{Host: "a.internal", Port: 8080}, {Host: "b.internal"}},
+		Limits:   map[string]seedLimit{"api": {RPS: 100, Burst: 10}, "admin": {RPS: 5}},
+		Replicas: &replicas,
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	t.Run("seeds missing values", func(t *testing.T) {
+		loader, err := fuda.New().
+			FromBytes([]byte("{}")).
+			WithFilesystem(afero.NewMemMapFs()).
+			WithDefaults(seedDefaults()).
+			Build()
+		require.NoError(t, err)
+
+		var cfg seedConfig
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "from-instance", cfg.Name, "the instance should win over the default tag")
+		assert.Equal(t, "us-east-1", cfg.Region)
+		assert.Equal(t, "dev-token", cfg.Token, "the instance applies when the ref is not found")
+		assert.Equal(t, []seedServer{{Host: "a.internal", Port: 8080}, {Host: "b.internal", Port: 80}}, cfg.Servers)
+		assert.Equal(t, map[string]seedLimit{"api": {RPS: 100, Burst: 10}, "admin": {RPS: 5}}, cfg.Limits)
+		require.NotNil(t, cfg.Replicas)
+		assert.Equal(t, 3, *cfg.Replicas)
+	})
+
+	t.Run("other sources win", func(t *testing.T) {
+		t.Setenv("SEED_REGION", "eu-west-1")
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "/secrets/token", []byte("vault-token"), 0o600))
+
+		loader, err := fuda.NewLoader(
+			fuda.FromBytes([]byte("name: from-file\nservers: [{host: c.internal}]\nlimits: {api: {rps: 1}}\n")),
+			fuda.WithFilesystem(fs),
+			fuda.WithDefaults(*seedDefaults()),
+		)
+		require.NoError(t, err)
+
+		var cfg seedConfig
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "from-file", cfg.Name)
+		assert.Equal(t, "eu-west-1", cfg.Region)
+		assert.Equal(t, "vault-token", cfg.Token, "a ref should replace the instance value")
+		assert.Equal(t, []seedServer{{Host: "c.internal", Port: 80}}, cfg.Servers, "a file slice replaces the default slice")
+		assert.Equal(t, map[string]seedLimit{"api": {RPS: 1}, "admin": {RPS: 5}}, cfg.Limits, "a file map keeps other default entries")
+	})
+
+	t.Run("defaults are not shared", func(t *testing.T) {
+		defaults := seedDefaults()
+		loader, err := fuda.New().FromBytes([]byte("{}")).WithDefaults(defaults).Build()
+		require.NoError(t, err)
+
+		// Changes after WithDefaults are not seen
+		defaults.Servers[0].Host = "changed"
+
+		var first, second seedConfig
+		require.NoError(t, loader.Load(&first))
+		first.Servers[0].Host = "mutated"
+		first.Limits["api"] = seedLimit{}
+		*first.Replicas = 9
+
+		require.NoError(t, loader.Load(&second))
+		assert.Equal(t, "a.internal", second.Servers[0].Host)
+		assert.Equal(t, 100, second.Limits["api"].RPS)
+		assert.Equal(t, 3, *second.Replicas)
+	})
+}
+
+func TestWithDefaults_Errors(t *testing.T) {
+	_, err := fuda.New().FromBytes([]byte("{}")).WithDefaults(nil).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "struct or pointer to struct")
+
+	_, err = fuda.New().FromBytes([]byte("{}")).WithDefaults(42).Build()
+	require.Error(t, err)
+
+	loader, err := fuda.New().FromBytes([]byte("{}")).WithDefaults(seedServer{}).Build()
+	require.NoError(t, err)
+	var cfg seedConfig
+	err = loader.Load(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "do not match target type")
+}
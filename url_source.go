@@ -0,0 +1,174 @@
+//go:build !fuda_minimal
+
+package fuda
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// maxURLSourceSize bounds the config document fetched by a URLSource.
+const maxURLSourceSize = 16 * 1024 * 1024
+
+// URLSource fetches a config document over HTTP(S), for Builder.FromURL.
+// It remembers the ETag of the last response and sends it as If-None-Match,
+// so an unchanged document is not downloaded again. A URLSource is safe for
+// concurrent use.
+type URLSource struct {
+	url    string
+	client *http.Client
+	header http.Header
+
+	mu   sync.Mutex
+	etag string
+	body []byte
+}
+
+// URLOption configures a URLSource.
+type URLOption func(*URLSource)
+
+// WithHeader adds a request header, such as an API key, sent with every
+// fetch.
+func WithHeader(key, value string) URLOption {
+	return func(s *URLSource) {
+		s.header.Add(key, value)
+	}
+}
+
+// WithBearerToken sends token in an "Authorization: Bearer" header.
+func WithBearerToken(token string) URLOption {
+	return func(s *URLSource) {
+		s.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithHTTPClient sets the client used to fetch the document, for example to
+// configure TLS client certificates. The default is http.DefaultClient.
+func WithHTTPClient(c *http.Client) URLOption {
+	return func(s *URLSource) {
+		s.client = c
+	}
+}
+
+// NewURLSource returns a source fetching the document at rawURL, which must
+// be an http or https URL.
+//
+// Pass its Fetch method to watcher.Builder.FromSource to reload the config
+// when the document changes; each poll is then a cheap conditional request.
+func NewURLSource(rawURL string, opts ...URLOption) (*URLSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid config URL %q: scheme must be http or https", u.Redacted())
+	}
+
+	s := &URLSource{url: rawURL, client: http.DefaultClient, header: make(http.Header)}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// String returns the URL without its password, for messages.
+func (s *URLSource) String() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return s.url
+	}
+
+	return u.Redacted()
+}
+
+// Fetch returns the current document. When the server answers 304 Not
+// Modified to the cached ETag, the cached document is returned.
+func (s *URLSource) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = s.header.Clone()
+
+	s.mu.Lock()
+	etag, cached := s.etag, s.body
+	s.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		return bytes.Clone(cached), nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status %s", s, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxURLSourceSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", s, err)
+	}
+	if len(data) > maxURLSourceSize {
+		return nil, fmt.Errorf("failed to fetch %s: document exceeds maximum size of %d bytes", s, maxURLSourceSize)
+	}
+
+	s.mu.Lock()
+	s.etag, s.body = resp.Header.Get("ETag"), data
+	s.mu.Unlock()
+
+	return bytes.Clone(data), nil
+}
+
+// FromURL fetches the configuration document from an http or https URL at
+// every load, bounded by WithTimeout. Options add headers or a bearer token,
+// or set the HTTP client. The document's ETag is remembered, so repeated
+// loads of an unchanged document are cheap conditional requests. The format
+// (YAML or JSON) is auto-detected. See NewURLSource for use with a watcher.
+//
+// Example:
+//
+//	loader, err := fuda.New().
+//	    FromURL("https://config.internal/apps/billing.yaml",
+//	        fuda.WithBearerToken(os.Getenv("CONFIG_TOKEN"))).
+//	    WithTimeout(10 * time.Second).
+//	    Build()
+func (b *Builder) FromURL(rawURL string, opts ...URLOption) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	src, err := NewURLSource(rawURL, opts...)
+	if err != nil {
+		b.err = err
+
+		return b
+	}
+
+	b.source = nil
+	b.layers = nil
+	b.name = src.String()
+	b.path = ""
+	b.fetch = src.Fetch
+	b.open = nil
+	b.object = ""
+
+	return b
+}
+
+// FromURL returns an option that fetches configuration from an http or
+// https URL at every load. See Builder.FromURL.
+func FromURL(rawURL string, opts ...URLOption) LoaderOption {
+	return func(b *Builder) { b.FromURL(rawURL, opts...) }
+}
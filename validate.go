@@ -0,0 +1,180 @@
+//go:build !fuda_minimal
+
+package fuda
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/arloliu/fuda/internal/loader"
+	"github.com/go-playground/validator/v10"
+)
+
+// WithValidator sets a custom validator instance.
+// If not set, a default validator is used. Call RegisterValidations on a
+// custom validator to keep fuda's rules, such as precision.
+func (b *Builder) WithValidator(v *validator.Validate) *Builder {
+	b.config.validator = v
+
+	return b
+}
+
+// WithLoaderValidator returns an option that sets a custom validator
+// instance. See Builder.WithValidator; the name differs from WithValidator,
+// which configures SetDefaults and Validate.
+func WithLoaderValidator(v *validator.Validate) LoaderOption {
+	return func(b *Builder) { b.WithValidator(v) }
+}
+
+// WithValidator sets a custom validator instance for SetDefaults or Validate.
+// Use this to apply custom validation rules or tags.
+//
+// For SetDefaults:
+// This option only takes effect if validation is enabled via WithValidation(true).
+//
+// For Validate:
+// This option overrides the default validator.
+//
+// Example:
+//
+//	v := validator.New()
+//	v.RegisterValidation("custom", customFunc)
+//
+//	fuda.SetDefaults(&cfg, fuda.WithValidation(true), fuda.WithValidator(v))
+func WithValidator(v *validator.Validate) Option {
+	return func(c *config) {
+		c.validator = v
+	}
+}
+
+// Validate runs validation on target using the `validate` tag, the
+// validate rules of `fuda` tags, and the Validate methods of its structs
+// (see Validator).
+// No loading, default processing, or env resolution occurs.
+// Only validation is performed.
+func Validate(target any, opts ...Option) error {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	v := cfg.validator
+	if v == nil {
+		v = newValidator()
+	}
+
+	errs := append(loader.Validate(v, target), loader.ValidateSelf(target)...)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	// Keep returning the validator's own error when no fuda tag rule failed
+	var verrs validator.ValidationErrors
+	if len(errs) == 1 && errors.As(errs[0], &verrs) {
+		return errs[0]
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+// RegisterValidations registers fuda's validation rules on v. Validators
+// created by fuda have them already; call this for a validator passed to
+// WithValidator.
+//
+// Rules:
+//   - precision=N: the value has at most N digits after the decimal point.
+//     Applies to Decimal types, floats, and numeric strings; integers always
+//     pass. Trailing zeros don't count, so "1.50" has precision 1.
+//
+// Example:
+//
+//	v := validator.New()
+//	if err := fuda.RegisterValidations(v); err != nil {
+//	    return err
+//	}
+//
+//	loader, _ := fuda.New().FromFile("config.yaml").WithValidator(v).Build()
+func RegisterValidations(v *validator.Validate) error {
+	return v.RegisterValidation("precision", validatePrecision)
+}
+
+// newValidator returns a validator with fuda's validation rules registered.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	if err := RegisterValidations(v); err != nil {
+		panic("fuda: " + err.Error())
+	}
+
+	return v
+}
+
+// validatePrecision implements the precision=N rule.
+func validatePrecision(fl validator.FieldLevel) bool {
+	places, err := strconv.Atoi(fl.Param())
+	if err != nil || places < 0 {
+		panic(fmt.Sprintf("fuda: invalid precision parameter %q on field %s", fl.Param(), fl.StructFieldName()))
+	}
+
+	text, ok := decimalText(fl.Field())
+	if !ok {
+		return false
+	}
+
+	n, ok := decimalPlaces(text)
+
+	return ok && n <= places
+}
+
+// decimalText returns the decimal representation of v, or false if v is not
+// a number.
+func decimalText(v reflect.Value) (string, bool) {
+	if reflect.PointerTo(v.Type()).Implements(decimalType) {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+
+		return p.Interface().(Decimal).String(), true
+	}
+
+	//nolint:exhaustive // only numeric kinds have a precision
+	switch v.Kind() {
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "0", true
+	case reflect.String:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// decimalPlaces returns the number of significant digits after the decimal
+// point in s, a decimal number with an optional exponent ("12.50", "1.5e-3").
+func decimalPlaces(s string) (int, bool) {
+	mantissa, exp := strings.TrimSpace(s), 0
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		e, err := strconv.Atoi(mantissa[i+1:])
+		if err != nil {
+			return 0, false
+		}
+		mantissa, exp = mantissa[:i], e
+	}
+	mantissa = strings.TrimPrefix(strings.TrimPrefix(mantissa, "-"), "+")
+
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+	digits := intPart + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return 0, false
+	}
+
+	trimmed := strings.TrimRight(digits, "0")
+	places := len(frac) - exp - (len(digits) - len(trimmed))
+
+	return max(places, 0), true
+}
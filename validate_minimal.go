@@ -0,0 +1,10 @@
+//go:build fuda_minimal
+
+package fuda
+
+import "github.com/arloliu/fuda/internal/loader"
+
+// newValidator returns nil: fuda_minimal builds do not validate.
+func newValidator() *loader.Validator {
+	return nil
+}
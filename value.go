@@ -0,0 +1,82 @@
+package fuda
+
+import "sync/atomic"
+
+// Value is a thread-safe handle to a configuration of type T, replacing the
+// hand-rolled atomic.Pointer pattern for configs that are reloaded at run
+// time. Readers call Get from any goroutine while a writer, typically the
+// config watcher (see watcher.Bind), swaps in new configs with Store.
+//
+// The zero Value is ready to use and holds no config.
+//
+// Example:
+//
+//	var cfg fuda.Value[Config]
+//	if err := watcher.Bind(w, &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	// In a request handler:
+//	timeout := cfg.Get().Timeout
+type Value[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewValue returns a Value holding cfg.
+func NewValue[T any](cfg *T) *Value[T] {
+	v := &Value[T]{}
+	v.Store(cfg)
+
+	return v
+}
+
+// Get returns the current config, or nil if none has been stored. The
+// returned config is shared with other readers and must not be modified;
+// use Snapshot for a copy to change.
+//
+// Call Get once per unit of work, such as a request, and read every field
+// from the result, so the values seen are consistent even if a reload
+// happens in between.
+func (v *Value[T]) Get() *T {
+	return v.p.Load()
+}
+
+// Snapshot returns a copy of the current config, or the zero T if none has
+// been stored. The copy is shallow: slices, maps, and pointers inside it are
+// still shared with the stored config.
+func (v *Value[T]) Snapshot() T {
+	if cfg := v.p.Load(); cfg != nil {
+		return *cfg
+	}
+
+	var zero T
+
+	return zero
+}
+
+// Store replaces the current config with cfg. cfg must not be modified
+// afterwards, since readers may hold it.
+func (v *Value[T]) Store(cfg *T) {
+	v.p.Store(cfg)
+}
+
+// Load loads the configuration with l and stores it. On failure the current
+// config is kept and the error is returned.
+//
+// Example:
+//
+//	loader, _ := fuda.New().FromFile("config.yaml").Build()
+//
+//	var cfg fuda.Value[Config]
+//	if err := cfg.Load(loader); err != nil {
+//	    log.Fatal(err)
+//	}
+func (v *Value[T]) Load(l *Loader) error {
+	cfg := new(T)
+	if err := l.Load(cfg); err != nil {
+		return err
+	}
+	v.Store(cfg)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// lease is a leased secret, such as dynamic database credentials, that is
+// reused across loads while the lease is valid.
+type lease struct {
+	id        string
+	secret    *vaultapi.Secret
+	ttl       time.Duration // duration granted when the secret was issued
+	duration  time.Duration // duration granted by the latest issue or renewal
+	renewable bool
+	granted   time.Time
+}
+
+// RenewLeases renews the leases of dynamic secrets resolved so far that are
+// past half of their duration. Leased secrets are reused by Resolve while
+// valid, so renewing them keeps the same credentials in use.
+//
+// A lease that is not renewable, fails to renew, or has reached its maximum
+// TTL is dropped, so the next Resolve reads a fresh secret; expired reports
+// whether that happened, meaning callers should reload to pick up the rotated
+// value. next is the time until the next renewal is due, or 0 when no leases
+// are held.
+//
+// The config watcher calls RenewLeases automatically when built with
+// WithAutoRenewLease.
+func (r *Resolver) RenewLeases(ctx context.Context) (next time.Duration, expired bool, err error) {
+	r.leasesMu.Lock()
+	defer r.leasesMu.Unlock()
+
+	var errs []error
+	now := r.now()
+	for path, l := range r.leases {
+		due := l.granted.Add(l.duration / 2)
+		if now.Before(due) {
+			next = minDuration(next, due.Sub(now))
+
+			continue
+		}
+
+		renewed, renewErr := r.renewLease(ctx, l)
+		if renewErr != nil {
+			errs = append(errs, fmt.Errorf("failed to renew lease for %q: %w", path, renewErr))
+		}
+		if !renewed {
+			delete(r.leases, path)
+			expired = true
+
+			continue
+		}
+
+		l.granted = now
+		next = minDuration(next, l.duration/2)
+	}
+
+	return next, expired, errors.Join(errs...)
+}
+
+// renewLease extends l by its original TTL. It returns false when the lease
+// cannot be kept, including when renewal was capped by the lease's max TTL.
+func (r *Resolver) renewLease(ctx context.Context, l *lease) (bool, error) {
+	if !l.renewable {
+		return false, nil
+	}
+
+	secret, err := r.client.Sys().RenewWithContext(ctx, l.id, int(l.ttl.Seconds()))
+	if err != nil {
+		return false, err
+	}
+	if secret == nil {
+		return false, errors.New("empty renewal response")
+	}
+
+	duration := time.Duration(secret.LeaseDuration) * time.Second
+	if duration < l.ttl {
+		return false, nil // max TTL reached; rotate before it expires
+	}
+	l.duration = duration
+
+	return true, nil
+}
+
+// leasedSecret returns the secret held under a still valid lease for path.
+// A lease counts as valid until two thirds of its duration have passed, so
+// callers never receive credentials that are about to expire.
+func (r *Resolver) leasedSecret(path string) *vaultapi.Secret {
+	r.leasesMu.Lock()
+	defer r.leasesMu.Unlock()
+
+	l, ok := r.leases[path]
+	if !ok {
+		return nil
+	}
+	if r.now().Sub(l.granted) >= l.duration*2/3 {
+		delete(r.leases, path)
+
+		return nil
+	}
+
+	return l.secret
+}
+
+// trackLease records secret for reuse if it carries a lease.
+func (r *Resolver) trackLease(path string, secret *vaultapi.Secret) {
+	if secret == nil || secret.LeaseID == "" || secret.LeaseDuration <= 0 {
+		return
+	}
+
+	duration := time.Duration(secret.LeaseDuration) * time.Second
+
+	r.leasesMu.Lock()
+	defer r.leasesMu.Unlock()
+
+	if r.leases == nil {
+		r.leases = make(map[string]*lease)
+	}
+	r.leases[path] = &lease{
+		id:        secret.LeaseID,
+		secret:    secret,
+		ttl:       duration,
+		duration:  duration,
+		renewable: secret.Renewable,
+		granted:   r.now(),
+	}
+}
+
+// minDuration returns the smaller of a and b, treating 0 as unset.
+func minDuration(a, b time.Duration) time.Duration {
+	if a == 0 || b < a {
+		return b
+	}
+
+	return a
+}
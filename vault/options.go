@@ -4,11 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"time"
 
 	vaultapi "github.com/hashicorp/vault/api"
 )
 
+// dialTimeout is the connect timeout of connections opened by a tuned
+// HTTPTransport, as in the Vault API client.
+const dialTimeout = 30 * time.Second
+
 // Option configures a Vault resolver.
 type Option func(*resolverConfig)
 
@@ -63,6 +71,82 @@ func WithTLSConfig(cfg *vaultapi.TLSConfig) Option {
 	}
 }
 
+// WithHTTPClient sets the HTTP client of the Vault client, for example to
+// share a connection pool with the application. WithTLSConfig, when also
+// given, is applied to the client's transport, which must then be an
+// *http.Transport. The default is a pooled client with a 60s timeout.
+//
+// Example:
+//
+//	vault.WithHTTPClient(&http.Client{Transport: transport, Timeout: 10 * time.Second})
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *resolverConfig) {
+		c.httpClient = client
+	}
+}
+
+// HTTPTransport tunes connection reuse of the default Vault HTTP client, for
+// WithHTTPTransport. Zero fields keep the defaults of the Vault API client.
+type HTTPTransport struct {
+	// MaxIdleConns bounds the idle connections kept across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost bounds the idle connections kept per host. Raise it
+	// to the ref concurrency of the loader to reuse all connections.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost bounds all connections per host; 0 means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept for reuse.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period of new connections. A negative
+	// value disables TCP keep-alives.
+	KeepAlive time.Duration
+	// DisableKeepAlives closes each connection after one request.
+	DisableKeepAlives bool
+	// Proxy returns the proxy for a request, for example http.ProxyURL(u).
+	// nil uses the VAULT_HTTP_PROXY, HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+	// variables.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// WithHTTPTransport tunes the connections of the default Vault HTTP client.
+// It cannot be combined with WithHTTPClient.
+//
+// Example:
+//
+//	vault.WithHTTPTransport(vault.HTTPTransport{
+//	    MaxIdleConnsPerHost: 16,
+//	    IdleConnTimeout:     5 * time.Minute,
+//	})
+func WithHTTPTransport(t HTTPTransport) Option {
+	return func(c *resolverConfig) {
+		c.transport = &t
+	}
+}
+
+// apply sets t on tr.
+func (t *HTTPTransport) apply(tr *http.Transport) {
+	if t.MaxIdleConns > 0 {
+		tr.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+	if t.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = t.IdleConnTimeout
+	}
+	if t.KeepAlive != 0 {
+		dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: t.KeepAlive}
+		tr.DialContext = dialer.DialContext
+	}
+	tr.DisableKeepAlives = t.DisableKeepAlives
+	if t.Proxy != nil {
+		tr.Proxy = t.Proxy
+	}
+}
+
 // WithKubernetesAuth configures Kubernetes authentication.
 // This is the recommended method for applications running in Kubernetes.
 //
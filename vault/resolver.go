@@ -43,14 +43,33 @@
 // AppRole authentication:
 //
 //	vault.WithAppRole(roleID, secretID)
+//
+// # Batched Reads
+//
+// Within a single fuda Load, each Vault path is read at most once: fields
+// referencing vault:///secret/data/db#username and
+// vault:///secret/data/db#password share one HTTP request. The cache is
+// dropped when the load ends, so reloads see current secrets (leased secrets
+// excepted, see below).
+//
+// # Leased Secrets
+//
+// Secrets that carry a lease, such as dynamic database credentials, are
+// reused across loads until two thirds of the lease have passed, instead of
+// issuing new credentials on every load. [Resolver.RenewLeases] extends those
+// leases; the config watcher calls it when built with WithAutoRenewLease and
+// reloads with fresh credentials once a lease can no longer be renewed.
 package vault
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	vaultapi "github.com/hashicorp/vault/api"
 )
@@ -62,6 +81,27 @@ type Resolver struct {
 	config    *resolverConfig
 	authDone  bool
 	namespace string
+	leasesMu  sync.Mutex
+	leases    map[string]*lease // leased secrets by path
+	now       func() time.Time
+}
+
+// loadCacheKey keys a Resolver's per-load secret cache in a context.
+type loadCacheKey struct {
+	resolver *Resolver
+}
+
+// loadCache holds the secrets read during one load, keyed by path.
+type loadCache struct {
+	mu    sync.Mutex
+	reads map[string]*secretRead
+}
+
+// secretRead is a single, possibly in-flight, read of a secret path.
+type secretRead struct {
+	done   chan struct{}
+	secret *vaultapi.Secret
+	err    error
 }
 
 // resolverConfig holds internal configuration for the resolver.
@@ -71,6 +111,8 @@ type resolverConfig struct {
 	namespace  string
 	authMethod authMethod
 	tlsConfig  *vaultapi.TLSConfig
+	httpClient *http.Client
+	transport  *HTTPTransport
 }
 
 // authMethod represents a Vault authentication method.
@@ -95,6 +137,8 @@ type authMethod interface {
 //   - [WithAppRole] - AppRole authentication
 //   - [WithNamespace] - Vault namespace (Enterprise)
 //   - [WithTLSConfig] - Custom TLS configuration
+//   - [WithHTTPClient] - Custom HTTP client
+//   - [WithHTTPTransport] - Connection pool and proxy tuning
 func NewResolver(opts ...Option) (*Resolver, error) {
 	cfg := &resolverConfig{}
 	for _, opt := range opts {
@@ -109,6 +153,19 @@ func NewResolver(opts ...Option) (*Resolver, error) {
 	vaultCfg := vaultapi.DefaultConfig()
 	vaultCfg.Address = cfg.address
 
+	switch {
+	case cfg.httpClient != nil && cfg.transport != nil:
+		return nil, errors.New("WithHTTPClient and WithHTTPTransport cannot be combined")
+	case cfg.httpClient != nil:
+		vaultCfg.HttpClient = cfg.httpClient
+	case cfg.transport != nil:
+		tr, ok := vaultCfg.HttpClient.Transport.(*http.Transport)
+		if !ok {
+			return nil, errors.New("failed to tune transport: default vault transport is not an *http.Transport")
+		}
+		cfg.transport.apply(tr)
+	}
+
 	if cfg.tlsConfig != nil {
 		if err := vaultCfg.ConfigureTLS(cfg.tlsConfig); err != nil {
 			return nil, fmt.Errorf("failed to configure TLS: %w", err)
@@ -134,6 +191,7 @@ func NewResolver(opts ...Option) (*Resolver, error) {
 		client:    client,
 		config:    cfg,
 		namespace: cfg.namespace,
+		now:       time.Now,
 	}, nil
 }
 
@@ -177,8 +235,8 @@ func (r *Resolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
 		return nil, err
 	}
 
-	// Read secret from Vault
-	secret, err := r.client.Logical().ReadWithContext(ctx, path)
+	// Read secret from Vault (once per path per load)
+	secret, err := r.readSecret(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read vault secret at %q: %w", path, err)
 	}
@@ -196,6 +254,67 @@ func (r *Resolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
 	return []byte(value), nil
 }
 
+// BeginLoad returns a context carrying a fresh secret cache, so every field
+// of one fuda Load that references the same path shares a single read.
+// fuda calls it automatically at the start of each Load.
+func (r *Resolver) BeginLoad(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadCacheKey{r}, &loadCache{reads: make(map[string]*secretRead)})
+}
+
+// readSecret reads the secret at path, reusing a successful read of the same
+// path made earlier in the current load. Concurrent callers wait for the
+// first read; a failed read is not cached, so retries reach Vault again.
+func (r *Resolver) readSecret(ctx context.Context, path string) (*vaultapi.Secret, error) {
+	cache, ok := ctx.Value(loadCacheKey{r}).(*loadCache)
+	if !ok {
+		return r.read(ctx, path)
+	}
+
+	cache.mu.Lock()
+	read, found := cache.reads[path]
+	if !found {
+		read = &secretRead{done: make(chan struct{})}
+		cache.reads[path] = read
+	}
+	cache.mu.Unlock()
+
+	if !found {
+		read.secret, read.err = r.read(ctx, path)
+		if read.err != nil {
+			// Only successful reads are shared; retries read again
+			cache.mu.Lock()
+			delete(cache.reads, path)
+			cache.mu.Unlock()
+		}
+		close(read.done)
+
+		return read.secret, read.err
+	}
+
+	select {
+	case <-read.done:
+		return read.secret, read.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// read returns the secret at path, reusing a secret whose lease is still
+// valid instead of issuing new credentials.
+func (r *Resolver) read(ctx context.Context, path string) (*vaultapi.Secret, error) {
+	if secret := r.leasedSecret(path); secret != nil {
+		return secret, nil
+	}
+
+	secret, err := r.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	r.trackLease(path, secret)
+
+	return secret, nil
+}
+
 // ensureAuthenticated performs lazy authentication if an auth method is configured.
 func (r *Resolver) ensureAuthenticated(ctx context.Context) error {
 	// Skip if already authenticated or using direct token
@@ -3,11 +3,17 @@ package vault
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/arloliu/fuda"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -183,6 +189,257 @@ func TestResolver_Resolve(t *testing.T) {
 	})
 }
 
+func TestResolver_BatchedReads(t *testing.T) {
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data": map[string]any{"username": "admin", "password": "s3cret"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	resolver, err := NewResolver(WithAddress(server.URL), WithToken("test-token"))
+	require.NoError(t, err)
+
+	t.Run("one read per path per load", func(t *testing.T) {
+		reads.Store(0)
+		ctx := resolver.BeginLoad(t.Context())
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Go(func() {
+				value, err := resolver.Resolve(ctx, "vault:///secret/data/db#password")
+				assert.NoError(t, err)
+				assert.Equal(t, "s3cret", string(value))
+			})
+		}
+		wg.Wait()
+
+		value, err := resolver.Resolve(ctx, "vault:///secret/data/db#username")
+		require.NoError(t, err)
+		assert.Equal(t, "admin", string(value))
+		assert.Equal(t, int32(1), reads.Load())
+
+		_, err = resolver.Resolve(ctx, "vault:///secret/data/other#username")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), reads.Load())
+	})
+
+	t.Run("new load reads again", func(t *testing.T) {
+		reads.Store(0)
+
+		for range 2 {
+			ctx := resolver.BeginLoad(t.Context())
+			_, err := resolver.Resolve(ctx, "vault:///secret/data/db#username")
+			require.NoError(t, err)
+			_, err = resolver.Resolve(ctx, "vault:///secret/data/db#password")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), reads.Load())
+	})
+
+	t.Run("no caching outside a load", func(t *testing.T) {
+		reads.Store(0)
+
+		for range 2 {
+			_, err := resolver.Resolve(t.Context(), "vault:///secret/data/db#password")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), reads.Load())
+	})
+}
+
+func TestResolver_RetryAfterFailedRead(t *testing.T) {
+	// Disable the Vault client's own retries so the failure reaches fuda
+	t.Setenv("VAULT_MAX_RETRIES", "0")
+
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reads.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data": map[string]any{"password": "s3cret"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	resolver, err := NewResolver(WithAddress(server.URL), WithToken("test-token"))
+	require.NoError(t, err)
+
+	loader, err := fuda.New().
+		WithResolver("vault", resolver).
+		WithRefRetry(3, time.Millisecond).
+		Build()
+	require.NoError(t, err)
+
+	var cfg struct {
+		Password string `ref:"vault:///secret/data/db#password"`
+	}
+	require.NoError(t, loader.Load(&cfg))
+	assert.Equal(t, "s3cret", cfg.Password)
+	assert.Equal(t, int32(2), reads.Load())
+}
+
+func TestResolver_Leases(t *testing.T) {
+	type leaseServer struct {
+		resolver  *Resolver
+		now       *time.Time
+		reads     *atomic.Int32
+		renewals  *atomic.Int32
+		renewTTL  *atomic.Int32 // lease_duration returned by renewals, 0 fails
+		renewable bool
+	}
+
+	setup := func(t *testing.T, renewable bool) leaseServer {
+		t.Helper()
+
+		now := time.Now()
+		ls := leaseServer{
+			now:       &now,
+			reads:     new(atomic.Int32),
+			renewals:  new(atomic.Int32),
+			renewTTL:  new(atomic.Int32),
+			renewable: renewable,
+		}
+		ls.renewTTL.Store(60)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/v1/database/creds/app":
+				n := ls.reads.Add(1)
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"lease_id":       fmt.Sprintf("database/creds/app/%d", n),
+					"lease_duration": 60,
+					"renewable":      ls.renewable,
+					"data":           map[string]any{"username": fmt.Sprintf("user-%d", n)},
+				})
+			case "/v1/sys/leases/renew":
+				ls.renewals.Add(1)
+				ttl := ls.renewTTL.Load()
+				if ttl == 0 {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"lease_id":       "database/creds/app/1",
+					"lease_duration": ttl,
+					"renewable":      true,
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		resolver, err := NewResolver(WithAddress(server.URL), WithToken("test-token"))
+		require.NoError(t, err)
+		resolver.now = func() time.Time { return *ls.now }
+		ls.resolver = resolver
+
+		return ls
+	}
+
+	username := func(t *testing.T, ls leaseServer) string {
+		t.Helper()
+
+		value, err := ls.resolver.Resolve(t.Context(), "vault:///database/creds/app#username")
+		require.NoError(t, err)
+
+		return string(value)
+	}
+
+	t.Run("reuses a leased secret while valid", func(t *testing.T) {
+		ls := setup(t, true)
+
+		assert.Equal(t, "user-1", username(t, ls))
+		*ls.now = ls.now.Add(30 * time.Second)
+		assert.Equal(t, "user-1", username(t, ls))
+		assert.Equal(t, int32(1), ls.reads.Load())
+
+		*ls.now = ls.now.Add(10 * time.Second) // two thirds of the lease
+		assert.Equal(t, "user-2", username(t, ls))
+	})
+
+	t.Run("renews leases past half their duration", func(t *testing.T) {
+		ls := setup(t, true)
+		assert.Equal(t, "user-1", username(t, ls))
+
+		*ls.now = ls.now.Add(10 * time.Second)
+		next, expired, err := ls.resolver.RenewLeases(t.Context())
+		require.NoError(t, err)
+		assert.False(t, expired)
+		assert.Equal(t, 20*time.Second, next)
+		assert.Equal(t, int32(0), ls.renewals.Load())
+
+		*ls.now = ls.now.Add(20 * time.Second)
+		next, expired, err = ls.resolver.RenewLeases(t.Context())
+		require.NoError(t, err)
+		assert.False(t, expired)
+		assert.Equal(t, 30*time.Second, next)
+		assert.Equal(t, int32(1), ls.renewals.Load())
+
+		*ls.now = ls.now.Add(30 * time.Second) // past the original lease
+		assert.Equal(t, "user-1", username(t, ls))
+	})
+
+	t.Run("drops leases that reach their max TTL", func(t *testing.T) {
+		ls := setup(t, true)
+		assert.Equal(t, "user-1", username(t, ls))
+
+		ls.renewTTL.Store(15)
+		*ls.now = ls.now.Add(30 * time.Second)
+		next, expired, err := ls.resolver.RenewLeases(t.Context())
+		require.NoError(t, err)
+		assert.True(t, expired)
+		assert.Zero(t, next)
+		assert.Equal(t, "user-2", username(t, ls))
+	})
+
+	t.Run("drops leases that fail to renew", func(t *testing.T) {
+		ls := setup(t, true)
+		assert.Equal(t, "user-1", username(t, ls))
+
+		ls.renewTTL.Store(0)
+		*ls.now = ls.now.Add(30 * time.Second)
+		_, expired, err := ls.resolver.RenewLeases(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `failed to renew lease for "database/creds/app"`)
+		assert.True(t, expired)
+		assert.Equal(t, "user-2", username(t, ls))
+	})
+
+	t.Run("rotates non-renewable leases", func(t *testing.T) {
+		ls := setup(t, false)
+		assert.Equal(t, "user-1", username(t, ls))
+
+		*ls.now = ls.now.Add(30 * time.Second)
+		_, expired, err := ls.resolver.RenewLeases(t.Context())
+		require.NoError(t, err)
+		assert.True(t, expired)
+		assert.Equal(t, int32(0), ls.renewals.Load())
+		assert.Equal(t, "user-2", username(t, ls))
+	})
+
+	t.Run("ignores secrets without a lease", func(t *testing.T) {
+		ls := setup(t, true)
+
+		next, expired, err := ls.resolver.RenewLeases(t.Context())
+		require.NoError(t, err)
+		assert.False(t, expired)
+		assert.Zero(t, next)
+	})
+}
+
 func TestResolver_AuthMethods(t *testing.T) {
 	t.Run("kubernetes auth", func(t *testing.T) {
 		// Create a temp file to simulate the JWT
@@ -321,3 +578,79 @@ func TestWithOptions(t *testing.T) {
 		assert.Equal(t, "value", string(data))
 	})
 }
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestResolver_HTTPOptions(t *testing.T) {
+	responses := map[string]any{
+		"/v1/secret/data/test": map[string]any{
+			"data": map[string]any{
+				"data": map[string]any{"key": "value"},
+			},
+		},
+	}
+
+	t.Run("WithHTTPClient", func(t *testing.T) {
+		server := mockVaultServer(t, responses)
+		defer server.Close()
+
+		var requests atomic.Int32
+		client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			return http.DefaultTransport.RoundTrip(req)
+		})}
+
+		resolver, err := NewResolver(
+			WithAddress(server.URL),
+			WithToken("token"),
+			WithHTTPClient(client),
+		)
+		require.NoError(t, err)
+
+		data, err := resolver.Resolve(context.Background(), "vault:///secret/data/test#key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(data))
+		assert.Positive(t, requests.Load(), "requests should go through the custom client")
+	})
+
+	t.Run("WithHTTPTransport", func(t *testing.T) {
+		server := mockVaultServer(t, responses)
+		defer server.Close()
+
+		var proxied atomic.Int32
+		resolver, err := NewResolver(
+			WithAddress(server.URL),
+			WithToken("token"),
+			WithHTTPTransport(HTTPTransport{
+				MaxIdleConnsPerHost: 8,
+				IdleConnTimeout:     time.Minute,
+				KeepAlive:           time.Minute,
+				Proxy: func(*http.Request) (*url.URL, error) {
+					proxied.Add(1)
+					return nil, nil // connect directly
+				},
+			}),
+		)
+		require.NoError(t, err)
+
+		data, err := resolver.Resolve(context.Background(), "vault:///secret/data/test#key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(data))
+		assert.Positive(t, proxied.Load(), "the tuned transport should pick the proxy")
+	})
+
+	t.Run("client and transport conflict", func(t *testing.T) {
+		_, err := NewResolver(
+			WithAddress("http://127.0.0.1:8200"),
+			WithHTTPClient(&http.Client{}),
+			WithHTTPTransport(HTTPTransport{MaxIdleConnsPerHost: 8}),
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be combined")
+	})
+}
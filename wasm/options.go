@@ -0,0 +1,66 @@
+package wasm
+
+// Option configures a WASM resolver.
+type Option func(*resolverConfig)
+
+// mount is a host directory exposed read-only to the module.
+type mount struct {
+	hostDir  string
+	guestDir string
+}
+
+// resolverConfig holds WASM resolver configuration.
+type resolverConfig struct {
+	args        []string
+	env         map[string]string
+	mounts      []mount
+	memoryPages uint32
+}
+
+// WithArgs sets the command-line arguments passed to the module. The module
+// name is always passed as argv[0].
+//
+// Example:
+//
+//	wasm.WithArgs("--profile", "prod")
+func WithArgs(args ...string) Option {
+	return func(c *resolverConfig) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// WithEnv exposes an environment variable to the module. The host
+// environment is not visible to the module otherwise.
+//
+// Example:
+//
+//	wasm.WithEnv("SECRETS_REGION", "eu-west-1")
+func WithEnv(key, value string) Option {
+	return func(c *resolverConfig) {
+		if c.env == nil {
+			c.env = make(map[string]string)
+		}
+		c.env[key] = value
+	}
+}
+
+// WithDir mounts hostDir read-only at guestDir inside the module.
+// Without mounts the module has no filesystem access.
+//
+// Example:
+//
+//	wasm.WithDir("/etc/corp/credentials", "/credentials")
+func WithDir(hostDir, guestDir string) Option {
+	return func(c *resolverConfig) {
+		c.mounts = append(c.mounts, mount{hostDir: hostDir, guestDir: guestDir})
+	}
+}
+
+// WithMemoryLimitPages caps the module's linear memory, in 64 KiB pages.
+//
+// Default is 512 pages (32 MiB).
+func WithMemoryLimitPages(pages uint32) Option {
+	return func(c *resolverConfig) {
+		c.memoryPages = pages
+	}
+}
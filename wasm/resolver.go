@@ -0,0 +1,216 @@
+// Package wasm provides a fuda resolver that runs third-party resolver
+// plugins as WebAssembly modules inside a wazero sandbox.
+//
+// Modules are WASI (preview 1) command programs speaking the fuda resolver
+// plugin protocol (see github.com/arloliu/fuda/resolver): each Resolve call
+// runs the module once with a single JSON request line on stdin and reads a
+// single JSON response line from stdout. A fresh instance is used per call,
+// so plugins cannot keep state between lookups.
+//
+// The sandbox grants nothing by default: no network, no filesystem, no host
+// environment variables, and at most 32 MiB of linear memory. Grant access
+// explicitly with WithDir and WithEnv, and raise the memory limit with
+// WithMemoryLimitPages.
+//
+// Plugins written in Go can use resolver.ServeStdio and be built with:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -o corp.wasm ./cmd/corp-plugin
+//
+// Basic usage:
+//
+//	r, err := wasm.NewResolverFromFile(ctx, "corp.wasm",
+//	    wasm.WithEnv("SECRETS_REGION", "eu-west-1"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer r.Close()
+//
+//	loader, _ := fuda.New().WithResolver("corp", r).Build()
+package wasm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// protocol is the resolver plugin protocol version sent with requests.
+	protocol = "fuda-resolver/v1"
+	// defaultMemoryPages is the default linear memory limit (32 MiB).
+	defaultMemoryPages = 512
+	// moduleName is passed to the module as argv[0].
+	moduleName = "fuda-resolver"
+)
+
+// compilationCache shares compiled machine code between resolvers created
+// from the same module bytes, since compiling large modules is slow.
+var compilationCache = wazero.NewCompilationCache()
+
+// Resolver resolves refs by running a sandboxed WASM plugin.
+// It is safe for concurrent use; each call runs in its own module instance.
+type Resolver struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	config   resolverConfig
+	nextID   atomic.Uint64
+}
+
+// request mirrors resolver.Request of the plugin protocol.
+type request struct {
+	Protocol string `json:"protocol"`
+	ID       uint64 `json:"id"`
+	URI      string `json:"uri"`
+}
+
+// response mirrors resolver.Response of the plugin protocol.
+type response struct {
+	ID       uint64 `json:"id"`
+	Data     []byte `json:"data,omitempty"`
+	Error    string `json:"error,omitempty"`
+	NotFound bool   `json:"notFound,omitempty"`
+}
+
+// NewResolverFromFile compiles the WASM module at path.
+// See NewResolver.
+func NewResolverFromFile(ctx context.Context, path string, opts ...Option) (*Resolver, error) {
+	wasmBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wasm module: %w", err)
+	}
+
+	return NewResolver(ctx, wasmBytes, opts...)
+}
+
+// NewResolver compiles wasmBytes into a sandboxed resolver.
+// Compilation happens once per distinct module; Close releases the resolver's
+// runtime.
+func NewResolver(ctx context.Context, wasmBytes []byte, opts ...Option) (*Resolver, error) {
+	cfg := resolverConfig{memoryPages: defaultMemoryPages}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(cfg.memoryPages).
+		WithCloseOnContextDone(true).
+		WithCompilationCache(compilationCache))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		_ = runtime.Close(ctx)
+
+		return nil, fmt.Errorf("failed to compile wasm module: %w", err)
+	}
+
+	return &Resolver{runtime: runtime, compiled: compiled, config: cfg}, nil
+}
+
+// Resolve runs the plugin for uri and returns the resolved content.
+// A plugin response with notFound set yields an error wrapping
+// os.ErrNotExist, so fuda default tags still apply.
+func (r *Resolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	req := request{Protocol: protocol, ID: r.nextID.Add(1), URI: uri}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	modCfg := r.moduleConfig().
+		WithStdin(bytes.NewReader(append(line, '\n'))).
+		WithStdout(&stdout)
+
+	mod, err := r.runtime.InstantiateModule(ctx, r.compiled, modCfg)
+	if mod != nil {
+		_ = mod.Close(ctx)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		var exitErr *sys.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 0 {
+			return nil, fmt.Errorf("wasm resolver failed for %s: %w", uri, err)
+		}
+	}
+
+	resp, err := readResponse(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("wasm resolver failed for %s: %w", uri, err)
+	}
+	if resp.ID != req.ID {
+		return nil, fmt.Errorf("wasm resolver: response id %d does not match request id %d", resp.ID, req.ID)
+	}
+
+	switch {
+	case resp.NotFound:
+		return nil, fmt.Errorf("%s: %w", uri, os.ErrNotExist)
+	case resp.Error != "":
+		return nil, fmt.Errorf("wasm resolver failed for %s: %s", uri, resp.Error)
+	default:
+		return resp.Data, nil
+	}
+}
+
+// Close releases the compiled module and the sandbox runtime.
+func (r *Resolver) Close() error {
+	return r.runtime.Close(context.Background())
+}
+
+// moduleConfig builds the per-call module configuration with the granted
+// capabilities only.
+func (r *Resolver) moduleConfig() wazero.ModuleConfig {
+	cfg := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{moduleName}, r.config.args...)...).
+		WithStderr(os.Stderr)
+
+	for key, value := range r.config.env {
+		cfg = cfg.WithEnv(key, value)
+	}
+
+	if len(r.config.mounts) > 0 {
+		fsCfg := wazero.NewFSConfig()
+		for _, m := range r.config.mounts {
+			fsCfg = fsCfg.WithReadOnlyDirMount(m.hostDir, m.guestDir)
+		}
+		cfg = cfg.WithFSConfig(fsCfg)
+	}
+
+	return cfg
+}
+
+// readResponse decodes the first line the module wrote to stdout.
+func readResponse(stdout []byte) (response, error) {
+	var resp response
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(stdout)+1)
+	if !scanner.Scan() {
+		return resp, errors.New("module wrote no response")
+	}
+
+	if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+		return resp, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return resp, nil
+}
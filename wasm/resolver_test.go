@@ -0,0 +1,166 @@
+package wasm
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pluginWasm holds the compiled test plugin, built once in TestMain.
+var pluginWasm []byte
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "fuda-wasm-test")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "plugin.wasm")
+	cmd := exec.Command("go", "build", "-o", out, "./testdata/plugin")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		panic("failed to build test plugin: " + err.Error() + "\n" + string(output))
+	}
+
+	if pluginWasm, err = os.ReadFile(out); err != nil {
+		panic(err)
+	}
+
+	return m.Run()
+}
+
+func newTestResolver(t *testing.T, opts ...Option) *Resolver {
+	t.Helper()
+
+	r, err := NewResolver(t.Context(), pluginWasm, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Close() })
+
+	return r
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	r := newTestResolver(t)
+
+	data, err := r.Resolve(t.Context(), "corp://db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(data))
+
+	_, err = r.Resolve(t.Context(), "corp://unknown")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestResolver_Sandbox(t *testing.T) {
+	t.Run("host environment is hidden", func(t *testing.T) {
+		r := newTestResolver(t)
+
+		data, err := r.Resolve(t.Context(), "corp://host-env")
+		require.NoError(t, err)
+		assert.Empty(t, data)
+
+		_, err = r.Resolve(t.Context(), "corp://env")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("granted environment is visible", func(t *testing.T) {
+		r := newTestResolver(t, WithEnv("SECRETS_REGION", "eu-west-1"))
+
+		data, err := r.Resolve(t.Context(), "corp://env")
+		require.NoError(t, err)
+		assert.Equal(t, "eu-west-1", string(data))
+	})
+
+	t.Run("no filesystem by default", func(t *testing.T) {
+		r := newTestResolver(t)
+
+		_, err := r.Resolve(t.Context(), "corp://escape")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("mounted directory is readable", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("tok-123"), 0o600))
+
+		r := newTestResolver(t, WithDir(dir, "/creds"))
+
+		data, err := r.Resolve(t.Context(), "corp://file")
+		require.NoError(t, err)
+		assert.Equal(t, "tok-123", string(data))
+
+		_, err = r.Resolve(t.Context(), "corp://escape")
+		require.Error(t, err, "only the mounted directory is visible")
+	})
+}
+
+func TestResolver_Args(t *testing.T) {
+	r := newTestResolver(t, WithArgs("--profile", "prod"))
+
+	data, err := r.Resolve(t.Context(), "corp://args")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", string(data))
+}
+
+func TestResolver_ContextCancel(t *testing.T) {
+	r := newTestResolver(t)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := r.Resolve(ctx, "corp://spin")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A fresh instance serves the next call.
+	data, err := r.Resolve(t.Context(), "corp://db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(data))
+}
+
+func TestResolver_Crash(t *testing.T) {
+	r := newTestResolver(t)
+
+	_, err := r.Resolve(t.Context(), "corp://crash")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit_code(3)")
+}
+
+func TestResolver_Concurrent(t *testing.T) {
+	r := newTestResolver(t)
+
+	errs := make(chan error, 8)
+	for range 8 {
+		go func() {
+			data, err := r.Resolve(t.Context(), "corp://db/password")
+			if err == nil && string(data) != "s3cret" {
+				err = assert.AnError
+			}
+			errs <- err
+		}()
+	}
+
+	for range 8 {
+		require.NoError(t, <-errs)
+	}
+}
+
+func TestNewResolver_InvalidModule(t *testing.T) {
+	_, err := NewResolver(t.Context(), []byte("not wasm"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to compile wasm module")
+}
+
+func TestNewResolverFromFile_Missing(t *testing.T) {
+	_, err := NewResolverFromFile(t.Context(), filepath.Join(t.TempDir(), "missing.wasm"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,74 @@
+// Command plugin is a resolver plugin used by the wasm package tests.
+// Build with GOOS=wasip1 GOARCH=wasm.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+type request struct {
+	ID  uint64 `json:"id"`
+	URI string `json:"uri"`
+}
+
+type response struct {
+	ID       uint64 `json:"id"`
+	Data     []byte `json:"data,omitempty"`
+	Error    string `json:"error,omitempty"`
+	NotFound bool   `json:"notFound,omitempty"`
+}
+
+func main() {
+	scanner := bufio.NewScanner(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(2)
+		}
+		_ = enc.Encode(resolve(req))
+	}
+}
+
+func resolve(req request) response {
+	resp := response{ID: req.ID}
+
+	switch req.URI {
+	case "corp://db/password":
+		resp.Data = []byte("s3cret")
+	case "corp://args":
+		resp.Data = []byte(os.Args[len(os.Args)-1])
+	case "corp://env":
+		value, ok := os.LookupEnv("SECRETS_REGION")
+		if !ok {
+			resp.NotFound = true
+		}
+		resp.Data = []byte(value)
+	case "corp://host-env":
+		resp.Data = []byte(os.Getenv("PATH"))
+	case "corp://file":
+		data, err := os.ReadFile("/creds/token")
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		resp.Data = data
+	case "corp://escape":
+		data, err := os.ReadFile("/etc/passwd")
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		resp.Data = data
+	case "corp://spin":
+		for {
+		}
+	case "corp://crash":
+		os.Exit(3)
+	default:
+		resp.NotFound = true
+	}
+
+	return resp
+}
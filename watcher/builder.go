@@ -1,21 +1,24 @@
 package watcher
 
 import (
+	"context"
 	"io"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/arloliu/fuda"
-	"github.com/go-playground/validator/v10"
 	"github.com/spf13/afero"
 )
 
 // Builder provides a fluent API for constructing a Watcher.
 type Builder struct {
-	config watcherConfig
-	source []byte
-	path   string
-	err    error
-	fs     afero.Fs
+	config   watcherConfig
+	source   []byte
+	paths    []string
+	sourceFn SourceFunc
+	err      error
+	fs       afero.Fs
 }
 
 // FromFile sets the configuration file to watch.
@@ -37,7 +40,43 @@ func (b *Builder) FromFile(path string) *Builder {
 	}
 
 	b.source = data
-	b.path = path
+	b.paths = []string{path}
+
+	return b
+}
+
+// FromFiles sets several configuration files to watch, deep-merged in order
+// as by fuda's Builder.FromFiles: later files override earlier ones key by
+// key. A change to any of the files triggers a reload of the merged config.
+//
+// Example:
+//
+//	w, _ := watcher.New().
+//	    FromFiles("base.yaml", "prod.yaml", "secrets.yaml").
+//	    Build()
+func (b *Builder) FromFiles(paths ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(paths) == 0 {
+		b.err = &WatcherError{Message: "FromFiles requires at least one path"}
+		return b
+	}
+
+	fs := b.fs
+	if fs == nil {
+		fs = fuda.DefaultFs
+	}
+
+	for _, path := range paths {
+		if _, err := fs.Stat(path); err != nil {
+			b.err = err
+			return b
+		}
+	}
+
+	b.source = nil
+	b.paths = paths
 
 	return b
 }
@@ -69,6 +108,34 @@ func (b *Builder) FromBytes(data []byte) *Builder {
 	return b
 }
 
+// FromSource sets a non-file configuration source, such as a key in a
+// key-value store. The source is fetched once during Build and again on
+// every reload, so changes are picked up by polling or, when the ref resolver
+// implements WatchableResolver, by push notifications.
+//
+// Example:
+//
+//	w, _ := watcher.New().
+//	    FromSource(etcdResolver.Source("/config/app.yaml")).
+//	    WithRefResolver(etcdResolver).
+//	    Build()
+func (b *Builder) FromSource(fetch SourceFunc) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	data, err := fetch(context.Background())
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	b.source = data
+	b.sourceFn = fetch
+
+	return b
+}
+
 // WithRefResolver sets the reference resolver for ref/refFrom tags.
 // The resolver is also used for watching remote secrets if it implements
 // the WatchableResolver interface.
@@ -83,12 +150,6 @@ func (b *Builder) WithEnvPrefix(prefix string) *Builder {
 	return b
 }
 
-// WithValidator sets a custom validator instance.
-func (b *Builder) WithValidator(v *validator.Validate) *Builder {
-	b.config.validator = v
-	return b
-}
-
 // WithWatchInterval sets the polling interval for remote secrets.
 // This is the interval at which the watcher checks for changes in
 // secrets resolved via ref/refFrom tags (e.g., Vault secrets).
@@ -109,8 +170,11 @@ func (b *Builder) WithDebounceInterval(interval time.Duration) *Builder {
 }
 
 // WithAutoRenewLease enables automatic lease renewal for Vault dynamic secrets.
-// When enabled, the watcher will attempt to renew leases before they expire,
-// rather than waiting for expiry and re-fetching.
+// When enabled and the ref resolver implements LeaseRenewer, the watcher
+// renews leases as they come due, so the same credentials stay in use. When a
+// lease cannot be renewed, for example because it reached its max TTL, the
+// config is reloaded with freshly issued secrets and an update is emitted.
+// Renewal failures are reported on Errors.
 //
 // Default is false (no auto-renewal).
 func (b *Builder) WithAutoRenewLease() *Builder {
@@ -118,6 +182,93 @@ func (b *Builder) WithAutoRenewLease() *Builder {
 	return b
 }
 
+// WithSchedule enables scheduled config sections: after every load, sections
+// embedding schedule.Window that are not in effect are removed (see
+// schedule.Apply), and the config is reloaded when the next window opens or
+// closes, emitting an update with the switched values. The time is read from
+// the clock if it has a Now method, like the watchertest fake clock.
+//
+// Default is false (windows are loaded as plain fields).
+func (b *Builder) WithSchedule() *Builder {
+	b.config.schedule = true
+	return b
+}
+
+// OnSignal reloads the configuration when the process receives one of
+// sigs, so the `kill -HUP` convention of many daemons works alongside file
+// watching and polling. Like Trigger, the reload is debounced and an update
+// is emitted only if the configuration changed.
+//
+// Example:
+//
+//	w, _ := watcher.New().
+//	    FromFile("config.yaml").
+//	    OnSignal(syscall.SIGHUP).
+//	    Build()
+//
+// The signals are relayed only while the watcher runs; between Stop and the
+// next Watch they get their default behavior again.
+func (b *Builder) OnSignal(sigs ...os.Signal) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(sigs) == 0 {
+		b.err = &WatcherError{Message: "OnSignal requires at least one signal"}
+		return b
+	}
+
+	b.config.signals = append(b.config.signals, sigs...)
+
+	return b
+}
+
+// WithMetrics reports every reload, successful or not, and the latency of
+// every ref resolution to m, so a config that stopped reloading can be
+// alerted on. Reload times are read from the clock like schedule
+// boundaries. See fuda.Metrics; the fuda/prometheus module provides an
+// implementation.
+//
+// Example:
+//
+//	m, _ := prometheus.New(prom.DefaultRegisterer)
+//	w, _ := watcher.New().
+//	    FromFile("config.yaml").
+//	    WithMetrics(m).
+//	    Build()
+func (b *Builder) WithMetrics(m fuda.Metrics) *Builder {
+	b.config.metrics = m
+	return b
+}
+
+// WithLogger logs watcher events to logger at debug level: why each reload
+// was scheduled, its outcome including the error of a failed reload, errors
+// dropped because the Errors channel is full, and file watches that could
+// not be set up. The loaders of the watcher log their decisions too; see
+// fuda's Builder.WithLogger.
+//
+// Default is no logging.
+func (b *Builder) WithLogger(logger *slog.Logger) *Builder {
+	b.config.logger = logger
+	return b
+}
+
+// WithClock sets the clock used for the polling ticker and debounce timer.
+// This is intended for tests; see the watchertest package for a fake clock.
+//
+// Default is the real wall clock.
+func (b *Builder) WithClock(c Clock) *Builder {
+	b.config.clock = c
+	return b
+}
+
+// WithReloadPolicy sets how failed reloads are reported. See ReloadPolicy.
+//
+// Default is KeepLastGood.
+func (b *Builder) WithReloadPolicy(p ReloadPolicy) *Builder {
+	b.config.reloadPolicy = p
+	return b
+}
+
 // WithFilesystem sets a custom filesystem for file operations.
 // This is useful for testing with in-memory filesystems.
 func (b *Builder) WithFilesystem(fs afero.Fs) *Builder {
@@ -136,11 +287,14 @@ func (b *Builder) Build() (*Watcher, error) {
 		fs = fuda.DefaultFs
 	}
 
-	// Create the underlying fuda.Loader
-	loaderBuilder := fuda.New().WithFilesystem(fs)
+	// Create the underlying fuda.Loader, recording file:// refs to watch
+	refs := newRefFiles()
+	loaderBuilder := fuda.New().WithFilesystem(fs).WithResolverMiddleware(refs.middleware)
 
-	if b.path != "" {
-		loaderBuilder = loaderBuilder.FromFile(b.path)
+	if len(b.paths) > 1 {
+		loaderBuilder = loaderBuilder.FromFiles(b.paths...)
+	} else if len(b.paths) == 1 {
+		loaderBuilder = loaderBuilder.FromFile(b.paths[0])
 	} else if len(b.source) > 0 {
 		loaderBuilder = loaderBuilder.FromBytes(b.source)
 	}
@@ -153,12 +307,14 @@ func (b *Builder) Build() (*Watcher, error) {
 		loaderBuilder = loaderBuilder.WithRefResolver(b.config.refResolver)
 	}
 
-	if b.config.validator != nil {
-		if v, ok := b.config.validator.(*validator.Validate); ok {
-			loaderBuilder = loaderBuilder.WithValidator(v)
-		}
+	if b.config.metrics != nil {
+		loaderBuilder = loaderBuilder.WithMetrics(b.config.metrics)
 	}
 
+	loaderBuilder = loaderBuilder.WithLogger(b.config.logger)
+
+	loaderBuilder = withValidator(loaderBuilder, b.config.validator)
+
 	loader, err := loaderBuilder.Build()
 	if err != nil {
 		return nil, err
@@ -167,8 +323,10 @@ func (b *Builder) Build() (*Watcher, error) {
 	return &Watcher{
 		loader:        loader,
 		config:        b.config,
-		configPath:    b.path,
+		configPaths:   b.paths,
 		configContent: b.source,
+		source:        b.sourceFn,
 		fs:            fs,
+		refFiles:      refs,
 	}, nil
 }
@@ -0,0 +1,89 @@
+package watcher
+
+import "time"
+
+// Clock creates the tickers and timers used by the watcher for polling and
+// debouncing. Inject a fake implementation (see the watchertest package) via
+// Builder.WithClock to unit-test reload handling deterministically.
+type Clock interface {
+	// NewTicker returns a ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+	// NewTimer returns a timer that fires once after d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker is the subset of *time.Ticker used by the watcher.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is the subset of *time.Timer used by the watcher.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// realClock implements Clock using the time package.
+type realClock struct{}
+
+// realTicker adapts *time.Ticker to Ticker.
+type realTicker struct {
+	t *time.Ticker
+}
+
+// realTimer adapts *time.Timer to Timer.
+type realTimer struct {
+	t *time.Timer
+}
+
+var (
+	_ Clock  = realClock{}
+	_ Ticker = realTicker{}
+	_ Timer  = realTimer{}
+)
+
+// NewTicker returns a ticker backed by time.NewTicker.
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{t: time.NewTicker(d)}
+}
+
+// NewTimer returns a timer backed by time.NewTimer.
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{t: time.NewTimer(d)}
+}
+
+// C returns the ticker channel.
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+
+// Stop stops the ticker.
+func (r realTicker) Stop() { r.t.Stop() }
+
+// C returns the timer channel.
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+
+// Stop stops the timer.
+func (r realTimer) Stop() bool { return r.t.Stop() }
+
+// loopTimer is a timer of the watch loop that can be rearmed. Its channel
+// is nil while the timer is not armed, so selecting on it blocks.
+type loopTimer struct {
+	timer Timer
+	c     <-chan time.Time
+}
+
+// reset stops the timer and arms it to fire after d.
+func (t *loopTimer) reset(clock Clock, d time.Duration) {
+	t.stop()
+	t.timer = clock.NewTimer(d)
+	t.c = t.timer.C()
+}
+
+// stop stops the timer, if armed.
+func (t *loopTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = nil
+	t.c = nil
+}
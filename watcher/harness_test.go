@@ -0,0 +1,424 @@
+package watcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/schedule"
+	"github.com/arloliu/fuda/watcher"
+	"github.com/arloliu/fuda/watcher/watchertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type harnessConfig struct {
+	Host string `yaml:"host" default:"localhost"`
+}
+
+// mutableSource is a watcher.SourceFunc whose content the test can change.
+type mutableSource struct {
+	mu      sync.Mutex
+	content string
+}
+
+func (s *mutableSource) fetch(context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return []byte(s.content), nil
+}
+
+func (s *mutableSource) set(content string) {
+	s.mu.Lock()
+	s.content = content
+	s.mu.Unlock()
+}
+
+func receive(t *testing.T, updates <-chan any) *harnessConfig {
+	t.Helper()
+
+	select {
+	case v := <-updates:
+		cfg, ok := v.(*harnessConfig)
+		require.True(t, ok, "expected *harnessConfig")
+
+		return cfg
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	return nil
+}
+
+func TestWatcher_FakeClockPolling(t *testing.T) {
+	source := &mutableSource{content: "host: initial.com\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithClock(clock).
+		WithWatchInterval(time.Minute).
+		WithDebounceInterval(time.Second).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg harnessConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "initial.com", cfg.Host)
+
+	source.set("host: updated.com\n")
+
+	// Poll tick arms the debounce timer; firing it performs the reload.
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	assert.Equal(t, "updated.com", receive(t, updates).Host)
+}
+
+func TestWatcher_Trigger(t *testing.T) {
+	source := &mutableSource{content: "host: initial.com\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithClock(clock).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(time.Second).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg harnessConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+
+	source.set("host: triggered.com\n")
+	w.Trigger()
+
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	assert.Equal(t, "triggered.com", receive(t, updates).Host)
+}
+
+func TestWatcher_TriggerWhenStopped(t *testing.T) {
+	w, err := watcher.New().FromBytes([]byte("host: a\n")).Build()
+	require.NoError(t, err)
+
+	// Neither before Watch nor after Stop should Trigger block or panic.
+	w.Trigger()
+
+	var cfg harnessConfig
+	_, err = w.Watch(&cfg)
+	require.NoError(t, err)
+	w.Stop()
+	w.Trigger()
+}
+
+func TestWatcher_WatchChanges(t *testing.T) {
+	type Config struct {
+		Host     string `yaml:"host"`
+		Port     int    `yaml:"port"`
+		Password string `yaml:"password" secret:"true"`
+	}
+
+	source := &mutableSource{content: "host: a.com\nport: 80\npassword: one\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithClock(clock).
+		WithDebounceInterval(time.Second).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg Config
+	updates, err := w.WatchChanges(&cfg)
+	require.NoError(t, err)
+
+	source.set("host: a.com\nport: 8080\npassword: two\n")
+	w.Trigger()
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	select {
+	case u := <-updates:
+		newCfg, ok := u.Config.(*Config)
+		require.True(t, ok, "expected *Config")
+		assert.Equal(t, 8080, newCfg.Port)
+		assert.Equal(t, []fuda.FieldChange{
+			{Path: "port", Old: 80, New: 8080},
+			{Path: "password", Old: "[REDACTED]", New: "[REDACTED]", Redacted: true},
+		}, u.Changes)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	w.Stop()
+	_, ok := <-updates
+	assert.False(t, ok, "updates channel is closed on Stop")
+}
+
+func TestWatcher_OnChange(t *testing.T) {
+	type Database struct {
+		Host     string `yaml:"host"`
+		PoolSize int    `yaml:"pool_size"`
+	}
+	type Config struct {
+		Database  Database `yaml:"database"`
+		Databases []string `yaml:"databases"`
+		LogLevel  string   `yaml:"log_level"`
+	}
+
+	source := &mutableSource{content: "database:\n  host: a\n  pool_size: 5\nlog_level: info\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithClock(clock).
+		WithDebounceInterval(time.Second).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	type call struct{ old, new any }
+	var mu sync.Mutex
+	calls := map[string][]call{}
+	record := func(path string) {
+		w.OnChange(path, func(oldValue, newValue any) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[path] = append(calls[path], call{oldValue, newValue})
+		})
+	}
+	record("database.pool_size")
+	record("database")
+	record("log_level")
+
+	var cfg Config
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+
+	record("databases") // registering after Watch works too
+
+	source.set("database:\n  host: b\n  pool_size: 10\ndatabases: [x]\nlog_level: info\n")
+	w.Trigger()
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	select {
+	case <-updates:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []call{{5, 10}}, calls["database.pool_size"])
+	assert.Equal(t, []call{{"a", "b"}, {5, 10}}, calls["database"], "prefix matches each nested change")
+	assert.Equal(t, []call{{nil, "x"}}, calls["databases"], "sibling with shared prefix is distinct")
+	assert.Empty(t, calls["log_level"])
+}
+
+// leasedResolver is a watcher.LeaseRenewer whose secret the test can rotate.
+type leasedResolver struct {
+	mu       sync.Mutex
+	value    string
+	expired  bool
+	renewals int
+	renewErr error
+}
+
+func (r *leasedResolver) Resolve(context.Context, string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return []byte(r.value), nil
+}
+
+func (r *leasedResolver) RenewLeases(context.Context) (time.Duration, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.renewals++
+	expired := r.expired
+	r.expired = false
+
+	return time.Minute, expired, r.renewErr
+}
+
+func (r *leasedResolver) rotate(value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.value = value
+	r.expired = true
+}
+
+func (r *leasedResolver) renewCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.renewals
+}
+
+func TestWatcher_AutoRenewLease(t *testing.T) {
+	type Config struct {
+		Password string `ref:"lease://db#password"`
+	}
+
+	setup := func(t *testing.T, autoRenew bool) (*watcher.Watcher, *leasedResolver, *watchertest.FakeClock) {
+		t.Helper()
+
+		resolver := &leasedResolver{value: "initial"}
+		clock := watchertest.NewFakeClock(time.Unix(0, 0))
+		b := watcher.New().
+			FromBytes([]byte("{}")).
+			WithRefResolver(resolver).
+			WithClock(clock).
+			WithWatchInterval(time.Hour).
+			WithDebounceInterval(time.Second)
+		if autoRenew {
+			b = b.WithAutoRenewLease()
+		}
+		w, err := b.Build()
+		require.NoError(t, err)
+		t.Cleanup(w.Stop)
+
+		return w, resolver, clock
+	}
+
+	t.Run("reloads when a lease expires", func(t *testing.T) {
+		w, resolver, clock := setup(t, true)
+
+		var cfg Config
+		updates, err := w.Watch(&cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "initial", cfg.Password)
+
+		// Poll ticker + renewal timer; leases are checked once at startup.
+		clock.BlockUntil(2)
+		assert.Equal(t, 1, resolver.renewCount())
+
+		resolver.rotate("rotated")
+		clock.Advance(time.Minute)
+
+		// The renewal arms the debounce timer and schedules the next renewal.
+		clock.BlockUntil(3)
+		clock.Advance(time.Second)
+
+		select {
+		case v := <-updates:
+			assert.Equal(t, "rotated", v.(*Config).Password)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+		assert.Equal(t, 2, resolver.renewCount())
+	})
+
+	t.Run("reports renewal errors", func(t *testing.T) {
+		w, resolver, clock := setup(t, true)
+		resolver.renewErr = errors.New("permission denied")
+
+		var cfg Config
+		_, err := w.Watch(&cfg)
+		require.NoError(t, err)
+		clock.BlockUntil(2)
+
+		select {
+		case err := <-w.Errors():
+			assert.Contains(t, err.Error(), "failed to renew leases: permission denied")
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for renewal error")
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		w, resolver, clock := setup(t, false)
+
+		var cfg Config
+		updates, err := w.Watch(&cfg)
+		require.NoError(t, err)
+
+		resolver.rotate("rotated")
+		w.Trigger()
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+
+		select {
+		case v := <-updates:
+			assert.Equal(t, "rotated", v.(*Config).Password)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+		assert.Zero(t, resolver.renewCount())
+	})
+}
+
+func TestWatcher_Schedule(t *testing.T) {
+	type Maintenance struct {
+		schedule.Window `yaml:",inline"`
+		Enabled         bool `yaml:"enabled"`
+	}
+	type Config struct {
+		Maintenance *Maintenance `yaml:"maintenance"`
+	}
+
+	start := time.Date(2026, 11, 1, 1, 0, 0, 0, time.UTC)
+	clock := watchertest.NewFakeClock(start)
+	w, err := watcher.New().
+		FromBytes([]byte("maintenance:\n  active_from: 2026-11-01T02:00:00Z\n  active_until: 2026-11-01T04:00:00Z\n  enabled: true\n")).
+		WithClock(clock).
+		WithSchedule().
+		WithWatchInterval(24 * time.Hour).
+		WithDebounceInterval(time.Second).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg Config
+	updates, err := w.WatchChanges(&cfg)
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Maintenance)
+
+	next := func() *Config {
+		t.Helper()
+
+		select {
+		case u := <-updates:
+			require.NoError(t, u.Err)
+
+			return u.Config.(*Config)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+
+		return nil
+	}
+
+	// Poll ticker + boundary timer; reaching the boundary replaces the
+	// boundary timer with the debounce timer
+	clock.BlockUntil(2)
+	clock.Advance(time.Hour)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	opened := next()
+	require.NotNil(t, opened.Maintenance)
+	assert.True(t, opened.Maintenance.Enabled)
+
+	clock.BlockUntil(2)
+	clock.Advance(2 * time.Hour)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	assert.Nil(t, next().Maintenance)
+}
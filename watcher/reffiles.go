@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/arloliu/fuda"
+	"github.com/fsnotify/fsnotify"
+)
+
+// refFiles records the local files referenced by file:// refs during loads,
+// so they can be watched alongside the main config file.
+type refFiles struct {
+	mu      sync.Mutex
+	files   map[string]bool // absolute paths of referenced files
+	dirs    map[string]bool // directories added to the fsnotify watcher
+	pending bool            // files were recorded since the last watchDirs
+}
+
+// newRefFiles creates an empty tracker.
+func newRefFiles() *refFiles {
+	return &refFiles{files: make(map[string]bool), dirs: make(map[string]bool)}
+}
+
+// middleware records every file:// URI passing through the resolver chain,
+// whether or not the file currently exists, so a secret that appears later
+// still triggers a reload.
+func (t *refFiles) middleware(next fuda.RefResolver) fuda.RefResolver {
+	return fuda.RefResolverFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		if path, ok := strings.CutPrefix(uri, "file://"); ok && path != "" {
+			if abs, err := filepath.Abs(path); err == nil {
+				t.mu.Lock()
+				if !t.files[abs] {
+					t.files[abs] = true
+					t.pending = true
+				}
+				t.mu.Unlock()
+			}
+		}
+
+		return next.Resolve(ctx, uri)
+	})
+}
+
+// hasFiles reports whether any file has been recorded.
+func (t *refFiles) hasFiles() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.files) > 0
+}
+
+// watchDirs adds the parent directory of every recorded file to fsw.
+// Directories are watched rather than files because secret mounts such as
+// Kubernetes volumes rotate files by atomically swapping symlinks, which
+// replaces the watched inode.
+func (t *refFiles) watchDirs(fsw *fsnotify.Watcher) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.pending {
+		return
+	}
+	t.pending = false
+
+	for file := range t.files {
+		dir := filepath.Dir(file)
+		if t.dirs[dir] {
+			continue
+		}
+		if err := fsw.Add(dir); err != nil {
+			t.pending = true // retry after the next reload, e.g. once mounted
+
+			continue
+		}
+		t.dirs[dir] = true
+	}
+}
+
+// affected reports whether an fsnotify event may change a referenced file:
+// the file itself changed, or a hidden entry (such as Kubernetes' "..data"
+// symlink) in a watched directory was replaced.
+func (t *refFiles) affected(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.files[name] {
+		return true
+	}
+
+	return t.dirs[filepath.Dir(name)] && strings.HasPrefix(filepath.Base(name), "..")
+}
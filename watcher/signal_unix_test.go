@@ -0,0 +1,57 @@
+//go:build unix
+
+package watcher
+
+import (
+	"context"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_OnSignal(t *testing.T) {
+	var content atomic.Value
+	content.Store("host: initial.com\n")
+	fetch := func(context.Context) ([]byte, error) {
+		return []byte(content.Load().(string)), nil
+	}
+
+	w, err := New().
+		FromSource(fetch).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(10 * time.Millisecond).
+		OnSignal(syscall.SIGHUP).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg testConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "initial.com", cfg.Host)
+
+	// Wait until the signal is relayed to the watcher
+	<-w.ready
+
+	content.Store("host: signaled.com\n")
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case newCfg := <-updates:
+		updated, ok := newCfg.(*testConfig)
+		require.True(t, ok, "expected *testConfig")
+		assert.Equal(t, "signaled.com", updated.Host)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+}
+
+func TestWatcher_OnSignalRequiresSignal(t *testing.T) {
+	_, err := New().FromBytes([]byte("host: a\n")).OnSignal().Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OnSignal requires at least one signal")
+}
@@ -0,0 +1,31 @@
+//go:build !fuda_minimal
+
+package watcher
+
+import "github.com/arloliu/fuda"
+
+// FromURL sets an http or https URL serving the configuration, polled every
+// watch interval (see WithWatchInterval). Polls are conditional requests on
+// the document's ETag, so an unchanged document is not downloaded again.
+// Options add headers or a bearer token, as for fuda's Builder.FromURL.
+//
+// Example:
+//
+//	w, _ := watcher.New().
+//	    FromURL("https://config.internal/apps/billing.yaml",
+//	        fuda.WithBearerToken(token)).
+//	    WithWatchInterval(time.Minute).
+//	    Build()
+func (b *Builder) FromURL(rawURL string, opts ...fuda.URLOption) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	src, err := fuda.NewURLSource(rawURL, opts...)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	return b.FromSource(src.Fetch)
+}
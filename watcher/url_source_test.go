@@ -0,0 +1,66 @@
+//go:build !fuda_minimal
+
+package watcher_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/watcher"
+	"github.com/arloliu/fuda/watcher/watchertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_FromURL(t *testing.T) {
+	source := &mutableSource{content: "host: initial.com\n"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		body, _ := source.fetch(r.Context())
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write(body)
+	}))
+	defer ts.Close()
+
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+	w, err := watcher.New().
+		FromURL(ts.URL, fuda.WithBearerToken("token")).
+		WithClock(clock).
+		WithWatchInterval(time.Minute).
+		WithDebounceInterval(time.Second).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg harnessConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "initial.com", cfg.Host)
+
+	source.set("host: updated.com\n")
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	assert.Equal(t, "updated.com", receive(t, updates).Host)
+
+	_, err = watcher.New().FromURL("file:///etc/app.yaml").Build()
+	require.Error(t, err)
+}
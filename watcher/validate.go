@@ -0,0 +1,23 @@
+//go:build !fuda_minimal
+
+package watcher
+
+import (
+	"github.com/arloliu/fuda"
+	"github.com/go-playground/validator/v10"
+)
+
+// WithValidator sets a custom validator instance.
+func (b *Builder) WithValidator(v *validator.Validate) *Builder {
+	b.config.validator = v
+	return b
+}
+
+// withValidator sets v, the validator given to WithValidator, on lb.
+func withValidator(lb *fuda.Builder, v any) *fuda.Builder {
+	if v, ok := v.(*validator.Validate); ok && v != nil {
+		return lb.WithValidator(v)
+	}
+
+	return lb
+}
@@ -0,0 +1,10 @@
+//go:build fuda_minimal
+
+package watcher
+
+import "github.com/arloliu/fuda"
+
+// withValidator returns lb unchanged: fuda_minimal builds do not validate.
+func withValidator(lb *fuda.Builder, _ any) *fuda.Builder {
+	return lb
+}
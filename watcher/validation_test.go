@@ -0,0 +1,473 @@
+//go:build !fuda_minimal
+
+package watcher_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/watcher"
+	"github.com/arloliu/fuda/watcher/watchertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_ReloadErrors(t *testing.T) {
+	type Config struct {
+		Port int `yaml:"port" validate:"min=1"`
+	}
+
+	setup := func(t *testing.T, policy watcher.ReloadPolicy) (*watcher.Watcher, *mutableSource, *watchertest.FakeClock) {
+		t.Helper()
+
+		source := &mutableSource{content: "port: 80\n"}
+		clock := watchertest.NewFakeClock(time.Unix(0, 0))
+		w, err := watcher.New().
+			FromSource(source.fetch).
+			WithClock(clock).
+			WithDebounceInterval(time.Second).
+			WithReloadPolicy(policy).
+			Build()
+		require.NoError(t, err)
+		t.Cleanup(w.Stop)
+
+		return w, source, clock
+	}
+
+	reload := func(w *watcher.Watcher, clock *watchertest.FakeClock) {
+		w.Trigger()
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+	}
+
+	receiveErr := func(t *testing.T, errs <-chan error) error {
+		t.Helper()
+
+		select {
+		case err := <-errs:
+			return err
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for reload error")
+		}
+
+		return nil
+	}
+
+	t.Run("keep last good", func(t *testing.T) {
+		w, source, clock := setup(t, watcher.KeepLastGood)
+
+		var cfg Config
+		updates, err := w.Watch(&cfg)
+		require.NoError(t, err)
+
+		source.set("port: 0\n")
+		reload(w, clock)
+
+		err = receiveErr(t, w.Errors())
+		var watcherErr *watcher.WatcherError
+		require.ErrorAs(t, err, &watcherErr)
+		assert.Contains(t, err.Error(), "failed to reload config")
+		assert.Contains(t, err.Error(), "min")
+		assert.Equal(t, 80, cfg.Port, "last good config is kept")
+		assert.Empty(t, updates)
+
+		// A later good config is applied as usual.
+		source.set("port: 81\n")
+		reload(w, clock)
+		select {
+		case v := <-updates:
+			assert.Equal(t, 81, v.(*Config).Port)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+	})
+
+	t.Run("propagate error", func(t *testing.T) {
+		w, source, clock := setup(t, watcher.PropagateError)
+
+		var cfg Config
+		updates, err := w.Watch(&cfg)
+		require.NoError(t, err)
+
+		source.set("port: 0\n")
+		reload(w, clock)
+
+		select {
+		case v := <-updates:
+			err, ok := v.(error)
+			require.True(t, ok, "expected an error value, got %T", v)
+			assert.Contains(t, err.Error(), "failed to reload config")
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for propagated error")
+		}
+		require.Error(t, receiveErr(t, w.Errors()))
+		assert.Equal(t, 80, cfg.Port)
+	})
+
+	t.Run("propagate error with changes", func(t *testing.T) {
+		w, source, clock := setup(t, watcher.PropagateError)
+
+		var cfg Config
+		updates, err := w.WatchChanges(&cfg)
+		require.NoError(t, err)
+
+		source.set("port: 0\n")
+		reload(w, clock)
+
+		select {
+		case u := <-updates:
+			require.Error(t, u.Err)
+			assert.Nil(t, u.Config)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for propagated error")
+		}
+	})
+
+	t.Run("closed on stop", func(t *testing.T) {
+		w, _, _ := setup(t, watcher.KeepLastGood)
+
+		var cfg Config
+		_, err := w.Watch(&cfg)
+		require.NoError(t, err)
+
+		errs := w.Errors()
+		w.Stop()
+		_, ok := <-errs
+		assert.False(t, ok)
+	})
+}
+
+func TestWatcher_Run(t *testing.T) {
+	type Config struct {
+		Port int `yaml:"port" validate:"min=1"`
+	}
+
+	setup := func(t *testing.T, content string, policy watcher.ReloadPolicy) (*watcher.Watcher, *mutableSource, *watchertest.FakeClock) {
+		t.Helper()
+
+		source := &mutableSource{content: content}
+		clock := watchertest.NewFakeClock(time.Unix(0, 0))
+		w, err := watcher.New().
+			FromSource(source.fetch).
+			WithClock(clock).
+			WithWatchInterval(time.Hour).
+			WithDebounceInterval(time.Second).
+			WithReloadPolicy(policy).
+			Build()
+		require.NoError(t, err)
+		t.Cleanup(w.Stop)
+
+		return w, source, clock
+	}
+
+	// run starts Run in the background, forwarding each config's port.
+	run := func(ctx context.Context, w *watcher.Watcher) (<-chan int, <-chan error) {
+		ports := make(chan int, 4)
+		done := make(chan error, 1)
+		go func() {
+			var cfg Config
+			done <- w.Run(ctx, &cfg, func(v any) {
+				ports <- v.(*Config).Port
+			})
+		}()
+
+		return ports, done
+	}
+
+	receiveValue := func(t *testing.T, ch <-chan int) int {
+		t.Helper()
+
+		select {
+		case v := <-ch:
+			return v
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config")
+		}
+
+		return 0
+	}
+
+	receiveDone := func(t *testing.T, done <-chan error) error {
+		t.Helper()
+
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for Run to return")
+		}
+
+		return nil
+	}
+
+	t.Run("delivers configs until canceled", func(t *testing.T) {
+		w, source, clock := setup(t, "port: 80\n", watcher.KeepLastGood)
+		ctx, cancel := context.WithCancel(t.Context())
+
+		ports, done := run(ctx, w)
+		assert.Equal(t, 80, receiveValue(t, ports), "initial config")
+
+		source.set("port: 81\n")
+		w.Trigger()
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+		assert.Equal(t, 81, receiveValue(t, ports))
+
+		cancel()
+		require.NoError(t, receiveDone(t, done))
+
+		// The watcher is stopped, so it can be started again.
+		var cfg Config
+		_, err := w.Watch(&cfg)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns initial load error", func(t *testing.T) {
+		w, _, _ := setup(t, "port: 0\n", watcher.KeepLastGood)
+
+		var cfg Config
+		err := w.Run(t.Context(), &cfg, func(any) { t.Error("fn must not be called") })
+		require.Error(t, err)
+	})
+
+	t.Run("returns propagated reload error", func(t *testing.T) {
+		w, source, clock := setup(t, "port: 80\n", watcher.PropagateError)
+
+		ports, done := run(t.Context(), w)
+		assert.Equal(t, 80, receiveValue(t, ports))
+
+		source.set("port: 0\n")
+		w.Trigger()
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+
+		err := receiveDone(t, done)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to reload config")
+	})
+}
+
+func TestWatcher_Bind(t *testing.T) {
+	type Config struct {
+		Port int `yaml:"port" validate:"min=1"`
+	}
+
+	source := &mutableSource{content: "port: 80\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithClock(clock).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(time.Second).
+		WithReloadPolicy(watcher.PropagateError).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	// OnChange runs after the value is stored, so it sees the new config.
+	seen := make(chan int, 1)
+	var cfg fuda.Value[Config]
+	w.OnChange("port", func(_, _ any) {
+		seen <- cfg.Get().Port
+	})
+
+	require.NoError(t, watcher.Bind(w, &cfg))
+	assert.Equal(t, 80, cfg.Get().Port)
+
+	reload := func(content string) {
+		source.set(content)
+		w.Trigger()
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+	}
+
+	reload("port: 81\n")
+	select {
+	case port := <-seen:
+		assert.Equal(t, 81, port)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	// A failed reload keeps the value and is reported on Errors, even under
+	// PropagateError, since no channel is consumed.
+	reload("port: 0\n")
+	select {
+	case err := <-w.Errors():
+		assert.Contains(t, err.Error(), "failed to reload config")
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for reload error")
+	}
+	assert.Equal(t, 81, cfg.Get().Port)
+
+	reload("port: 82\n")
+	select {
+	case port := <-seen:
+		assert.Equal(t, 82, port)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	t.Run("returns initial load error", func(t *testing.T) {
+		w, err := watcher.New().FromBytes([]byte("port: 0\n")).Build()
+		require.NoError(t, err)
+
+		var cfg fuda.Value[Config]
+		require.Error(t, watcher.Bind(w, &cfg))
+		assert.Nil(t, cfg.Get())
+	})
+}
+
+// recordingMetrics is a fuda.Metrics that records what it receives.
+type recordingMetrics struct {
+	mu      sync.Mutex
+	reloads []error
+	times   []time.Time
+	schemes []string
+}
+
+func (m *recordingMetrics) RefResolved(scheme string, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.schemes = append(m.schemes, scheme)
+}
+
+func (m *recordingMetrics) Reloaded(t time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reloads = append(m.reloads, err)
+	m.times = append(m.times, t)
+}
+
+func TestWatcher_Metrics(t *testing.T) {
+	type Config struct {
+		Port  int    `yaml:"port" validate:"min=1"`
+		Token string `ref:"mem://token"`
+	}
+
+	source := &mutableSource{content: "port: 80\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+	metrics := &recordingMetrics{}
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithRefResolver(fuda.RefResolverFunc(func(context.Context, string) ([]byte, error) {
+			return []byte("secret"), nil
+		})).
+		WithClock(clock).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(time.Second).
+		WithMetrics(metrics).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg Config
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+
+	source.set("port: 81\n")
+	w.Trigger()
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	select {
+	case <-updates:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	source.set("port: 0\n")
+	w.Trigger()
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	select {
+	case <-w.Errors():
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for reload error")
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	require.Len(t, metrics.reloads, 2, "the initial load is not a reload")
+	require.NoError(t, metrics.reloads[0])
+	require.Error(t, metrics.reloads[1])
+	assert.Equal(t, time.Unix(2, 0), metrics.times[1], "reload times should come from the clock")
+	assert.Equal(t, []string{"mem", "mem", "mem"}, metrics.schemes)
+}
+
+// syncBuffer is a bytes.Buffer safe for a logger writing from the watch
+// goroutine while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestWatcher_WithLogger(t *testing.T) {
+	type Config struct {
+		Port int `yaml:"port" validate:"min=1"`
+	}
+
+	source := &mutableSource{content: "port: 80\n"}
+	clock := watchertest.NewFakeClock(time.Unix(0, 0))
+	var buf syncBuffer
+	w, err := watcher.New().
+		FromSource(source.fetch).
+		WithClock(clock).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(time.Second).
+		WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg Config
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+
+	source.set("port: 81\n")
+	w.Trigger()
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	select {
+	case <-updates:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+
+	source.set("port: 0\n")
+	w.Trigger()
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	select {
+	case <-w.Errors():
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for reload error")
+	}
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="reload scheduled" reason=trigger`)
+	assert.Contains(t, out, `msg="config reloaded" changes=1`)
+	assert.Contains(t, out, `msg="config reload failed, keeping the last good config"`)
+	assert.Contains(t, out, `msg="config document read"`, "reload loaders should log too")
+}
@@ -30,10 +30,19 @@
 //
 // # Watch Mechanisms
 //
-// The watcher uses two mechanisms for detecting changes:
+// The watcher uses three mechanisms for detecting changes:
 //
 // 1. File system watching (fsnotify) - for config files and local secrets
 // 2. Periodic polling - for remote secrets (Vault, HTTP endpoints)
+// 3. Push notifications - for resolvers implementing [WatchableResolver] (etcd)
+//
+// With [Builder.WithSchedule], sections carrying a schedule.Window are only
+// in effect during their window, and the config is reloaded at every window
+// boundary.
+//
+// With [Builder.WithAutoRenewLease], leases of dynamic secrets from resolvers
+// implementing [LeaseRenewer] (Vault) are renewed as they come due, and the
+// config is reloaded once a secret rotates.
 //
 // # Thread Safety
 //
@@ -43,13 +52,21 @@
 package watcher
 
 import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/arloliu/fuda"
+	"github.com/arloliu/fuda/internal/loader"
+	"github.com/arloliu/fuda/schedule"
 	"github.com/fsnotify/fsnotify"
-	"github.com/go-playground/validator/v10"
 	"github.com/spf13/afero"
 )
 
@@ -60,14 +77,78 @@ type Watcher struct {
 	fsWatcher     *fsnotify.Watcher
 	stopChan      chan struct{}
 	doneChan      chan struct{}
+	triggerChan   chan struct{}
+	ready         chan struct{} // closed once watchLoop has set up all event sources
 	updatesChan   chan any
+	changesChan   chan Update
+	store         func(cfg any) // set by Bind instead of a consumed updates channel
+	errorsChan    chan error
+	subscriptions []subscription
 	mu            sync.Mutex
 	running       bool
 	watchedFiles  []string
+	configDirs    map[string]bool // parent directories of configPaths being watched
+	unwatched     map[string]bool // configPaths whose file watch was lost and not yet re-added
 	lastConfig    any
-	configPath    string
+	configPaths   []string
 	configContent []byte
+	source        SourceFunc
 	fs            afero.Fs
+	refFiles      *refFiles
+	nextBoundary  time.Time // next schedule window boundary, if WithSchedule
+}
+
+// Update is a configuration change emitted by WatchChanges.
+type Update struct {
+	// Config is a copy of the new configuration, of the same type as the
+	// target passed to WatchChanges.
+	Config any
+	// Changes lists the values that differ from the previous configuration,
+	// with sensitive values redacted. See fuda.Diff.
+	Changes []fuda.FieldChange
+	// Err is set instead of Config and Changes when a reload failed and the
+	// watcher uses the PropagateError policy.
+	Err error
+}
+
+// ReloadPolicy controls how the watcher reports a reload that fails, for
+// example because the new config does not pass validation. In every policy
+// the target keeps its last good value and the error is sent on Errors.
+type ReloadPolicy int
+
+// subscription is a path-specific change callback registered with OnChange.
+type subscription struct {
+	path string
+	fn   func(oldValue, newValue any)
+}
+
+// SourceFunc fetches the raw configuration document from a non-file source
+// such as a key-value store. It is called once when the watcher is built and
+// again on every reload.
+type SourceFunc func(ctx context.Context) ([]byte, error)
+
+// WatchableResolver is implemented by resolvers that can push change
+// notifications instead of relying solely on the polling ticker.
+//
+// Watch starts watching every reference the resolver has resolved so far (and
+// any it resolves later) and returns a channel that receives a value whenever
+// one of them changes. Watching stops when ctx is canceled.
+type WatchableResolver interface {
+	fuda.RefResolver
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
+
+// LeaseRenewer is implemented by resolvers whose secrets carry leases, such
+// as Vault dynamic database credentials. The vault package's Resolver
+// implements it.
+//
+// RenewLeases renews the leases that are due, drops those that can no longer
+// be renewed, and reports via expired whether a reload is needed to pick up
+// freshly issued secrets. next is the delay until the next renewal is due, or
+// 0 if no leases are held.
+type LeaseRenewer interface {
+	fuda.RefResolver
+	RenewLeases(ctx context.Context) (next time.Duration, expired bool, err error)
 }
 
 // watcherConfig holds internal configuration for the watcher.
@@ -78,6 +159,12 @@ type watcherConfig struct {
 	autoRenewLease   bool
 	debounceInterval time.Duration
 	validator        any // *validator.Validate
+	reloadPolicy     ReloadPolicy
+	clock            Clock
+	schedule         bool
+	signals          []os.Signal // reload triggers set by OnSignal
+	metrics          fuda.Metrics
+	logger           *slog.Logger
 }
 
 // defaultWatchInterval is the default polling interval for remote secrets.
@@ -86,12 +173,27 @@ const defaultWatchInterval = 30 * time.Second
 // defaultDebounceInterval prevents rapid successive reloads.
 const defaultDebounceInterval = 100 * time.Millisecond
 
+// errorsBuffer is the capacity of the Errors channel; further errors are
+// dropped until it is drained.
+const errorsBuffer = 8
+
+const (
+	// KeepLastGood keeps serving the last good config and reports a failed
+	// reload only on Errors. This is the default.
+	KeepLastGood ReloadPolicy = iota
+	// PropagateError additionally delivers a failed reload on the updates
+	// channel: as an error value from Watch, or as an Update with Err set
+	// from WatchChanges. Consumers must handle both kinds of value.
+	PropagateError
+)
+
 // New creates a new watcher Builder.
 func New() *Builder {
 	return &Builder{
 		config: watcherConfig{
 			watchInterval:    defaultWatchInterval,
 			debounceInterval: defaultDebounceInterval,
+			clock:            realClock{},
 		},
 	}
 }
@@ -113,27 +215,190 @@ func (w *Watcher) Watch(target any) (<-chan any, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.running {
-		return nil, &WatcherError{Message: "watcher is already running"}
+	if err := w.start(target); err != nil {
+		return nil, err
 	}
 
-	// Perform initial load
-	if err := w.loader.Load(target); err != nil {
+	w.updatesChan = make(chan any, 1)
+	go w.watchLoop(target)
+
+	return w.updatesChan, nil
+}
+
+// WatchChanges is like Watch, but each update carries the list of changed
+// values alongside the new configuration, so applications can react only to
+// the changes they care about:
+//
+//	updates, err := watcher.WatchChanges(&cfg)
+//	go func() {
+//	    for u := range updates {
+//	        for _, c := range u.Changes {
+//	            if strings.HasPrefix(c.Path, "database.") {
+//	                db.Reconnect(u.Config.(*Config).Database)
+//	                break
+//	            }
+//	        }
+//	    }
+//	}()
+func (w *Watcher) WatchChanges(target any) (<-chan Update, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.start(target); err != nil {
 		return nil, err
 	}
 
-	// Store a copy of the initial config for change detection
-	w.lastConfig = w.deepCopy(target)
+	w.changesChan = make(chan Update, 1)
+	go w.watchLoop(target)
 
-	// Start watching
-	w.running = true
+	return w.changesChan, nil
+}
+
+// Run watches target until ctx is canceled, calling fn with a copy of the
+// initial configuration and then with every update. It is an alternative to
+// Watch and Stop that ties the watcher's lifetime to ctx, so it composes with
+// errgroup-based service runners:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	g.Go(func() error {
+//	    return w.Run(ctx, &cfg, func(cfg any) {
+//	        app.UpdateConfig(cfg.(*Config))
+//	    })
+//	})
+//
+// fn runs on the caller's goroutine. Run returns nil once ctx is canceled or
+// the watcher is stopped, and an error if the initial load fails. Under the
+// PropagateError policy it also stops and returns the first failed reload.
+// The watcher is stopped before Run returns.
+func (w *Watcher) Run(ctx context.Context, target any, fn func(cfg any)) error {
+	w.mu.Lock()
+	if err := w.start(target); err != nil {
+		w.mu.Unlock()
+
+		return err
+	}
+	initial := w.deepCopy(target)
 	w.updatesChan = make(chan any, 1)
-	w.stopChan = make(chan struct{})
-	w.doneChan = make(chan struct{})
+	updates := w.updatesChan
+	go w.watchLoop(target)
+	w.mu.Unlock()
+
+	defer w.Stop()
+
+	fn(initial)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case v, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err, isErr := v.(error); isErr {
+				return err
+			}
+			fn(v)
+		}
+	}
+}
+
+// Bind starts watching and keeps v holding the current configuration: the
+// initial config is stored before Bind returns, and every reload that changes
+// it stores a fresh copy, so readers calling v.Get never see a config that is
+// being modified. It is an alternative to Watch for applications that only
+// read the latest config; OnChange callbacks still run, after v is updated.
+//
+// Failed reloads leave v unchanged and are reported on Errors, whatever the
+// ReloadPolicy. Bind returns an error if the initial load fails. Call Stop to
+// stop watching.
+//
+// Example:
+//
+//	var cfg fuda.Value[Config]
+//	if err := watcher.Bind(w, &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer w.Stop()
+//
+//	http.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
+//	    fmt.Fprintln(rw, cfg.Get().Greeting)
+//	})
+func Bind[T any](w *Watcher, v *fuda.Value[T]) error {
+	target := new(T)
 
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.start(target); err != nil {
+		return err
+	}
+
+	initial, _ := w.deepCopy(target).(*T)
+	v.Store(initial)
+	w.store = func(cfg any) {
+		if c, ok := cfg.(*T); ok {
+			v.Store(c)
+		}
+	}
+	w.updatesChan = make(chan any, 1)
 	go w.watchLoop(target)
 
-	return w.updatesChan, nil
+	return nil
+}
+
+// OnChange registers fn to be called when the value at path changes on
+// reload. path uses yaml field names as reported by fuda.Diff, such as
+// "database.pool_size". A path naming a struct, slice, or map also matches
+// changes below it, and fn is then called once per changed value.
+//
+// fn receives the old and new values of each change; values of sensitive
+// fields arrive redacted, so read them from the new config instead.
+// Callbacks run on the watch goroutine, in registration order, before the
+// update is sent on the channel returned by Watch, which must still be
+// consumed. OnChange may be called before or after Watch.
+//
+// Example:
+//
+//	w.OnChange("database.pool_size", func(oldValue, newValue any) {
+//	    db.SetMaxOpenConns(newValue.(int))
+//	})
+func (w *Watcher) OnChange(path string, fn func(oldValue, newValue any)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscriptions = append(w.subscriptions, subscription{path: path, fn: fn})
+}
+
+// Errors returns a channel that receives an error for every failed reload,
+// such as an unreadable source or a config that fails validation, so
+// operators can alert on bad config pushes. The last good config stays in
+// effect. Errors are dropped while the channel is full, so it need not be
+// consumed. Call Errors after Watch or WatchChanges; the channel is closed
+// when the watcher stops.
+func (w *Watcher) Errors() <-chan error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.errorsChan
+}
+
+// Trigger requests a reload as if a watched source had changed.
+// The reload is debounced like any other change and an update is emitted only
+// if the resulting configuration differs. Trigger is a no-op when the watcher
+// is not running. It is useful for signal handlers and for simulating file
+// events in tests.
+func (w *Watcher) Trigger() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return
+	}
+
+	select {
+	case w.triggerChan <- struct{}{}:
+	default:
+	}
 }
 
 // Stop gracefully stops the watcher.
@@ -155,39 +420,82 @@ func (w *Watcher) Stop() {
 	}
 }
 
+// start performs the initial load and prepares the watcher to run.
+// The caller must hold w.mu and start watchLoop.
+func (w *Watcher) start(target any) error {
+	if w.running {
+		return &WatcherError{Message: "watcher is already running"}
+	}
+
+	// Perform initial load
+	if err := w.loader.Load(target); err != nil {
+		return err
+	}
+	w.applySchedule(target)
+
+	// Store a copy of the initial config for change detection
+	w.lastConfig = w.deepCopy(target)
+
+	w.running = true
+	w.updatesChan = nil
+	w.changesChan = nil
+	w.store = nil
+	w.errorsChan = make(chan error, errorsBuffer)
+	w.stopChan = make(chan struct{})
+	w.doneChan = make(chan struct{})
+	w.triggerChan = make(chan struct{}, 1)
+	w.ready = make(chan struct{})
+
+	return nil
+}
+
+// loopTimers holds the timers of watchLoop.
+type loopTimers struct {
+	debounce loopTimer // delays reloads, so rapid successive changes reload once
+	renew    loopTimer // renews leased secrets
+	boundary loopTimer // reloads at the next boundary of scheduled sections
+}
+
+// stop stops all timers.
+func (t *loopTimers) stop() {
+	t.debounce.stop()
+	t.renew.stop()
+	t.boundary.stop()
+}
+
 // watchLoop is the main watch loop that monitors for changes.
 func (w *Watcher) watchLoop(target any) {
 	defer close(w.doneChan)
-	defer close(w.updatesChan)
-
-	// Setup file watcher if we have a config file
-	var fsChan <-chan fsnotify.Event
-	if w.configPath != "" {
-		var err error
-		w.fsWatcher, err = fsnotify.NewWatcher()
-		if err == nil {
-			_ = w.fsWatcher.Add(w.configPath)
-			fsChan = w.fsWatcher.Events
-			w.watchedFiles = append(w.watchedFiles, w.configPath)
-		}
-	}
+	defer w.closeUpdates()
+	defer close(w.errorsChan)
+
+	fsChan := w.startFileWatch()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resolverChan := w.watchResolver(ctx)
 
 	// Setup polling timer for remote secrets
-	pollTicker := time.NewTicker(w.config.watchInterval)
+	pollTicker := w.config.clock.NewTicker(w.config.watchInterval)
 	defer pollTicker.Stop()
 
-	// Debounce timer to prevent rapid successive reloads
-	var debounceTimer *time.Timer
-	var debounceChan <-chan time.Time
+	timers := &loopTimers{}
+	defer timers.stop()
 
-	reload := func() {
-		if debounceTimer != nil {
-			debounceTimer.Stop()
-		}
-		debounceTimer = time.NewTimer(w.config.debounceInterval)
-		debounceChan = debounceTimer.C
+	renewer := w.leaseRenewer()
+	if renewer != nil {
+		w.renewLeases(ctx, renewer, timers)
+	}
+	w.armBoundary(timers)
+
+	// Reload on the signals given to OnSignal
+	signalChan := w.notifySignals()
+	if signalChan != nil {
+		defer signal.Stop(signalChan)
 	}
 
+	close(w.ready)
+
 	for {
 		select {
 		case <-w.stopChan:
@@ -198,75 +506,329 @@ func (w *Watcher) watchLoop(target any) {
 				fsChan = nil
 				continue
 			}
-			// Only react to write and create events
-			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
-				reload()
+			// React to writes and replacements of the config files, and to
+			// any change of a referenced file
+			if w.handleConfigEvent(event) || w.refFiles.affected(event) {
+				w.scheduleReload(timers, "file "+event.Name)
+			}
+
+		case _, ok := <-resolverChan:
+			if !ok {
+				resolverChan = nil
+				continue
 			}
+			w.scheduleReload(timers, "resolver")
 
-		case <-pollTicker.C:
+		case <-pollTicker.C():
 			// Poll remote secrets
-			reload()
-
-		case <-debounceChan:
-			debounceChan = nil
-			if changed := w.reloadIfChanged(target); changed {
-				// Create a copy and send to updates channel
-				newConfig := w.deepCopy(target)
-				select {
-				case w.updatesChan <- newConfig:
-				case <-w.stopChan:
-					return
-				}
+			w.scheduleReload(timers, "poll")
+
+		case <-w.triggerChan:
+			w.scheduleReload(timers, "trigger")
+
+		case sig := <-signalChan:
+			w.scheduleReload(timers, "signal "+sig.String())
+
+		case <-timers.renew.c:
+			w.renewLeases(ctx, renewer, timers)
+
+		case <-timers.boundary.c:
+			timers.boundary.c = nil
+			w.scheduleReload(timers, "schedule")
+
+		case <-timers.debounce.c:
+			timers.debounce.c = nil
+			if !w.handleReload(target, timers) {
+				return
 			}
 		}
 	}
 }
 
-// reloadIfChanged reloads configuration and returns true if it changed.
-func (w *Watcher) reloadIfChanged(target any) bool {
-	// For file-based config, check if content changed
-	if w.configPath != "" {
-		fs := w.fs
-		if fs == nil {
-			fs = fuda.DefaultFs
-		}
-		content, err := afero.ReadFile(fs, w.configPath)
-		if err != nil {
+// startFileWatch watches the config files and the files referenced via
+// file://, returning the channel of their events. It returns nil if file
+// watching is unavailable.
+func (w *Watcher) startFileWatch() <-chan fsnotify.Event {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		w.logDebug("file watching unavailable, relying on polling", "error", err)
+
+		return nil
+	}
+
+	w.fsWatcher = fsw
+	w.watchConfigFiles(fsw)
+	w.refFiles.watchDirs(fsw)
+
+	return fsw.Events
+}
+
+// watchResolver subscribes to push notifications from the resolver, if
+// supported, returning nil otherwise.
+func (w *Watcher) watchResolver(ctx context.Context) <-chan struct{} {
+	wr, ok := w.config.refResolver.(WatchableResolver)
+	if !ok {
+		return nil
+	}
+
+	ch, err := wr.Watch(ctx)
+	if err != nil {
+		w.logDebug("resolver watch unavailable, relying on polling", "error", err)
+
+		return nil
+	}
+
+	return ch
+}
+
+// notifySignals returns the channel of the signals given to OnSignal, or
+// nil if there are none.
+func (w *Watcher) notifySignals() chan os.Signal {
+	if len(w.config.signals) == 0 {
+		return nil
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, w.config.signals...)
+
+	return signalChan
+}
+
+// scheduleReload (re)arms the debounce timer, so the config reloads once
+// changes settle.
+func (w *Watcher) scheduleReload(timers *loopTimers, reason string) {
+	w.logDebug("reload scheduled", "reason", reason)
+	timers.debounce.reset(w.config.clock, w.config.debounceInterval)
+}
+
+// leaseRenewer returns the resolver as a LeaseRenewer, if lease renewal is
+// enabled and supported.
+func (w *Watcher) leaseRenewer() LeaseRenewer {
+	if !w.config.autoRenewLease {
+		return nil
+	}
+	renewer, _ := w.config.refResolver.(LeaseRenewer)
+
+	return renewer
+}
+
+// renewLeases renews the leases of renewer, scheduling a reload if any
+// expired, and arms the renewal timer for the next renewal.
+func (w *Watcher) renewLeases(ctx context.Context, renewer LeaseRenewer, timers *loopTimers) {
+	next, expired, err := renewer.RenewLeases(ctx)
+	if err != nil {
+		w.logDebug("lease renewal failed", "error", err)
+		w.sendError(&WatcherError{Message: "failed to renew leases", Err: err})
+	}
+	if expired {
+		w.scheduleReload(timers, "lease expired")
+	}
+	if next <= 0 {
+		next = w.config.watchInterval // leases may be acquired later
+	}
+	timers.renew.reset(w.config.clock, next)
+}
+
+// armBoundary arms the boundary timer for the next boundary of scheduled
+// sections, if any.
+func (w *Watcher) armBoundary(timers *loopTimers) {
+	timers.boundary.stop()
+
+	// A boundary that is not ahead failed to reload; polling retries it
+	d := w.nextBoundary.Sub(w.now())
+	if w.nextBoundary.IsZero() || d <= 0 {
+		return
+	}
+	timers.boundary.reset(w.config.clock, d)
+}
+
+// handleReload reloads target once the debounce timer fires, publishing
+// the new config if it changed. It returns false if the watch loop must
+// exit.
+func (w *Watcher) handleReload(target any, timers *loopTimers) bool {
+	previous := w.lastConfig
+	changed, err := w.reloadIfChanged(target)
+	if w.config.metrics != nil {
+		w.config.metrics.Reloaded(w.now(), err)
+	}
+	if err != nil {
+		w.logDebug("config reload failed, keeping the last good config", "error", err)
+	} else if !changed {
+		w.logDebug("config reloaded, no changes")
+	}
+	if w.fsWatcher != nil {
+		w.rewatchConfigFiles()
+		w.refFiles.watchDirs(w.fsWatcher) // refs may point to new files
+	}
+	w.armBoundary(timers)
+	if err != nil {
+		return w.reportError(err)
+	}
+	if !changed {
+		return true
+	}
+
+	// Create a copy and send to updates channel
+	newConfig := w.deepCopy(target)
+	changes := fuda.Diff(previous, newConfig)
+	w.logDebug("config reloaded", "changes", len(changes))
+	if w.store != nil {
+		w.store(newConfig)
+	}
+	w.notify(changes)
+
+	return w.emit(newConfig, changes)
+}
+
+// emit sends newConfig to the channel returned by Watch or WatchChanges.
+// It returns false if the watcher was stopped while waiting.
+func (w *Watcher) emit(newConfig any, changes []fuda.FieldChange) bool {
+	if w.store != nil {
+		return true // stored by Bind; nothing consumes the channel
+	}
+
+	if w.changesChan != nil {
+		update := Update{Config: newConfig, Changes: changes}
+		select {
+		case w.changesChan <- update:
+			return true
+		case <-w.stopChan:
 			return false
 		}
-		// Quick check: if content is identical, skip full reload
-		if string(content) == string(w.configContent) {
+	}
+
+	select {
+	case w.updatesChan <- newConfig:
+		return true
+	case <-w.stopChan:
+		return false
+	}
+}
+
+// notify calls the OnChange callbacks whose path matches a change.
+func (w *Watcher) notify(changes []fuda.FieldChange) {
+	w.mu.Lock()
+	subs := slices.Clone(w.subscriptions)
+	w.mu.Unlock()
+
+	for _, sub := range subs {
+		for _, c := range changes {
+			if pathMatches(sub.path, c.Path) {
+				sub.fn(c.Old, c.New)
+			}
+		}
+	}
+}
+
+// pathMatches reports whether a change at changed falls under the
+// subscribed path.
+func pathMatches(subscribed, changed string) bool {
+	if !strings.HasPrefix(changed, subscribed) {
+		return false
+	}
+
+	rest := changed[len(subscribed):]
+
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+// reportError publishes a failed reload on Errors and, under the
+// PropagateError policy, on the updates channel. It returns false if the
+// watcher was stopped while waiting.
+func (w *Watcher) reportError(err error) bool {
+	w.sendError(err)
+
+	if w.config.reloadPolicy != PropagateError || w.store != nil {
+		return true
+	}
+
+	if w.changesChan != nil {
+		select {
+		case w.changesChan <- Update{Err: err}:
+			return true
+		case <-w.stopChan:
 			return false
 		}
+	}
+
+	select {
+	case w.updatesChan <- err:
+		return true
+	case <-w.stopChan:
+		return false
+	}
+}
+
+// sendError publishes err on Errors, dropping it if the channel is full.
+func (w *Watcher) sendError(err error) {
+	select {
+	case w.errorsChan <- err:
+	default:
+		w.logDebug("error dropped, Errors channel is full", "error", err)
+	}
+}
+
+// logDebug logs msg at debug level to the logger set by WithLogger, if any.
+func (w *Watcher) logDebug(msg string, args ...any) {
+	if w.config.logger != nil {
+		w.config.logger.Debug(msg, args...)
+	}
+}
+
+// closeUpdates closes the channel returned by Watch or WatchChanges.
+func (w *Watcher) closeUpdates() {
+	if w.changesChan != nil {
+		close(w.changesChan)
+	} else {
+		close(w.updatesChan)
+	}
+}
+
+// reloadIfChanged reloads configuration and returns true if it changed.
+// On failure target is left untouched and the error is returned.
+func (w *Watcher) reloadIfChanged(target any) (bool, error) {
+	// For file-based or source-based config, check if content changed
+	if w.hasDynamicSource() {
+		content, err := w.readSource()
+		if err != nil {
+			return false, &WatcherError{Message: "failed to read config source", Err: err}
+		}
+		// Even when the document is unchanged, resolved refs may have changed,
+		// so always fall through to a full reload; configEquals filters no-ops.
 		w.configContent = content
 	}
 
 	// Create a new target of the same type
 	targetType := reflect.TypeOf(target)
 	if targetType.Kind() != reflect.Ptr {
-		return false
+		return false, nil
 	}
 	newTarget := reflect.New(targetType.Elem()).Interface()
 
 	// Create a fresh loader with updated content for file-based config
 	var loadErr error
-	if w.configPath != "" && len(w.configContent) > 0 {
+	if w.hasDynamicSource() && len(w.configContent) > 0 {
 		// Create a new loader with the updated content
-		builder := fuda.New().WithFilesystem(w.fs).FromBytes(w.configContent)
+		builder := fuda.New().
+			WithFilesystem(w.fs).
+			WithResolverMiddleware(w.refFiles.middleware).
+			FromBytes(w.configContent)
 		if w.config.envPrefix != "" {
 			builder = builder.WithEnvPrefix(w.config.envPrefix)
 		}
 		if w.config.refResolver != nil {
 			builder = builder.WithRefResolver(w.config.refResolver)
 		}
-		if w.config.validator != nil {
-			if v, ok := w.config.validator.(*validator.Validate); ok {
-				builder = builder.WithValidator(v)
-			}
+		if w.config.metrics != nil {
+			builder = builder.WithMetrics(w.config.metrics)
 		}
+		if w.config.logger != nil {
+			builder = builder.WithLogger(w.config.logger)
+		}
+		builder = withValidator(builder, w.config.validator)
 		freshLoader, err := builder.Build()
 		if err != nil {
-			return false
+			return false, &WatcherError{Message: "failed to reload config", Err: err}
 		}
 		loadErr = freshLoader.Load(newTarget)
 	} else {
@@ -274,22 +836,195 @@ func (w *Watcher) reloadIfChanged(target any) bool {
 	}
 
 	if loadErr != nil {
-		// Log error but don't stop watching
-		return false
+		// Keep the last good config and keep watching
+		return false, &WatcherError{Message: "failed to reload config", Err: loadErr}
 	}
+	w.applySchedule(newTarget)
 
 	// Compare with last config
 	if w.configEquals(newTarget, w.lastConfig) {
-		return false
+		return false, nil
 	}
 
 	// Update target in place
 	reflect.ValueOf(target).Elem().Set(reflect.ValueOf(newTarget).Elem())
 	w.lastConfig = w.deepCopy(target)
 
+	return true, nil
+}
+
+// applySchedule removes the sections of a freshly loaded config that are not
+// in effect and records the next window boundary, if WithSchedule is set.
+func (w *Watcher) applySchedule(cfg any) {
+	if w.config.schedule {
+		w.nextBoundary = schedule.Apply(cfg, w.now())
+	}
+}
+
+// now returns the current time of the clock, if it tells time (like the
+// watchertest fake clock), or the wall clock.
+func (w *Watcher) now() time.Time {
+	if c, ok := w.config.clock.(interface{ Now() time.Time }); ok {
+		return c.Now()
+	}
+
+	return time.Now()
+}
+
+// watchConfigFiles adds every config file and its parent directory to fsw.
+// The directory watch reports files that editors and deploy tools recreate
+// in place, which drops the watch on the replaced file.
+func (w *Watcher) watchConfigFiles(fsw *fsnotify.Watcher) {
+	w.configDirs = make(map[string]bool)
+	w.unwatched = make(map[string]bool)
+	for _, path := range w.configPaths {
+		if err := fsw.Add(path); err != nil {
+			w.logDebug("config file not watched, retrying after the next reload", "path", path, "error", err)
+			w.unwatched[path] = true
+		}
+		w.watchedFiles = append(w.watchedFiles, path)
+
+		dir := filepath.Dir(filepath.Clean(path))
+		if !w.configDirs[dir] && fsw.Add(dir) == nil {
+			w.configDirs[dir] = true
+		}
+	}
+}
+
+// handleConfigEvent keeps the config file watches alive across atomic saves
+// and reports whether event may have changed a config file.
+//
+// Editors such as vim save by renaming the old file away and creating a new
+// one, and other tools rename a temporary file over the target. Either way
+// the watched inode is gone, so the watch is re-added on the new file. A
+// Remove or Rename alone does not reload, since the new file may not be
+// written yet; the Create that follows does.
+func (w *Watcher) handleConfigEvent(event fsnotify.Event) bool {
+	if w.isConfigEvent(event) {
+		switch {
+		case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+			// The file may already be replaced, as by a rename over it
+			return w.rewatch(event.Name)
+		case event.Op&fsnotify.Create != 0:
+			w.rewatch(event.Name)
+
+			return true
+		default:
+			return event.Op&fsnotify.Write != 0
+		}
+	}
+
+	// A hidden entry such as Kubernetes' "..data" symlink was swapped under
+	// a symlinked config file
+	name := filepath.Clean(event.Name)
+	if w.configDirs[filepath.Dir(name)] && strings.HasPrefix(filepath.Base(name), "..") {
+		w.rewatchConfigFiles()
+
+		return true
+	}
+
+	return false
+}
+
+// rewatch re-adds the file watch of the config file at path and reports
+// whether it succeeded. A path that does not exist yet is retried after the
+// next reload.
+func (w *Watcher) rewatch(path string) bool {
+	for _, p := range w.configPaths {
+		if filepath.Clean(p) != filepath.Clean(path) {
+			continue
+		}
+		_ = w.fsWatcher.Remove(p)
+		if err := w.fsWatcher.Add(p); err != nil {
+			w.logDebug("config file watch lost, retrying after the next reload", "path", p, "error", err)
+			w.unwatched[p] = true
+
+			return false
+		}
+		delete(w.unwatched, p)
+	}
+
 	return true
 }
 
+// rewatchConfigFiles re-adds the file watches lost by config files that were
+// missing when last seen. Symlinked files are re-added too, as their target
+// may have changed.
+func (w *Watcher) rewatchConfigFiles() {
+	for _, path := range w.configPaths {
+		if w.unwatched[path] || isSymlink(path) {
+			w.rewatch(path)
+		}
+	}
+}
+
+// isSymlink reports whether path is a symbolic link.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// isConfigEvent reports whether event concerns one of the config files.
+func (w *Watcher) isConfigEvent(event fsnotify.Event) bool {
+	for _, path := range w.configPaths {
+		if filepath.Clean(event.Name) == filepath.Clean(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasDynamicSource reports whether the configuration document is re-read on reload.
+func (w *Watcher) hasDynamicSource() bool {
+	return len(w.configPaths) > 0 || w.source != nil
+}
+
+// readSource reads the current configuration document from the source or
+// files, merging multiple files in order.
+func (w *Watcher) readSource() ([]byte, error) {
+	if w.source != nil {
+		return w.source(context.Background())
+	}
+
+	fs := w.fs
+	if fs == nil {
+		fs = fuda.DefaultFs
+	}
+
+	if len(w.configPaths) == 1 {
+		return readConfigFile(fs, w.configPaths[0])
+	}
+
+	layers := make([]loader.Layer, 0, len(w.configPaths))
+	for _, path := range w.configPaths {
+		data, err := readConfigFile(fs, path)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, loader.Layer{Name: path, Data: data})
+	}
+
+	return loader.MergeLayers(layers)
+}
+
+// readConfigFile reads the config file at path as UTF-8 with "\n" line
+// endings, decoded like FromFiles does at startup.
+func readConfigFile(fs afero.Fs, path string) ([]byte, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = loader.DecodeSource(data)
+	if err != nil {
+		return nil, &fuda.FieldError{Message: "failed to decode " + path, Err: err}
+	}
+
+	return data, nil
+}
+
 // deepCopy creates a deep copy of the config value.
 func (w *Watcher) deepCopy(v any) any {
 	if v == nil {
@@ -1,7 +1,12 @@
 package watcher
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -101,8 +106,8 @@ func TestWatcher_Watch(t *testing.T) {
 		// Initial values
 		assert.Equal(t, "initial.com", cfg.Host)
 
-		// Give fsnotify time to set up the watch
-		time.Sleep(50 * time.Millisecond)
+		// Wait until the fsnotify watch is set up
+		<-w.ready
 
 		// Modify the file
 		err = os.WriteFile(tmpFile.Name(), []byte("host: updated.com\nport: 5678\n"), 0o644)
@@ -202,25 +207,32 @@ func TestWatcher_Concurrent(t *testing.T) {
 
 		// Track updates received
 		var updateCount int64
+		firstUpdate := make(chan struct{})
 
 		// Consumer goroutine
 		done := make(chan struct{})
 		go func() {
 			for range updates {
-				atomic.AddInt64(&updateCount, 1)
+				if atomic.AddInt64(&updateCount, 1) == 1 {
+					close(firstUpdate)
+				}
 			}
 			close(done)
 		}()
 
-		// Give fsnotify time to set up
-		time.Sleep(100 * time.Millisecond)
+		// Wait until the fsnotify watch is set up
+		<-w.ready
 
 		// Make a significant change to trigger an update
 		err = os.WriteFile(tmpFile.Name(), []byte("host: updated.com\nport: 9999\n"), 0o644)
 		require.NoError(t, err)
 
-		// Give time for update to propagate
-		time.Sleep(500 * time.Millisecond)
+		// Wait for the update to propagate
+		select {
+		case <-firstUpdate:
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
 
 		w.Stop()
 		<-done
@@ -275,3 +287,356 @@ func TestBuilder_Options(t *testing.T) {
 		assert.True(t, w.config.autoRenewLease)
 	})
 }
+
+// pushResolver is a WatchableResolver backed by an in-memory map.
+type pushResolver struct {
+	mu      sync.Mutex
+	values  map[string]string
+	changes chan struct{}
+}
+
+func newPushResolver(values map[string]string) *pushResolver {
+	return &pushResolver{values: values, changes: make(chan struct{}, 1)}
+}
+
+func (r *pushResolver) Resolve(_ context.Context, uri string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.values[uri]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	return []byte(v), nil
+}
+
+func (r *pushResolver) Watch(_ context.Context) (<-chan struct{}, error) {
+	return r.changes, nil
+}
+
+func (r *pushResolver) set(uri, value string) {
+	r.mu.Lock()
+	r.values[uri] = value
+	r.mu.Unlock()
+	r.changes <- struct{}{}
+}
+
+func TestWatcher_WatchableResolver(t *testing.T) {
+	type secretConfig struct {
+		Password string `ref:"mem://password"`
+	}
+
+	resolver := newPushResolver(map[string]string{"mem://password": "old"})
+
+	w, err := New().
+		FromBytes([]byte("{}")).
+		WithRefResolver(resolver).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(time.Millisecond).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg secretConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "old", cfg.Password)
+
+	resolver.set("mem://password", "new")
+
+	select {
+	case newCfg := <-updates:
+		updated, ok := newCfg.(*secretConfig)
+		require.True(t, ok, "expected *secretConfig")
+		assert.Equal(t, "new", updated.Password)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+}
+
+func TestWatcher_FromSource(t *testing.T) {
+	t.Run("fails when initial fetch fails", func(t *testing.T) {
+		_, err := New().
+			FromSource(func(context.Context) ([]byte, error) {
+				return nil, errors.New("unavailable")
+			}).
+			Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unavailable")
+	})
+}
+
+func TestWatcher_FromFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	secrets := filepath.Join(dir, "secrets.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("host: base.com\nport: 1234\n"), 0o600))
+	require.NoError(t, os.WriteFile(prod, []byte("host: prod.com\n"), 0o600))
+	require.NoError(t, os.WriteFile(secrets, []byte(""), 0o600))
+
+	w, err := New().
+		FromFiles(base, prod, secrets).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(10 * time.Millisecond).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg testConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "prod.com", cfg.Host)
+	assert.Equal(t, 1234, cfg.Port)
+
+	// Wait until the fsnotify watch is set up
+	<-w.ready
+
+	waitConfig := func(want testConfig) {
+		t.Helper()
+
+		select {
+		case newCfg := <-updates:
+			updated, ok := newCfg.(*testConfig)
+			require.True(t, ok, "expected *testConfig")
+			assert.Equal(t, want, *updated)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+	}
+
+	// A change to the base file is merged under the overlay
+	require.NoError(t, os.WriteFile(base, []byte("host: base.com\nport: 5678\n"), 0o600))
+	waitConfig(testConfig{Host: "prod.com", Port: 5678, Timeout: "30s"})
+
+	// A change to the last overlay wins
+	require.NoError(t, os.WriteFile(secrets, []byte("port: 9999\n"), 0o600))
+	waitConfig(testConfig{Host: "prod.com", Port: 9999, Timeout: "30s"})
+}
+
+func TestWatcher_FromFiles_EncodedLayer(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overlay := filepath.Join(dir, "overlay.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("host: base.com\nport: 1234\n"), 0o600))
+	require.NoError(t, os.WriteFile(overlay, []byte("\xEF\xBB\xBFhost: bom.com\r\n"), 0o600))
+
+	w, err := New().
+		FromFiles(base, overlay).
+		WithWatchInterval(time.Hour).
+		WithDebounceInterval(10 * time.Millisecond).
+		Build()
+	require.NoError(t, err)
+	defer w.Stop()
+
+	var cfg testConfig
+	updates, err := w.Watch(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "bom.com", cfg.Host)
+
+	// Wait until the fsnotify watch is set up
+	<-w.ready
+
+	// Saved by a Windows editor: UTF-16LE without a byte order mark and CRLF
+	var utf16 []byte
+	for _, r := range "host: utf16.com\r\nport: 9999\r\n" {
+		utf16 = append(utf16, byte(r), 0)
+	}
+	require.NoError(t, os.WriteFile(overlay, utf16, 0o600))
+
+	select {
+	case newCfg := <-updates:
+		updated, ok := newCfg.(*testConfig)
+		require.True(t, ok, "expected *testConfig")
+		assert.Equal(t, testConfig{Host: "utf16.com", Port: 9999, Timeout: "30s"}, *updated)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for config update")
+	}
+}
+
+func TestWatcher_AtomicSaves(t *testing.T) {
+	watch := func(t *testing.T, path string) <-chan any {
+		t.Helper()
+
+		w, err := New().
+			FromFile(path).
+			WithWatchInterval(time.Hour).
+			WithDebounceInterval(10 * time.Millisecond).
+			Build()
+		require.NoError(t, err)
+		t.Cleanup(w.Stop)
+
+		var cfg testConfig
+		updates, err := w.Watch(&cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "v0.com", cfg.Host)
+
+		// Wait until the fsnotify watch is set up
+		<-w.ready
+
+		return updates
+	}
+
+	waitHost := func(t *testing.T, updates <-chan any, want string) {
+		t.Helper()
+
+		select {
+		case newCfg := <-updates:
+			updated, ok := newCfg.(*testConfig)
+			require.True(t, ok, "expected *testConfig")
+			assert.Equal(t, want, updated.Host)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+	}
+
+	content := func(i int) []byte {
+		return []byte("host: v" + strconv.Itoa(i) + ".com\n")
+	}
+
+	t.Run("vim-style rename and create", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, content(0), 0o600))
+
+		updates := watch(t, path)
+
+		// Every save must be seen, not only the first after the watch is lost
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, os.Rename(path, path+"~"))
+			require.NoError(t, os.WriteFile(path, content(i), 0o600))
+			require.NoError(t, os.Remove(path+"~"))
+			waitHost(t, updates, "v"+strconv.Itoa(i)+".com")
+		}
+	})
+
+	t.Run("temporary file renamed over the config", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		require.NoError(t, os.WriteFile(path, content(0), 0o600))
+
+		updates := watch(t, path)
+
+		for i := 1; i <= 3; i++ {
+			tmp := filepath.Join(dir, ".config.yaml.tmp")
+			require.NoError(t, os.WriteFile(tmp, content(i), 0o600))
+			require.NoError(t, os.Rename(tmp, path))
+			waitHost(t, updates, "v"+strconv.Itoa(i)+".com")
+		}
+	})
+
+	t.Run("removed and recreated later", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, content(0), 0o600))
+
+		updates := watch(t, path)
+
+		require.NoError(t, os.Remove(path))
+		require.NoError(t, os.WriteFile(path, content(1), 0o600))
+		waitHost(t, updates, "v1.com")
+
+		// The recreated file is watched for plain writes
+		require.NoError(t, os.WriteFile(path, content(2), 0o600))
+		waitHost(t, updates, "v2.com")
+	})
+
+	t.Run("symlinked config with swapped target", func(t *testing.T) {
+		// Kubernetes ConfigMap volumes expose each key as a symlink through
+		// ..data, which is atomically re-pointed at a new directory.
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "..v0"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "..v0", "config.yaml"), content(0), 0o600))
+		require.NoError(t, os.Symlink("..v0", filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")))
+
+		updates := watch(t, filepath.Join(dir, "config.yaml"))
+
+		for i := 1; i <= 2; i++ {
+			version := "..v" + strconv.Itoa(i)
+			require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o700))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, version, "config.yaml"), content(i), 0o600))
+			require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+			require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+			waitHost(t, updates, "v"+strconv.Itoa(i)+".com")
+		}
+	})
+}
+
+func TestBuilder_FromFilesErrors(t *testing.T) {
+	_, err := New().FromFiles().Build()
+	require.Error(t, err)
+
+	_, err = New().FromFiles(filepath.Join(t.TempDir(), "missing.yaml")).Build()
+	require.Error(t, err)
+}
+
+func TestWatcher_RefFiles(t *testing.T) {
+	type fileSecretConfig struct {
+		PasswordFile string `yaml:"password_file"`
+		Password     string `yaml:"password" refFrom:"PasswordFile"`
+	}
+
+	watch := func(t *testing.T, secretPath string) (*Watcher, <-chan any) {
+		t.Helper()
+
+		w, err := New().
+			FromBytes([]byte("password_file: file://" + secretPath + "\n")).
+			WithWatchInterval(time.Hour).
+			WithDebounceInterval(10 * time.Millisecond).
+			Build()
+		require.NoError(t, err)
+		t.Cleanup(w.Stop)
+
+		var cfg fileSecretConfig
+		updates, err := w.Watch(&cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "old", cfg.Password)
+
+		// Wait until the fsnotify watch is set up
+		<-w.ready
+
+		return w, updates
+	}
+
+	waitPassword := func(t *testing.T, updates <-chan any, want string) {
+		t.Helper()
+
+		select {
+		case newCfg := <-updates:
+			updated, ok := newCfg.(*fileSecretConfig)
+			require.True(t, ok, "expected *fileSecretConfig")
+			assert.Equal(t, want, updated.Password)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for config update")
+		}
+	}
+
+	t.Run("reloads when a referenced file is rewritten", func(t *testing.T) {
+		secretPath := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(secretPath, []byte("old"), 0o600))
+
+		_, updates := watch(t, secretPath)
+
+		require.NoError(t, os.WriteFile(secretPath, []byte("new"), 0o600))
+		waitPassword(t, updates, "new")
+	})
+
+	t.Run("reloads on a Kubernetes-style symlink swap", func(t *testing.T) {
+		// Kubernetes secret volumes expose each key as a symlink through
+		// ..data, which is atomically re-pointed at a new directory.
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "..v1"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "..v1", "password"), []byte("old"), 0o600))
+		require.NoError(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "password"), filepath.Join(dir, "password")))
+
+		_, updates := watch(t, filepath.Join(dir, "password"))
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "..v2"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "..v2", "password"), []byte("new"), 0o600))
+		require.NoError(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+		waitPassword(t, updates, "new")
+	})
+}
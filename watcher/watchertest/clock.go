@@ -0,0 +1,188 @@
+// Package watchertest provides test helpers for code that uses the watcher
+// package, so reload handling can be unit-tested deterministically without
+// time.Sleep-based synchronization.
+//
+// Basic usage:
+//
+//	clock := watchertest.NewFakeClock(time.Now())
+//	w, _ := watcher.New().
+//	    FromBytes(data).
+//	    WithClock(clock).
+//	    WithDebounceInterval(100 * time.Millisecond).
+//	    Build()
+//	updates, _ := w.Watch(&cfg)
+//
+//	// Simulate a change and let the debounce timer fire.
+//	w.Trigger()
+//	clock.BlockUntil(2) // poll ticker + debounce timer
+//	clock.Advance(100 * time.Millisecond)
+//	newCfg := <-updates
+package watchertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arloliu/fuda/watcher"
+)
+
+// FakeClock is a manually advanced watcher.Clock.
+// Timers and tickers fire only when Advance moves the clock past their
+// deadline. FakeClock is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a pending timer or ticker registered with a FakeClock.
+type waiter struct {
+	clock    *FakeClock
+	ch       chan time.Time
+	deadline time.Time
+	period   time.Duration // zero for one-shot timers
+}
+
+// fakeTicker implements watcher.Ticker on top of a waiter.
+type fakeTicker struct {
+	*waiter
+}
+
+// fakeTimer implements watcher.Timer on top of a waiter.
+type fakeTimer struct {
+	*waiter
+}
+
+var (
+	_ watcher.Clock  = (*FakeClock)(nil)
+	_ watcher.Ticker = fakeTicker{}
+	_ watcher.Timer  = fakeTimer{}
+)
+
+// NewFakeClock creates a FakeClock whose current time is now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker returns a ticker that fires every d of fake time.
+func (c *FakeClock) NewTicker(d time.Duration) watcher.Ticker {
+	return fakeTicker{waiter: c.add(d, d)}
+}
+
+// NewTimer returns a timer that fires once after d of fake time.
+func (c *FakeClock) NewTimer(d time.Duration) watcher.Timer {
+	return fakeTimer{waiter: c.add(d, 0)}
+}
+
+// Advance moves the clock forward by d and fires every timer and ticker whose
+// deadline has passed. Like time.Ticker, a ticker delivers at most one pending
+// tick; ticks that would overflow its channel are dropped.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+
+		if w.period > 0 {
+			for !w.deadline.After(c.now) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending.
+// Use it to wait for the watcher goroutine to arm its debounce timer before
+// calling Advance.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// Pending returns the number of timers and tickers currently pending.
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+// add registers a new waiter firing after d, repeating every period if non-zero.
+func (c *FakeClock) add(d, period time.Duration) *waiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &waiter{
+		clock:    c,
+		ch:       make(chan time.Time, 1),
+		deadline: c.now.Add(d),
+		period:   period,
+	}
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+
+	return w
+}
+
+// remove unregisters w and reports whether it was pending.
+func (c *FakeClock) remove(w *waiter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.cond.Broadcast()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// C returns the channel on which the waiter fires.
+func (w *waiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop stops the ticker.
+func (t fakeTicker) Stop() {
+	t.clock.remove(t.waiter)
+}
+
+// Stop stops the timer and reports whether it was pending.
+func (t fakeTimer) Stop() bool {
+	return t.clock.remove(t.waiter)
+}